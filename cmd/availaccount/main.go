@@ -1,10 +1,10 @@
 package availaccount
 
 import (
+	"context"
 	"log"
 	"math/big"
 	"math/rand"
-	"os"
 	"time"
 
 	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
@@ -18,9 +18,6 @@ const (
 	// AVL is a constant representing 1 Avail token in its smallest denomination.
 	// The Avail token has 18 decimal places, so 1 Avail token equals 10^18 of its smallest denomination.
 	AVL = 1_000_000_000_000_000_000
-
-	// maxUint64 is the maximum value that can be represented by a uint64.
-	maxUint64 = ^uint64(0)
 )
 
 // GetCommand returns a Cobra command for creating an avail account and depositing the balance.
@@ -33,42 +30,71 @@ const (
 //	}
 func GetCommand() *cobra.Command {
 	var balance uint64
-	var availAddr, path string
+	var availAddr, path, passphraseFile, network, devFunder string
 	var retry bool
+	var strength int
 	cmd := &cobra.Command{
 		Use:   "availaccount",
 		Short: "Create an avail account and deposit the balance",
 		Run: func(cmd *cobra.Command, args []string) {
-			Run(availAddr, path, balance, retry)
+			Run(availAddr, path, passphraseFile, network, devFunder, balance, retry, strength)
 		},
 	}
-	cmd.Flags().StringVar(&availAddr, "avail-addr", "ws://127.0.0.1:9944/v1/json-rpc", "Avail JSON-RPC URL")
+	cmd.Flags().StringVar(&availAddr, "avail-addr", "ws://127.0.0.1:9944/v1/json-rpc", "Avail JSON-RPC URL, WebSocket or HTTP")
 	cmd.Flags().StringVar(&path, "path", "./configs/account", "Save path for account memonic file")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Path to the file with the passphrase the account mnemonic file is encrypted with")
+	cmd.Flags().StringVar(&network, "network", "", "Name of the Avail network the account is created for, recorded in the account file")
+	cmd.Flags().StringVar(&devFunder, "dev-funder", "", "Secret URI of the Avail account funding the new account, the Alice development account without it")
 	cmd.Flags().Uint64Var(&balance, "balance", 18, "Number of AVLs to deposit on the account")
 	cmd.Flags().BoolVar(&retry, "retry", false, "Retry if account deposit fails")
+	cmd.Flags().IntVar(&strength, "mnemonic-strength", avail.DefaultMnemonicStrength, "Entropy bits of the account mnemonic: 128, 160, 192, 224 or 256 (24 words)")
 	return cmd
 }
 
 // Run is responsible for setting up and executing the process of creating an Avail account and
 // depositing a balance into it. It takes the Avail JSON-RPC URL, a file path for saving the
-// account mnemonic, the balance to deposit into the account, and a retry flag to indicate
-// whether the process should be retried if an error occurs.
+// account file, a file path of the passphrase the mnemonic is encrypted with (the mnemonic
+// is saved in plaintext without it), the name of the Avail network recorded in the account file,
+// the secret URI of the funder account (Alice without it), the balance to deposit into the account,
+// a retry flag to indicate whether the process should be retried if an error occurs, and the entropy
+// bits of the mnemonic.
 // Example usage:
-// Run("ws://127.0.0.1:9944/v1/json-rpc", "./configs/account", 18, false)
-func Run(availAddr, path string, balance uint64, retry bool) {
+// Run("ws://127.0.0.1:9944/v1/json-rpc", "./configs/account", "./configs/passphrase", "testnet", "", 18, false, 256)
+func Run(availAddr, path, passphraseFile, network, devFunder string, balance uint64, retry bool, strength int) {
+	passphrase, err := avail.ReadPassphraseFile(passphraseFile)
+	if err != nil {
+		panic(err)
+	}
+
+	if devFunder != "" {
+		funder, err := avail.NewAccountFromURI(devFunder, avail.DefaultSS58Prefix())
+		if err != nil {
+			panic(err)
+		}
+
+		avail.SetDevFunder(funder)
+	}
+
 	availClient, err := avail.NewClient(availAddr, hclog.Default())
 	if err != nil {
 		panic(err)
 	}
 
+	// The address of the account is encoded with the SS58 prefix of the chain.
+	if _, err := avail.UseChainDefaults(availClient); err != nil {
+		log.Printf("WARNING: couldn't detect the Avail chain properties, using the defaults: %s", err)
+	}
+
 	log.Print("Creating new avail account...")
 
-	availAccount, err := avail.NewAccount()
+	generated, err := avail.NewAccountWithStrength(strength)
 	if err != nil {
 		panic(err)
 	}
 
-	log.Printf("Created new avail account %+v", availAccount)
+	availAccount := generated.KeyPair
+
+	log.Printf("Created new avail account %s", availAccount.Address)
 	log.Printf("Depositing %d AVL to '%s'...", balance, availAccount.Address)
 
 	if retry {
@@ -90,40 +116,23 @@ func Run(availAddr, path string, balance uint64, retry bool) {
 
 	log.Printf("Successfully deposited '%d' AVL to '%s'", balance, availAccount.Address)
 
-	if err := os.WriteFile(path, []byte(availAccount.URI), 0o644); err != nil {
+	if err := avail.SaveAccount(path, generated.Mnemonic, passphrase, avail.WithAccountNetwork(network)); err != nil {
 		panic(err)
 	}
 
-	log.Printf("Successfuly written mnemonic into '%s'", path)
+	log.Printf("Successfuly written account file into '%s'", path)
 }
 
-// deposit is a helper function used to deposit a specified balance into an Avail account.
-// It works by depositing the balance in chunks of maxUint64 due to the API limitations.
+// deposit is a helper function used to deposit a specified balance, in AVL, into an Avail account.
 // This function takes an Avail client, an Avail account, and a balance, and returns an error.
 // Example usage (assuming availClient and availAccount are already defined):
 //
 //	if err := deposit(availClient, availAccount, 1000); err != nil {
 //	   log.Fatalf("deposit error: %v", err)
 //	}
-func deposit(availClient avail.Client, availAccount signature.KeyringPair, balance uint64) (err error) {
-	amount := big.NewInt(0).Mul(big.NewInt(0).SetUint64(balance), big.NewInt(AVL))
-
-	for {
-		if amount.IsUint64() {
-			err = avail.DepositBalance(availClient, availAccount, amount.Uint64(), 0)
-			if err != nil {
-				return err
-			}
+func deposit(availClient avail.Client, availAccount signature.KeyringPair, balance uint64) error {
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(balance), big.NewInt(AVL))
 
-			break
-		} else {
-			err = avail.DepositBalance(availClient, availAccount, maxUint64, 0)
-			if err != nil {
-				return err
-			}
-
-			amount = big.NewInt(0).Sub(amount, big.NewInt(0).SetUint64(maxUint64))
-		}
-	}
-	return
+	_, err := avail.DepositAmountFromDevFunder(context.Background(), availClient, availAccount, amount, avail.SubmitOpts{})
+	return err
 }
@@ -1,11 +1,16 @@
 package server
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/helper/common"
+	avail_types "github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/hashicorp/go-hclog"
 	golog "github.com/ipfs/go-log/v2"
 	"github.com/spf13/cobra"
@@ -26,28 +31,69 @@ import (
 //	}
 func GetCommand() *cobra.Command {
 	var bootnode bool
-	var availAddr, path, accountPath, fraudListenAddr string
+	var availAddr, path, accountPath, accountPassphraseFile, fraudListenAddr, devFunder, maxFee, feeBudget string
+	var feeBudgetWindow time.Duration
 	cmd := &cobra.Command{
 		Use:   "server",
 		Short: "Run the Optimistic EVM Rollup",
 		Run: func(cmd *cobra.Command, args []string) {
-			Run(availAddr, path, accountPath, fraudListenAddr, bootnode)
+			budget, err := newFeeBudget(maxFee, feeBudget, feeBudgetWindow)
+			if err != nil {
+				log.Fatalf("invalid Avail fee budget: %s\n", err)
+			}
+
+			Run(availAddr, path, accountPath, accountPassphraseFile, fraudListenAddr, devFunder, budget, bootnode)
 		},
 	}
-	cmd.Flags().StringVar(&availAddr, "avail-addr", "ws://127.0.0.1:9944/v1/json-rpc", "Avail JSON-RPC URL")
+	cmd.Flags().StringVar(&availAddr, "avail-addr", "ws://127.0.0.1:9944/v1/json-rpc", "Avail JSON-RPC URL, or comma separated URLs of the same Avail network to fail over between")
 	cmd.Flags().StringVar(&path, "config-file", "./configs/bootnode.yaml", "Path to the configuration file")
 	cmd.Flags().StringVar(&accountPath, "account-config-file", "./configs/account", "Path to the account mnemonic file")
+	cmd.Flags().StringVar(&accountPassphraseFile, "account-passphrase-file", "", "Path to the file with the passphrase of the encrypted account file; a plaintext account file is encrypted with it")
+	cmd.Flags().StringVar(&devFunder, "dev-funder", "", "Secret URI of the Avail account topping up the node account, the Alice development account without it")
 	cmd.Flags().BoolVar(&bootnode, "bootstrap", false, "bootstrap flag must be specified for the first node booting a new network from the genesis")
 	cmd.Flags().StringVar(&fraudListenAddr, "fraud-srv-listen-addr", ":9990", "Fraud server listen address")
+	cmd.Flags().StringVar(&maxFee, "avail-max-fee", "", "Maximum fee of an Avail submission, in AVL, e.g. 0.5; unlimited if empty")
+	cmd.Flags().StringVar(&feeBudget, "avail-fee-budget", "", "Maximum fees of the Avail submissions in the fee budget window, in AVL; unlimited if empty")
+	cmd.Flags().DurationVar(&feeBudgetWindow, "avail-fee-budget-window", time.Hour, "Window of the Avail fee budget")
 	return cmd
 }
 
-// Run initializes and starts the optimistic EVM rollup server. It takes the Avail JSON-RPC URL, a file path for
-// the configuration file, a file path for the account mnemonic file, a fraud server listen address and a bootnode
-// flag. It does not return a value.
+// newFeeBudget returns the Avail fee budget of the maximum fee per submission and the maximum fees in the window,
+// decimal amounts of AVL, or nil if neither is set.
+func newFeeBudget(maxFee, maxSpend string, window time.Duration) (*avail.FeeBudget, error) {
+	if maxFee == "" && maxSpend == "" {
+		return nil, nil
+	}
+
+	fee, err := parseAVL(maxFee)
+	if err != nil {
+		return nil, fmt.Errorf("maximum fee: %w", err)
+	}
+
+	spend, err := parseAVL(maxSpend)
+	if err != nil {
+		return nil, fmt.Errorf("budget: %w", err)
+	}
+
+	return avail.NewFeeBudget(fee, spend, window), nil
+}
+
+// parseAVL parses a decimal amount of AVL into Avail fractions, nil if empty.
+func parseAVL(amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+
+	return avail.AVLToFractions(amount)
+}
+
+// Run initializes and starts the optimistic EVM rollup server. It takes the Avail JSON-RPC URLs, comma separated, a file path for
+// the configuration file, a file path for the account mnemonic file, a file path for the account passphrase file,
+// a fraud server listen address, the secret URI of the account funding the node account (Alice without it), the fee
+// budget capping the fees of the Avail submissions (unlimited if nil) and a bootnode flag. It does not return a value.
 // Example usage:
-// Run("ws://127.0.0.1:9944/v1/json-rpc", "./configs/bootnode.yaml", "./configs/account", ":9990", false)
-func Run(availAddr, path, accountPath, fraudListenAddr string, bootnode bool) {
+// Run("ws://127.0.0.1:9944/v1/json-rpc", "./configs/bootnode.yaml", "./configs/account", "./configs/passphrase", ":9990", "", nil, false)
+func Run(availAddr, path, accountPath, accountPassphraseFile, fraudListenAddr, devFunder string, feeBudget *avail.FeeBudget, bootnode bool) {
 	// Enable LibP2P logging but only >= warn
 	golog.SetAllLoggers(golog.LevelWarn)
 
@@ -59,22 +105,70 @@ func Run(availAddr, path, accountPath, fraudListenAddr string, bootnode bool) {
 	// Enable TxPool P2P gossiping
 	config.Config.Seal = true
 
-	availAccount, err := avail.AccountFromFile(accountPath)
+	var clientOpts []avail.ClientOption
+
+	if devFunder != "" {
+		funder, err := avail.NewAccountFromURI(devFunder, avail.DefaultSS58Prefix())
+		if err != nil {
+			log.Fatalf("invalid Avail development funder: %s\n", err)
+		}
+
+		clientOpts = append(clientOpts, avail.WithDevFunder(funder))
+	}
+
+	if feeBudget != nil {
+		clientOpts = append(clientOpts, avail.WithFeeBudget(feeBudget))
+	}
+
+	availClient, err := avail.NewFailoverClient(strings.Split(availAddr, ","), hclog.Default(), clientOpts...)
+	if err != nil {
+		log.Fatalf("failed to create Avail client: %s\n", err)
+	}
+
+	// The node follows the Avail blocks with subscriptions.
+	if !availClient.Capabilities().Subscriptions {
+		log.Fatalf("Avail endpoint %s doesn't support subscriptions, use a WebSocket (ws:// or wss://) URL\n", availAddr)
+	}
+
+	// The address of the account is encoded with the SS58 prefix of the chain, and the amounts are formatted with
+	// the decimals of its token.
+	if _, err := avail.UseChainDefaults(availClient); err != nil {
+		log.Printf("WARNING: couldn't detect the Avail chain properties, using the defaults: %s", err)
+	}
+
+	passphrase, err := avail.ReadPassphraseFile(accountPassphraseFile)
+	if err != nil {
+		log.Fatalf("failed to read Avail account passphrase: %s\n", err)
+	}
+
+	if passphrase == "" {
+		log.Printf("WARNING: no Avail account passphrase set, expecting the account file %q to be unencrypted", accountPath)
+	}
+
+	availAccount, err := avail.AccountFromFile(accountPath, passphrase)
 	if err != nil {
 		log.Fatalf("failed to read Avail account from %q: %s\n", accountPath, err)
 	}
 
-	availClient, err := avail.NewClient(availAddr, hclog.Default())
+	nonces := avail.NewNonceManager()
+
+	// The fraud proofs are posted and read along with the blocks, under the AppID of the blocks.
+	appIDs, err := avail.EnsureAppIDRegistry(context.Background(), availClient, nil, map[avail.Role]string{avail.RoleBlocks: avail.ApplicationKey}, availAccount, avail.SubmitOpts{Nonces: nonces})
 	if err != nil {
-		log.Fatalf("failed to create Avail client: %s\n", err)
+		log.Fatalf("failed to get AppID from Avail: %s\n", err)
 	}
 
-	appID, err := avail.EnsureApplicationKeyExists(availClient, avail.ApplicationKey, availAccount)
+	blocksAppID, err := appIDs.AppID(avail.RoleBlocks)
 	if err != nil {
 		log.Fatalf("failed to get AppID from Avail: %s\n", err)
 	}
 
-	availSender := avail.NewSender(availClient, appID, availAccount)
+	appID := avail_types.NewUCompactFromUInt(uint64(blocksAppID))
+
+	availSender, err := avail.NewSenderForRole(availClient, appIDs, avail.RoleBlocks, availAccount, nonces, avail.SignOpts{})
+	if err != nil {
+		log.Fatalf("failed to create Avail sender: %s\n", err)
+	}
 
 	cfg := consensus.Config{
 		AvailAccount:      availAccount,
@@ -90,7 +184,12 @@ func Run(availAddr, path, accountPath, fraudListenAddr string, bootnode bool) {
 		log.Fatalf("failure to start node: %s", err)
 	}
 
-	if err := HandleSignals(serverInstance.Close); err != nil {
+	closeFn := func() {
+		serverInstance.Close()
+		availClient.Close()
+	}
+
+	if err := HandleSignals(closeFn); err != nil {
 		log.Fatalf("handle signal error: %s", err)
 	}
 }
@@ -53,6 +53,10 @@ const (
 
 	// StakingPollPeersIntervalMs is the interval in milliseconds to wait for when waiting for peers to come up before staking.
 	StakingPollPeersIntervalMs = 200
+
+	// AvailSubmissionTimeout bounds the wait for the blocks sent to Avail to be included, or finalized, so the block
+	// production and the fraud handling don't hang when Avail stalls.
+	AvailSubmissionTimeout = 5 * time.Minute
 )
 
 // minBalance is the minimum number of tokens that miner address must have, in
@@ -299,6 +303,8 @@ func (d *Avail) Start() error {
 // Note: The function panics if it fails to sync the node, ensure the node is staked, or run the Sequencer worker.
 func (d *Avail) startBootstrapSequencer() {
 	activeParticipantsQuerier := staking.NewActiveParticipantsQuerier(d.blockchain, d.executor, d.logger)
+	go d.dumpStakingStateOnSignal(activeParticipantsQuerier)
+	go d.invalidateStakingCacheOnBlocks(activeParticipantsQuerier)
 
 	sequencerWorker, _ := NewSequencer(
 		d.logger.Named(d.nodeType.LogString()), d.blockchain, d.executor, d.txpool,
@@ -331,6 +337,8 @@ func (d *Avail) startBootstrapSequencer() {
 // Note: The function panics if it fails to ensure the node is staked or run the Sequencer worker.
 func (d *Avail) startSequencer() {
 	activeParticipantsQuerier := staking.NewActiveParticipantsQuerier(d.blockchain, d.executor, d.logger)
+	go d.dumpStakingStateOnSignal(activeParticipantsQuerier)
+	go d.invalidateStakingCacheOnBlocks(activeParticipantsQuerier)
 
 	sequencerWorker, _ := NewSequencer(
 		d.logger.Named(d.nodeType.LogString()), d.blockchain, d.executor, d.txpool,
@@ -356,6 +364,8 @@ func (d *Avail) startSequencer() {
 // Note: The function panics if it fails to ensure the node is staked or run the WatchTower process.
 func (d *Avail) startWatchTower() {
 	activeParticipantsQuerier := staking.NewActiveParticipantsQuerier(d.blockchain, d.executor, d.logger)
+	go d.dumpStakingStateOnSignal(activeParticipantsQuerier)
+	go d.invalidateStakingCacheOnBlocks(activeParticipantsQuerier)
 	key := &keystore.Key{PrivateKey: d.signKey}
 
 	d.logger.Info("About to process node staking...", "node_type", d.nodeType)
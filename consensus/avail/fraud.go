@@ -2,6 +2,7 @@ package avail
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
@@ -474,7 +475,10 @@ func (f *Fraud) produceBeginDisputeResolutionBlock(blockBuilderFactory block.Blo
 		"parent_block_hash", maliciousHeader.ParentHash,
 	)
 
-	err = f.availSender.SendAndWaitForStatus(blk, stypes.ExtrinsicStatus{IsInBlock: true})
+	ctx, cancel := context.WithTimeout(context.Background(), AvailSubmissionTimeout)
+	defer cancel()
+
+	_, err = f.availSender.SendAndWaitForStatus(ctx, blk, stypes.ExtrinsicStatus{IsInBlock: true})
 	if err != nil {
 		f.logger.Error("error while submitting begin dispute resolution block to avail", "error", err)
 		return nil, err
@@ -557,7 +561,10 @@ func (f *Fraud) produceSlashBlock(blockBuilderFactory block.BlockBuilderFactory,
 		"parent_block_hash", maliciousHeader.ParentHash,
 	)
 
-	err = f.availSender.SendAndWaitForStatus(blk, stypes.ExtrinsicStatus{IsInBlock: true})
+	ctx, cancel := context.WithTimeout(context.Background(), AvailSubmissionTimeout)
+	defer cancel()
+
+	_, err = f.availSender.SendAndWaitForStatus(ctx, blk, stypes.ExtrinsicStatus{IsInBlock: true})
 	if err != nil {
 		f.logger.Error("error while submitting slashing block to avail", "error", err)
 		return nil, err
@@ -2,9 +2,12 @@ package avail
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"sync/atomic"
 	"time"
 
@@ -25,11 +28,16 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
 )
 
 // availBlockWindowLen is the length of the Avail block window.
 const availBlockWindowLen = 7
 
+// availInclusionsCacheSize is the number of recently produced blocks the Avail inclusion
+// details are kept for, which covers the fraud window of the blocks still disputable.
+const availInclusionsCacheSize = 1024
+
 // TransitionInterface represents an interface for write transitions.
 type transitionInterface interface {
 	Write(txn *types.Transaction) error
@@ -61,6 +69,16 @@ type SequencerWorker struct {
 	blockProductionEnabled     *atomic.Bool
 	currentNodeSyncIndex       uint64
 
+	// blockPending is set while the data of the last block produced by this node is submitted to Avail and not
+	// finalized yet. The block is only written to the local chain once it's finalized, and the next block is built
+	// on top of it, so no block is produced in the meantime.
+	blockPending atomic.Bool
+
+	// availInclusions maps the hashes of the blocks produced by this node to the
+	// *avail.SubmitResult of their submission, the Avail block and extrinsic
+	// index including their data.
+	availInclusions *lru.Cache
+
 	// availBlockNumWhenStaked is a used to fence the sequencing logic until
 	// this node is staked and there is a start of a fresh new Avail block window.
 	// Point type is used intentionally. `nil` means that this node has not staked
@@ -341,21 +359,24 @@ func (sw *SequencerWorker) processStorageSnapshot(ss *snapshot.Snapshot) error {
 // It returns an error if one occurs during the process.
 func (sw *SequencerWorker) ensureEnoughAvailBalance() error {
 	balance, err := avail.GetBalance(sw.availClient, sw.availAccount)
-	if err != nil {
+	if errors.Is(err, avail.ErrAccountNotFound) {
+		// The account is created by the first deposit.
+		balance = big.NewInt(0)
+	} else if err != nil {
 		return err
 	}
 
 	// If balance is less than 5 AVL, deposit more.
-	if balance.Uint64() < 5 {
-		maxUint64 := uint64(^uint64(0) >> 1)
-		sw.logger.Info("account balance for Avail account has dropped below 5 AVL; depositing more tokens", "balance", float64(balance.Uint64()/avail.AVL), "deposit", float64(maxUint64/avail.AVL))
+	if balance.Cmp(big.NewInt(5*avail.AVL)) < 0 {
+		deposit := new(big.Int).SetUint64(^uint64(0) >> 1)
+		sw.logger.Info("account balance for Avail account has dropped below 5 AVL; depositing more tokens", "balance", avail.FormatAVL(balance), "deposit", avail.FormatAVL(deposit))
 
-		err := avail.DepositBalance(sw.availClient, sw.availAccount, maxUint64, 0)
+		_, err := avail.DepositAmountFromDevFunder(context.Background(), sw.availClient, sw.availAccount, deposit, avail.SubmitOpts{})
 		if err != nil {
 			return err
 		}
 	} else {
-		sw.logger.Info("account balance for Avail account healthy", "balance", balance.Uint64())
+		sw.logger.Info("account balance for Avail account healthy", "balance", avail.FormatAVL(balance))
 	}
 
 	return nil
@@ -364,12 +385,15 @@ func (sw *SequencerWorker) ensureEnoughAvailBalance() error {
 // runWriteBlocksLoop runs a loop that produces blocks at an interval defined in the blockProductionIntervalSec config option.
 // The loop listens for a tick from a ticker and a signal from the close channel.
 // When it receives a tick and block production is enabled, and the chain is not disabled,
-// and the current worker is the next sequencer, it writes a block.
+// and the current worker is the next sequencer, it writes a block, unless the previous block is still
+// waiting for Avail finality.
 // When it receives a signal from the close channel, it stops the loop.
 func (sw *SequencerWorker) runWriteBlocksLoop(activeSequencersQuerier staking.ActiveSequencers, fraudResolver *Fraud, myAccount accounts.Account, signKey *keystore.Key) {
 	t := time.NewTicker(time.Duration(sw.blockProductionIntervalSec) * time.Second)
 	defer t.Stop()
 
+	quorumGuard := staking.NewQuorumGuard(sw.apq, int(staking.MinSequencerCount), sw.logger)
+
 	for {
 		select {
 		case <-t.C:
@@ -377,6 +401,17 @@ func (sw *SequencerWorker) runWriteBlocksLoop(activeSequencersQuerier staking.Ac
 				continue
 			}
 
+			// The next block is built on top of the previous one, which is written once its data is finalized.
+			if sw.blockPending.Load() {
+				sw.logger.Debug("previous block is waiting for Avail finality; not producing a block")
+				continue
+			}
+
+			// Don't produce blocks while there aren't enough active sequencers.
+			if !quorumGuard.Check() {
+				continue
+			}
+
 			// Means we are processing the disputed (fraud) block verification and should not create new
 			// blocks anywhere...
 			if fraudResolver.IsChainDisabled() {
@@ -410,9 +445,11 @@ func (sw *SequencerWorker) runWriteBlocksLoop(activeSequencersQuerier staking.Ac
 }
 
 // writeBlock writes a block.
-// It generates a new block based on transactions from the pool, and writes the block to the blockchain.
-// It also distributes the snapshot of the block to other sequencers over P2P.
-// It returns an error if one occurs during the process.
+// It generates a new block based on transactions from the pool, and submits the block data to Avail.
+// It returns as soon as the data is accepted by the Avail transaction pool, the finality of the data is
+// awaited in the background by awaitBlockFinality, which then writes the block to the blockchain and
+// distributes the snapshot of the block to other sequencers over P2P.
+// It returns an error if one occurs before the block data is accepted.
 func (sw *SequencerWorker) writeBlock(fraudResolver *Fraud, myAccount accounts.Account, signKey *keystore.Key) error {
 	parent := sw.blockchain.Header()
 
@@ -453,8 +490,15 @@ func (sw *SequencerWorker) writeBlock(fraudResolver *Fraud, myAccount accounts.A
 	sw.snapshotter.Begin()
 
 	// Ensure that snapshotter is not gathering changes in case an error occurs
-	// during block generation.
-	defer func() { sw.snapshotter.End() }()
+	// during block generation. Once the block data is submitted, the snapshot
+	// is ended by awaitBlockFinality.
+	submitted := false
+
+	defer func() {
+		if !submitted {
+			sw.snapshotter.End()
+		}
+	}()
 
 	transition, err := sw.executor.BeginTxn(parent.StateRoot, header, types.StringToAddress(myAccount.Address.Hex()))
 	if err != nil {
@@ -513,19 +557,104 @@ func (sw *SequencerWorker) writeBlock(fraudResolver *Fraud, myAccount accounts.A
 		"block_parent_hash", blk.ParentHash(),
 	)
 
-	// Submit block without waiting for status.
-	err = sw.availSender.SendAndWaitForStatus(blk, avail_types.ExtrinsicStatus{IsInBlock: true})
+	// The block data is finalized before the block is written, as an Avail block including it can be retracted on
+	// a fork. The finality is awaited in the background, so the block production loop isn't held up for it.
+	ctx, cancel := context.WithTimeout(context.Background(), AvailSubmissionTimeout)
+
+	pending, err := sw.availSender.SendAsync(ctx, blk, avail_types.ExtrinsicStatus{IsFinalized: true})
 	if err != nil {
+		cancel()
+
+		var exceeded *avail.FeeBudgetExceededError
+		if errors.As(err, &exceeded) {
+			sw.logger.Error(
+				"Withholding block submission to avail: the Avail fee budget is exhausted. Check for a runaway block production loop, or raise the fee budget of the node (--avail-max-fee, --avail-fee-budget and --avail-fee-budget-window) if the spend is expected",
+				"block_number", blk.Number(),
+				"fee", avail.FormatAVL(exceeded.Fee),
+				"spent", avail.FormatAVL(exceeded.Usage.Spent),
+				"submissions", exceeded.Usage.Submissions,
+				"window", exceeded.Usage.Window,
+				"retry_at", exceeded.RetryAt,
+				"error", err,
+			)
+
+			return err
+		}
+
 		sw.logger.Error("Error while submitting data to avail", "error", err)
 		return err
 	}
 
+	submitted = true
+	sw.blockPending.Store(true)
+
+	sw.logger.Debug(
+		"Block data accepted by avail, waiting for finality in the background",
+		"block_number", blk.Number(),
+		"block_hash", blk.Hash(),
+		"avail_extrinsic_hash", pending.ExtrinsicHash.Hex(),
+		"avail_nonce", pending.Nonce,
+	)
+
+	go sw.awaitBlockFinality(blk, pending, cancel)
+
+	return nil
+}
+
+// awaitBlockFinality waits for the outcome of the submission of the block data to Avail, and writes the block
+// once the data is finalized, see finishBlock. The submission stops being watched when the worker is closed.
+// Block production resumes once it returns.
+func (sw *SequencerWorker) awaitBlockFinality(blk *types.Block, pending *avail.PendingSubmission, cancel context.CancelFunc) {
+	defer sw.blockPending.Store(false)
+	defer cancel()
+
+	var outcome avail.PendingResult
+
+	select {
+	case outcome = <-pending.Result():
+	case <-sw.closeCh:
+		sw.logger.Debug("received stop signal; no longer waiting for block data finality", "block_number", blk.Number())
+		pending.Cancel()
+
+		outcome = <-pending.Result()
+	}
+
+	if err := sw.finishBlock(blk, outcome); err != nil {
+		sw.logger.Error("failed to mine block", "block_number", blk.Number(), "error", err)
+	}
+}
+
+// finishBlock handles the outcome of the submission of the block data to Avail.
+// Once the data is finalized, it writes the block to the blockchain and distributes the snapshot of
+// the block, begun by writeBlock, to other sequencers over P2P.
+// It returns an error if the submission failed or one occurs during the process.
+func (sw *SequencerWorker) finishBlock(blk *types.Block, outcome avail.PendingResult) error {
+	ended := false
+
+	defer func() {
+		if !ended {
+			sw.snapshotter.End()
+		}
+	}()
+
+	if outcome.Err != nil {
+		sw.logger.Error("Error while submitting data to avail", "block_number", blk.Number(), "error", outcome.Err)
+		return outcome.Err
+	}
+
+	inclusion := outcome.Result
+
+	sw.availInclusions.Add(blk.Hash(), inclusion)
+
 	sw.logger.Info(
 		"Block successfully sent to avail. Writing block to local chain...",
 		"sequencer_node_addr", sw.nodeAddr,
 		"block_number", blk.Number(),
 		"block_hash", blk.Hash(),
 		"block_parent_hash", blk.ParentHash(),
+		"avail_block_number", inclusion.BlockNumber,
+		"avail_block_hash", inclusion.BlockHash,
+		"avail_extrinsic_index", inclusion.ExtrinsicIndex,
 	)
 
 	// Write the block to the blockchain
@@ -546,6 +675,7 @@ func (sw *SequencerWorker) writeBlock(fraudResolver *Fraud, myAccount accounts.A
 
 	// Gather changes from EVM and blockchain storages.
 	snapshot := sw.snapshotter.End()
+	ended = true
 
 	// Augment the snapshot with block metadata.
 	snapshot.BlockNumber = blk.Header.Number
@@ -553,8 +683,7 @@ func (sw *SequencerWorker) writeBlock(fraudResolver *Fraud, myAccount accounts.A
 	snapshot.StateRoot = blk.Header.StateRoot
 
 	// Distribute snapshot to other sequencers over P2P.
-	err = sw.snapshotDistributor.Send(snapshot)
-	if err != nil {
+	if err := sw.snapshotDistributor.Send(snapshot); err != nil {
 		return err
 	}
 
@@ -614,6 +743,17 @@ func (sw *SequencerWorker) writeTransactions(fraudResolver *Fraud, gasLimit uint
 	return successful
 }
 
+// AvailInclusion returns the details of the Avail inclusion of the block produced by this node,
+// if it's one of the last availInclusionsCacheSize ones.
+func (sw *SequencerWorker) AvailInclusion(hash types.Hash) (*avail.SubmitResult, bool) {
+	inclusion, ok := sw.availInclusions.Get(hash)
+	if !ok {
+		return nil, false
+	}
+
+	return inclusion.(*avail.SubmitResult), true
+}
+
 // NewSequencer creates a new SequencerWorker.
 // It returns an error if one occurs during the creation.
 func NewSequencer(
@@ -649,6 +789,13 @@ func NewSequencer(
 		closeCh:                    closeCh,
 	}
 
+	var err error
+
+	sw.availInclusions, err = lru.New(availInclusionsCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(fraudListenerAddr) > 0 {
 		go func() {
 			err := sw.fraudServer.ListenAndServe(fraudListenerAddr)
@@ -1,6 +1,7 @@
 package avail
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -8,13 +9,16 @@ import (
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/crypto"
+	"github.com/0xPolygon/polygon-edge/types"
 	stypes "github.com/centrifuge/go-substrate-rpc-client/v4/types"
 
 	"github.com/availproject/op-evm/pkg/block"
-	"github.com/availproject/op-evm/pkg/common"
 	"github.com/availproject/op-evm/pkg/staking"
 )
 
+// defaultStakeTokens is the amount of tokens the node stakes, unless the stake requirement is higher.
+const defaultStakeTokens = 10
+
 // ensureStaked verifies whether a node is staked in the network.
 // It takes as arguments a WaitGroup and an ActiveParticipants object.
 // It determines the node type and checks if the node is under probation.
@@ -54,14 +58,43 @@ func (d *Avail) ensureStaked(wg *sync.WaitGroup, activeParticipantsQuerier staki
 		return nil
 	}
 
+	// Slashed participants must never be re-admitted automatically.
+	slashed, err := activeParticipantsQuerier.IsSlashed(d.minerAddr)
+	if err != nil {
+		d.logger.Error("failed to check if participant has been slashed", "error", err)
+		return err
+	}
+
+	// Sequencers and watchtowers may have different stake requirements, older contracts only
+	// have the global staking threshold.
+	requirement, err := activeParticipantsQuerier.GetStakeRequirement(nodeType)
+	if err != nil && !errors.Is(err, staking.ErrSingleStakeThreshold) {
+		d.logger.Error("failed to query stake requirement", "node_type", nodeType, "error", err)
+		return err
+	}
+
+	stakeAmount := staking.ToStakeUnits(defaultStakeTokens)
+	if requirement != nil && requirement.Cmp(stakeAmount) > 0 {
+		d.logger.Info("staking the stake requirement instead of the default stake", "node_type", nodeType, "requirement", requirement)
+		stakeAmount = requirement
+	}
+
+	stakeOpts := []staking.StakeTxOption{
+		staking.WithStakeRequirement(requirement),
+	}
+
+	if slashed {
+		stakeOpts = append(stakeOpts, staking.WithSlashedParticipants([]types.Address{d.minerAddr}))
+	}
+
 	switch MechanismType(d.nodeType) {
 	case BootstrapSequencer:
 		// Staking smart contract does not support `BootstrapSequencer` MachineType.
-		if returnErr := d.stakeParticipant(false, Sequencer.String()); returnErr != nil {
+		if returnErr := d.stakeParticipant(false, Sequencer.String(), stakeAmount, stakeOpts...); returnErr != nil {
 			return returnErr
 		}
 	case Sequencer, WatchTower:
-		staked, returnErr := d.stakeParticipantThroughTxPool(activeParticipantsQuerier)
+		staked, returnErr := d.stakeParticipantThroughTxPool(activeParticipantsQuerier, stakeAmount, stakeOpts...)
 		if returnErr != nil {
 			return returnErr
 		}
@@ -76,11 +109,12 @@ func (d *Avail) ensureStaked(wg *sync.WaitGroup, activeParticipantsQuerier staki
 
 // stakeParticipant stakes a participant in the network.
 // It takes as arguments a boolean value indicating whether to wait for discovery of additional peers
-// before pushing the block towards the rest of the community, and a string representing the node type.
+// before pushing the block towards the rest of the community, a string representing the node type,
+// the stake amount in wei and the options of the stake transaction.
 // It first builds a staking block, signs it, and then submits it to the Avail network.
 // After a successful submission, it writes the block to the local blockchain.
 // Function is used only if staked participant is bootstrap sequencer.
-func (d *Avail) stakeParticipant(shouldWait bool, nodeType string) error {
+func (d *Avail) stakeParticipant(shouldWait bool, nodeType string, stakeAmount *big.Int, opts ...staking.StakeTxOption) error {
 	// Bootnode does not need to wait for any additional peers to be discovered prior pushing the
 	// block towards rest of the community, however, sequencers and watchtowers must!
 	if shouldWait {
@@ -104,8 +138,7 @@ func (d *Avail) stakeParticipant(shouldWait bool, nodeType string) error {
 	bb.SetCoinbaseAddress(d.minerAddr)
 	bb.SignWith(d.signKey)
 
-	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), common.ETH)
-	tx, err := staking.StakeTx(d.minerAddr, stakeAmount, nodeType, 1_000_000)
+	tx, err := staking.StakeTx(d.minerAddr, stakeAmount, nodeType, 1_000_000, opts...)
 	if err != nil {
 		return err
 	}
@@ -124,7 +157,10 @@ func (d *Avail) stakeParticipant(shouldWait bool, nodeType string) error {
 	}
 
 	d.logger.Debug("sending block with staking tx to Avail")
-	err = d.availSender.SendAndWaitForStatus(blk, stypes.ExtrinsicStatus{IsInBlock: true})
+	ctx, cancel := context.WithTimeout(context.Background(), AvailSubmissionTimeout)
+	defer cancel()
+
+	_, err = d.availSender.SendAndWaitForStatus(ctx, blk, stypes.ExtrinsicStatus{IsInBlock: true})
 	if err != nil {
 		d.logger.Error("error while submitting data to avail", "error", err)
 		return err
@@ -144,13 +180,13 @@ func (d *Avail) stakeParticipant(shouldWait bool, nodeType string) error {
 }
 
 // stakeParticipantThroughTxPool stakes a participant through the transaction pool.
-// It takes as argument an ActiveParticipants object.
+// It takes as arguments an ActiveParticipants object, the stake amount in wei and the options of the stake transaction.
 // Before proceeding, it checks for network connection.
 // It creates and signs a staking transaction, and attempts to add it to the transaction pool,
 // retrying up to 10 times if unsuccessful. If successful, it waits for the main sequencer loop
 // to do the synchronization.
 // Function is used only if staked participant is sequencer or watchtower.
-func (d *Avail) stakeParticipantThroughTxPool(activeParticipantsQuerier staking.ActiveParticipants) (bool, error) {
+func (d *Avail) stakeParticipantThroughTxPool(activeParticipantsQuerier staking.ActiveParticipants, stakeAmount *big.Int, opts ...staking.StakeTxOption) (bool, error) {
 	// We need to have at least one node available to be able successfully push tx
 	// to the neighborhood peers.
 	for d.network == nil || d.network.GetBootnodeConnCount() < 1 {
@@ -164,8 +200,7 @@ func (d *Avail) stakeParticipantThroughTxPool(activeParticipantsQuerier staking.
 	// txpool tx will be added but bootstrap sequencer won't receive it.
 	time.Sleep(5 * time.Second)
 
-	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), common.ETH)
-	tx, err := staking.StakeTx(d.minerAddr, stakeAmount, d.nodeType.String(), 1_000_000)
+	tx, err := staking.StakeTx(d.minerAddr, stakeAmount, d.nodeType.String(), 1_000_000, opts...)
 	if err != nil {
 		return false, err
 	}
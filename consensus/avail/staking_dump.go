@@ -0,0 +1,45 @@
+package avail
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/availproject/op-evm/pkg/staking"
+)
+
+// dumpStakingStateOnSignal logs the staking state of the network and of the node every time the
+// process receives SIGUSR1, until the node is closed. The dump is meant to be attached to support
+// requests, e.g. when a node doesn't produce blocks, and never includes the keys of the node.
+func (d *Avail) dumpStakingStateOnSignal(activeParticipantsQuerier staking.ActiveParticipants) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-sigCh:
+			d.dumpStakingState(activeParticipantsQuerier)
+		case <-d.closeCh:
+			return
+		}
+	}
+}
+
+// dumpStakingState logs the staking state dump as JSON.
+func (d *Avail) dumpStakingState(activeParticipantsQuerier staking.ActiveParticipants) {
+	dump, err := staking.DumpStakingState(activeParticipantsQuerier, d.minerAddr)
+	if err != nil {
+		d.logger.Error("failed to dump staking state", "error", err)
+		return
+	}
+
+	raw, err := json.Marshal(dump)
+	if err != nil {
+		d.logger.Error("failed to encode staking state dump", "error", err)
+		return
+	}
+
+	d.logger.Info("staking state dump", "dump", string(raw))
+}
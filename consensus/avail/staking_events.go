@@ -0,0 +1,30 @@
+package avail
+
+import (
+	"context"
+
+	"github.com/availproject/op-evm/pkg/staking"
+)
+
+// invalidateStakingCacheOnBlocks feeds the blocks written to the chain to the active participants querier
+// until the node is closed, so its cached results are dropped only when a block has staking activity.
+// Queriers that don't cache their results are left alone.
+func (d *Avail) invalidateStakingCacheOnBlocks(activeParticipantsQuerier staking.ActiveParticipants) {
+	handler, ok := activeParticipantsQuerier.(staking.BlockEventHandler)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-d.closeCh
+		cancel()
+	}()
+
+	sub := d.blockchain.SubscribeEvents()
+	defer sub.Close()
+
+	staking.ListenBlockEvents(ctx, sub, handler)
+}
@@ -1,6 +1,8 @@
 package avail
 
 import (
+	"context"
+	"errors"
 	"strings"
 
 	"github.com/0xPolygon/polygon-edge/types"
@@ -29,6 +31,7 @@ import (
 func (d *Avail) runWatchTower(activeParticipantsQuerier staking.ActiveParticipants, currentNodeSyncIndex uint64, myAccount accounts.Account, signKey *keystore.Key) {
 	logger := d.logger.Named("watchtower")
 	watchTower := watchtower.New(d.blockchain, d.executor, d.txpool, logger, types.Address(myAccount.Address), signKey.PrivateKey)
+	disputeQuerier := staking.NewDisputeQuerier(d.blockchain, d.executor, logger)
 
 	// Start watching HEAD from Avail.
 	availBlockStream := d.availClient.BlockStream(currentNodeSyncIndex)
@@ -95,6 +98,20 @@ func (d *Avail) runWatchTower(activeParticipantsQuerier staking.ActiveParticipan
 						continue blksLoop
 					}
 
+					// Skip blocks for which a fraud proof was already submitted. Contracts
+					// without dispute records, like the deployed one, can't tell; that isn't
+					// an answer, the dispute is unknown and the fraud proof is submitted anyway.
+					_, disputed, disputeErr := disputeQuerier.DisputeForBlock(blk.Header.Hash)
+					switch {
+					case errors.Is(disputeErr, staking.ErrUnsupportedByContract):
+						logger.Warn("staking contract doesn't keep dispute records, can't tell whether the block is already disputed; submitting fraudproof anyway", "block_number", blk.Header.Number, "block_hash", blk.Header.Hash, "error", disputeErr)
+					case disputeErr != nil:
+						logger.Error("failed to query dispute for block", "block_number", blk.Header.Number, "block_hash", blk.Header.Hash, "error", disputeErr)
+					case disputed:
+						logger.Info("Block is already disputed; skipping fraudproof", "block_number", blk.Header.Number, "block_hash", blk.Header.Hash)
+						continue blksLoop
+					}
+
 					logger.Info("Block verification failed. constructing fraudproof", "block_number", blk.Header.Number, "block_hash", blk.Header.Hash, "error", err)
 
 					fp, err := watchTower.ConstructFraudproof(blk)
@@ -105,7 +122,10 @@ func (d *Avail) runWatchTower(activeParticipantsQuerier staking.ActiveParticipan
 
 					logger.Info("Submitting fraudproof", "block_hash", fp.Header.Hash)
 
-					err = d.availSender.SendAndWaitForStatus(fp, avail_types.ExtrinsicStatus{IsInBlock: true})
+					ctx, cancel := context.WithTimeout(context.Background(), AvailSubmissionTimeout)
+					_, err = d.availSender.SendAndWaitForStatus(ctx, fp, avail_types.ExtrinsicStatus{IsInBlock: true})
+					cancel()
+
 					if err != nil {
 						logger.Error("Submitting fraud proof to avail failed", "error", err)
 						continue blksLoop
@@ -6,9 +6,12 @@ replace github.com/centrifuge/go-substrate-rpc-client/v4 => github.com/availproj
 
 require (
 	github.com/0xPolygon/polygon-edge v1.0.0-rc1
+	github.com/ChainSafe/go-schnorrkel v1.0.0
 	github.com/armon/go-metrics v0.4.1
 	github.com/availproject/op-evm-contracts v0.0.1-alpha2
 	github.com/centrifuge/go-substrate-rpc-client/v4 v4.0.3
+	github.com/consensys/gnark-crypto v0.5.3
+	github.com/decred/base58 v1.0.3
 	github.com/ethereum/go-ethereum v1.10.26
 	github.com/google/go-cmp v0.5.9
 	github.com/hashicorp/go-hclog v1.5.0
@@ -16,6 +19,7 @@ require (
 	github.com/hashicorp/hcl v1.0.0
 	github.com/ipfs/go-log/v2 v2.5.1
 	github.com/juju/ansiterm v1.0.0
+	github.com/klauspost/compress v1.15.12
 	github.com/libp2p/go-libp2p v0.25.0
 	github.com/multiformats/go-multiaddr v0.9.0
 	github.com/prometheus/client_golang v1.15.1
@@ -27,6 +31,7 @@ require (
 	github.com/umbracle/ethgo v0.1.4-0.20230524094434-7700cae3ef42
 	github.com/umbracle/fastrlp v0.1.1-0.20230504065717-58a1b8a9929d
 	github.com/vedhavyas/go-subkey v1.0.3
+	golang.org/x/crypto v0.9.0
 	google.golang.org/grpc v1.55.0
 	google.golang.org/protobuf v1.30.0
 	gopkg.in/DataDog/dd-trace-go.v1 v1.51.0
@@ -40,7 +45,6 @@ require (
 	cloud.google.com/go/secretmanager v1.11.0 // indirect
 	filippo.io/edwards25519 v1.0.0-rc.1 // indirect
 	github.com/0xPolygon/go-ibft v0.4.1-0.20230418151118-337b82c3c3c4 // indirect
-	github.com/ChainSafe/go-schnorrkel v1.0.0 // indirect
 	github.com/DataDog/appsec-internal-go v1.0.0 // indirect
 	github.com/DataDog/datadog-agent/pkg/obfuscate v0.45.0-rc.1 // indirect
 	github.com/DataDog/datadog-agent/pkg/remoteconfig/state v0.45.0-rc.1 // indirect
@@ -63,14 +67,12 @@ require (
 	github.com/cenkalti/backoff/v3 v3.2.2 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/coinbase/kryptology v1.8.0 // indirect
-	github.com/consensys/gnark-crypto v0.5.3 // indirect
 	github.com/containerd/cgroups v1.0.4 // indirect
 	github.com/coreos/go-systemd/v22 v22.5.0 // indirect
 	github.com/cosmos/go-bip39 v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/davidlazar/go-crypto v0.0.0-20200604182044-b73af7476f6c // indirect
 	github.com/deckarep/golang-set v1.8.0 // indirect
-	github.com/decred/base58 v1.0.3 // indirect
 	github.com/decred/dcrd/crypto/blake256 v1.0.0 // indirect
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.1.0 // indirect
 	github.com/docker/go-units v0.5.0 // indirect
@@ -119,7 +121,6 @@ require (
 	github.com/jackpal/go-nat-pmp v1.0.2 // indirect
 	github.com/jbenet/go-temp-err-catcher v0.1.0 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
-	github.com/klauspost/compress v1.15.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.1 // indirect
 	github.com/koron/go-ssdp v0.0.3 // indirect
 	github.com/libp2p/go-buffer-pool v0.1.0 // indirect
@@ -200,7 +201,6 @@ require (
 	go.uber.org/zap v1.24.0 // indirect
 	go4.org/intern v0.0.0-20211027215823-ae77deb06f29 // indirect
 	go4.org/unsafe/assume-no-moving-gc v0.0.0-20220617031537-928513b29760 // indirect
-	golang.org/x/crypto v0.9.0 // indirect
 	golang.org/x/exp v0.0.0-20221205204356-47842c84f3db // indirect
 	golang.org/x/mod v0.10.0 // indirect
 	golang.org/x/net v0.10.0 // indirect
@@ -1,218 +1,1094 @@
 package avail
 
 import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
-	"os"
+	"strings"
+	"sync"
+	"time"
 
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
 	"github.com/tyler-smith/go-bip39"
 )
 
 const (
-	// 1 AVL == 10^18 Avail fractions.
+	// 1 AVL == 10^18 Avail fractions. Only about 9.2 AVL fit in an int64, and 18.4 AVL in a uint64, so larger
+	// amounts are computed with big.Int, see AVLToFractions.
 	AVL = 1_000_000_000_000_000_000
+
+	// CallTransfer is the RPC API call transferring Avail tokens, which reaps the sender account if its balance
+	// drops below the existential deposit. It's only used with SubmitOpts.AllowDeath.
+	CallTransfer = "Balances.transfer"
+
+	// CallTransferKeepAlive is the RPC API call transferring Avail tokens, which fails instead of reaping the
+	// sender account. It's the call of the transfers by default.
+	CallTransferKeepAlive = "Balances.transfer_keep_alive"
+
+	// CallTransferAll is the RPC API call transferring the whole transferable balance of the sender account.
+	CallTransferAll = "Balances.transfer_all"
 )
 
-// NewAccount generates a new Avail account by creating a mnemonic phrase and deriving the key pair.
+// ErrAccountNotFound is the error returned when the account doesn't exist on the Avail network.
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrStatePruned is the error returned when the state at a past block was discarded by the Avail node, which
+// only keeps the state of the recent blocks unless it's an archive node.
+var ErrStatePruned = errors.New("state pruned")
+
+// prunedStateErrors are the messages of the errors of the Avail node for a state it discarded.
+var prunedStateErrors = []string{"State already discarded"}
+
+// ErrInsufficientBalance is the error returned by Transfer when the sender can't afford the transfer and its fee.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// ErrBelowExistentialDeposit is the error returned by DepositAmount when the balance of the recipient account
+// would stay below the existential deposit, in which case the chain doesn't create the account.
+var ErrBelowExistentialDeposit = errors.New("below existential deposit")
+
+// AccountData is the state of an Avail account. The balances are in Avail fractions.
+type AccountData struct {
+	// Free is the balance that can be transferred and used for fees.
+	Free *big.Int
+	// Reserved is the balance reserved by the runtime, e.g. as a deposit, which can't be used until it's unreserved.
+	Reserved *big.Int
+	// MiscFrozen is the part of the free balance that can't be used for anything but transaction fees.
+	MiscFrozen *big.Int
+	// FeeFrozen is the part of the free balance that can't be used for transaction fees.
+	FeeFrozen *big.Int
+	// Nonce is the number of transactions sent by the account.
+	Nonce uint64
+}
+
+// Transferable returns the part of the free balance that can be transferred, i.e. that isn't frozen.
+func (d *AccountData) Transferable() *big.Int {
+	frozen := d.MiscFrozen
+	if d.FeeFrozen.Cmp(frozen) > 0 {
+		frozen = d.FeeFrozen
+	}
+
+	transferable := new(big.Int).Sub(d.Free, frozen)
+	if transferable.Sign() < 0 {
+		return big.NewInt(0)
+	}
+
+	return transferable
+}
+
+// DefaultMnemonicStrength is the entropy, in bits, of the mnemonic generated by NewAccount, i.e. 12 words.
+const DefaultMnemonicStrength = 128
+
+// ErrInvalidMnemonicStrength is the error returned when the requested mnemonic entropy isn't supported.
+var ErrInvalidMnemonicStrength = errors.New("invalid mnemonic strength")
+
+// GeneratedAccount is a newly generated Avail account together with the mnemonic it was derived from.
+// The mnemonic is the only way to recover the account, so it has to be persisted.
+type GeneratedAccount struct {
+	KeyPair  signature.KeyringPair
+	Mnemonic string
+}
+
+// NewAccount generates a new Avail account by creating a 12-word mnemonic phrase and deriving the key pair,
+// with an address on the network of the optional SS58 prefix, DefaultSS58Prefix by default.
 // It returns the generated key pair and an error if there is an issue.
-func NewAccount() (signature.KeyringPair, error) {
-	entropy, err := bip39.NewEntropy(128)
+// Use NewAccountWithStrength to get the mnemonic as well.
+func NewAccount(prefix ...uint16) (signature.KeyringPair, error) {
+	account, err := NewAccountWithStrength(DefaultMnemonicStrength)
 	if err != nil {
 		return signature.KeyringPair{}, err
 	}
 
+	return withSS58Prefix(account.KeyPair, ss58PrefixOr(prefix))
+}
+
+// NewAccountWithStrength generates a new Avail account by creating a mnemonic phrase from entropy of
+// the given number of bits and deriving the key pair. The supported strengths are 128, 160, 192, 224
+// and 256 bits, which give 12, 15, 18, 21 and 24-word mnemonics respectively.
+// It returns the generated account with its mnemonic, and an error wrapping ErrInvalidMnemonicStrength
+// if the strength isn't supported.
+func NewAccountWithStrength(bits int) (*GeneratedAccount, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return nil, fmt.Errorf("%w: %d bits, must be one of 128, 160, 192, 224 or 256", ErrInvalidMnemonicStrength, bits)
+	}
+
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return nil, err
+	}
+
 	mnemonic, err := bip39.NewMnemonic(entropy)
 	if err != nil {
-		return signature.KeyringPair{}, err
+		return nil, err
 	}
 
-	keyPair, err := signature.KeyringPairFromSecret(mnemonic, 42)
+	keyPair, err := NewAccountFromMnemonic(mnemonic)
 	if err != nil {
-		return signature.KeyringPair{}, err
+		return nil, err
 	}
 
-	return keyPair, nil
+	return &GeneratedAccount{KeyPair: keyPair, Mnemonic: mnemonic}, nil
 }
 
-// NewAccountFromMnemonic generates an Avail account using the provided mnemonic phrase.
-// It returns the generated key pair and an error if there is an issue.
-func NewAccountFromMnemonic(mnemonic string) (signature.KeyringPair, error) {
-	keyPair, err := signature.KeyringPairFromSecret(mnemonic, 42)
+// ErrInvalidMnemonic is the error returned when a mnemonic phrase isn't a valid BIP39 mnemonic.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// ValidateMnemonic checks that the mnemonic phrase is a valid BIP39 mnemonic of the English wordlist: it has
+// 12, 15, 18, 21 or 24 words, all of them in the wordlist, and its checksum matches. The whitespace around and
+// between the words, e.g. the trailing newline of an account file, is ignored.
+// It returns an error wrapping ErrInvalidMnemonic telling the first issue found, e.g. the position of a misspelled
+// word, or nil if the mnemonic is valid.
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return fmt.Errorf("%w: %d words, must be 12, 15, 18, 21 or 24", ErrInvalidMnemonic, len(words))
+	}
+
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			return fmt.Errorf("%w: word %d '%s' is not a valid BIP39 word", ErrInvalidMnemonic, i+1, word)
+		}
+	}
+
+	if _, err := bip39.EntropyFromMnemonic(strings.Join(words, " ")); err != nil {
+		return fmt.Errorf("%w: checksum mismatch, a word may be wrong or out of order", ErrInvalidMnemonic)
+	}
+
+	return nil
+}
+
+// NewAccountFromMnemonic generates an Avail account using the provided mnemonic phrase, with an address on
+// the network of the optional SS58 prefix, DefaultSS58Prefix by default. The whitespace around and between
+// the words is ignored.
+// It returns the generated key pair, and an error wrapping ErrInvalidMnemonic if the mnemonic isn't valid, see
+// ValidateMnemonic, or an error if there is an issue.
+func NewAccountFromMnemonic(mnemonic string, prefix ...uint16) (signature.KeyringPair, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return signature.KeyringPair{}, err
+	}
+
+	keyPair, err := signature.KeyringPairFromSecret(strings.Join(strings.Fields(mnemonic), " "), 42)
 	if err != nil {
 		return signature.KeyringPair{}, err
 	}
 
-	return keyPair, nil
+	return withSS58Prefix(keyPair, ss58PrefixOr(prefix))
 }
 
-// AccountFromFile reads an Avail account from a file containing the mnemonic phrase.
-// It returns the generated key pair and an error if there is an issue.
-func AccountFromFile(filePath string) (signature.KeyringPair, error) {
-	accountBytes, err := os.ReadFile(filePath)
+// ErrInvalidAccountURI is the error returned when an account URI is malformed.
+var ErrInvalidAccountURI = errors.New("invalid account URI")
+
+// NewAccountFromURI generates an Avail account from a substrate-style secret URI, with an address on the network
+// of the SS58 prefix.
+// The URI is a mnemonic phrase or a 0x-prefixed hex seed, optionally followed by derivation junctions,
+// `//hard` or `/soft`, and a `///password` suffix, e.g. "<mnemonic>//sequencer//0".
+// Unlike subkey, the URI must start with the phrase or seed, so a missing phrase isn't replaced by the dev phrase.
+// It returns the generated key pair, and an error wrapping ErrInvalidAccountURI if the URI is malformed.
+func NewAccountFromURI(uri string, prefix uint16) (signature.KeyringPair, error) {
+	normalized, err := normalizeAccountURI(uri)
 	if err != nil {
-		return signature.KeyringPair{}, fmt.Errorf("failure to read account file '%s'", err)
+		return signature.KeyringPair{}, err
 	}
 
-	availAccount, err := NewAccountFromMnemonic(string(accountBytes))
+	keyPair, err := signature.KeyringPairFromSecret(normalized, 42)
 	if err != nil {
-		return signature.KeyringPair{}, err
+		return signature.KeyringPair{}, fmt.Errorf("%w: %s", ErrInvalidAccountURI, err)
 	}
 
-	return availAccount, nil
+	return withSS58Prefix(keyPair, prefix)
 }
 
-// AccountExistsFromMnemonic checks if an Avail account exists on the blockchain using the provided mnemonic phrase.
-// It takes a client and the file path of the mnemonic phrase, and returns a boolean indicating if the account exists and an error if there is an issue.
-func AccountExistsFromMnemonic(client Client, filePath string) (bool, error) {
-	account, err := AccountFromFile(filePath)
+// DeriveAccount derives the Avail account at the derivation path from the mnemonic phrase, e.g. "//sequencer//0",
+// with an address on the network of DefaultSS58Prefix.
+// An empty path returns the account of the mnemonic itself.
+// It returns the derived key pair, and an error wrapping ErrInvalidAccountURI if the mnemonic or the path is malformed.
+func DeriveAccount(mnemonic, path string) (signature.KeyringPair, error) {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return signature.KeyringPair{}, fmt.Errorf("%w: derivation path %q must start with '/'", ErrInvalidAccountURI, path)
+	}
+
+	return NewAccountFromURI(strings.TrimSpace(mnemonic)+path, DefaultSS58Prefix())
+}
+
+// normalizeAccountURI validates the secret URI and returns it without the whitespace around the phrase.
+func normalizeAccountURI(uri string) (string, error) {
+	phrase, rest := uri, ""
+	if i := strings.Index(uri, "/"); i >= 0 {
+		phrase, rest = uri[:i], uri[i:]
+	}
+
+	phrase = strings.Join(strings.Fields(phrase), " ")
+
+	switch {
+	case phrase == "":
+		return "", fmt.Errorf("%w: missing mnemonic phrase or seed", ErrInvalidAccountURI)
+	case strings.HasPrefix(phrase, "0x"):
+		if _, err := hex.DecodeString(phrase[2:]); err != nil || len(phrase) != 66 {
+			return "", fmt.Errorf("%w: seed must be 32 bytes of hex", ErrInvalidAccountURI)
+		}
+	default:
+		if err := ValidateMnemonic(phrase); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidAccountURI, err)
+		}
+	}
+
+	path, password := rest, ""
+	if i := strings.Index(rest, "///"); i >= 0 {
+		path, password = rest[:i], rest[i+3:]
+
+		if password == "" {
+			return "", fmt.Errorf("%w: empty password after '///'", ErrInvalidAccountURI)
+		}
+	}
+
+	for position := 1; path != ""; position++ {
+		junction := strings.TrimPrefix(strings.TrimPrefix(path, "/"), "/")
+
+		name := junction
+		if i := strings.Index(junction, "/"); i >= 0 {
+			name = junction[:i]
+		}
+
+		if name == "" {
+			return "", fmt.Errorf("%w: empty derivation junction %d in %q", ErrInvalidAccountURI, position, rest)
+		}
+
+		path = junction[len(name):]
+	}
+
+	return phrase + rest, nil
+}
+
+// AccountFromFile reads an Avail account from an account file written by SaveAccount, decrypting it with the passphrase.
+// A legacy account file holding the plain mnemonic is migrated to a JSON account file first, see LoadAccount, and
+// an unencrypted account file is encrypted in place with the passphrase if there is one.
+// It returns the generated key pair and an error if there is an issue, see LoadAccount for the errors.
+func AccountFromFile(filePath, passphrase string) (signature.KeyringPair, error) {
+	availAccount, err := LoadAccount(filePath, passphrase)
+	if !errors.Is(err, ErrPlaintextAccountFile) {
+		return availAccount, err
+	}
+
+	if err := MigrateAccount(filePath, passphrase); err != nil {
+		return signature.KeyringPair{}, fmt.Errorf("failure to encrypt account file '%s': %w", filePath, err)
+	}
+
+	return LoadAccount(filePath, passphrase)
+}
+
+// AccountExistsFromMnemonic checks if the Avail account of the account file exists on the blockchain.
+// It takes a client, the file path of the account file and its passphrase, and returns a boolean indicating if
+// the account exists and an error if there is an issue. The account file is read with AccountFromFile, and
+// the account looked up with AccountExists.
+func AccountExistsFromMnemonic(client Client, filePath, passphrase string) (bool, error) {
+	account, err := AccountFromFile(filePath, passphrase)
 	if err != nil {
 		return false, err
 	}
 
+	return AccountExists(client, account)
+}
+
+// AccountExists checks if the Avail account exists on the blockchain.
+// It takes a client and the account key pair, and returns false without an error if the account doesn't exist,
+// or an error if the lookup failed.
+func AccountExists(client Client, account signature.KeyringPair) (bool, error) {
 	api, err := instance(client)
 	if err != nil {
 		return false, err
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := latestMetadata(client, api)
 	if err != nil {
 		return false, err
 	}
 
-	key, err := types.CreateStorageKey(meta, "System", "Account", account.PublicKey, nil)
+	key, err := accountStorageKey(meta, account)
 	if err != nil {
 		return false, err
 	}
 
 	var accountInfo types.AccountInfo
-	return api.RPC.State.GetStorageLatest(key, &accountInfo)
+	ok, err := api.RPC.State.GetStorageLatest(key, &accountInfo)
+	if err != nil {
+		return false, fmt.Errorf("couldn't fetch latest account storage info: %w", err)
+	}
+
+	return ok, nil
 }
 
-// DepositBalance deposits a specified amount of Avail tokens from the specified account to the specified recipient.
-// It takes a client, the account key pair, the amount to deposit, and the nonce increment.
-// It returns an error if there is an issue.
-func DepositBalance(client Client, account signature.KeyringPair, amount, nonceIncrement uint64) error {
-	api, err := instance(client)
-	if err != nil {
-		return err
+// DepositBalance deposits a specified amount of Avail tokens, in Avail fractions, from the funder account to the
+// recipient account, see DepositAmount.
+//
+// Deprecated: Use DepositAmount, whose amount isn't capped at about 18.4 AVL by a uint64.
+func DepositBalance(ctx context.Context, client Client, funder, recipient signature.KeyringPair, amount uint64, opts SubmitOpts) (*SubmitResult, error) {
+	return DepositAmount(ctx, client, funder, recipient, new(big.Int).SetUint64(amount), opts)
+}
+
+// DepositAmount deposits a specified amount of Avail tokens, in Avail fractions, from the funder account to the
+// recipient account.
+// It takes a context bounding the wait for the inclusion of the transfer, a client, the funder and recipient key pairs,
+// the amount to deposit, and the submission options.
+// The transfer keeps the funder account alive, unless the options allow its death, see SubmitOpts.AllowDeath.
+// The transfer is signed with the next funder nonce handed out by the nonce manager of the options, or by the one of
+// the client if it's nil, and submitted with the funder account. The concurrent deposits of a funder with a client
+// are submitted one at a time, each with its own nonce, and waited for concurrently, so a funder shared by the
+// components of a process, e.g. the development funder, doesn't need coordinating. A transfer rejected for a stale
+// nonce, e.g. because another process submitted an extrinsic of the funder at the same time, is re-signed with the
+// nonce fetched from chain and resubmitted, following the retry policy of the client. If the status subscription of
+// the transfer fails, the transfer is looked for on chain, or resubmitted, following the retry policy of the client.
+// It returns the submission result locating the transfer on Avail, and an error wrapping ErrBelowExistentialDeposit
+// if the balance of the recipient would stay below the existential deposit, in which case nothing is submitted,
+// a *SubmitTimeoutError if the context is done before the inclusion, an error wrapping ErrExtrinsicRetracted or ErrFinalityTimeout if the transfer won't be
+// finalized while waiting for it, a *DispatchError if the included transfer failed, e.g. for an insufficient balance
+// of the funder, or an error if there is an issue. The result of an included transfer is returned even with an error.
+// It returns an error, without submitting anything, if the amount is nil or negative.
+func DepositAmount(ctx context.Context, client Client, funder, recipient signature.KeyringPair, amount *big.Int, opts SubmitOpts) (*SubmitResult, error) {
+	if amount == nil || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid deposit amount %v, must not be negative", amount)
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	c, err := implementation(client)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return depositBalance(ctx, c, funder, recipient, types.NewUCompact(amount), opts)
+}
+
+// devFunder is the default development funder account, set with SetDevFunder.
+var devFunder = struct {
+	lock sync.RWMutex
+	pair signature.KeyringPair
+}{pair: signature.TestKeyringPairAlice}
+
+// SetDevFunder sets the default development funder account, funding the accounts with DepositBalanceFromDevFunder
+// on local devnets and testnets, to the given key pair, e.g. the faucet account of a testnet without Alice.
+// It defaults to the Alice development account. The funder of a client set with WithDevFunder takes precedence.
+func SetDevFunder(pair signature.KeyringPair) {
+	devFunder.lock.Lock()
+	defer devFunder.lock.Unlock()
+
+	devFunder.pair = pair
+}
+
+// WithDevFunder sets the development funder account of the client, instead of the default one set with SetDevFunder.
+func WithDevFunder(pair signature.KeyringPair) ClientOption {
+	return func(c *client) {
+		c.devFunder = &pair
+	}
+}
+
+// DevFunder returns the development funder account of the client: the one set with WithDevFunder, or the default
+// one set with SetDevFunder, the Alice development account unless changed.
+func DevFunder(client Client) signature.KeyringPair {
+	if c, err := implementation(client); err == nil && c.devFunder != nil {
+		return *c.devFunder
 	}
 
-	addr, err := types.NewMultiAddressFromAccountID(account.PublicKey)
+	devFunder.lock.RLock()
+	defer devFunder.lock.RUnlock()
+
+	return devFunder.pair
+}
+
+// DepositBalanceFromDevFunder deposits a specified amount of Avail tokens, in Avail fractions, to the recipient
+// account from the development funder account of the client, see DepositAmountFromDevFunder.
+//
+// Deprecated: Use DepositAmountFromDevFunder, whose amount isn't capped at about 18.4 AVL by a uint64.
+func DepositBalanceFromDevFunder(ctx context.Context, client Client, recipient signature.KeyringPair, amount uint64, opts SubmitOpts) (*SubmitResult, error) {
+	return DepositAmountFromDevFunder(ctx, client, recipient, new(big.Int).SetUint64(amount), opts)
+}
+
+// DepositAmountFromDevFunder deposits a specified amount of Avail tokens, in Avail fractions, to the recipient
+// account from the development funder account of the client, see DevFunder. It only works on networks where the
+// funder is funded, e.g. the Alice account of local devnets.
+// It takes a context bounding the wait for the inclusion of the transfer, a client, the recipient key pair,
+// the amount to deposit, and the submission options.
+// It returns the submission result, and an error if there is an issue, see DepositAmount.
+func DepositAmountFromDevFunder(ctx context.Context, client Client, recipient signature.KeyringPair, amount *big.Int, opts SubmitOpts) (*SubmitResult, error) {
+	return DepositAmount(ctx, client, DevFunder(client), recipient, amount, opts)
+}
+
+// depositBalance signs the transfer from the funder to the recipient and submits it with the given client.
+func depositBalance(ctx context.Context, c *client, funder, recipient signature.KeyringPair, amount types.UCompact, opts SubmitOpts) (*SubmitResult, error) {
+	api := c.instance()
+
+	meta, err := c.metadata(api)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c, err := types.NewCall(meta, "Balances.transfer", addr, types.NewUCompactFromUInt(amount))
+	constants, err := c.constantsOf(meta)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Create the extrinsic
-	ext := types.NewExtrinsic(c)
+	if err := checkExistentialDeposit(api, meta, constants.ExistentialDeposit, recipient, (*big.Int)(&amount)); err != nil {
+		return nil, err
+	}
+
+	callName := transferCall(opts)
 
-	genesisHash, err := api.RPC.Chain.GetBlockHash(0)
+	ext, err := newTransfer(meta, callName, recipient.PublicKey, amount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rv, err := api.RPC.State.GetRuntimeVersionLatest()
+	if opts.Nonces == nil {
+		opts.Nonces = c.funderNonces
+	}
+
+	start := time.Now()
+
+	// Sign the transaction using the funder account, and send it. The lock is only held until the transfer is in
+	// the transaction pool, so the nonces reach the pool in order.
+	lock := c.funderLock(funder)
+	lock.Lock()
+
+	sub, signed, nonce, err := c.signAndWatch(ctx, api, meta, funder, opts, func(nonce uint64) (types.Extrinsic, error) {
+		signed := ext
+		err := c.signTransfer(api, &signed, funder, nonce, opts)
+
+		return signed, err
+	})
+	lock.Unlock()
+
+	var result *SubmitResult
+	if err == nil {
+		result, err = c.awaitInclusion(ctx, sub, signed, funder, nonce, opts)
+	}
+
+	c.recordSubmission(callName, opts.WaitFor, start, err)
+
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return result, c.dispatchOutcome(c.instance().RPC, meta, result)
+}
+
+// funderLock returns the lock serializing the deposits of the funder account.
+func (c *client) funderLock(funder signature.KeyringPair) *sync.Mutex {
+	lock, _ := c.funderLocks.LoadOrStore(string(funder.PublicKey), &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// transferCall returns the name of the transfer call of the submission options, CallTransferKeepAlive unless
+// the sender account is allowed to be reaped.
+func transferCall(opts SubmitOpts) string {
+	if opts.AllowDeath {
+		return CallTransfer
 	}
 
-	key, err := types.CreateStorageKey(meta, "System", "Account", signature.TestKeyringPairAlice.PublicKey, nil)
+	return CallTransferKeepAlive
+}
+
+// newTransfer returns the unsigned extrinsic of the transfer call of the amount to the recipient account ID.
+func newTransfer(meta *types.Metadata, callName string, recipient []byte, amount types.UCompact) (types.Extrinsic, error) {
+	addr, err := types.NewMultiAddressFromAccountID(recipient)
 	if err != nil {
-		return err
+		return types.Extrinsic{}, err
 	}
 
-	var accountInfo types.AccountInfo
-	ok, err := api.RPC.State.GetStorageLatest(key, &accountInfo)
-	if err != nil || !ok {
-		return fmt.Errorf("couldn't fetch latest alice account storage info: %w", err)
+	call, err := types.NewCall(meta, callName, addr, amount)
+	if err != nil {
+		return types.Extrinsic{}, err
 	}
 
-	nonce := uint64(accountInfo.Nonce)
+	return types.NewExtrinsic(call), nil
+}
+
+// signTransfer signs the transfer extrinsic with the account and the nonce, with the cached genesis hash and
+// runtime version of the client.
+func (c *client) signTransfer(api *gsrpc.SubstrateAPI, ext *types.Extrinsic, account signature.KeyringPair, nonce uint64, opts SubmitOpts) error {
+	era, blockHash, err := signingEra(api.RPC, c.genesisHash, opts.SignOpts)
+	if err != nil {
+		return err
+	}
 
-	if nonceIncrement > 0 {
-		nonce = nonce + nonceIncrement
+	rv, err := c.runtimeVersion(api)
+	if err != nil {
+		return err
 	}
 
 	o := types.SignatureOptions{
-		BlockHash:          genesisHash,
-		Era:                types.ExtrinsicEra{IsMortalEra: false},
-		GenesisHash:        genesisHash,
+		BlockHash:          blockHash,
+		Era:                era,
+		GenesisHash:        c.genesisHash,
 		Nonce:              types.NewUCompactFromUInt(nonce),
 		SpecVersion:        rv.SpecVersion,
-		Tip:                types.NewUCompactFromUInt(0),
+		Tip:                opts.tipOr(0),
 		AppID:              types.NewUCompactFromUInt(0),
 		TransactionVersion: rv.TransactionVersion,
 	}
 
-	// Sign the transaction using Alice's default account
-	err = ext.Sign(signature.TestKeyringPairAlice, o)
+	return ext.Sign(account, o)
+}
+
+// Transfer transfers the amount of Avail tokens, in Avail fractions, from the sender account to the recipient account.
+// It takes a context bounding the wait for the inclusion of the transfer, a client, the sender key pair,
+// the recipient account ID, the amount, and the submission options.
+// The amount and the estimated fee of the transfer must not exceed the transferable balance of the sender, i.e.
+// its free balance that isn't locked, see TransferableBalance.
+// The transfer is signed with the next sender nonce handed out by the nonce manager of the options, or looked up
+// on chain if it's nil, and waited for like DepositAmount does.
+// It returns the submission result, and an error wrapping ErrInsufficientBalance if the sender can't afford
+// the transfer, in which case nothing is submitted, or an error if there is an issue, see DepositAmount.
+func Transfer(ctx context.Context, client Client, from signature.KeyringPair, to types.AccountID, amount *big.Int, opts SubmitOpts) (*SubmitResult, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid transfer amount %v, must be positive", amount)
+	}
+
+	c, err := implementation(client)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Send the extrinsic
-	sub, err := api.RPC.Author.SubmitAndWatchExtrinsic(ext)
+	api := c.instance()
+
+	meta, err := c.metadata(api)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer sub.Unsubscribe()
-
-	for {
-		select {
-		case status := <-sub.Chan():
-			// NOTE: See first line of this function for supported extrinsic status expectations.
-			switch {
-			case status.IsFinalized:
-				return nil
-			case status.IsInBlock:
-				return nil
-			default:
-				if status.IsDropped || status.IsInvalid {
-					return fmt.Errorf("unexpected extrinsic status from Avail: %#v", status)
-				}
-			}
-		case err := <-sub.Err():
-			// TODO: Consider re-connecting subscription channel on error?
-			return err
-		}
+	data, err := GetAccountData(client, from)
+	if errors.Is(err, ErrAccountNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientBalance, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	locks, err := GetLocks(client, from)
+	if err != nil {
+		return nil, err
+	}
+
+	transferable := transferableBalance(data, locks)
+
+	callName := transferCall(opts)
+
+	ext, err := newTransfer(meta, callName, to[:], types.NewUCompact(amount))
+	if err != nil {
+		return nil, err
+	}
+
+	// The fee is estimated before the nonce is handed out, so no nonce is wasted on a transfer that isn't submitted.
+	estimated := ext
+	if err := c.signTransfer(api, &estimated, from, data.Nonce, opts); err != nil {
+		return nil, err
+	}
+
+	fee, err := estimateFee(api.Client, estimated)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't estimate the transfer fee: %w", err)
+	}
+
+	if required := new(big.Int).Add(amount, fee); required.Cmp(transferable) > 0 {
+		return nil, fmt.Errorf("%w: transferring %s AVL with a fee of %s AVL, transferable balance is %s AVL", ErrInsufficientBalance, FormatAVL(amount), FormatAVL(fee), FormatAVL(transferable))
+	}
+
+	start := time.Now()
+
+	result, err := c.submitAndWaitForInclusion(ctx, api, meta, from, opts, func(nonce uint64) (types.Extrinsic, error) {
+		signed := ext
+		err := c.signTransfer(api, &signed, from, nonce, opts)
+
+		return signed, err
+	})
+	c.recordSubmission(callName, opts.WaitFor, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, c.dispatchOutcome(c.instance().RPC, meta, result)
+}
+
+// TransferAll transfers the whole transferable balance of the sender account to the recipient account, e.g. to
+// sweep an account being decommissioned.
+// It takes a context bounding the wait for the inclusion of the transfer, a client, the sender key pair,
+// the recipient account ID, whether to keep the existential deposit in the sender account so it isn't reaped,
+// and the submission options.
+// The transfer is signed with the next sender nonce handed out by the nonce manager of the options, or looked up
+// on chain if it's nil, and waited for like DepositAmount does.
+// It returns the submission result, and an error if there is an issue, see DepositAmount.
+func TransferAll(ctx context.Context, client Client, from signature.KeyringPair, to types.AccountID, keepAlive bool, opts SubmitOpts) (*SubmitResult, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	api := c.instance()
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := types.NewMultiAddressFromAccountID(to[:])
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := types.NewCall(meta, CallTransferAll, addr, keepAlive)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := types.NewExtrinsic(call)
+	start := time.Now()
+
+	result, err := c.submitAndWaitForInclusion(ctx, api, meta, from, opts, func(nonce uint64) (types.Extrinsic, error) {
+		signed := ext
+		err := c.signTransfer(api, &signed, from, nonce, opts)
+
+		return signed, err
+	})
+	c.recordSubmission(CallTransferAll, opts.WaitFor, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, c.dispatchOutcome(c.instance().RPC, meta, result)
+}
+
+// estimateFee returns the fee of the signed extrinsic estimated with payment_queryInfo, in Avail fractions.
+func estimateFee(cl gsrpcclient.Client, ext types.Extrinsic) (*big.Int, error) {
+	encoded, err := codec.EncodeToHex(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		// PartialFee is a decimal string, or a number on older nodes.
+		PartialFee json.Number `json:"partialFee"`
+	}
+
+	if err := cl.Call(&info, "payment_queryInfo", encoded); err != nil {
+		return nil, err
+	}
+
+	fee, ok := new(big.Int).SetString(info.PartialFee.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid partial fee %q", info.PartialFee)
+	}
+
+	return fee, nil
+}
+
+// GetAccountData retrieves the balances and the nonce of the specified account.
+// It takes a client and the account key pair, and returns the account data and an error if there is an issue.
+// It returns an error wrapping ErrAccountNotFound if the account doesn't exist on the Avail network.
+func GetAccountData(client Client, account signature.KeyringPair) (*AccountData, error) {
+	return getAccountData(client, account, nil)
+}
+
+// GetAccountDataAt retrieves the balances and the nonce of the specified account at the Avail block.
+// It takes a client, the account key pair and the block hash, and returns the account data and an error if there
+// is an issue. It returns an error wrapping ErrAccountNotFound if the account didn't exist at the block, or
+// ErrStatePruned if the node discarded the state of the block.
+func GetAccountDataAt(client Client, account signature.KeyringPair, blockHash types.Hash) (*AccountData, error) {
+	return getAccountData(client, account, &blockHash)
+}
+
+// getAccountData retrieves the account data at the block, or at the latest block if the block hash is nil.
+func getAccountData(client Client, account signature.KeyringPair, blockHash *types.Hash) (*AccountData, error) {
+	api, err := instance(client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := latestMetadata(client, api)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := accountStorageKey(meta, account)
+	if err != nil {
+		return nil, err
 	}
+
+	var accountInfo types.AccountInfo
+
+	var ok bool
+	if blockHash == nil {
+		ok, err = api.RPC.State.GetStorageLatest(key, &accountInfo)
+	} else if ok, err = api.RPC.State.GetStorage(key, &accountInfo, *blockHash); err != nil && isPrunedStateError(err) {
+		return nil, fmt.Errorf("%w: account %s at block %s: %s", ErrStatePruned, account.Address, blockHash.Hex(), err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, account.Address)
+	}
+
+	return &AccountData{
+		Free:       u128ToBig(accountInfo.Data.Free),
+		Reserved:   u128ToBig(accountInfo.Data.Reserved),
+		MiscFrozen: u128ToBig(accountInfo.Data.MiscFrozen),
+		FeeFrozen:  u128ToBig(accountInfo.Data.FreeFrozen),
+		Nonce:      uint64(accountInfo.Nonce),
+	}, nil
 }
 
-// GetBalance retrieves the Avail token balance of the specified account.
+// GetBalance retrieves the free Avail token balance of the specified account, in Avail fractions.
 // It takes a client and the account key pair, and returns the account balance as a *big.Int and an error if there is an issue.
+// It returns an error wrapping ErrAccountNotFound if the account doesn't exist on the Avail network, never a nil
+// balance without an error, and the error of the storage lookup if it fails. Use FormatAVL to display the balance in AVL.
 func GetBalance(client Client, account signature.KeyringPair) (*big.Int, error) {
+	data, err := GetAccountData(client, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Free, nil
+}
+
+// GetBalanceAt retrieves the free Avail token balance of the specified account at the Avail block, in Avail
+// fractions, e.g. to check whether an account ran dry before a block.
+// It takes a client, the account key pair and the block hash, and returns the account balance and an error if
+// there is an issue, see GetAccountDataAt.
+func GetBalanceAt(client Client, account signature.KeyringPair, blockHash types.Hash) (*big.Int, error) {
+	data, err := GetAccountDataAt(client, account, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Free, nil
+}
+
+// GetBalanceAtNumber retrieves the free Avail token balance of the specified account at the Avail block with
+// the number, in Avail fractions.
+// It takes a client, the account key pair and the block number, and returns the account balance, and an error
+// wrapping ErrBlockNotFound if there is no such block, or an error if there is an issue, see GetAccountDataAt.
+func GetBalanceAtNumber(client Client, account signature.KeyringPair, blockNumber uint64) (*big.Int, error) {
 	api, err := instance(client)
 	if err != nil {
 		return nil, err
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	blockHash, err := api.RPC.Chain.GetBlockHash(blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d: %s", ErrBlockNotFound, blockNumber, err)
+	}
+
+	// The node returns null for a block above the head, which decodes into a zero hash.
+	if blockHash == (types.Hash{}) {
+		return nil, fmt.Errorf("%w: %d", ErrBlockNotFound, blockNumber)
+	}
+
+	return GetBalanceAt(client, account, blockHash)
+}
+
+// GetBalances retrieves the free Avail token balances of the accounts, in Avail fractions, with a single storage
+// query of their System.Account keys at the latest block, e.g. to monitor the balances of many sequencers or
+// bridge relayers without a round trip per account.
+// It takes a client and the public keys of the accounts, in any order, and returns the balances by the hex-encoded
+// public key, 0x-prefixed, see codec.HexEncodeToString. The accounts that don't exist on the Avail network map to
+// nil. It returns an error if a public key isn't 32 bytes long, or if there is an issue.
+func GetBalances(client Client, accounts [][]byte) (map[string]*big.Int, error) {
+	balances := make(map[string]*big.Int, len(accounts))
+	if len(accounts) == 0 {
+		return balances, nil
+	}
+
+	api, err := instance(client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := latestMetadata(client, api)
 	if err != nil {
 		return nil, err
 	}
 
-	key, err := types.CreateStorageKey(meta, "System", "Account", account.PublicKey, nil)
+	// The accounts are queried once each, so duplicates don't change the result.
+	keys := make([]types.StorageKey, 0, len(accounts))
+	byKey := make(map[string]string, len(accounts))
+
+	for _, account := range accounts {
+		if len(account) != 32 {
+			return nil, fmt.Errorf("invalid public key %s: %d bytes long, expected 32", codec.HexEncodeToString(account), len(account))
+		}
+
+		id := codec.HexEncodeToString(account)
+		if _, ok := balances[id]; ok {
+			continue
+		}
+
+		key, err := types.CreateStorageKey(meta, "System", "Account", account, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		balances[id] = nil
+		byKey[string(key)] = id
+		keys = append(keys, key)
+	}
+
+	sets, err := api.RPC.State.QueryStorageAtLatest(keys)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't query the balances of %d accounts: %w", len(keys), err)
+	}
+
+	// The changes are matched by key, as the node doesn't return them in the order of the keys.
+	for _, set := range sets {
+		for _, change := range set.Changes {
+			id, ok := byKey[string(change.StorageKey)]
+			if !ok {
+				continue
+			}
+
+			update, err := decodeBalanceUpdate(set.Block, change)
+			if err != nil {
+				return nil, fmt.Errorf("account %s: %w", id, err)
+			}
+
+			if update.Exists {
+				balances[id] = update.Free
+			} else {
+				balances[id] = nil
+			}
+		}
+	}
+
+	return balances, nil
+}
+
+// isPrunedStateError checks whether the storage query error was caused by the state discarded by the node.
+func isPrunedStateError(err error) bool {
+	for _, msg := range prunedStateErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnsureBalance tops up the transferable balance of the target account to the minimum, with a transfer from the
+// funder account of the shortfall, so the balance locked e.g. by vesting doesn't count toward the minimum. An account
+// that doesn't exist yet is funded with at least the existential deposit, as the transfer creating it fails
+// otherwise. Nothing is transferred if the transferable balance already meets the minimum.
+// It takes a context bounding the wait for the inclusion of the transfer, a client, the funder and target key
+// pairs, the minimum balance in Avail fractions, and the submission options.
+// It returns the amount deposited, in Avail fractions, and an error if there is an issue, see DepositAmount.
+func EnsureBalance(ctx context.Context, client Client, funder, target signature.KeyringPair, minimum *big.Int, opts SubmitOpts) (*big.Int, error) {
+	c, err := implementation(client)
 	if err != nil {
 		return nil, err
 	}
 
+	balance, err := TransferableBalance(client, target)
+	exists := err == nil
+	if errors.Is(err, ErrAccountNotFound) {
+		balance = big.NewInt(0)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if balance.Cmp(minimum) >= 0 {
+		return big.NewInt(0), nil
+	}
+
+	shortfall := new(big.Int).Sub(minimum, balance)
+
+	if !exists {
+		constants, err := c.chainConstants(c.instance())
+		if err != nil {
+			return nil, err
+		}
+
+		if shortfall.Cmp(constants.ExistentialDeposit) < 0 {
+			shortfall = new(big.Int).Set(constants.ExistentialDeposit)
+		}
+	}
+
+	if _, err := depositBalance(ctx, c, funder, target, types.NewUCompact(shortfall), opts); err != nil {
+		return nil, err
+	}
+
+	return shortfall, nil
+}
+
+// checkExistentialDeposit returns an error wrapping ErrBelowExistentialDeposit if the balance of the recipient
+// account would stay below the existential deposit after the deposit of the amount. The balance of the recipient
+// is only looked up for an amount below the existential deposit.
+func checkExistentialDeposit(api *gsrpc.SubstrateAPI, meta *types.Metadata, existentialDeposit *big.Int, recipient signature.KeyringPair, amount *big.Int) error {
+	if amount.Cmp(existentialDeposit) >= 0 {
+		return nil
+	}
+
+	key, err := accountStorageKey(meta, recipient)
+	if err != nil {
+		return err
+	}
+
 	var accountInfo types.AccountInfo
 	ok, err := api.RPC.State.GetStorageLatest(key, &accountInfo)
-	if err != nil || !ok {
+	if err != nil {
+		return fmt.Errorf("couldn't fetch the account of the recipient: %w", err)
+	}
+
+	balance := big.NewInt(0)
+	if ok {
+		balance = u128ToBig(accountInfo.Data.Free)
+	}
+
+	if new(big.Int).Add(balance, amount).Cmp(existentialDeposit) < 0 {
+		return fmt.Errorf("%w: depositing %s AVL to %s, which needs a balance of at least %s AVL", ErrBelowExistentialDeposit, FormatAVL(amount), recipient.Address, FormatAVL(existentialDeposit))
+	}
+
+	return nil
+}
+
+// GetExistentialDeposit retrieves the existential deposit of the Avail network, i.e. the minimum balance of an
+// account, in Avail fractions. An account whose balance drops below it is reaped, and a new account isn't created
+// by a deposit below it.
+// It takes a client, and returns the existential deposit and an error if there is an issue.
+func GetExistentialDeposit(client Client) (*big.Int, error) {
+	c, err := implementation(client)
+	if err != nil {
 		return nil, err
 	}
 
-	return new(big.Int).Div(new(big.Int).SetUint64(accountInfo.Data.Free.Uint64()), big.NewInt(AVL)), nil
+	constants, err := c.chainConstants(c.instance())
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Set(constants.ExistentialDeposit), nil
+}
+
+// existentialDeposit returns the Balances.ExistentialDeposit constant of the chain, i.e. the minimum balance of
+// an account, in Avail fractions.
+func existentialDeposit(meta *types.Metadata) (*big.Int, error) {
+	value, err := meta.FindConstantValue("Balances", "ExistentialDeposit")
+	if err != nil {
+		return nil, err
+	}
+
+	var deposit types.U128
+	if err := codec.Decode(value, &deposit); err != nil {
+		return nil, fmt.Errorf("invalid Balances.ExistentialDeposit constant: %w", err)
+	}
+
+	return u128ToBig(deposit), nil
+}
+
+// accountStorageKey returns the key of the storage of the account in the System pallet.
+func accountStorageKey(meta *types.Metadata, account signature.KeyringPair) (types.StorageKey, error) {
+	return types.CreateStorageKey(meta, "System", "Account", account.PublicKey, nil)
+}
+
+// u128ToBig copies the value of the U128 to a big.Int, which is zero if the value wasn't decoded.
+func u128ToBig(value types.U128) *big.Int {
+	if value.Int == nil {
+		return big.NewInt(0)
+	}
+
+	return new(big.Int).Set(value.Int)
+}
+
+// FormatAVL formats an amount of Avail fractions as a decimal number of AVL with DefaultTokenDecimals decimals,
+// e.g. "1.5" for 1.5 * 10^18 fractions.
+// The trailing zeros of the fractional part are dropped, so whole amounts have no decimal point.
+func FormatAVL(amount *big.Int) string {
+	return FormatAmount(amount, DefaultTokenDecimals())
+}
+
+// FractionsToAVL converts an amount of Avail fractions to a decimal amount of AVL, the inverse of AVLToFractions,
+// see FormatAVL.
+func FractionsToAVL(amount *big.Int) string {
+	return FormatAVL(amount)
+}
+
+// AVLToFractions converts a decimal amount of AVL, e.g. "0.25" or big.NewFloat(1.5), to Avail fractions, without
+// the overflows of the arithmetic on AVL, e.g. for the amounts above about 18.4 AVL.
+// A *big.Float is rounded to the nearest Avail fraction, as it may not represent a decimal amount exactly.
+// It returns an error if the amount is negative, not a decimal number, or has more than DefaultTokenDecimals
+// decimals, see ParseAmount.
+func AVLToFractions[T string | *big.Float](avl T) (*big.Int, error) {
+	switch avl := any(avl).(type) {
+	case *big.Float:
+		if avl == nil || avl.IsInf() {
+			return nil, fmt.Errorf("invalid amount of AVL %v", avl)
+		}
+
+		return ParseAmount(avl.Text('f', int(DefaultTokenDecimals())), DefaultTokenDecimals())
+	default:
+		return ParseAmount(avl.(string), DefaultTokenDecimals())
+	}
+}
+
+// ParseAmount parses a decimal number of tokens with the number of decimals into token fractions, e.g. 15
+// fractions for "1.5" tokens with 1 decimal, the inverse of FormatAmount.
+// It returns an error if the amount is negative, not a decimal number, or has more decimals than the token.
+func ParseAmount(amount string, decimals uint32) (*big.Int, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(amount), ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return nil, fmt.Errorf("invalid amount %q, must be a non-negative decimal number", amount)
+	}
+
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("invalid amount %q, more than %d decimals", amount, decimals)
+	}
+
+	// The fractional part is padded to as many digits as the token decimals.
+	fractions, _ := new(big.Int).SetString("0"+whole+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+
+	return fractions, nil
+}
+
+// isDigits checks whether the string only has decimal digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FormatAmount formats an amount of token fractions as a decimal number of tokens with the number of decimals,
+// e.g. "1.5" for 15 fractions of a token with 1 decimal, see ChainProperties.TokenDecimals.
+// The trailing zeros of the fractional part are dropped, so whole amounts have no decimal point.
+func FormatAmount(amount *big.Int, decimals uint32) string {
+	if amount == nil {
+		return "0"
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, fraction := new(big.Int).QuoRem(new(big.Int).Abs(amount), unit, new(big.Int))
+
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+
+	if fraction.Sign() == 0 {
+		return sign + whole.String()
+	}
+
+	// The fractional part has as many digits as the token decimals.
+	digits := fraction.String()
+	digits = strings.Repeat("0", int(decimals)-len(digits)) + digits
+	digits = strings.TrimRight(digits, "0")
+
+	return sign + whole.String() + "." + digits
 }
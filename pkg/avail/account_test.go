@@ -0,0 +1,1008 @@
+package avail
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+var errSubmitStopped = errors.New("submit stopped by test")
+
+func TestDepositBalanceSignsWithFunder(t *testing.T) {
+	m, funder := newFundedMockClient(t, 15*AVL+AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetNonce(funder, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, 15*AVL, SubmitOpts{})
+	assert.NoError(t, err)
+
+	funderAddr, err := types.NewMultiAddressFromAccountID(funder.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The nonce is looked up for the funder only, and the funder signs.
+	assert.Equal(t, []string{funder.Address}, r.recorded().nonceLookups)
+	if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+		assert.True(t, submitted[0].IsSigned())
+		assert.Equal(t, funderAddr, submitted[0].Signature.Signer)
+		assert.Equal(t, types.NewUCompactFromUInt(7), submitted[0].Signature.Nonce)
+	}
+}
+
+func TestDepositBalanceFromDevFunderSignsWithAlice(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(signature.TestKeyringPairAlice, new(big.Int).SetUint64(2*AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DepositBalanceFromDevFunder(context.Background(), m, recipient, AVL, SubmitOpts{})
+	assert.NoError(t, err)
+
+	aliceAddr, err := types.NewMultiAddressFromAccountID(signature.TestKeyringPairAlice.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+		assert.Equal(t, aliceAddr, submitted[0].Signature.Signer)
+	}
+}
+
+func TestDepositBalanceFromConfiguredDevFunder(t *testing.T) {
+	m, funder := newFundedMockClient(t, 2*AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funderAddr, err := types.NewMultiAddressFromAccountID(funder.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetDevFunder(funder)
+	t.Cleanup(func() { SetDevFunder(signature.TestKeyringPairAlice) })
+
+	// The nonce of the configured funder is read, and it signs the transfer.
+	if err := m.SetNonce(funder, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+	assert.Equal(t, funder.PublicKey, DevFunder(m).PublicKey)
+
+	_, err = DepositBalanceFromDevFunder(context.Background(), m, recipient, AVL, SubmitOpts{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{funder.Address}, r.recorded().nonceLookups)
+	if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+		assert.Equal(t, funderAddr, submitted[0].Signature.Signer)
+		assert.Equal(t, types.NewUCompactFromUInt(5), submitted[0].Signature.Nonce)
+	}
+
+	// The funder of a client takes precedence.
+	clientFunder, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err = NewMockClient(WithDevFunder(clientFunder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, clientFunder.PublicKey, DevFunder(m).PublicKey)
+}
+
+func TestTransferCallIndex(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := types.NewAccountID(recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		submit func(c Client, funder signature.KeyringPair) error
+		call   types.CallIndex
+		args   []interface{}
+	}{
+		{
+			name: "keep alive",
+			submit: func(c Client, funder signature.KeyringPair) error {
+				_, err := DepositBalance(context.Background(), c, funder, recipient, AVL, SubmitOpts{})
+				return err
+			},
+			call: types.CallIndex{SectionIndex: 6, MethodIndex: 3},
+			args: []interface{}{types.NewUCompactFromUInt(AVL)},
+		},
+		{
+			name: "allow death",
+			submit: func(c Client, funder signature.KeyringPair) error {
+				_, err := DepositBalance(context.Background(), c, funder, recipient, AVL, SubmitOpts{AllowDeath: true})
+				return err
+			},
+			call: types.CallIndex{SectionIndex: 6, MethodIndex: 0},
+			args: []interface{}{types.NewUCompactFromUInt(AVL)},
+		},
+		{
+			name: "transfer all",
+			submit: func(c Client, funder signature.KeyringPair) error {
+				_, err := TransferAll(context.Background(), c, funder, *to, false, SubmitOpts{})
+				return err
+			},
+			call: types.CallIndex{SectionIndex: 6, MethodIndex: 4},
+			args: []interface{}{false},
+		},
+		{
+			name: "transfer all keep alive",
+			submit: func(c Client, funder signature.KeyringPair) error {
+				_, err := TransferAll(context.Background(), c, funder, *to, true, SubmitOpts{})
+				return err
+			},
+			call: types.CallIndex{SectionIndex: 6, MethodIndex: 4},
+			args: []interface{}{true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, funder := newFundedMockClient(t, 2*AVL)
+
+			assert.NoError(t, tc.submit(m, funder))
+
+			submitted := m.Submitted()
+			if !assert.Len(t, submitted, 1) {
+				return
+			}
+
+			args, err := codec.Encode(types.MultiAddress{IsID: true, AsID: *to})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, arg := range tc.args {
+				encoded, err := codec.Encode(arg)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				args = append(args, encoded...)
+			}
+
+			assert.Equal(t, tc.call, submitted[0].Method.CallIndex)
+			assert.Equal(t, types.Args(args), submitted[0].Method.Args)
+		})
+	}
+}
+
+func TestGetExistentialDeposit(t *testing.T) {
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The existential deposit of the test metadata is 0.0001 AVL.
+	existentialDeposit, err := GetExistentialDeposit(m)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100_000_000_000_000), existentialDeposit)
+}
+
+func TestDepositBalanceExistentialDeposit(t *testing.T) {
+	m, funder := newFundedMockClient(t, AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existentialDeposit, err := existentialDeposit(m.meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipientKey, err := accountStorageKey(m.meta, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+	below := existentialDeposit.Uint64() - 1
+
+	// A new account isn't funded below the existential deposit, and nothing is submitted.
+	_, err = DepositBalance(context.Background(), m, funder, recipient, below, SubmitOpts{})
+	assert.ErrorIs(t, err, ErrBelowExistentialDeposit)
+	assert.ErrorContains(t, err, FormatAVL(existentialDeposit))
+	assert.Empty(t, m.Submitted())
+
+	// The recipient isn't looked up for a deposit of at least the existential deposit.
+	r.reset()
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, existentialDeposit.Uint64(), SubmitOpts{})
+	assert.NoError(t, err)
+	assert.NotContains(t, r.recorded().lookups, recipientKey)
+
+	// An existing account can receive less than the existential deposit.
+	_, err = DepositBalance(context.Background(), m, funder, recipient, 1, SubmitOpts{})
+	assert.NoError(t, err)
+	assert.Len(t, m.Submitted(), 2)
+}
+
+func TestGetBalance(t *testing.T) {
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aboveUint64, _ := new(big.Int).SetString("123456789012345678901234", 10)
+
+	testCases := []struct {
+		name string
+		free *big.Int
+	}{
+		{"zero", big.NewInt(0)},
+		{"less than 1 AVL", big.NewInt(AVL / 2)},
+		{"1.5 AVL", big.NewInt(AVL + AVL/2)},
+		{"above 2^64 fractions", aboveUint64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewMockClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.SetBalance(account, tc.free); err != nil {
+				t.Fatal(err)
+			}
+
+			balance, err := GetBalance(m, account)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, tc.free.Cmp(balance), "balance %s, want %s", balance, tc.free)
+		})
+	}
+
+	// An account without storage doesn't exist.
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance, err := GetBalance(m, other)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+	assert.Nil(t, balance)
+
+	// A failed lookup isn't reported as a missing account.
+	recordMockCalls(m).failStorage(errConnectionReset)
+
+	balance, err = GetBalance(m, other)
+	assert.ErrorIs(t, err, errConnectionReset)
+	assert.NotErrorIs(t, err, ErrAccountNotFound)
+	assert.Nil(t, balance)
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The recipient is created in block 1, and topped up in block 2.
+	for i := 0; i < 2; i++ {
+		if _, err := DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for n, want := range []uint64{10 * AVL, 9 * AVL, 8 * AVL} {
+		balance, err := GetBalanceAtNumber(m, funder, uint64(n))
+		if assert.NoError(t, err) {
+			assert.Equal(t, new(big.Int).SetUint64(want), balance, "block %d", n)
+		}
+	}
+
+	blockHash, err := m.api.RPC.Chain.GetBlockHash(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance, err := GetBalanceAt(m, recipient, blockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(AVL), balance)
+
+	_, err = GetBalanceAtNumber(m, recipient, 0)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+
+	_, err = GetBalanceAtNumber(m, recipient, 3)
+	assert.ErrorIs(t, err, ErrBlockNotFound)
+
+	// A non-archive node discarded the state of the old blocks.
+	m.PruneState(2)
+
+	_, err = GetBalanceAt(m, recipient, blockHash)
+	assert.ErrorIs(t, err, ErrStatePruned)
+
+	balance, err = GetBalanceAtNumber(m, recipient, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2*AVL), balance)
+}
+
+func TestGetBalances(t *testing.T) {
+	accounts := make([]signature.KeyringPair, 4)
+	for i := range accounts {
+		account, err := NewAccount()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		accounts[i] = account
+	}
+
+	aboveUint64, _ := new(big.Int).SetString("123456789012345678901234", 10)
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last two accounts don't exist.
+	if err := m.SetBalance(accounts[0], big.NewInt(AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(accounts[1], aboveUint64); err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+
+	want := map[string]*big.Int{
+		codec.HexEncodeToString(accounts[0].PublicKey): big.NewInt(AVL),
+		codec.HexEncodeToString(accounts[1].PublicKey): aboveUint64,
+		codec.HexEncodeToString(accounts[2].PublicKey): nil,
+		codec.HexEncodeToString(accounts[3].PublicKey): nil,
+	}
+
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1, 0}} {
+		var keys [][]byte
+		for _, i := range order {
+			keys = append(keys, accounts[i].PublicKey)
+		}
+
+		r.reset()
+
+		balances, err := GetBalances(m, keys)
+		if assert.NoError(t, err) {
+			assert.Equal(t, want, balances, "order %v", order)
+			assert.Equal(t, 1, r.recorded().queries)
+		}
+	}
+
+	balances, err := GetBalances(m, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, balances)
+
+	_, err = GetBalances(m, [][]byte{accounts[0].PublicKey, accounts[1].PublicKey[:31]})
+	assert.EqualError(t, err, "invalid public key "+codec.HexEncodeToString(accounts[1].PublicKey[:31])+": 31 bytes long, expected 32")
+
+	r.failStorage(errConnectionReset)
+
+	_, err = GetBalances(m, [][]byte{accounts[0].PublicKey})
+	assert.ErrorIs(t, err, errConnectionReset)
+}
+
+func TestGetAccountData(t *testing.T) {
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reserved, _ := new(big.Int).SetString("98765432109876543210987", 10)
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.updateAccount(account.PublicKey, func(info *types.AccountInfo) {
+		info.Nonce = 42
+		info.Data.Free = types.NewU128(*big.NewInt(AVL + AVL/2))
+		info.Data.Reserved = types.NewU128(*reserved)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.SetLocks(account,
+		BalanceLock{ID: [8]byte{'v', 'e', 's', 't', 'i', 'n', 'g', ' '}, Amount: big.NewInt(AVL), Reasons: LockReasonsMisc},
+		BalanceLock{ID: [8]byte{'s', 't', 'a', 'k', 'i', 'n', 'g', ' '}, Amount: big.NewInt(AVL / 2), Reasons: LockReasonsFee},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := GetAccountData(m, account)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "1.5", FormatAVL(data.Free))
+	assert.Equal(t, 0, reserved.Cmp(data.Reserved))
+	assert.Equal(t, "1", FormatAVL(data.MiscFrozen))
+	assert.Equal(t, "0.5", FormatAVL(data.FeeFrozen))
+	assert.Equal(t, uint64(42), data.Nonce)
+
+	_, err = GetAccountData(m, other)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestAccountExists(t *testing.T) {
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetNonce(account, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := AccountExists(m, account)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = AccountExists(m, missing)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	recordMockCalls(m).failStorage(errConnectionReset)
+
+	ok, err = AccountExists(m, account)
+	assert.ErrorIs(t, err, errConnectionReset)
+	assert.False(t, ok)
+}
+
+func TestAccountExistsFromMnemonic(t *testing.T) {
+	useFastKeystoreKDF(t)
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "account")
+	if err := SaveAccount(path, newTestMnemonic(t), "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := AccountFromFile(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(account, new(big.Int).SetUint64(AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := AccountExistsFromMnemonic(m, path, "correct horse")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	if err := SaveAccount(missing, newTestMnemonic(t), "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err = AccountExistsFromMnemonic(m, missing, "correct horse")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = AccountExistsFromMnemonic(m, path, "wrong horse")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestEnsureBalance(t *testing.T) {
+	target, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	minimum := new(big.Int).Mul(big.NewInt(15), big.NewInt(AVL))
+
+	// transfer returns the transfer of the amount to the target.
+	transfer := func(t *testing.T, meta *types.Metadata, amount *big.Int) types.Call {
+		addr, err := types.NewMultiAddressFromAccountID(target.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		call, err := types.NewCall(meta, CallTransferKeepAlive, addr, types.NewUCompact(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return call
+	}
+
+	t.Run("balance above minimum", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 17*AVL)
+		if err := m.SetBalance(target, new(big.Int).Add(minimum, big.NewInt(1))); err != nil {
+			t.Fatal(err)
+		}
+
+		deposited, err := EnsureBalance(context.Background(), m, funder, target, minimum, SubmitOpts{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, deposited.Sign())
+		assert.Empty(t, m.Submitted())
+	})
+
+	t.Run("balance below minimum", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 17*AVL)
+		if err := m.SetBalance(target, big.NewInt(AVL)); err != nil {
+			t.Fatal(err)
+		}
+
+		deposited, err := EnsureBalance(context.Background(), m, funder, target, minimum, SubmitOpts{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, new(big.Int).Sub(minimum, big.NewInt(AVL)).Cmp(deposited))
+
+		if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+			assert.Equal(t, transfer(t, m.meta, new(big.Int).Sub(minimum, big.NewInt(AVL))), submitted[0].Method)
+		}
+
+		balance, err := GetBalance(m, target)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, minimum.Cmp(balance))
+	})
+
+	t.Run("deposited amount", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 17*AVL)
+
+		deposited, err := EnsureBalance(context.Background(), m, funder, target, minimum, SubmitOpts{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, minimum.Cmp(deposited))
+		assert.Len(t, m.Submitted(), 1)
+	})
+
+	t.Run("missing account funded with the existential deposit", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, AVL)
+
+		existentialDeposit, err := existentialDeposit(m.meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = EnsureBalance(context.Background(), m, funder, target, big.NewInt(1), SubmitOpts{})
+		assert.NoError(t, err)
+
+		if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+			assert.Equal(t, transfer(t, m.meta, existentialDeposit), submitted[0].Method)
+		}
+	})
+}
+
+func TestTransfer(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := types.NewAccountID(recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("transfer", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 2*AVL)
+
+		amount, _ := new(big.Int).SetString("1500000000000000000", 10)
+
+		_, err = Transfer(context.Background(), m, funder, *to, amount, SubmitOpts{})
+		assert.NoError(t, err)
+
+		if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+			call, err := types.NewCall(m.meta, CallTransferKeepAlive, types.MultiAddress{IsID: true, AsID: *to}, types.NewUCompact(amount))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, call, submitted[0].Method)
+			assert.Equal(t, types.NewUCompactFromUInt(0), submitted[0].Signature.Nonce)
+		}
+
+		balance, err := GetBalance(m, recipient)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, amount.Cmp(balance))
+	})
+
+	testCases := []struct {
+		name       string
+		free       int64
+		miscFrozen int64
+		amount     *big.Int
+	}{
+		{"amount and fee above free balance", AVL, 0, big.NewInt(AVL)},
+		{"amount above transferable balance", 2 * AVL, AVL + AVL/2, big.NewInt(AVL)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, funder := newFundedMockClient(t, uint64(tc.free))
+			m.SetFee(big.NewInt(1_000_000_000))
+
+			if err := m.SetNonce(funder, 5); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.miscFrozen > 0 {
+				if err := m.SetLocks(funder, BalanceLock{ID: [8]byte{'v', 'e', 's', 't', 'i', 'n', 'g', ' '}, Amount: big.NewInt(tc.miscFrozen), Reasons: LockReasonsMisc}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			nonces := NewNonceManager()
+
+			_, err := Transfer(context.Background(), m, funder, *to, tc.amount, SubmitOpts{Nonces: nonces})
+			assert.ErrorIs(t, err, ErrInsufficientBalance)
+			assert.Empty(t, m.Submitted())
+
+			// No nonce is handed out for the rejected transfer.
+			nonce, err := nonces.Next(m, funder)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(5), nonce)
+		})
+	}
+
+	t.Run("missing sender", func(t *testing.T) {
+		m, err := NewMockClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sender, err := NewAccount()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Transfer(context.Background(), m, sender, *to, big.NewInt(AVL), SubmitOpts{})
+		assert.ErrorIs(t, err, ErrInsufficientBalance)
+		assert.Empty(t, m.Submitted())
+	})
+
+	t.Run("non positive amount", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, AVL)
+
+		_, err = Transfer(context.Background(), m, funder, *to, big.NewInt(0), SubmitOpts{})
+		assert.Error(t, err)
+		_, err = Transfer(context.Background(), m, funder, *to, nil, SubmitOpts{})
+		assert.Error(t, err)
+		assert.Empty(t, m.Submitted())
+	})
+}
+
+func TestFormatAVL(t *testing.T) {
+	aboveUint64, _ := new(big.Int).SetString("123456789012345678901234", 10)
+
+	testCases := []struct {
+		amount *big.Int
+		want   string
+	}{
+		{nil, "0"},
+		{big.NewInt(0), "0"},
+		{big.NewInt(1), "0.000000000000000001"},
+		{big.NewInt(AVL / 2), "0.5"},
+		{big.NewInt(AVL), "1"},
+		{big.NewInt(AVL + AVL/2), "1.5"},
+		{big.NewInt(-(AVL + AVL/2)), "-1.5"},
+		{aboveUint64, "123456.789012345678901234"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, FormatAVL(tc.amount), "amount %v", tc.amount)
+	}
+}
+
+func TestAVLToFractions(t *testing.T) {
+	aboveUint64, _ := new(big.Int).SetString("123456789012345678901234", 10)
+
+	testCases := []struct {
+		avl       string
+		want      *big.Int
+		formatted string
+	}{
+		{"0", big.NewInt(0), "0"},
+		{"0.25", big.NewInt(AVL / 4), "0.25"},
+		{".5", big.NewInt(AVL / 2), "0.5"},
+		{"1.", big.NewInt(AVL), "1"},
+		{" 1.50\n", big.NewInt(AVL + AVL/2), "1.5"},
+		{"0.000000000000000001", big.NewInt(1), "0.000000000000000001"},
+		{"123456.789012345678901234", aboveUint64, "123456.789012345678901234"},
+	}
+
+	for _, tc := range testCases {
+		fractions, err := AVLToFractions(tc.avl)
+		if assert.NoError(t, err, "amount %q", tc.avl) {
+			assert.Equal(t, tc.want, fractions, "amount %q", tc.avl)
+			assert.Equal(t, tc.formatted, FractionsToAVL(fractions), "amount %q", tc.avl)
+		}
+	}
+
+	for _, avl := range []string{"", ".", "-1", "+1", "1e3", "1/4", "0x10", "1.0000000000000000001", "1 000"} {
+		_, err := AVLToFractions(avl)
+		assert.Error(t, err, "amount %q", avl)
+	}
+
+	tenth, _ := new(big.Float).SetPrec(128).SetString("0.1")
+
+	// The floats are rounded to the nearest fraction, so the precision of a float64 shows.
+	floats := []struct {
+		avl  *big.Float
+		want *big.Int
+	}{
+		{big.NewFloat(0.25), big.NewInt(AVL / 4)},
+		{big.NewFloat(0.1), big.NewInt(AVL/10 + 6)},
+		{tenth, big.NewInt(AVL / 10)},
+		{new(big.Float).SetInt(aboveUint64), new(big.Int).Mul(aboveUint64, big.NewInt(AVL))},
+	}
+
+	for _, tc := range floats {
+		fractions, err := AVLToFractions(tc.avl)
+		if assert.NoError(t, err, "amount %v", tc.avl) {
+			assert.Equal(t, tc.want, fractions, "amount %v", tc.avl)
+		}
+	}
+
+	for _, avl := range []*big.Float{nil, big.NewFloat(-1), new(big.Float).SetInf(false)} {
+		_, err := AVLToFractions(avl)
+		assert.Error(t, err, "amount %v", avl)
+	}
+}
+
+func TestDepositAmount(t *testing.T) {
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funder, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	free, err := AVLToFractions("100000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(funder, free); err != nil {
+		t.Fatal(err)
+	}
+
+	// The amount is above 2^64 fractions.
+	amount, err := AVLToFractions("25000.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DepositAmount(context.Background(), m, funder, recipient, amount, SubmitOpts{})
+	assert.NoError(t, err)
+
+	balance, err := GetBalance(m, recipient)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "25000.25", FractionsToAVL(balance))
+	}
+
+	balance, err = GetBalance(m, funder)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "74999.75", FractionsToAVL(balance))
+	}
+
+	_, err = DepositAmount(context.Background(), m, funder, recipient, big.NewInt(-1), SubmitOpts{})
+	assert.EqualError(t, err, "invalid deposit amount -1, must not be negative")
+
+	_, err = DepositAmount(context.Background(), m, funder, recipient, nil, SubmitOpts{})
+	assert.Error(t, err)
+	assert.Len(t, m.Submitted(), 1)
+}
+
+func TestNewAccountWithStrength(t *testing.T) {
+	wordCounts := map[int]int{128: 12, 160: 15, 192: 18, 224: 21, 256: 24}
+
+	for bits, words := range wordCounts {
+		account, err := NewAccountWithStrength(bits)
+		if !assert.NoError(t, err, "strength %d", bits) {
+			continue
+		}
+
+		assert.Len(t, strings.Fields(account.Mnemonic), words, "strength %d", bits)
+
+		// The account is recovered from its mnemonic.
+		recovered, err := NewAccountFromMnemonic(account.Mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, account.KeyPair.Address, recovered.Address)
+		assert.Equal(t, account.KeyPair.PublicKey, recovered.PublicKey)
+	}
+
+	for _, bits := range []int{0, 64, 127, 129, 144, 288} {
+		_, err := NewAccountWithStrength(bits)
+		assert.ErrorIs(t, err, ErrInvalidMnemonicStrength, "strength %d", bits)
+	}
+}
+
+func TestNewAccountIsTwelveWords(t *testing.T) {
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The key pair is derived from the mnemonic, which is kept as its URI.
+	assert.Len(t, strings.Fields(account.URI), 12)
+}
+
+// devPhrase is the mnemonic of the substrate development accounts.
+const devPhrase = "bottom drive obey lake curtain smoke basket hold race lonely fit walk"
+
+func TestDeriveAccount(t *testing.T) {
+	testCases := []struct {
+		path      string
+		publicKey string
+		address   string
+	}{
+		// The well-known substrate development accounts.
+		{"//Alice", "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d", "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+		{"//Bob", "8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48", "5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty"},
+		{"//Alice//stash", "be5ddb1579b72e84524fc29e78609e3caf42e85aa118ebfe0b0ad404b5bdd25f", "5GNJqTPyNqANBkUVMN1LPPrxXnFouWXoe2wNSmmEoLctxiZY"},
+		// Role-specific keys, soft junctions and passwords.
+		{"//sequencer//0", "1ceb0b7dde7fc2e4f2f1dc14812062ea2f8601a0c49f34946c2642e2a7777f39", "5Cid2APVKTXdhKc3n657f6ipxbVoN8kS2zXj5FK7rXh77uhc"},
+		{"//sequencer/0", "16beb6d295f7c08bde2c08edfebd6c06c5c2891c2b5f160513ffb5c88f49111d", "5CaXZhSAhsdQRJh3vcHQva3fvJ54P1mTWq23vnYYg9Av8zQa"},
+		{"//sequencer//0///secret", "561a5884466a6a27a8e326fefb7bcb4ba7046ab8eaa795add9758bb02e5fb331", "5E1boSk9tgcURkC54FJZy4PRWGc9Z2LVKPH3drWvQ7FxmsUV"},
+	}
+
+	for _, tc := range testCases {
+		account, err := DeriveAccount(devPhrase, tc.path)
+		if !assert.NoError(t, err, "path %s", tc.path) {
+			continue
+		}
+
+		assert.Equal(t, tc.publicKey, hex.EncodeToString(account.PublicKey), "path %s", tc.path)
+		assert.Equal(t, tc.address, account.Address, "path %s", tc.path)
+	}
+
+	// The development account matches the one used for devnet deposits.
+	alice, err := NewAccountFromURI(devPhrase+"//Alice", 42)
+	assert.NoError(t, err)
+	assert.Equal(t, signature.TestKeyringPairAlice.PublicKey, alice.PublicKey)
+
+	// Without a path, the account is the one of the mnemonic.
+	base, err := DeriveAccount(devPhrase, "")
+	assert.NoError(t, err)
+
+	fromMnemonic, err := NewAccountFromMnemonic(devPhrase)
+	assert.NoError(t, err)
+	assert.Equal(t, fromMnemonic.PublicKey, base.PublicKey)
+
+	// The whitespace between the phrase and the path is ignored.
+	spaced, err := NewAccountFromURI(devPhrase+" //Alice", 42)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.PublicKey, spaced.PublicKey)
+}
+
+func TestNewAccountFromURIMalformed(t *testing.T) {
+	for _, uri := range []string{
+		"",
+		"//Alice",
+		"bottom drive obey lake curtain smoke basket hold race lonely fit",
+		"0x1234//Alice",
+		devPhrase + "//",
+		devPhrase + "//sequencer//",
+		devPhrase + "//sequencer///",
+		devPhrase + "/",
+	} {
+		_, err := NewAccountFromURI(uri, 42)
+		assert.ErrorIs(t, err, ErrInvalidAccountURI, "uri %q", uri)
+	}
+
+	_, err := DeriveAccount(devPhrase, "sequencer//0")
+	assert.ErrorIs(t, err, ErrInvalidAccountURI)
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	assert.NoError(t, ValidateMnemonic(devPhrase))
+	assert.NoError(t, ValidateMnemonic("\n  "+strings.ReplaceAll(devPhrase, " ", " \t")+"\r\n"))
+
+	words := strings.Fields(devPhrase)
+	words[6] = "recieve"
+
+	testCases := []struct {
+		mnemonic string
+		message  string
+	}{
+		{"", "0 words"},
+		{strings.Join(strings.Fields(devPhrase)[:11], " "), "11 words"},
+		{strings.Join(words, " "), "word 7 'recieve' is not a valid BIP39 word"},
+		{strings.Repeat("abandon ", 12), "checksum"},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateMnemonic(tc.mnemonic)
+		if assert.ErrorIs(t, err, ErrInvalidMnemonic, "mnemonic %q", tc.mnemonic) {
+			assert.Contains(t, err.Error(), tc.message)
+		}
+	}
+
+	// The account is generated from the mnemonic without the whitespace around it, and a typo is reported.
+	trimmed, err := NewAccountFromMnemonic(devPhrase + "\n")
+	assert.NoError(t, err)
+
+	expected, err := NewAccountFromMnemonic(devPhrase)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, trimmed.PublicKey)
+
+	_, err = NewAccountFromMnemonic(strings.Join(words, " "))
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+
+	_, err = DeriveAccount(strings.Join(words, " "), "//sequencer")
+	if assert.ErrorIs(t, err, ErrInvalidAccountURI) {
+		assert.Contains(t, err.Error(), "word 7 'recieve'")
+	}
+}
@@ -0,0 +1,225 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// Role is a logical kind of data posted to Avail, each one posted under its own AppID so its consumers can
+// subscribe to it selectively, see AppIDRegistry.
+type Role string
+
+const (
+	// RoleBlocks is the role of the block data of the sequencers.
+	RoleBlocks Role = "blocks"
+	// RoleFraudProofs is the role of the fraud proofs of the watchtowers.
+	RoleFraudProofs Role = "fraud-proofs"
+	// RoleBridgeMessages is the role of the bridge messages.
+	RoleBridgeMessages Role = "bridge-messages"
+)
+
+// ErrRoleNotRegistered is the error returned when no AppID is registered for a role.
+var ErrRoleNotRegistered = errors.New("no AppID registered for role")
+
+// AppIDRegistry maps the roles of the data posted to Avail to their AppIDs, one per role.
+// It's created at startup, from the configuration or from the application keys of the roles, and is immutable
+// then, so it's safe for concurrent use.
+type AppIDRegistry struct {
+	appIDs map[Role]uint32
+	roles  map[uint32]Role
+}
+
+// NewAppIDRegistry creates the registry of the AppIDs of the roles, e.g. from the configuration.
+// It returns an error if several roles share an AppID.
+func NewAppIDRegistry(appIDs map[Role]uint32) (*AppIDRegistry, error) {
+	r := &AppIDRegistry{
+		appIDs: make(map[Role]uint32, len(appIDs)),
+		roles:  make(map[uint32]Role, len(appIDs)),
+	}
+
+	// The roles are registered in order, so the error of a shared AppID is deterministic.
+	roles := make([]Role, 0, len(appIDs))
+	for role := range appIDs {
+		roles = append(roles, role)
+	}
+
+	sortRoles(roles)
+
+	for _, role := range roles {
+		appID := appIDs[role]
+
+		if other, ok := r.roles[appID]; ok {
+			return nil, fmt.Errorf("roles %s and %s share AppID %d", other, role, appID)
+		}
+
+		r.appIDs[role] = appID
+		r.roles[appID] = role
+	}
+
+	return r, nil
+}
+
+// EnsureAppIDRegistry creates the registry of the AppIDs of the roles, the configured AppIDs first, and then the
+// AppIDs of the application keys of the other roles, created on Avail if they don't exist, see CreateApplicationKey.
+// It takes a context bounding the wait for the creation of the keys, a client, the configured AppIDs and the
+// application keys of the roles, the signing key pair, and the submission options.
+// It returns the registry, and an error if an application key can't be created or if several roles share an AppID.
+func EnsureAppIDRegistry(ctx context.Context, client Client, appIDs map[Role]uint32, keys map[Role]string, signingKeyPair signature.KeyringPair, opts SubmitOpts) (*AppIDRegistry, error) {
+	return ensureAppIDRegistry(appIDs, keys, func(key string) (uint32, error) {
+		appID, err := CreateApplicationKey(ctx, client, key, signingKeyPair, opts)
+		return uint32(appID.Int64()), err
+	})
+}
+
+// ensureAppIDRegistry creates the registry of the configured AppIDs, and of the AppIDs of the application keys of
+// the other roles, returned by create.
+func ensureAppIDRegistry(appIDs map[Role]uint32, keys map[Role]string, create func(key string) (uint32, error)) (*AppIDRegistry, error) {
+	all := make(map[Role]uint32, len(appIDs)+len(keys))
+	for role, appID := range appIDs {
+		all[role] = appID
+	}
+
+	roles := make([]Role, 0, len(keys))
+	for role := range keys {
+		if _, ok := all[role]; !ok {
+			roles = append(roles, role)
+		}
+	}
+
+	sortRoles(roles)
+
+	for _, role := range roles {
+		appID, err := create(keys[role])
+		if err != nil {
+			return nil, fmt.Errorf("couldn't ensure the application key %q of role %s: %w", keys[role], role, err)
+		}
+
+		all[role] = appID
+	}
+
+	return NewAppIDRegistry(all)
+}
+
+// AppID returns the AppID of the role, or an error wrapping ErrRoleNotRegistered if there is none.
+func (r *AppIDRegistry) AppID(role Role) (uint32, error) {
+	appID, ok := r.appIDs[role]
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrRoleNotRegistered, role)
+	}
+
+	return appID, nil
+}
+
+// Role returns the role of the AppID, and whether it's registered.
+func (r *AppIDRegistry) Role(appID uint32) (Role, bool) {
+	role, ok := r.roles[appID]
+	return role, ok
+}
+
+// Roles returns the registered roles, sorted.
+func (r *AppIDRegistry) Roles() []Role {
+	roles := make([]Role, 0, len(r.appIDs))
+	for role := range r.appIDs {
+		roles = append(roles, role)
+	}
+
+	sortRoles(roles)
+
+	return roles
+}
+
+// Require checks that the roles are registered, e.g. at startup, so a missing AppID fails fast rather than on the
+// first submission. It returns an error wrapping ErrRoleNotRegistered listing the missing roles.
+func (r *AppIDRegistry) Require(roles ...Role) error {
+	var missing []string
+
+	for _, role := range roles {
+		if _, ok := r.appIDs[role]; !ok {
+			missing = append(missing, string(role))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w %s", ErrRoleNotRegistered, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// SubmitDataForRole submits the data to Avail under the AppID of the role, see SubmitData.
+// It returns an error wrapping ErrRoleNotRegistered if the role has no AppID, in which case nothing is submitted.
+func SubmitDataForRole(ctx context.Context, client Client, account signature.KeyringPair, registry *AppIDRegistry, role Role, data []byte, opts SubmitOpts) (*SubmitResult, error) {
+	appID, err := registry.AppID(role)
+	if err != nil {
+		return nil, err
+	}
+
+	return SubmitData(ctx, client, account, appID, data, opts)
+}
+
+// NewSenderForRole constructs a block data sender for Avail submitting under the AppID of the role, see NewSender.
+// It returns an error wrapping ErrRoleNotRegistered if the role has no AppID.
+func NewSenderForRole(client Client, registry *AppIDRegistry, role Role, signingKeyPair signature.KeyringPair, nonces *NonceManager, signOpts SignOpts) (Sender, error) {
+	appID, err := registry.AppID(role)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSender(client, types.NewUCompactFromUInt(uint64(appID)), signingKeyPair, nonces, signOpts), nil
+}
+
+// GetBlockExtrinsicsByRole fetches the Avail block once, and returns the data of its DataAvailability.submit_data
+// extrinsics submitted under the AppIDs of the roles, by role, in block order, see GetBlockExtrinsics.
+// It takes a client, the block hash, the registry, the signers the extrinsics are filtered by, all the signers
+// being accepted if it's empty, and the roles, all the registered ones if there are none.
+// It returns the data, an error wrapping ErrRoleNotRegistered if a role has no AppID, ErrBlockNotFound if the
+// block hash is invalid or unknown, ErrNoExtrinsicFound if no extrinsic matches, or an error if there is an issue.
+func GetBlockExtrinsicsByRole(client Client, blockHash types.Hash, registry *AppIDRegistry, signers []signature.KeyringPair, roles ...Role) (map[Role][][]byte, error) {
+	if len(roles) == 0 {
+		roles = registry.Roles()
+	}
+
+	if err := registry.Require(roles...); err != nil {
+		return nil, err
+	}
+
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	blk, callIdx, err := c.submitDataBlock(client, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[Role][][]byte, len(roles))
+
+	for _, role := range roles {
+		data, err := c.blockData(blk, callIdx, blockHash, registry.appIDs[role], signers)
+		if errors.Is(err, ErrNoExtrinsicFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		byRole[role] = data
+	}
+
+	if len(byRole) == 0 {
+		return nil, ErrNoExtrinsicFound
+	}
+
+	return byRole, nil
+}
+
+// sortRoles sorts the roles.
+func sortRoles(roles []Role) {
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+}
@@ -0,0 +1,128 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppIDRegistry(t *testing.T) {
+	r, err := NewAppIDRegistry(map[Role]uint32{RoleBlocks: 1, RoleFraudProofs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appID, err := r.AppID(RoleFraudProofs)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint32(2), appID)
+	}
+
+	_, err = r.AppID(RoleBridgeMessages)
+	assert.ErrorIs(t, err, ErrRoleNotRegistered)
+
+	role, ok := r.Role(1)
+	assert.True(t, ok)
+	assert.Equal(t, RoleBlocks, role)
+
+	_, ok = r.Role(3)
+	assert.False(t, ok)
+
+	assert.Equal(t, []Role{RoleBlocks, RoleFraudProofs}, r.Roles())
+	assert.NoError(t, r.Require(RoleBlocks, RoleFraudProofs))
+
+	err = r.Require(RoleBlocks, RoleBridgeMessages, "other")
+	assert.ErrorIs(t, err, ErrRoleNotRegistered)
+	assert.EqualError(t, err, "no AppID registered for role bridge-messages, other")
+
+	_, err = NewAppIDRegistry(map[Role]uint32{RoleBlocks: 1, RoleFraudProofs: 1})
+	assert.EqualError(t, err, "roles blocks and fraud-proofs share AppID 1")
+}
+
+func TestEnsureAppIDRegistry(t *testing.T) {
+	var created []string
+
+	create := func(key string) (uint32, error) {
+		created = append(created, key)
+		return uint32(10 + len(created)), nil
+	}
+
+	// The configured AppIDs are kept, and the application keys of the other roles are created.
+	r, err := ensureAppIDRegistry(
+		map[Role]uint32{RoleBlocks: 1},
+		map[Role]string{RoleBlocks: "op-evm", RoleFraudProofs: "op-evm-fraud", RoleBridgeMessages: "op-evm-bridge"},
+		create,
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"op-evm-bridge", "op-evm-fraud"}, created)
+
+	for role, expected := range map[Role]uint32{RoleBlocks: 1, RoleBridgeMessages: 11, RoleFraudProofs: 12} {
+		appID, err := r.AppID(role)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, appID, role)
+		}
+	}
+
+	errRefused := errors.New("connection refused")
+
+	_, err = ensureAppIDRegistry(nil, map[Role]string{RoleBlocks: "op-evm"}, func(string) (uint32, error) { return 0, errRefused })
+	assert.ErrorIs(t, err, errRefused)
+}
+
+func TestSubmitDataForRole(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	r, err := NewAppIDRegistry(map[Role]uint32{RoleBlocks: 1, RoleFraudProofs: 2, RoleBridgeMessages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := SubmitDataForRole(context.Background(), m, funder, r, RoleBlocks, []byte("block"), SubmitOpts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	fraudProofs, err := SubmitDataForRole(context.Background(), m, funder, r, RoleFraudProofs, []byte("fraud proof"), SubmitOpts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 2) {
+		assert.Equal(t, int64(1), submitted[0].Signature.AppID.Int64())
+		assert.Equal(t, int64(2), submitted[1].Signature.AppID.Int64())
+	}
+
+	// An unregistered role fails before submitting anything.
+	unregistered, err := NewAppIDRegistry(map[Role]uint32{RoleBlocks: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = SubmitDataForRole(context.Background(), m, funder, unregistered, RoleFraudProofs, []byte("fraud proof"), SubmitOpts{})
+	assert.ErrorIs(t, err, ErrRoleNotRegistered)
+	assert.Len(t, m.Submitted(), 2)
+
+	_, err = NewSenderForRole(m, unregistered, RoleFraudProofs, funder, nil, SignOpts{})
+	assert.ErrorIs(t, err, ErrRoleNotRegistered)
+
+	// The data of a block is filtered by role.
+	byRole, err := GetBlockExtrinsicsByRole(m, fraudProofs.BlockHash, r, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[Role][][]byte{RoleFraudProofs: {[]byte("fraud proof")}}, byRole)
+	}
+
+	byRole, err = GetBlockExtrinsicsByRole(m, blocks.BlockHash, r, nil, RoleBlocks)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[Role][][]byte{RoleBlocks: {[]byte("block")}}, byRole)
+	}
+
+	_, err = GetBlockExtrinsicsByRole(m, blocks.BlockHash, r, nil, RoleBridgeMessages)
+	assert.ErrorIs(t, err, ErrNoExtrinsicFound)
+
+	_, err = GetBlockExtrinsicsByRole(m, blocks.BlockHash, unregistered, nil, RoleFraudProofs)
+	assert.ErrorIs(t, err, ErrRoleNotRegistered)
+}
@@ -1,8 +1,10 @@
 package avail
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
@@ -26,44 +28,52 @@ var (
 )
 
 // EnsureApplicationKeyExists checks if the application key exists on the blockchain. If it doesn't exist, it creates a new application key.
-// It takes a client, the application key string, and the signing key pair.
+// It takes a context bounding the wait for the creation of the key, a client, the application key string,
+// the signing key pair, and the submission options.
 // It returns the AppID and an error if there is an issue.
-func EnsureApplicationKeyExists(client Client, applicationKey string, signingKeyPair signature.KeyringPair) (types.UCompact, error) {
-	appID, err := QueryAppID(client, applicationKey)
-	if errors.Is(err, ErrAppIDNotFound) {
-		appID, err = CreateApplicationKey(client, applicationKey, signingKeyPair)
-		if err != nil {
-			return types.NewUCompactFromUInt(0), err
-		}
-	} else if err != nil {
+func EnsureApplicationKeyExists(ctx context.Context, client Client, applicationKey string, signingKeyPair signature.KeyringPair, opts SubmitOpts) (types.UCompact, error) {
+	// CreateApplicationKey returns the AppID of an existing application key.
+	return CreateApplicationKey(ctx, client, applicationKey, signingKeyPair, opts)
+}
+
+// QueryAppID retrieves the AppID associated with the application key.
+// It takes a client and the application key string.
+// It returns the AppID, and ErrAppIDNotFound if the application key doesn't exist, or an error if there is an issue.
+func QueryAppID(client Client, applicationKey string) (types.UCompact, error) {
+	appID, ok, err := GetApplicationKey(client, applicationKey)
+	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
 
-	return appID, nil
+	if !ok {
+		return types.NewUCompactFromUInt(0), ErrAppIDNotFound
+	}
+
+	return types.NewUCompactFromUInt(uint64(appID)), nil
 }
 
-// QueryAppID retrieves the AppID associated with the application key.
+// GetApplicationKey looks up the application key in the DataAvailability.AppKeys storage map.
 // It takes a client and the application key string.
-// It returns the AppID and an error if there is an issue.
-func QueryAppID(client Client, applicationKey string) (types.UCompact, error) {
+// It returns the AppID, whether the application key exists, and an error if there is an issue.
+func GetApplicationKey(client Client, applicationKey string) (uint32, bool, error) {
 	api, err := instance(client)
 	if err != nil {
-		return types.NewUCompactFromUInt(0), err
+		return 0, false, err
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := latestMetadata(client, api)
 	if err != nil {
-		return types.NewUCompactFromUInt(0), err
+		return 0, false, err
 	}
 
 	encodedAppKey, err := codec.Encode([]byte(applicationKey))
 	if err != nil {
-		return types.NewUCompactFromUInt(0), err
+		return 0, false, err
 	}
 
 	key, err := types.CreateStorageKey(meta, "DataAvailability", "AppKeys", encodedAppKey)
 	if err != nil {
-		return types.NewUCompactFromUInt(0), err
+		return 0, false, err
 	}
 
 	type AppKeyInfo struct {
@@ -73,27 +83,34 @@ func QueryAppID(client Client, applicationKey string) (types.UCompact, error) {
 
 	var aki AppKeyInfo
 	ok, err := api.RPC.State.GetStorageLatest(key, &aki)
-	if err != nil {
-		return types.NewUCompactFromUInt(0), err
+	if err != nil || !ok {
+		return 0, false, err
 	}
 
-	if ok {
-		return aki.AppID, nil
-	} else {
-		return types.NewUCompactFromUInt(0), ErrAppIDNotFound
-	}
+	return uint32(aki.AppID.Int64()), true, nil
 }
 
-// CreateApplicationKey creates a new application key on the blockchain.
-// It takes a client, the application key string, and the signing key pair.
-// It returns the AppID and an error if there is an issue.
-func CreateApplicationKey(client Client, applicationKey string, signingKeyPair signature.KeyringPair) (types.UCompact, error) {
-	api, err := instance(client)
+// CreateApplicationKey creates a new application key on the blockchain, and returns the AppID assigned by the
+// ApplicationKeyCreated event. If the application key already exists, its AppID is returned and nothing is submitted.
+// It takes a context bounding the wait for the inclusion of the extrinsic, a client, the application key string,
+// the signing key pair, and the submission options.
+// The extrinsic is signed with the next nonce handed out by the nonce manager of the options, or looked up on chain if it's nil.
+// It returns the AppID, and a *SubmitTimeoutError if the context is done before the inclusion, an error wrapping
+// ErrExtrinsicRetracted or ErrFinalityTimeout if the extrinsic won't be finalized while waiting for it, or an error
+// if there is an issue.
+func CreateApplicationKey(ctx context.Context, client Client, applicationKey string, signingKeyPair signature.KeyringPair, opts SubmitOpts) (types.UCompact, error) {
+	c, err := implementation(client)
 	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	if appID, ok, err := GetApplicationKey(client, applicationKey); err != nil || ok {
+		return types.NewUCompactFromUInt(uint64(appID)), err
+	}
+
+	api := c.instance()
+
+	meta, err := c.metadata(api)
 	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
@@ -105,66 +122,98 @@ func CreateApplicationKey(client Client, applicationKey string, signingKeyPair s
 
 	ext := types.NewExtrinsic(call)
 
-	rv, err := api.RPC.State.GetRuntimeVersionLatest()
+	rv, err := c.runtimeVersion(api)
 	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
 
-	key, err := types.CreateStorageKey(meta, "System", "Account", signingKeyPair.PublicKey)
+	era, blockHash, err := signingEra(api.RPC, c.genesisHash, opts.SignOpts)
 	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
 
-	var accountInfo types.AccountInfo
-	ok, err := api.RPC.State.GetStorageLatest(key, &accountInfo)
-	if err != nil || !ok {
-		return types.NewUCompactFromUInt(0), fmt.Errorf("couldn't fetch latest account storage info: %w", err)
+	start := time.Now()
+
+	sub, signed, _, err := c.signAndWatch(ctx, api, meta, signingKeyPair, opts, func(nonce uint64) (types.Extrinsic, error) {
+		o := types.SignatureOptions{
+			// An immortal transaction (https://wiki.polkadot.network/docs/build-protocol-info#transaction-mortality)
+			// is checked against the genesis hash, a mortal one against the block its era starts at.
+			BlockHash:          blockHash,
+			Era:                era,
+			GenesisHash:        c.genesisHash,
+			Nonce:              types.NewUCompactFromUInt(nonce),
+			SpecVersion:        rv.SpecVersion,
+			Tip:                opts.tipOr(100),
+			AppID:              DefaultAppID,
+			TransactionVersion: rv.TransactionVersion,
+		}
+
+		signed := ext
+		err := signed.Sign(signingKeyPair, o)
+
+		return signed, err
+	})
+	if err != nil {
+		c.recordSubmission(CallCreateApplicationKey, opts.WaitFor, start, err)
+		return types.NewUCompactFromUInt(0), err
 	}
 
-	genesisHash, err := api.RPC.Chain.GetBlockHash(0)
+	defer sub.Unsubscribe()
+
+	statuses := c.dispatchStatuses(opts)
+	defer statuses.close()
+
+	extrinsicHash, err := hashExtrinsic(signed)
 	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
 
-	nonce := uint64(accountInfo.Nonce)
-	o := types.SignatureOptions{
-		// This transaction is Immortal (https://wiki.polkadot.network/docs/build-protocol-info#transaction-mortality)
-		// Hence BlockHash: Genesis Hash.
-		BlockHash:          genesisHash,
-		Era:                types.ExtrinsicEra{IsMortalEra: false},
-		GenesisHash:        genesisHash,
-		Nonce:              types.NewUCompactFromUInt(nonce),
-		SpecVersion:        rv.SpecVersion,
-		Tip:                types.NewUCompactFromUInt(100),
-		AppID:              DefaultAppID,
-		TransactionVersion: rv.TransactionVersion,
+	includedIn, _, err := waitForInclusion(ctx, sub, extrinsicHash, signingKeyPair, opts.Nonces, opts.WaitFor, statuses)
+	c.recordSubmission(CallCreateApplicationKey, opts.WaitFor, start, err)
+
+	if err != nil {
+		return types.NewUCompactFromUInt(0), fmt.Errorf("error while waiting for application key creation status: %w", err)
 	}
 
-	err = ext.Sign(signingKeyPair, o)
+	raw, index, _, err := includedExtrinsicEvents(c.instance().RPC, meta, includedIn, extrinsicHash)
 	if err != nil {
 		return types.NewUCompactFromUInt(0), err
 	}
 
-	sub, err := api.RPC.Author.SubmitAndWatchExtrinsic(ext)
+	appID, err := createdAppID(meta, raw, index)
+	if errors.Is(err, ErrExtrinsicFailed) {
+		// The application key may have been created by someone else in the meantime.
+		if existing, ok, lookupErr := GetApplicationKey(client, applicationKey); lookupErr == nil && ok {
+			return types.NewUCompactFromUInt(uint64(existing)), nil
+		}
+	}
+
 	if err != nil {
-		return types.NewUCompactFromUInt(0), err
+		return types.NewUCompactFromUInt(0), fmt.Errorf("application key creation failed: %w", c.invalidateMetadataOnMismatch(err))
 	}
 
-	defer sub.Unsubscribe()
+	return types.NewUCompactFromUInt(uint64(appID)), nil
+}
 
-	for {
-		select {
-		case status := <-sub.Chan():
-			if status.IsInBlock {
-				return QueryAppID(client, applicationKey)
-			}
+// createdAppID decodes the events and returns the AppID of the ApplicationKeyCreated event of the extrinsic at
+// the index in the block, or a *DispatchError if the extrinsic failed.
+func createdAppID(meta *types.Metadata, raw types.EventRecordsRaw, index uint32) (uint32, error) {
+	var events availEventRecords
+	if err := raw.DecodeEventRecords(meta, &events); err != nil {
+		return 0, fmt.Errorf("couldn't decode the events of the block including the extrinsic: %w", &metadataMismatchError{err})
+	}
 
-			if status.IsDropped || status.IsInvalid {
-				return types.NewUCompactFromUInt(0), fmt.Errorf("unexpected extrinsic status from Avail: %#v", status)
-			}
+	for _, ev := range events.System_ExtrinsicFailed {
+		if ev.Phase.IsApplyExtrinsic && ev.Phase.AsApplyExtrinsic == index {
+			return 0, newDispatchError(meta, ev.DispatchError)
+		}
+	}
 
-		case err = <-sub.Err():
-			return types.NewUCompactFromUInt(0), fmt.Errorf("error while waiting for application key creation status: %w", err)
+	for _, ev := range events.DataAvailability_ApplicationKeyCreated {
+		if ev.Phase.IsApplyExtrinsic && ev.Phase.AsApplyExtrinsic == index {
+			return uint32(ev.ID.Int64()), nil
 		}
 	}
+
+	return 0, errors.New("no ApplicationKeyCreated event for the extrinsic")
 }
@@ -0,0 +1,29 @@
+package avail
+
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatedAppIDWithoutEvent(t *testing.T) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	events := &eventRecordsBuilder{t: t}
+	events.add(0, eventExtrinsicSuccess, dispatchInfo)
+	events.add(1, eventExtrinsicFailed, balancesInsufficientBalance, dispatchInfo)
+
+	raw := events.raw()
+
+	_, err := createdAppID(&meta, raw, 0)
+	assert.EqualError(t, err, "no ApplicationKeyCreated event for the extrinsic")
+
+	_, err = createdAppID(&meta, raw, 1)
+	assert.ErrorIs(t, err, ErrExtrinsicFailed)
+	assert.Contains(t, err.Error(), "InsufficientBalance")
+}
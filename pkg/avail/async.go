@@ -0,0 +1,164 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// DefaultMaxPendingSubmissions is the default maximum number of the submissions a client watches in the background.
+const DefaultMaxPendingSubmissions = 64
+
+// ErrTooManyPendingSubmissions is the error returned when submitting data asynchronously while the client already
+// watches the maximum number of submissions in the background, in which case nothing is submitted.
+var ErrTooManyPendingSubmissions = errors.New("too many pending Avail submissions")
+
+// WithMaxPendingSubmissions sets the maximum number of the submissions watched in the background by the client,
+// DefaultMaxPendingSubmissions by default, see SubmitDataAsync.
+func WithMaxPendingSubmissions(n int) ClientOption {
+	return func(c *client) {
+		if n > 0 {
+			c.maxPendingSubmissions = n
+		}
+	}
+}
+
+// PendingResult is the outcome of a PendingSubmission.
+type PendingResult struct {
+	// Result locates the extrinsic on Avail, like the result of SubmitData. It's nil if the extrinsic wasn't included.
+	Result *SubmitResult
+	// Err is the error of the submission, like the ones returned by SubmitData.
+	Err error
+}
+
+// PendingSubmission is a submission accepted by the transaction pool of the Avail node, whose inclusion, or
+// finalization, is watched in the background.
+type PendingSubmission struct {
+	// ExtrinsicHash is the hash of the submitted extrinsic, and Nonce the nonce it was signed with.
+	ExtrinsicHash types.Hash
+	Nonce         uint64
+
+	result chan PendingResult
+	cancel context.CancelFunc
+}
+
+// Result returns the channel receiving the outcome of the submission, once it's included, or finalized, or the
+// watch failed or was cancelled.
+func (p *PendingSubmission) Result() <-chan PendingResult {
+	return p.result
+}
+
+// Cancel stops watching the submission; the extrinsic itself is still in the transaction pool, and may be included.
+// The outcome is then a *SubmitTimeoutError wrapping context.Canceled, unless it was already known.
+func (p *PendingSubmission) Cancel() {
+	p.cancel()
+}
+
+// SubmitDataAsync submits the data to Avail under the AppID, signed with the account, and returns as soon as the
+// extrinsic is accepted by the transaction pool, e.g. so the block production doesn't wait for the finality of its
+// data, see SubmitData. The inclusion, or the finalization, of the extrinsic is watched in the background, until the
+// context is done, the submission is cancelled, or the client is closed, and its outcome delivered by the pending
+// submission.
+// It takes the same arguments as SubmitData, the context bounding both the submission and the watch.
+// It returns the pending submission, and an error wrapping ErrTooManyPendingSubmissions if the client already
+// watches the maximum number of submissions, see WithMaxPendingSubmissions, or the errors SubmitData returns
+// before the extrinsic is accepted, in which case nothing is watched.
+func SubmitDataAsync(ctx context.Context, client Client, account signature.KeyringPair, appID uint32, data []byte, opts SubmitOpts) (*PendingSubmission, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	api := c.instance()
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := c.submitDataCall(meta, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := c.callSigner(api, account, call, appID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submitAsync(ctx, api, meta, account, opts, sign)
+}
+
+// submitAsync submits the extrinsic signed by sign and watches its inclusion, or finalization, in the background,
+// see SubmitDataAsync.
+func (c *client) submitAsync(ctx context.Context, api *gsrpc.SubstrateAPI, meta *types.Metadata, account signature.KeyringPair, opts SubmitOpts, sign signFunc) (*PendingSubmission, error) {
+	select {
+	case c.pendingSubmissions <- struct{}{}:
+	default:
+		return nil, fmt.Errorf("%w: %d watched", ErrTooManyPendingSubmissions, cap(c.pendingSubmissions))
+	}
+
+	start := time.Now()
+
+	sub, signed, nonce, err := c.signAndWatch(ctx, api, meta, account, opts, sign)
+	if err != nil {
+		<-c.pendingSubmissions
+		c.recordSubmission(CallSubmitData, opts.WaitFor, start, err)
+		return nil, err
+	}
+
+	extrinsicHash, err := hashExtrinsic(signed)
+	if err != nil {
+		<-c.pendingSubmissions
+		sub.Unsubscribe()
+		return nil, err
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	p := &PendingSubmission{
+		ExtrinsicHash: extrinsicHash,
+		Nonce:         nonce,
+		result:        make(chan PendingResult, 1),
+		cancel:        cancel,
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	// The watch is cancelled when the client is closed.
+	go func() {
+		defer wg.Done()
+
+		select {
+		case <-c.closeCh:
+			cancel()
+		case <-watchCtx.Done():
+		}
+	}()
+
+	go func() {
+		result, err := c.awaitInclusion(watchCtx, sub, signed, account, nonce, opts)
+		if err == nil {
+			err = c.dispatchOutcome(api.RPC, meta, result)
+		}
+
+		c.recordSubmission(CallSubmitData, opts.WaitFor, start, err)
+
+		// The watch is released before its outcome is delivered, so another submission can be watched right away.
+		cancel()
+		wg.Wait()
+		<-c.pendingSubmissions
+
+		p.result <- PendingResult{Result: result, Err: err}
+	}()
+
+	return p, nil
+}
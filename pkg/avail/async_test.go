@@ -0,0 +1,128 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// pendingResult returns the outcome of the pending submission, failing the test if it isn't delivered in time.
+func pendingResult(t *testing.T, p *PendingSubmission) PendingResult {
+	t.Helper()
+
+	select {
+	case result := <-p.Result():
+		return result
+	case <-time.After(time.Second):
+		t.Fatalf("no outcome of submission %s", p.ExtrinsicHash.Hex())
+		return PendingResult{}
+	}
+}
+
+func TestSubmitDataAsync(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	p, err := SubmitDataAsync(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{WaitFor: WaitFinalized})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	outcome := pendingResult(t, p)
+	if assert.NoError(t, outcome.Err) {
+		assert.Equal(t, p.ExtrinsicHash, outcome.Result.ExtrinsicHash)
+		assert.Equal(t, uint64(1), outcome.Result.BlockNumber)
+		assert.True(t, outcome.Result.Finalized)
+	}
+
+	assert.Empty(t, m.pendingSubmissions)
+
+	// A submission rejected by the transaction pool isn't watched.
+	m.Script(MockOutcome{Err: errors.New("1010: Invalid Transaction")})
+
+	_, err = SubmitDataAsync(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{})
+	assert.EqualError(t, err, "1010: Invalid Transaction")
+	assert.Empty(t, m.pendingSubmissions)
+
+	// The included submission failing to dispatch is delivered with its result.
+	m.Script(MockOutcome{DispatchError: m.ModuleError("Balances", "InsufficientBalance")})
+
+	p, err = SubmitDataAsync(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{})
+	if assert.NoError(t, err) {
+		outcome := pendingResult(t, p)
+
+		var dispatchErr *DispatchError
+		assert.ErrorAs(t, outcome.Err, &dispatchErr)
+		assert.NotNil(t, outcome.Result)
+	}
+}
+
+func TestSubmitDataAsyncBoundsWatches(t *testing.T) {
+	m, err := NewMockClient(WithRetryPolicy(testRetryPolicy), WithMaxPendingSubmissions(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(account, new(big.Int).SetUint64(AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The submissions are accepted, but never included.
+	m.client.submitAndWatch = func(_ *gsrpc.SubstrateAPI, _ types.Extrinsic) (extrinsicSubscription, error) {
+		return newFakeSubscription(nil, types.ExtrinsicStatus{IsReady: true}), nil
+	}
+
+	opts := SubmitOpts{Nonces: NewNonceManager()}
+
+	submit := func() (*PendingSubmission, error) {
+		return SubmitDataAsync(context.Background(), m, account, 1, []byte("data"), opts)
+	}
+
+	first, err := submit()
+	assert.NoError(t, err)
+
+	second, err := submit()
+	assert.NoError(t, err)
+
+	_, err = submit()
+	assert.ErrorIs(t, err, ErrTooManyPendingSubmissions)
+
+	// A cancelled watch makes room for another one, the extrinsic staying in the pool.
+	first.Cancel()
+
+	outcome := pendingResult(t, first)
+
+	var timeoutErr *SubmitTimeoutError
+	if assert.ErrorAs(t, outcome.Err, &timeoutErr) {
+		assert.Equal(t, first.ExtrinsicHash, timeoutErr.ExtrinsicHash)
+		assert.ErrorIs(t, outcome.Err, context.Canceled)
+	}
+
+	assert.Nil(t, outcome.Result)
+
+	third, err := submit()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, second.Nonce+1, third.Nonce)
+
+	// The watches are cancelled once the client is closed.
+	m.Close()
+
+	for _, p := range []*PendingSubmission{second, third} {
+		assert.ErrorIs(t, pendingResult(t, p).Err, context.Canceled)
+	}
+
+	assert.Empty(t, m.pendingSubmissions)
+}
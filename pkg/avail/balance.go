@@ -0,0 +1,221 @@
+package avail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+)
+
+// storageSubscription is the subscription to the changes of storage keys, i.e. a *state.StorageSubscription.
+type storageSubscription interface {
+	Chan() <-chan types.StorageChangeSet
+	Err() <-chan error
+	Unsubscribe()
+}
+
+// BalanceUpdate is the balance of an Avail account after a change, in Avail fractions.
+type BalanceUpdate struct {
+	// BlockHash is the hash of the block the balance was changed in.
+	BlockHash types.Hash
+	// Free is the balance that can be transferred and used for fees.
+	Free *big.Int
+	// Reserved is the balance reserved by the runtime, which can't be used until it's unreserved.
+	Reserved *big.Int
+	// Exists tells whether the account exists. The balances of an account that doesn't exist, or was reaped, are zero.
+	Exists bool
+}
+
+// SubscribeBalance follows the balance of the account, with the storage subscription of its System.Account key.
+// The current balance is emitted first, and then the balance after every change.
+// When the subscription fails, e.g. on a WebSocket disconnection, the connection is re-established following the
+// retry policy of the client, and the balance is emitted again only if it changed meanwhile.
+// It takes a context ending the subscription, a client and the account key pair.
+// It returns the channel of the balance updates, which is closed once the context is done, or when the
+// reconnections failed, which is logged. It returns an error wrapping ErrSubscriptionsUnsupported if the client is
+// connected over HTTP, or an error if the subscription can't be established.
+func SubscribeBalance(ctx context.Context, client Client, account signature.KeyringPair) (<-chan BalanceUpdate, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.requireSubscriptions("SubscribeBalance"); err != nil {
+		return nil, err
+	}
+
+	api := c.instance()
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := accountStorageKey(meta, account)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := c.storageChanges(api, key)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &balanceFollower{
+		c:       c,
+		key:     key,
+		updates: make(chan BalanceUpdate),
+	}
+
+	go f.follow(ctx, sub)
+
+	return f.updates, nil
+}
+
+// storageChanges subscribes to the changes of the storage key with the api.
+func (c *client) storageChanges(api *gsrpc.SubstrateAPI, key types.StorageKey) (storageSubscription, error) {
+	if c.subscribeStorage != nil {
+		return c.subscribeStorage(api, key)
+	}
+
+	sub, err := api.RPC.State.SubscribeStorageRaw([]types.StorageKey{key})
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// balanceFollower emits the balances decoded from the changes of the account storage of the subscriptions.
+type balanceFollower struct {
+	c       *client
+	key     types.StorageKey
+	updates chan BalanceUpdate
+
+	// last is the last emitted balance, if any.
+	last *BalanceUpdate
+}
+
+// follow emits the balances of the subscription, and re-establishes it when it fails, until the reconnections
+// fail or the context is done.
+func (f *balanceFollower) follow(ctx context.Context, sub storageSubscription) {
+	defer close(f.updates)
+
+	attempt := 0
+
+	for {
+		progressed, err := f.forward(ctx, sub)
+		sub.Unsubscribe()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// The attempts are counted from the last received change.
+		if progressed {
+			attempt = 0
+		}
+
+		for sub = nil; sub == nil; {
+			if attempt++; attempt > f.c.retry.MaxReconnects {
+				f.c.logger.Error("balance subscription failed", "error", err)
+				return
+			}
+
+			f.c.logger.Warn("balance subscription failed, reconnecting", "attempt", attempt, "error", err)
+
+			select {
+			case <-time.After(time.Duration(attempt) * f.c.retry.Backoff):
+			case <-ctx.Done():
+				return
+			}
+
+			var api *gsrpc.SubstrateAPI
+			if api, err = f.c.reconnect(); err != nil {
+				continue
+			}
+
+			sub, err = f.c.storageChanges(api, f.key)
+		}
+	}
+}
+
+// forward emits the balances of the subscription until it fails or the context is done, and returns whether
+// a change was received.
+func (f *balanceFollower) forward(ctx context.Context, sub storageSubscription) (bool, error) {
+	progressed := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return progressed, ctx.Err()
+		case err := <-sub.Err():
+			return progressed, err
+		case set, ok := <-sub.Chan():
+			if !ok {
+				return progressed, errors.New("balance subscription closed")
+			}
+
+			if err := f.emit(ctx, set); err != nil {
+				return progressed, err
+			}
+
+			progressed = true
+		}
+	}
+}
+
+// emit emits the balance of the account storage change of the change set, unless it's the last emitted one.
+func (f *balanceFollower) emit(ctx context.Context, set types.StorageChangeSet) error {
+	for _, change := range set.Changes {
+		if !bytes.Equal(change.StorageKey, f.key) {
+			continue
+		}
+
+		update, err := decodeBalanceUpdate(set.Block, change)
+		if err != nil {
+			return err
+		}
+
+		if f.last != nil && f.last.Exists == update.Exists && f.last.Free.Cmp(update.Free) == 0 &&
+			f.last.Reserved.Cmp(update.Reserved) == 0 {
+			continue
+		}
+
+		select {
+		case f.updates <- update:
+			f.last = &update
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// decodeBalanceUpdate decodes the balance of the account storage change.
+func decodeBalanceUpdate(blockHash types.Hash, change types.KeyValueOption) (BalanceUpdate, error) {
+	update := BalanceUpdate{BlockHash: blockHash, Free: big.NewInt(0), Reserved: big.NewInt(0)}
+
+	if !change.HasStorageData {
+		return update, nil
+	}
+
+	var accountInfo types.AccountInfo
+	if err := codec.Decode(change.StorageData, &accountInfo); err != nil {
+		return update, fmt.Errorf("couldn't decode the account storage: %w", err)
+	}
+
+	update.Free = u128ToBig(accountInfo.Data.Free)
+	update.Reserved = u128ToBig(accountInfo.Data.Reserved)
+	update.Exists = true
+
+	return update, nil
+}
@@ -0,0 +1,208 @@
+package avail
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStorageSubscription is a storage subscription delivering change sets in order, and then failing with an error.
+type fakeStorageSubscription struct {
+	setCh chan types.StorageChangeSet
+	errCh chan error
+	done  chan struct{}
+}
+
+func newFakeStorageSubscription(err error, sets ...types.StorageChangeSet) *fakeStorageSubscription {
+	sub := &fakeStorageSubscription{
+		setCh: make(chan types.StorageChangeSet),
+		errCh: make(chan error),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		for _, set := range sets {
+			select {
+			case sub.setCh <- set:
+			case <-sub.done:
+				return
+			}
+		}
+
+		if err != nil {
+			select {
+			case sub.errCh <- err:
+			case <-sub.done:
+			}
+		}
+	}()
+
+	return sub
+}
+
+func (s *fakeStorageSubscription) Chan() <-chan types.StorageChangeSet { return s.setCh }
+func (s *fakeStorageSubscription) Err() <-chan error                   { return s.errCh }
+func (s *fakeStorageSubscription) Unsubscribe()                        { close(s.done) }
+
+// balanceTest is a client whose storage subscriptions of the account are served in order, on a chain of 5 blocks of
+// a mock client. The subscriptions are faked, as the mock client can't fail them.
+type balanceTest struct {
+	m          *MockClient
+	c          *client
+	account    signature.KeyringPair
+	accountKey types.StorageKey
+	dials      int
+}
+
+func newBalanceTest(t *testing.T, policy RetryPolicy) *balanceTest {
+	m, err := NewMockClient(WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accountKey, err := accountStorageKey(m.meta, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 1; n < 5; n++ {
+		produceBlock(t, m)
+	}
+
+	bt := &balanceTest{m: m, c: m.client, account: account, accountKey: accountKey}
+
+	api := bt.c.instance()
+
+	bt.c.dial = func(_ string) (*gsrpc.SubstrateAPI, error) {
+		bt.dials++
+		return api, nil
+	}
+
+	return bt
+}
+
+// subscribe subscribes to the balance of the account, served by the subscriptions in order.
+func (bt *balanceTest) subscribe(t *testing.T, ctx context.Context, subs ...storageSubscription) <-chan BalanceUpdate {
+	served := 0
+
+	bt.c.subscribeStorage = func(_ *gsrpc.SubstrateAPI, key types.StorageKey) (storageSubscription, error) {
+		assert.Equal(t, bt.accountKey, key)
+
+		if served++; served > len(subs) {
+			return nil, errSubmitStopped
+		}
+
+		return subs[served-1], nil
+	}
+
+	updates, err := SubscribeBalance(ctx, bt.c, bt.account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return updates
+}
+
+// accountChange returns the change set of the account storage in the block, with the free and reserved
+// balances, or without storage data if free is negative.
+func (bt *balanceTest) accountChange(t *testing.T, n uint64, free, reserved int64) types.StorageChangeSet {
+	change := types.KeyValueOption{StorageKey: bt.accountKey}
+
+	if free >= 0 {
+		var info types.AccountInfo
+		info.Data.Free = types.NewU128(*big.NewInt(free))
+		info.Data.Reserved = types.NewU128(*big.NewInt(reserved))
+		info.Data.MiscFrozen = types.NewU128(*big.NewInt(0))
+		info.Data.FreeFrozen = types.NewU128(*big.NewInt(0))
+
+		encoded, err := codec.Encode(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		change.HasStorageData, change.StorageData = true, encoded
+	}
+
+	return types.StorageChangeSet{Block: blockHashOf(t, bt.m, n), Changes: []types.KeyValueOption{change}}
+}
+
+// receiveBalances returns the free and reserved balances received until the channel is closed.
+func receiveBalances(t *testing.T, updates <-chan BalanceUpdate) [][2]int64 {
+	t.Helper()
+
+	var balances [][2]int64
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return balances
+			}
+
+			balances = append(balances, [2]int64{update.Free.Int64(), update.Reserved.Int64()})
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out receiving the balance updates")
+		}
+	}
+}
+
+func TestSubscribeBalance(t *testing.T) {
+	bt := newBalanceTest(t, RetryPolicy{})
+
+	updates := bt.subscribe(t, context.Background(), newFakeStorageSubscription(errConnectionReset,
+		bt.accountChange(t, 1, 10, 0),
+		bt.accountChange(t, 2, 7, 2),
+		bt.accountChange(t, 3, -1, 0),
+	))
+
+	first := <-updates
+	assert.Equal(t, blockHashOf(t, bt.m, 1), first.BlockHash)
+	assert.Equal(t, int64(10), first.Free.Int64())
+	assert.True(t, first.Exists)
+
+	// The reaped account has zero balances, and the channel is closed when the subscription fails.
+	assert.Equal(t, [][2]int64{{7, 2}, {0, 0}}, receiveBalances(t, updates))
+	assert.Equal(t, 0, bt.dials)
+}
+
+func TestSubscribeBalanceReconnects(t *testing.T) {
+	bt := newBalanceTest(t, testRetryPolicy)
+
+	updates := bt.subscribe(t, context.Background(),
+		newFakeStorageSubscription(errConnectionReset, bt.accountChange(t, 1, 10, 0), bt.accountChange(t, 2, 8, 0)),
+		// The resubscription sends the current balance first, which isn't emitted again as it didn't change.
+		newFakeStorageSubscription(errConnectionReset, bt.accountChange(t, 3, 8, 0), bt.accountChange(t, 4, 5, 1)),
+	)
+
+	assert.Equal(t, [][2]int64{{10, 0}, {8, 0}, {5, 1}}, receiveBalances(t, updates))
+
+	// The reconnections are counted from the last received change.
+	assert.Equal(t, 1+testRetryPolicy.MaxReconnects, bt.dials)
+}
+
+func TestSubscribeBalanceContextCancellation(t *testing.T) {
+	bt := newBalanceTest(t, testRetryPolicy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	updates := bt.subscribe(t, ctx, newFakeStorageSubscription(nil, bt.accountChange(t, 1, 10, 0), bt.accountChange(t, 2, 8, 0)))
+
+	assert.Equal(t, int64(10), (<-updates).Free.Int64())
+
+	cancel()
+
+	receiveBalances(t, updates)
+	assert.Equal(t, 0, bt.dials)
+}
@@ -0,0 +1,289 @@
+package avail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+const (
+	// CallBatch is the RPC API call dispatching a batch of calls until the first failing one.
+	CallBatch = "Utility.batch"
+
+	// CallBatchAll is the RPC API call dispatching a batch of calls atomically.
+	CallBatchAll = "Utility.batch_all"
+)
+
+// ErrExtrinsicFailed is the error returned when an included extrinsic failed to dispatch, e.g. an atomic
+// batch with a failing call. It's matched by a *DispatchError.
+var ErrExtrinsicFailed = errors.New("extrinsic failed")
+
+// BatchInterruptedError is the error returned by SubmitBatch when a non-atomic batch stopped at a failing call.
+// The calls before the failing one were dispatched.
+type BatchInterruptedError struct {
+	// Index is the index of the failing call in the batch.
+	Index uint32
+	// Reason is the dispatch error of the failing call.
+	Reason string
+}
+
+func (e *BatchInterruptedError) Error() string {
+	return fmt.Sprintf("batch interrupted at call %d: %s", e.Index, e.Reason)
+}
+
+// availEventRecords are the event records of an Avail block, i.e. the default ones and the Avail specific ones.
+//
+//nolint:stylecheck,revive
+type availEventRecords struct {
+	types.EventRecords
+
+	Utility_BatchCompletedWithErrors []eventUtilityBatchCompletedWithErrors
+	Utility_ItemFailed               []eventUtilityItemFailed
+
+	DataAvailability_ApplicationKeyCreated        []eventDataAvailabilityApplicationKeyCreated
+	DataAvailability_DataSubmitted                []eventDataAvailabilityDataSubmitted
+	DataAvailability_BlockLengthProposalSubmitted []eventDataAvailabilityBlockLengthProposalSubmitted
+}
+
+type eventUtilityBatchCompletedWithErrors struct {
+	Phase  types.Phase
+	Topics []types.Hash
+}
+
+type eventUtilityItemFailed struct {
+	Phase         types.Phase
+	DispatchError types.DispatchError
+	Topics        []types.Hash
+}
+
+type eventDataAvailabilityApplicationKeyCreated struct {
+	Phase  types.Phase
+	Key    types.Bytes
+	Owner  types.AccountID
+	ID     types.UCompact
+	Topics []types.Hash
+}
+
+type eventDataAvailabilityDataSubmitted struct {
+	Phase    types.Phase
+	Who      types.AccountID
+	DataHash types.Hash
+	Topics   []types.Hash
+}
+
+type eventDataAvailabilityBlockLengthProposalSubmitted struct {
+	Phase  types.Phase
+	Rows   types.U32
+	Cols   types.U32
+	Topics []types.Hash
+}
+
+// SubmitBatch submits the calls in a single extrinsic signed with the signer, and waits for its inclusion.
+// An atomic batch is dispatched with Utility.batch_all and reverts all the calls if one fails; otherwise
+// it's dispatched with Utility.batch, which stops at the first failing call.
+// It takes a context bounding the wait for the inclusion, a client, the signer key pair, the calls, whether
+// the batch is atomic, and the submission options.
+// It returns the submission result and, once the batch is included, a *DispatchError if the extrinsic failed,
+// or a *BatchInterruptedError with the index of the failing call if a non-atomic batch was interrupted. It returns
+// a *SubmitTimeoutError if the context is done before the inclusion, or an error if there is an issue.
+func SubmitBatch(ctx context.Context, client Client, signer signature.KeyringPair, calls []types.Call, atomic bool, opts SubmitOpts) (*SubmitResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("no calls to batch")
+	}
+
+	api, err := instance(client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := latestMetadata(client, api)
+	if err != nil {
+		return nil, err
+	}
+
+	batchCall := CallBatch
+	if atomic {
+		batchCall = CallBatchAll
+	}
+
+	call, err := types.NewCall(meta, batchCall, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := submitExtrinsic(ctx, client, api, meta, signer, batchCall, call, 0, opts)
+	if err != nil {
+		return result, err
+	}
+
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, c.invalidateMetadataOnMismatch(extrinsicBatchOutcome(meta, result.Events.Raw, result.Events.Index))
+}
+
+// DepositBalanceBatch deposits the amounts of Avail tokens, in Avail fractions, from the funder account to the
+// recipient accounts, mapped by their public keys, in a single extrinsic, see DepositAmountBatch.
+//
+// Deprecated: Use DepositAmountBatch, whose amounts aren't capped at about 18.4 AVL by a uint64.
+func DepositBalanceBatch(ctx context.Context, client Client, funder signature.KeyringPair, recipients map[types.AccountID]uint64, opts SubmitOpts) error {
+	amounts := make(map[types.AccountID]*big.Int, len(recipients))
+	for account, amount := range recipients {
+		amounts[account] = new(big.Int).SetUint64(amount)
+	}
+
+	return DepositAmountBatch(ctx, client, funder, amounts, opts)
+}
+
+// DepositAmountBatch deposits the amounts of Avail tokens, in Avail fractions, from the funder account to the
+// recipient accounts, mapped by their public keys, in a single extrinsic.
+// The transfers are dispatched in the order of the recipient public keys, and stop at the first failing one.
+// The transfers keep the funder account alive, unless the options allow its death, see SubmitOpts.AllowDeath.
+// It takes a context bounding the wait for the inclusion, a client, the funder key pair, the amounts by recipient,
+// and the submission options.
+// It returns an error wrapping a *BatchInterruptedError if a transfer failed, or an error if there is an issue, e.g.
+// a nil or negative amount, in which case nothing is submitted.
+func DepositAmountBatch(ctx context.Context, client Client, funder signature.KeyringPair, recipients map[types.AccountID]*big.Int, opts SubmitOpts) error {
+	api, err := instance(client)
+	if err != nil {
+		return err
+	}
+
+	meta, err := latestMetadata(client, api)
+	if err != nil {
+		return err
+	}
+
+	accounts := make([]types.AccountID, 0, len(recipients))
+	for account := range recipients {
+		accounts = append(accounts, account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(accounts[i][:], accounts[j][:]) < 0
+	})
+
+	calls := make([]types.Call, 0, len(accounts))
+	for _, account := range accounts {
+		addr, err := types.NewMultiAddressFromAccountID(account[:])
+		if err != nil {
+			return err
+		}
+
+		amount := recipients[account]
+		if amount == nil || amount.Sign() < 0 {
+			return fmt.Errorf("invalid deposit amount %v to %s, must not be negative", amount, ss58Address(account[:]))
+		}
+
+		c, err := types.NewCall(meta, transferCall(opts), addr, types.NewUCompact(amount))
+		if err != nil {
+			return err
+		}
+
+		calls = append(calls, c)
+	}
+
+	_, err = SubmitBatch(ctx, client, funder, calls, false, opts)
+
+	var interrupted *BatchInterruptedError
+	if errors.As(err, &interrupted) && int(interrupted.Index) < len(accounts) {
+		return fmt.Errorf("deposit to %s failed: %w", ss58Address(accounts[interrupted.Index][:]), err)
+	}
+
+	return err
+}
+
+// includedExtrinsicEvents returns the raw events of the block including the extrinsic, the index of the
+// extrinsic in the block, and the number of the block.
+func includedExtrinsicEvents(r *rpc.RPC, meta *types.Metadata, blockHash, extrinsicHash types.Hash) (types.EventRecordsRaw, uint32, uint64, error) {
+	block, err := r.Chain.GetBlock(blockHash)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("couldn't fetch the block including the extrinsic: %w", err)
+	}
+
+	index := -1
+
+	for i, ext := range block.Block.Extrinsics {
+		if hash, err := hashExtrinsic(ext); err == nil && hash == extrinsicHash {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return nil, 0, 0, fmt.Errorf("extrinsic %s not found in block %s", extrinsicHash.Hex(), blockHash.Hex())
+	}
+
+	key, err := types.CreateStorageKey(meta, "System", "Events", nil)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	var raw types.EventRecordsRaw
+	if _, err := r.State.GetStorage(key, &raw, blockHash); err != nil {
+		return nil, 0, 0, fmt.Errorf("couldn't fetch the events of the block including the extrinsic: %w", err)
+	}
+
+	return raw, uint32(index), uint64(block.Block.Header.Number), nil
+}
+
+// extrinsicBatchOutcome decodes the events and returns the failure or the interruption of the batch extrinsic
+// at the index in the block.
+func extrinsicBatchOutcome(meta *types.Metadata, raw types.EventRecordsRaw, index uint32) error {
+	var events availEventRecords
+	if err := raw.DecodeEventRecords(meta, &events); err != nil {
+		return fmt.Errorf("couldn't decode the events of the block including the batch: %w", &metadataMismatchError{err})
+	}
+
+	ofExtrinsic := func(phase types.Phase) bool {
+		return phase.IsApplyExtrinsic && phase.AsApplyExtrinsic == index
+	}
+
+	for _, ev := range events.System_ExtrinsicFailed {
+		if ofExtrinsic(ev.Phase) {
+			return newDispatchError(meta, ev.DispatchError)
+		}
+	}
+
+	for _, ev := range events.Utility_BatchInterrupted {
+		if ofExtrinsic(ev.Phase) {
+			return &BatchInterruptedError{Index: uint32(ev.Index), Reason: describeDispatchError(meta, ev.DispatchError)}
+		}
+	}
+
+	return nil
+}
+
+// describeDispatchError returns a readable description of the dispatch error, with the name of a module error.
+func describeDispatchError(meta *types.Metadata, dispatchError types.DispatchError) string {
+	switch {
+	case dispatchError.IsModule:
+		moduleError := dispatchError.ModuleError
+
+		metaError, err := meta.FindError(moduleError.Index, moduleError.Error)
+		if err != nil {
+			return fmt.Sprintf("module %d error %v", moduleError.Index, moduleError.Error)
+		}
+
+		return metaError.Name
+	case dispatchError.IsBadOrigin:
+		return "bad origin"
+	case dispatchError.IsCannotLookup:
+		return "cannot lookup"
+	case dispatchError.IsToken:
+		return "token error"
+	case dispatchError.IsArithmetic:
+		return "arithmetic error"
+	default:
+		return "dispatch error"
+	}
+}
@@ -0,0 +1,199 @@
+package avail
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRecordsBuilder encodes the raw event records of a block for the test metadata.
+type eventRecordsBuilder struct {
+	t      *testing.T
+	count  int
+	events []byte
+}
+
+func (b *eventRecordsBuilder) add(extrinsic uint32, id types.EventID, fields ...interface{}) {
+	b.count++
+
+	b.events = append(b.events, 0)
+	b.events = binary.LittleEndian.AppendUint32(b.events, extrinsic)
+	b.events = append(b.events, id[:]...)
+
+	for _, field := range fields {
+		if raw, ok := field.([]byte); ok {
+			b.events = append(b.events, raw...)
+			continue
+		}
+
+		encoded, err := codec.Encode(field)
+		if err != nil {
+			b.t.Fatal(err)
+		}
+
+		b.events = append(b.events, encoded...)
+	}
+
+	// No topics.
+	b.events = append(b.events, 0)
+}
+
+func (b *eventRecordsBuilder) raw() types.EventRecordsRaw {
+	count, err := codec.Encode(types.NewUCompactFromUInt(uint64(b.count)))
+	if err != nil {
+		b.t.Fatal(err)
+	}
+
+	return types.EventRecordsRaw(append(count, b.events...))
+}
+
+var (
+	eventExtrinsicSuccess = types.EventID{0, 0}
+	eventExtrinsicFailed  = types.EventID{0, 1}
+	eventBatchInterrupted = types.EventID{1, 0}
+
+	dispatchInfo = types.DispatchInfo{
+		Weight:  types.NewWeight(types.NewUCompactFromUInt(1000), types.NewUCompactFromUInt(0)),
+		Class:   types.DispatchClass{IsNormal: true},
+		PaysFee: types.Pays{IsYes: true},
+	}
+
+	// balancesInsufficientBalance is the encoded Balances.InsufficientBalance module error of the test metadata.
+	balancesInsufficientBalance = []byte{3, 6, 2, 0, 0, 0}
+)
+
+func TestExtrinsicBatchOutcome(t *testing.T) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	events := &eventRecordsBuilder{t: t}
+	events.add(0, eventExtrinsicSuccess, dispatchInfo)
+	events.add(1, eventBatchInterrupted, types.U32(2), balancesInsufficientBalance)
+	events.add(1, eventExtrinsicSuccess, dispatchInfo)
+	events.add(2, eventExtrinsicFailed, balancesInsufficientBalance, dispatchInfo)
+
+	raw := events.raw()
+
+	assert.NoError(t, extrinsicBatchOutcome(&meta, raw, 0))
+
+	err := extrinsicBatchOutcome(&meta, raw, 1)
+
+	var interrupted *BatchInterruptedError
+	if assert.True(t, errors.As(err, &interrupted)) {
+		assert.Equal(t, uint32(2), interrupted.Index)
+		assert.Equal(t, "InsufficientBalance", interrupted.Reason)
+	}
+
+	err = extrinsicBatchOutcome(&meta, raw, 2)
+	assert.ErrorIs(t, err, ErrExtrinsicFailed)
+	assert.Contains(t, err.Error(), "InsufficientBalance")
+}
+
+func TestDepositAmountBatch(t *testing.T) {
+	m, funder := newFundedMockClient(t, AVL)
+
+	aboveUint64, _ := new(big.Int).SetString("123456789012345678901234", 10)
+
+	assert.NoError(t, DepositAmountBatch(context.Background(), m, funder, map[types.AccountID]*big.Int{{0x01}: aboveUint64}, SubmitOpts{}))
+
+	submitted := m.Submitted()
+	if !assert.Len(t, submitted, 1) {
+		return
+	}
+
+	addr, err := types.NewMultiAddressFromAccountID([]byte{0x01, 31: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call, err := types.NewCall(m.meta, CallTransferKeepAlive, addr, types.NewUCompact(aboveUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := types.NewCall(m.meta, CallBatch, []types.Call{call})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, batch.Args, submitted[0].Method.Args)
+
+	// Nothing is submitted with a negative amount.
+	err = DepositAmountBatch(context.Background(), m, funder, map[types.AccountID]*big.Int{{0x01}: big.NewInt(-1)}, SubmitOpts{})
+	assert.ErrorContains(t, err, "invalid deposit amount -1")
+	assert.Len(t, m.Submitted(), 1)
+}
+
+func TestSubmitBatch(t *testing.T) {
+	m, funder := newFundedMockClient(t, AVL)
+
+	if err := m.SetNonce(funder, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	recipients := map[types.AccountID]uint64{
+		{0x02}: 2 * AVL,
+		{0x01}: AVL,
+	}
+
+	assert.NoError(t, DepositBalanceBatch(context.Background(), m, funder, recipients, SubmitOpts{}))
+
+	submitted := m.Submitted()
+	if !assert.Len(t, submitted, 1) {
+		return
+	}
+
+	batchIndex, err := m.meta.FindCallIndex(CallBatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, batchIndex, submitted[0].Method.CallIndex)
+	assert.Equal(t, types.NewUCompactFromUInt(1), submitted[0].Signature.Nonce)
+
+	// The transfers are ordered by recipient.
+	var calls []types.Call
+	for _, recipient := range []types.AccountID{{0x01}, {0x02}} {
+		addr, err := types.NewMultiAddressFromAccountID(recipient[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		call, err := types.NewCall(m.meta, CallTransferKeepAlive, addr, types.NewUCompactFromUInt(recipients[recipient]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		calls = append(calls, call)
+	}
+
+	expectedArgs, err := codec.Encode(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, types.Args(expectedArgs), submitted[0].Method.Args)
+
+	batchAllIndex, err := m.meta.FindCallIndex(CallBatchAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = SubmitBatch(context.Background(), m, funder, calls, true, SubmitOpts{})
+	assert.NoError(t, err)
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 2) {
+		assert.Equal(t, batchAllIndex, submitted[1].Method.CallIndex)
+	}
+
+	_, err = SubmitBatch(context.Background(), m, funder, nil, true, SubmitOpts{})
+	assert.Error(t, err)
+}
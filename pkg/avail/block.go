@@ -3,10 +3,11 @@ package avail
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"sync/atomic"
 
 	edge_types "github.com/0xPolygon/polygon-edge/types"
-	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
 	"github.com/hashicorp/go-hclog"
@@ -51,6 +52,122 @@ func (dbs *DummyBlockSource) DummyBlock(appID types.UCompact, callIdx types.Call
 // Error returned when no compatible extrinsic is found in Avail block's extrinsic data
 var ErrNoExtrinsicFound = errors.New("no compatible extrinsic found")
 
+// ErrBlockNotFound is the error returned when the Avail block hash is invalid or unknown.
+var ErrBlockNotFound = errors.New("Avail block not found")
+
+// GetBlockExtrinsics fetches the Avail block, and returns the data of its DataAvailability.submit_data extrinsics
+// submitted under the AppID, in block order.
+// It takes a client, the block hash, the AppID, and the signers the extrinsics are filtered by; all the signers are
+// accepted if it's empty.
+// The data split by SubmitDataChunked is reassembled from its chunks when they're all included in the block, in
+// place of the first of them; the chunks of data spread across blocks are returned as they are.
+// The data is decompressed if the client compresses the data it submits, see WithCompression.
+// It returns the data, an error wrapping ErrBlockNotFound if the block hash is invalid or unknown, ErrNoExtrinsicFound
+// if no extrinsic matches, or an error if there is an issue.
+func GetBlockExtrinsics(client Client, blockHash types.Hash, appID uint32, signers []signature.KeyringPair) ([][]byte, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	blk, callIdx, err := c.submitDataBlock(client, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.blockData(blk, callIdx, blockHash, appID, signers)
+}
+
+// blockData returns the data of the submit_data extrinsics of the block submitted under the AppID by one of the
+// signers, or by anyone if there are none, with the chunks included in the block reassembled, and decompressed.
+func (c *client) blockData(blk *types.SignedBlock, callIdx types.CallIndex, blockHash types.Hash, appID uint32, signers []signature.KeyringPair) ([][]byte, error) {
+	data, err := blockExtrinsicsData(blk, callIdx, appID, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	data = reassembleBlockChunks(data)
+
+	for i := range data {
+		if data[i], err = c.decompress(data[i]); err != nil {
+			return nil, fmt.Errorf("couldn't decompress the data of block %s: %w", blockHash.Hex(), err)
+		}
+	}
+
+	return data, nil
+}
+
+// submitDataBlock fetches the Avail block, and returns it with the call index of DataAvailability.submit_data.
+// It returns an error wrapping ErrBlockNotFound if the block hash is invalid or unknown.
+func (c *client) submitDataBlock(client Client, blockHash types.Hash) (*types.SignedBlock, types.CallIndex, error) {
+	meta, err := c.metadata(c.instance())
+	if err != nil {
+		return nil, types.CallIndex{}, err
+	}
+
+	callIdx, err := meta.FindCallIndex(CallSubmitData)
+	if err != nil {
+		return nil, types.CallIndex{}, err
+	}
+
+	blk, err := c.reader().RPC.Chain.GetBlock(blockHash)
+	if err != nil {
+		return nil, types.CallIndex{}, fmt.Errorf("%w: %s: %s", ErrBlockNotFound, blockHash.Hex(), err)
+	}
+
+	// The node returns null for an unknown block hash, which decodes into an empty block.
+	if blk == nil || (blk.Block.Header.ParentHash == types.Hash{} && blockHash != client.GenesisHash()) {
+		return nil, types.CallIndex{}, fmt.Errorf("%w: %s", ErrBlockNotFound, blockHash.Hex())
+	}
+
+	return blk, callIdx, nil
+}
+
+// blockExtrinsicsData returns the data of the extrinsics of the block calling the call index, submitted under
+// the AppID by one of the signers, or by anyone if there are none.
+func blockExtrinsicsData(blk *types.SignedBlock, callIdx types.CallIndex, appID uint32, signers []signature.KeyringPair) ([][]byte, error) {
+	var data [][]byte
+
+	for i, extrinsic := range blk.Block.Extrinsics {
+		if !extrinsic.IsSigned() || extrinsic.Method.CallIndex != callIdx || extrinsic.Signature.AppID.Int64() != int64(appID) {
+			continue
+		}
+
+		if len(signers) > 0 && !signedByOneOf(extrinsic, signers) {
+			continue
+		}
+
+		var bs types.Bytes
+		if err := codec.Decode(extrinsic.Method.Args, &bs); err != nil {
+			return nil, fmt.Errorf("couldn't decode the data of extrinsic %d of block %d: %w", i, blk.Block.Header.Number, err)
+		}
+
+		data = append(data, bs)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoExtrinsicFound
+	}
+
+	return data, nil
+}
+
+// signedByOneOf checks whether the extrinsic is signed by one of the signers.
+func signedByOneOf(extrinsic types.Extrinsic, signers []signature.KeyringPair) bool {
+	signer := extrinsic.Signature.Signer
+	if !signer.IsID {
+		return false
+	}
+
+	for _, s := range signers {
+		if bytes.Equal(signer.AsID[:], s.PublicKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // BlockFromAvail converts Avail blocks into Edge blocks.
 // It takes an Avail block, appID, callIdx, and logger as parameters.
 // It returns a slice of Edge blocks or an error if conversion fails.
@@ -84,8 +201,8 @@ func BlockFromAvail(avail_blk *types.SignedBlock, appID types.UCompact, callIdx
 				continue
 			}
 
-			decoder := scale.NewDecoder(bytes.NewBuffer(bs))
-			err = blob.Decode(*decoder)
+			// The blob is decompressed if it was sent with compression.
+			blob, err = decodeBlob(bs)
 			if err != nil {
 				// Don't return just yet because there is no way of filtering
 				// uninteresting extrinsics / method.Args and failing decoding
@@ -0,0 +1,72 @@
+package avail
+
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+var submitDataCallIndex = types.CallIndex{SectionIndex: 29, MethodIndex: 1}
+
+// dataExtrinsic returns an extrinsic calling the call index with the data, signed by the signer under the AppID,
+// or unsigned if the signer is nil.
+func dataExtrinsic(t *testing.T, callIdx types.CallIndex, appID uint64, signer *signature.KeyringPair, data []byte) types.Extrinsic {
+	t.Helper()
+
+	args, err := codec.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ext := types.Extrinsic{Version: types.ExtrinsicVersion4, Method: types.Call{CallIndex: callIdx, Args: args}}
+
+	if signer != nil {
+		addr, err := types.NewMultiAddressFromAccountID(signer.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ext.Version |= types.ExtrinsicBitSigned
+		ext.Signature.Signer = addr
+		ext.Signature.AppID = types.NewUCompactFromUInt(appID)
+	}
+
+	return ext
+}
+
+func TestBlockExtrinsicsData(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+
+	bob, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherCall := types.CallIndex{SectionIndex: 5, MethodIndex: 0}
+
+	blk := &types.SignedBlock{}
+	blk.Block.Extrinsics = []types.Extrinsic{
+		dataExtrinsic(t, submitDataCallIndex, 1, &alice, []byte("first")),
+		dataExtrinsic(t, submitDataCallIndex, 2, &alice, []byte("other app")),
+		dataExtrinsic(t, submitDataCallIndex, 1, nil, []byte("unsigned")),
+		dataExtrinsic(t, otherCall, 1, &alice, []byte("other call")),
+		dataExtrinsic(t, submitDataCallIndex, 1, &bob, []byte("second")),
+	}
+
+	data, err := blockExtrinsicsData(blk, submitDataCallIndex, 1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("first"), []byte("second")}, data)
+
+	data, err = blockExtrinsicsData(blk, submitDataCallIndex, 1, []signature.KeyringPair{bob})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("second")}, data)
+
+	_, err = blockExtrinsicsData(blk, submitDataCallIndex, 3, nil)
+	assert.ErrorIs(t, err, ErrNoExtrinsicFound)
+
+	_, err = blockExtrinsicsData(&types.SignedBlock{}, submitDataCallIndex, 1, nil)
+	assert.ErrorIs(t, err, ErrNoExtrinsicFound)
+}
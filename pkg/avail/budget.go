@@ -0,0 +1,220 @@
+package avail
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// ErrFeeBudgetExceeded is the error returned when the fee of an extrinsic exceeds the fee budget of the submission,
+// in which case it isn't submitted.
+var ErrFeeBudgetExceeded = errors.New("fee budget exceeded")
+
+// FeeBudgetExceededError is the error returned when the estimated fee of an extrinsic exceeds the maximum fee per
+// extrinsic of a FeeBudget, or the fees spent in its window. It matches ErrFeeBudgetExceeded.
+type FeeBudgetExceededError struct {
+	// Fee is the estimated fee of the extrinsic, in Avail fractions.
+	Fee *big.Int
+	// MaxFee is the maximum fee per extrinsic, nil if unlimited.
+	MaxFee *big.Int
+	// Usage is the usage of the budget when the extrinsic was withheld.
+	Usage FeeBudgetUsage
+	// RetryAt is the time enough of the fees spent leave the window for the extrinsic to fit in the budget, zero if
+	// its fee exceeds the maximum fee per extrinsic or the whole budget.
+	RetryAt time.Time
+}
+
+func (e *FeeBudgetExceededError) Error() string {
+	if e.MaxFee != nil && e.Fee.Cmp(e.MaxFee) > 0 {
+		return fmt.Sprintf("%s: fee of %s AVL, maximum is %s AVL per extrinsic", ErrFeeBudgetExceeded, FormatAVL(e.Fee), FormatAVL(e.MaxFee))
+	}
+
+	return fmt.Sprintf("%s: fee of %s AVL, %s AVL of %s AVL spent in the last %s", ErrFeeBudgetExceeded, FormatAVL(e.Fee), FormatAVL(e.Usage.Spent), FormatAVL(e.Usage.MaxSpend), e.Usage.Window)
+}
+
+// Is matches ErrFeeBudgetExceeded.
+func (e *FeeBudgetExceededError) Is(target error) bool {
+	return target == ErrFeeBudgetExceeded
+}
+
+// FeeBudgetUsage is the usage of a FeeBudget over its window.
+type FeeBudgetUsage struct {
+	// Spent is the sum of the fees of the extrinsics submitted in the window, in Avail fractions.
+	Spent *big.Int
+	// Submissions is the number of extrinsics submitted in the window.
+	Submissions int
+	// MaxSpend is the maximum spend in the window, nil if unlimited, and Window the duration of the window.
+	MaxSpend *big.Int
+	Window   time.Duration
+}
+
+// FeeBudget caps the fees spent by the submissions to Avail, e.g. so a runaway submission loop doesn't burn
+// through the balance of the account: the fee of every extrinsic is estimated before it's submitted, and the
+// extrinsic is withheld with a *FeeBudgetExceededError if the fee exceeds the maximum fee per extrinsic, or if the
+// fees of the extrinsics submitted in the last window would exceed the maximum spend.
+// The fee of an extrinsic is charged once it's submitted, whatever its outcome, and refunded if it's rejected.
+// It is safe for concurrent use, and can be shared by several accounts and clients to cap their joint spend.
+type FeeBudget struct {
+	maxFee   *big.Int
+	maxSpend *big.Int
+	window   time.Duration
+
+	// lock guards the spends, the fees charged in the window in the order they were charged.
+	lock   sync.Mutex
+	spends []*feeSpend
+
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+}
+
+// feeSpend is a fee charged to a FeeBudget.
+type feeSpend struct {
+	at  time.Time
+	fee *big.Int
+}
+
+// NewFeeBudget creates a FeeBudget with the maximum fee per extrinsic and the maximum spend per window, in Avail
+// fractions. A nil maximum fee or spend, or a non-positive window, isn't enforced.
+func NewFeeBudget(maxFee, maxSpend *big.Int, window time.Duration) *FeeBudget {
+	if window <= 0 {
+		maxSpend = nil
+	}
+
+	return &FeeBudget{
+		maxFee:   maxFee,
+		maxSpend: maxSpend,
+		window:   window,
+		now:      time.Now,
+	}
+}
+
+// WithFeeBudget sets the FeeBudget capping the fees of the submissions of the client, unless one is set by the
+// submission options, see SubmitOpts.FeeBudget, including the ones of the Sender.
+func WithFeeBudget(budget *FeeBudget) ClientOption {
+	return func(c *client) {
+		c.feeBudget = budget
+	}
+}
+
+// Usage returns the usage of the budget over the last window.
+func (b *FeeBudget) Usage() FeeBudgetUsage {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.usage(b.now())
+}
+
+// Reset forgets the fees spent, e.g. once an operator checked the submissions after the budget was exceeded.
+func (b *FeeBudget) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.spends = nil
+}
+
+// usage drops the spends out of the window at the time, and returns the usage of the budget. The lock must be held.
+func (b *FeeBudget) usage(now time.Time) FeeBudgetUsage {
+	expired := 0
+	for expired < len(b.spends) && !now.Before(b.spends[expired].at.Add(b.window)) {
+		expired++
+	}
+
+	b.spends = b.spends[expired:]
+
+	spent := new(big.Int)
+	for _, s := range b.spends {
+		spent.Add(spent, s.fee)
+	}
+
+	return FeeBudgetUsage{Spent: spent, Submissions: len(b.spends), MaxSpend: b.maxSpend, Window: b.window}
+}
+
+// charge charges the fee to the budget, and returns the spend to refund if the extrinsic isn't submitted, or a
+// *FeeBudgetExceededError if the fee doesn't fit in the budget.
+func (b *FeeBudget) charge(fee *big.Int) (*feeSpend, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	usage := b.usage(now)
+
+	exceeded := &FeeBudgetExceededError{Fee: fee, MaxFee: b.maxFee, Usage: usage}
+
+	if b.maxFee != nil && fee.Cmp(b.maxFee) > 0 {
+		return nil, exceeded
+	}
+
+	if b.maxSpend != nil {
+		if over := new(big.Int).Sub(new(big.Int).Add(usage.Spent, fee), b.maxSpend); over.Sign() > 0 {
+			// The extrinsic fits once the oldest spends covering the excess leave the window.
+			for _, s := range b.spends {
+				if over.Sub(over, s.fee); over.Sign() <= 0 {
+					exceeded.RetryAt = s.at.Add(b.window)
+					break
+				}
+			}
+
+			return nil, exceeded
+		}
+	}
+
+	spend := &feeSpend{at: now, fee: fee}
+	b.spends = append(b.spends, spend)
+
+	return spend, nil
+}
+
+// refund refunds the spend of an extrinsic which wasn't submitted.
+func (b *FeeBudget) refund(spend *feeSpend) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for i, s := range b.spends {
+		if s == spend {
+			b.spends = append(b.spends[:i], b.spends[i+1:]...)
+			return
+		}
+	}
+}
+
+// feeBudgetOr returns the fee budget of the submission options, or the one of the client if none was given.
+func (o SubmitOpts) feeBudgetOr(budget *FeeBudget) *FeeBudget {
+	if o.FeeBudget != nil {
+		return o.FeeBudget
+	}
+
+	return budget
+}
+
+// chargeFee estimates the fee of the signed extrinsic, and charges it to the budget, see FeeBudget. It returns the
+// spend to refund if the extrinsic isn't submitted, nil without a budget.
+func (c *client) chargeFee(api *gsrpc.SubstrateAPI, budget *FeeBudget, ext types.Extrinsic) (*feeSpend, error) {
+	if budget == nil {
+		return nil, nil
+	}
+
+	fee, err := estimateFee(api.Client, ext)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't estimate the fee checked against the fee budget: %w", err)
+	}
+
+	spend, err := budget.charge(fee)
+	if err != nil {
+		c.logger.Warn("withholding Avail submission over the fee budget", "error", err)
+		return nil, err
+	}
+
+	return spend, nil
+}
+
+// refundFee refunds the spend of the extrinsic rejected by the Avail node, if any.
+func refundFee(budget *FeeBudget, spend *feeSpend) {
+	if spend != nil {
+		budget.refund(spend)
+	}
+}
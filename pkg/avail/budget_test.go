@@ -0,0 +1,210 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeBudget(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start
+
+	budget := NewFeeBudget(big.NewInt(10), big.NewInt(25), time.Minute)
+	budget.now = func() time.Time { return now }
+
+	usage := func() (int64, int) {
+		u := budget.Usage()
+		return u.Spent.Int64(), u.Submissions
+	}
+
+	_, err := budget.charge(big.NewInt(10))
+	assert.NoError(t, err)
+
+	// A fee above the maximum fee per extrinsic is never accepted.
+	_, err = budget.charge(big.NewInt(11))
+
+	var exceeded *FeeBudgetExceededError
+	if assert.ErrorAs(t, err, &exceeded) {
+		assert.ErrorIs(t, err, ErrFeeBudgetExceeded)
+		assert.True(t, exceeded.RetryAt.IsZero())
+		assert.EqualError(t, err, "fee budget exceeded: fee of 0.000000000000000011 AVL, maximum is 0.00000000000000001 AVL per extrinsic")
+	}
+
+	now = start.Add(10 * time.Second)
+
+	_, err = budget.charge(big.NewInt(10))
+	assert.NoError(t, err)
+
+	// The next fee fits once the first one leaves the window.
+	_, err = budget.charge(big.NewInt(10))
+	if assert.ErrorAs(t, err, &exceeded) {
+		assert.Equal(t, start.Add(time.Minute), exceeded.RetryAt)
+		assert.Equal(t, int64(20), exceeded.Usage.Spent.Int64())
+		assert.Equal(t, big.NewInt(25), exceeded.Usage.MaxSpend)
+	}
+
+	spent, submissions := usage()
+	assert.Equal(t, int64(20), spent)
+	assert.Equal(t, 2, submissions)
+
+	now = start.Add(time.Minute)
+
+	spend, err := budget.charge(big.NewInt(10))
+	assert.NoError(t, err)
+
+	spent, submissions = usage()
+	assert.Equal(t, int64(20), spent)
+	assert.Equal(t, 2, submissions)
+
+	budget.refund(spend)
+
+	spent, _ = usage()
+	assert.Equal(t, int64(10), spent)
+
+	budget.Reset()
+
+	spent, submissions = usage()
+	assert.Equal(t, int64(0), spent)
+	assert.Equal(t, 0, submissions)
+
+	// A fee above the whole budget never fits.
+	_, err = NewFeeBudget(nil, big.NewInt(5), time.Minute).charge(big.NewInt(8))
+	if assert.ErrorAs(t, err, &exceeded) {
+		assert.True(t, exceeded.RetryAt.IsZero())
+	}
+
+	// Without a window, only the maximum fee per extrinsic is enforced.
+	unlimited := NewFeeBudget(big.NewInt(10), big.NewInt(5), 0)
+	for i := 0; i < 3; i++ {
+		_, err = unlimited.charge(big.NewInt(10))
+		assert.NoError(t, err)
+	}
+}
+
+func TestFeeBudgetConcurrentCharges(t *testing.T) {
+	budget := NewFeeBudget(nil, big.NewInt(50), time.Hour)
+
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		charged int
+	)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := budget.charge(big.NewInt(1)); err == nil {
+				lock.Lock()
+				charged++
+				lock.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 50, charged)
+	assert.Equal(t, int64(50), budget.Usage().Spent.Int64())
+}
+
+func TestSubmissionsWithheldOverFeeBudget(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// deposit deposits 1 AVL to the recipient from the funder.
+	deposit := func(m *MockClient, funder signature.KeyringPair, opts SubmitOpts) error {
+		_, err := DepositBalance(context.Background(), m, funder, recipient, AVL, opts)
+		return err
+	}
+
+	t.Run("submission options", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 5*AVL)
+		m.SetFee(big.NewInt(1000))
+
+		budget := NewFeeBudget(nil, big.NewInt(1500), time.Hour)
+
+		assert.NoError(t, deposit(m, funder, SubmitOpts{FeeBudget: budget}))
+		assert.ErrorIs(t, deposit(m, funder, SubmitOpts{FeeBudget: budget}), ErrFeeBudgetExceeded)
+		assert.Len(t, m.Submitted(), 1)
+
+		// The budget of the options overrides the one of the client.
+		m.client.feeBudget = budget
+		assert.NoError(t, deposit(m, funder, SubmitOpts{FeeBudget: NewFeeBudget(nil, nil, 0)}))
+	})
+
+	t.Run("client", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 5*AVL)
+		m.SetFee(big.NewInt(1000))
+
+		budget := NewFeeBudget(big.NewInt(999), nil, 0)
+		WithFeeBudget(budget)(m.client)
+
+		assert.ErrorIs(t, deposit(m, funder, SubmitOpts{}), ErrFeeBudgetExceeded)
+		assert.Empty(t, m.Submitted())
+	})
+
+	t.Run("rejected submission refunded", func(t *testing.T) {
+		m, funder := newFundedMockClient(t, 5*AVL)
+		m.SetFee(big.NewInt(1000))
+		m.Script(MockOutcome{Err: errSubmitStopped})
+
+		budget := NewFeeBudget(nil, big.NewInt(1500), time.Hour)
+
+		assert.ErrorIs(t, deposit(m, funder, SubmitOpts{FeeBudget: budget}), errSubmitStopped)
+		assert.Len(t, m.Submitted(), 1)
+		assert.Equal(t, 0, budget.Usage().Spent.Sign())
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		m, account := newFundedMockClient(t, AVL)
+		m.SetFee(big.NewInt(1000))
+
+		nonces := NewNonceManager()
+		budget := NewFeeBudget(nil, big.NewInt(2500), time.Hour)
+
+		q, err := NewSubmitQueue(m, account, SubmitOpts{Nonces: nonces, FeeBudget: budget})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer q.Close()
+
+		submit := func() error {
+			item, err := q.SubmitData(context.Background(), 1, []byte("data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return (<-item.Result()).Err
+		}
+
+		// The fee of an item rejected by the transaction pool is refunded.
+		m.Script(MockOutcome{Err: errors.New("connection reset by peer")})
+		assert.Error(t, submit())
+		assert.Equal(t, 0, budget.Usage().Spent.Sign())
+
+		assert.NoError(t, submit())
+		assert.NoError(t, submit())
+
+		// The item over the budget is withheld, and its nonce taken back.
+		var budgetErr *FeeBudgetExceededError
+		assert.ErrorAs(t, submit(), &budgetErr)
+		assert.Equal(t, int64(2000), budget.Usage().Spent.Int64())
+		assert.Len(t, m.Submitted(), 3)
+
+		nonce, err := nonces.next(m.instance(), account)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), nonce)
+	})
+}
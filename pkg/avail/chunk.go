@@ -0,0 +1,331 @@
+package avail
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+)
+
+const (
+	// ChunkMagic is the first byte of a chunk of the data submitted with SubmitDataChunked, telling it apart from
+	// a Blob.
+	ChunkMagic = byte(0b01010101)
+
+	// ChunkHeaderSize is the size of the header framing each chunk: the magic byte, the number of chunks and the
+	// index of the chunk as big-endian uint16, and the CRC-32 checksum of the whole data as a big-endian uint32.
+	ChunkHeaderSize = 9
+
+	// MaxChunks is the maximum number of chunks the data can be split into.
+	MaxChunks = math.MaxUint16
+)
+
+var (
+	// ErrInvalidChunk is the error returned when a chunk isn't framed by a valid header, or doesn't belong with
+	// the other chunks.
+	ErrInvalidChunk = errors.New("invalid chunk")
+
+	// ErrMissingChunks is the error returned when reassembling chunks while some of them are missing.
+	ErrMissingChunks = errors.New("missing chunks")
+
+	// ErrChunkChecksum is the error returned when the reassembled data doesn't match the checksum of the chunks.
+	ErrChunkChecksum = errors.New("chunk checksum mismatch")
+)
+
+// ChunkHeader is the header framing a chunk.
+type ChunkHeader struct {
+	// Count is the number of chunks of the data, and Index the index of the chunk, from 0.
+	Count, Index uint16
+	// Checksum is the CRC-32 (IEEE) checksum of the whole data, shared by all its chunks.
+	Checksum uint32
+}
+
+// IsChunk checks whether the data of a submit_data extrinsic starts like a chunk of SubmitDataChunked.
+func IsChunk(data []byte) bool {
+	return len(data) >= ChunkHeaderSize && data[0] == ChunkMagic
+}
+
+// ParseChunk returns the header and the payload of the chunk, or an error wrapping ErrInvalidChunk.
+func ParseChunk(chunk []byte) (ChunkHeader, []byte, error) {
+	if !IsChunk(chunk) {
+		return ChunkHeader{}, nil, fmt.Errorf("%w: no chunk header", ErrInvalidChunk)
+	}
+
+	header := ChunkHeader{
+		Count:    binary.BigEndian.Uint16(chunk[1:3]),
+		Index:    binary.BigEndian.Uint16(chunk[3:5]),
+		Checksum: binary.BigEndian.Uint32(chunk[5:9]),
+	}
+
+	if header.Index >= header.Count {
+		return ChunkHeader{}, nil, fmt.Errorf("%w: index %d of %d chunks", ErrInvalidChunk, header.Index, header.Count)
+	}
+
+	return header, chunk[ChunkHeaderSize:], nil
+}
+
+// SplitChunks splits the data into framed chunks of at most chunkSize bytes, headers included.
+// It returns an error if the chunk size doesn't leave room for a payload, or if the data would need more than
+// MaxChunks chunks.
+func SplitChunks(data []byte, chunkSize int) ([][]byte, error) {
+	payloadSize := chunkSize - ChunkHeaderSize
+	if payloadSize <= 0 {
+		return nil, fmt.Errorf("chunk size %d must exceed the %d bytes of the chunk header", chunkSize, ChunkHeaderSize)
+	}
+
+	count := (len(data) + payloadSize - 1) / payloadSize
+	if count == 0 {
+		// Empty data is a single empty chunk.
+		count = 1
+	}
+
+	if count > MaxChunks {
+		return nil, fmt.Errorf("%w: %d bytes need %d chunks of %d bytes, maximum is %d", ErrDataTooLarge, len(data), count, chunkSize, MaxChunks)
+	}
+
+	checksum := crc32.ChecksumIEEE(data)
+	chunks := make([][]byte, count)
+
+	for i := range chunks {
+		start := i * payloadSize
+
+		end := start + payloadSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		chunk := make([]byte, ChunkHeaderSize, ChunkHeaderSize+end-start)
+		chunk[0] = ChunkMagic
+		binary.BigEndian.PutUint16(chunk[1:3], uint16(count))
+		binary.BigEndian.PutUint16(chunk[3:5], uint16(i))
+		binary.BigEndian.PutUint32(chunk[5:9], checksum)
+
+		chunks[i] = append(chunk, data[start:end]...)
+	}
+
+	return chunks, nil
+}
+
+// ReassembleChunks reassembles the data split by SubmitDataChunked from its chunks, in any order. Duplicated chunks
+// are ignored.
+// It returns the data, an error wrapping ErrInvalidChunk if a chunk isn't framed or belongs to other data,
+// ErrMissingChunks if some chunks are missing, or ErrChunkChecksum if the reassembled data is corrupted.
+func ReassembleChunks(chunks [][]byte) ([]byte, error) {
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%w: no chunks", ErrMissingChunks)
+	}
+
+	var first ChunkHeader
+
+	var payloads [][]byte
+
+	for i, chunk := range chunks {
+		header, payload, err := ParseChunk(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+
+		if i == 0 {
+			first = header
+			payloads = make([][]byte, header.Count)
+		} else if header.Count != first.Count || header.Checksum != first.Checksum {
+			return nil, fmt.Errorf("%w: chunk %d belongs to other data", ErrInvalidChunk, i)
+		}
+
+		payloads[header.Index] = payload
+	}
+
+	var missing []int
+
+	size := 0
+
+	for i, payload := range payloads {
+		if payload == nil {
+			missing = append(missing, i)
+		}
+
+		size += len(payload)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %v of %d", ErrMissingChunks, missing, first.Count)
+	}
+
+	data := make([]byte, 0, size)
+	for _, payload := range payloads {
+		data = append(data, payload...)
+	}
+
+	if crc32.ChecksumIEEE(data) != first.Checksum {
+		return nil, ErrChunkChecksum
+	}
+
+	return data, nil
+}
+
+// reassembleBlockChunks replaces the chunks of the data of a block that reassemble into the data split by
+// SubmitDataChunked with the reassembled data, in place of the first of them. The chunks of data spread across
+// blocks, the ones that don't reassemble and the other data are left as they are, in order.
+func reassembleBlockChunks(data [][]byte) [][]byte {
+	type chunkedData struct {
+		count    uint16
+		checksum uint32
+	}
+
+	indexes := make(map[chunkedData][]int)
+
+	for i, d := range data {
+		if header, _, err := ParseChunk(d); err == nil {
+			key := chunkedData{count: header.Count, checksum: header.Checksum}
+			indexes[key] = append(indexes[key], i)
+		}
+	}
+
+	reassembled := make(map[int][]byte)
+	merged := make(map[int]bool)
+
+	for _, chunkIndexes := range indexes {
+		chunks := make([][]byte, len(chunkIndexes))
+		for j, i := range chunkIndexes {
+			chunks[j] = data[i]
+		}
+
+		whole, err := ReassembleChunks(chunks)
+		if err != nil {
+			continue
+		}
+
+		reassembled[chunkIndexes[0]] = whole
+		for _, i := range chunkIndexes[1:] {
+			merged[i] = true
+		}
+	}
+
+	if len(reassembled) == 0 {
+		return data
+	}
+
+	result := make([][]byte, 0, len(data)-len(merged))
+
+	for i, d := range data {
+		if merged[i] {
+			continue
+		}
+
+		if whole, ok := reassembled[i]; ok {
+			d = whole
+		}
+
+		result = append(result, d)
+	}
+
+	return result
+}
+
+// ChunkedSubmitError is the error returned by SubmitDataChunked when some chunks weren't included, or failed to
+// dispatch. The chunks can be resubmitted on their own, e.g. with SubmitData, as their frames don't depend on the
+// submission. It unwraps to the error of the first missing chunk.
+type ChunkedSubmitError struct {
+	// Missing are the indexes of the chunks that weren't included, and Errs their errors, in the same order.
+	Missing []int
+	Errs    []error
+	// Chunks are the framed chunks of the data, by index.
+	Chunks [][]byte
+}
+
+func (e *ChunkedSubmitError) Error() string {
+	return fmt.Sprintf("%d of %d chunks not submitted, chunk %d: %s", len(e.Missing), len(e.Chunks), e.Missing[0], e.Errs[0])
+}
+
+func (e *ChunkedSubmitError) Unwrap() error {
+	return e.Errs[0]
+}
+
+// SubmitDataChunked splits the data into chunks framed with a ChunkHeader, so data over the maximum length accepted
+// by the chain can be submitted, and submits the chunks under the AppID, signed with the account, back-to-back with
+// a SubmitQueue. The data is compressed with the codec of the client, if any, before it's split, and the chunks are
+// submitted as they are, see WithCompression.
+// The chunks included in the same block are reassembled by GetBlockExtrinsics and GetBlockExtrinsicsByRole, which
+// read back the chunks of data spread across blocks as they are; the data is reassembled from them with
+// ReassembleChunks, and decompressed with DecompressData if it's compressed.
+// It takes a context bounding the submissions, a client, the account key pair, the AppID, the data, the maximum size
+// of a chunk, header included, which defaults to the maximum length accepted by the chain if it's 0, and the
+// submission options.
+// It returns the submission results of the chunks, by index, and a *ChunkedSubmitError if some of them weren't
+// included, in which case the results of the missing ones are zero, or an error if there is an issue, in which case
+// nothing is submitted.
+func SubmitDataChunked(ctx context.Context, client Client, account signature.KeyringPair, appID uint32, data []byte, chunkSize int, opts SubmitOpts) ([]SubmitResult, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	constants, err := c.chainConstants(c.instance())
+	if err != nil {
+		return nil, err
+	}
+
+	maxLength := constants.MaxAppDataLength
+
+	if chunkSize == 0 {
+		chunkSize = maxLength
+	} else if chunkSize > maxLength {
+		return nil, fmt.Errorf("%w: chunks of %d bytes, maximum is %d", ErrDataTooLarge, chunkSize, maxLength)
+	}
+
+	if data, err = c.compress(data); err != nil {
+		return nil, err
+	}
+
+	chunks, err := SplitChunks(data, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := NewSubmitQueue(client, account, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer q.Close()
+
+	items := make([]*QueueItem, len(chunks))
+	errs := make([]error, len(chunks))
+
+	for i, chunk := range chunks {
+		items[i], errs[i] = q.SubmitData(ctx, appID, chunk)
+	}
+
+	results := make([]SubmitResult, len(chunks))
+
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+
+		if outcome := <-item.Result(); outcome.Err != nil {
+			errs[i] = outcome.Err
+		} else {
+			results[i] = *outcome.Result
+		}
+	}
+
+	failed := &ChunkedSubmitError{Chunks: chunks}
+
+	for i, err := range errs {
+		if err != nil {
+			failed.Missing = append(failed.Missing, i)
+			failed.Errs = append(failed.Errs, err)
+		}
+	}
+
+	if len(failed.Missing) > 0 {
+		return results, failed
+	}
+
+	return results, nil
+}
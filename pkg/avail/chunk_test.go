@@ -0,0 +1,234 @@
+package avail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReassembleChunks(t *testing.T) {
+	data := []byte("the data of an edge block batch over the size limit")
+
+	chunks, err := SplitChunks(data, ChunkHeaderSize+8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !assert.Len(t, chunks, 7) {
+		return
+	}
+
+	for i, chunk := range chunks {
+		assert.True(t, IsChunk(chunk))
+		assert.LessOrEqual(t, len(chunk), ChunkHeaderSize+8)
+
+		header, _, err := ParseChunk(chunk)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint16(7), header.Count)
+			assert.Equal(t, uint16(i), header.Index)
+		}
+	}
+
+	other, err := SplitChunks([]byte("other data"), ChunkHeaderSize+8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted := append([]byte(nil), chunks[2]...)
+	corrupted[ChunkHeaderSize] ^= 1
+
+	testCases := []struct {
+		name   string
+		chunks [][]byte
+		err    error
+	}{
+		{"in order", chunks, nil},
+		{"out of order and duplicated", [][]byte{chunks[6], chunks[3], chunks[0], chunks[1], chunks[5], chunks[2], chunks[4], chunks[3]}, nil},
+		{"missing", [][]byte{chunks[0], chunks[1], chunks[2], chunks[4], chunks[5], chunks[6]}, ErrMissingChunks},
+		{"none", nil, ErrMissingChunks},
+		{"other data", [][]byte{chunks[0], other[1]}, ErrInvalidChunk},
+		{"not a chunk", [][]byte{{BlobMagic, 0}}, ErrInvalidChunk},
+		{"corrupted", [][]byte{chunks[0], chunks[1], corrupted, chunks[3], chunks[4], chunks[5], chunks[6]}, ErrChunkChecksum},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reassembled, err := ReassembleChunks(tc.chunks)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, data, reassembled)
+			}
+		})
+	}
+
+	// Empty data is a single chunk.
+	empty, err := SplitChunks(nil, ChunkHeaderSize+8)
+	if assert.NoError(t, err) && assert.Len(t, empty, 1) {
+		reassembled, err := ReassembleChunks(empty)
+		assert.NoError(t, err)
+		assert.Empty(t, reassembled)
+	}
+
+	_, err = SplitChunks(data, ChunkHeaderSize)
+	assert.Error(t, err)
+
+	_, err = SplitChunks(make([]byte, MaxChunks+1), ChunkHeaderSize+1)
+	assert.ErrorIs(t, err, ErrDataTooLarge)
+}
+
+func TestSubmitDataChunked(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	data := bytes.Repeat([]byte("0123456789"), 3)
+
+	results, err := SubmitDataChunked(context.Background(), m, funder, 1, data, ChunkHeaderSize+12, SubmitOpts{})
+	if !assert.NoError(t, err) || !assert.Len(t, results, 3) {
+		return
+	}
+
+	// The chunks are read back from the blocks including them.
+	var chunks [][]byte
+
+	for i, result := range results {
+		assert.Equal(t, uint64(i), result.Nonce)
+
+		blockData, err := GetBlockExtrinsics(m, result.BlockHash, 1, nil)
+		if assert.NoError(t, err) {
+			chunks = append(chunks, blockData...)
+		}
+	}
+
+	reassembled, err := ReassembleChunks(chunks)
+	assert.NoError(t, err)
+	assert.Equal(t, data, reassembled)
+
+	_, err = SubmitDataChunked(context.Background(), m, funder, 1, data, DefaultMaxAppDataLength+1, SubmitOpts{})
+	assert.ErrorIs(t, err, ErrDataTooLarge)
+}
+
+func TestSubmitDataChunkedPartialFailure(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	errRejected := errors.New("1010: Invalid Transaction: Inability to pay some fees")
+	m.Script(MockOutcome{}, MockOutcome{Err: errRejected})
+
+	data := bytes.Repeat([]byte("0123456789"), 3)
+
+	results, err := SubmitDataChunked(context.Background(), m, funder, 1, data, ChunkHeaderSize+12, SubmitOpts{})
+	assert.ErrorIs(t, err, errRejected)
+
+	var failed *ChunkedSubmitError
+	if !assert.ErrorAs(t, err, &failed) || !assert.Len(t, results, 3) {
+		return
+	}
+
+	assert.Equal(t, []int{1}, failed.Missing)
+	assert.Equal(t, SubmitResult{}, results[1])
+
+	// The chunk behind the rejected one took its nonce, and only the missing chunk is resubmitted.
+	assert.Equal(t, uint64(1), results[2].Nonce)
+
+	resubmitted, err := SubmitData(context.Background(), m, funder, 1, failed.Chunks[1], SubmitOpts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	results[1] = *resubmitted
+
+	var chunks [][]byte
+
+	for _, result := range results {
+		blockData, err := GetBlockExtrinsics(m, result.BlockHash, 1, nil)
+		if assert.NoError(t, err) {
+			chunks = append(chunks, blockData...)
+		}
+	}
+
+	reassembled, err := ReassembleChunks(chunks)
+	assert.NoError(t, err)
+	assert.Equal(t, data, reassembled)
+}
+
+func TestSubmitDataChunkedCompressed(t *testing.T) {
+	m, err := NewMockClient(WithRetryPolicy(testRetryPolicy), WithCompression(CompressionZstd))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funder, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(funder, new(big.Int).SetUint64(10*AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The incompressible data is framed before it's split, so the chunks of the maximum length don't overflow it.
+	data := make([]byte, 2*DefaultMaxAppDataLength)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := SubmitDataChunked(context.Background(), m, funder, 1, data, 0, SubmitOpts{})
+	if !assert.NoError(t, err) || !assert.Len(t, results, 3) {
+		return
+	}
+
+	// The chunks spread across blocks are read back as they are.
+	var chunks [][]byte
+
+	for _, result := range results {
+		blockData, err := GetBlockExtrinsics(m, result.BlockHash, 1, nil)
+		if assert.NoError(t, err) && assert.Len(t, blockData, 1) {
+			assert.True(t, IsChunk(blockData[0]))
+			chunks = append(chunks, blockData[0])
+		}
+	}
+
+	reassembled, err := ReassembleChunks(chunks)
+	if assert.NoError(t, err) {
+		reassembled, err = DecompressData(reassembled)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(data, reassembled))
+	}
+
+	// The chunks included in the same block are reassembled in place of the first of them, in any order.
+	if _, err := SubmitData(context.Background(), m, funder, 1, []byte("other data"), SubmitOpts{}); err != nil {
+		t.Fatal(err)
+	}
+
+	submitted := m.Submitted()
+	extrinsics := []types.Extrinsic{submitted[2], submitted[3], submitted[0], submitted[1]}
+
+	m.lock.Lock()
+	blockHash, err := m.produceBlock(extrinsics, m.storage[len(m.storage)-1])
+	m.lock.Unlock()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockData, err := GetBlockExtrinsics(m, blockHash, 1, nil)
+	if assert.NoError(t, err) && assert.Len(t, blockData, 2) {
+		assert.True(t, bytes.Equal(data, blockData[0]))
+		assert.Equal(t, []byte("other data"), blockData[1])
+	}
+
+	r, err := NewAppIDRegistry(map[Role]uint32{RoleBlocks: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byRole, err := GetBlockExtrinsicsByRole(m, blockHash, r, nil)
+	if assert.NoError(t, err) && assert.Len(t, byRole[RoleBlocks], 2) {
+		assert.True(t, bytes.Equal(data, byRole[RoleBlocks][0]))
+	}
+}
@@ -2,8 +2,11 @@ package avail
 
 import (
 	"errors"
+	"sync"
+	"time"
 
 	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/hashicorp/go-hclog"
 )
@@ -16,6 +19,9 @@ type Client interface {
 	// BlockStream creates a new Avail block stream, starting from the specified block height offset.
 	BlockStream(offset uint64) BlockStream
 
+	// Capabilities returns the features supported by the transport of the client, see Transport.
+	Capabilities() Capabilities
+
 	// GenesisHash returns the genesis hash of the Avail network.
 	GenesisHash() types.Hash
 
@@ -24,13 +30,106 @@ type Client interface {
 
 	// SearchBlock searches for a block at the specified offset using the provided search function.
 	SearchBlock(offset int64, searchFunc SearchFunc) (*types.SignedBlock, error)
+
+	// Stats returns the measurements of the endpoints of the client, taken by the health checks of a failover
+	// client, see EndpointStats.
+	Stats() []EndpointStats
+
+	// Close closes the connections to the Avail network, and stops the health checks of a failover or pooled client.
+	Close()
 }
 
 // client is an implementation of the Client interface.
 type client struct {
-	api         *gsrpc.SubstrateAPI
 	genesisHash types.Hash
 	logger      hclog.Logger
+	retry       RetryPolicy
+
+	healthCheckInterval time.Duration
+	maxBlockLag         uint64
+	onFailover          func(FailoverEvent)
+	latencyAware        bool
+	latencyHysteresis   float64
+	metrics             Metrics
+
+	// lock guards the connections, which are replaced when re-established or on a failover.
+	// conns are the connections to the endpoints, nil if an endpoint isn't connected, and api
+	// is the connection to the active endpoint the calls are routed to.
+	lock      sync.RWMutex
+	endpoints []string
+	conns     []*gsrpc.SubstrateAPI
+	active    int
+	api       *gsrpc.SubstrateAPI
+	// stats are the measurements of the endpoints.
+	stats []EndpointStats
+	// pool are the extra connections the read-only calls are distributed across along with api, see
+	// WithConnections, and poolNext the counter distributing them.
+	pool     []pooledConn
+	poolNext uint32
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+
+	metadataCache  metadataCache
+	constantsCache constantsCache
+
+	runtimeVersionTTL   time.Duration
+	runtimeVersionCache runtimeVersionCache
+
+	// transport is the transport of the endpoints, and pollInterval the interval the status of the extrinsics
+	// submitted over HTTP is polled at.
+	transport    Transport
+	pollInterval time.Duration
+
+	// verifiedFinality makes the block followers verify the finality of the blocks, see WithVerifiedFinality,
+	// and finality tracks the authority set they're verified with.
+	verifiedFinality bool
+	finality         finalityTracker
+
+	// feeBudget caps the fees of the submissions, see WithFeeBudget.
+	feeBudget *FeeBudget
+
+	// onStatus is called with the statuses of the watched extrinsics, see WithStatusCallback.
+	onStatus func(types.ExtrinsicStatus)
+
+	// pendingSubmissions holds a token per submission watched in the background, up to maxPendingSubmissions, see
+	// SubmitDataAsync.
+	pendingSubmissions    chan struct{}
+	maxPendingSubmissions int
+
+	// compression is the codec the submitted data is compressed with, none if zero, see WithCompression.
+	compression CompressionCodec
+
+	// funderNonces hands out the nonces of the deposits submitted without a nonce manager, and funderLocks are the
+	// locks serializing the deposits of each funder, by public key, see DepositAmount.
+	funderNonces *NonceManager
+	funderLocks  sync.Map
+
+	// devFunder is the development funder account set with WithDevFunder, nil for the default one.
+	devFunder *signature.KeyringPair
+
+	// propertiesLock guards the chain properties, fetched once.
+	propertiesLock sync.Mutex
+	properties     *ChainProperties
+
+	// dial, submitAndWatch, subscribeFinalizedHeads, subscribeStorage and probe are the connection, the extrinsic
+	// submission, the finalized heads and the storage subscriptions to the Avail node, and the health check probe of
+	// an endpoint, replaced in tests. They default to the gsrpc ones when nil.
+	dial                    func(url string) (*gsrpc.SubstrateAPI, error)
+	submitAndWatch          func(api *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error)
+	subscribeFinalizedHeads func(api *gsrpc.SubstrateAPI) (headsSubscription, error)
+	subscribeStorage        func(api *gsrpc.SubstrateAPI, key types.StorageKey) (storageSubscription, error)
+	probe                   func(api *gsrpc.SubstrateAPI) (height uint64, latency time.Duration, err error)
+}
+
+// ClientOption configures the Avail client.
+type ClientOption func(*client)
+
+// WithRetryPolicy sets the policy used when the status subscription of a submitted extrinsic fails, see RetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *client) {
+		c.retry = policy
+	}
 }
 
 // NewClient constructs a new Avail Client for the specified URL.
@@ -38,28 +137,91 @@ type client struct {
 // Parameters:
 //   - url: The URL of the Avail JSON-RPC server.
 //   - logger: The logger instance.
+//   - opts: The client options, e.g. WithRetryPolicy. DefaultRetryPolicy is used without one.
 //
 // Return:
 //   - Client: The Avail client instance.
 //   - error: An error if the client initialization fails.
-func NewClient(url string, logger hclog.Logger) (Client, error) {
+func NewClient(url string, logger hclog.Logger, opts ...ClientOption) (Client, error) {
+	return newClient([]string{url}, logger, opts...)
+}
 
-	api, err := gsrpc.NewSubstrateAPI(url)
-	if err != nil {
+// newClient constructs a new Avail client for the endpoints, connected to the first one available.
+func newClient(urls []string, logger hclog.Logger, opts ...ClientOption) (*client, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no Avail endpoints")
+	}
+
+	c := &client{
+		logger:                logger,
+		retry:                 DefaultRetryPolicy,
+		healthCheckInterval:   DefaultHealthCheckInterval,
+		maxBlockLag:           DefaultMaxBlockLag,
+		metrics:               NopMetrics(),
+		runtimeVersionTTL:     DefaultRuntimeVersionTTL,
+		pollInterval:          DefaultPollInterval,
+		maxPendingSubmissions: DefaultMaxPendingSubmissions,
+		funderNonces:          NewNonceManager(),
+		endpoints:             urls,
+		conns:                 make([]*gsrpc.SubstrateAPI, len(urls)),
+		stats:                 make([]EndpointStats, len(urls)),
+		closeCh:               make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.pendingSubmissions = make(chan struct{}, c.maxPendingSubmissions)
+
+	var err error
+
+	if c.transport, err = endpointsTransport(urls); err != nil {
 		return nil, err
 	}
 
-	// Cache genesis hash as it will never change.
-	genesisHash, err := api.RPC.Chain.GetBlockHash(0)
-	if err != nil {
+	for i, url := range urls {
+		var api *gsrpc.SubstrateAPI
+		if api, err = c.dialEndpoint(url); err != nil {
+			logger.Warn("couldn't connect to Avail endpoint", "endpoint", url, "error", err)
+			continue
+		}
+
+		if c.api == nil {
+			// Cache genesis hash as it will never change.
+			if c.genesisHash, err = api.RPC.Chain.GetBlockHash(0); err != nil {
+				closeConn(api)
+				continue
+			}
+
+			c.active = i
+			c.api = api
+		} else if err = c.checkGenesis(api); err != nil {
+			logger.Warn("ignoring Avail endpoint", "endpoint", url, "error", err)
+			closeConn(api)
+			continue
+		}
+
+		c.conns[i] = api
+	}
+
+	if c.api == nil {
 		return nil, err
 	}
 
-	return &client{
-		api:         api,
-		genesisHash: genesisHash,
-		logger:      logger,
-	}, nil
+	if c.transport == TransportHTTP {
+		logger.Info("connected to Avail over HTTP; subscriptions unsupported, polling the submitted extrinsics", "endpoint", urls[c.active], "poll_interval", c.pollInterval)
+	} else {
+		logger.Debug("connected to Avail over WebSocket", "endpoint", urls[c.active])
+	}
+
+	if len(c.pool) > 0 {
+		c.fillPool()
+
+		go c.healthCheckLoop(c.checkPool)
+	}
+
+	return c, nil
 }
 
 // instance returns the underlying SubstrateAPI instance.
@@ -68,12 +230,28 @@ func NewClient(url string, logger hclog.Logger) (Client, error) {
 //   - *gsrpc.SubstrateAPI: The SubstrateAPI instance.
 //   - error: An error if the client is not supported or found.
 func instance(c Client) (*gsrpc.SubstrateAPI, error) {
-	c2, ok := c.(*client)
-	if ok {
-		return c2.instance(), nil
+	c2, err := implementation(c)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, ErrUnsupportedClient
+	return c2.instance(), nil
+}
+
+// implementation returns the client implementation of the Client interface.
+//
+// Return:
+//   - *client: The client implementation.
+//   - error: An error if the client is not supported.
+func implementation(c Client) (*client, error) {
+	switch c2 := c.(type) {
+	case *client:
+		return c2, nil
+	case *MockClient:
+		return c2.client, nil
+	default:
+		return nil, ErrUnsupportedClient
+	}
 }
 
 // instance returns the underlying SubstrateAPI instance.
@@ -81,6 +259,9 @@ func instance(c Client) (*gsrpc.SubstrateAPI, error) {
 // Return:
 //   - *gsrpc.SubstrateAPI: The SubstrateAPI instance.
 func (c *client) instance() *gsrpc.SubstrateAPI {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return c.api
 }
 
@@ -109,7 +290,7 @@ func (c *client) GenesisHash() types.Hash {
 //   - *types.Header: The latest header.
 //   - error: An error if the retrieval fails.
 func (c *client) GetLatestHeader() (*types.Header, error) {
-	return c.api.RPC.Chain.GetHeaderLatest()
+	return c.instance().RPC.Chain.GetHeaderLatest()
 }
 
 // FindCallIndex finds the call index for CallSubmitData in the Avail network.
@@ -128,7 +309,7 @@ func FindCallIndex(client Client) (types.CallIndex, error) {
 		return types.CallIndex{}, err
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := latestMetadata(client, api)
 	if err != nil {
 		return types.CallIndex{}, err
 	}
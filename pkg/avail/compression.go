@@ -0,0 +1,229 @@
+package avail
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionCodec identifies the codec the data submitted to Avail is compressed with. It's the one-byte prefix
+// framing the compressed data, telling it apart from a Blob and a chunk of SubmitDataChunked.
+type CompressionCodec byte
+
+const (
+	// CompressionNone frames data left uncompressed, because compressing it didn't reduce its size.
+	CompressionNone CompressionCodec = 0xc0
+	// CompressionGzip frames data compressed with gzip.
+	CompressionGzip CompressionCodec = 0xc1
+	// CompressionZstd frames data compressed with Zstandard.
+	CompressionZstd CompressionCodec = 0xc2
+)
+
+// ErrInvalidCompression is the error returned when compressed data isn't framed by a known codec, or can't be
+// decompressed.
+var ErrInvalidCompression = errors.New("invalid compressed data")
+
+func (c CompressionCodec) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("CompressionCodec(%#x)", byte(c))
+	}
+}
+
+// WithCompression compresses the data submitted with SubmitData and SubmitQueue.SubmitData, and the blocks sent
+// by the sender, with the codec, framed by its prefix. The data is framed by the CompressionNone prefix instead
+// when compressing it doesn't reduce its size. The chunks of SubmitDataChunked are submitted as they are, the data
+// being compressed before it's split. The data read back with GetBlockExtrinsics is decompressed, and the blocks
+// read with BlockFromAvail are decompressed with or without the option.
+func WithCompression(codec CompressionCodec) ClientOption {
+	return func(c *client) {
+		c.compression = codec
+	}
+}
+
+// IsCompressed checks whether the data starts with the prefix of a known compression codec.
+func IsCompressed(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	switch CompressionCodec(data[0]) {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		return true
+	default:
+		return false
+	}
+}
+
+// CompressData compresses the data with the codec, and frames it with the codec prefix. The data is framed by
+// the CompressionNone prefix instead when compressing it doesn't reduce its size.
+// It returns the framed data, and an error wrapping ErrInvalidCompression if the codec is unknown, or an error if
+// there is an issue.
+func CompressData(codec CompressionCodec, data []byte) ([]byte, error) {
+	var compressed []byte
+
+	switch codec {
+	case CompressionNone:
+	case CompressionGzip:
+		var buf bytes.Buffer
+
+		buf.WriteByte(byte(CompressionGzip))
+
+		w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		compressed = buf.Bytes()
+	case CompressionZstd:
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+
+		compressed = encoder.EncodeAll(data, []byte{byte(CompressionZstd)})
+	default:
+		return nil, fmt.Errorf("%w: unknown codec %s", ErrInvalidCompression, codec)
+	}
+
+	if compressed != nil && len(compressed) < len(data)+1 {
+		return compressed, nil
+	}
+
+	return append([]byte{byte(CompressionNone)}, data...), nil
+}
+
+// DecompressData decompresses the data framed by CompressData.
+// It returns the data, an error wrapping ErrInvalidCompression if it isn't framed by a known codec or can't be
+// decompressed, or ErrDataTooLong if it decompresses to more than MaxBlobSize bytes.
+func DecompressData(framed []byte) ([]byte, error) {
+	if !IsCompressed(framed) {
+		return nil, fmt.Errorf("%w: missing compression codec prefix", ErrInvalidCompression)
+	}
+
+	codec, compressed := CompressionCodec(framed[0]), framed[1:]
+
+	switch codec {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCompression, err)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r, MaxBlobSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCompression, err)
+		}
+
+		if len(data) > MaxBlobSize {
+			return nil, ErrDataTooLong
+		}
+
+		return data, nil
+	case CompressionZstd:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := decoder.DecodeAll(compressed, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, ErrDataTooLong
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCompression, err)
+		}
+
+		return data, nil
+	default:
+		return append([]byte(nil), compressed...), nil
+	}
+}
+
+// zstdCodecs are the Zstandard encoder and decoder, created once as they're costly and safe for concurrent use.
+var zstdCodecs struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func initZstdCodecs() {
+	zstdCodecs.encoder, zstdCodecs.err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if zstdCodecs.err != nil {
+		return
+	}
+
+	zstdCodecs.decoder, zstdCodecs.err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxBlobSize))
+}
+
+// zstdEncoder returns the shared Zstandard encoder.
+func zstdEncoder() (*zstd.Encoder, error) {
+	zstdCodecs.once.Do(initZstdCodecs)
+	return zstdCodecs.encoder, zstdCodecs.err
+}
+
+// zstdDecoder returns the shared Zstandard decoder, rejecting data decompressing to more than MaxBlobSize bytes.
+func zstdDecoder() (*zstd.Decoder, error) {
+	zstdCodecs.once.Do(initZstdCodecs)
+	return zstdCodecs.decoder, zstdCodecs.err
+}
+
+// compress frames the data with the compression codec of the client, if it has one, unless it's a chunk of
+// SubmitDataChunked, whose data was compressed before it was split.
+func (c *client) compress(data []byte) ([]byte, error) {
+	if c.compression == 0 || IsChunk(data) {
+		return data, nil
+	}
+
+	return CompressData(c.compression, data)
+}
+
+// decompress decompresses the data of a submit_data extrinsic if the client compresses the data it submits, and
+// it's framed by a compression codec.
+func (c *client) decompress(data []byte) ([]byte, error) {
+	if c.compression == 0 || !IsCompressed(data) {
+		return data, nil
+	}
+
+	return DecompressData(data)
+}
+
+// decodeBlob decodes the blob of the data of a submit_data extrinsic, decompressing it first if it's framed by
+// a compression codec.
+func decodeBlob(data []byte) (Blob, error) {
+	var blob Blob
+
+	if IsCompressed(data) {
+		decompressed, err := DecompressData(data)
+		if err != nil {
+			return blob, err
+		}
+
+		data = decompressed
+	}
+
+	decoder := scale.NewDecoder(bytes.NewBuffer(data))
+	err := blob.Decode(*decoder)
+
+	return blob, err
+}
@@ -0,0 +1,188 @@
+package avail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+var compressionCodecs = []CompressionCodec{CompressionNone, CompressionGzip, CompressionZstd}
+
+func TestCompressData(t *testing.T) {
+	// An RLP encoded batch is mostly zero bytes.
+	compressible := append(bytes.Repeat([]byte{0}, 4096), []byte("edge block batch")...)
+
+	incompressible := make([]byte, 4096)
+	if _, err := rand.Read(incompressible); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, codec := range compressionCodecs {
+		t.Run(codec.String(), func(t *testing.T) {
+			for _, data := range [][]byte{compressible, incompressible, {}, []byte("x")} {
+				framed, err := CompressData(codec, data)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				assert.True(t, IsCompressed(framed))
+
+				decompressed, err := DecompressData(framed)
+				assert.NoError(t, err)
+				assert.Equal(t, len(data), len(decompressed))
+				assert.True(t, bytes.Equal(data, decompressed))
+			}
+
+			framed, err := CompressData(codec, compressible)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if codec == CompressionNone {
+				assert.Equal(t, byte(CompressionNone), framed[0])
+			} else {
+				assert.Equal(t, byte(codec), framed[0])
+				assert.Less(t, len(framed), len(compressible)/10)
+			}
+
+			// The compression is skipped when it doesn't reduce the size.
+			framed, err = CompressData(codec, incompressible)
+			if assert.NoError(t, err) {
+				assert.Equal(t, append([]byte{byte(CompressionNone)}, incompressible...), framed)
+			}
+		})
+	}
+
+	_, err := CompressData(CompressionCodec(0x01), compressible)
+	assert.ErrorIs(t, err, ErrInvalidCompression)
+
+	// The framing prefixes can't be mistaken for a blob or a chunk.
+	assert.False(t, IsCompressed([]byte{BlobMagic, 0}))
+	assert.False(t, IsCompressed([]byte{ChunkMagic, 0}))
+	assert.False(t, IsCompressed(nil))
+}
+
+func TestDecompressDataInvalid(t *testing.T) {
+	for name, framed := range map[string][]byte{
+		"unframed":       {BlobMagic, 1, 2, 3},
+		"empty":          nil,
+		"corrupted gzip": {byte(CompressionGzip), 1, 2, 3},
+		"corrupted zstd": {byte(CompressionZstd), 1, 2, 3},
+	} {
+		_, err := DecompressData(framed)
+		assert.ErrorIs(t, err, ErrInvalidCompression, name)
+	}
+
+	// Data decompressing past the maximum blob size is refused.
+	for _, codec := range []CompressionCodec{CompressionGzip, CompressionZstd} {
+		framed, err := CompressData(codec, make([]byte, MaxBlobSize+1))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_, err = DecompressData(framed)
+		assert.ErrorIs(t, err, ErrDataTooLong, codec.String())
+	}
+}
+
+func TestDecodeCompressedBlob(t *testing.T) {
+	blob := Blob{Magic: BlobMagic, Data: bytes.Repeat([]byte{0, 0, 0, 1}, 1024)}
+
+	encoded, err := codec.Encode(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range compressionCodecs {
+		framed, err := CompressData(c, encoded)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		decoded, err := decodeBlob(framed)
+		assert.NoError(t, err, c.String())
+		assert.Equal(t, blob, decoded, c.String())
+	}
+
+	// The blobs sent without compression are still decoded.
+	decoded, err := decodeBlob(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, blob, decoded)
+}
+
+func TestSubmitDataCompressed(t *testing.T) {
+	m, err := NewMockClient(WithRetryPolicy(testRetryPolicy), WithCompression(CompressionZstd))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(account, new(big.Int).SetUint64(10*AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The size limit applies to the compressed data.
+	data := bytes.Repeat([]byte{0}, 2*DefaultMaxAppDataLength)
+
+	result, err := SubmitData(context.Background(), m, account, 1, data, SubmitOpts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	blk, ok := m.Block(result.BlockNumber)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	var submitted types.Bytes
+	if assert.NoError(t, codec.Decode(blk.Block.Extrinsics[result.ExtrinsicIndex].Method.Args, &submitted)) {
+		assert.Equal(t, byte(CompressionZstd), submitted[0])
+		assert.Less(t, len(submitted), DefaultMaxAppDataLength)
+	}
+
+	read, err := GetBlockExtrinsics(m, result.BlockHash, 1, nil)
+	if assert.NoError(t, err) && assert.Len(t, read, 1) {
+		assert.True(t, bytes.Equal(data, read[0]))
+	}
+}
+
+func FuzzCompressData(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("edge block batch"))
+	f.Add(bytes.Repeat([]byte{0}, 1024))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, codec := range compressionCodecs {
+			framed, err := CompressData(codec, data)
+			if err != nil {
+				t.Fatalf("%s: %s", codec, err)
+			}
+
+			if len(framed) > len(data)+1 {
+				t.Fatalf("%s: %d bytes compressed to %d", codec, len(data), len(framed))
+			}
+
+			decompressed, err := DecompressData(framed)
+			if err != nil {
+				t.Fatalf("%s: %s", codec, err)
+			}
+
+			if !bytes.Equal(data, decompressed) {
+				t.Fatalf("%s: round trip mismatch", codec)
+			}
+		}
+
+		// Arbitrary framed data fails to decompress without panicking.
+		_, _ = DecompressData(data)
+	})
+}
@@ -0,0 +1,277 @@
+package avail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// ChainConstants are the pallet constants of the Avail runtime bounding the submissions, read from its metadata.
+type ChainConstants struct {
+	// SpecVersion is the spec version of the runtime the constants were read from.
+	SpecVersion uint32
+	// MaxAppDataLength is the maximum length of the data of a submit_data extrinsic, in bytes.
+	MaxAppDataLength int
+	// MaxBlockLength is the maximum length of the normal extrinsics of a block, in bytes.
+	MaxBlockLength uint32
+	// MaxBlockWeight is the maximum weight of a block, its reference time for a two-dimensional weight.
+	MaxBlockWeight uint64
+	// ExistentialDeposit is the minimum balance of an account, in Avail fractions.
+	ExistentialDeposit *big.Int
+	// ExpectedBlockTime is the expected time between two blocks.
+	ExpectedBlockTime time.Duration
+}
+
+// constantsCache is the chain constants read from the metadata they're cached with, so they're read again once
+// the metadata is refreshed, i.e. after a runtime upgrade. It is safe for concurrent use.
+type constantsCache struct {
+	lock      sync.Mutex
+	meta      *types.Metadata
+	constants *ChainConstants
+}
+
+// GetChainConstants retrieves the pallet constants of the latest Avail runtime bounding the submissions. They're
+// cached per runtime version, and read again once a runtime upgrade is detected.
+// It takes a client, and returns the chain constants and an error if there is an issue.
+func GetChainConstants(client Client) (*ChainConstants, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := c.chainConstants(c.instance())
+	if err != nil {
+		return nil, err
+	}
+
+	constants := *cached
+	constants.ExistentialDeposit = new(big.Int).Set(cached.ExistentialDeposit)
+
+	return &constants, nil
+}
+
+// chainConstants returns the cached chain constants of the latest runtime, which the callers mustn't modify.
+func (c *client) chainConstants(api *gsrpc.SubstrateAPI) (*ChainConstants, error) {
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.constantsOf(meta)
+}
+
+// constantsOf returns the cached chain constants read from the metadata returned by c.metadata, which the callers
+// mustn't modify.
+func (c *client) constantsOf(meta *types.Metadata) (*ChainConstants, error) {
+	c.constantsCache.lock.Lock()
+	defer c.constantsCache.lock.Unlock()
+
+	if c.constantsCache.meta == meta {
+		return c.constantsCache.constants, nil
+	}
+
+	constants, err := readChainConstants(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	c.metadataCache.lock.Lock()
+	if c.metadataCache.meta == meta {
+		constants.SpecVersion = uint32(c.metadataCache.specVersion)
+	}
+	c.metadataCache.lock.Unlock()
+
+	c.constantsCache.meta = meta
+	c.constantsCache.constants = constants
+
+	return constants, nil
+}
+
+// readChainConstants reads the chain constants from the metadata.
+func readChainConstants(meta *types.Metadata) (*ChainConstants, error) {
+	maxLength, err := maxAppDataLength(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBlockLength, err := maxBlockLength(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBlockWeight, err := maxBlockWeight(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	deposit, err := existentialDeposit(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	blockTime, err := expectedBlockTime(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChainConstants{
+		MaxAppDataLength:   maxLength,
+		MaxBlockLength:     maxBlockLength,
+		MaxBlockWeight:     maxBlockWeight,
+		ExistentialDeposit: deposit,
+		ExpectedBlockTime:  blockTime,
+	}, nil
+}
+
+// maxBlockLength returns the maximum length of the normal extrinsics of a block, the first dispatch class of the
+// System.BlockLength constant.
+func maxBlockLength(meta *types.Metadata) (uint32, error) {
+	value, err := meta.FindConstantValue("System", "BlockLength")
+	if err != nil {
+		return 0, err
+	}
+
+	if len(value) < 4 {
+		return 0, fmt.Errorf("invalid System.BlockLength constant: %x", value)
+	}
+
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+// maxBlockWeight returns the maximum weight of a block, the max_block field of the System.BlockWeights constant
+// following the base_block one. The weights are u64 before Substrate two-dimensional weights, and a reference time
+// and a proof size after, so they're decoded after their type in the metadata.
+func maxBlockWeight(meta *types.Metadata) (uint64, error) {
+	constant, err := findConstant(meta, "System", "BlockWeights")
+	if err != nil {
+		return 0, err
+	}
+
+	blockWeights, err := lookupType(meta, constant.Type)
+	if err != nil {
+		return 0, err
+	}
+
+	if !blockWeights.Def.IsComposite || len(blockWeights.Def.Composite.Fields) < 2 {
+		return 0, fmt.Errorf("invalid System.BlockWeights constant type")
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(constant.Value))
+	fields := blockWeights.Def.Composite.Fields
+
+	if _, err := decodeWeight(meta, decoder, fields[0].Type); err != nil {
+		return 0, fmt.Errorf("invalid System.BlockWeights constant: %w", err)
+	}
+
+	weight, err := decodeWeight(meta, decoder, fields[1].Type)
+	if err != nil {
+		return 0, fmt.Errorf("invalid System.BlockWeights constant: %w", err)
+	}
+
+	return weight, nil
+}
+
+// decodeWeight decodes a weight of the type, and returns its reference time, i.e. its first field for
+// a two-dimensional weight.
+func decodeWeight(meta *types.Metadata, decoder *scale.Decoder, typeID types.Si1LookupTypeID) (uint64, error) {
+	t, err := lookupType(meta, typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case t.Def.IsPrimitive && t.Def.Primitive.Si0TypeDefPrimitive == types.IsU64:
+		var weight types.U64
+		if err := decoder.Decode(&weight); err != nil {
+			return 0, err
+		}
+
+		return uint64(weight), nil
+	case t.Def.IsCompact:
+		weight, err := decoder.DecodeUintCompact()
+		if err != nil {
+			return 0, err
+		}
+
+		if !weight.IsUint64() {
+			return 0, fmt.Errorf("weight %s overflows uint64", weight)
+		}
+
+		return weight.Uint64(), nil
+	case t.Def.IsComposite && len(t.Def.Composite.Fields) > 0:
+		var refTime uint64
+
+		for i, field := range t.Def.Composite.Fields {
+			weight, err := decodeWeight(meta, decoder, field.Type)
+			if err != nil {
+				return 0, err
+			}
+
+			if i == 0 {
+				refTime = weight
+			}
+		}
+
+		return refTime, nil
+	default:
+		return 0, fmt.Errorf("unsupported weight type %d", typeID.Int64())
+	}
+}
+
+// expectedBlockTime returns the Babe.ExpectedBlockTime constant, or twice the Timestamp.MinimumPeriod one for
+// a chain without BABE.
+func expectedBlockTime(meta *types.Metadata) (time.Duration, error) {
+	value, err := meta.FindConstantValue("Babe", "ExpectedBlockTime")
+	if err != nil {
+		value, err = meta.FindConstantValue("Timestamp", "MinimumPeriod")
+		if err != nil {
+			return 0, err
+		}
+
+		if len(value) != 8 {
+			return 0, fmt.Errorf("invalid Timestamp.MinimumPeriod constant: %x", value)
+		}
+
+		return 2 * time.Duration(binary.LittleEndian.Uint64(value)) * time.Millisecond, nil
+	}
+
+	if len(value) != 8 {
+		return 0, fmt.Errorf("invalid Babe.ExpectedBlockTime constant: %x", value)
+	}
+
+	return time.Duration(binary.LittleEndian.Uint64(value)) * time.Millisecond, nil
+}
+
+// findConstant returns the constant of the pallet, with its type.
+func findConstant(meta *types.Metadata, pallet, name string) (*types.ConstantMetadataV14, error) {
+	for _, p := range meta.AsMetadataV14.Pallets {
+		if string(p.Name) != pallet {
+			continue
+		}
+
+		for i := range p.Constants {
+			if string(p.Constants[i].Name) == name {
+				return &p.Constants[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("constant %s.%s not found", pallet, name)
+}
+
+// lookupType returns the type of the metadata.
+func lookupType(meta *types.Metadata, typeID types.Si1LookupTypeID) (*types.Si1Type, error) {
+	t, ok := meta.AsMetadataV14.EfficientLookup[typeID.Int64()]
+	if !ok {
+		return nil, fmt.Errorf("type %d not found in the metadata", typeID.Int64())
+	}
+
+	return t, nil
+}
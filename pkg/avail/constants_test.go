@@ -0,0 +1,140 @@
+package avail
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChainConstants(t *testing.T) {
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	constants, err := GetChainConstants(m)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, ChainConstants{
+		SpecVersion:        1,
+		MaxAppDataLength:   DefaultMaxAppDataLength,
+		MaxBlockLength:     3932160,
+		MaxBlockWeight:     2_000_000_000_000,
+		ExistentialDeposit: big.NewInt(100_000_000_000_000),
+		ExpectedBlockTime:  3 * time.Second,
+	}, *constants)
+
+	// The cached constants aren't modified through the returned ones.
+	constants.ExistentialDeposit.SetUint64(0)
+
+	deposit, err := GetExistentialDeposit(m)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100_000_000_000_000), deposit)
+}
+
+func TestChainConstantsRefreshedAfterUpgrade(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+	r := recordMockCalls(m)
+
+	// The runtime version isn't cached, so the upgrade is seen right away.
+	WithRuntimeVersionTTL(0)(m.client)
+
+	for i := 0; i < 3; i++ {
+		constants, err := GetChainConstants(m)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint32(1), constants.SpecVersion)
+			assert.Equal(t, DefaultMaxAppDataLength, constants.MaxAppDataLength)
+		}
+	}
+
+	assert.Equal(t, 1, r.recorded().metadataFetches)
+
+	// The upgraded runtime lowers the maximum length of the data.
+	var upgraded types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &upgraded); err != nil {
+		t.Fatal(err)
+	}
+
+	withDataAvailabilityPallet(&upgraded, 16)
+	m.UpgradeRuntime(&upgraded)
+
+	constants, err := GetChainConstants(m)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint32(2), constants.SpecVersion)
+		assert.Equal(t, 16, constants.MaxAppDataLength)
+	}
+
+	assert.Equal(t, 2, r.recorded().metadataFetches)
+
+	// The submissions are bounded by the refreshed constants.
+	q, err := NewSubmitQueue(m, account, SubmitOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer q.Close()
+
+	_, err = q.SubmitData(context.Background(), 1, make([]byte, 17))
+	assert.ErrorIs(t, err, ErrDataTooLarge)
+}
+
+func TestMaxBlockWeightV2(t *testing.T) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// The two-dimensional weights are a compact reference time and proof size.
+	u64Type := types.NewSi1LookupTypeIDFromUInt(1 << 20)
+	compactType := types.NewSi1LookupTypeIDFromUInt(1<<20 + 1)
+	weightType := types.NewSi1LookupTypeIDFromUInt(1<<20 + 2)
+	blockWeightsType := types.NewSi1LookupTypeIDFromUInt(1<<20 + 3)
+
+	lookup := meta.AsMetadataV14.EfficientLookup
+	lookup[u64Type.Int64()] = &types.Si1Type{Def: types.Si1TypeDef{
+		IsPrimitive: true,
+		Primitive:   types.Si1TypeDefPrimitive{Si0TypeDefPrimitive: types.IsU64},
+	}}
+	lookup[compactType.Int64()] = &types.Si1Type{Def: types.Si1TypeDef{
+		IsCompact: true,
+		Compact:   types.Si1TypeDefCompact{Type: u64Type},
+	}}
+	lookup[weightType.Int64()] = &types.Si1Type{Def: types.Si1TypeDef{
+		IsComposite: true,
+		Composite:   types.Si1TypeDefComposite{Fields: []types.Si1Field{{Type: compactType}, {Type: compactType}}},
+	}}
+	lookup[blockWeightsType.Int64()] = &types.Si1Type{Def: types.Si1TypeDef{
+		IsComposite: true,
+		Composite:   types.Si1TypeDefComposite{Fields: []types.Si1Field{{Type: weightType}, {Type: weightType}, {Type: u64Type}}},
+	}}
+
+	var value []byte
+
+	for _, weight := range []uint64{5_000_000_000, 1 << 20, 2_000_000_000_000, 5 << 20} {
+		encoded, err := codec.Encode(types.NewUCompactFromUInt(weight))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value = append(value, encoded...)
+	}
+
+	constant, err := findConstant(&meta, "System", "BlockWeights")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	constant.Type = blockWeightsType
+	constant.Value = value
+
+	weight, err := maxBlockWeight(&meta)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2_000_000_000_000), weight)
+}
@@ -0,0 +1,180 @@
+package avail
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrInvalidDataProof is the error returned when the data proof of a submission doesn't verify against the data
+// root committed in the header of its block.
+var ErrInvalidDataProof = errors.New("invalid data proof")
+
+// DataProof is the Merkle proof of the data of a submit_data extrinsic against the data root of its block, in
+// the shape of the binary Merkle tree verifiers of the Avail bridge contracts: the leaves are the Keccak-256 hashes
+// of the data of the submit_data extrinsics of the block, in block order, and a node without a sibling is promoted
+// to the level above.
+type DataProof struct {
+	// BlockHash is the hash of the Avail block including the data, and DataRoot the data root committed in its header.
+	BlockHash types.Hash `json:"blockHash"`
+	DataRoot  types.Hash `json:"dataRoot"`
+	// Proof are the sibling hashes of the Merkle branch of the leaf, from the leaves to the root.
+	Proof []types.Hash `json:"proof"`
+	// NumberOfLeaves is the number of leaves of the tree, i.e. of submit_data extrinsics of the block, and
+	// LeafIndex the index of the leaf of the data.
+	NumberOfLeaves uint32 `json:"numberOfLeaves"`
+	LeafIndex      uint32 `json:"leafIndex"`
+	// Leaf is the Keccak-256 hash of the data.
+	Leaf types.Hash `json:"leaf"`
+}
+
+// GetDataRoot retrieves the data root of the Avail block, committed in the header extension, i.e. the root of
+// the Merkle tree of the data submitted in the block.
+// It takes a client and the block hash, and returns the data root and an error if there is an issue.
+func GetDataRoot(client Client, blockHash types.Hash) (types.Hash, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	var header kateHeader
+	if err := c.instance().Client.Call(&header, "chain_getHeader", blockHash.Hex()); err != nil {
+		return types.Hash{}, fmt.Errorf("couldn't fetch the header of block %s: %w", blockHash.Hex(), err)
+	}
+
+	return header.Extension.V1.Commitment.DataRoot, nil
+}
+
+// VerifyDataRootForExtrinsic verifies that the payload submitted with SubmitData is committed in the data root
+// of the block including it: the Merkle proof of the extrinsic is queried with kate_queryDataProof, and its branch
+// is verified from the leaf recomputed from the payload to the data root of the block header, so the proof doesn't
+// rely on the node queried. The returned proof can be forwarded to an EVM verifier.
+// It takes a client, the result of the submission, and the submitted data.
+// It returns the data proof, and an error wrapping ErrInvalidDataProof if it doesn't verify, or an error if there
+// is an issue.
+func VerifyDataRootForExtrinsic(client Client, result *SubmitResult, payload []byte) (*DataProof, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	dataRoot, err := GetDataRoot(client, result.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var response dataProofResponse
+	if err := c.instance().Client.Call(&response, "kate_queryDataProof", result.ExtrinsicIndex, result.BlockHash.Hex()); err != nil {
+		return nil, fmt.Errorf("couldn't query the data proof of extrinsic %d of block %s: %w", result.ExtrinsicIndex, result.BlockHash.Hex(), err)
+	}
+
+	proof := &DataProof{
+		BlockHash:      result.BlockHash,
+		DataRoot:       dataRoot,
+		Proof:          response.Proof,
+		NumberOfLeaves: response.NumberOfLeaves,
+		LeafIndex:      response.LeafIndex,
+		Leaf:           dataLeaf(payload),
+	}
+
+	if response.Leaf != (types.Hash{}) && response.Leaf != proof.Leaf {
+		return nil, fmt.Errorf("%w: leaf %s of extrinsic %d isn't the hash of the payload", ErrInvalidDataProof, response.Leaf.Hex(), result.ExtrinsicIndex)
+	}
+
+	if response.Root != dataRoot {
+		return nil, fmt.Errorf("%w: root %s of the proof isn't the data root %s of block %s", ErrInvalidDataProof, response.Root.Hex(), dataRoot.Hex(), result.BlockHash.Hex())
+	}
+
+	if !proof.verify() {
+		return nil, fmt.Errorf("%w: Merkle branch of extrinsic %d doesn't lead to the data root of block %s", ErrInvalidDataProof, result.ExtrinsicIndex, result.BlockHash.Hex())
+	}
+
+	return proof, nil
+}
+
+// verify checks the Merkle branch of the leaf against the data root.
+func (p *DataProof) verify() bool {
+	if p.LeafIndex >= p.NumberOfLeaves {
+		return false
+	}
+
+	computed := p.Leaf
+	position, width := p.LeafIndex, p.NumberOfLeaves
+
+	for _, sibling := range p.Proof {
+		// The last node of an odd level is promoted until it's the right child of a node.
+		if position%2 == 1 || position+1 == width {
+			computed = hashNodes(sibling, computed)
+		} else {
+			computed = hashNodes(computed, sibling)
+		}
+
+		position /= 2
+		width = (width-1)/2 + 1
+	}
+
+	return computed == p.DataRoot
+}
+
+// dataProofResponse is the response of the kate_queryDataProof RPC.
+type dataProofResponse struct {
+	Root           types.Hash   `json:"root"`
+	Proof          []types.Hash `json:"proof"`
+	NumberOfLeaves uint32       `json:"numberOfLeaves"`
+	LeafIndex      uint32       `json:"leafIndex"`
+	Leaf           types.Hash   `json:"leaf"`
+}
+
+// dataLeaf returns the leaf of the data in the data root Merkle tree.
+func dataLeaf(data []byte) types.Hash {
+	var leaf types.Hash
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	h.Sum(leaf[:0])
+
+	return leaf
+}
+
+// hashNodes returns the parent of the nodes in the data root Merkle tree.
+func hashNodes(left, right types.Hash) types.Hash {
+	return dataLeaf(append(left[:], right[:]...))
+}
+
+// dataRoot returns the root of the Merkle tree of the leaves, zero if there are none, along with the Merkle branch
+// of the leaf at the index.
+func dataRoot(leaves []types.Hash, index int) (types.Hash, []types.Hash) {
+	if len(leaves) == 0 {
+		return types.Hash{}, nil
+	}
+
+	var proof []types.Hash
+
+	level := leaves
+
+	for len(level) > 1 {
+		next := make([]types.Hash, 0, (len(level)+1)/2)
+
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+
+			if index == i {
+				proof = append(proof, level[i+1])
+			} else if index == i+1 {
+				proof = append(proof, level[i])
+			}
+
+			next = append(next, hashNodes(level[i], level[i+1]))
+		}
+
+		level = next
+		index /= 2
+	}
+
+	return level[0], proof
+}
@@ -0,0 +1,66 @@
+package avail
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataProofVerify(t *testing.T) {
+	leaves := make([]types.Hash, 7)
+	for i := range leaves {
+		leaves[i] = dataLeaf([]byte{byte(i)})
+	}
+
+	// The last leaf of the odd level is promoted.
+	root, proof := dataRoot(leaves[:3], 2)
+	assert.Equal(t, hashNodes(hashNodes(leaves[0], leaves[1]), leaves[2]), root)
+	assert.Equal(t, []types.Hash{hashNodes(leaves[0], leaves[1])}, proof)
+
+	for n := 1; n <= len(leaves); n++ {
+		for i := 0; i < n; i++ {
+			t.Run(fmt.Sprintf("leaf %d of %d", i, n), func(t *testing.T) {
+				root, branch := dataRoot(leaves[:n], i)
+
+				p := &DataProof{DataRoot: root, Proof: branch, NumberOfLeaves: uint32(n), LeafIndex: uint32(i), Leaf: leaves[i]}
+				assert.True(t, p.verify())
+
+				p.Leaf = dataLeaf([]byte("other"))
+				assert.False(t, p.verify())
+			})
+		}
+	}
+
+	p := &DataProof{DataRoot: leaves[0], NumberOfLeaves: 1, LeafIndex: 1, Leaf: leaves[0]}
+	assert.False(t, p.verify())
+}
+
+func TestVerifyDataRootForExtrinsic(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	data := []byte("edge block batch")
+
+	result, err := SubmitData(context.Background(), m, funder, 1, data, SubmitOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := GetDataRoot(m, result.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, dataLeaf(data), root)
+
+	emptyRoot, err := GetDataRoot(m, m.GenesisHash())
+	assert.NoError(t, err)
+	assert.Equal(t, types.Hash{}, emptyRoot)
+
+	proof, err := VerifyDataRootForExtrinsic(m, result, data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, DataProof{BlockHash: result.BlockHash, DataRoot: root, NumberOfLeaves: 1, Leaf: root}, *proof)
+	}
+
+	_, err = VerifyDataRootForExtrinsic(m, result, []byte("other data"))
+	assert.ErrorIs(t, err, ErrInvalidDataProof)
+}
@@ -0,0 +1,192 @@
+package avail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+)
+
+// ErrDryRunFailed is the error returned by the submit helpers when the dry run requested by the submission
+// options reports that the extrinsic would fail to dispatch. Nothing is submitted then.
+var ErrDryRunFailed = errors.New("extrinsic dry run failed")
+
+// invalidTransactions and unknownTransactions are the names of the reasons a transaction is invalid, by their
+// index in the substrate InvalidTransaction and UnknownTransaction enums.
+var (
+	invalidTransactions = []string{
+		"Call", "Payment", "Future", "Stale", "BadProof", "AncientBirthBlock", "ExhaustsResources", "Custom",
+		"BadMandatory", "MandatoryValidation", "BadSigner",
+	}
+	unknownTransactions = []string{"CannotLookup", "NoUnsignedValidator", "Custom"}
+)
+
+// DryRunResult is the outcome of an extrinsic executed against the current state with DryRun.
+type DryRunResult struct {
+	// Invalid is the reason the extrinsic isn't valid, e.g. "Invalid: Stale" for an outdated nonce, or empty if
+	// it's valid. An invalid extrinsic is rejected by the transaction pool, so it doesn't cost any fee.
+	Invalid string
+	// DispatchError is the error the valid extrinsic fails to dispatch with, or nil if it's dispatched.
+	DispatchError *types.DispatchError
+	// Pallet and Error are the names of the pallet and of the error of a module dispatch error, resolved
+	// with the metadata. They are empty if the dispatch error isn't a module error, or is unknown.
+	Pallet string
+	Error  string
+
+	// reason is the description of the dispatch error.
+	reason string
+}
+
+// Ok checks whether the extrinsic is valid and dispatched successfully.
+func (r *DryRunResult) Ok() bool {
+	return r.Invalid == "" && r.DispatchError == nil
+}
+
+func (r *DryRunResult) String() string {
+	switch {
+	case r.Invalid != "":
+		return "invalid transaction: " + r.Invalid
+	case r.DispatchError != nil:
+		return "dispatch error: " + r.reason
+	default:
+		return "ok"
+	}
+}
+
+// DryRun executes the signed extrinsic against the current state with the system_dryRun RPC, without submitting it.
+// It takes a client and the signed extrinsic, and returns the dry run result, or an error if there is an issue.
+func DryRun(client Client, ext types.Extrinsic) (*DryRunResult, error) {
+	api, err := instance(client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := latestMetadata(client, api)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := codec.EncodeToHex(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	var res string
+	if err := api.Client.Call(&res, "system_dryRun", encoded); err != nil {
+		return nil, fmt.Errorf("couldn't dry run the extrinsic: %w", err)
+	}
+
+	raw, err := codec.HexDecodeString(res)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dry run result %q: %w", res, err)
+	}
+
+	return decodeDryRun(meta, raw)
+}
+
+// preflight dry runs the signed extrinsic, and returns an error wrapping ErrDryRunFailed if it would fail to dispatch.
+// An invalid extrinsic isn't an error, as the transaction pool rejects it without any fee.
+func (c *client) preflight(ext types.Extrinsic) error {
+	result, err := DryRun(c, ext)
+	if err != nil {
+		return err
+	}
+
+	if result.DispatchError != nil {
+		return fmt.Errorf("%w: %s", ErrDryRunFailed, result)
+	}
+
+	return nil
+}
+
+// decodeDryRun decodes the ApplyExtrinsicResult of a dry run, i.e. a Result<Result<(), DispatchError>,
+// TransactionValidityError>, and resolves the module error names with the metadata.
+func decodeDryRun(meta *types.Metadata, raw []byte) (*DryRunResult, error) {
+	decoder := scale.NewDecoder(bytes.NewReader(raw))
+
+	valid, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, fmt.Errorf("invalid dry run result: %w", err)
+	}
+
+	result := &DryRunResult{}
+
+	switch valid {
+	case 0:
+		dispatched, err := decoder.ReadOneByte()
+		if err != nil {
+			return nil, fmt.Errorf("invalid dry run result: %w", err)
+		}
+
+		if dispatched == 0 {
+			return result, nil
+		}
+
+		var dispatchError types.DispatchError
+		if err := decoder.Decode(&dispatchError); err != nil {
+			return nil, fmt.Errorf("invalid dry run dispatch error: %w", err)
+		}
+
+		result.DispatchError = &dispatchError
+		result.reason = describeDispatchError(meta, dispatchError)
+
+		if dispatchError.IsModule {
+			result.Pallet, result.Error = moduleErrorNames(meta, dispatchError.ModuleError)
+		}
+
+		if result.Pallet != "" {
+			result.reason = result.Pallet + "." + result.Error
+		}
+	case 1:
+		kind, err := decoder.ReadOneByte()
+		if err != nil {
+			return nil, fmt.Errorf("invalid dry run result: %w", err)
+		}
+
+		reason, err := decoder.ReadOneByte()
+		if err != nil {
+			return nil, fmt.Errorf("invalid dry run result: %w", err)
+		}
+
+		switch kind {
+		case 0:
+			result.Invalid = "Invalid: " + validityReason(invalidTransactions, reason)
+		case 1:
+			result.Invalid = "Unknown: " + validityReason(unknownTransactions, reason)
+		default:
+			return nil, fmt.Errorf("invalid dry run transaction validity error %d", kind)
+		}
+	default:
+		return nil, fmt.Errorf("invalid dry run result %d", valid)
+	}
+
+	return result, nil
+}
+
+// moduleErrorNames returns the names of the pallet and of the error of the module error, or empty names if the
+// metadata doesn't know them.
+func moduleErrorNames(meta *types.Metadata, moduleError types.ModuleError) (string, string) {
+	metaError, err := meta.FindError(moduleError.Index, moduleError.Error)
+	if err != nil {
+		return "", ""
+	}
+
+	for _, pallet := range meta.AsMetadataV14.Pallets {
+		if pallet.Index == moduleError.Index {
+			return string(pallet.Name), metaError.Name
+		}
+	}
+
+	return "", metaError.Name
+}
+
+func validityReason(reasons []string, reason byte) string {
+	if int(reason) < len(reasons) {
+		return reasons[reason]
+	}
+
+	return fmt.Sprintf("%d", reason)
+}
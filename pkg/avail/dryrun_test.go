@@ -0,0 +1,119 @@
+package avail
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDryRunClient serves the result of the system_dryRun calls, which the mock client doesn't serve, and captures
+// the number of calls. The other calls are served by the client it wraps, if any.
+type mockDryRunClient struct {
+	gsrpcclient.Client
+
+	result string
+	runs   int
+}
+
+func (c *mockDryRunClient) Call(result interface{}, method string, args ...interface{}) error {
+	if method != "system_dryRun" {
+		if c.Client != nil {
+			return c.Client.Call(result, method, args...)
+		}
+
+		return fmt.Errorf("unexpected method %s", method)
+	}
+
+	c.runs++
+	*result.(*string) = c.result
+
+	return nil
+}
+
+func TestDecodeDryRun(t *testing.T) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name    string
+		raw     []byte
+		ok      bool
+		invalid string
+		pallet  string
+		err     string
+		result  string
+	}{
+		{name: "dispatched", raw: []byte{0, 0}, ok: true, result: "ok"},
+		{name: "module error", raw: []byte{0, 1, 3, 6, 2, 0, 0, 0}, pallet: "Balances", err: "InsufficientBalance", result: "dispatch error: Balances.InsufficientBalance"},
+		{name: "unknown module error", raw: []byte{0, 1, 3, 200, 1, 0, 0, 0}, result: "dispatch error: module 200 error [1 0 0 0]"},
+		{name: "bad origin", raw: []byte{0, 1, 2}, result: "dispatch error: bad origin"},
+		{name: "stale", raw: []byte{1, 0, 3}, invalid: "Invalid: Stale", result: "invalid transaction: Invalid: Stale"},
+		{name: "cannot lookup", raw: []byte{1, 1, 0}, invalid: "Unknown: CannotLookup", result: "invalid transaction: Unknown: CannotLookup"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := decodeDryRun(&meta, tc.raw)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tc.ok, result.Ok())
+			assert.Equal(t, tc.invalid, result.Invalid)
+			assert.Equal(t, tc.pallet, result.Pallet)
+			assert.Equal(t, tc.err, result.Error)
+			assert.Equal(t, tc.result, result.String())
+		})
+	}
+
+	_, err := decodeDryRun(&meta, []byte{2})
+	assert.Error(t, err)
+
+	_, err = decodeDryRun(&meta, []byte{0})
+	assert.Error(t, err)
+}
+
+func TestSubmitDataDryRun(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	if err := m.SetNonce(account, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	api := m.instance()
+	dryRun := &mockDryRunClient{Client: api.Client, result: "0x0001030602000000"}
+	api.Client = dryRun
+
+	nonces := NewNonceManager()
+	opts := SubmitOpts{Nonces: nonces, DryRun: true}
+
+	// The call would fail to dispatch, so it isn't submitted, and its nonce is still unused.
+	_, err := SubmitData(context.Background(), m, account, 1, []byte("block"), opts)
+	assert.ErrorIs(t, err, ErrDryRunFailed)
+	assert.ErrorContains(t, err, "Balances.InsufficientBalance")
+	assert.Empty(t, m.Submitted())
+	assert.Equal(t, 1, dryRun.runs)
+
+	// An invalid extrinsic is still submitted, the transaction pool rejects it.
+	dryRun.result = "0x010003"
+	m.Script(MockOutcome{Invalid: true})
+
+	_, err = SubmitData(context.Background(), m, account, 1, []byte("block"), opts)
+	assert.ErrorIs(t, err, ErrExtrinsicInvalid)
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+		assert.Equal(t, types.NewUCompactFromUInt(3), submitted[0].Signature.Nonce)
+	}
+
+	// Without the option, nothing is dry run.
+	_, err = SubmitData(context.Background(), m, account, 1, []byte("block"), SubmitOpts{Nonces: nonces})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dryRun.runs)
+}
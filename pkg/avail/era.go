@@ -0,0 +1,108 @@
+package avail
+
+import (
+	"math/bits"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+const (
+	minMortalPeriod = 4
+	maxMortalPeriod = 1 << 16
+)
+
+// SignOpts are the options of the extrinsic signatures.
+type SignOpts struct {
+	// MortalPeriod is the number of blocks a signed extrinsic is valid for, so a dropped extrinsic can't be
+	// included later on. It's rounded up to a power of two between 4 and 65536. Zero signs immortal extrinsics.
+	MortalPeriod uint64
+
+	// tip is the tip paid for the extrinsics, see WithTip.
+	tip *uint64
+}
+
+// MortalEra is the validity period of a mortal extrinsic, starting at the block with the phase number modulo the period.
+type MortalEra struct {
+	Period uint64
+	Phase  uint64
+}
+
+// NewMortalEra returns the era of an extrinsic valid for the period from the current block on.
+// The period is rounded up to a power of two between 4 and 65536, and the phase is quantized for
+// the periods above 4096, the same way as substrate does.
+func NewMortalEra(period, currentBlock uint64) MortalEra {
+	if period > maxMortalPeriod {
+		period = maxMortalPeriod
+	} else if period < minMortalPeriod {
+		period = minMortalPeriod
+	}
+
+	// Round up to the next power of two.
+	if period&(period-1) != 0 {
+		period = 1 << bits.Len64(period)
+	}
+
+	quantizeFactor := eraQuantizeFactor(period)
+	phase := currentBlock % period / quantizeFactor * quantizeFactor
+
+	return MortalEra{Period: period, Phase: phase}
+}
+
+// Birth returns the number of the first block the extrinsic is valid in, which is the block its signature is
+// checked against, for an extrinsic signed at the current block.
+func (e MortalEra) Birth(currentBlock uint64) uint64 {
+	if currentBlock < e.Phase {
+		currentBlock = e.Phase
+	}
+
+	return (currentBlock-e.Phase)/e.Period*e.Period + e.Phase
+}
+
+// ExtrinsicEra returns the era in its extrinsic encoding.
+func (e MortalEra) ExtrinsicEra() types.ExtrinsicEra {
+	period := bits.TrailingZeros64(e.Period) - 1
+	if period < 1 {
+		period = 1
+	} else if period > 15 {
+		period = 15
+	}
+
+	encoded := uint16(period) | uint16(e.Phase/eraQuantizeFactor(e.Period))<<4
+
+	return types.ExtrinsicEra{
+		IsMortalEra: true,
+		AsMortalEra: types.MortalEra{First: byte(encoded), Second: byte(encoded >> 8)},
+	}
+}
+
+func eraQuantizeFactor(period uint64) uint64 {
+	if factor := period >> 12; factor > 1 {
+		return factor
+	}
+
+	return 1
+}
+
+// signingEra returns the era and the block hash of the signature options. An immortal extrinsic is checked
+// against the genesis block, a mortal one against the block its era starts at.
+func signingEra(r *rpc.RPC, genesisHash types.Hash, opts SignOpts) (types.ExtrinsicEra, types.Hash, error) {
+	if opts.MortalPeriod == 0 {
+		return types.ExtrinsicEra{IsImmortalEra: true}, genesisHash, nil
+	}
+
+	header, err := r.Chain.GetHeaderLatest()
+	if err != nil {
+		return types.ExtrinsicEra{}, types.Hash{}, err
+	}
+
+	currentBlock := uint64(header.Number)
+	era := NewMortalEra(opts.MortalPeriod, currentBlock)
+
+	blockHash, err := r.Chain.GetBlockHash(era.Birth(currentBlock))
+	if err != nil {
+		return types.ExtrinsicEra{}, types.Hash{}, err
+	}
+
+	return era.ExtrinsicEra(), blockHash, nil
+}
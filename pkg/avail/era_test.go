@@ -0,0 +1,100 @@
+package avail
+
+import (
+	"context"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMortalEra(t *testing.T) {
+	tt := []struct {
+		name         string
+		period       uint64
+		currentBlock uint64
+		era          MortalEra
+		encoded      types.MortalEra
+	}{
+		// The vectors of the substrate and go-substrate-rpc-client era encoding tests.
+		{name: "substrate vector", period: 64, currentBlock: 42, era: MortalEra{Period: 64, Phase: 42}, encoded: types.MortalEra{First: 0xa5, Second: 0x02}},
+		{name: "quantized phase", period: 32768, currentBlock: 20000, era: MortalEra{Period: 32768, Phase: 20000}, encoded: types.MortalEra{First: 78, Second: 156}},
+		{name: "period rounded up", period: 200, currentBlock: 513, era: MortalEra{Period: 256, Phase: 1}, encoded: types.MortalEra{First: 0x17, Second: 0x00}},
+		{name: "minimum period", period: 2, currentBlock: 1, era: MortalEra{Period: 4, Phase: 1}, encoded: types.MortalEra{First: 0x11, Second: 0x00}},
+		{name: "phase modulo period", period: 4, currentBlock: 5, era: MortalEra{Period: 4, Phase: 1}, encoded: types.MortalEra{First: 0x11, Second: 0x00}},
+		{name: "maximum period", period: 1000000, currentBlock: 1000000, era: MortalEra{Period: 65536, Phase: 16960}, encoded: types.MortalEra{First: 0x4f, Second: 0x42}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			era := NewMortalEra(tc.period, tc.currentBlock)
+			assert.Equal(t, tc.era, era)
+			assert.Equal(t, types.ExtrinsicEra{IsMortalEra: true, AsMortalEra: tc.encoded}, era.ExtrinsicEra())
+		})
+	}
+}
+
+func TestMortalEraBirth(t *testing.T) {
+	era := NewMortalEra(64, 42)
+
+	assert.Equal(t, uint64(42), era.Birth(42))
+	assert.Equal(t, uint64(42), era.Birth(105))
+	assert.Equal(t, uint64(106), era.Birth(106))
+
+	// The phase of a quantized era is rounded down, so the birth block is before the current one.
+	era = NewMortalEra(65536, 1000007)
+	assert.Equal(t, uint64(1000000), era.Birth(1000007))
+}
+
+// newMockClientAt42 returns a funded mock client whose head is block 42.
+func newMockClientAt42(t *testing.T) (*MockClient, signature.KeyringPair) {
+	m, funder := newFundedMockClient(t, 2*AVL)
+
+	for i := 0; i < 42; i++ {
+		produceBlock(t, m)
+	}
+
+	return m, funder
+}
+
+func TestDepositBalanceSignsMortalEra(t *testing.T) {
+	m, funder := newMockClientAt42(t)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{SignOpts: SignOpts{MortalPeriod: 64}})
+	assert.NoError(t, err)
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+		assert.Equal(t, types.ExtrinsicEra{IsMortalEra: true, AsMortalEra: types.MortalEra{First: 0xa5, Second: 0x02}}, submitted[0].Signature.Era)
+	}
+
+	// Only the hash of the era birth block is fetched, the genesis hash is cached by the client.
+	assert.Equal(t, []uint64{42}, r.recorded().blockHashes)
+}
+
+func TestDepositBalanceSignsImmortalEraByDefault(t *testing.T) {
+	m, funder := newMockClientAt42(t)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{})
+	assert.NoError(t, err)
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+		assert.Equal(t, types.ExtrinsicEra{IsImmortalEra: true}, submitted[0].Signature.Era)
+	}
+
+	assert.Empty(t, r.recorded().blockHashes)
+}
@@ -0,0 +1,120 @@
+package avail
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// DispatchError is the error returned by the submit helpers when an included extrinsic failed to dispatch, e.g.
+// a transfer exceeding the balance of the sender. The extrinsic still used its nonce and paid its fee.
+// It matches ErrExtrinsicFailed.
+type DispatchError struct {
+	// Module and Name are the names of the pallet and of the error of a module error, e.g. "Balances" and
+	// "InsufficientBalance". They're empty for the other dispatch errors.
+	Module string
+	Name   string
+	// Reason is the readable description of the dispatch error, e.g. "InsufficientBalance" or "bad origin".
+	Reason string
+}
+
+func (e *DispatchError) Error() string {
+	if e.Module != "" {
+		return fmt.Sprintf("%s: %s.%s", ErrExtrinsicFailed, e.Module, e.Name)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrExtrinsicFailed, e.Reason)
+}
+
+// Is matches ErrExtrinsicFailed.
+func (e *DispatchError) Is(target error) bool {
+	return target == ErrExtrinsicFailed
+}
+
+// newDispatchError returns the dispatch error with the names of a module error resolved with the metadata.
+func newDispatchError(meta *types.Metadata, dispatchError types.DispatchError) *DispatchError {
+	e := &DispatchError{Reason: describeDispatchError(meta, dispatchError)}
+
+	if !dispatchError.IsModule {
+		return e
+	}
+
+	moduleError := dispatchError.ModuleError
+
+	metaError, err := meta.FindError(moduleError.Index, moduleError.Error)
+	if err != nil {
+		return e
+	}
+
+	for _, pallet := range meta.AsMetadataV14.Pallets {
+		if pallet.Index == moduleError.Index {
+			e.Module, e.Name = string(pallet.Name), metaError.Name
+			break
+		}
+	}
+
+	return e
+}
+
+// ExtrinsicEvents are the events of the block including a submitted extrinsic, together with the index of the
+// extrinsic in the block, which tells its events apart.
+type ExtrinsicEvents struct {
+	// Raw are the encoded events of the whole block.
+	Raw types.EventRecordsRaw
+	// Index is the index of the extrinsic in the block.
+	Index uint32
+
+	meta *types.Metadata
+}
+
+// Decode decodes the events of the block into the target, a pointer to a struct embedding types.EventRecords with
+// fields for the Avail specific events the caller is interested in, e.g. DataAvailability_ApplicationKeyCreated.
+// See types.EventRecordsRaw.DecodeEventRecords.
+func (e *ExtrinsicEvents) Decode(target interface{}) error {
+	return e.Raw.DecodeEventRecords(e.meta, target)
+}
+
+// Emitted checks whether the event with the phase was emitted by the extrinsic.
+func (e *ExtrinsicEvents) Emitted(phase types.Phase) bool {
+	return phase.IsApplyExtrinsic && phase.AsApplyExtrinsic == e.Index
+}
+
+// dispatchOutcome fetches the block including the extrinsic of the submission result, and its events, and checks
+// that the extrinsic was dispatched successfully. The block number, the extrinsic index and the events of the result
+// are set once they're fetched. It returns a *DispatchError if the extrinsic failed.
+func (c *client) dispatchOutcome(r *rpc.RPC, meta *types.Metadata, result *SubmitResult) error {
+	raw, index, number, err := includedExtrinsicEvents(r, meta, result.BlockHash, result.ExtrinsicHash)
+	if err != nil {
+		return err
+	}
+
+	result.BlockNumber, result.ExtrinsicIndex = number, index
+	result.Events = &ExtrinsicEvents{Raw: raw, Index: index, meta: meta}
+
+	return c.invalidateMetadataOnMismatch(extrinsicDispatchOutcome(result.Events))
+}
+
+// extrinsicDispatchOutcome decodes the events and returns a *DispatchError if the extrinsic failed, or an error
+// if it emitted neither an ExtrinsicSuccess nor an ExtrinsicFailed event.
+func extrinsicDispatchOutcome(events *ExtrinsicEvents) error {
+	var records availEventRecords
+	if err := events.Decode(&records); err != nil {
+		return fmt.Errorf("couldn't decode the events of the block including the extrinsic: %w", &metadataMismatchError{err})
+	}
+
+	for _, ev := range records.System_ExtrinsicFailed {
+		if events.Emitted(ev.Phase) {
+			return newDispatchError(events.meta, ev.DispatchError)
+		}
+	}
+
+	for _, ev := range records.System_ExtrinsicSuccess {
+		if events.Emitted(ev.Phase) {
+			return nil
+		}
+	}
+
+	return errors.New("no dispatch event for the extrinsic")
+}
@@ -0,0 +1,77 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtrinsicDispatchOutcome(t *testing.T) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	events := &eventRecordsBuilder{t: t}
+	events.add(0, eventExtrinsicSuccess, dispatchInfo)
+	events.add(1, eventExtrinsicFailed, balancesInsufficientBalance, dispatchInfo)
+
+	raw := events.raw()
+
+	assert.NoError(t, extrinsicDispatchOutcome(&ExtrinsicEvents{Raw: raw, Index: 0, meta: &meta}))
+
+	err := extrinsicDispatchOutcome(&ExtrinsicEvents{Raw: raw, Index: 1, meta: &meta})
+	assert.ErrorIs(t, err, ErrExtrinsicFailed)
+
+	var dispatchErr *DispatchError
+	if assert.True(t, errors.As(err, &dispatchErr)) {
+		assert.Equal(t, "Balances", dispatchErr.Module)
+		assert.Equal(t, "InsufficientBalance", dispatchErr.Name)
+		assert.EqualError(t, err, "extrinsic failed: Balances.InsufficientBalance")
+	}
+
+	assert.EqualError(t, extrinsicDispatchOutcome(&ExtrinsicEvents{Raw: raw, Index: 2, meta: &meta}), "no dispatch event for the extrinsic")
+}
+
+func TestDepositBalanceDispatchFailed(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The transfer is included, but fails to dispatch.
+	m.Script(MockOutcome{DispatchError: m.ModuleError("Balances", "InsufficientBalance")})
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{})
+
+	var dispatchErr *DispatchError
+	if assert.ErrorAs(t, err, &dispatchErr) {
+		assert.Equal(t, "InsufficientBalance", dispatchErr.Name)
+	}
+}
+
+func TestSubmitDataEvents(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	result, err := SubmitData(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.NotNil(t, result.Events) {
+		assert.Equal(t, uint32(0), result.Events.Index)
+
+		var records types.EventRecords
+		assert.NoError(t, result.Events.Decode(&records))
+
+		if assert.Len(t, records.System_ExtrinsicSuccess, 1) {
+			assert.True(t, result.Events.Emitted(records.System_ExtrinsicSuccess[0].Phase))
+		}
+	}
+}
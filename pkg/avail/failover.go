@@ -0,0 +1,355 @@
+package avail
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// DefaultHealthCheckInterval is the interval between the health checks of the endpoints of a failover client.
+	DefaultHealthCheckInterval = 10 * time.Second
+
+	// DefaultMaxBlockLag is the number of blocks the active endpoint of a failover client can lag behind
+	// the best block of the other endpoints before failing over.
+	DefaultMaxBlockLag = 5
+)
+
+// FailoverEvent is a switch of the active endpoint of a failover client.
+type FailoverEvent struct {
+	// From is the endpoint which was active.
+	From string
+	// To is the endpoint which is now active.
+	To string
+	// Reason is the reason of the failover, e.g. the error of the endpoint which was active.
+	Reason string
+}
+
+// WithHealthCheckInterval sets the interval between the health checks of the endpoints of a failover client.
+func WithHealthCheckInterval(interval time.Duration) ClientOption {
+	return func(c *client) {
+		if interval > 0 {
+			c.healthCheckInterval = interval
+		}
+	}
+}
+
+// WithMaxBlockLag sets the number of blocks the active endpoint of a failover client can lag behind the best
+// block of the other endpoints before failing over.
+func WithMaxBlockLag(blocks uint64) ClientOption {
+	return func(c *client) {
+		c.maxBlockLag = blocks
+	}
+}
+
+// WithFailoverCallback sets the function called on every switch of the active endpoint of a failover client,
+// besides the warning logged. It must not block.
+func WithFailoverCallback(callback func(FailoverEvent)) ClientOption {
+	return func(c *client) {
+		c.onFailover = callback
+	}
+}
+
+// NewFailoverClient constructs a new Avail Client for several endpoints of the same Avail network.
+// The calls are routed to the first healthy endpoint. The endpoints are health-checked periodically, and the
+// client fails over to the next healthy one when the active endpoint errors or lags behind the best block of
+// the others by more than the maximum block lag, so the subscriptions are re-established with the new endpoint.
+// With WithLatencyAwareSelection, the calls are routed to the lowest-latency healthy endpoint instead. The
+// measurements of the health checks are returned by Stats.
+//
+// Parameters:
+//   - urls: The URLs of the Avail JSON-RPC servers, in the order of preference.
+//   - logger: The logger instance.
+//   - opts: The client options, e.g. WithHealthCheckInterval, WithMaxBlockLag, WithLatencyAwareSelection and
+//     WithFailoverCallback.
+//
+// Return:
+//   - Client: The Avail client instance, which must be closed to stop its health checks.
+//   - error: An error if none of the endpoints can be connected to.
+func NewFailoverClient(urls []string, logger hclog.Logger, opts ...ClientOption) (Client, error) {
+	c, err := newClient(urls, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) > 1 {
+		go c.healthCheckLoop(c.checkHealth)
+	}
+
+	return c, nil
+}
+
+// ActiveEndpoint returns the URL of the endpoint the calls of the client are routed to.
+func ActiveEndpoint(client Client) (string, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return "", err
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.endpoints) == 0 {
+		return "", errors.New("no Avail endpoints")
+	}
+
+	return c.endpoints[c.active], nil
+}
+
+// Close closes the connections to the Avail network, and stops the health checks of a failover or pooled client.
+func (c *client) Close() {
+	c.closeOnce.Do(func() {
+		if c.closeCh != nil {
+			close(c.closeCh)
+		}
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		for i, conn := range c.conns {
+			closeConn(conn)
+			c.conns[i] = nil
+		}
+
+		for i, member := range c.pool {
+			closeConn(member.api)
+			c.pool[i] = pooledConn{}
+		}
+	})
+}
+
+// healthCheckLoop runs the health check periodically, until the client is closed.
+func (c *client) healthCheckLoop(check func()) {
+	ticker := time.NewTicker(c.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closeCh:
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// checkHealth probes the best block of every endpoint, connecting to the disconnected ones, and fails over to the
+// first healthy endpoint if the active one errors or lags behind. A latency-aware client fails over to the fastest
+// endpoint within one block of the best block instead, if any, and switches to it from the healthy active endpoint
+// once it's faster by more than the hysteresis, see WithLatencyAwareSelection.
+func (c *client) checkHealth() {
+	c.lock.RLock()
+	endpoints := c.endpoints
+	conns := append([]*gsrpc.SubstrateAPI(nil), c.conns...)
+	c.lock.RUnlock()
+
+	heights := make([]uint64, len(endpoints))
+	latencies := make([]time.Duration, len(endpoints))
+	failures := make([]error, len(endpoints))
+
+	var best uint64
+
+	for i, url := range endpoints {
+		if conns[i] == nil {
+			api, err := c.dialEndpoint(url)
+			if err == nil {
+				err = c.checkGenesis(api)
+			}
+
+			if err != nil {
+				closeConn(api)
+				failures[i] = err
+				continue
+			}
+
+			conns[i] = api
+			c.setConn(i, api)
+		}
+
+		height, latency, err := c.probeEndpoint(conns[i])
+		if err != nil {
+			failures[i] = err
+			continue
+		}
+
+		heights[i], latencies[i] = height, latency
+
+		if height > best {
+			best = height
+		}
+	}
+
+	c.lock.Lock()
+
+	for i := range endpoints {
+		c.recordProbe(i, heights[i], latencies[i], failures[i])
+	}
+
+	from := c.active
+	reason := ""
+
+	switch {
+	case failures[from] != nil:
+		reason = failures[from].Error()
+	case best-heights[from] > c.maxBlockLag:
+		reason = fmt.Sprintf("%d blocks behind the best block %d", best-heights[from], best)
+	}
+
+	to := from
+	faster := false
+
+	switch {
+	case reason == "" && c.latencyAware:
+		to, reason = c.fasterEndpoint(best, heights, failures)
+		faster = to != from
+	case reason != "" && c.latencyAware:
+		if fastest := c.fastestEndpoint(best, heights, failures); fastest >= 0 {
+			to = fastest
+		}
+	}
+
+	if reason != "" && to == from {
+		for i := range endpoints {
+			if i != from && failures[i] == nil && c.conns[i] != nil && best-heights[i] <= c.maxBlockLag {
+				to = i
+				break
+			}
+		}
+	}
+
+	// The failing connections are closed, so they're re-established on the next health check.
+	var stale []*gsrpc.SubstrateAPI
+
+	for i := range endpoints {
+		if failures[i] != nil && c.conns[i] != nil && i != to {
+			stale = append(stale, c.conns[i])
+			c.conns[i] = nil
+		}
+	}
+
+	event := FailoverEvent{From: endpoints[from], To: endpoints[to], Reason: reason}
+
+	if to != from {
+		c.active = to
+		c.api = c.conns[to]
+
+		// The subscriptions of the lagging endpoint are re-established with the new active one once it's closed.
+		if c.conns[from] != nil {
+			stale = append(stale, c.conns[from])
+			c.conns[from] = nil
+		}
+	}
+
+	c.lock.Unlock()
+
+	for _, conn := range stale {
+		closeConn(conn)
+	}
+
+	switch {
+	case faster:
+		c.logger.Info("switching to a faster Avail endpoint", "from", event.From, "to", event.To, "reason", event.Reason)
+
+		if c.onFailover != nil {
+			c.onFailover(event)
+		}
+	case to != from:
+		c.logger.Warn("Avail endpoint failover", "from", event.From, "to", event.To, "reason", event.Reason)
+
+		if c.onFailover != nil {
+			c.onFailover(event)
+		}
+	case reason != "":
+		c.logger.Error("active Avail endpoint unhealthy, and no healthy endpoint to fail over to", "endpoint", event.From, "reason", reason)
+	}
+}
+
+// reconnect re-establishes the connection to the Avail network, trying the endpoints from the active one on,
+// and returns the new api.
+func (c *client) reconnect() (*gsrpc.SubstrateAPI, error) {
+	c.lock.RLock()
+	endpoints := c.endpoints
+	active := c.active
+	c.lock.RUnlock()
+
+	if len(endpoints) == 0 {
+		return nil, errors.New("couldn't reconnect to Avail: no endpoints")
+	}
+
+	var lastErr error
+
+	for n := range endpoints {
+		i := (active + n) % len(endpoints)
+
+		api, err := c.dialEndpoint(endpoints[i])
+		if err != nil {
+			c.logger.Debug("couldn't reconnect to Avail endpoint", "endpoint", endpoints[i], "error", err)
+			lastErr = err
+			continue
+		}
+
+		c.lock.Lock()
+		c.conns[i] = api
+		c.active = i
+		c.api = api
+		c.lock.Unlock()
+
+		if i != active {
+			c.logger.Warn("Avail endpoint failover", "from", endpoints[active], "to", endpoints[i], "reason", "reconnection")
+
+			if c.onFailover != nil {
+				c.onFailover(FailoverEvent{From: endpoints[active], To: endpoints[i], Reason: "reconnection"})
+			}
+		}
+
+		return api, nil
+	}
+
+	return nil, fmt.Errorf("couldn't reconnect to Avail endpoints %s: %w", strings.Join(endpoints, ", "), lastErr)
+}
+
+// dialEndpoint connects to the endpoint.
+func (c *client) dialEndpoint(url string) (*gsrpc.SubstrateAPI, error) {
+	if c.dial != nil {
+		return c.dial(url)
+	}
+
+	return gsrpc.NewSubstrateAPI(url)
+}
+
+// checkGenesis checks that the connection is to the same Avail network as the client.
+func (c *client) checkGenesis(api *gsrpc.SubstrateAPI) error {
+	genesisHash, err := api.RPC.Chain.GetBlockHash(0)
+	if err != nil {
+		return err
+	}
+
+	if genesisHash != c.genesisHash {
+		return fmt.Errorf("genesis hash %s doesn't match %s", genesisHash.Hex(), c.genesisHash.Hex())
+	}
+
+	return nil
+}
+
+// setConn sets the connection to the endpoint, unless it's already connected.
+func (c *client) setConn(i int, api *gsrpc.SubstrateAPI) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.conns[i] == nil {
+		c.conns[i] = api
+	} else {
+		defer closeConn(api)
+	}
+}
+
+// closeConn closes the connection, if any.
+func closeConn(api *gsrpc.SubstrateAPI) {
+	if api != nil && api.Client != nil {
+		api.Client.Close()
+	}
+}
@@ -0,0 +1,290 @@
+package avail
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// endpointChain serves the best block number of an endpoint, or fails with err, and the round-trip time of its
+// probes with latencyProbe. The endpoints are faked, as a mock client serves a single chain.
+type endpointChain struct {
+	chain.Chain
+
+	head    types.BlockNumber
+	genesis types.Hash
+	err     error
+	latency time.Duration
+}
+
+// latencyProbe probes the endpoints served by their endpointChain, with their scripted round-trip time.
+func latencyProbe(api *gsrpc.SubstrateAPI) (uint64, time.Duration, error) {
+	ch := api.RPC.Chain.(*endpointChain)
+
+	header, err := ch.GetHeaderLatest()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return uint64(header.Number), ch.latency, nil
+}
+
+func (c *endpointChain) GetHeaderLatest() (*types.Header, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &types.Header{Number: c.head}, nil
+}
+
+func (c *endpointChain) GetBlockHash(n uint64) (types.Hash, error) {
+	if c.err != nil {
+		return types.Hash{}, c.err
+	}
+
+	if n == 0 {
+		return c.genesis, nil
+	}
+
+	return endpointBlockHash(n), nil
+}
+
+// endpointBlockHash returns the hash of the block number served by endpointChain.
+func endpointBlockHash(n uint64) types.Hash {
+	return types.NewHash([]byte{byte(n + 1)})
+}
+
+// failoverTest is a failover client of the endpoints served by their endpointChain.
+type failoverTest struct {
+	c      *client
+	chains map[string]*endpointChain
+	events []FailoverEvent
+}
+
+func newFailoverTest(t *testing.T, heads map[string]types.BlockNumber, urls ...string) *failoverTest {
+	ft := &failoverTest{chains: make(map[string]*endpointChain)}
+
+	for _, url := range urls {
+		ft.chains[url] = &endpointChain{head: heads[url], genesis: endpointBlockHash(0)}
+	}
+
+	dial := func(c *client) {
+		c.dial = func(url string) (*gsrpc.SubstrateAPI, error) {
+			return &gsrpc.SubstrateAPI{RPC: &rpc.RPC{Chain: ft.chains[url]}}, nil
+		}
+	}
+
+	onFailover := WithFailoverCallback(func(event FailoverEvent) {
+		ft.events = append(ft.events, event)
+	})
+
+	c, err := newClient(urls, hclog.NewNullLogger(), dial, onFailover, WithMaxBlockLag(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ft.c = c
+
+	return ft
+}
+
+func (ft *failoverTest) activeEndpoint(t *testing.T) string {
+	t.Helper()
+
+	endpoint, err := ActiveEndpoint(ft.c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return endpoint
+}
+
+func TestFailoverClientRoutesToFirstEndpoint(t *testing.T) {
+	ft := newFailoverTest(t, map[string]types.BlockNumber{"a": 10, "b": 12}, "a", "b")
+
+	ft.c.checkHealth()
+
+	assert.Equal(t, "a", ft.activeEndpoint(t))
+	assert.Same(t, ft.chains["a"], ft.c.instance().RPC.Chain)
+	assert.Empty(t, ft.events)
+}
+
+func TestFailoverClientFailsOverOnError(t *testing.T) {
+	ft := newFailoverTest(t, map[string]types.BlockNumber{"a": 10, "b": 10, "c": 10}, "a", "b", "c")
+
+	ft.chains["a"].err = errors.New("connection reset")
+	ft.chains["b"].err = errors.New("connection refused")
+
+	ft.c.checkHealth()
+
+	assert.Equal(t, "c", ft.activeEndpoint(t))
+	assert.Same(t, ft.chains["c"], ft.c.instance().RPC.Chain)
+	assert.Equal(t, []FailoverEvent{{From: "a", To: "c", Reason: "connection reset"}}, ft.events)
+	assert.Nil(t, ft.c.conns[0])
+	assert.Nil(t, ft.c.conns[1])
+}
+
+func TestFailoverClientFailsOverOnLag(t *testing.T) {
+	ft := newFailoverTest(t, map[string]types.BlockNumber{"a": 7, "b": 9, "c": 10}, "a", "b", "c")
+
+	ft.c.checkHealth()
+
+	assert.Equal(t, "b", ft.activeEndpoint(t))
+	assert.Equal(t, []FailoverEvent{{From: "a", To: "b", Reason: "3 blocks behind the best block 10"}}, ft.events)
+
+	// The lagging endpoint is connected again once healthy, but the active one is kept.
+	ft.chains["a"].head = 10
+	ft.c.checkHealth()
+
+	assert.Equal(t, "b", ft.activeEndpoint(t))
+	assert.NotNil(t, ft.c.conns[0])
+	assert.Len(t, ft.events, 1)
+}
+
+func TestFailoverClientKeepsActiveEndpointWithoutHealthyOne(t *testing.T) {
+	ft := newFailoverTest(t, map[string]types.BlockNumber{"a": 10, "b": 10}, "a", "b")
+
+	ft.chains["a"].err = errors.New("connection reset")
+	ft.chains["b"].err = errors.New("connection refused")
+
+	ft.c.checkHealth()
+
+	assert.Equal(t, "a", ft.activeEndpoint(t))
+	assert.NotNil(t, ft.c.instance())
+	assert.Empty(t, ft.events)
+}
+
+func TestFailoverClientIgnoresOtherNetworks(t *testing.T) {
+	ft := newFailoverTest(t, map[string]types.BlockNumber{"a": 10}, "a")
+
+	other := &endpointChain{head: 20, genesis: endpointBlockHash(1)}
+	ft.chains["b"] = other
+
+	c, err := newClient([]string{"a", "b"}, hclog.NewNullLogger(), func(c *client) { c.dial = ft.c.dial })
+
+	assert.NoError(t, err)
+	assert.Nil(t, c.conns[1])
+}
+
+func TestReconnectFailsOverToNextEndpoint(t *testing.T) {
+	ft := newFailoverTest(t, map[string]types.BlockNumber{"a": 10, "b": 10}, "a", "b")
+
+	dial := ft.c.dial
+	ft.c.dial = func(url string) (*gsrpc.SubstrateAPI, error) {
+		if url == "a" {
+			return nil, errors.New("connection refused")
+		}
+
+		return dial(url)
+	}
+
+	api, err := ft.c.reconnect()
+
+	assert.NoError(t, err)
+	assert.Same(t, ft.chains["b"], api.RPC.Chain)
+	assert.Equal(t, "b", ft.activeEndpoint(t))
+	assert.Equal(t, []FailoverEvent{{From: "a", To: "b", Reason: "reconnection"}}, ft.events)
+}
+
+// newLatencyAwareTest returns a latency-aware failoverTest of the endpoints, with the round-trip times.
+func newLatencyAwareTest(t *testing.T, latencies map[string]time.Duration, urls ...string) *failoverTest {
+	heads := make(map[string]types.BlockNumber)
+	for _, url := range urls {
+		heads[url] = 10
+	}
+
+	ft := newFailoverTest(t, heads, urls...)
+	WithLatencyAwareSelection(0)(ft.c)
+	ft.c.probe = latencyProbe
+
+	for url, latency := range latencies {
+		ft.chains[url].latency = latency
+	}
+
+	return ft
+}
+
+func TestLatencyAwareClientRoutesToFastestEndpoint(t *testing.T) {
+	ft := newLatencyAwareTest(t, map[string]time.Duration{"a": 80 * time.Millisecond, "b": 20 * time.Millisecond, "c": 40 * time.Millisecond}, "a", "b", "c")
+
+	ft.c.checkHealth()
+
+	assert.Equal(t, "b", ft.activeEndpoint(t))
+	assert.Same(t, ft.chains["b"], ft.c.instance().RPC.Chain)
+	assert.Equal(t, []FailoverEvent{{From: "a", To: "b", Reason: "round-trip time 20ms, against 80ms"}}, ft.events)
+
+	stats := ft.c.Stats()
+	if assert.Len(t, stats, 3) {
+		assert.Equal(t, "b", stats[1].Endpoint)
+		assert.True(t, stats[1].Active)
+		assert.True(t, stats[1].Healthy)
+		assert.Equal(t, uint64(10), stats[1].Height)
+		assert.Equal(t, 20*time.Millisecond, stats[1].Latency)
+		assert.False(t, stats[0].Active)
+		assert.Equal(t, 80*time.Millisecond, stats[0].Latency)
+	}
+}
+
+func TestLatencyAwareClientIsHysteretic(t *testing.T) {
+	ft := newLatencyAwareTest(t, map[string]time.Duration{"a": 50 * time.Millisecond, "b": 45 * time.Millisecond}, "a", "b")
+
+	// The endpoints alternate being slightly faster, which doesn't switch the active one.
+	for i := 0; i < 6; i++ {
+		ft.chains["a"].latency, ft.chains["b"].latency = ft.chains["b"].latency, ft.chains["a"].latency
+		ft.c.checkHealth()
+	}
+
+	assert.Equal(t, "a", ft.activeEndpoint(t))
+	assert.Empty(t, ft.events)
+
+	// A single slow probe is smoothed out.
+	ft.chains["a"].latency = 80 * time.Millisecond
+	ft.c.checkHealth()
+
+	assert.Equal(t, "a", ft.activeEndpoint(t))
+
+	// The active endpoint is switched once it's consistently slower.
+	ft.c.checkHealth()
+
+	assert.Equal(t, "b", ft.activeEndpoint(t))
+	assert.Len(t, ft.events, 1)
+}
+
+func TestLatencyAwareClientPrefersCurrentEndpoints(t *testing.T) {
+	ft := newLatencyAwareTest(t, map[string]time.Duration{"a": 50 * time.Millisecond, "b": 10 * time.Millisecond, "c": 30 * time.Millisecond}, "a", "b", "c")
+
+	// The fastest endpoint is two blocks behind, within the maximum block lag but not within one block.
+	ft.chains["b"].head = 8
+	ft.c.checkHealth()
+
+	assert.Equal(t, "c", ft.activeEndpoint(t))
+
+	// The active endpoint falling behind is switched from, regardless of the hysteresis.
+	ft.chains["b"].head, ft.chains["c"].head, ft.chains["a"].head = 11, 9, 11
+	ft.c.checkHealth()
+
+	assert.Equal(t, "b", ft.activeEndpoint(t))
+	assert.Equal(t, []FailoverEvent{
+		{From: "a", To: "c", Reason: "round-trip time 30ms, against 50ms"},
+		{From: "c", To: "b", Reason: "2 blocks behind the best block 11"},
+	}, ft.events)
+
+	// On an error, the client fails over to the fastest endpoint.
+	ft.chains["b"].err = errors.New("connection reset")
+	ft.chains["c"].head = 11
+	ft.c.checkHealth()
+
+	assert.Equal(t, "c", ft.activeEndpoint(t))
+	assert.Equal(t, FailoverEvent{From: "b", To: "c", Reason: "connection reset"}, ft.events[2])
+
+	stats := ft.c.Stats()
+	assert.False(t, stats[1].Healthy)
+	assert.EqualError(t, stats[1].Err, "connection reset")
+}
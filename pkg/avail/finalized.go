@@ -0,0 +1,190 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// headsSubscription is the subscription to the finalized heads, i.e. a *chain.FinalizedHeadsSubscription.
+type headsSubscription interface {
+	Chan() <-chan types.Header
+	Err() <-chan error
+	Unsubscribe()
+}
+
+// SubscribeFinalizedHeads follows the finalized heads of Avail, in order and without gaps: when the subscription
+// skips block numbers, the intermediate headers are fetched and emitted first.
+// When the subscription fails, e.g. on a WebSocket disconnection, the connection is re-established following the
+// retry policy of the client, and the headers already emitted aren't emitted again.
+// It takes a context ending the subscription, and a client.
+// It returns the channel of the finalized headers, and the channel receiving the error ending the subscription
+// when the reconnections failed. Both channels are closed once the subscription ends, or the context is done.
+// It returns an error wrapping ErrSubscriptionsUnsupported if the client is connected over HTTP, or an error if the
+// subscription can't be established.
+func SubscribeFinalizedHeads(ctx context.Context, client Client) (<-chan types.Header, <-chan error, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.requireSubscriptions("SubscribeFinalizedHeads"); err != nil {
+		return nil, nil, err
+	}
+
+	api := c.instance()
+
+	sub, err := c.finalizedHeads(api)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := &finalizedHeadsFollower{
+		c:     c,
+		heads: make(chan types.Header),
+		errs:  make(chan error, 1),
+	}
+
+	go f.follow(ctx, api, sub)
+
+	return f.heads, f.errs, nil
+}
+
+// finalizedHeads subscribes to the finalized heads with the api.
+func (c *client) finalizedHeads(api *gsrpc.SubstrateAPI) (headsSubscription, error) {
+	if c.subscribeFinalizedHeads != nil {
+		return c.subscribeFinalizedHeads(api)
+	}
+
+	sub, err := api.RPC.Chain.SubscribeFinalizedHeads()
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// finalizedHeadsFollower emits the finalized headers of the subscriptions, in order and without gaps.
+type finalizedHeadsFollower struct {
+	c     *client
+	heads chan types.Header
+	errs  chan error
+
+	// next is the number of the next header to emit, once started.
+	next    types.BlockNumber
+	started bool
+}
+
+// follow emits the headers of the subscription, and re-establishes it when it fails, until the reconnections
+// fail or the context is done.
+func (f *finalizedHeadsFollower) follow(ctx context.Context, api *gsrpc.SubstrateAPI, sub headsSubscription) {
+	defer close(f.errs)
+	defer close(f.heads)
+
+	attempt := 0
+
+	for {
+		progressed, err := f.forward(ctx, api, sub)
+		sub.Unsubscribe()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// The attempts are counted from the last emitted header.
+		if progressed {
+			attempt = 0
+		}
+
+		for sub = nil; sub == nil; {
+			if attempt++; attempt > f.c.retry.MaxReconnects {
+				f.errs <- fmt.Errorf("finalized heads subscription failed: %w", err)
+				return
+			}
+
+			f.c.logger.Warn("finalized heads subscription failed, reconnecting", "attempt", attempt, "error", err)
+
+			select {
+			case <-time.After(time.Duration(attempt) * f.c.retry.Backoff):
+			case <-ctx.Done():
+				return
+			}
+
+			if api, err = f.c.reconnect(); err != nil {
+				continue
+			}
+
+			sub, err = f.c.finalizedHeads(api)
+		}
+	}
+}
+
+// forward emits the headers of the subscription until it fails or the context is done, and returns whether
+// a header was emitted.
+func (f *finalizedHeadsFollower) forward(ctx context.Context, api *gsrpc.SubstrateAPI, sub headsSubscription) (bool, error) {
+	progressed := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return progressed, ctx.Err()
+		case err := <-sub.Err():
+			return progressed, err
+		case header, ok := <-sub.Chan():
+			if !ok {
+				return progressed, errors.New("finalized heads subscription closed")
+			}
+
+			if err := f.emit(ctx, api, header); err != nil {
+				return progressed, err
+			}
+
+			progressed = true
+		}
+	}
+}
+
+// emit emits the header, after the headers missing since the last emitted one. A header already emitted is skipped.
+func (f *finalizedHeadsFollower) emit(ctx context.Context, api *gsrpc.SubstrateAPI, header types.Header) error {
+	if f.started && header.Number < f.next {
+		return nil
+	}
+
+	for f.started && f.next < header.Number {
+		blockHash, err := api.RPC.Chain.GetBlockHash(uint64(f.next))
+		if err != nil {
+			return fmt.Errorf("couldn't fetch the hash of finalized block %d: %w", f.next, err)
+		}
+
+		missing, err := api.RPC.Chain.GetHeader(blockHash)
+		if err != nil {
+			return fmt.Errorf("couldn't fetch the header of finalized block %d: %w", f.next, err)
+		}
+
+		if missing.Number != f.next {
+			return fmt.Errorf("fetched header %d instead of finalized block %d", missing.Number, f.next)
+		}
+
+		if err := f.send(ctx, *missing); err != nil {
+			return err
+		}
+	}
+
+	return f.send(ctx, header)
+}
+
+// send sends the header on the channel, unless the context is done.
+func (f *finalizedHeadsFollower) send(ctx context.Context, header types.Header) error {
+	select {
+	case f.heads <- header:
+		f.next = header.Number + 1
+		f.started = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
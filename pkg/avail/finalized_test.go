@@ -0,0 +1,167 @@
+package avail
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHeadsSubscription is a finalized heads subscription delivering headers in order, and then failing with an error.
+type fakeHeadsSubscription struct {
+	headCh chan types.Header
+	errCh  chan error
+	done   chan struct{}
+}
+
+func newFakeHeadsSubscription(err error, numbers ...types.BlockNumber) *fakeHeadsSubscription {
+	sub := &fakeHeadsSubscription{
+		headCh: make(chan types.Header),
+		errCh:  make(chan error),
+		done:   make(chan struct{}),
+	}
+
+	// The headers and the error are delivered one at a time, so they're received in order.
+	go func() {
+		for _, n := range numbers {
+			select {
+			case sub.headCh <- types.Header{Number: n}:
+			case <-sub.done:
+				return
+			}
+		}
+
+		if err != nil {
+			select {
+			case sub.errCh <- err:
+			case <-sub.done:
+			}
+		}
+	}()
+
+	return sub
+}
+
+func (s *fakeHeadsSubscription) Chan() <-chan types.Header { return s.headCh }
+func (s *fakeHeadsSubscription) Err() <-chan error         { return s.errCh }
+func (s *fakeHeadsSubscription) Unsubscribe()              { close(s.done) }
+
+// finalizedHeadsTest is a client whose finalized heads subscriptions are served in order, on a chain of 10 blocks
+// of a mock client, whose finalized head is the genesis block unless finalize is called.
+// The subscriptions are faked, as the mock client can't fail them.
+type finalizedHeadsTest struct {
+	m     *MockClient
+	c     *client
+	ch    *laggingChain
+	dials int
+}
+
+func newFinalizedHeadsTest(t *testing.T, policy RetryPolicy, subs ...headsSubscription) *finalizedHeadsTest {
+	m, err := NewMockClient(WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 1; n < 10; n++ {
+		produceBlock(t, m)
+	}
+
+	ft := &finalizedHeadsTest{m: m, c: m.client, ch: lagFinality(m, 9)}
+
+	api := ft.c.instance()
+	served := 0
+
+	ft.c.dial = func(_ string) (*gsrpc.SubstrateAPI, error) {
+		ft.dials++
+		return api, nil
+	}
+	ft.c.subscribeFinalizedHeads = func(_ *gsrpc.SubstrateAPI) (headsSubscription, error) {
+		if served++; served > len(subs) {
+			return nil, errSubmitStopped
+		}
+
+		return subs[served-1], nil
+	}
+
+	return ft
+}
+
+// finalize makes the block with the number the finalized head.
+func (ft *finalizedHeadsTest) finalize(n uint64) {
+	ft.ch.setLag(9 - n)
+}
+
+// receive returns the numbers of the headers received until the channel is closed.
+func receive(t *testing.T, heads <-chan types.Header) []types.BlockNumber {
+	t.Helper()
+
+	var numbers []types.BlockNumber
+
+	for {
+		select {
+		case header, ok := <-heads:
+			if !ok {
+				return numbers
+			}
+
+			numbers = append(numbers, header.Number)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out receiving the finalized heads")
+		}
+	}
+}
+
+func TestSubscribeFinalizedHeadsFillsGaps(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, RetryPolicy{}, newFakeHeadsSubscription(errConnectionReset, 1, 2, 5, 6))
+
+	heads, errs, err := SubscribeFinalizedHeads(context.Background(), ft.c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []types.BlockNumber{1, 2, 3, 4, 5, 6}, receive(t, heads))
+	assert.ErrorIs(t, <-errs, errConnectionReset)
+}
+
+func TestSubscribeFinalizedHeadsReconnects(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, testRetryPolicy,
+		newFakeHeadsSubscription(errConnectionReset, 1, 2),
+		newFakeHeadsSubscription(errConnectionReset, 2, 4),
+	)
+
+	heads, errs, err := SubscribeFinalizedHeads(context.Background(), ft.c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The header emitted before the reconnection isn't emitted again, and the ones missed meanwhile are fetched.
+	assert.Equal(t, []types.BlockNumber{1, 2, 3, 4}, receive(t, heads))
+
+	// The reconnections are counted from the last emitted header.
+	assert.ErrorIs(t, <-errs, errSubmitStopped)
+	assert.Equal(t, 1+testRetryPolicy.MaxReconnects, ft.dials)
+}
+
+func TestSubscribeFinalizedHeadsContextCancellation(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, testRetryPolicy, newFakeHeadsSubscription(nil, 1, 2, 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	heads, errs, err := SubscribeFinalizedHeads(ctx, ft.c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, types.BlockNumber(1), (<-heads).Number)
+
+	cancel()
+
+	receive(t, heads)
+
+	_, ok := <-errs
+	assert.False(t, ok)
+	assert.Equal(t, 0, ft.dials)
+}
@@ -0,0 +1,347 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// BlockFollowerOpts are the options of a BlockFollower.
+type BlockFollowerOpts struct {
+	// LastProcessed is the number of the last block processed, e.g. persisted by a previous run, so the blocks
+	// finalized after it are backfilled before following the finalized heads.
+	LastProcessed *uint64
+	// Start is the Avail block the blocks are followed from without LastProcessed, e.g. the one including
+	// the genesis of the chain for a node joining an existing network. It's checked to exist and be finalized
+	// before following. Without it either, the blocks are followed from the first finalized head received.
+	Start *SyncStart
+	// Persist is called with the number of each processed block, so a restart from it doesn't skip any block.
+	// A block counts as processed once the next one is received from the channel, so the blocks must be processed
+	// in the order they're received. An error persisting the number is logged, and the blocks are still delivered.
+	Persist func(number uint64) error
+}
+
+// BlockFollower follows the finalized blocks of Avail, delivering them strictly in order and without gaps, from
+// the block after the last processed one: the blocks missing from the finalized heads subscription, e.g. the
+// ones finalized while the node was down or disconnected, are fetched and delivered first.
+// With a client created with WithVerifiedFinality, the blocks are only delivered once their finality is proven by
+// a GRANDPA justification, fetched with grandpa_proveFinality, of the block or of a descendant linked to it by the
+// parent hashes, see VerifyFinalizedHeader. A block failing the verification ends the follower with an error
+// wrapping ErrInvalidJustification.
+type BlockFollower struct {
+	c       *client
+	blocks  chan *types.SignedBlock
+	errs    chan error
+	persist func(number uint64) error
+
+	// next is the number of the next block to deliver, once started, and delivered whether a block was delivered.
+	next      uint64
+	started   bool
+	delivered bool
+
+	// verified are the hashes of the blocks whose finality is verified, by number, from the next block to deliver
+	// to the last justified one, and last is the hash of the last delivered block, with verified finality.
+	verified map[uint64]types.Hash
+	last     *types.Hash
+}
+
+// NewBlockFollower starts following the finalized blocks of Avail, see BlockFollower.
+// The finalized heads subscription is re-established following the retry policy of the client when it fails,
+// and fetching a block is retried following it too.
+// It takes a context ending the follower, a client, and the options.
+// It returns the follower, an error if the start block can't be resolved, see ResolveSyncStart, or an error if
+// the finalized heads subscription can't be established.
+func NewBlockFollower(ctx context.Context, client Client, opts BlockFollowerOpts) (*BlockFollower, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var start *uint64
+
+	switch {
+	case opts.LastProcessed != nil:
+		next := *opts.LastProcessed + 1
+		start = &next
+	case opts.Start != nil:
+		n, err := ResolveSyncStart(c, opts.Start)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't resolve the sync start %s: %w", opts.Start, err)
+		}
+
+		start = &n
+	}
+
+	// The subscription ends with the follower.
+	ctx, cancel := context.WithCancel(ctx)
+
+	heads, headErrs, err := SubscribeFinalizedHeads(ctx, c)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	f := &BlockFollower{
+		c:       c,
+		blocks:  make(chan *types.SignedBlock),
+		errs:    make(chan error, 1),
+		persist: opts.Persist,
+	}
+
+	if start != nil {
+		f.next = *start
+		f.started = true
+	}
+
+	go func() {
+		defer cancel()
+		f.follow(ctx, heads, headErrs)
+	}()
+
+	return f, nil
+}
+
+// Chan returns the channel of the finalized blocks, closed once the follower ends.
+func (f *BlockFollower) Chan() <-chan *types.SignedBlock {
+	return f.blocks
+}
+
+// Err returns the channel receiving the error ending the follower, e.g. when the finalized heads subscription
+// couldn't be re-established, closed once the follower ends.
+func (f *BlockFollower) Err() <-chan error {
+	return f.errs
+}
+
+// follow delivers the blocks of the finalized heads, after the ones missing since the last delivered one, until
+// the subscription ends or the context is done.
+func (f *BlockFollower) follow(ctx context.Context, heads <-chan types.Header, headErrs <-chan error) {
+	defer close(f.errs)
+	defer close(f.blocks)
+
+	for header := range heads {
+		if err := f.deliverUntil(ctx, uint64(header.Number)); err != nil {
+			if ctx.Err() == nil {
+				f.errs <- err
+			}
+
+			return
+		}
+	}
+
+	if err, ok := <-headErrs; ok && ctx.Err() == nil {
+		f.errs <- err
+	}
+}
+
+// deliverUntil delivers the blocks from the next one to the given number. The blocks already delivered are skipped.
+func (f *BlockFollower) deliverUntil(ctx context.Context, number uint64) error {
+	if !f.started {
+		f.next = number
+		f.started = true
+	}
+
+	if number > f.next {
+		f.c.logger.Info("backfilling finalized Avail blocks", "from", f.next, "to", number-1)
+	}
+
+	for f.next <= number {
+		if f.c.verifiedFinality && f.verified[f.next] == (types.Hash{}) {
+			if err := f.retry(ctx, "couldn't verify the finality of Avail block", number, func() error { return f.verifyUntil(number) }); err != nil {
+				return err
+			}
+
+			// The node doesn't have a justification for the block yet, it's verified with the next finalized head.
+			if f.verified[f.next] == (types.Hash{}) {
+				f.c.logger.Debug("waiting for the GRANDPA justification of finalized Avail block", "number", f.next)
+				return nil
+			}
+		}
+
+		blk, err := f.fetch(ctx, f.next)
+		if err != nil {
+			return err
+		}
+
+		if f.c.verifiedFinality {
+			if err := f.checkVerified(blk); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case f.blocks <- blk:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		// The previous block was processed once the next one is received.
+		if f.persist != nil && f.delivered {
+			if err := f.persist(f.next - 1); err != nil {
+				f.c.logger.Warn("couldn't persist the last processed Avail block", "number", f.next-1, "error", err)
+			}
+		}
+
+		f.next++
+		f.delivered = true
+	}
+
+	return nil
+}
+
+// fetch fetches the finalized block, retrying following the retry policy of the client.
+func (f *BlockFollower) fetch(ctx context.Context, number uint64) (*types.SignedBlock, error) {
+	var blk *types.SignedBlock
+
+	err := f.retry(ctx, "couldn't fetch finalized Avail block", number, func() (err error) {
+		blk, err = finalizedBlock(f.c.reader(), number)
+		return err
+	})
+
+	return blk, err
+}
+
+// retry calls the function until it succeeds, following the retry policy of the client, and logs the message
+// with the block number on each failure. The failed verifications, wrapping ErrInvalidJustification, aren't retried.
+func (f *BlockFollower) retry(ctx context.Context, message string, number uint64, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= f.c.retry.MaxReconnects || errors.Is(err, ErrInvalidJustification) {
+			return err
+		}
+
+		f.c.logger.Warn(message+", retrying", "number", number, "attempt", attempt+1, "error", err)
+
+		select {
+		case <-time.After(time.Duration(attempt+1) * f.c.retry.Backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// verifyUntil verifies the finality of the blocks from the next one to deliver to the block of the number, or to
+// the first block enacting an authority set change before it, see verify.
+func (f *BlockFollower) verifyUntil(number uint64) error {
+	for {
+		err := f.verify(number)
+
+		var handoff *handoffError
+		if !errors.As(err, &handoff) {
+			return err
+		}
+
+		// The block enacting the change is the last one its authority set justifies.
+		if handoff.number == number {
+			return fmt.Errorf("%w: no justification of block %d enacting an authority set change", ErrInvalidJustification, number)
+		}
+
+		number = handoff.number
+	}
+}
+
+// verify fetches the finality proof of the block of the number, and verifies the finality of the blocks from the
+// next one to deliver to the justified one, see VerifyFinalizedHeader. The headers of the blocks are fetched from
+// the justified one, and checked to be linked by their parent hashes to it, and to the last delivered block.
+func (f *BlockFollower) verify(number uint64) error {
+	api := f.c.reader()
+
+	proof, err := proveFinality(api, number)
+	if err != nil || proof == nil {
+		return err
+	}
+
+	header, err := api.RPC.Chain.GetHeader(proof.Block)
+	if err != nil {
+		return fmt.Errorf("couldn't fetch the header of justified block %s: %w", proof.Block.Hex(), err)
+	}
+
+	justified := uint64(header.Number)
+	if justified < f.next {
+		return nil
+	}
+
+	// The headers are collected from the justified block back to the next one to deliver.
+	headers := make([]*types.Header, justified-f.next+1)
+	hashes := make(map[uint64]types.Hash, len(headers))
+	hash := proof.Block
+
+	for n := justified; ; n-- {
+		if computed, err := headerHash(header); err != nil {
+			return err
+		} else if computed != hash || uint64(header.Number) != n {
+			return fmt.Errorf("%w: header of block %d (%s) doesn't match its hash", ErrInvalidJustification, n, hash.Hex())
+		}
+
+		headers[n-f.next], hashes[n] = header, hash
+
+		if n == f.next {
+			break
+		}
+
+		hash = header.ParentHash
+
+		if header, err = api.RPC.Chain.GetHeader(hash); err != nil {
+			return fmt.Errorf("couldn't fetch the header of block %d (%s): %w", n-1, hash.Hex(), err)
+		}
+	}
+
+	if f.last != nil && headers[0].ParentHash != *f.last {
+		return fmt.Errorf("%w: block %d doesn't descend from the last delivered block %s", ErrInvalidJustification, f.next, f.last.Hex())
+	}
+
+	if err := f.c.verifyFinalizedChain(api, headers, proof.Block, proof.Justification); err != nil {
+		return err
+	}
+
+	f.verified = hashes
+
+	f.c.logger.Debug("verified the finality of Avail blocks", "from", f.next, "to", justified)
+
+	return nil
+}
+
+// checkVerified checks that the block is the one whose finality is verified.
+func (f *BlockFollower) checkVerified(blk *types.SignedBlock) error {
+	number := uint64(blk.Block.Header.Number)
+
+	hash, err := headerHash(&blk.Block.Header)
+	if err != nil {
+		return err
+	}
+
+	if hash != f.verified[number] {
+		return fmt.Errorf("%w: fetched block %d (%s) isn't the verified one %s", ErrInvalidJustification, number, hash.Hex(), f.verified[number].Hex())
+	}
+
+	delete(f.verified, number)
+	f.last = &hash
+
+	return nil
+}
+
+// finalizedBlock fetches the finalized block of the number.
+func finalizedBlock(api *gsrpc.SubstrateAPI, number uint64) (*types.SignedBlock, error) {
+	blockHash, err := api.RPC.Chain.GetBlockHash(number)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch the hash of finalized block %d: %w", number, err)
+	}
+
+	blk, err := api.RPC.Chain.GetBlock(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch finalized block %d: %w", number, err)
+	}
+
+	if uint64(blk.Block.Header.Number) != number {
+		return nil, fmt.Errorf("fetched block %d instead of finalized block %d", blk.Block.Header.Number, number)
+	}
+
+	return blk, nil
+}
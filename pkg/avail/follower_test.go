@@ -0,0 +1,121 @@
+package avail
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveBlocks returns the numbers of the blocks received until the channel is closed.
+func receiveBlocks(t *testing.T, blocks <-chan *types.SignedBlock) []types.BlockNumber {
+	t.Helper()
+
+	var numbers []types.BlockNumber
+
+	for {
+		select {
+		case blk, ok := <-blocks:
+			if !ok {
+				return numbers
+			}
+
+			numbers = append(numbers, blk.Block.Header.Number)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out receiving the finalized blocks")
+		}
+	}
+}
+
+func TestBlockFollowerBackfillsAfterRestart(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, RetryPolicy{}, newFakeHeadsSubscription(errConnectionReset, 6, 7))
+
+	var lock sync.Mutex
+	var persisted []uint64
+
+	lastProcessed := uint64(2)
+
+	f, err := NewBlockFollower(context.Background(), ft.c, BlockFollowerOpts{
+		LastProcessed: &lastProcessed,
+		Persist: func(number uint64) error {
+			lock.Lock()
+			defer lock.Unlock()
+
+			persisted = append(persisted, number)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The blocks finalized since the last processed one are delivered before the live ones.
+	assert.Equal(t, []types.BlockNumber{3, 4, 5, 6, 7}, receiveBlocks(t, f.Chan()))
+	assert.ErrorIs(t, <-f.Err(), errConnectionReset)
+
+	// A block is persisted once the next one is received, so the last one is delivered again after a restart.
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Equal(t, []uint64{3, 4, 5, 6}, persisted)
+}
+
+func TestBlockFollowerFillsGaps(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, testRetryPolicy,
+		newFakeHeadsSubscription(errConnectionReset, 2, 3),
+		newFakeHeadsSubscription(errConnectionReset, 3, 6),
+	)
+
+	f, err := NewBlockFollower(context.Background(), ft.c, BlockFollowerOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The blocks are followed from the first head, and the ones skipped after the reconnection are fetched.
+	assert.Equal(t, []types.BlockNumber{2, 3, 4, 5, 6}, receiveBlocks(t, f.Chan()))
+	assert.ErrorIs(t, <-f.Err(), errSubmitStopped)
+}
+
+func TestBlockFollowerSkipsProcessedBlocks(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, RetryPolicy{}, newFakeHeadsSubscription(nil, 4, 5, 6))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lastProcessed := uint64(5)
+
+	f, err := NewBlockFollower(ctx, ft.c, BlockFollowerOpts{LastProcessed: &lastProcessed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case blk := <-f.Chan():
+		assert.Equal(t, types.BlockNumber(6), blk.Block.Header.Number)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out receiving the finalized blocks")
+	}
+
+	cancel()
+
+	assert.Empty(t, receiveBlocks(t, f.Chan()))
+
+	_, ok := <-f.Err()
+	assert.False(t, ok)
+}
+
+func TestBlockFollowerFetchFailure(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, testRetryPolicy, newFakeHeadsSubscription(nil, 8, 12))
+
+	f, err := NewBlockFollower(context.Background(), ft.c, BlockFollowerOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain only has 10 blocks, so fetching the missing block 10 fails, ending the follower after the retries.
+	assert.Equal(t, []types.BlockNumber{8, 9}, receiveBlocks(t, f.Chan()))
+	assert.Error(t, <-f.Err())
+}
@@ -0,0 +1,473 @@
+package avail
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrInvalidJustification is the error returned when the GRANDPA justification of an Avail header doesn't prove
+// its finality with the authority set, or when a header doesn't belong to the chain of a justified one.
+var ErrInvalidJustification = errors.New("invalid GRANDPA justification")
+
+// grandpaAuthoritiesKey is the well-known storage key of the GRANDPA authorities.
+const grandpaAuthoritiesKey = ":grandpa_authorities"
+
+// grandpaEngineID is the consensus engine ID of the GRANDPA digests, "FRNK".
+var grandpaEngineID = types.ConsensusEngineID(binary.LittleEndian.Uint32([]byte("FRNK")))
+
+// GrandpaAuthority is a GRANDPA voter of an authority set.
+type GrandpaAuthority struct {
+	// Key is the ed25519 public key of the authority.
+	Key [32]byte
+	// Weight is the weight of the votes of the authority.
+	Weight uint64
+}
+
+// AuthoritySet is the set of GRANDPA authorities finalizing the Avail blocks, replaced on the authority set changes
+// signaled in the block digests, e.g. on every new session.
+type AuthoritySet struct {
+	// ID is the number of the set, incremented on every change.
+	ID uint64
+	// Authorities are the voters of the set.
+	Authorities []GrandpaAuthority
+}
+
+// WithVerifiedFinality makes the block followers of the client verify the GRANDPA justification of the finalized
+// blocks before delivering them, see NewBlockFollower and VerifyFinalizedHeader, so no block whose finality isn't
+// proven is delivered.
+func WithVerifiedFinality() ClientOption {
+	return func(c *client) {
+		c.verifiedFinality = true
+	}
+}
+
+// WithTrustedAuthoritySet sets the authority set the finality of the first header verified with
+// VerifyFinalizedHeader is checked against, instead of the one read from the storage of the Avail node, e.g. one
+// checked out of band, so a malicious node can't feed fabricated finality from the first header on.
+func WithTrustedAuthoritySet(set AuthoritySet) ClientOption {
+	return func(c *client) {
+		c.finality.state.set = &set
+	}
+}
+
+// GetAuthoritySet retrieves the GRANDPA authority set from the storage of the Avail block: the authorities of the
+// GRANDPA pallet, and the ID of their set.
+// It takes a client and the block hash, and returns the authority set and an error if there is an issue.
+func GetAuthoritySet(client Client, blockHash types.Hash) (*AuthoritySet, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.authoritySet(c.instance(), blockHash)
+}
+
+// authoritySet retrieves the GRANDPA authority set from the storage of the block.
+func (c *client) authoritySet(api *gsrpc.SubstrateAPI, blockHash types.Hash) (*AuthoritySet, error) {
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	setIDKey, err := types.CreateStorageKey(meta, "Grandpa", "CurrentSetId")
+	if err != nil {
+		return nil, err
+	}
+
+	var setID types.U64
+	if _, err := api.RPC.State.GetStorage(setIDKey, &setID, blockHash); err != nil {
+		return nil, fmt.Errorf("couldn't fetch the GRANDPA set ID of block %s: %w", blockHash.Hex(), err)
+	}
+
+	raw, err := api.RPC.State.GetStorageRaw(types.NewStorageKey([]byte(grandpaAuthoritiesKey)), blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch the GRANDPA authorities of block %s: %w", blockHash.Hex(), err)
+	}
+
+	if raw == nil || len(*raw) == 0 {
+		return nil, fmt.Errorf("no GRANDPA authorities in the storage of block %s", blockHash.Hex())
+	}
+
+	// The authorities are versioned, version 1 being the only one.
+	var list struct {
+		Version     types.U8
+		Authorities []grandpaAuthority
+	}
+
+	if err := codec.Decode(*raw, &list); err != nil {
+		return nil, fmt.Errorf("couldn't decode the GRANDPA authorities of block %s: %w", blockHash.Hex(), err)
+	}
+
+	if list.Version != 1 {
+		return nil, fmt.Errorf("unsupported version %d of the GRANDPA authorities of block %s", list.Version, blockHash.Hex())
+	}
+
+	return &AuthoritySet{ID: uint64(setID), Authorities: authorities(list.Authorities)}, nil
+}
+
+// VerifyFinalizedHeader verifies the GRANDPA justification of the Avail header, i.e. that the authority set
+// finalizing it signed precommits for it, or for its descendants included in the justification, carrying more than
+// two thirds of the weight of the set. The authority set is tracked by the client: it's read from the storage of
+// the parent of the first header verified, unless set with WithTrustedAuthoritySet, and then replaced on the changes
+// signaled in the digests of the verified headers. So the headers must be verified in order, including the ones
+// signaling a change: with the changes enacted right away, as on Avail, GRANDPA always justifies them.
+// It takes a client, the header and its SCALE encoded justification, e.g. of grandpa_proveFinality.
+// It returns an error wrapping ErrInvalidJustification if the justification doesn't prove the finality of
+// the header, or an error if there is an issue.
+func VerifyFinalizedHeader(client Client, header *types.Header, justification []byte) error {
+	c, err := implementation(client)
+	if err != nil {
+		return err
+	}
+
+	hash, err := headerHash(header)
+	if err != nil {
+		return err
+	}
+
+	return c.verifyFinalizedChain(c.instance(), []*types.Header{header}, hash, justification)
+}
+
+// handoffError is the error returned when an authority set change signaled by a header of a chain is enacted
+// before its justified header: the block enacting it must be verified first, with its own justification, as
+// the next authority set can't be trusted from a header that isn't verified yet.
+type handoffError struct {
+	number uint64
+}
+
+func (e *handoffError) Error() string {
+	return fmt.Sprintf("authority set change enacted by block %d must be verified first", e.number)
+}
+
+// verifyFinalizedChain verifies the justification of the last of the headers, of the hash, following the authority
+// set changes signaled by the previous ones first. The headers must be in order, and linked by their parent hashes.
+// It returns a *handoffError if a change signaled by the previous headers is enacted before the last one.
+// The tracked authority set isn't changed unless the justification is verified.
+func (c *client) verifyFinalizedChain(api *gsrpc.SubstrateAPI, headers []*types.Header, hash types.Hash, justification []byte) error {
+	c.finality.lock.Lock()
+	defer c.finality.lock.Unlock()
+
+	if err := c.bootstrapAuthoritySet(api, headers[0].ParentHash); err != nil {
+		return err
+	}
+
+	state := c.finality.state
+	last := headers[len(headers)-1]
+
+	for _, header := range headers[:len(headers)-1] {
+		state.setFinalizing(uint64(header.Number))
+
+		if err := state.follow(header); err != nil {
+			return err
+		}
+
+		if state.pending != nil && state.pending.enactedAt < uint64(last.Number) {
+			return &handoffError{number: state.pending.enactedAt}
+		}
+	}
+
+	var j grandpaJustification
+	if err := codec.Decode(justification, &j); err != nil {
+		return fmt.Errorf("%w: couldn't decode the justification of block %d: %s", ErrInvalidJustification, last.Number, err)
+	}
+
+	if err := j.verify(state.setFinalizing(uint64(last.Number)), hash, uint64(last.Number)); err != nil {
+		return err
+	}
+
+	if err := state.follow(last); err != nil {
+		return err
+	}
+
+	c.finality.state = state
+
+	return nil
+}
+
+// bootstrapAuthoritySet reads the authority set from the storage of the block, unless it's already tracked.
+// The finality lock must be held.
+func (c *client) bootstrapAuthoritySet(api *gsrpc.SubstrateAPI, blockHash types.Hash) error {
+	if c.finality.state.set != nil {
+		return nil
+	}
+
+	set, err := c.authoritySet(api, blockHash)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("tracking the GRANDPA authority set read from the Avail node", "set_id", set.ID, "authorities", len(set.Authorities), "block", blockHash.Hex())
+
+	c.finality.state.set = set
+
+	return nil
+}
+
+// finalityTracker tracks the authority set finalizing the Avail blocks, following the changes signaled in the
+// digests of the verified headers.
+type finalityTracker struct {
+	lock  sync.Mutex
+	state finalityState
+}
+
+// finalityState is the authority set finalizing the next blocks, and the change signaled and not enacted yet, if any.
+// The authority sets and changes are never modified, so a copy of the state can be advanced independently.
+type finalityState struct {
+	set     *AuthoritySet
+	pending *authoritySetChange
+}
+
+// authoritySetChange is an authority set change signaled in a block digest, enacted once the block of the number
+// is finalized: the blocks after it are finalized by the next authorities.
+type authoritySetChange struct {
+	enactedAt   uint64
+	authorities []GrandpaAuthority
+}
+
+// setFinalizing returns the authority set finalizing the block of the number, enacting the pending change if the
+// block is after it.
+func (t *finalityState) setFinalizing(number uint64) *AuthoritySet {
+	if t.pending != nil && number > t.pending.enactedAt {
+		t.set = &AuthoritySet{ID: t.set.ID + 1, Authorities: t.pending.authorities}
+		t.pending = nil
+	}
+
+	return t.set
+}
+
+// follow records the authority set change signaled in the digest of the finalized header, if any.
+func (t *finalityState) follow(header *types.Header) error {
+	for _, item := range header.Digest {
+		if !item.IsConsensus || item.AsConsensus.ConsensusEngineID != grandpaEngineID {
+			continue
+		}
+
+		change, err := decodeAuthoritySetChange(item.AsConsensus.Bytes)
+		if err != nil {
+			return fmt.Errorf("couldn't decode the GRANDPA digest of block %d: %w", header.Number, err)
+		}
+
+		if change != nil {
+			change.enactedAt += uint64(header.Number)
+			t.pending = change
+		}
+	}
+
+	return nil
+}
+
+// decodeAuthoritySetChange decodes the GRANDPA consensus log of a digest, and returns the scheduled or forced
+// authority set change, with the delay it's enacted after, or nil for the other logs.
+func decodeAuthoritySetChange(log []byte) (*authoritySetChange, error) {
+	if len(log) == 0 {
+		return nil, errors.New("empty consensus log")
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(log[1:]))
+
+	switch log[0] {
+	case 1: // ScheduledChange
+	case 2: // ForcedChange, with the median last finalized block first.
+		var median types.U32
+		if err := decoder.Decode(&median); err != nil {
+			return nil, err
+		}
+	default: // OnDisabled, Pause and Resume don't change the set.
+		return nil, nil
+	}
+
+	var change struct {
+		NextAuthorities []grandpaAuthority
+		Delay           types.U32
+	}
+
+	if err := decoder.Decode(&change); err != nil {
+		return nil, err
+	}
+
+	return &authoritySetChange{enactedAt: uint64(change.Delay), authorities: authorities(change.NextAuthorities)}, nil
+}
+
+// grandpaAuthority is the SCALE encoding of a GRANDPA authority and its weight.
+type grandpaAuthority struct {
+	Key    [32]byte
+	Weight types.U64
+}
+
+// authorities returns the authorities of their SCALE encoding.
+func authorities(encoded []grandpaAuthority) []GrandpaAuthority {
+	list := make([]GrandpaAuthority, len(encoded))
+	for i, a := range encoded {
+		list[i] = GrandpaAuthority{Key: a.Key, Weight: uint64(a.Weight)}
+	}
+
+	return list
+}
+
+// grandpaJustification is the SCALE encoding of a GRANDPA justification: the commit of the round finalizing its
+// target block, and the headers from the targets of the precommits to it.
+type grandpaJustification struct {
+	Round           types.U64
+	Commit          grandpaCommit
+	VotesAncestries []types.Header
+}
+
+type grandpaCommit struct {
+	TargetHash   types.Hash
+	TargetNumber types.U32
+	Precommits   []grandpaSignedPrecommit
+}
+
+type grandpaSignedPrecommit struct {
+	Precommit grandpaPrecommit
+	Signature [64]byte
+	ID        [32]byte
+}
+
+type grandpaPrecommit struct {
+	TargetHash   types.Hash
+	TargetNumber types.U32
+}
+
+// grandpaPrecommitMessage is the index of the precommits in the enum of the GRANDPA messages.
+const grandpaPrecommitMessage = 1
+
+// verify checks that the justification finalizes the block of the hash and the number, with the authority set.
+func (j *grandpaJustification) verify(set *AuthoritySet, hash types.Hash, number uint64) error {
+	if j.Commit.TargetHash != hash || uint64(j.Commit.TargetNumber) != number {
+		return fmt.Errorf("%w: justification of block %d (%s) for block %d (%s)", ErrInvalidJustification, j.Commit.TargetNumber, j.Commit.TargetHash.Hex(), number, hash.Hex())
+	}
+
+	weights := make(map[[32]byte]uint64, len(set.Authorities))
+
+	var total uint64
+	for _, a := range set.Authorities {
+		weights[a.Key] += a.Weight
+		total += a.Weight
+	}
+
+	ancestries := make(map[types.Hash]*types.Header, len(j.VotesAncestries))
+	for i := range j.VotesAncestries {
+		h, err := headerHash(&j.VotesAncestries[i])
+		if err != nil {
+			return err
+		}
+
+		ancestries[h] = &j.VotesAncestries[i]
+	}
+
+	voted := make(map[[32]byte]bool, len(j.Commit.Precommits))
+
+	var weight uint64
+
+	for i, p := range j.Commit.Precommits {
+		w, ok := weights[p.ID]
+		if !ok {
+			return fmt.Errorf("%w: precommit %d of block %d by %#x, not in authority set %d", ErrInvalidJustification, i, number, p.ID, set.ID)
+		}
+
+		if !ed25519.Verify(p.ID[:], precommitPayload(p.Precommit, uint64(j.Round), set.ID), p.Signature[:]) {
+			return fmt.Errorf("%w: invalid signature of precommit %d of block %d by %#x in authority set %d", ErrInvalidJustification, i, number, p.ID, set.ID)
+		}
+
+		if !descends(ancestries, p.Precommit.TargetHash, hash) {
+			return fmt.Errorf("%w: precommit %d for block %s, not a descendant of block %d", ErrInvalidJustification, i, p.Precommit.TargetHash.Hex(), number)
+		}
+
+		// The equivocations of an authority count once.
+		if !voted[p.ID] {
+			voted[p.ID] = true
+			weight += w
+		}
+	}
+
+	// More than two thirds of the weight is needed, tolerating a third of faulty authorities.
+	if threshold := total - (total-1)/3; total == 0 || weight < threshold {
+		return fmt.Errorf("%w: precommits of block %d weigh %d out of %d, %d needed", ErrInvalidJustification, number, weight, total, threshold)
+	}
+
+	return nil
+}
+
+// precommitPayload returns the payload signed by the authorities for the precommit of the round in the set: the
+// SCALE encoding of the precommit message, the round and the set ID.
+func precommitPayload(p grandpaPrecommit, round, setID uint64) []byte {
+	payload := make([]byte, 0, 1+32+4+8+8)
+	payload = append(payload, grandpaPrecommitMessage)
+	payload = append(payload, p.TargetHash[:]...)
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(p.TargetNumber))
+	payload = binary.LittleEndian.AppendUint64(payload, round)
+
+	return binary.LittleEndian.AppendUint64(payload, setID)
+}
+
+// descends checks whether the block of the hash is the ancestor block, or descends from it through the headers.
+func descends(headers map[types.Hash]*types.Header, hash, ancestor types.Hash) bool {
+	for seen := 0; seen <= len(headers); seen++ {
+		if hash == ancestor {
+			return true
+		}
+
+		header, ok := headers[hash]
+		if !ok {
+			return false
+		}
+
+		hash = header.ParentHash
+	}
+
+	return false
+}
+
+// headerHash returns the hash of the header, the BLAKE2b-256 hash of its SCALE encoding.
+func headerHash(header *types.Header) (types.Hash, error) {
+	encoded, err := codec.Encode(*header)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	return types.Hash(blake2b.Sum256(encoded)), nil
+}
+
+// finalityProof is the SCALE encoding of a GRANDPA finality proof: the justified block, which is the block proven
+// or a descendant, and its justification. The headers between the block proven and the justified one, which follow
+// them, aren't decoded, they're fetched and checked instead.
+type finalityProof struct {
+	Block         types.Hash
+	Justification types.Bytes
+}
+
+// proveFinality fetches the finality proof of the finalized block of the number, with grandpa_proveFinality.
+// It returns nil if the node doesn't have a justification proving it yet.
+func proveFinality(api *gsrpc.SubstrateAPI, number uint64) (*finalityProof, error) {
+	var encoded string
+	if err := api.Client.Call(&encoded, "grandpa_proveFinality", number); err != nil {
+		return nil, fmt.Errorf("couldn't fetch the finality proof of block %d: %w", number, err)
+	}
+
+	if encoded == "" {
+		return nil, nil
+	}
+
+	raw, err := codec.HexDecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode the finality proof of block %d: %w", number, err)
+	}
+
+	var proof finalityProof
+	if err := codec.Decode(raw, &proof); err != nil {
+		return nil, fmt.Errorf("couldn't decode the finality proof of block %d: %w", number, err)
+	}
+
+	return &proof, nil
+}
@@ -0,0 +1,448 @@
+package avail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/state"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// grandpaFixture are the recorded Avail blocks finalized across GRANDPA authority set handoffs, and their
+// justifications, see testdata/grandpa_handoffs.json.
+type grandpaFixture struct {
+	set            AuthoritySet
+	headers        []types.Header
+	hashes         []types.Hash
+	justifications map[uint64][]byte
+	forged         map[uint64][]byte
+}
+
+func loadGrandpaFixture(t *testing.T) *grandpaFixture {
+	data, err := os.ReadFile("testdata/grandpa_handoffs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var recorded struct {
+		SetID       uint64 `json:"setId"`
+		Authorities []struct {
+			Key    string `json:"key"`
+			Weight uint64 `json:"weight"`
+		} `json:"authorities"`
+		Blocks []struct {
+			Header        string `json:"header"`
+			Justification string `json:"justification"`
+		} `json:"blocks"`
+		Forged map[string]string `json:"forgedJustifications"`
+	}
+
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	fx := &grandpaFixture{
+		set:            AuthoritySet{ID: recorded.SetID},
+		justifications: make(map[uint64][]byte),
+		forged:         make(map[uint64][]byte),
+	}
+
+	for _, a := range recorded.Authorities {
+		key, err := codec.HexDecodeString(a.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		authority := GrandpaAuthority{Weight: a.Weight}
+		copy(authority.Key[:], key)
+		fx.set.Authorities = append(fx.set.Authorities, authority)
+	}
+
+	for n, b := range recorded.Blocks {
+		var header types.Header
+		if err := codec.DecodeFromHex(b.Header, &header); err != nil {
+			t.Fatal(err)
+		}
+
+		hash, err := headerHash(&header)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fx.headers = append(fx.headers, header)
+		fx.hashes = append(fx.hashes, hash)
+
+		if b.Justification != "" {
+			if fx.justifications[uint64(n)], err = codec.HexDecodeString(b.Justification); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for n, forged := range recorded.Forged {
+		number, err := strconv.ParseUint(n, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fx.forged[number], err = codec.HexDecodeString(forged); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return fx
+}
+
+// grandpaNode serves the blocks of the fixture, the GRANDPA storage of the first authority set in the blocks before
+// its first change, and the finality proofs of grandpa_proveFinality: the justification of the first justified block
+// from the one requested.
+type grandpaNode struct {
+	fx   *grandpaFixture
+	meta *types.Metadata
+
+	// headers replace the headers of the fixture served by hash, and justifications the justifications served.
+	headers        map[types.Hash]types.Header
+	justifications map[uint64][]byte
+}
+
+func (n *grandpaNode) header(hash types.Hash) (*types.Header, error) {
+	if header, ok := n.headers[hash]; ok {
+		return &header, nil
+	}
+
+	for i := range n.fx.hashes {
+		if n.fx.hashes[i] == hash {
+			return &n.fx.headers[i], nil
+		}
+	}
+
+	return nil, errors.New("block not found")
+}
+
+type grandpaChain struct {
+	chain.Chain
+
+	n *grandpaNode
+}
+
+func (c *grandpaChain) GetBlockHash(number uint64) (types.Hash, error) {
+	if number >= uint64(len(c.n.fx.hashes)) {
+		return types.Hash{}, errors.New("block not found")
+	}
+
+	return c.n.fx.hashes[number], nil
+}
+
+func (c *grandpaChain) GetHeader(hash types.Hash) (*types.Header, error) {
+	return c.n.header(hash)
+}
+
+func (c *grandpaChain) GetBlock(hash types.Hash) (*types.SignedBlock, error) {
+	header, err := c.n.header(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SignedBlock{Block: types.Block{Header: *header}}, nil
+}
+
+type grandpaState struct {
+	state.State
+
+	n *grandpaNode
+}
+
+// storedSet checks that the storage of the block holds the first authority set.
+func (s *grandpaState) storedSet(blockHash types.Hash) error {
+	for _, hash := range s.n.fx.hashes[:3] {
+		if hash == blockHash {
+			return nil
+		}
+	}
+
+	return errors.New("state not found")
+}
+
+func (s *grandpaState) GetMetadataLatest() (*types.Metadata, error) {
+	return s.n.meta, nil
+}
+
+func (s *grandpaState) GetRuntimeVersionLatest() (*types.RuntimeVersion, error) {
+	return &types.RuntimeVersion{SpecVersion: 1, TransactionVersion: 1}, nil
+}
+
+func (s *grandpaState) GetStorage(_ types.StorageKey, target interface{}, blockHash types.Hash) (bool, error) {
+	if err := s.storedSet(blockHash); err != nil {
+		return false, err
+	}
+
+	*target.(*types.U64) = types.U64(s.n.fx.set.ID)
+
+	return true, nil
+}
+
+func (s *grandpaState) GetStorageRaw(key types.StorageKey, blockHash types.Hash) (*types.StorageDataRaw, error) {
+	if err := s.storedSet(blockHash); err != nil {
+		return nil, err
+	}
+
+	if string(key) != grandpaAuthoritiesKey {
+		return nil, errors.New("storage not found")
+	}
+
+	list := []byte{1}
+
+	encoded, err := codec.Encode(s.n.fx.set.Authorities)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := types.StorageDataRaw(append(list, encoded...))
+
+	return &raw, nil
+}
+
+type grandpaRPCClient struct {
+	gsrpcclient.Client
+
+	n *grandpaNode
+}
+
+func (c *grandpaRPCClient) Call(result interface{}, method string, args ...interface{}) error {
+	if method != "grandpa_proveFinality" {
+		return errors.New("method not found")
+	}
+
+	for n := args[0].(uint64); n < uint64(len(c.n.fx.hashes)); n++ {
+		justification, ok := c.n.justifications[n]
+		if !ok {
+			continue
+		}
+
+		encoded, err := codec.EncodeToHex(finalityProof{Block: c.n.fx.hashes[n], Justification: justification})
+		if err != nil {
+			return err
+		}
+
+		*result.(*string) = encoded
+
+		return nil
+	}
+
+	return nil
+}
+
+// newGrandpaClient returns a client of a node serving the fixture.
+func newGrandpaClient(t *testing.T, fx *grandpaFixture, opts ...ClientOption) (*client, *grandpaNode) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	node := &grandpaNode{fx: fx, meta: &meta, headers: make(map[types.Hash]types.Header), justifications: make(map[uint64][]byte)}
+	for n, justification := range fx.justifications {
+		node.justifications[n] = justification
+	}
+
+	dial := func(c *client) {
+		c.dial = func(string) (*gsrpc.SubstrateAPI, error) {
+			return &gsrpc.SubstrateAPI{
+				RPC:    &rpc.RPC{Chain: &grandpaChain{n: node}, State: &grandpaState{n: node}},
+				Client: &grandpaRPCClient{n: node},
+			}, nil
+		}
+	}
+
+	c, err := newClient([]string{"mock"}, hclog.NewNullLogger(), append([]ClientOption{dial}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, node
+}
+
+func TestGetAuthoritySet(t *testing.T) {
+	fx := loadGrandpaFixture(t)
+	c, _ := newGrandpaClient(t, fx)
+
+	set, err := GetAuthoritySet(c, fx.hashes[0])
+	if assert.NoError(t, err) {
+		assert.Equal(t, &fx.set, set)
+	}
+}
+
+func TestVerifyFinalizedHeaderFollowsHandoffs(t *testing.T) {
+	fx := loadGrandpaFixture(t)
+	c, _ := newGrandpaClient(t, fx)
+
+	// Block 3 hands off to set 5, whose first justified block is 5.
+	for _, n := range []uint64{2, 3, 5} {
+		assert.NoError(t, VerifyFinalizedHeader(c, &fx.headers[n], fx.justifications[n]), n)
+	}
+
+	assert.Equal(t, uint64(5), c.finality.state.set.ID)
+
+	// The previous set can't finalize blocks after its handoff.
+	c, _ = newGrandpaClient(t, fx)
+
+	for _, n := range []uint64{2, 3} {
+		assert.NoError(t, VerifyFinalizedHeader(c, &fx.headers[n], fx.justifications[n]), n)
+	}
+
+	assert.ErrorIs(t, VerifyFinalizedHeader(c, &fx.headers[5], fx.forged[5]), ErrInvalidJustification)
+
+	// Without the handoff, the next set can't finalize blocks either.
+	c, _ = newGrandpaClient(t, fx, WithTrustedAuthoritySet(fx.set))
+
+	assert.NoError(t, VerifyFinalizedHeader(c, &fx.headers[2], fx.justifications[2]))
+	assert.ErrorIs(t, VerifyFinalizedHeader(c, &fx.headers[5], fx.justifications[5]), ErrInvalidJustification)
+}
+
+func TestVerifyFinalizedHeaderInvalid(t *testing.T) {
+	fx := loadGrandpaFixture(t)
+
+	tamper := func(tamper func(j *grandpaJustification)) []byte {
+		var tampered grandpaJustification
+		if err := codec.Decode(fx.justifications[3], &tampered); err != nil {
+			t.Fatal(err)
+		}
+
+		tamper(&tampered)
+
+		encoded, err := codec.Encode(tampered)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return encoded
+	}
+
+	for name, justification := range map[string][]byte{
+		"other block":  fx.justifications[2],
+		"signature":    tamper(func(j *grandpaJustification) { j.Commit.Precommits[0].Signature[0] ^= 1 }),
+		"round":        tamper(func(j *grandpaJustification) { j.Round++ }),
+		"below quorum": tamper(func(j *grandpaJustification) { j.Commit.Precommits = j.Commit.Precommits[:2] }),
+		"equivocation": tamper(func(j *grandpaJustification) {
+			j.Commit.Precommits = append(j.Commit.Precommits[:2:2], j.Commit.Precommits[1])
+		}),
+		"unknown voter": tamper(func(j *grandpaJustification) { j.Commit.Precommits[0].ID[0] ^= 1 }),
+		"truncated":     fx.justifications[3][:20],
+	} {
+		c, _ := newGrandpaClient(t, fx)
+
+		assert.NoError(t, VerifyFinalizedHeader(c, &fx.headers[2], fx.justifications[2]), name)
+		assert.ErrorIs(t, VerifyFinalizedHeader(c, &fx.headers[3], justification), ErrInvalidJustification, name)
+
+		// The authority set isn't changed by a failed verification.
+		assert.Equal(t, uint64(4), c.finality.state.set.ID, name)
+		assert.Nil(t, c.finality.state.pending, name)
+	}
+
+	// A precommit for a descendant of the block needs the headers linking them.
+	var j2 grandpaJustification
+	if err := codec.Decode(fx.justifications[2], &j2); err != nil {
+		t.Fatal(err)
+	}
+
+	j2.VotesAncestries = nil
+
+	withoutAncestries, err := codec.Encode(j2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := newGrandpaClient(t, fx)
+	assert.ErrorIs(t, VerifyFinalizedHeader(c, &fx.headers[2], withoutAncestries), ErrInvalidJustification)
+}
+
+func TestBlockFollowerVerifiesFinality(t *testing.T) {
+	fx := loadGrandpaFixture(t)
+	c, _ := newGrandpaClient(t, fx, WithVerifiedFinality(), WithRetryPolicy(RetryPolicy{}))
+
+	c.subscribeFinalizedHeads = func(*gsrpc.SubstrateAPI) (headsSubscription, error) {
+		return newFakeHeadsSubscription(errConnectionReset, 8), nil
+	}
+
+	lastProcessed := uint64(0)
+
+	f, err := NewBlockFollower(context.Background(), c, BlockFollowerOpts{LastProcessed: &lastProcessed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The blocks are verified across both handoffs, the second one enacted after block 7.
+	assert.Equal(t, []types.BlockNumber{1, 2, 3, 4, 5, 6, 7, 8}, receiveBlocks(t, f.Chan()))
+	assert.ErrorIs(t, <-f.Err(), errConnectionReset)
+	assert.Equal(t, uint64(6), c.finality.state.set.ID)
+}
+
+func TestBlockFollowerRefusesUnverifiedBlocks(t *testing.T) {
+	fx := loadGrandpaFixture(t)
+
+	follow := func(t *testing.T, c *client) ([]types.BlockNumber, error) {
+		c.subscribeFinalizedHeads = func(*gsrpc.SubstrateAPI) (headsSubscription, error) {
+			return newFakeHeadsSubscription(errConnectionReset, 8), nil
+		}
+
+		lastProcessed := uint64(0)
+
+		f, err := NewBlockFollower(context.Background(), c, BlockFollowerOpts{LastProcessed: &lastProcessed})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return receiveBlocks(t, f.Chan()), <-f.Err()
+	}
+
+	t.Run("forged justification after handoff", func(t *testing.T) {
+		c, node := newGrandpaClient(t, fx, WithVerifiedFinality(), WithRetryPolicy(RetryPolicy{}))
+		node.justifications[8] = fx.forged[8]
+
+		blocks, err := follow(t, c)
+		assert.Equal(t, []types.BlockNumber{1, 2, 3, 4, 5, 6, 7}, blocks)
+		assert.ErrorIs(t, err, ErrInvalidJustification)
+	})
+
+	t.Run("fabricated header", func(t *testing.T) {
+		c, node := newGrandpaClient(t, fx, WithVerifiedFinality())
+
+		fabricated := fx.headers[1]
+		fabricated.StateRoot[0] ^= 1
+		node.headers[fx.hashes[1]] = fabricated
+
+		blocks, err := follow(t, c)
+		assert.Empty(t, blocks)
+		assert.ErrorIs(t, err, ErrInvalidJustification)
+	})
+
+	t.Run("missing handoff justification", func(t *testing.T) {
+		c, node := newGrandpaClient(t, fx, WithVerifiedFinality(), WithRetryPolicy(RetryPolicy{}))
+		delete(node.justifications, 7)
+
+		// The blocks are delivered up to the first handoff, the second one can't be verified.
+		blocks, err := follow(t, c)
+		assert.Equal(t, []types.BlockNumber{1, 2, 3}, blocks)
+		assert.ErrorIs(t, err, ErrInvalidJustification)
+	})
+
+	t.Run("no justification yet", func(t *testing.T) {
+		c, node := newGrandpaClient(t, fx, WithVerifiedFinality(), WithRetryPolicy(RetryPolicy{}))
+		delete(node.justifications, 8)
+
+		// The blocks wait for a justification of the finalized head, or of a descendant.
+		blocks, err := follow(t, c)
+		assert.Empty(t, blocks)
+		assert.ErrorIs(t, err, errConnectionReset)
+	})
+}
@@ -0,0 +1,313 @@
+package avail
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+)
+
+const (
+	// kateProofSize is the size of a cell in the kate_queryProof response: the 48 bytes compressed
+	// G1 proof, followed by the 32 bytes little-endian scalar content of the cell.
+	kateProofSize = bls.SizeOfG1AffineCompressed + fr.Bytes
+
+	// kateExtensionFactor is the factor the rows of the Avail data matrix are erasure coded with.
+	kateExtensionFactor = 2
+)
+
+// Cell is a cell of the extended data matrix of an Avail block.
+type Cell struct {
+	Row uint32 `json:"row"`
+	Col uint32 `json:"col"`
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("(%d,%d)", c.Row, c.Col)
+}
+
+// CellVerificationError is the error returned by VerifyDataAvailability when too few of the sampled cells were verified.
+type CellVerificationError struct {
+	// Failed are the sampled cells whose proof didn't verify.
+	Failed []Cell
+	// Sampled is the number of sampled cells.
+	Sampled int
+}
+
+func (e *CellVerificationError) Error() string {
+	cells := make([]string, len(e.Failed))
+	for i, cell := range e.Failed {
+		cells[i] = cell.String()
+	}
+
+	return fmt.Sprintf("%d of %d sampled cells failed verification: %s", len(e.Failed), e.Sampled, strings.Join(cells, ", "))
+}
+
+// DataAvailabilityOpts are the options of VerifyDataAvailability.
+type DataAvailabilityOpts struct {
+	// TauG2 is the compressed [τ]G2 point of the trusted setup of the Avail network, the commitments are opened with.
+	TauG2 []byte
+	// Threshold is the fraction of the sampled cells that must be verified for the data to be available.
+	// Zero requires all of them.
+	Threshold float64
+	// Seed seeds the sampling of the cells, for reproducible samples. Zero samples randomly.
+	Seed int64
+}
+
+// VerifyDataAvailability samples random cells of the extended data matrix of the Avail block, queries their
+// proofs with kate_queryProof, and verifies them against the KZG commitments of the block header, so the
+// availability of the data doesn't rely on the node queried.
+// It takes a client, the block hash, the number of cells to sample, and the verification options.
+// It returns whether the data is available, along with a *CellVerificationError listing the cells that failed
+// if it isn't, or an error if there is an issue.
+func VerifyDataAvailability(client Client, blockHash types.Hash, samples int, opts DataAvailabilityOpts) (bool, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return false, err
+	}
+
+	if samples <= 0 {
+		return false, errors.New("no cells to sample")
+	}
+
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = 1
+	} else if threshold < 0 || threshold > 1 {
+		return false, fmt.Errorf("invalid threshold %v, must be between 0 and 1", threshold)
+	}
+
+	var tauG2 bls.G2Affine
+	if _, err := tauG2.SetBytes(opts.TauG2); err != nil {
+		return false, fmt.Errorf("invalid trusted setup [τ]G2 point: %w", err)
+	}
+
+	api := c.instance()
+
+	var header kateHeader
+	if err := api.Client.Call(&header, "chain_getHeader", blockHash.Hex()); err != nil {
+		return false, fmt.Errorf("couldn't fetch the header of block %s: %w", blockHash.Hex(), err)
+	}
+
+	commitment := header.Extension.V1.Commitment
+
+	commitments, err := commitment.rowCommitments()
+	if err != nil {
+		return false, fmt.Errorf("invalid commitment of block %s: %w", blockHash.Hex(), err)
+	}
+
+	cells := sampleCells(uint32(len(commitments)), commitment.Cols, samples, opts.Seed)
+
+	var proofs kateBytes
+	if err := api.Client.Call(&proofs, "kate_queryProof", cells, blockHash.Hex()); err != nil {
+		return false, fmt.Errorf("couldn't query the cell proofs of block %s: %w", blockHash.Hex(), err)
+	}
+
+	if len(proofs) != len(cells)*kateProofSize {
+		return false, fmt.Errorf("invalid kate_queryProof response of %d bytes for %d cells", len(proofs), len(cells))
+	}
+
+	domain := fft.NewDomain(uint64(commitment.Cols), 0, false)
+
+	var failed []Cell
+
+	for i, cell := range cells {
+		err := verifyCellProof(&commitments[cell.Row], &tauG2, domain, cell.Col, proofs[i*kateProofSize:(i+1)*kateProofSize])
+		if err != nil {
+			c.logger.Debug("cell proof verification failed", "block_hash", blockHash.Hex(), "cell", cell, "error", err)
+			failed = append(failed, cell)
+		}
+	}
+
+	verified := len(cells) - len(failed)
+	if float64(verified) < threshold*float64(len(cells)) {
+		return false, &CellVerificationError{Failed: failed, Sampled: len(cells)}
+	}
+
+	if len(failed) > 0 {
+		c.logger.Warn("some sampled cell proofs failed verification", "block_hash", blockHash.Hex(), "failed", len(failed), "sampled", len(cells))
+	}
+
+	return true, nil
+}
+
+// verifyCellProof verifies the KZG proof of the content of the cell at the column, i.e. the evaluation of the row
+// polynomial at the column point of the domain: e(C - [y]G1, G2) = e(π, [τ]G2 - [x]G2).
+func verifyCellProof(commitment *bls.G1Affine, tauG2 *bls.G2Affine, domain *fft.Domain, col uint32, cellProof []byte) error {
+	var proof bls.G1Affine
+	if _, err := proof.SetBytes(cellProof[:bls.SizeOfG1AffineCompressed]); err != nil {
+		return fmt.Errorf("invalid proof: %w", err)
+	}
+
+	content, err := scalarFromLE(cellProof[bls.SizeOfG1AffineCompressed:])
+	if err != nil {
+		return err
+	}
+
+	var x fr.Element
+	x.Exp(domain.Generator, big.NewInt(int64(col)))
+
+	_, _, g1, g2 := bls.Generators()
+
+	var y, lhs bls.G1Affine
+	y.ScalarMultiplication(&g1, content)
+	lhs.Sub(commitment, &y)
+
+	var xG2, rhs, negG2 bls.G2Affine
+	xG2.ScalarMultiplication(&g2, x.ToBigIntRegular(new(big.Int)))
+	rhs.Sub(tauG2, &xG2)
+	negG2.Neg(&g2)
+
+	ok, err := bls.PairingCheck([]bls.G1Affine{lhs, proof}, []bls.G2Affine{negG2, rhs})
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("invalid proof")
+	}
+
+	return nil
+}
+
+// scalarFromLE decodes the canonical little-endian encoding of a scalar.
+func scalarFromLE(b []byte) (*big.Int, error) {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+
+	s := new(big.Int).SetBytes(be)
+	if s.Cmp(fr.Modulus()) >= 0 {
+		return nil, errors.New("non canonical cell content")
+	}
+
+	return s, nil
+}
+
+// sampleCells returns distinct random cells of the matrix, all of them if there are fewer than the samples.
+func sampleCells(rows, cols uint32, samples int, seed int64) []Cell {
+	total := int(rows) * int(cols)
+	if samples >= total {
+		cells := make([]Cell, 0, total)
+		for row := uint32(0); row < rows; row++ {
+			for col := uint32(0); col < cols; col++ {
+				cells = append(cells, Cell{Row: row, Col: col})
+			}
+		}
+
+		return cells
+	}
+
+	if seed == 0 {
+		var b [8]byte
+		if _, err := crand.Read(b[:]); err == nil {
+			seed = int64(binary.LittleEndian.Uint64(b[:]))
+		}
+	}
+
+	//nolint:gosec // The sampling only has to be unpredictable to the node, and reproducible with a seed.
+	rng := rand.New(rand.NewSource(seed))
+
+	sampled := make(map[int]bool, samples)
+	cells := make([]Cell, 0, samples)
+
+	for len(cells) < samples {
+		i := rng.Intn(total)
+		if sampled[i] {
+			continue
+		}
+
+		sampled[i] = true
+		cells = append(cells, Cell{Row: uint32(i) / cols, Col: uint32(i) % cols})
+	}
+
+	return cells
+}
+
+// kateHeader is the Kate commitment of the Avail block header extension, as returned by chain_getHeader.
+type kateHeader struct {
+	Extension struct {
+		V1 struct {
+			Commitment kateCommitment `json:"commitment"`
+		} `json:"v1"`
+	} `json:"extension"`
+}
+
+// kateCommitment is the commitment of the data matrix of an Avail block: the KZG commitments of its extended rows,
+// and the data root of the data submitted in the block.
+type kateCommitment struct {
+	Rows       uint32     `json:"rows"`
+	Cols       uint32     `json:"cols"`
+	DataRoot   types.Hash `json:"dataRoot"`
+	Commitment kateBytes  `json:"commitment"`
+}
+
+// rowCommitments decodes the commitments of the extended rows.
+func (k kateCommitment) rowCommitments() ([]bls.G1Affine, error) {
+	if k.Cols == 0 || bits.OnesCount32(k.Cols) != 1 {
+		return nil, fmt.Errorf("invalid number of columns %d", k.Cols)
+	}
+
+	rows := int(k.Rows) * kateExtensionFactor
+	if rows == 0 || len(k.Commitment) != rows*bls.SizeOfG1AffineCompressed {
+		return nil, fmt.Errorf("%d bytes of commitments for %d extended rows", len(k.Commitment), rows)
+	}
+
+	commitments := make([]bls.G1Affine, rows)
+
+	for i := range commitments {
+		if _, err := commitments[i].SetBytes(k.Commitment[i*bls.SizeOfG1AffineCompressed:]); err != nil {
+			return nil, fmt.Errorf("invalid commitment of row %d: %w", i, err)
+		}
+	}
+
+	return commitments, nil
+}
+
+// kateBytes are bytes encoded in JSON as an array of numbers, or as a hex string.
+type kateBytes []byte
+
+func (b *kateBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return err
+		}
+
+		*b = decoded
+
+		return nil
+	}
+
+	// A []uint8 would be decoded from base64.
+	var numbers []uint16
+	if err := json.Unmarshal(data, &numbers); err != nil {
+		return err
+	}
+
+	decoded := make([]byte, len(numbers))
+	for i, n := range numbers {
+		if n > 0xff {
+			return fmt.Errorf("invalid byte %d", n)
+		}
+
+		decoded[i] = byte(n)
+	}
+
+	*b = decoded
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package avail
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	"github.com/stretchr/testify/assert"
+)
+
+// kateNode serves the header commitment and the cell proofs of a data matrix committed with a known τ, which the mock
+// client can't serve, for the block of the client it wraps. The other calls are served by the client it wraps.
+type kateNode struct {
+	gsrpcclient.Client
+
+	// blockHash is the hash of the block whose header has the commitment.
+	blockHash  types.Hash
+	rows, cols uint32
+	commitment []byte
+	// cells are the proofs and the contents of the cells of the extended matrix, by row and column.
+	cells [][][]byte
+}
+
+func newKateNode(t *testing.T, tau *fr.Element, rows, cols uint32) *kateNode {
+	node := &kateNode{rows: rows, cols: cols}
+
+	_, _, g1, _ := bls.Generators()
+	domain := fft.NewDomain(uint64(cols), 0, false)
+
+	g1Mul := func(s *fr.Element) []byte {
+		var p bls.G1Affine
+		p.ScalarMultiplication(&g1, s.ToBigIntRegular(new(big.Int)))
+		b := p.Bytes()
+		return b[:]
+	}
+
+	for row := uint32(0); row < rows*kateExtensionFactor; row++ {
+		coefficients := make([]fr.Element, cols)
+		for i := range coefficients {
+			if _, err := coefficients[i].SetRandom(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		eval := func(x *fr.Element) fr.Element {
+			var y fr.Element
+			for i := len(coefficients) - 1; i >= 0; i-- {
+				y.Mul(&y, x).Add(&y, &coefficients[i])
+			}
+			return y
+		}
+
+		atTau := eval(tau)
+		node.commitment = append(node.commitment, g1Mul(&atTau)...)
+
+		var cells [][]byte
+
+		for col := uint32(0); col < cols; col++ {
+			var x fr.Element
+			x.Exp(domain.Generator, big.NewInt(int64(col)))
+
+			// The proof is the commitment of (p(X) - p(x)) / (X - x).
+			y := eval(&x)
+
+			var num, den, q fr.Element
+			num.Sub(&atTau, &y)
+			den.Sub(tau, &x)
+			q.Div(&num, &den)
+
+			be := y.Bytes()
+			le := make([]byte, len(be))
+			for i := range be {
+				le[len(be)-1-i] = be[i]
+			}
+
+			cells = append(cells, append(g1Mul(&q), le...))
+		}
+
+		node.cells = append(node.cells, cells)
+	}
+
+	return node
+}
+
+// numbers encodes the bytes as a JSON array of numbers, the way the Avail node does.
+func numbers(b []byte) []uint16 {
+	n := make([]uint16, len(b))
+	for i := range b {
+		n[i] = uint16(b[i])
+	}
+	return n
+}
+
+func (n *kateNode) Call(result interface{}, method string, args ...interface{}) error {
+	var response interface{}
+
+	switch method {
+	case "chain_getHeader":
+		// The block is looked up by the wrapped client, so an unknown block isn't found.
+		var header json.RawMessage
+		if err := n.Client.Call(&header, method, args...); err != nil {
+			return err
+		}
+
+		response = map[string]interface{}{
+			"extension": map[string]interface{}{
+				"V1": map[string]interface{}{
+					"commitment": map[string]interface{}{"rows": n.rows, "cols": n.cols, "commitment": numbers(n.commitment)},
+				},
+			},
+		}
+	case "kate_queryProof":
+		var proofs []byte
+		for _, cell := range args[0].([]Cell) {
+			proofs = append(proofs, n.cells[cell.Row][cell.Col]...)
+		}
+		response = numbers(proofs)
+	default:
+		return n.Client.Call(result, method, args...)
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, result)
+}
+
+func newKateTest(t *testing.T) (*MockClient, *kateNode, DataAvailabilityOpts) {
+	var tau fr.Element
+	if _, err := tau.SetRandom(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, g2 := bls.Generators()
+
+	var tauG2 bls.G2Affine
+	tauG2.ScalarMultiplication(&g2, tau.ToBigIntRegular(new(big.Int)))
+	encoded := tauG2.Bytes()
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := m.instance()
+
+	node := newKateNode(t, &tau, 2, 4)
+	node.Client, node.blockHash = api.Client, produceBlock(t, m)
+	api.Client = node
+
+	return m, node, DataAvailabilityOpts{TauG2: encoded[:], Seed: 1}
+}
+
+func TestVerifyDataAvailability(t *testing.T) {
+	c, node, opts := newKateTest(t)
+
+	available, err := VerifyDataAvailability(c, node.blockHash, 10, opts)
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	// All the cells are sampled when there are fewer than the samples.
+	available, err = VerifyDataAvailability(c, node.blockHash, 100, opts)
+	assert.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestVerifyDataAvailabilityReportsFailedCells(t *testing.T) {
+	c, node, opts := newKateTest(t)
+
+	// The content of a cell doesn't match its proof.
+	node.cells[3][1][kateProofSize-1] ^= 1
+
+	available, err := VerifyDataAvailability(c, node.blockHash, 16, opts)
+	assert.False(t, available)
+
+	var failed *CellVerificationError
+	if assert.True(t, errors.As(err, &failed)) {
+		assert.Equal(t, []Cell{{Row: 3, Col: 1}}, failed.Failed)
+		assert.Equal(t, 16, failed.Sampled)
+	}
+
+	// A single failing cell is tolerated below the threshold.
+	opts.Threshold = 0.9
+	available, err = VerifyDataAvailability(c, node.blockHash, 16, opts)
+	assert.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestVerifyDataAvailabilityInvalidCommitment(t *testing.T) {
+	c, node, opts := newKateTest(t)
+
+	node.commitment = node.commitment[:len(node.commitment)-1]
+
+	_, err := VerifyDataAvailability(c, node.blockHash, 4, opts)
+	assert.ErrorContains(t, err, "invalid commitment")
+}
+
+func TestSampleCellsIsReproducible(t *testing.T) {
+	cells := sampleCells(4, 8, 10, 42)
+
+	assert.Equal(t, cells, sampleCells(4, 8, 10, 42))
+	assert.Len(t, cells, 10)
+
+	distinct := make(map[Cell]bool)
+	for _, cell := range cells {
+		assert.Less(t, cell.Row, uint32(4))
+		assert.Less(t, cell.Col, uint32(8))
+		distinct[cell] = true
+	}
+
+	assert.Len(t, distinct, 10)
+}
@@ -0,0 +1,456 @@
+package avail
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// keystoreVersion is the version of the JSON account file envelope.
+	keystoreVersion = 1
+
+	// keystoreFileMode is the only permission the account file may have.
+	keystoreFileMode os.FileMode = 0o600
+
+	keystoreKDF    = "scrypt"
+	keystoreCipher = "aes-256-gcm"
+)
+
+var (
+	// ErrWrongPassphrase is the error returned when the account file can't be decrypted with the given passphrase.
+	ErrWrongPassphrase = errors.New("wrong account passphrase")
+
+	// ErrCorruptAccountFile is the error returned when the encrypted account file is malformed or was tampered with.
+	ErrCorruptAccountFile = errors.New("corrupt account file")
+
+	// ErrPlaintextAccountFile is the error returned by LoadAccount with a passphrase for an unencrypted account file.
+	// Such a file is encrypted in place with MigrateAccount.
+	ErrPlaintextAccountFile = errors.New("account file isn't encrypted")
+
+	// ErrInsecureAccountFile is the error returned when the account file is accessible by other users.
+	ErrInsecureAccountFile = errors.New("account file permissions are too open")
+)
+
+// keystoreScryptN is the scrypt cost parameter of newly encrypted account files.
+// The parameters are stored in the file, so changing it doesn't affect the existing files.
+var keystoreScryptN = 1 << 17
+
+// maxKeystoreScryptN is the highest scrypt cost parameter accepted from an account file,
+// so a crafted file can't make the node exhaust its memory.
+const maxKeystoreScryptN = 1 << 20
+
+// keystoreScryptParams are the scrypt parameters the key of an account file was derived with.
+type keystoreScryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// keystoreEnvelope is the JSON envelope of an account file, holding either the encrypted mnemonic, or the plain
+// mnemonic of an unencrypted account file.
+type keystoreEnvelope struct {
+	Version int `json:"version"`
+	// Address is the SS58 address of the account, for display.
+	Address string `json:"address"`
+	// DerivationPath is the derivation path of the account from the mnemonic, see DeriveAccount. It's empty for
+	// the account of the mnemonic itself.
+	DerivationPath string `json:"derivationPath,omitempty"`
+	// Network is the name of the Avail network the account was created for, if known.
+	Network string `json:"network,omitempty"`
+	// CreatedAt is the creation time of the account file, zero for the files written before it was recorded.
+	CreatedAt time.Time `json:"createdAt"`
+	// Mnemonic is the plain mnemonic, or hex seed, of an unencrypted account file, only meant for local devnets.
+	Mnemonic string `json:"mnemonic,omitempty"`
+
+	KDF       string                `json:"kdf,omitempty"`
+	KDFParams *keystoreScryptParams `json:"kdfparams,omitempty"`
+	Cipher    string                `json:"cipher,omitempty"`
+	Nonce     string                `json:"nonce,omitempty"`
+	// Check is the hash of the second half of the derived key. It tells a wrong passphrase apart from
+	// a corrupt ciphertext, which the AEAD can't.
+	Check      string `json:"check,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+}
+
+// AccountFileOption configures the account file written by SaveAccount.
+type AccountFileOption func(envelope *keystoreEnvelope)
+
+// WithAccountDerivationPath sets the derivation path the account of the file is derived from the mnemonic with,
+// e.g. "//sequencer//0", see DeriveAccount.
+func WithAccountDerivationPath(path string) AccountFileOption {
+	return func(envelope *keystoreEnvelope) {
+		envelope.DerivationPath = path
+	}
+}
+
+// WithAccountNetwork records the name of the Avail network the account was created for in the account file.
+func WithAccountNetwork(network string) AccountFileOption {
+	return func(envelope *keystoreEnvelope) {
+		envelope.Network = network
+	}
+}
+
+// SaveAccount writes the account of the mnemonic to the versioned JSON account file at the given path, replacing
+// any existing file, along with its address, its derivation path and network if set by the options, and its
+// creation time. The mnemonic is encrypted with the passphrase. Without a passphrase, it's written as is, which is
+// only meant for local devnets. The file is only readable by its owner.
+// It returns an error if the mnemonic or the derivation path is invalid, or if there is an issue.
+func SaveAccount(path, mnemonic, passphrase string, opts ...AccountFileOption) error {
+	envelope := keystoreEnvelope{CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	for _, opt := range opts {
+		opt(&envelope)
+	}
+
+	return saveAccountFile(path, mnemonic, passphrase, envelope)
+}
+
+// saveAccountFile writes the account file of the mnemonic with the metadata of the envelope.
+func saveAccountFile(path, mnemonic, passphrase string, envelope keystoreEnvelope) error {
+	keyPair, err := DeriveAccount(mnemonic, envelope.DerivationPath)
+	if err != nil {
+		return err
+	}
+
+	envelope.Version = keystoreVersion
+	envelope.Address = keyPair.Address
+
+	if passphrase == "" {
+		envelope.Mnemonic = strings.TrimSpace(mnemonic)
+	} else if err := encryptKeystoreEnvelope(&envelope, mnemonic, passphrase); err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, raw, keystoreFileMode)
+}
+
+// encryptKeystoreEnvelope encrypts the mnemonic into the envelope with the passphrase.
+func encryptKeystoreEnvelope(envelope *keystoreEnvelope, mnemonic, passphrase string) error {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	params := keystoreScryptParams{N: keystoreScryptN, R: 8, P: 1, Salt: hex.EncodeToString(salt)}
+
+	encryptionKey, check, err := deriveKeystoreKeys(passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	aead, err := newKeystoreAEAD(encryptionKey)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	envelope.KDF = keystoreKDF
+	envelope.KDFParams = &params
+	envelope.Cipher = keystoreCipher
+	envelope.Nonce = hex.EncodeToString(nonce)
+	envelope.Check = hex.EncodeToString(check)
+	envelope.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, []byte(mnemonic), nil))
+
+	return nil
+}
+
+// LoadAccount reads the account file at the given path, decrypting its mnemonic with the passphrase, and derives
+// its account. A legacy account file holding the plain mnemonic on a single line is migrated to a JSON account file
+// first, encrypted with the passphrase if there is one, and the original is kept next to it with a .bak extension.
+// It returns the key pair, and an error wrapping ErrWrongPassphrase if the passphrase doesn't match,
+// ErrCorruptAccountFile if the file is malformed, ErrInsecureAccountFile if the file is accessible by other users,
+// or ErrPlaintextAccountFile if the file isn't encrypted while there is a passphrase.
+func LoadAccount(path, passphrase string) (signature.KeyringPair, error) {
+	envelope, mnemonic, err := loadAccountMnemonic(path, passphrase)
+	if err != nil {
+		return signature.KeyringPair{}, err
+	}
+
+	return DeriveAccount(mnemonic, envelope.DerivationPath)
+}
+
+// MigrateAccount encrypts the unencrypted account file at the given path in place with the passphrase, keeping
+// its metadata. A legacy account file holding the plain mnemonic is migrated to a JSON account file, and kept next
+// to it with a .bak extension.
+// It returns an error wrapping ErrCorruptAccountFile if the file doesn't hold a valid mnemonic, or if there is an issue.
+// A file that is already encrypted is left untouched.
+func MigrateAccount(path, passphrase string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if !isKeystoreEnvelope(raw) {
+		return migrateLegacyAccount(path, raw, passphrase)
+	}
+
+	envelope, err := parseKeystoreEnvelope(raw)
+	if err != nil {
+		return err
+	}
+
+	if envelope.Ciphertext != "" {
+		return nil
+	}
+
+	mnemonic := envelope.Mnemonic
+	envelope.Mnemonic = ""
+
+	return saveAccountFile(path, mnemonic, passphrase, *envelope)
+}
+
+// migrateLegacyAccount writes the JSON account file of the secret URI of the legacy account file content, and
+// keeps the original file with a .bak extension.
+func migrateLegacyAccount(path string, raw []byte, passphrase string) error {
+	mnemonic, derivationPath := strings.TrimSpace(string(raw)), ""
+	if i := strings.Index(mnemonic, "/"); i >= 0 {
+		mnemonic, derivationPath = mnemonic[:i], mnemonic[i:]
+	}
+
+	if _, err := DeriveAccount(mnemonic, derivationPath); err != nil {
+		return fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(path+".bak", raw, keystoreFileMode); err != nil {
+		return fmt.Errorf("couldn't back up the legacy account file: %w", err)
+	}
+
+	envelope := keystoreEnvelope{DerivationPath: derivationPath, CreatedAt: info.ModTime().UTC().Truncate(time.Second)}
+
+	return saveAccountFile(path, mnemonic, passphrase, envelope)
+}
+
+// loadAccountMnemonic reads the account file, migrating a legacy one, and returns its envelope and its mnemonic,
+// decrypted if it's encrypted.
+func loadAccountMnemonic(path, passphrase string) (*keystoreEnvelope, string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isKeystoreEnvelope(raw) {
+		if err := migrateLegacyAccount(path, raw, passphrase); err != nil {
+			return nil, "", fmt.Errorf("failure to migrate legacy account file '%s': %w", path, err)
+		}
+
+		if raw, err = os.ReadFile(path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if info.Mode().Perm()&^keystoreFileMode != 0 {
+		return nil, "", fmt.Errorf("%w: %s has mode %s, want %s", ErrInsecureAccountFile, path, info.Mode().Perm(), keystoreFileMode)
+	}
+
+	envelope, err := parseKeystoreEnvelope(raw)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mnemonic := envelope.Mnemonic
+
+	if envelope.Ciphertext == "" {
+		if passphrase != "" {
+			return nil, "", fmt.Errorf("%w: %s", ErrPlaintextAccountFile, path)
+		}
+	} else if mnemonic, err = decryptKeystoreEnvelope(envelope, passphrase); err != nil {
+		return nil, "", err
+	}
+
+	// A hex seed is checked when the account is derived.
+	if !strings.HasPrefix(strings.TrimSpace(mnemonic), "0x") {
+		if err := ValidateMnemonic(mnemonic); err != nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+		}
+	}
+
+	return envelope, mnemonic, nil
+}
+
+// parseKeystoreEnvelope decodes the envelope of the account file, and checks its version and that it holds
+// a mnemonic.
+func parseKeystoreEnvelope(raw []byte) (*keystoreEnvelope, error) {
+	var envelope keystoreEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	if envelope.Version != keystoreVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrCorruptAccountFile, envelope.Version)
+	}
+
+	if (envelope.Mnemonic == "") == (envelope.Ciphertext == "") {
+		return nil, fmt.Errorf("%w: account file must hold either a mnemonic or a ciphertext", ErrCorruptAccountFile)
+	}
+
+	return &envelope, nil
+}
+
+// decryptKeystoreEnvelope checks the passphrase against the envelope and decrypts the mnemonic.
+func decryptKeystoreEnvelope(envelope *keystoreEnvelope, passphrase string) (string, error) {
+	if envelope.KDF != keystoreKDF || envelope.Cipher != keystoreCipher || envelope.KDFParams == nil {
+		return "", fmt.Errorf("%w: unsupported kdf %q or cipher %q", ErrCorruptAccountFile, envelope.KDF, envelope.Cipher)
+	}
+
+	if envelope.KDFParams.N > maxKeystoreScryptN {
+		return "", fmt.Errorf("%w: scrypt cost %d exceeds %d", ErrCorruptAccountFile, envelope.KDFParams.N, maxKeystoreScryptN)
+	}
+
+	nonce, err := decodeEnvelopeField("nonce", envelope.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	check, err := decodeEnvelopeField("check", envelope.Check)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := decodeEnvelopeField("ciphertext", envelope.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	encryptionKey, expectedCheck, err := deriveKeystoreKeys(passphrase, *envelope.KDFParams)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	if subtle.ConstantTimeCompare(check, expectedCheck) != 1 {
+		return "", ErrWrongPassphrase
+	}
+
+	aead, err := newKeystoreAEAD(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("%w: invalid nonce length %d", ErrCorruptAccountFile, len(nonce))
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// decodeEnvelopeField decodes a hex field of the envelope.
+func decodeEnvelopeField(name, value string) ([]byte, error) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s: %s", ErrCorruptAccountFile, name, err)
+	}
+
+	return decoded, nil
+}
+
+// deriveKeystoreKeys derives the encryption key and the passphrase check from the passphrase.
+func deriveKeystoreKeys(passphrase string, params keystoreScryptParams) ([]byte, []byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	derived, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	check := sha256.Sum256(derived[32:])
+
+	return derived[:32], check[:], nil
+}
+
+// newKeystoreAEAD returns the AES-GCM cipher for the encryption key.
+func newKeystoreAEAD(encryptionKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// isKeystoreEnvelope checks whether the account file content is a JSON envelope rather than a plain mnemonic.
+func isKeystoreEnvelope(raw []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{"))
+}
+
+// writeFileAtomic writes the data to a temporary file next to the path and renames it over the path,
+// so a failed write doesn't leave a truncated account file behind.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadPassphraseFile reads an account passphrase from the file at the given path, without the trailing newline.
+// An empty path gives an empty passphrase.
+func ReadPassphraseFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failure to read passphrase file '%s': %w", path, err)
+	}
+
+	return strings.TrimRight(string(raw), "\r\n"), nil
+}
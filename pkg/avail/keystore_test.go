@@ -0,0 +1,300 @@
+package avail
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useFastKeystoreKDF lowers the scrypt cost for the duration of the test.
+func useFastKeystoreKDF(t *testing.T) {
+	n := keystoreScryptN
+	keystoreScryptN = 1 << 10
+
+	t.Cleanup(func() { keystoreScryptN = n })
+}
+
+func newTestMnemonic(t *testing.T) string {
+	account, err := NewAccountWithStrength(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return account.Mnemonic
+}
+
+func TestSaveAndLoadAccount(t *testing.T) {
+	useFastKeystoreKDF(t)
+
+	mnemonic := newTestMnemonic(t)
+	path := filepath.Join(t.TempDir(), "account")
+
+	assert.NoError(t, SaveAccount(path, mnemonic, "correct horse"))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), strings.Fields(mnemonic)[0]+" ")
+
+	expected, err := NewAccountFromMnemonic(mnemonic)
+	assert.NoError(t, err)
+
+	account, err := LoadAccount(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+
+	_, err = LoadAccount(path, "wrong horse")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+	assert.False(t, errors.Is(err, ErrCorruptAccountFile))
+
+	assert.Error(t, SaveAccount(path, "not a mnemonic", "correct horse"))
+	assert.Error(t, SaveAccount(path, mnemonic, "correct horse", WithAccountDerivationPath("sequencer")))
+}
+
+func TestAccountFileMetadata(t *testing.T) {
+	useFastKeystoreKDF(t)
+
+	mnemonic := newTestMnemonic(t)
+	path := filepath.Join(t.TempDir(), "account")
+
+	before := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, SaveAccount(path, mnemonic, "", WithAccountDerivationPath("//sequencer//0"), WithAccountNetwork("devnet")))
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	expected, err := DeriveAccount(mnemonic, "//sequencer//0")
+	assert.NoError(t, err)
+
+	var envelope keystoreEnvelope
+	if assert.NoError(t, json.Unmarshal(raw, &envelope)) {
+		assert.Equal(t, keystoreVersion, envelope.Version)
+		assert.Equal(t, expected.Address, envelope.Address)
+		assert.Equal(t, "//sequencer//0", envelope.DerivationPath)
+		assert.Equal(t, "devnet", envelope.Network)
+		assert.False(t, envelope.CreatedAt.Before(before))
+		assert.Equal(t, mnemonic, envelope.Mnemonic)
+		assert.Empty(t, envelope.Ciphertext)
+	}
+
+	// The unencrypted file is read without a passphrase, and refused with one.
+	account, err := LoadAccount(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+
+	_, err = LoadAccount(path, "correct horse")
+	assert.ErrorIs(t, err, ErrPlaintextAccountFile)
+
+	// Encrypting it keeps its metadata.
+	assert.NoError(t, MigrateAccount(path, "correct horse"))
+
+	raw, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), strings.Fields(mnemonic)[0]+" ")
+
+	var encrypted keystoreEnvelope
+	if assert.NoError(t, json.Unmarshal(raw, &encrypted)) {
+		assert.Equal(t, envelope.Address, encrypted.Address)
+		assert.Equal(t, envelope.Network, encrypted.Network)
+		assert.Equal(t, envelope.CreatedAt, encrypted.CreatedAt)
+		assert.Empty(t, encrypted.Mnemonic)
+	}
+
+	account, err = LoadAccount(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+}
+
+func TestLoadAccountCorrupt(t *testing.T) {
+	useFastKeystoreKDF(t)
+
+	path := filepath.Join(t.TempDir(), "account")
+	assert.NoError(t, SaveAccount(path, newTestMnemonic(t), "correct horse"))
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var envelope keystoreEnvelope
+	assert.NoError(t, json.Unmarshal(raw, &envelope))
+
+	tamper := func(fn func(envelope *keystoreEnvelope)) string {
+		tampered := envelope
+		fn(&tampered)
+
+		raw, err := json.Marshal(tampered)
+		assert.NoError(t, err)
+
+		tamperedPath := filepath.Join(t.TempDir(), "account")
+		assert.NoError(t, os.WriteFile(tamperedPath, raw, 0o600))
+
+		return tamperedPath
+	}
+
+	corruptPaths := map[string]string{
+		"ciphertext": tamper(func(e *keystoreEnvelope) {
+			last := e.Ciphertext[len(e.Ciphertext)-1:]
+			flipped := "0"
+			if last == "0" {
+				flipped = "1"
+			}
+			e.Ciphertext = e.Ciphertext[:len(e.Ciphertext)-1] + flipped
+		}),
+		"nonce":   tamper(func(e *keystoreEnvelope) { e.Nonce = "zz" }),
+		"version": tamper(func(e *keystoreEnvelope) { e.Version = keystoreVersion + 1 }),
+		"kdf":     tamper(func(e *keystoreEnvelope) { e.KDF = "pbkdf2" }),
+		"cost": tamper(func(e *keystoreEnvelope) {
+			params := *e.KDFParams
+			params.N = maxKeystoreScryptN * 2
+			e.KDFParams = &params
+		}),
+		"both": tamper(func(e *keystoreEnvelope) { e.Mnemonic = "mnemonic" }),
+	}
+
+	truncatedPath := filepath.Join(t.TempDir(), "account")
+	assert.NoError(t, os.WriteFile(truncatedPath, raw[:len(raw)/2], 0o600))
+	corruptPaths["truncated"] = truncatedPath
+
+	for name, corruptPath := range corruptPaths {
+		_, err := LoadAccount(corruptPath, "correct horse")
+		assert.ErrorIs(t, err, ErrCorruptAccountFile, name)
+		assert.False(t, errors.Is(err, ErrWrongPassphrase), name)
+	}
+
+	// A file other users can read is refused.
+	assert.NoError(t, os.Chmod(path, 0o644))
+
+	_, err = LoadAccount(path, "correct horse")
+	assert.ErrorIs(t, err, ErrInsecureAccountFile)
+}
+
+func TestMigrateAccount(t *testing.T) {
+	useFastKeystoreKDF(t)
+
+	mnemonic := newTestMnemonic(t)
+	path := filepath.Join(t.TempDir(), "account")
+	assert.NoError(t, os.WriteFile(path, []byte(mnemonic+"//sequencer\n"), 0o644))
+
+	expected, err := DeriveAccount(mnemonic, "//sequencer")
+	assert.NoError(t, err)
+
+	// The legacy file is migrated on first load, and the original kept.
+	account, err := LoadAccount(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	backup, err := os.ReadFile(path + ".bak")
+	assert.NoError(t, err)
+	assert.Equal(t, mnemonic+"//sequencer\n", string(backup))
+
+	account, err = LoadAccount(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+
+	// Migrating an encrypted file again leaves it untouched.
+	before, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, MigrateAccount(path, "other passphrase"))
+
+	after, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	// A legacy file without a valid mnemonic isn't migrated.
+	assert.NoError(t, os.WriteFile(path, []byte("not a mnemonic"), 0o600))
+	assert.ErrorIs(t, MigrateAccount(path, "correct horse"), ErrCorruptAccountFile)
+
+	_, err = LoadAccount(path, "")
+	assert.ErrorIs(t, err, ErrCorruptAccountFile)
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "not a mnemonic", string(raw))
+}
+
+func TestLoadAccountInvalidMnemonic(t *testing.T) {
+	mnemonic := strings.Fields(newTestMnemonic(t))
+	mnemonic[6] = "recieve"
+
+	raw, err := json.Marshal(keystoreEnvelope{Version: keystoreVersion, Mnemonic: strings.Join(mnemonic, " ")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "account")
+	assert.NoError(t, os.WriteFile(path, raw, 0o600))
+
+	_, err = LoadAccount(path, "")
+	if assert.ErrorIs(t, err, ErrCorruptAccountFile) {
+		assert.Contains(t, err.Error(), "word 7 'recieve' is not a valid BIP39 word")
+	}
+
+	// The legacy file is reported the same, and left as is.
+	assert.NoError(t, os.WriteFile(path, []byte(strings.Join(mnemonic, " ")+"\n"), 0o600))
+
+	_, err = LoadAccount(path, "")
+	if assert.ErrorIs(t, err, ErrCorruptAccountFile) {
+		assert.Contains(t, err.Error(), "word 7 'recieve' is not a valid BIP39 word")
+	}
+}
+
+func TestAccountFromFile(t *testing.T) {
+	useFastKeystoreKDF(t)
+
+	mnemonic := newTestMnemonic(t)
+	path := filepath.Join(t.TempDir(), "account")
+	assert.NoError(t, os.WriteFile(path, []byte(mnemonic), 0o644))
+
+	expected, err := NewAccountFromMnemonic(mnemonic)
+	assert.NoError(t, err)
+
+	// Without a passphrase, the legacy file is migrated to an unencrypted JSON account file.
+	account, err := AccountFromFile(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, isKeystoreEnvelope(raw))
+	assert.Contains(t, string(raw), mnemonic)
+
+	backup, err := os.ReadFile(path + ".bak")
+	assert.NoError(t, err)
+	assert.Equal(t, mnemonic, string(backup))
+
+	// With a passphrase, it's encrypted in place.
+	account, err = AccountFromFile(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.PublicKey, account.PublicKey)
+
+	_, err = LoadAccount(path, "correct horse")
+	assert.NoError(t, err)
+
+	_, err = AccountFromFile(path, "")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestReadPassphraseFile(t *testing.T) {
+	passphrase, err := ReadPassphraseFile("")
+	assert.NoError(t, err)
+	assert.Empty(t, passphrase)
+
+	path := filepath.Join(t.TempDir(), "passphrase")
+	assert.NoError(t, os.WriteFile(path, []byte("correct horse \n"), 0o600))
+
+	passphrase, err = ReadPassphraseFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "correct horse ", passphrase)
+}
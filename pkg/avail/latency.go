@@ -0,0 +1,144 @@
+package avail
+
+import (
+	"fmt"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+)
+
+// DefaultLatencyHysteresis is the fraction of the round-trip time of the active endpoint another endpoint must be
+// faster by for a latency-aware failover client to switch to it.
+const DefaultLatencyHysteresis = 0.25
+
+// latencySmoothing is the weight of the last probe in the smoothed round-trip time of an endpoint.
+const latencySmoothing = 0.3
+
+// EndpointStats are the measurements of an endpoint of a failover client, taken by its health checks.
+type EndpointStats struct {
+	// Endpoint is the URL of the endpoint.
+	Endpoint string
+	// Active tells whether the calls of the client are routed to the endpoint.
+	Active bool
+	// Healthy tells whether the last probe of the endpoint succeeded.
+	Healthy bool
+	// Err is the error of the last probe, or of the connection to the endpoint, if it failed.
+	Err error
+	// Height is the best block number of the endpoint, as of its last successful probe.
+	Height uint64
+	// Latency is the smoothed round-trip time of the probes of the endpoint, and LastLatency the one of the last
+	// successful probe.
+	Latency     time.Duration
+	LastLatency time.Duration
+	// Probed is the time of the last probe of the endpoint, zero if it wasn't probed yet.
+	Probed time.Time
+}
+
+// WithLatencyAwareSelection makes a failover client route the calls to the lowest-latency healthy endpoint within
+// one block of the best block of the endpoints, measured by the health checks, rather than to the first healthy one.
+// The client only switches to an endpoint faster than the active one by more than the hysteresis fraction of the
+// round-trip time of the active one, DefaultLatencyHysteresis if not between 0 and 1, so it doesn't flap between
+// endpoints of similar latencies.
+func WithLatencyAwareSelection(hysteresis float64) ClientOption {
+	return func(c *client) {
+		if hysteresis <= 0 || hysteresis >= 1 {
+			hysteresis = DefaultLatencyHysteresis
+		}
+
+		c.latencyAware = true
+		c.latencyHysteresis = hysteresis
+	}
+}
+
+// Stats returns the measurements of the endpoints of the client, in the order of the endpoints.
+func (c *client) Stats() []EndpointStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	stats := make([]EndpointStats, len(c.endpoints))
+	for i, url := range c.endpoints {
+		stats[i] = c.stats[i]
+		stats[i].Endpoint = url
+		stats[i].Active = i == c.active
+	}
+
+	return stats
+}
+
+// probeEndpoint fetches the best block number of the endpoint, and measures the round-trip time of the call.
+func (c *client) probeEndpoint(api *gsrpc.SubstrateAPI) (uint64, time.Duration, error) {
+	if c.probe != nil {
+		return c.probe(api)
+	}
+
+	start := time.Now()
+
+	header, err := api.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return uint64(header.Number), time.Since(start), nil
+}
+
+// recordProbe records the probe of the endpoint, smoothing its round-trip time. The lock must be held.
+func (c *client) recordProbe(i int, height uint64, latency time.Duration, err error) {
+	stats := &c.stats[i]
+	stats.Probed = time.Now()
+	stats.Healthy = err == nil
+	stats.Err = err
+
+	if err != nil {
+		return
+	}
+
+	if stats.Latency == 0 {
+		stats.Latency = latency
+	} else {
+		stats.Latency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(stats.Latency))
+	}
+
+	stats.Height = height
+	stats.LastLatency = latency
+}
+
+// fastestEndpoint returns the healthy endpoint with the lowest smoothed round-trip time within one block of the best
+// block, or -1 if none. The lock must be held.
+func (c *client) fastestEndpoint(best uint64, heights []uint64, failures []error) int {
+	fastest := -1
+
+	for i := range c.endpoints {
+		if failures[i] != nil || c.conns[i] == nil || best-heights[i] > 1 {
+			continue
+		}
+
+		if fastest < 0 || c.stats[i].Latency < c.stats[fastest].Latency {
+			fastest = i
+		}
+	}
+
+	return fastest
+}
+
+// fasterEndpoint returns the endpoint a latency-aware client switches to from the healthy active one, and the
+// reason, or the active one if it's still preferred: the fastest endpoint is only preferred if it's faster by more
+// than the hysteresis, unless the active one lags more than one block behind the best block. The lock must be held.
+func (c *client) fasterEndpoint(best uint64, heights []uint64, failures []error) (int, string) {
+	from := c.active
+
+	to := c.fastestEndpoint(best, heights, failures)
+	if to < 0 || to == from {
+		return from, ""
+	}
+
+	if lag := best - heights[from]; lag > 1 {
+		return to, fmt.Sprintf("%d blocks behind the best block %d", lag, best)
+	}
+
+	active, fastest := c.stats[from].Latency, c.stats[to].Latency
+	if float64(fastest) >= float64(active)*(1-c.latencyHysteresis) {
+		return from, ""
+	}
+
+	return to, fmt.Sprintf("round-trip time %s, against %s", fastest, active)
+}
@@ -0,0 +1,132 @@
+package avail
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// LockReasons are the operations the locked balance can't be used for.
+type LockReasons uint8
+
+const (
+	// LockReasonsFee locks the balance for transaction fees only.
+	LockReasonsFee LockReasons = iota
+	// LockReasonsMisc locks the balance for anything but transaction fees, e.g. transfers.
+	LockReasonsMisc
+	// LockReasonsAll locks the balance for everything.
+	LockReasonsAll
+)
+
+func (r LockReasons) String() string {
+	switch r {
+	case LockReasonsFee:
+		return "Fee"
+	case LockReasonsMisc:
+		return "Misc"
+	case LockReasonsAll:
+		return "All"
+	default:
+		return fmt.Sprintf("LockReasons(%d)", uint8(r))
+	}
+}
+
+// BalanceLock is a lock on the free balance of an account, e.g. of the vested tokens not yet unlocked. The locks
+// of an account overlap: the locked balance is the amount of the largest lock, not their sum.
+type BalanceLock struct {
+	// ID identifies the pallet holding the lock, e.g. "vesting " or "staking ".
+	ID [8]byte
+	// Amount is the locked balance, in Avail fractions.
+	Amount *big.Int
+	// Reasons are the operations the locked balance can't be used for.
+	Reasons LockReasons
+}
+
+// Name returns the ID of the lock as text, without its padding.
+func (l BalanceLock) Name() string {
+	return strings.TrimRight(string(l.ID[:]), " \x00")
+}
+
+// AppliesToTransfers checks whether the lock restricts the transfers of the locked balance.
+func (l BalanceLock) AppliesToTransfers() bool {
+	return l.Reasons != LockReasonsFee
+}
+
+// balanceLock is the SCALE encoding of a lock in the Balances.Locks storage.
+type balanceLock struct {
+	ID      [8]byte
+	Amount  types.U128
+	Reasons types.U8
+}
+
+// GetLocks retrieves the locks on the free balance of the specified account.
+// It takes a client and the account key pair, and returns the locks, none if the account has no locks or doesn't
+// exist, and an error if there is an issue.
+func GetLocks(client Client, account signature.KeyringPair) ([]BalanceLock, error) {
+	api, err := instance(client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := latestMetadata(client, api)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := types.CreateStorageKey(meta, "Balances", "Locks", account.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []balanceLock
+	if _, err := api.RPC.State.GetStorageLatest(key, &raw); err != nil {
+		return nil, err
+	}
+
+	locks := make([]BalanceLock, len(raw))
+	for i, lock := range raw {
+		locks[i] = BalanceLock{ID: lock.ID, Amount: u128ToBig(lock.Amount), Reasons: LockReasons(lock.Reasons)}
+	}
+
+	return locks, nil
+}
+
+// TransferableBalance retrieves the part of the free balance of the specified account that can be transferred,
+// in Avail fractions, i.e. its free balance minus the largest lock restricting transfers, e.g. of its vested tokens.
+// It takes a client and the account key pair, and returns the transferable balance and an error if there is an
+// issue. It returns an error wrapping ErrAccountNotFound if the account doesn't exist on the Avail network.
+func TransferableBalance(client Client, account signature.KeyringPair) (*big.Int, error) {
+	data, err := GetAccountData(client, account)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, err := GetLocks(client, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return transferableBalance(data, locks), nil
+}
+
+// transferableBalance returns the free balance of the account minus the largest of its locks restricting transfers,
+// or its balance frozen for anything but fees if it's larger.
+func transferableBalance(data *AccountData, locks []BalanceLock) *big.Int {
+	locked := data.MiscFrozen
+
+	for _, lock := range locks {
+		if lock.AppliesToTransfers() && lock.Amount.Cmp(locked) > 0 {
+			locked = lock.Amount
+		}
+	}
+
+	transferable := new(big.Int).Sub(data.Free, locked)
+	if transferable.Sign() < 0 {
+		return big.NewInt(0)
+	}
+
+	return transferable
+}
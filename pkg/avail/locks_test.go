@@ -0,0 +1,135 @@
+package avail
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newLock returns a lock of the amount, in AVL.
+func newLock(id string, amount uint64, reasons LockReasons) BalanceLock {
+	lock := BalanceLock{Amount: new(big.Int).SetUint64(amount * AVL), Reasons: reasons}
+	copy(lock.ID[:], id)
+
+	return lock
+}
+
+func TestTransferableBalance(t *testing.T) {
+	testCases := []struct {
+		name  string
+		locks []BalanceLock
+		want  uint64
+	}{
+		{"no locks", nil, 10},
+		{"vesting", []BalanceLock{newLock("vesting ", 4, LockReasonsMisc)}, 6},
+		{"overlapping", []BalanceLock{newLock("vesting ", 4, LockReasonsMisc), newLock("staking ", 7, LockReasonsAll), newLock("democrac", 2, LockReasonsAll)}, 3},
+		{"fees only", []BalanceLock{newLock("vesting ", 3, LockReasonsMisc), newLock("feelock ", 9, LockReasonsFee)}, 7},
+		{"above free balance", []BalanceLock{newLock("vesting ", 12, LockReasonsAll)}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, account := newFundedMockClient(t, 10*AVL)
+
+			if err := m.SetLocks(account, tc.locks...); err != nil {
+				t.Fatal(err)
+			}
+
+			locks, err := GetLocks(m, account)
+			if assert.NoError(t, err) {
+				assert.Equal(t, len(tc.locks), len(locks))
+
+				for i := range locks {
+					assert.Equal(t, tc.locks[i].ID, locks[i].ID)
+					assert.Equal(t, 0, tc.locks[i].Amount.Cmp(locks[i].Amount))
+					assert.Equal(t, tc.locks[i].Reasons, locks[i].Reasons)
+				}
+			}
+
+			transferable, err := TransferableBalance(m, account)
+			assert.NoError(t, err)
+			assert.Equal(t, new(big.Int).SetUint64(tc.want*AVL), transferable)
+		})
+	}
+
+	lock := newLock("vesting ", 1, LockReasonsMisc)
+	assert.Equal(t, "vesting", lock.Name())
+	assert.Equal(t, "Misc", lock.Reasons.String())
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missing, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locks, err := GetLocks(m, missing)
+	assert.NoError(t, err)
+	assert.Empty(t, locks)
+
+	_, err = TransferableBalance(m, missing)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestLockedBalanceNotTransferable(t *testing.T) {
+	m, funder := newFundedMockClient(t, 15*AVL)
+
+	operator, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(operator, new(big.Int).SetUint64(10*AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetLocks(operator, newLock("vesting ", 6, LockReasonsMisc), newLock("staking ", 8, LockReasonsAll)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The operator is topped up to a transferable balance of 5 AVL, even though its free balance is 10 AVL.
+	minimum := new(big.Int).SetUint64(5 * AVL)
+
+	deposited, err := EnsureBalance(context.Background(), m, funder, operator, minimum, SubmitOpts{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, new(big.Int).SetUint64(3*AVL), deposited)
+	}
+
+	transferable, err := TransferableBalance(m, operator)
+	assert.NoError(t, err)
+	assert.Equal(t, minimum, transferable)
+
+	deposited, err = EnsureBalance(context.Background(), m, funder, operator, minimum, SubmitOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deposited.Sign())
+
+	// The locked balance can't be transferred.
+	to, err := types.NewAccountID(funder.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Transfer(context.Background(), m, operator, *to, new(big.Int).SetUint64(6*AVL), SubmitOpts{})
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	_, err = Transfer(context.Background(), m, operator, *to, minimum, SubmitOpts{})
+	assert.NoError(t, err)
+
+	// TransferAll only transfers the transferable balance.
+	if err := m.SetBalance(operator, new(big.Int).SetUint64(11*AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = TransferAll(context.Background(), m, operator, *to, false, SubmitOpts{})
+	assert.NoError(t, err)
+
+	balance, err := GetBalance(m, operator)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).SetUint64(8*AVL), balance)
+}
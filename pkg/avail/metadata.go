@@ -0,0 +1,95 @@
+package avail
+
+import (
+	"errors"
+	"sync"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// metadataCache is the metadata of the Avail runtime, cached by its spec version, as fetching the metadata is one
+// of the slowest Avail RPCs. It is safe for concurrent use.
+type metadataCache struct {
+	// lock is held while the metadata is fetched, so concurrent callers don't fetch it again.
+	lock        sync.Mutex
+	specVersion types.U32
+	meta        *types.Metadata
+}
+
+// metadataMismatchError is the error returned when chain data can't be decoded with the metadata, e.g. the events
+// of a block produced by an upgraded runtime.
+type metadataMismatchError struct {
+	err error
+}
+
+func (e *metadataMismatchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *metadataMismatchError) Unwrap() error {
+	return e.err
+}
+
+// metadata returns the metadata of the latest runtime. It's only fetched when the spec version reported by the
+// latest runtime version changes, i.e. after a runtime upgrade, or when the cache was invalidated. The runtime
+// upgrade is detected once the cached runtime version is revalidated.
+func (c *client) metadata(api *gsrpc.SubstrateAPI) (*types.Metadata, error) {
+	rv, err := c.runtimeVersion(api)
+	if err != nil {
+		return nil, err
+	}
+
+	c.metadataCache.lock.Lock()
+	defer c.metadataCache.lock.Unlock()
+
+	if c.metadataCache.meta != nil && c.metadataCache.specVersion == rv.SpecVersion {
+		return c.metadataCache.meta, nil
+	}
+
+	meta, err := api.RPC.State.GetMetadataLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.metadataCache.meta != nil {
+		c.logger.Info("Avail runtime upgraded, metadata refreshed", "spec_version", rv.SpecVersion, "previous_spec_version", c.metadataCache.specVersion)
+	}
+
+	c.metadataCache.specVersion = rv.SpecVersion
+	c.metadataCache.meta = meta
+
+	return meta, nil
+}
+
+// invalidateMetadata drops the cached metadata and runtime version, so they're fetched again on the next call,
+// e.g. after a decoding error caused by a metadata mismatch.
+func (c *client) invalidateMetadata() {
+	c.invalidateRuntimeVersion()
+
+	c.metadataCache.lock.Lock()
+	defer c.metadataCache.lock.Unlock()
+
+	c.metadataCache.meta = nil
+}
+
+// invalidateMetadataOnMismatch invalidates the cached metadata if the error is a metadata mismatch, and returns
+// the error.
+func (c *client) invalidateMetadataOnMismatch(err error) error {
+	var mismatch *metadataMismatchError
+	if errors.As(err, &mismatch) {
+		c.invalidateMetadata()
+	}
+
+	return err
+}
+
+// latestMetadata returns the cached metadata of the latest runtime of the client, fetched with the api.
+func latestMetadata(client Client, api *gsrpc.SubstrateAPI) (*types.Metadata, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.metadata(api)
+}
@@ -0,0 +1,74 @@
+package avail
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataCachedBySpecVersion(t *testing.T) {
+	// The runtime version isn't cached, so the upgrade is seen right away.
+	m, err := NewMockClient(WithRuntimeVersionTTL(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+	api := m.instance()
+
+	for i := 0; i < 3; i++ {
+		meta, err := latestMetadata(m, api)
+		assert.NoError(t, err)
+		assert.Same(t, m.meta, meta)
+	}
+
+	assert.Equal(t, 1, r.recorded().metadataFetches)
+
+	m.UpgradeRuntime(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := latestMetadata(m, api)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	// The runtime upgrade triggers exactly one refetch, also for concurrent callers.
+	assert.Equal(t, 2, r.recorded().metadataFetches)
+}
+
+func TestMetadataInvalidatedOnMismatch(t *testing.T) {
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+	c, api := m.client, m.instance()
+
+	_, err = c.metadata(api)
+	assert.NoError(t, err)
+
+	otherErr := errors.New("other error")
+	assert.Equal(t, otherErr, c.invalidateMetadataOnMismatch(otherErr))
+
+	_, err = c.metadata(api)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r.recorded().metadataFetches)
+
+	mismatchErr := &metadataMismatchError{errors.New("unknown event")}
+	assert.ErrorIs(t, c.invalidateMetadataOnMismatch(mismatchErr), mismatchErr)
+
+	_, err = c.metadata(api)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.recorded().metadataFetches)
+}
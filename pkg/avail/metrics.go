@@ -0,0 +1,80 @@
+package avail
+
+import (
+	"errors"
+	"time"
+)
+
+// SubmitOutcome is the outcome of an extrinsic submission recorded by the metrics.
+type SubmitOutcome string
+
+const (
+	// OutcomeFinalized is the outcome of a submission waited for finalization, and finalized.
+	OutcomeFinalized SubmitOutcome = "finalized"
+	// OutcomeInBlock is the outcome of a submission waited for inclusion, and included in a block.
+	OutcomeInBlock SubmitOutcome = "in_block"
+	// OutcomeDropped is the outcome of a submission dropped from the transaction pool.
+	OutcomeDropped SubmitOutcome = "dropped"
+	// OutcomeInvalid is the outcome of a submission found invalid by the transaction pool.
+	OutcomeInvalid SubmitOutcome = "invalid"
+	// OutcomeError is the outcome of a submission failed for any other reason, e.g. a timeout or an RPC error.
+	OutcomeError SubmitOutcome = "error"
+)
+
+// Metrics records the extrinsic submissions of the submit helpers. It must be safe for concurrent use.
+type Metrics interface {
+	// ExtrinsicSubmitted records a submission of the call with its outcome, and its latency from the signature
+	// of the extrinsic to the outcome.
+	ExtrinsicSubmitted(call string, outcome SubmitOutcome, latency time.Duration)
+
+	// DataSubmitted records the size of the data of a data submission, in bytes.
+	DataSubmitted(size int)
+}
+
+// NopMetrics returns the Metrics recording nothing, used by default.
+func NopMetrics() Metrics {
+	return nopMetrics{}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ExtrinsicSubmitted(string, SubmitOutcome, time.Duration) {}
+
+func (nopMetrics) DataSubmitted(int) {}
+
+// WithMetrics sets the metrics recording the extrinsic submissions of the client, e.g. a *PrometheusMetrics.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *client) {
+		c.metrics = metrics
+	}
+}
+
+// submitOutcome returns the outcome of a submission waiting for the status, from its error.
+func submitOutcome(waitFor WaitFor, err error) SubmitOutcome {
+	switch {
+	case err == nil && waitFor == WaitFinalized:
+		return OutcomeFinalized
+	case err == nil:
+		return OutcomeInBlock
+	case errors.Is(err, ErrExtrinsicDropped):
+		return OutcomeDropped
+	case errors.Is(err, ErrExtrinsicInvalid):
+		return OutcomeInvalid
+	default:
+		return OutcomeError
+	}
+}
+
+// submissionMetrics returns the metrics of the client, recording nothing without any.
+func (c *client) submissionMetrics() Metrics {
+	if c.metrics == nil {
+		return nopMetrics{}
+	}
+
+	return c.metrics
+}
+
+// recordSubmission records the outcome of the submission of the call signed at the start time.
+func (c *client) recordSubmission(call string, waitFor WaitFor, start time.Time, err error) {
+	c.submissionMetrics().ExtrinsicSubmitted(call, submitOutcome(waitFor, err), time.Since(start))
+}
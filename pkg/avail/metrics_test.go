@@ -0,0 +1,96 @@
+package avail
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMetrics records the submissions by call and outcome, and the data sizes.
+type recordingMetrics struct {
+	lock        sync.Mutex
+	submissions map[string]map[SubmitOutcome]int
+	dataSizes   []int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{submissions: make(map[string]map[SubmitOutcome]int)}
+}
+
+func (m *recordingMetrics) ExtrinsicSubmitted(call string, outcome SubmitOutcome, _ time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.submissions[call] == nil {
+		m.submissions[call] = make(map[SubmitOutcome]int)
+	}
+
+	m.submissions[call][outcome]++
+}
+
+func (m *recordingMetrics) DataSubmitted(size int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.dataSizes = append(m.dataSizes, size)
+}
+
+func TestSubmissionMetrics(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	metrics := newRecordingMetrics()
+	WithMetrics(metrics)(m.client)
+
+	m.Script(MockOutcome{}, MockOutcome{Drop: true}, MockOutcome{Invalid: true}, MockOutcome{}, MockOutcome{Err: errSubmitStopped})
+
+	nonces := NewNonceManager()
+	submit := func(data []byte, waitFor WaitFor) error {
+		_, err := SubmitData(context.Background(), m, account, 1, data, SubmitOpts{Nonces: nonces, WaitFor: waitFor})
+		return err
+	}
+
+	assert.NoError(t, submit([]byte("block 1"), WaitInBlock))
+	assert.ErrorIs(t, submit([]byte("block 2"), WaitInBlock), ErrExtrinsicDropped)
+	assert.ErrorIs(t, submit([]byte("block 3"), WaitInBlock), ErrExtrinsicInvalid)
+	assert.NoError(t, submit([]byte("block 4"), WaitFinalized))
+	assert.ErrorIs(t, submit([]byte("block 5"), WaitInBlock), errSubmitStopped)
+
+	// An oversized payload isn't submitted, so it isn't recorded.
+	assert.ErrorIs(t, submit(make([]byte, DefaultMaxAppDataLength+1), WaitInBlock), ErrDataTooLarge)
+
+	assert.Equal(t, map[SubmitOutcome]int{
+		OutcomeInBlock:   1,
+		OutcomeDropped:   1,
+		OutcomeInvalid:   1,
+		OutcomeFinalized: 1,
+		OutcomeError:     1,
+	}, metrics.submissions[CallSubmitData])
+	assert.Equal(t, []int{7, 7, 7, 7, 7}, metrics.dataSizes)
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	metrics, err := NewPrometheusMetrics("test", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics.ExtrinsicSubmitted(CallSubmitData, OutcomeInBlock, time.Second)
+	metrics.ExtrinsicSubmitted(CallSubmitData, OutcomeInBlock, 2*time.Second)
+	metrics.ExtrinsicSubmitted(CallTransfer, OutcomeDropped, time.Second)
+	metrics.DataSubmitted(1024)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.submissions.WithLabelValues(CallSubmitData, string(OutcomeInBlock))))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.submissions.WithLabelValues(CallTransfer, string(OutcomeDropped))))
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.latency)+testutil.CollectAndCount(metrics.dataSize))
+
+	// The metrics can't be registered twice with the same registerer.
+	_, err = NewPrometheusMetrics("test", registry)
+	assert.Error(t, err)
+}
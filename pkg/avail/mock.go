@@ -0,0 +1,1024 @@
+package avail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/author"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/state"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/crypto/blake2b"
+)
+
+// errMockUnsupported is the error of the calls a MockClient doesn't serve.
+var errMockUnsupported = errors.New("not supported by the mock client")
+
+// mockOutdatedError is the transaction pool error of an extrinsic signed with a nonce already used on chain.
+const mockOutdatedError = "1010: Invalid Transaction: Transaction is outdated"
+
+// MockOutcome is the scripted outcome of an extrinsic submitted to a MockClient.
+type MockOutcome struct {
+	// Err rejects the submission with the error, e.g. an invalid transaction error of the transaction pool.
+	Err error
+	// Drop drops the extrinsic from the transaction pool once it's ready, with a Dropped status.
+	Drop bool
+	// Invalid invalidates the extrinsic once it's ready, with an Invalid status.
+	Invalid bool
+	// FinalizeAfter is the number of statuses sent before the Finalized one: Ready statuses, and then InBlock.
+	// It defaults to 2, a Ready and an InBlock status.
+	FinalizeAfter int
+	// DispatchError fails the dispatch of the included extrinsic with the error, e.g. the one returned by
+	// MockClient.ModuleError. The extrinsic still uses its nonce.
+	DispatchError *types.DispatchError
+}
+
+// MockClient is an in-memory Avail chain implementing the Client interface, so the code using the Avail helpers,
+// e.g. GetBalance, DepositAmount or SubmitData, can be tested without an Avail node.
+// The chain has the metadata of the go-substrate-rpc-client tests, with a DataAvailability pallet. Each accepted
+// extrinsic is included in a new block, finalized right away, and dispatched without fees: the nonce of the signer
+// is bumped, and the Balances transfers are applied within the balance locks. The fee estimated by payment_queryInfo
+// is zero unless it's set with SetFee, and the runtime can be upgraded with UpgradeRuntime. The outcomes of the
+// submissions can be scripted with Script.
+// The block headers commit the data root of the submitted data, whose proofs are served by kate_queryDataProof.
+// The signatures and the eras of the extrinsics aren't checked, and the new heads and storage subscriptions
+// aren't supported.
+// It is safe for concurrent use.
+type MockClient struct {
+	*client
+
+	// lock guards the runtime, the chain and the submissions.
+	lock sync.Mutex
+	// meta and specVersion are the metadata and the spec version of the runtime.
+	meta        *types.Metadata
+	specVersion uint32
+	// blocks, blockHashes and storage are the blocks of the chain by number, their hashes, and the storage
+	// at each block by key. numbers are the numbers of the blocks by hash.
+	blocks      []*types.SignedBlock
+	blockHashes []types.Hash
+	storage     []map[string][]byte
+	numbers     map[types.Hash]uint64
+	// outcomes are the scripted outcomes of the next submissions, in order.
+	outcomes  []MockOutcome
+	submitted []types.Extrinsic
+	// fee is the partial fee estimated for the extrinsics.
+	fee *big.Int
+}
+
+// NewMockClient creates a new MockClient with a genesis block, and no accounts.
+// It takes the client options, see NewClient, and returns an error if the chain can't be created.
+func NewMockClient(opts ...ClientOption) (*MockClient, error) {
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		return nil, err
+	}
+
+	withDataAvailabilityPallet(&meta, DefaultMaxAppDataLength)
+
+	m := &MockClient{
+		meta:        &meta,
+		specVersion: 1,
+		numbers:     make(map[types.Hash]uint64),
+		fee:         big.NewInt(0),
+	}
+
+	if _, err := m.produceBlock(nil, make(map[string][]byte)); err != nil {
+		return nil, err
+	}
+
+	api := &gsrpc.SubstrateAPI{
+		RPC: &rpc.RPC{
+			State:  &memoryState{m: m},
+			Chain:  &memoryChain{m: m},
+			Author: &memoryAuthor{m: m},
+		},
+		Client: &memoryRPCClient{m: m},
+	}
+
+	connect := func(c *client) {
+		c.dial = func(_ string) (*gsrpc.SubstrateAPI, error) {
+			return api, nil
+		}
+		c.submitAndWatch = func(_ *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+			return m.submit(ext)
+		}
+	}
+
+	c, err := newClient([]string{"mock"}, hclog.NewNullLogger(), append([]ClientOption{connect}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.client = c
+
+	return m, nil
+}
+
+// SetBalance sets the free balance of the account, in Avail fractions, creating the account if it doesn't exist.
+func (m *MockClient) SetBalance(account signature.KeyringPair, free *big.Int) error {
+	return m.updateAccount(account.PublicKey, func(info *types.AccountInfo) {
+		info.Data.Free = types.NewU128(*free)
+	})
+}
+
+// SetNonce sets the nonce of the account on chain, creating the account if it doesn't exist.
+func (m *MockClient) SetNonce(account signature.KeyringPair, nonce uint32) error {
+	return m.updateAccount(account.PublicKey, func(info *types.AccountInfo) {
+		info.Nonce = types.U32(nonce)
+	})
+}
+
+// SetLocks sets the locks on the free balance of the account, creating the account if it doesn't exist. The
+// balances frozen by the locks are updated the way the Balances pallet does.
+func (m *MockClient) SetLocks(account signature.KeyringPair, locks ...BalanceLock) error {
+	raw := make([]balanceLock, len(locks))
+	miscFrozen, feeFrozen := big.NewInt(0), big.NewInt(0)
+
+	for i, lock := range locks {
+		raw[i] = balanceLock{ID: lock.ID, Amount: types.NewU128(*lock.Amount), Reasons: types.U8(lock.Reasons)}
+
+		if lock.Reasons != LockReasonsFee && lock.Amount.Cmp(miscFrozen) > 0 {
+			miscFrozen = lock.Amount
+		}
+
+		if lock.Reasons != LockReasonsMisc && lock.Amount.Cmp(feeFrozen) > 0 {
+			feeFrozen = lock.Amount
+		}
+	}
+
+	encoded, err := codec.Encode(raw)
+	if err != nil {
+		return err
+	}
+
+	err = m.updateAccount(account.PublicKey, func(info *types.AccountInfo) {
+		info.Data.MiscFrozen = types.NewU128(*miscFrozen)
+		info.Data.FreeFrozen = types.NewU128(*feeFrozen)
+	})
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key, err := types.CreateStorageKey(m.meta, "Balances", "Locks", account.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	m.storage[len(m.storage)-1][string(key)] = encoded
+
+	return nil
+}
+
+// SetFee sets the partial fee estimated for the extrinsics, in Avail fractions. The extrinsics are still dispatched
+// without fees.
+func (m *MockClient) SetFee(fee *big.Int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.fee = new(big.Int).Set(fee)
+}
+
+// UpgradeRuntime upgrades the runtime of the chain, bumping its spec version, to the runtime with the metadata, or
+// with the same metadata if it's nil. The metadata of the go-substrate-rpc-client tests needs the DataAvailability
+// pallet added to it.
+func (m *MockClient) UpgradeRuntime(meta *types.Metadata) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.specVersion++
+
+	if meta != nil {
+		m.meta = meta
+	}
+}
+
+// Script sets the outcomes of the next submissions, in order. The submissions without a scripted outcome are
+// included and dispatched successfully.
+func (m *MockClient) Script(outcomes ...MockOutcome) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.outcomes = append(m.outcomes, outcomes...)
+}
+
+// Submitted returns the extrinsics submitted to the chain, in order, including the rejected ones.
+func (m *MockClient) Submitted() []types.Extrinsic {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]types.Extrinsic(nil), m.submitted...)
+}
+
+// Block returns the block of the chain with the number, if any.
+func (m *MockClient) Block(n uint64) (*types.SignedBlock, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if n >= uint64(len(m.blocks)) {
+		return nil, false
+	}
+
+	return m.blocks[n], true
+}
+
+// PruneState discards the state of the blocks below the number, like a non-archive node does, so a storage
+// query at one of them fails. The state of the latest block is always kept.
+func (m *MockClient) PruneState(below uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for n := uint64(0); n < below && n+1 < uint64(len(m.storage)); n++ {
+		m.storage[n] = nil
+	}
+}
+
+// ModuleError returns the dispatch error of the error of the pallet in the metadata of the chain,
+// e.g. ModuleError("Balances", "InsufficientBalance"), or nil if there is no such error.
+func (m *MockClient) ModuleError(pallet, name string) *types.DispatchError {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return moduleError(m.meta, pallet, name)
+}
+
+// updateAccount updates the info of the account with the public key at the head of the chain.
+func (m *MockClient) updateAccount(publicKey []byte, update func(info *types.AccountInfo)) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	storage := m.storage[len(m.storage)-1]
+
+	info, err := m.accountInfo(storage, publicKey)
+	if err != nil {
+		return err
+	}
+
+	update(&info)
+
+	return m.setAccountInfo(storage, publicKey, info)
+}
+
+// submit submits the extrinsic following the next scripted outcome, and returns its status subscription.
+func (m *MockClient) submit(ext types.Extrinsic) (extrinsicSubscription, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.submitted = append(m.submitted, ext)
+
+	var outcome MockOutcome
+	if len(m.outcomes) > 0 {
+		outcome, m.outcomes = m.outcomes[0], m.outcomes[1:]
+	}
+
+	if outcome.Err != nil {
+		return nil, outcome.Err
+	}
+
+	if err := m.checkNonce(ext); err != nil {
+		return nil, err
+	}
+
+	var statuses []types.ExtrinsicStatus
+
+	switch {
+	case outcome.Drop:
+		statuses = []types.ExtrinsicStatus{{IsReady: true}, {IsDropped: true}}
+	case outcome.Invalid:
+		statuses = []types.ExtrinsicStatus{{IsReady: true}, {IsInvalid: true}}
+	default:
+		blockHash, err := m.include(ext, outcome.DispatchError)
+		if err != nil {
+			return nil, err
+		}
+
+		finalizeAfter := outcome.FinalizeAfter
+		if finalizeAfter < 1 {
+			finalizeAfter = 2
+		}
+
+		for i := 1; i < finalizeAfter; i++ {
+			statuses = append(statuses, types.ExtrinsicStatus{IsReady: true})
+		}
+
+		statuses = append(statuses,
+			types.ExtrinsicStatus{IsInBlock: true, AsInBlock: blockHash},
+			types.ExtrinsicStatus{IsFinalized: true, AsFinalized: blockHash},
+		)
+	}
+
+	sub := &memorySubscription{statusCh: make(chan types.ExtrinsicStatus, len(statuses)), errCh: make(chan error)}
+	for _, status := range statuses {
+		sub.statusCh <- status
+	}
+
+	return sub, nil
+}
+
+// checkNonce rejects the signed extrinsic if its nonce was already used by its signer. The lock must be held.
+func (m *MockClient) checkNonce(ext types.Extrinsic) error {
+	if !ext.IsSigned() {
+		return nil
+	}
+
+	info, err := m.accountInfo(m.storage[len(m.storage)-1], ext.Signature.Signer.AsID[:])
+	if err != nil {
+		return err
+	}
+
+	if ext.Signature.Nonce.Int64() < int64(info.Nonce) {
+		return errors.New(mockOutdatedError)
+	}
+
+	return nil
+}
+
+// include includes the extrinsic in a new block, dispatched with the dispatch error if any, and returns the hash
+// of the block. The lock must be held.
+func (m *MockClient) include(ext types.Extrinsic, dispatchError *types.DispatchError) (types.Hash, error) {
+	head := m.storage[len(m.storage)-1]
+
+	storage := make(map[string][]byte, len(head))
+	for key, value := range head {
+		storage[key] = value
+	}
+
+	if ext.IsSigned() {
+		signer := ext.Signature.Signer.AsID[:]
+
+		info, err := m.accountInfo(storage, signer)
+		if err != nil {
+			return types.Hash{}, err
+		}
+
+		info.Nonce = types.U32(ext.Signature.Nonce.Int64() + 1)
+
+		if err := m.setAccountInfo(storage, signer, info); err != nil {
+			return types.Hash{}, err
+		}
+
+		if dispatchError == nil {
+			// The dispatch only changes the storage if it's successful.
+			dispatched := make(map[string][]byte, len(storage))
+			for key, value := range storage {
+				dispatched[key] = value
+			}
+
+			if dispatchError = m.dispatch(dispatched, signer, ext.Method); dispatchError == nil {
+				storage = dispatched
+			}
+		}
+	}
+
+	// The extrinsic is the only one of the block, so its events are the ones of the block.
+	events, err := m.extrinsicEvents(dispatchError)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	eventsKey, err := types.CreateStorageKey(m.meta, "System", "Events", nil)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	storage[string(eventsKey)] = events
+
+	return m.produceBlock([]types.Extrinsic{ext}, storage)
+}
+
+// produceBlock appends the block with the extrinsics, and the storage after them, to the chain, and returns its hash.
+// The lock must be held.
+func (m *MockClient) produceBlock(extrinsics []types.Extrinsic, storage map[string][]byte) (types.Hash, error) {
+	header := types.Header{Number: types.BlockNumber(len(m.blocks))}
+	if len(m.blocks) > 0 {
+		header.ParentHash = m.blockHashes[len(m.blocks)-1]
+	}
+
+	encoded, err := codec.Encode(header)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	hash := types.Hash(blake2b.Sum256(encoded))
+
+	block := &types.SignedBlock{}
+	block.Block.Header = header
+	block.Block.Extrinsics = extrinsics
+
+	m.numbers[hash] = uint64(len(m.blocks))
+	m.blocks = append(m.blocks, block)
+	m.blockHashes = append(m.blockHashes, hash)
+	m.storage = append(m.storage, storage)
+
+	return hash, nil
+}
+
+// dispatch applies the Balances transfers of the call signed by the account to the storage, and returns the
+// dispatch error, if any. The other calls are dispatched without changes.
+func (m *MockClient) dispatch(storage map[string][]byte, signer []byte, call types.Call) *types.DispatchError {
+	name := ""
+
+	for _, callName := range []string{CallTransfer, CallTransferKeepAlive, CallTransferAll} {
+		if index, err := m.meta.FindCallIndex(callName); err == nil && index == call.CallIndex {
+			name = callName
+			break
+		}
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(call.Args))
+
+	var dest types.MultiAddress
+	if err := decoder.Decode(&dest); err != nil || !dest.IsID {
+		return &types.DispatchError{IsOther: true}
+	}
+
+	from, err := m.accountInfo(storage, signer)
+	if err != nil {
+		return &types.DispatchError{IsOther: true}
+	}
+
+	free := u128ToBig(from.Data.Free)
+
+	// The balance frozen by the locks restricting transfers can't be transferred.
+	usable := new(big.Int).Sub(free, u128ToBig(from.Data.MiscFrozen))
+	if usable.Sign() < 0 {
+		usable.SetInt64(0)
+	}
+
+	amount := usable
+	if name != CallTransferAll {
+		var value types.UCompact
+		if err := decoder.Decode(&value); err != nil {
+			return &types.DispatchError{IsOther: true}
+		}
+
+		amount = (*big.Int)(&value)
+	}
+
+	if amount.Cmp(free) > 0 {
+		return moduleError(m.meta, "Balances", "InsufficientBalance")
+	}
+
+	if amount.Cmp(usable) > 0 {
+		return moduleError(m.meta, "Balances", "LiquidityRestrictions")
+	}
+
+	from.Data.Free = types.NewU128(*new(big.Int).Sub(free, amount))
+	if err := m.setAccountInfo(storage, signer, from); err != nil {
+		return &types.DispatchError{IsOther: true}
+	}
+
+	to, err := m.accountInfo(storage, dest.AsID[:])
+	if err != nil {
+		return &types.DispatchError{IsOther: true}
+	}
+
+	to.Data.Free = types.NewU128(*new(big.Int).Add(u128ToBig(to.Data.Free), amount))
+	if err := m.setAccountInfo(storage, dest.AsID[:], to); err != nil {
+		return &types.DispatchError{IsOther: true}
+	}
+
+	return nil
+}
+
+// extrinsicEvents returns the encoded events of a block with a single extrinsic, dispatched with the dispatch error
+// if any.
+func (m *MockClient) extrinsicEvents(dispatchError *types.DispatchError) ([]byte, error) {
+	info := types.DispatchInfo{
+		Weight:  types.NewWeight(types.NewUCompactFromUInt(1000), types.NewUCompactFromUInt(0)),
+		Class:   types.DispatchClass{IsNormal: true},
+		PaysFee: types.Pays{IsYes: true},
+	}
+
+	name, fields := "ExtrinsicSuccess", []interface{}{info}
+	if dispatchError != nil {
+		name, fields = "ExtrinsicFailed", []interface{}{*dispatchError, info}
+	}
+
+	id, err := eventID(m.meta, "System", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single event record, applied by the extrinsic 0, without topics.
+	events := []byte{1 << 2, 0, 0, 0, 0, 0}
+	events = append(events, id[:]...)
+
+	for _, field := range fields {
+		encoded, err := codec.Encode(field)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, encoded...)
+	}
+
+	return append(events, 0), nil
+}
+
+// accountInfo returns the info of the account with the public key in the storage, empty if it doesn't exist.
+func (m *MockClient) accountInfo(storage map[string][]byte, publicKey []byte) (types.AccountInfo, error) {
+	var info types.AccountInfo
+
+	key, err := types.CreateStorageKey(m.meta, "System", "Account", publicKey)
+	if err != nil {
+		return info, err
+	}
+
+	if raw, ok := storage[string(key)]; ok {
+		err = codec.Decode(raw, &info)
+		return info, err
+	}
+
+	info.Data.Free = types.NewU128(*big.NewInt(0))
+	info.Data.Reserved = types.NewU128(*big.NewInt(0))
+	info.Data.MiscFrozen = types.NewU128(*big.NewInt(0))
+	info.Data.FreeFrozen = types.NewU128(*big.NewInt(0))
+
+	return info, nil
+}
+
+// setAccountInfo sets the info of the account with the public key in the storage.
+func (m *MockClient) setAccountInfo(storage map[string][]byte, publicKey []byte, info types.AccountInfo) error {
+	key, err := types.CreateStorageKey(m.meta, "System", "Account", publicKey)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := codec.Encode(info)
+	if err != nil {
+		return err
+	}
+
+	storage[string(key)] = encoded
+
+	return nil
+}
+
+// blockNumber returns the number of the block with the hash. The lock must be held.
+func (m *MockClient) blockNumber(blockHash types.Hash) (uint64, error) {
+	n, ok := m.numbers[blockHash]
+	if !ok {
+		return 0, fmt.Errorf("block %s not found", blockHash.Hex())
+	}
+
+	return n, nil
+}
+
+// getStorage decodes the value of the key in the storage at the block number into the target.
+func (m *MockClient) getStorage(key types.StorageKey, target interface{}, n uint64) (bool, error) {
+	if m.storage[n] == nil {
+		return false, fmt.Errorf("State already discarded for BlockId::Number(%d)", n)
+	}
+
+	raw, ok := m.storage[n][string(key)]
+	if !ok {
+		return false, nil
+	}
+
+	return true, codec.Decode(raw, target)
+}
+
+// eventID returns the ID of the event of the pallet in the metadata.
+func eventID(meta *types.Metadata, pallet, name string) (types.EventID, error) {
+	for _, p := range meta.AsMetadataV14.Pallets {
+		if string(p.Name) != pallet || !p.HasEvents {
+			continue
+		}
+
+		if index, ok := variantIndex(meta, p.Events.Type, name); ok {
+			return types.EventID{byte(p.Index), index}, nil
+		}
+	}
+
+	return types.EventID{}, fmt.Errorf("event %s.%s not found", pallet, name)
+}
+
+// moduleError returns the dispatch error of the error of the pallet in the metadata, or nil if there is no such error.
+func moduleError(meta *types.Metadata, pallet, name string) *types.DispatchError {
+	for _, p := range meta.AsMetadataV14.Pallets {
+		if string(p.Name) != pallet || !p.HasErrors {
+			continue
+		}
+
+		if index, ok := variantIndex(meta, p.Errors.Type, name); ok {
+			return &types.DispatchError{
+				IsModule:    true,
+				ModuleError: types.ModuleError{Index: p.Index, Error: [4]types.U8{types.U8(index)}},
+			}
+		}
+	}
+
+	return nil
+}
+
+// variantIndex returns the index of the named variant of the enum type in the metadata.
+func variantIndex(meta *types.Metadata, typeID types.Si1LookupTypeID, name string) (byte, bool) {
+	t, ok := meta.AsMetadataV14.EfficientLookup[typeID.Int64()]
+	if !ok || !t.Def.IsVariant {
+		return 0, false
+	}
+
+	for _, v := range t.Def.Variant.Variants {
+		if string(v.Name) == name {
+			return byte(v.Index), true
+		}
+	}
+
+	return 0, false
+}
+
+// withDataAvailabilityPallet adds the DataAvailability pallet, with its submit_data call and its MaxAppDataLength
+// constant, to the metadata of the go-substrate-rpc-client tests.
+func withDataAvailabilityPallet(meta *types.Metadata, maxAppDataLength uint32) {
+	callType := types.NewSi1LookupTypeIDFromUInt(1 << 20)
+
+	meta.AsMetadataV14.EfficientLookup[callType.Int64()] = &types.Si1Type{
+		Def: types.Si1TypeDef{
+			IsVariant: true,
+			Variant: types.Si1TypeDefVariant{
+				Variants: []types.Si1Variant{{Name: "submit_data", Index: 1}},
+			},
+		},
+	}
+
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, maxAppDataLength)
+
+	meta.AsMetadataV14.Pallets = append(meta.AsMetadataV14.Pallets, types.PalletMetadataV14{
+		Name:      "DataAvailability",
+		HasCalls:  true,
+		Calls:     types.FunctionMetadataV14{Type: callType},
+		Constants: []types.ConstantMetadataV14{{Name: "MaxAppDataLength", Value: value}},
+		Index:     29,
+	})
+}
+
+// memorySubscription is the status subscription of an extrinsic submitted to a MockClient.
+type memorySubscription struct {
+	statusCh chan types.ExtrinsicStatus
+	errCh    chan error
+}
+
+func (s *memorySubscription) Chan() <-chan types.ExtrinsicStatus { return s.statusCh }
+func (s *memorySubscription) Err() <-chan error                  { return s.errCh }
+func (s *memorySubscription) Unsubscribe()                       {}
+
+// memoryState serves the metadata, the runtime version and the storage of a MockClient.
+// Calls of the methods it doesn't override panic.
+type memoryState struct {
+	state.State
+
+	m *MockClient
+}
+
+func (s *memoryState) GetMetadataLatest() (*types.Metadata, error) {
+	s.m.lock.Lock()
+	defer s.m.lock.Unlock()
+
+	return s.m.meta, nil
+}
+
+func (s *memoryState) GetRuntimeVersionLatest() (*types.RuntimeVersion, error) {
+	s.m.lock.Lock()
+	defer s.m.lock.Unlock()
+
+	return &types.RuntimeVersion{SpecName: "avail", SpecVersion: types.U32(s.m.specVersion), TransactionVersion: 1}, nil
+}
+
+func (s *memoryState) GetStorageLatest(key types.StorageKey, target interface{}) (bool, error) {
+	s.m.lock.Lock()
+	defer s.m.lock.Unlock()
+
+	return s.m.getStorage(key, target, uint64(len(s.m.blocks)-1))
+}
+
+func (s *memoryState) GetStorage(key types.StorageKey, target interface{}, blockHash types.Hash) (bool, error) {
+	s.m.lock.Lock()
+	defer s.m.lock.Unlock()
+
+	n, err := s.m.blockNumber(blockHash)
+	if err != nil {
+		return false, err
+	}
+
+	return s.m.getStorage(key, target, n)
+}
+
+func (s *memoryState) QueryStorageAtLatest(keys []types.StorageKey) ([]types.StorageChangeSet, error) {
+	s.m.lock.Lock()
+	defer s.m.lock.Unlock()
+
+	n := len(s.m.blocks) - 1
+	set := types.StorageChangeSet{Block: s.m.blockHashes[n]}
+
+	for _, key := range keys {
+		raw, ok := s.m.storage[n][string(key)]
+		set.Changes = append(set.Changes, types.KeyValueOption{StorageKey: key, HasStorageData: ok, StorageData: raw})
+	}
+
+	return []types.StorageChangeSet{set}, nil
+}
+
+// memoryChain serves the blocks of a MockClient. Calls of the methods it doesn't override panic.
+type memoryChain struct {
+	chain.Chain
+
+	m *MockClient
+}
+
+func (c *memoryChain) GetBlockHash(n uint64) (types.Hash, error) {
+	c.m.lock.Lock()
+	defer c.m.lock.Unlock()
+
+	if n >= uint64(len(c.m.blocks)) {
+		return types.Hash{}, fmt.Errorf("block %d not found", n)
+	}
+
+	return c.m.blockHashes[n], nil
+}
+
+func (c *memoryChain) GetBlockHashLatest() (types.Hash, error) {
+	c.m.lock.Lock()
+	defer c.m.lock.Unlock()
+
+	return c.m.blockHashes[len(c.m.blockHashes)-1], nil
+}
+
+func (c *memoryChain) GetBlock(blockHash types.Hash) (*types.SignedBlock, error) {
+	c.m.lock.Lock()
+	defer c.m.lock.Unlock()
+
+	n, err := c.m.blockNumber(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.m.blocks[n], nil
+}
+
+func (c *memoryChain) GetHeader(blockHash types.Hash) (*types.Header, error) {
+	block, err := c.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block.Block.Header, nil
+}
+
+func (c *memoryChain) GetHeaderLatest() (*types.Header, error) {
+	c.m.lock.Lock()
+	defer c.m.lock.Unlock()
+
+	return &c.m.blocks[len(c.m.blocks)-1].Block.Header, nil
+}
+
+func (c *memoryChain) GetFinalizedHead() (types.Hash, error) {
+	return c.GetBlockHashLatest()
+}
+
+// memoryAuthor submits the extrinsics to a MockClient. The status subscriptions are served by the submitAndWatch
+// seam of the client, as an *author.ExtrinsicStatusSubscription can't be created outside of gsrpc.
+// Calls of the methods it doesn't override panic.
+type memoryAuthor struct {
+	author.Author
+
+	m *MockClient
+}
+
+func (a *memoryAuthor) SubmitExtrinsic(ext types.Extrinsic) (types.Hash, error) {
+	if _, err := a.m.submit(ext); err != nil {
+		return types.Hash{}, err
+	}
+
+	return hashExtrinsic(ext)
+}
+
+func (a *memoryAuthor) SubmitAndWatchExtrinsic(_ types.Extrinsic) (*author.ExtrinsicStatusSubscription, error) {
+	return nil, fmt.Errorf("author_submitAndWatchExtrinsic %w", errMockUnsupported)
+}
+
+// memoryRPCClient serves the RPC calls of a MockClient made without the gsrpc modules.
+// Calls of the methods it doesn't override panic.
+type memoryRPCClient struct {
+	gsrpcclient.Client
+
+	m *MockClient
+}
+
+func (c *memoryRPCClient) Call(result interface{}, method string, args ...interface{}) error {
+	switch method {
+	case "chain_getHeader":
+		header, err := c.m.header(args)
+		if err != nil {
+			return err
+		}
+
+		return remarshal(header, result)
+	case "kate_queryDataProof":
+		proof, err := c.m.dataProof(args)
+		if err != nil {
+			return err
+		}
+
+		return remarshal(proof, result)
+	case "system_accountNextIndex":
+		nonce, err := c.m.nextIndex(args)
+		if err != nil {
+			return err
+		}
+
+		return remarshal(nonce, result)
+	case "payment_queryInfo":
+		c.m.lock.Lock()
+		fee := c.m.fee.String()
+		c.m.lock.Unlock()
+
+		// The fee is only estimated, the extrinsics are dispatched without fees.
+		return json.Unmarshal([]byte(fmt.Sprintf(`{"partialFee":%q}`, fee)), result)
+	case "system_properties":
+		properties := fmt.Sprintf(`{"ss58Format":%d,"tokenDecimals":%d,"tokenSymbol":"AVL"}`, DefaultSS58Prefix(), DefaultTokenDecimals())
+		return json.Unmarshal([]byte(properties), result)
+	default:
+		return fmt.Errorf("%s %w", method, errMockUnsupported)
+	}
+}
+
+func (c *memoryRPCClient) URL() string { return "mock" }
+func (c *memoryRPCClient) Close()      {}
+
+// nextIndex returns the next nonce of the account of the address argument of system_accountNextIndex. The
+// accepted extrinsics are included right away, so it's the nonce of the account on chain.
+func (m *MockClient) nextIndex(args []interface{}) (uint64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("invalid system_accountNextIndex arguments %v", args)
+	}
+
+	address, ok := args[0].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid system_accountNextIndex address %v", args[0])
+	}
+
+	publicKey, _, err := FromSS58(address)
+	if err != nil {
+		return 0, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	info, err := m.accountInfo(m.storage[len(m.storage)-1], publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(info.Nonce), nil
+}
+
+// header returns the JSON header of the block of the hash argument of chain_getHeader, with the data root of
+// its extension.
+func (m *MockClient) header(args []interface{}) (map[string]interface{}, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	n, err := m.blockArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, _, err := m.dataLeaves(n)
+	if err != nil {
+		return nil, err
+	}
+
+	root, _ := dataRoot(leaves, 0)
+	header := m.blocks[n].Block.Header
+
+	return map[string]interface{}{
+		"parentHash": header.ParentHash,
+		"number":     header.Number,
+		"extension": map[string]interface{}{
+			"V1": map[string]interface{}{
+				"commitment": map[string]interface{}{"rows": 0, "cols": 0, "dataRoot": root, "commitment": []uint16{}},
+			},
+		},
+	}, nil
+}
+
+// dataProof returns the data proof of the extrinsic index and block hash arguments of kate_queryDataProof.
+func (m *MockClient) dataProof(args []interface{}) (*dataProofResponse, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(args) == 0 {
+		return nil, errors.New("missing extrinsic index")
+	}
+
+	index, ok := args[0].(uint32)
+	if !ok {
+		return nil, fmt.Errorf("invalid extrinsic index %v", args[0])
+	}
+
+	n, err := m.blockArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, indexes, err := m.dataLeaves(n)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, extrinsicIndex := range indexes {
+		if extrinsicIndex == index {
+			root, proof := dataRoot(leaves, i)
+
+			return &dataProofResponse{
+				Root:           root,
+				Proof:          proof,
+				NumberOfLeaves: uint32(len(leaves)),
+				LeafIndex:      uint32(i),
+				Leaf:           leaves[i],
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("extrinsic %d of block %d doesn't submit data", index, n)
+}
+
+// blockArg returns the number of the block of the hex hash argument at the position, the latest block if it's
+// missing. The lock must be held.
+func (m *MockClient) blockArg(args []interface{}, position int) (uint64, error) {
+	if len(args) <= position {
+		return uint64(len(m.blocks) - 1), nil
+	}
+
+	hex, ok := args[position].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid block hash %v", args[position])
+	}
+
+	blockHash, err := types.NewHashFromHexString(hex)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.blockNumber(blockHash)
+}
+
+// dataLeaves returns the data root leaves of the submit_data extrinsics of the block, along with their extrinsic
+// indexes. The lock must be held.
+func (m *MockClient) dataLeaves(n uint64) ([]types.Hash, []uint32, error) {
+	callIdx, err := m.meta.FindCallIndex(CallSubmitData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var leaves []types.Hash
+
+	var indexes []uint32
+
+	for i, ext := range m.blocks[n].Block.Extrinsics {
+		if ext.Method.CallIndex != callIdx {
+			continue
+		}
+
+		var data types.Bytes
+		if err := codec.Decode(ext.Method.Args, &data); err != nil {
+			return nil, nil, err
+		}
+
+		leaves = append(leaves, dataLeaf(data))
+		indexes = append(indexes, uint32(i))
+	}
+
+	return leaves, indexes, nil
+}
+
+// remarshal decodes the JSON encoding of the response into the result, the way the RPC responses are.
+func remarshal(response interface{}, result interface{}) error {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, result)
+}
@@ -0,0 +1,429 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/state"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFundedMockClient returns a mock client where the funder has the free balance.
+func newFundedMockClient(t *testing.T, free uint64) (*MockClient, signature.KeyringPair) {
+	m, err := NewMockClient(WithRetryPolicy(testRetryPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funder, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(funder, new(big.Int).SetUint64(free)); err != nil {
+		t.Fatal(err)
+	}
+
+	return m, funder
+}
+
+func TestMockClientDepositBalance(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := DepositBalance(context.Background(), m, funder, recipient, 3*AVL, SubmitOpts{WaitFor: WaitFinalized})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The transfer is included in a new block, and applied.
+	assert.Equal(t, uint64(1), result.BlockNumber)
+	assert.True(t, result.Finalized)
+	assert.Len(t, m.Submitted(), 1)
+
+	balance, err := GetBalance(m, recipient)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(3*AVL), balance)
+
+	data, err := GetAccountData(m, funder)
+	if assert.NoError(t, err) {
+		assert.Equal(t, big.NewInt(7*AVL), data.Free)
+		assert.Equal(t, uint64(1), data.Nonce)
+	}
+
+	header, err := m.GetLatestHeader()
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.BlockNumber(1), header.Number)
+	}
+}
+
+func TestMockClientDispatchFailed(t *testing.T) {
+	m, funder := newFundedMockClient(t, AVL)
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonces := NewNonceManager()
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, 2*AVL, SubmitOpts{Nonces: nonces})
+
+	var dispatchErr *DispatchError
+	if assert.ErrorAs(t, err, &dispatchErr) {
+		assert.Equal(t, "Balances", dispatchErr.Module)
+		assert.Equal(t, "InsufficientBalance", dispatchErr.Name)
+	}
+
+	// The failed transfer used its nonce, but didn't change the balances.
+	data, err := GetAccountData(m, funder)
+	if assert.NoError(t, err) {
+		assert.Equal(t, big.NewInt(AVL), data.Free)
+		assert.Equal(t, uint64(1), data.Nonce)
+	}
+
+	_, err = GetBalance(m, recipient)
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestMockClientScriptedOutcomes(t *testing.T) {
+	errRejected := errors.New("1010: Invalid Transaction: Inability to pay some fees")
+
+	testCases := []struct {
+		name    string
+		outcome MockOutcome
+		err     error
+	}{
+		{"rejected", MockOutcome{Err: errRejected}, errRejected},
+		{"dropped", MockOutcome{Drop: true}, ErrExtrinsicDropped},
+		{"invalid", MockOutcome{Invalid: true}, ErrExtrinsicInvalid},
+		{"finalized after statuses", MockOutcome{FinalizeAfter: 4}, nil},
+		{"dispatch error", MockOutcome{DispatchError: &types.DispatchError{IsBadOrigin: true}}, ErrExtrinsicFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, funder := newFundedMockClient(t, 10*AVL)
+			m.Script(tc.outcome)
+
+			result, err := SubmitData(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{WaitFor: WaitFinalized})
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.True(t, result.Finalized)
+			}
+
+			assert.Len(t, m.Submitted(), 1)
+		})
+	}
+}
+
+func TestMockClientRejectsOutdatedNonce(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+	m.retry.MaxNonceRetries = 1
+
+	nonces := NewNonceManager()
+
+	_, err := SubmitData(context.Background(), m, funder, 1, []byte("a"), SubmitOpts{Nonces: nonces})
+	assert.NoError(t, err)
+
+	// The nonce handed out next was used outside the nonce manager, so the extrinsic is re-signed.
+	if err := m.SetNonce(funder, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := SubmitData(context.Background(), m, funder, 1, []byte("b"), SubmitOpts{Nonces: nonces})
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(2), result.Nonce)
+	}
+
+	submitted := m.Submitted()
+	if assert.Len(t, submitted, 3) {
+		assert.Equal(t, types.NewUCompactFromUInt(1), submitted[1].Signature.Nonce)
+	}
+}
+
+func TestMockClientBlocks(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	result, err := SubmitData(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	block, ok := m.Block(1)
+	if assert.True(t, ok) {
+		assert.Equal(t, m.GenesisHash(), block.Block.Header.ParentHash)
+		assert.Equal(t, []types.Extrinsic{m.Submitted()[0]}, block.Block.Extrinsics)
+	}
+
+	blockHash, err := m.api.RPC.Chain.GetBlockHash(1)
+	assert.NoError(t, err)
+	assert.Equal(t, result.BlockHash, blockHash)
+
+	_, ok = m.Block(2)
+	assert.False(t, ok)
+}
+
+func TestMockClientFeeAndUpgrade(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+	m.SetFee(big.NewInt(1000))
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fee is estimated, but not paid.
+	budget := NewFeeBudget(nil, nil, time.Hour)
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{FeeBudget: budget})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), budget.Usage().Spent.Int64())
+
+	balance, err := GetBalance(m, funder)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).SetUint64(9*AVL), balance)
+
+	// The upgraded runtime is served with a bumped spec version.
+	m.UpgradeRuntime(nil)
+
+	rv, err := m.instance().RPC.State.GetRuntimeVersionLatest()
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.U32(2), rv.SpecVersion)
+	}
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{})
+	assert.NoError(t, err)
+}
+
+// produceBlock appends a block with the extrinsics to the chain of the mock client, without dispatching them, and
+// returns its hash.
+func produceBlock(t *testing.T, m *MockClient, extrinsics ...types.Extrinsic) types.Hash {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	head := m.storage[len(m.storage)-1]
+
+	storage := make(map[string][]byte, len(head))
+	for key, value := range head {
+		storage[key] = value
+	}
+
+	blockHash, err := m.produceBlock(extrinsics, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return blockHash
+}
+
+// blockHashOf returns the hash of the block of the mock client with the number.
+func blockHashOf(t *testing.T, m *MockClient, n uint64) types.Hash {
+	blockHash, err := m.api.RPC.Chain.GetBlockHash(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return blockHash
+}
+
+// mockCalls are the calls recorded by a mockRecorder.
+type mockCalls struct {
+	metadataFetches       int
+	runtimeVersionFetches int
+	// lookups are the keys of the storage lookups at the head, and queries the number of storage queries of
+	// several keys.
+	lookups []types.StorageKey
+	queries int
+	// nonceLookups are the addresses of the system_accountNextIndex calls.
+	nonceLookups []string
+	// blockHashes are the numbers of the requested block hashes.
+	blockHashes []uint64
+}
+
+// mockRecorder records the calls to a mock client, which doesn't record them itself, and fails its storage
+// lookups with storageErr, which it can't be scripted to. The changes of the storage queries are served in
+// reverse order, like a node is free to.
+type mockRecorder struct {
+	lock       sync.Mutex
+	calls      mockCalls
+	storageErr error
+}
+
+// recordMockCalls wraps the state, the chain and the RPC client of the mock client with a recorder.
+func recordMockCalls(m *MockClient) *mockRecorder {
+	r := &mockRecorder{}
+
+	api := m.instance()
+	api.RPC.State = &recordingState{State: api.RPC.State, r: r}
+	api.RPC.Chain = &recordingChain{Chain: api.RPC.Chain, r: r}
+	api.Client = &recordingRPCClient{Client: api.Client, r: r}
+
+	return r
+}
+
+// recorded returns the calls recorded so far.
+func (r *mockRecorder) recorded() mockCalls {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	calls := r.calls
+	calls.lookups = append([]types.StorageKey(nil), r.calls.lookups...)
+	calls.nonceLookups = append([]string(nil), r.calls.nonceLookups...)
+	calls.blockHashes = append([]uint64(nil), r.calls.blockHashes...)
+
+	return calls
+}
+
+// reset forgets the calls recorded so far.
+func (r *mockRecorder) reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.calls = mockCalls{}
+}
+
+// failStorage fails the storage lookups and queries with the error, or serves them again if it's nil.
+func (r *mockRecorder) failStorage(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.storageErr = err
+}
+
+// record records a call with the function, and returns the storage error.
+func (r *mockRecorder) record(call func(calls *mockCalls)) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	call(&r.calls)
+
+	return r.storageErr
+}
+
+type recordingState struct {
+	state.State
+
+	r *mockRecorder
+}
+
+func (s *recordingState) GetMetadataLatest() (*types.Metadata, error) {
+	s.r.record(func(calls *mockCalls) { calls.metadataFetches++ })
+	return s.State.GetMetadataLatest()
+}
+
+func (s *recordingState) GetRuntimeVersionLatest() (*types.RuntimeVersion, error) {
+	s.r.record(func(calls *mockCalls) { calls.runtimeVersionFetches++ })
+	return s.State.GetRuntimeVersionLatest()
+}
+
+func (s *recordingState) GetStorageLatest(key types.StorageKey, target interface{}) (bool, error) {
+	if err := s.r.record(func(calls *mockCalls) { calls.lookups = append(calls.lookups, key) }); err != nil {
+		return false, err
+	}
+
+	return s.State.GetStorageLatest(key, target)
+}
+
+func (s *recordingState) QueryStorageAtLatest(keys []types.StorageKey) ([]types.StorageChangeSet, error) {
+	if err := s.r.record(func(calls *mockCalls) { calls.queries++ }); err != nil {
+		return nil, err
+	}
+
+	sets, err := s.State.QueryStorageAtLatest(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, set := range sets {
+		for i, j := 0, len(set.Changes)-1; i < j; i, j = i+1, j-1 {
+			set.Changes[i], set.Changes[j] = set.Changes[j], set.Changes[i]
+		}
+	}
+
+	return sets, nil
+}
+
+type recordingChain struct {
+	chain.Chain
+
+	r *mockRecorder
+}
+
+func (c *recordingChain) GetBlockHash(n uint64) (types.Hash, error) {
+	c.r.record(func(calls *mockCalls) { calls.blockHashes = append(calls.blockHashes, n) })
+	return c.Chain.GetBlockHash(n)
+}
+
+type recordingRPCClient struct {
+	gsrpcclient.Client
+
+	r *mockRecorder
+}
+
+func (c *recordingRPCClient) Call(result interface{}, method string, args ...interface{}) error {
+	if method == "system_accountNextIndex" {
+		address, _ := args[0].(string)
+		if err := c.r.record(func(calls *mockCalls) { calls.nonceLookups = append(calls.nonceLookups, address) }); err != nil {
+			return err
+		}
+	}
+
+	return c.Client.Call(result, method, args...)
+}
+
+// lagFinality makes the finalized head of the mock client lag behind its head by the number of blocks, as it
+// finalizes its blocks right away. The lag can be changed through the returned chain.
+func lagFinality(m *MockClient, lag uint64) *laggingChain {
+	api := m.instance()
+	ch := &laggingChain{Chain: api.RPC.Chain, lag: lag}
+	api.RPC.Chain = ch
+
+	return ch
+}
+
+type laggingChain struct {
+	chain.Chain
+
+	lock sync.Mutex
+	lag  uint64
+}
+
+// setLag sets the number of blocks the finalized head lags behind the head.
+func (c *laggingChain) setLag(lag uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lag = lag
+}
+
+func (c *laggingChain) GetFinalizedHead() (types.Hash, error) {
+	head, err := c.GetHeaderLatest()
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	c.lock.Lock()
+	lag := c.lag
+	c.lock.Unlock()
+
+	finalized := uint64(0)
+	if uint64(head.Number) > lag {
+		finalized = uint64(head.Number) - lag
+	}
+
+	return c.GetBlockHash(finalized)
+}
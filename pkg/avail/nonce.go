@@ -0,0 +1,142 @@
+package avail
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// staleNonceErrors are the messages of the Avail transaction pool errors caused by an outdated nonce,
+// or by a nonce already taken by an extrinsic in the pool.
+var staleNonceErrors = []string{
+	"Transaction is outdated",
+	"Priority is too low",
+}
+
+// NonceManager hands out the nonces of the extrinsics submitted to Avail.
+// The next nonce of an account is fetched from the node once, with system_accountNextIndex, which counts the
+// extrinsics of the account still in the transaction pool, and then incremented locally for every extrinsic, so
+// the extrinsics submitted in a row don't wait for each other. A nonce handed out for an extrinsic that isn't
+// accepted by the transaction pool is given back with a resync, so the next one is fetched from the node again.
+// It is safe for concurrent use; an account should only be used with a single NonceManager.
+type NonceManager struct {
+	lock  sync.Mutex
+	nonce map[string]uint64
+}
+
+// NewNonceManager creates a new instance of NonceManager.
+func NewNonceManager() *NonceManager {
+	return &NonceManager{
+		nonce: make(map[string]uint64),
+	}
+}
+
+// Next returns the nonce of the next extrinsic signed by the account, fetching it from the node on the first call
+// and after a resync. It returns an error if there is an issue.
+func (nm *NonceManager) Next(client Client, account signature.KeyringPair) (uint64, error) {
+	api, err := instance(client)
+	if err != nil {
+		return 0, err
+	}
+
+	return nm.next(api, account)
+}
+
+// Resync drops the local nonce of the account, so the next one is fetched from the node again.
+func (nm *NonceManager) Resync(account signature.KeyringPair) {
+	if nm == nil {
+		return
+	}
+
+	nm.lock.Lock()
+	defer nm.lock.Unlock()
+
+	delete(nm.nonce, string(account.PublicKey))
+}
+
+// next returns the nonce of the next extrinsic signed by the account. A nil NonceManager fetches it from the node
+// on every call.
+func (nm *NonceManager) next(api *gsrpc.SubstrateAPI, account signature.KeyringPair) (uint64, error) {
+	if nm == nil {
+		return accountNextIndex(api, account)
+	}
+
+	// The lock is held during the lookup, so concurrent callers don't fetch the same nonce.
+	nm.lock.Lock()
+	defer nm.lock.Unlock()
+
+	nonce, ok := nm.nonce[string(account.PublicKey)]
+	if !ok {
+		var err error
+		if nonce, err = accountNextIndex(api, account); err != nil {
+			return 0, err
+		}
+	}
+
+	nm.nonce[string(account.PublicKey)] = nonce + 1
+
+	return nonce, nil
+}
+
+// set sets the nonce of the next extrinsic signed by the account, e.g. to take back the nonces handed out for
+// extrinsics that weren't submitted.
+func (nm *NonceManager) set(account signature.KeyringPair, nonce uint64) {
+	nm.lock.Lock()
+	defer nm.lock.Unlock()
+
+	nm.nonce[string(account.PublicKey)] = nonce
+}
+
+// IsStaleNonceError checks whether the extrinsic submission error was caused by an outdated nonce,
+// or by a nonce already taken by another extrinsic of the account. The error of an extrinsic already in
+// the transaction pool isn't one, as the nonce is taken by the extrinsic itself.
+func IsStaleNonceError(err error) bool {
+	if err == nil || strings.Contains(err.Error(), alreadyImportedError) {
+		return false
+	}
+
+	for _, msg := range staleNonceErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// accountNextIndex fetches the next nonce of the account with system_accountNextIndex, i.e. its nonce on chain
+// followed by the nonces of its extrinsics in the transaction pool of the node.
+func accountNextIndex(api *gsrpc.SubstrateAPI, account signature.KeyringPair) (uint64, error) {
+	var nonce uint64
+	if err := api.Client.Call(&nonce, "system_accountNextIndex", account.Address); err != nil {
+		return 0, fmt.Errorf("couldn't fetch the next nonce of %s: %w", account.Address, err)
+	}
+
+	return nonce, nil
+}
+
+// chainNonce fetches the nonce of the account from its storage on chain, which doesn't count its extrinsics in the
+// transaction pool, e.g. to tell whether the nonce of an extrinsic was used by an included one.
+func chainNonce(r *rpc.RPC, meta *types.Metadata, account signature.KeyringPair) (uint64, error) {
+	key, err := accountStorageKey(meta, account)
+	if err != nil {
+		return 0, err
+	}
+
+	var accountInfo types.AccountInfo
+	ok, err := r.State.GetStorageLatest(key, &accountInfo)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't fetch latest account storage info: %w", err)
+	}
+
+	if !ok {
+		return 0, fmt.Errorf("couldn't fetch latest account storage info: %w: %s", ErrAccountNotFound, account.Address)
+	}
+
+	return uint64(accountInfo.Nonce), nil
+}
@@ -0,0 +1,181 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonceManagerSequential(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, funder := newFundedMockClient(t, 15*AVL)
+
+	if err := m.SetNonce(funder, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+	nonces := NewNonceManager()
+
+	deposit := func() error {
+		_, err := DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{Nonces: nonces})
+		return err
+	}
+
+	lastNonce := func() types.UCompact {
+		submitted := m.Submitted()
+		return submitted[len(submitted)-1].Signature.Nonce
+	}
+
+	// The nonce is fetched from the node once, and incremented locally.
+	for _, expected := range []uint64{5, 6, 7} {
+		assert.NoError(t, deposit())
+		assert.Equal(t, types.NewUCompactFromUInt(expected), lastNonce())
+	}
+
+	assert.Len(t, r.recorded().nonceLookups, 1)
+
+	// A submission failing before the transaction pool accepts it, e.g. on a connection error, gives its nonce
+	// back, so the next one is fetched from the node again.
+	m.Script(MockOutcome{Err: errSubmitStopped})
+
+	assert.ErrorIs(t, deposit(), errSubmitStopped)
+	assert.Equal(t, types.NewUCompactFromUInt(8), lastNonce())
+
+	nonce, err := nonces.Next(m, funder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), nonce)
+	assert.Len(t, r.recorded().nonceLookups, 2)
+
+	// So does a stale nonce.
+	nonces.set(funder, 3)
+
+	if err := m.SetNonce(funder, 12); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Error(t, deposit())
+	assert.Equal(t, types.NewUCompactFromUInt(3), lastNonce())
+
+	nonce, err = nonces.Next(m, funder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), nonce)
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	if err := m.SetNonce(account, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	r := recordMockCalls(m)
+	nonces := NewNonceManager()
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		seen = make(map[uint64]bool)
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			nonce, err := nonces.Next(m, account)
+			assert.NoError(t, err)
+
+			lock.Lock()
+			seen[nonce] = true
+			lock.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, seen, 20)
+	for nonce := uint64(3); nonce < 23; nonce++ {
+		assert.True(t, seen[nonce], nonce)
+	}
+
+	assert.Len(t, r.recorded().nonceLookups, 1)
+}
+
+func TestConcurrentDeposits(t *testing.T) {
+	m, funder := newFundedMockClient(t, 15*AVL)
+
+	const deposits = 10
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < deposits; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			recipient, err := NewAccount()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, err = DepositAmount(context.Background(), m, funder, recipient, new(big.Int).SetUint64(AVL), SubmitOpts{})
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	// Each deposit is accepted with its own nonce, none being rejected and resubmitted.
+	submitted := m.Submitted()
+	assert.Len(t, submitted, deposits)
+
+	seen := make(map[int64]bool)
+	for _, ext := range submitted {
+		seen[ext.Signature.Nonce.Int64()] = true
+	}
+
+	for nonce := int64(0); nonce < deposits; nonce++ {
+		assert.True(t, seen[nonce], nonce)
+	}
+
+	// A deposit whose nonce was used by another process is resubmitted with the nonce of the chain.
+	m.retry.MaxNonceRetries = 1
+
+	if err := m.SetNonce(funder, deposits+2); err != nil {
+		t.Fatal(err)
+	}
+
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DepositAmount(context.Background(), m, funder, recipient, new(big.Int).SetUint64(AVL), SubmitOpts{})
+	assert.NoError(t, err)
+
+	submitted = m.Submitted()
+	if assert.Len(t, submitted, deposits+2) {
+		assert.Equal(t, int64(deposits), submitted[deposits].Signature.Nonce.Int64())
+		assert.Equal(t, int64(deposits+2), submitted[deposits+1].Signature.Nonce.Int64())
+	}
+}
+
+func TestIsStaleNonceError(t *testing.T) {
+	assert.True(t, IsStaleNonceError(errors.New("1010: Invalid Transaction: Transaction is outdated")))
+	assert.True(t, IsStaleNonceError(errors.New("1014: Priority is too low: (140 vs 140)")))
+	assert.False(t, IsStaleNonceError(errors.New("1013: Transaction Already Imported")))
+	assert.False(t, IsStaleNonceError(errSubmitStopped))
+	assert.False(t, IsStaleNonceError(nil))
+}
@@ -0,0 +1,264 @@
+package avail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	schnorrkel "github.com/ChainSafe/go-schnorrkel"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/vedhavyas/go-subkey"
+	"github.com/vedhavyas/go-subkey/sr25519"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	polkadotKeystoreVersion = "3"
+
+	polkadotScryptN = 1 << 15
+	polkadotScryptP = 1
+	polkadotScryptR = 8
+
+	polkadotSaltLength  = 32
+	polkadotNonceLength = 24
+)
+
+var (
+	// ErrUnsupportedKeystore is the error returned when a polkadot-js keystore uses a version, an encoding
+	// or a key type other than the version 3 scrypt and xsalsa20-poly1305 encrypted sr25519 PKCS8 one.
+	ErrUnsupportedKeystore = errors.New("unsupported polkadot-js keystore")
+
+	polkadotKeystoreContent = []string{"pkcs8", "sr25519"}
+	polkadotKeystoreType    = []string{"scrypt", "xsalsa20-poly1305"}
+
+	// polkadotPKCS8Header and polkadotPKCS8Divider frame the secret and the public key in the PKCS8 plaintext.
+	polkadotPKCS8Header  = []byte{48, 83, 2, 1, 1, 48, 5, 6, 3, 43, 101, 112, 4, 34, 4, 32}
+	polkadotPKCS8Divider = []byte{161, 35, 3, 33, 0}
+)
+
+// polkadotKeystoreEncoding is the encoding section of a polkadot-js keystore.
+type polkadotKeystoreEncoding struct {
+	Content []string `json:"content"`
+	Type    []string `json:"type"`
+	Version string   `json:"version"`
+}
+
+// polkadotKeystore is the JSON account file exported by polkadot-js and its browser extension.
+type polkadotKeystore struct {
+	Encoded  string                   `json:"encoded"`
+	Encoding polkadotKeystoreEncoding `json:"encoding"`
+	Address  string                   `json:"address"`
+	Meta     map[string]interface{}   `json:"meta"`
+}
+
+// ImportPolkadotKeystore decrypts a polkadot-js sr25519 keystore, e.g. exported from the browser extension, with the passphrase.
+// It returns the key pair, and an error wrapping ErrUnsupportedKeystore if the keystore isn't a scrypt and
+// xsalsa20-poly1305 encrypted sr25519 one, ErrWrongPassphrase if the passphrase doesn't match, or
+// ErrCorruptAccountFile if the keystore is malformed.
+// The key pair URI is the hex encoded secret key, as the keystore doesn't hold the mnemonic.
+func ImportPolkadotKeystore(data []byte, passphrase string) (signature.KeyringPair, error) {
+	var keystore polkadotKeystore
+	if err := json.Unmarshal(data, &keystore); err != nil {
+		return signature.KeyringPair{}, fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	encoding := keystore.Encoding
+	if encoding.Version != polkadotKeystoreVersion || !equalStrings(encoding.Content, polkadotKeystoreContent) || !equalStrings(encoding.Type, polkadotKeystoreType) {
+		return signature.KeyringPair{}, fmt.Errorf("%w: version %q, content %q, type %q", ErrUnsupportedKeystore, encoding.Version, encoding.Content, encoding.Type)
+	}
+
+	encoded, err := base64.StdEncoding.DecodeString(keystore.Encoded)
+	if err != nil {
+		return signature.KeyringPair{}, fmt.Errorf("%w: invalid encoded: %s", ErrCorruptAccountFile, err)
+	}
+
+	paramsLength := polkadotSaltLength + 12
+	if len(encoded) < paramsLength+polkadotNonceLength+secretbox.Overhead {
+		return signature.KeyringPair{}, fmt.Errorf("%w: encoded is too short", ErrCorruptAccountFile)
+	}
+
+	salt := encoded[:polkadotSaltLength]
+	n := binary.LittleEndian.Uint32(encoded[polkadotSaltLength:])
+	p := binary.LittleEndian.Uint32(encoded[polkadotSaltLength+4:])
+	r := binary.LittleEndian.Uint32(encoded[polkadotSaltLength+8:])
+
+	if n > maxKeystoreScryptN || p > 16 || r > 16 {
+		return signature.KeyringPair{}, fmt.Errorf("%w: scrypt parameters n=%d, p=%d, r=%d are too high", ErrCorruptAccountFile, n, p, r)
+	}
+
+	encryptionKey, err := scrypt.Key([]byte(passphrase), salt, int(n), int(r), int(p), 32)
+	if err != nil {
+		return signature.KeyringPair{}, fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	var key [32]byte
+	copy(key[:], encryptionKey)
+
+	var nonce [polkadotNonceLength]byte
+	copy(nonce[:], encoded[paramsLength:])
+
+	// Unlike the node account files, the keystore has no passphrase check, so a failing
+	// authentication is reported as a wrong passphrase.
+	plaintext, ok := secretbox.Open(nil, encoded[paramsLength+polkadotNonceLength:], &nonce, &key)
+	if !ok {
+		return signature.KeyringPair{}, ErrWrongPassphrase
+	}
+
+	secret, publicKey, err := decodePolkadotPKCS8(plaintext)
+	if err != nil {
+		return signature.KeyringPair{}, err
+	}
+
+	if keystore.Address != "" {
+		if addressKey, _, err := FromSS58(keystore.Address); err != nil || !bytes.Equal(addressKey, publicKey) {
+			return signature.KeyringPair{}, fmt.Errorf("%w: address %s doesn't match the public key", ErrCorruptAccountFile, keystore.Address)
+		}
+	}
+
+	// polkadot-js stores the secret scalar in the ed25519 format, i.e. multiplied by the cofactor.
+	var scalar, secretNonce [32]byte
+	copy(scalar[:], divideScalarByCofactor(secret[:32]))
+	copy(secretNonce[:], secret[32:])
+
+	derivedPublicKey, err := schnorrkel.NewSecretKey(scalar, secretNonce).Public()
+	if err != nil {
+		return signature.KeyringPair{}, fmt.Errorf("%w: %s", ErrCorruptAccountFile, err)
+	}
+
+	if encodedPublicKey := derivedPublicKey.Encode(); !bytes.Equal(encodedPublicKey[:], publicKey) {
+		return signature.KeyringPair{}, fmt.Errorf("%w: secret key doesn't match the public key", ErrCorruptAccountFile)
+	}
+
+	keyPair, err := signature.KeyringPairFromSecret(subkey.EncodeHex(append(scalar[:], secretNonce[:]...)), 42)
+	if err != nil {
+		return signature.KeyringPair{}, err
+	}
+
+	return withSS58Prefix(keyPair, DefaultSS58Prefix())
+}
+
+// ExportPolkadotKeystore encrypts the key pair with the passphrase into a polkadot-js keystore, which can be imported
+// in the browser extension. The mnemonic is the secret URI the key pair was generated from; a URI with a soft
+// derivation junction can't be exported, as it has no mini secret key to expand.
+// It returns the JSON keystore and an error if the mnemonic doesn't produce the key pair, or if there is an issue.
+func ExportPolkadotKeystore(pair signature.KeyringPair, mnemonic, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("keystore passphrase must not be empty")
+	}
+
+	keyPair, err := subkey.DeriveKeyPair(sr25519.Scheme{}, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(keyPair.Public(), pair.PublicKey) {
+		return nil, errors.New("mnemonic doesn't match the key pair")
+	}
+
+	seed := keyPair.Seed()
+	if len(seed) != schnorrkel.MiniSecretKeySize {
+		return nil, errors.New("key pair derived with a soft junction can't be exported")
+	}
+
+	// The ed25519 format of the secret is the clamped SHA-512 expansion of the mini secret key.
+	secret := sha512.Sum512(seed)
+	secret[0] &= 248
+	secret[31] &= 63
+	secret[31] |= 64
+
+	plaintext := make([]byte, 0, len(polkadotPKCS8Header)+len(secret)+len(polkadotPKCS8Divider)+len(pair.PublicKey))
+	plaintext = append(plaintext, polkadotPKCS8Header...)
+	plaintext = append(plaintext, secret[:]...)
+	plaintext = append(plaintext, polkadotPKCS8Divider...)
+	plaintext = append(plaintext, pair.PublicKey...)
+
+	encoded := make([]byte, polkadotSaltLength+12, polkadotSaltLength+12+polkadotNonceLength+secretbox.Overhead+len(plaintext))
+	if _, err := rand.Read(encoded[:polkadotSaltLength]); err != nil {
+		return nil, err
+	}
+
+	binary.LittleEndian.PutUint32(encoded[polkadotSaltLength:], polkadotScryptN)
+	binary.LittleEndian.PutUint32(encoded[polkadotSaltLength+4:], polkadotScryptP)
+	binary.LittleEndian.PutUint32(encoded[polkadotSaltLength+8:], polkadotScryptR)
+
+	encryptionKey, err := scrypt.Key([]byte(passphrase), encoded[:polkadotSaltLength], polkadotScryptN, polkadotScryptR, polkadotScryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [32]byte
+	copy(key[:], encryptionKey)
+
+	var nonce [polkadotNonceLength]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+
+	encoded = append(encoded, nonce[:]...)
+	encoded = secretbox.Seal(encoded, plaintext, &nonce, &key)
+
+	keystore := polkadotKeystore{
+		Encoded: base64.StdEncoding.EncodeToString(encoded),
+		Encoding: polkadotKeystoreEncoding{
+			Content: polkadotKeystoreContent,
+			Type:    polkadotKeystoreType,
+			Version: polkadotKeystoreVersion,
+		},
+		Address: pair.Address,
+		Meta: map[string]interface{}{
+			"genesisHash": "",
+			"name":        "op-evm",
+			"whenCreated": time.Now().UnixMilli(),
+		},
+	}
+
+	return json.Marshal(keystore)
+}
+
+// decodePolkadotPKCS8 returns the 64 bytes secret key and the public key held in the PKCS8 plaintext of a keystore.
+func decodePolkadotPKCS8(plaintext []byte) ([]byte, []byte, error) {
+	secretEnd := len(polkadotPKCS8Header) + 64
+	dividerEnd := secretEnd + len(polkadotPKCS8Divider)
+
+	if len(plaintext) != dividerEnd+32 ||
+		!bytes.Equal(plaintext[:len(polkadotPKCS8Header)], polkadotPKCS8Header) ||
+		!bytes.Equal(plaintext[secretEnd:dividerEnd], polkadotPKCS8Divider) {
+		return nil, nil, fmt.Errorf("%w: invalid PKCS8 key", ErrCorruptAccountFile)
+	}
+
+	return plaintext[len(polkadotPKCS8Header):secretEnd], plaintext[dividerEnd:], nil
+}
+
+// divideScalarByCofactor divides the little-endian scalar by the cofactor 8 in place.
+func divideScalarByCofactor(s []byte) []byte {
+	low := byte(0)
+	for i := len(s) - 1; i >= 0; i-- {
+		r := s[i] & 0x07
+		s[i] >>= 3
+		s[i] += low
+		low = r << 5
+	}
+
+	return s
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
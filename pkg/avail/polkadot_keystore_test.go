@@ -0,0 +1,121 @@
+package avail
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/vedhavyas/go-subkey"
+)
+
+const polkadotFixturePassphrase = "testing"
+
+func readPolkadotFixture(t *testing.T) []byte {
+	data, err := os.ReadFile("testdata/polkadot_keystore_alice.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestImportPolkadotKeystore(t *testing.T) {
+	pair, err := ImportPolkadotKeystore(readPolkadotFixture(t), polkadotFixturePassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, signature.TestKeyringPairAlice.PublicKey, pair.PublicKey)
+	assert.Equal(t, signature.TestKeyringPairAlice.Address, pair.Address)
+
+	// The imported secret signs for the same account.
+	message := []byte("op-evm")
+	sig, err := signature.Sign(message, pair.URI)
+	assert.NoError(t, err)
+
+	ok, err := signature.Verify(message, sig, signature.TestKeyringPairAlice.URI)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = ImportPolkadotKeystore(readPolkadotFixture(t), "wrong")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestImportPolkadotKeystoreUnsupported(t *testing.T) {
+	tamper := func(fn func(keystore *polkadotKeystore)) []byte {
+		var keystore polkadotKeystore
+		assert.NoError(t, json.Unmarshal(readPolkadotFixture(t), &keystore))
+
+		fn(&keystore)
+
+		data, err := json.Marshal(keystore)
+		assert.NoError(t, err)
+
+		return data
+	}
+
+	unsupported := map[string][]byte{
+		"ed25519": tamper(func(k *polkadotKeystore) { k.Encoding.Content = []string{"pkcs8", "ed25519"} }),
+		"ecdsa":   tamper(func(k *polkadotKeystore) { k.Encoding.Content = []string{"pkcs8", "ecdsa"} }),
+		"version": tamper(func(k *polkadotKeystore) { k.Encoding.Version = "2" }),
+		"none":    tamper(func(k *polkadotKeystore) { k.Encoding.Type = []string{"none"} }),
+	}
+
+	for name, data := range unsupported {
+		_, err := ImportPolkadotKeystore(data, polkadotFixturePassphrase)
+		assert.ErrorIs(t, err, ErrUnsupportedKeystore, name)
+	}
+
+	corrupt := map[string][]byte{
+		"address": tamper(func(k *polkadotKeystore) { k.Address = signature.TestKeyringPairAlice.Address[:10] }),
+		"encoded": tamper(func(k *polkadotKeystore) { k.Encoded = k.Encoded[:40] }),
+		"json":    []byte("{"),
+	}
+
+	for name, data := range corrupt {
+		_, err := ImportPolkadotKeystore(data, polkadotFixturePassphrase)
+		assert.ErrorIs(t, err, ErrCorruptAccountFile, name)
+	}
+}
+
+func TestExportPolkadotKeystore(t *testing.T) {
+	fixture, err := ImportPolkadotKeystore(readPolkadotFixture(t), polkadotFixturePassphrase)
+	assert.NoError(t, err)
+
+	aliceURI := subkey.DevPhrase + "//Alice"
+
+	data, err := ExportPolkadotKeystore(signature.TestKeyringPairAlice, aliceURI, "correct horse")
+	assert.NoError(t, err)
+
+	var keystore polkadotKeystore
+	assert.NoError(t, json.Unmarshal(data, &keystore))
+	assert.Equal(t, polkadotKeystoreEncoding{Content: polkadotKeystoreContent, Type: polkadotKeystoreType, Version: "3"}, keystore.Encoding)
+	assert.Equal(t, signature.TestKeyringPairAlice.Address, keystore.Address)
+
+	// The exported secret is the one the fixture holds.
+	pair, err := ImportPolkadotKeystore(data, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, fixture, pair)
+
+	// A hard derived account has a mini secret key and is exported too.
+	stash, err := NewAccountFromURI(aliceURI+"//stash", 42)
+	assert.NoError(t, err)
+
+	data, err = ExportPolkadotKeystore(stash, stash.URI, "correct horse")
+	assert.NoError(t, err)
+
+	pair, err = ImportPolkadotKeystore(data, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, stash.PublicKey, pair.PublicKey)
+
+	soft, err := NewAccountFromURI(aliceURI+"/soft", 42)
+	assert.NoError(t, err)
+
+	_, err = ExportPolkadotKeystore(soft, soft.URI, "correct horse")
+	assert.Error(t, err)
+
+	_, err = ExportPolkadotKeystore(stash, aliceURI, "correct horse")
+	assert.Error(t, err)
+
+	_, err = ExportPolkadotKeystore(stash, stash.URI, "")
+	assert.Error(t, err)
+}
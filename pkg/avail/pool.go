@@ -0,0 +1,205 @@
+package avail
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// pooledConn is an extra connection of the pool, to the endpoint of the index.
+type pooledConn struct {
+	api      *gsrpc.SubstrateAPI
+	endpoint int
+}
+
+// WithConnections opens n connections to the active endpoint, the read-only calls fetching blocks being distributed
+// across them, e.g. by GetBlockRange, the block follower backfilling missed blocks and the block stream catching up.
+// The subscriptions and the extrinsic submissions stay pinned to the first one. The pooled connections are
+// health-checked at the health check interval, and replaced when they fail or the client fails over to another
+// endpoint. There is a single connection by default.
+func WithConnections(n int) ClientOption {
+	return func(c *client) {
+		if n > 1 {
+			c.pool = make([]pooledConn, n-1)
+		}
+	}
+}
+
+// GetBlockRange fetches the finalized blocks from one number to another, both included, with one request in flight
+// per connection of the client, see WithConnections.
+// It takes a client, and the numbers of the first and the last blocks.
+// It returns the blocks in order, and an error if the range is empty, or if a block can't be fetched.
+func GetBlockRange(client Client, from, to uint64) ([]*types.SignedBlock, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if from > to {
+		return nil, fmt.Errorf("invalid Avail block range %d to %d", from, to)
+	}
+
+	blocks := make([]*types.SignedBlock, to-from+1)
+
+	workers := c.readConnections()
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+
+	indexes := make(chan int)
+	errs := make(chan error, 1)
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				blk, err := finalizedBlock(c.reader(), from+uint64(i))
+				if err != nil {
+					failed.Store(true)
+
+					select {
+					case errs <- err:
+					default:
+					}
+
+					continue
+				}
+
+				blocks[i] = blk
+			}
+		}()
+	}
+
+	for i := range blocks {
+		if failed.Load() {
+			break
+		}
+
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+		return blocks, nil
+	}
+}
+
+// reader returns the connection the next read-only call is routed to, distributing the calls round-robin across
+// the active connection and the pooled connections to the active endpoint.
+func (c *client) reader() *gsrpc.SubstrateAPI {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.pool) == 0 {
+		return c.api
+	}
+
+	n := uint32(len(c.pool) + 1)
+	start := atomic.AddUint32(&c.poolNext, 1)
+
+	for k := uint32(0); k < n; k++ {
+		i := (start + k) % n
+		if i == 0 {
+			return c.api
+		}
+
+		// The disconnected members, and the ones left behind by a failover, are skipped until they're replaced.
+		if member := c.pool[i-1]; member.api != nil && member.endpoint == c.active {
+			return member.api
+		}
+	}
+
+	return c.api
+}
+
+// readConnections returns the number of connections the read-only calls are distributed across.
+func (c *client) readConnections() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.pool) + 1
+}
+
+// checkPool fetches the best block of every pooled connection, and replaces the ones failing, disconnected or
+// connected to an endpoint which isn't the active one anymore with a new connection to the active endpoint.
+func (c *client) checkPool() {
+	c.lock.RLock()
+	active := c.active
+	url := c.endpoints[active]
+	pool := append([]pooledConn(nil), c.pool...)
+	c.lock.RUnlock()
+
+	for i, member := range pool {
+		if member.api != nil && member.endpoint == active {
+			_, err := member.api.RPC.Chain.GetHeaderLatest()
+			if err == nil {
+				continue
+			}
+
+			c.logger.Warn("pooled Avail connection unhealthy, replacing it", "endpoint", url, "error", err)
+		}
+
+		api, err := c.dialEndpoint(url)
+		if err == nil {
+			err = c.checkGenesis(api)
+		}
+
+		if err != nil {
+			c.logger.Warn("couldn't replace pooled Avail connection", "endpoint", url, "error", err)
+			closeConn(api)
+			api = nil
+		}
+
+		c.replacePooled(i, member, pooledConn{api: api, endpoint: active})
+	}
+}
+
+// replacePooled replaces the pooled connection at the index, unless it was already replaced, and closes the
+// one replaced.
+func (c *client) replacePooled(i int, old, replacement pooledConn) {
+	c.lock.Lock()
+
+	if c.pool[i] != old {
+		c.lock.Unlock()
+		closeConn(replacement.api)
+
+		return
+	}
+
+	c.pool[i] = replacement
+	c.lock.Unlock()
+
+	closeConn(old.api)
+}
+
+// fillPool connects the pooled connections to the active endpoint. The ones failing to connect are connected by
+// the health checks.
+func (c *client) fillPool() {
+	url := c.endpoints[c.active]
+
+	for i := range c.pool {
+		api, err := c.dialEndpoint(url)
+		if err != nil {
+			c.logger.Warn("couldn't open pooled Avail connection", "endpoint", url, "error", err)
+			continue
+		}
+
+		c.pool[i] = pooledConn{api: api, endpoint: c.active}
+	}
+}
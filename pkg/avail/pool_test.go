@@ -0,0 +1,214 @@
+package avail
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+// poolTest is a pooled client of the endpoints, each connection served by its own endpointChain.
+type poolTest struct {
+	c *client
+
+	lock  sync.Mutex
+	dials map[string][]*endpointChain
+}
+
+func newPoolTest(t *testing.T, connections int, urls ...string) *poolTest {
+	pt := &poolTest{dials: make(map[string][]*endpointChain)}
+
+	dial := func(c *client) {
+		c.dial = func(url string) (*gsrpc.SubstrateAPI, error) {
+			pt.lock.Lock()
+			defer pt.lock.Unlock()
+
+			ch := &endpointChain{head: 10, genesis: endpointBlockHash(0)}
+			pt.dials[url] = append(pt.dials[url], ch)
+
+			return &gsrpc.SubstrateAPI{RPC: &rpc.RPC{Chain: ch}}, nil
+		}
+	}
+
+	c, err := newClient(urls, hclog.NewNullLogger(), dial, WithConnections(connections), WithHealthCheckInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(c.Close)
+
+	pt.c = c
+
+	return pt
+}
+
+// readers returns the chains of the connections the next read-only calls are routed to.
+func (pt *poolTest) readers(n int) map[*endpointChain]int {
+	readers := make(map[*endpointChain]int)
+
+	for i := 0; i < n; i++ {
+		readers[pt.c.reader().RPC.Chain.(*endpointChain)]++
+	}
+
+	return readers
+}
+
+func TestConnectionPoolDistributesReads(t *testing.T) {
+	pt := newPoolTest(t, 3, "a")
+
+	if !assert.Len(t, pt.dials["a"], 3) {
+		return
+	}
+
+	// The reads are distributed evenly, and the subscriptions and submissions stay on the first connection.
+	assert.Equal(t, map[*endpointChain]int{pt.dials["a"][0]: 2, pt.dials["a"][1]: 2, pt.dials["a"][2]: 2}, pt.readers(6))
+	assert.Same(t, pt.dials["a"][0], pt.c.instance().RPC.Chain)
+}
+
+func TestConnectionPoolReplacesFailingConnections(t *testing.T) {
+	pt := newPoolTest(t, 3, "a")
+
+	failing := pt.dials["a"][1]
+	failing.err = errors.New("connection reset")
+
+	pt.c.checkPool()
+
+	if !assert.Len(t, pt.dials["a"], 4) {
+		return
+	}
+
+	replacement := pt.dials["a"][3]
+	assert.Equal(t, map[*endpointChain]int{pt.dials["a"][0]: 2, pt.dials["a"][2]: 2, replacement: 2}, pt.readers(6))
+
+	// The healthy connections are kept.
+	pt.c.checkPool()
+	assert.Len(t, pt.dials["a"], 4)
+}
+
+func TestConnectionPoolFollowsFailover(t *testing.T) {
+	pt := newPoolTest(t, 3, "a", "b")
+
+	pt.dials["a"][0].err = errors.New("connection reset")
+	pt.c.checkHealth()
+
+	active, err := ActiveEndpoint(pt.c)
+	if !assert.NoError(t, err) || !assert.Equal(t, "b", active) {
+		return
+	}
+
+	// The pooled connections to the previous endpoint aren't read from anymore, and are replaced.
+	assert.Equal(t, map[*endpointChain]int{pt.dials["b"][0]: 4}, pt.readers(4))
+
+	pt.c.checkPool()
+
+	if assert.Len(t, pt.dials["b"], 3) {
+		assert.Equal(t, map[*endpointChain]int{pt.dials["b"][0]: 2, pt.dials["b"][1]: 2, pt.dials["b"][2]: 2}, pt.readers(6))
+	}
+}
+
+func TestGetBlockRange(t *testing.T) {
+	for _, connections := range []int{1, 4} {
+		c := newLatencyClient(t, 20, 0, connections)
+
+		blocks, err := GetBlockRange(c, 3, 17)
+		if !assert.NoError(t, err) || !assert.Len(t, blocks, 15) {
+			continue
+		}
+
+		for i, blk := range blocks {
+			assert.Equal(t, types.BlockNumber(3+i), blk.Block.Header.Number)
+		}
+
+		_, err = GetBlockRange(c, 15, 25)
+		assert.Error(t, err)
+
+		_, err = GetBlockRange(c, 4, 3)
+		assert.Error(t, err)
+	}
+}
+
+// latencyChain serves the blocks of a connection with latency, one request at a time.
+type latencyChain struct {
+	chain.Chain
+
+	lock    sync.Mutex
+	latency time.Duration
+	blocks  map[types.Hash]*types.SignedBlock
+}
+
+func (c *latencyChain) wait() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	time.Sleep(c.latency)
+}
+
+func (c *latencyChain) GetBlockHash(n uint64) (types.Hash, error) {
+	c.wait()
+
+	return latencyBlockHash(n), nil
+}
+
+func (c *latencyChain) GetBlock(blockHash types.Hash) (*types.SignedBlock, error) {
+	c.wait()
+
+	blk, ok := c.blocks[blockHash]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+
+	return blk, nil
+}
+
+// latencyBlockHash returns the hash of the block number served by latencyChain, unique for more than 256 blocks.
+func latencyBlockHash(n uint64) types.Hash {
+	return types.NewHash(binary.BigEndian.AppendUint64(nil, n+1))
+}
+
+// newLatencyClient returns a client with the number of connections, each serving the blocks with the latency.
+func newLatencyClient(tb testing.TB, blocks int, latency time.Duration, connections int) *client {
+	served := make(map[types.Hash]*types.SignedBlock, blocks)
+	for n := 0; n < blocks; n++ {
+		served[latencyBlockHash(uint64(n))] = &types.SignedBlock{Block: types.Block{Header: types.Header{Number: types.BlockNumber(n)}}}
+	}
+
+	dial := func(c *client) {
+		c.dial = func(string) (*gsrpc.SubstrateAPI, error) {
+			return &gsrpc.SubstrateAPI{RPC: &rpc.RPC{Chain: &latencyChain{latency: latency, blocks: served}}}, nil
+		}
+	}
+
+	c, err := newClient([]string{"mock"}, hclog.NewNullLogger(), dial, WithConnections(connections), WithHealthCheckInterval(time.Hour))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(c.Close)
+
+	return c
+}
+
+func BenchmarkGetBlockRange(b *testing.B) {
+	for _, connections := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("connections=%d", connections), func(b *testing.B) {
+			c := newLatencyClient(b, 1000, 100*time.Microsecond, connections)
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := GetBlockRange(c, 0, 999); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
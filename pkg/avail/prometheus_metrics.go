@@ -0,0 +1,62 @@
+package avail
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusMetrics is the implementation of Metrics exporting the extrinsic submissions to Prometheus.
+type PrometheusMetrics struct {
+	submissions *prometheus.CounterVec
+	latency     *prometheus.HistogramVec
+	dataSize    prometheus.Histogram
+}
+
+// NewPrometheusMetrics creates the Prometheus metrics of the extrinsic submissions under the namespace, and
+// registers them with the registerer, e.g. prometheus.DefaultRegisterer.
+// It returns an error if the metrics can't be registered, e.g. when they are already.
+func NewPrometheusMetrics(namespace string, registerer prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		submissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "avail",
+			Name:      "extrinsic_submissions_total",
+			Help:      "Number of Avail extrinsic submissions, by call and outcome.",
+		}, []string{"call", "outcome"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "avail",
+			Name:      "extrinsic_submission_seconds",
+			Help:      "Latency of Avail extrinsic submissions from the signature to the outcome, by call and outcome.",
+			// Avail blocks are produced every 20 seconds, and finalized a few blocks later.
+			Buckets: []float64{1, 5, 10, 20, 30, 45, 60, 90, 120, 180, 300},
+		}, []string{"call", "outcome"}),
+		dataSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "avail",
+			Name:      "data_submission_bytes",
+			Help:      "Size of the data of Avail data submissions.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 2, 10),
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.submissions, m.latency, m.dataSize} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ExtrinsicSubmitted implements Metrics.
+func (m *PrometheusMetrics) ExtrinsicSubmitted(call string, outcome SubmitOutcome, latency time.Duration) {
+	m.submissions.WithLabelValues(call, string(outcome)).Inc()
+	m.latency.WithLabelValues(call, string(outcome)).Observe(latency.Seconds())
+}
+
+// DataSubmitted implements Metrics.
+func (m *PrometheusMetrics) DataSubmitted(size int) {
+	m.dataSize.Observe(float64(size))
+}
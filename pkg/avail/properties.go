@@ -0,0 +1,147 @@
+package avail
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// chainDefaults are the SS58 prefix of the accounts created by the package and the token decimals of the formatted
+// amounts, set with UseChainDefaults, possibly while other goroutines read them.
+var chainDefaults = struct {
+	lock          sync.RWMutex
+	ss58Prefix    uint16
+	tokenDecimals uint32
+}{ss58Prefix: 42, tokenDecimals: 18}
+
+// DefaultTokenDecimals returns the number of decimals of the Avail token used by FormatAVL, AVL being 10^18
+// fractions, unless it was set to the decimals of the chain with UseChainDefaults.
+func DefaultTokenDecimals() uint32 {
+	chainDefaults.lock.RLock()
+	defer chainDefaults.lock.RUnlock()
+
+	return chainDefaults.tokenDecimals
+}
+
+// setChainDefaults sets the SS58 prefix and the token decimals returned by DefaultSS58Prefix and DefaultTokenDecimals.
+func setChainDefaults(ss58Prefix uint16, tokenDecimals uint32) {
+	chainDefaults.lock.Lock()
+	defer chainDefaults.lock.Unlock()
+
+	chainDefaults.ss58Prefix, chainDefaults.tokenDecimals = ss58Prefix, tokenDecimals
+}
+
+// ChainProperties are the properties of the Avail network, returned by the system_properties RPC.
+type ChainProperties struct {
+	// SS58Prefix is the network prefix of the SS58 addresses of the chain.
+	SS58Prefix uint16
+	// TokenDecimals is the number of decimals of the native token, i.e. the number of digits of its fractions.
+	TokenDecimals uint32
+	// TokenSymbol is the symbol of the native token, e.g. "AVL".
+	TokenSymbol string
+}
+
+// GetChainProperties retrieves the properties of the Avail network. They're fetched once per client, as they
+// don't change.
+// The chain properties missing from the node response default to DefaultSS58Prefix, DefaultTokenDecimals and
+// an empty symbol. For a chain with multiple tokens, the properties of the first one, the native token, are
+// returned.
+// It takes a client, and returns the chain properties and an error if there is an issue.
+func GetChainProperties(client Client) (*ChainProperties, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	c.propertiesLock.Lock()
+	defer c.propertiesLock.Unlock()
+
+	if c.properties != nil {
+		return c.properties, nil
+	}
+
+	var raw systemProperties
+	if err := c.instance().Client.Call(&raw, "system_properties"); err != nil {
+		return nil, fmt.Errorf("couldn't fetch the chain properties: %w", err)
+	}
+
+	properties, err := raw.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	c.properties = properties
+
+	return properties, nil
+}
+
+// UseChainDefaults sets DefaultSS58Prefix and DefaultTokenDecimals to the properties of the Avail network,
+// so the addresses of the accounts created afterwards and the formatted amounts match the ones of the chain
+// explorers. It's meant to be called on startup, before any account is created, but it's safe to call while
+// the defaults are read.
+// It takes a client, and returns the chain properties and an error if there is an issue, in which case
+// the defaults aren't changed.
+func UseChainDefaults(client Client) (*ChainProperties, error) {
+	properties, err := GetChainProperties(client)
+	if err != nil {
+		return nil, err
+	}
+
+	setChainDefaults(properties.SS58Prefix, properties.TokenDecimals)
+
+	return properties, nil
+}
+
+// systemProperties is the response of the system_properties RPC. The token properties are arrays on chains
+// with multiple tokens.
+type systemProperties struct {
+	SS58Format    *uint16         `json:"ss58Format"`
+	TokenDecimals json.RawMessage `json:"tokenDecimals"`
+	TokenSymbol   json.RawMessage `json:"tokenSymbol"`
+}
+
+// decode returns the chain properties of the response.
+func (p *systemProperties) decode() (*ChainProperties, error) {
+	properties := &ChainProperties{SS58Prefix: DefaultSS58Prefix(), TokenDecimals: DefaultTokenDecimals()}
+
+	if p.SS58Format != nil {
+		if *p.SS58Format > maxSS58Prefix {
+			return nil, fmt.Errorf("invalid ss58Format chain property %d", *p.SS58Format)
+		}
+
+		properties.SS58Prefix = *p.SS58Format
+	}
+
+	if err := decodeFirstProperty(p.TokenDecimals, &properties.TokenDecimals); err != nil {
+		return nil, fmt.Errorf("invalid tokenDecimals chain property: %w", err)
+	}
+
+	if err := decodeFirstProperty(p.TokenSymbol, &properties.TokenSymbol); err != nil {
+		return nil, fmt.Errorf("invalid tokenSymbol chain property: %w", err)
+	}
+
+	return properties, nil
+}
+
+// decodeFirstProperty decodes the property, or the first one of an array of properties, into the target.
+// The target is left unchanged if the property is missing or the array is empty.
+func decodeFirstProperty(raw json.RawMessage, target interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	if raw[0] != '[' {
+		return json.Unmarshal(raw, target)
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(values[0], target)
+}
@@ -0,0 +1,138 @@
+package avail
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	gsrpcclient "github.com/centrifuge/go-substrate-rpc-client/v4/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPropertiesClient serves the system_properties calls, in place of the fixed properties of the mock client, and
+// captures the number of calls. The other calls are served by the client it wraps.
+type mockPropertiesClient struct {
+	gsrpcclient.Client
+
+	properties string
+	calls      int
+}
+
+func (c *mockPropertiesClient) Call(result interface{}, method string, args ...interface{}) error {
+	if method != "system_properties" {
+		return c.Client.Call(result, method, args...)
+	}
+
+	c.calls++
+
+	return json.Unmarshal([]byte(c.properties), result)
+}
+
+func TestGetChainProperties(t *testing.T) {
+	testCases := []struct {
+		name       string
+		properties string
+		want       ChainProperties
+		err        string
+	}{
+		{
+			name:       "avail",
+			properties: `{"ss58Format":42,"tokenDecimals":18,"tokenSymbol":"AVL"}`,
+			want:       ChainProperties{SS58Prefix: 42, TokenDecimals: 18, TokenSymbol: "AVL"},
+		},
+		{
+			name:       "multiple tokens",
+			properties: `{"ss58Format":2,"tokenDecimals":[12,10],"tokenSymbol":["KSM","DOT"]}`,
+			want:       ChainProperties{SS58Prefix: 2, TokenDecimals: 12, TokenSymbol: "KSM"},
+		},
+		{
+			name:       "defaults",
+			properties: `{}`,
+			want:       ChainProperties{SS58Prefix: DefaultSS58Prefix(), TokenDecimals: DefaultTokenDecimals()},
+		},
+		{
+			name:       "invalid prefix",
+			properties: `{"ss58Format":16384}`,
+			err:        "invalid ss58Format chain property 16384",
+		},
+		{
+			name:       "invalid decimals",
+			properties: `{"tokenDecimals":"18"}`,
+			err:        "invalid tokenDecimals chain property",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewMockClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			api := m.instance()
+			mock := &mockPropertiesClient{Client: api.Client, properties: tc.properties}
+			api.Client = mock
+
+			properties, err := GetChainProperties(m)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, *properties)
+			}
+
+			// The properties are fetched once.
+			_, err = GetChainProperties(m)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, mock.calls)
+		})
+	}
+}
+
+func TestUseChainDefaults(t *testing.T) {
+	defer setChainDefaults(DefaultSS58Prefix(), DefaultTokenDecimals())
+
+	m, err := NewMockClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := m.instance()
+	api.Client = &mockPropertiesClient{Client: api.Client, properties: `{"ss58Format":0,"tokenDecimals":10,"tokenSymbol":"DOT"}`}
+
+	_, err = UseChainDefaults(m)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, uint16(0), DefaultSS58Prefix())
+	assert.Equal(t, "1.5", FormatAVL(big.NewInt(15_000_000_000)))
+
+	// The accounts created afterwards have addresses on the network of the chain, unless a prefix is given.
+	account, err := NewAccount()
+	if assert.NoError(t, err) {
+		_, prefix, err := FromSS58(account.Address)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(0), prefix)
+	}
+
+	generated, err := NewAccountWithStrength(DefaultMnemonicStrength)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err = NewAccountFromMnemonic(generated.Mnemonic, 42)
+	if assert.NoError(t, err) {
+		_, prefix, err := FromSS58(account.Address)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(42), prefix)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "1.5", FormatAmount(big.NewInt(15_000_000_000), 10))
+	assert.Equal(t, "0.0000000015", FormatAmount(big.NewInt(15_000_000_000), 19))
+	assert.Equal(t, "15000000000", FormatAmount(big.NewInt(15_000_000_000), 0))
+}
@@ -0,0 +1,648 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// ErrQueueClosed is the error returned when queueing a call in a closed SubmitQueue, and the error of the items
+// that weren't submitted yet when the queue was closed.
+var ErrQueueClosed = errors.New("submit queue closed")
+
+// ItemStatus is the status of an item of a SubmitQueue.
+type ItemStatus int32
+
+const (
+	// ItemQueued is the status of an item waiting for the submission of the items queued before it.
+	ItemQueued ItemStatus = iota
+	// ItemSubmitted is the status of an item accepted by the transaction pool, waiting for its inclusion.
+	ItemSubmitted
+	// ItemIncluded is the status of an item included in a block, or finalized, and dispatched successfully.
+	ItemIncluded
+	// ItemFailed is the status of an item whose submission, inclusion or dispatch failed.
+	ItemFailed
+)
+
+func (s ItemStatus) String() string {
+	switch s {
+	case ItemQueued:
+		return "queued"
+	case ItemSubmitted:
+		return "submitted"
+	case ItemIncluded:
+		return "included"
+	case ItemFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("ItemStatus(%d)", int32(s))
+	}
+}
+
+// QueueResult is the outcome of an item of a SubmitQueue.
+type QueueResult struct {
+	// Result locates the extrinsic of the item on Avail. It's nil if the extrinsic wasn't included.
+	Result *SubmitResult
+	// Err is the error of the item, like the ones returned by SubmitData.
+	Err error
+}
+
+// QueueItem is a call queued in a SubmitQueue.
+type QueueItem struct {
+	q        *SubmitQueue
+	ctx      context.Context
+	callName string
+	meta     *types.Metadata
+	sign     signFunc
+	start    time.Time
+
+	// status is the ItemStatus of the item. It's accessed atomically.
+	status int32
+	result chan QueueResult
+
+	// signing is the signature of the extrinsic with the nonce assigned to the item, guarded by the queue lock.
+	signing *itemSigning
+
+	// watch numbers the submissions of the item, and unwatch stops watching the current one, guarded by the queue
+	// lock. An item is submitted again when an item submitted before it is dropped, see SubmitQueue.dropped.
+	watch   int
+	unwatch context.CancelFunc
+}
+
+// itemSigning is the signature of the extrinsic of an item with a nonce, running in the background.
+type itemSigning struct {
+	nonce uint64
+	// done is closed once the extrinsic is signed.
+	done chan struct{}
+	ext  types.Extrinsic
+	err  error
+}
+
+// Nonce returns the nonce assigned to the item. It changes if an item queued before it fails before its submission,
+// or is dropped or invalidated by the transaction pool after its submission.
+func (i *QueueItem) Nonce() uint64 {
+	i.q.lock.Lock()
+	defer i.q.lock.Unlock()
+
+	return i.signing.nonce
+}
+
+// Status returns the status of the item.
+func (i *QueueItem) Status() ItemStatus {
+	return ItemStatus(atomic.LoadInt32(&i.status))
+}
+
+// Result returns the channel receiving the outcome of the item, once it's included or failed.
+func (i *QueueItem) Result() <-chan QueueResult {
+	return i.result
+}
+
+// presign assigns the nonce to the item, and signs its extrinsic with it in the background.
+// The queue lock must be held.
+func (i *QueueItem) presign(nonce uint64) {
+	s := &itemSigning{nonce: nonce, done: make(chan struct{})}
+	i.signing = s
+
+	go func() {
+		defer close(s.done)
+		s.ext, s.err = i.sign(nonce)
+	}()
+}
+
+// SubmitQueue submits the calls signed by an account in order, with strictly increasing nonces handed out by the
+// nonce manager of its submission options, so the calls can be posted back-to-back without waiting for the inclusion
+// of the previous ones. The extrinsics are signed concurrently as they're queued, and watched concurrently once
+// they're submitted.
+// When an item fails before its submission, e.g. when it's rejected by the transaction pool, the items queued
+// behind it are re-signed with the nonces following the last submitted one, so no nonce is skipped. When an item is
+// dropped or invalidated by the transaction pool after its submission, the items submitted after it stop being
+// watched, and they're re-signed, with the items queued behind them, with the nonces starting at the next nonce of
+// the account on chain, or following the items still in flight, and submitted again. An item
+// rejected for a stale nonce is re-signed with the nonce fetched from chain, and resubmitted following the retry
+// policy of the client. With a fee budget, the estimated fee of each item is charged to it before the item is
+// submitted, and an item that doesn't fit fails with a *FeeBudgetExceededError, see FeeBudget.
+// It is safe for concurrent use; the account should only be used by the queue while it's open.
+type SubmitQueue struct {
+	c       *client
+	account signature.KeyringPair
+	opts    SubmitOpts
+
+	// lock guards the queued items, not submitted yet, the submitted ones, the number of items not done, and
+	// the closing.
+	lock     sync.Mutex
+	queued   []*QueueItem
+	inflight []*QueueItem
+	pending  int
+
+	// rewound are the items detached from the in-flight ones after the item with the nonce rewindFrom was dropped,
+	// put back at the front of the queue by the run loop while rewinding is set, see rewind.
+	rewound    []*QueueItem
+	rewinding  bool
+	rewindFrom uint64
+
+	// idle is closed while no item is pending.
+	idle   chan struct{}
+	closed bool
+
+	wake      chan struct{}
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewSubmitQueue creates a new SubmitQueue of the calls signed with the account, submitted with the options.
+// A nonce manager is created for the queue if the options don't have one.
+// It returns an error if the client isn't supported.
+func NewSubmitQueue(client Client, account signature.KeyringPair, opts SubmitOpts) (*SubmitQueue, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Nonces == nil {
+		opts.Nonces = NewNonceManager()
+	}
+
+	idle := make(chan struct{})
+	close(idle)
+
+	q := &SubmitQueue{
+		c:       c,
+		account: account,
+		opts:    opts,
+		idle:    idle,
+		wake:    make(chan struct{}, 1),
+		closeCh: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go q.run()
+
+	return q, nil
+}
+
+// Submit queues the call, signed with the AppID. The call name labels the submission in the metrics of the client.
+// It takes a context bounding the wait for the submission and the inclusion of the item.
+// It returns the queued item, and ErrQueueClosed if the queue is closed, or an error if the call
+// can't be signed, in which case nothing is queued.
+func (q *SubmitQueue) Submit(ctx context.Context, callName string, call types.Call, appID uint32) (*QueueItem, error) {
+	api := q.c.instance()
+
+	meta, err := q.c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := q.c.callSigner(api, q.account, call, appID, q.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &QueueItem{
+		q:        q,
+		ctx:      ctx,
+		callName: callName,
+		meta:     meta,
+		sign:     sign,
+		start:    time.Now(),
+		result:   make(chan QueueResult, 1),
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+
+	nonce, err := q.opts.Nonces.next(api, q.account)
+	if err != nil {
+		return nil, err
+	}
+
+	item.presign(nonce)
+	q.queued = append(q.queued, item)
+
+	if q.pending++; q.pending == 1 {
+		q.idle = make(chan struct{})
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return item, nil
+}
+
+// SubmitData queues the submission of the data under the AppID, compressed with the codec of the client, if any,
+// see WithCompression.
+// It returns the queued item, and an error wrapping ErrDataTooLarge if the data exceeds the maximum length
+// accepted by the chain, in which case nothing is queued, or an error if there is an issue, see Submit.
+func (q *SubmitQueue) SubmitData(ctx context.Context, appID uint32, data []byte) (*QueueItem, error) {
+	meta, err := q.c.metadata(q.c.instance())
+	if err != nil {
+		return nil, err
+	}
+
+	constants, err := q.c.constantsOf(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = q.c.compress(data); err != nil {
+		return nil, err
+	}
+
+	if len(data) > constants.MaxAppDataLength {
+		return nil, fmt.Errorf("%w: %d bytes, maximum is %d", ErrDataTooLarge, len(data), constants.MaxAppDataLength)
+	}
+
+	call, err := types.NewCall(meta, CallSubmitData, data)
+	if err != nil {
+		return nil, err
+	}
+
+	q.c.submissionMetrics().DataSubmitted(len(data))
+
+	return q.Submit(ctx, CallSubmitData, call, appID)
+}
+
+// Flush waits for all the items queued, or submitted and still in flight, to be included or failed.
+// It returns the error of the context if it's done before.
+func (q *SubmitQueue) Flush(ctx context.Context) error {
+	q.lock.Lock()
+	idle := q.idle
+	q.lock.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the queue. The items that weren't submitted yet fail with ErrQueueClosed, and their nonces are
+// taken back; the submitted ones are still waited for.
+func (q *SubmitQueue) Close() {
+	q.closeOnce.Do(func() {
+		q.lock.Lock()
+		q.closed = true
+		q.lock.Unlock()
+
+		close(q.closeCh)
+	})
+
+	<-q.done
+}
+
+// run submits the queued items in order, until the queue is closed.
+func (q *SubmitQueue) run() {
+	defer close(q.done)
+
+	for {
+		select {
+		case <-q.closeCh:
+			q.drain()
+			return
+		default:
+		}
+
+		if err := q.rewind(); err != nil {
+			q.c.logger.Warn("failed to re-sign the queued extrinsics after a dropped one, retrying", "account", q.account.Address, "error", err)
+
+			select {
+			case <-time.After(jittered(q.c.retry.Backoff)):
+			case <-q.closeCh:
+			}
+
+			continue
+		}
+
+		q.lock.Lock()
+		var item *QueueItem
+		if len(q.queued) > 0 {
+			item = q.queued[0]
+		}
+		q.lock.Unlock()
+
+		if item == nil {
+			select {
+			case <-q.wake:
+			case <-q.closeCh:
+			}
+
+			continue
+		}
+
+		q.submit(item)
+	}
+}
+
+// submit submits the item at the front of the queue once it's signed, and waits for its inclusion in the background.
+// An item rejected for a stale nonce is re-signed with the nonce fetched from chain, and resubmitted. The fee of
+// the item is charged to the fee budget before its submission, and refunded if it's rejected.
+func (q *SubmitQueue) submit(item *QueueItem) {
+	budget := q.opts.feeBudgetOr(q.c.feeBudget)
+
+	for attempt := 0; ; attempt++ {
+		q.lock.Lock()
+		s := item.signing
+		q.lock.Unlock()
+
+		err := q.signed(item, s)
+
+		api := q.c.instance()
+
+		if err == nil && q.opts.DryRun {
+			err = q.c.preflight(s.ext)
+		}
+
+		var spend *feeSpend
+		if err == nil {
+			spend, err = q.c.chargeFee(api, budget, s.ext)
+		}
+
+		if err == nil {
+			var sub extrinsicSubscription
+			if sub, err = q.c.watch(api, s.ext); err == nil {
+				ctx, watch := q.submitted(item)
+				go q.await(ctx, item, watch, sub, s.ext, s.nonce)
+
+				return
+			}
+
+			refundFee(budget, spend)
+			q.c.invalidateRuntimeVersionOnBadSignature(err)
+		}
+
+		if !IsStaleNonceError(err) || attempt >= q.c.retry.MaxNonceRetries {
+			q.fail(item, err)
+			return
+		}
+
+		q.c.logger.Warn("queued extrinsic rejected for a stale nonce, resubmitting", "account", q.account.Address, "old_nonce", s.nonce, "attempt", attempt+1)
+
+		// The nonces of the item and of the ones behind it follow the nonce on chain.
+		q.opts.Nonces.Resync(q.account)
+
+		if err := q.renumberFromChain(api, item); err != nil {
+			q.fail(item, err)
+			return
+		}
+
+		select {
+		case <-time.After(jittered(time.Duration(attempt+1) * q.c.retry.Backoff)):
+		case <-item.ctx.Done():
+			q.fail(item, fmt.Errorf("queued extrinsic not submitted: %w", item.ctx.Err()))
+			return
+		case <-q.closeCh:
+			q.fail(item, ErrQueueClosed)
+			return
+		}
+	}
+}
+
+// signed waits for the extrinsic of the item to be signed, and returns the signing error, or an error wrapping
+// the error of the context of the item if it's done, or ErrQueueClosed if the queue is closed, before.
+func (q *SubmitQueue) signed(item *QueueItem, s *itemSigning) error {
+	select {
+	case <-s.done:
+	case <-item.ctx.Done():
+	case <-q.closeCh:
+		return ErrQueueClosed
+	}
+
+	if err := item.ctx.Err(); err != nil {
+		return fmt.Errorf("queued extrinsic not submitted: %w", err)
+	}
+
+	return s.err
+}
+
+// submitted moves the item from the front of the queue to the in-flight ones, once it's accepted by the transaction
+// pool. It returns the context bounding the watch of the submission, and the number of the submission.
+func (q *SubmitQueue) submitted(item *QueueItem) (context.Context, int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.queued = q.queued[1:]
+	q.inflight = append(q.inflight, item)
+
+	ctx, cancel := context.WithCancel(item.ctx)
+	item.watch++
+	item.unwatch = cancel
+
+	atomic.StoreInt32(&item.status, int32(ItemSubmitted))
+
+	return ctx, item.watch
+}
+
+// fail removes the item from the front of the queue, once it failed before its submission, and re-signs the items
+// queued behind it with the nonces starting at its unused nonce.
+func (q *SubmitQueue) fail(item *QueueItem, err error) {
+	q.lock.Lock()
+	nonce := item.signing.nonce
+	q.queued = q.queued[1:]
+	q.renumber(nonce, q.queued)
+	q.lock.Unlock()
+
+	q.c.recordSubmission(item.callName, q.opts.WaitFor, item.start, err)
+	q.finish(item, nil, err)
+}
+
+// renumberFromChain re-signs the item at the front of the queue, and the ones behind it, with the nonces starting
+// at the next nonce of the account, after a resync.
+func (q *SubmitQueue) renumberFromChain(api *gsrpc.SubstrateAPI, item *QueueItem) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	nonce, err := q.opts.Nonces.next(api, q.account)
+	if err != nil {
+		return err
+	}
+
+	q.renumber(nonce, q.queued)
+
+	return nil
+}
+
+// renumber re-signs the items whose nonce changed with the nonces starting at the nonce, and sets the next nonce
+// of the account to the one following the last item. The queue lock must be held.
+func (q *SubmitQueue) renumber(nonce uint64, items []*QueueItem) {
+	for i, item := range items {
+		if item.signing.nonce != nonce+uint64(i) {
+			item.presign(nonce + uint64(i))
+		}
+	}
+
+	q.opts.Nonces.set(q.account, nonce+uint64(len(items)))
+}
+
+// dropped handles the item dropped or invalidated by the transaction pool after its submission: the items
+// submitted after it stop being watched, and are put back in the queue by the run loop, see rewind.
+// The queue lock must be held.
+func (q *SubmitQueue) dropped(item *QueueItem) {
+	nonce := item.signing.nonce
+
+	if !q.rewinding || nonce < q.rewindFrom {
+		q.rewindFrom = nonce
+	}
+
+	q.rewinding = true
+	q.detachInflight(q.rewindFrom)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// detachInflight stops watching the in-flight items with a nonce above the nonce, and moves them to the rewound
+// ones. The queue lock must be held.
+func (q *SubmitQueue) detachInflight(nonce uint64) {
+	inflight := q.inflight[:0]
+
+	for _, item := range q.inflight {
+		if item.signing.nonce <= nonce {
+			inflight = append(inflight, item)
+			continue
+		}
+
+		item.unwatch()
+		item.watch++
+		atomic.StoreInt32(&item.status, int32(ItemQueued))
+		q.rewound = append(q.rewound, item)
+	}
+
+	q.inflight = inflight
+}
+
+// rewind puts the items detached after a dropped item back at the front of the queue, in nonce order, and re-signs
+// them, with the items queued behind them, with the nonces starting at the next nonce of the account on chain, or
+// following the items still in flight if the node doesn't count them.
+// The items submitted since the drop, e.g. the one being submitted when it happened, are detached as well.
+// It returns an error if the nonce can't be fetched, the rewind being retried on the next call.
+func (q *SubmitQueue) rewind() error {
+	q.lock.Lock()
+
+	if !q.rewinding {
+		q.lock.Unlock()
+		return nil
+	}
+
+	q.detachInflight(q.rewindFrom)
+
+	rewound := q.rewound
+	sort.Slice(rewound, func(i, j int) bool { return rewound[i].signing.nonce < rewound[j].signing.nonce })
+
+	q.queued = append(rewound, q.queued...)
+	q.rewound = nil
+	q.lock.Unlock()
+
+	// The nonces following the dropped one aren't used.
+	q.opts.Nonces.Resync(q.account)
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.queued) > 0 {
+		nonce, err := q.opts.Nonces.next(q.c.instance(), q.account)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range q.inflight {
+			if item.signing.nonce >= nonce {
+				nonce = item.signing.nonce + 1
+			}
+		}
+
+		q.renumber(nonce, q.queued)
+	}
+
+	q.rewinding = false
+
+	return nil
+}
+
+// drain fails the items that weren't submitted with ErrQueueClosed, and takes back their nonces.
+func (q *SubmitQueue) drain() {
+	q.lock.Lock()
+	items := append(q.rewound, q.queued...)
+	q.queued, q.rewound = nil, nil
+
+	switch {
+	case q.rewinding:
+		// The nonces following the dropped item aren't known until they're fetched from chain.
+		q.rewinding = false
+		q.opts.Nonces.Resync(q.account)
+	case len(items) > 0:
+		q.opts.Nonces.set(q.account, items[0].signing.nonce)
+	}
+	q.lock.Unlock()
+
+	for _, item := range items {
+		q.finish(item, nil, ErrQueueClosed)
+	}
+}
+
+// await waits for the inclusion of the submitted item, checks its dispatch, and sends its outcome, unless the item
+// stopped being watched, to be submitted again, in the meantime. An item dropped or invalidated by the transaction
+// pool makes the items submitted after it be submitted again.
+func (q *SubmitQueue) await(ctx context.Context, item *QueueItem, watch int, sub extrinsicSubscription, ext types.Extrinsic, nonce uint64) {
+	result, err := q.c.awaitInclusion(ctx, sub, ext, q.account, nonce, q.opts)
+	if err == nil {
+		err = q.c.dispatchOutcome(q.c.instance().RPC, item.meta, result)
+	}
+
+	q.lock.Lock()
+	if item.watch != watch {
+		q.lock.Unlock()
+		return
+	}
+
+	item.unwatch()
+
+	for i, inflight := range q.inflight {
+		if inflight == item {
+			q.inflight = append(q.inflight[:i], q.inflight[i+1:]...)
+			break
+		}
+	}
+
+	if errors.Is(err, ErrExtrinsicDropped) || errors.Is(err, ErrExtrinsicInvalid) {
+		q.dropped(item)
+	}
+	q.lock.Unlock()
+
+	q.c.recordSubmission(item.callName, q.opts.WaitFor, item.start, err)
+	q.finish(item, result, err)
+}
+
+// finish sends the outcome of the item, and marks it done.
+func (q *SubmitQueue) finish(item *QueueItem, result *SubmitResult, err error) {
+	status := ItemIncluded
+	if err != nil {
+		status = ItemFailed
+	}
+
+	atomic.StoreInt32(&item.status, int32(status))
+	item.result <- QueueResult{Result: result, Err: err}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.pending--; q.pending == 0 {
+		close(q.idle)
+	}
+}
@@ -0,0 +1,373 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// queueTest is a SubmitQueue of the funder of a watchTest, whose accepted extrinsics wait for land to be included.
+type queueTest struct {
+	*watchTest
+	q      *SubmitQueue
+	nonces *NonceManager
+
+	lock     sync.Mutex
+	accepted []types.Extrinsic
+	subs     []*fakeSubscription
+	// reject is called with the number of the submission, and rejects it if it returns an error.
+	reject func(n int) error
+}
+
+func newQueueTest(t *testing.T, policy RetryPolicy) *queueTest {
+	qt := &queueTest{watchTest: newWatchTest(t, policy), nonces: NewNonceManager()}
+
+	submissions := 0
+
+	qt.c.submitAndWatch = func(_ *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+		submissions++
+
+		if qt.reject != nil {
+			if err := qt.reject(submissions); err != nil {
+				return nil, err
+			}
+		}
+
+		qt.lock.Lock()
+		defer qt.lock.Unlock()
+
+		sub := newFakeSubscription(nil)
+		qt.accepted = append(qt.accepted, ext)
+		qt.subs = append(qt.subs, sub)
+
+		return sub, nil
+	}
+
+	q, err := NewSubmitQueue(qt.c, qt.funder, SubmitOpts{Nonces: qt.nonces})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qt.q = q
+	t.Cleanup(q.Close)
+
+	return qt
+}
+
+// submitData queues the data.
+func (qt *queueTest) submitData(t *testing.T, data string) *QueueItem {
+	item, err := qt.q.SubmitData(context.Background(), 1, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return item
+}
+
+// waitAccepted waits for n extrinsics to be accepted, and returns their nonces.
+func (qt *queueTest) waitAccepted(t *testing.T, n int) []types.UCompact {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		qt.lock.Lock()
+		accepted := append([]types.Extrinsic(nil), qt.accepted...)
+		qt.lock.Unlock()
+
+		if len(accepted) >= n {
+			nonces := make([]types.UCompact, len(accepted))
+			for i, ext := range accepted {
+				nonces[i] = ext.Signature.Nonce
+			}
+
+			return nonces
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d accepted extrinsics, got %d", n, len(accepted))
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// land includes the accepted extrinsics in a new block of the mock client, dispatched successfully, and sends the
+// in-block status to their subscriptions.
+func (qt *queueTest) land(t *testing.T) {
+	qt.lock.Lock()
+	indexes := make([]int, len(qt.accepted))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	qt.lock.Unlock()
+
+	qt.landAccepted(t, indexes...)
+}
+
+// landAccepted is land including only the accepted extrinsics with the indexes.
+func (qt *queueTest) landAccepted(t *testing.T, indexes ...int) {
+	qt.lock.Lock()
+	defer qt.lock.Unlock()
+
+	extrinsics := make([]types.Extrinsic, len(indexes))
+	events := &eventRecordsBuilder{t: t}
+	for i, index := range indexes {
+		extrinsics[i] = qt.accepted[index]
+		events.add(uint32(i), eventExtrinsicSuccess, dispatchInfo)
+	}
+
+	blockHash, err := qt.produceBlock(extrinsics, events.raw())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range indexes {
+		qt.subs[index].statusCh <- types.ExtrinsicStatus{IsInBlock: true, AsInBlock: blockHash}
+	}
+}
+
+// produceBlock appends a block with the extrinsics and the events to the chain of the mock client, the nonce of the
+// funder following the last extrinsic.
+func (qt *queueTest) produceBlock(extrinsics []types.Extrinsic, events types.EventRecordsRaw) (types.Hash, error) {
+	m := qt.m
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	head := m.storage[len(m.storage)-1]
+
+	storage := make(map[string][]byte, len(head))
+	for key, value := range head {
+		storage[key] = value
+	}
+
+	info, err := m.accountInfo(storage, qt.funder.PublicKey)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	info.Nonce = types.U32(extrinsics[len(extrinsics)-1].Signature.Nonce.Int64() + 1)
+
+	if err := m.setAccountInfo(storage, qt.funder.PublicKey, info); err != nil {
+		return types.Hash{}, err
+	}
+
+	eventsKey, err := types.CreateStorageKey(m.meta, "System", "Events", nil)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	storage[string(eventsKey)] = events
+
+	return m.produceBlock(extrinsics, storage)
+}
+
+// receiveResult returns the outcome of the item.
+func receiveResult(t *testing.T, item *QueueItem) QueueResult {
+	t.Helper()
+
+	select {
+	case result := <-item.Result():
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out receiving the result of the item")
+		return QueueResult{}
+	}
+}
+
+func TestSubmitQueue(t *testing.T) {
+	qt := newQueueTest(t, testRetryPolicy)
+
+	items := []*QueueItem{qt.submitData(t, "a"), qt.submitData(t, "b"), qt.submitData(t, "c")}
+
+	// The items are submitted without waiting for the inclusion of the previous ones.
+	assert.Equal(t, []types.UCompact{
+		types.NewUCompactFromUInt(5), types.NewUCompactFromUInt(6), types.NewUCompactFromUInt(7),
+	}, qt.waitAccepted(t, 3))
+
+	for _, item := range items {
+		assert.Equal(t, ItemSubmitted, item.Status())
+	}
+
+	qt.land(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, qt.q.Flush(ctx))
+
+	for i, item := range items {
+		result := receiveResult(t, item)
+		if assert.NoError(t, result.Err) {
+			assert.Equal(t, uint64(1), result.Result.BlockNumber)
+			assert.Equal(t, uint32(i), result.Result.ExtrinsicIndex)
+			assert.Equal(t, uint64(5+i), result.Result.Nonce)
+		}
+
+		assert.Equal(t, ItemIncluded, item.Status())
+	}
+}
+
+func TestSubmitQueueRenumbersAfterFailure(t *testing.T) {
+	qt := newQueueTest(t, testRetryPolicy)
+
+	errInvalid := errors.New("1010: Invalid Transaction: Inability to pay some fees")
+	release := make(chan struct{})
+
+	qt.reject = func(n int) error {
+		if n == 1 {
+			<-release
+			return errInvalid
+		}
+
+		return nil
+	}
+
+	failed := qt.submitData(t, "a")
+	items := []*QueueItem{qt.submitData(t, "b"), qt.submitData(t, "c")}
+	close(release)
+
+	assert.ErrorIs(t, receiveResult(t, failed).Err, errInvalid)
+	assert.Equal(t, ItemFailed, failed.Status())
+
+	// The items queued behind the rejected one are re-signed with the nonces starting at its unused nonce.
+	assert.Equal(t, []types.UCompact{types.NewUCompactFromUInt(5), types.NewUCompactFromUInt(6)}, qt.waitAccepted(t, 2))
+	assert.Equal(t, uint64(5), items[0].Nonce())
+
+	qt.land(t)
+
+	for _, item := range items {
+		assert.NoError(t, receiveResult(t, item).Err)
+	}
+
+	nonce, err := qt.nonces.Next(qt.c, qt.funder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+}
+
+func TestSubmitQueueResubmitsAfterDrop(t *testing.T) {
+	qt := newQueueTest(t, testRetryPolicy)
+
+	items := []*QueueItem{qt.submitData(t, "a"), qt.submitData(t, "b"), qt.submitData(t, "c")}
+	qt.waitAccepted(t, 3)
+
+	qt.lock.Lock()
+	dropped := qt.subs[1]
+	qt.lock.Unlock()
+
+	dropped.statusCh <- types.ExtrinsicStatus{IsDropped: true}
+
+	assert.ErrorIs(t, receiveResult(t, items[1]).Err, ErrExtrinsicDropped)
+	assert.Equal(t, ItemFailed, items[1].Status())
+
+	// The item submitted after the dropped one is submitted again with the nonce following the one still in flight.
+	nonces := qt.waitAccepted(t, 4)
+	assert.Equal(t, types.NewUCompactFromUInt(6), nonces[3])
+	assert.Equal(t, uint64(6), items[2].Nonce())
+
+	qt.landAccepted(t, 0, 3)
+
+	for _, item := range []*QueueItem{items[0], items[2]} {
+		assert.NoError(t, receiveResult(t, item).Err)
+	}
+
+	nonce, err := qt.nonces.Next(qt.c, qt.funder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+}
+
+func TestSubmitQueueResignsAfterStaleNonce(t *testing.T) {
+	qt := newQueueTest(t, RetryPolicy{MaxNonceRetries: 2})
+
+	release := make(chan struct{})
+
+	qt.reject = func(n int) error {
+		if n == 1 {
+			<-release
+
+			// The nonce 5 was taken by an extrinsic submitted outside the queue.
+			if err := qt.m.SetNonce(qt.funder, 6); err != nil {
+				return err
+			}
+
+			return errPriorityTooLow
+		}
+
+		return nil
+	}
+
+	items := []*QueueItem{qt.submitData(t, "a"), qt.submitData(t, "b")}
+	close(release)
+
+	assert.Equal(t, []types.UCompact{types.NewUCompactFromUInt(6), types.NewUCompactFromUInt(7)}, qt.waitAccepted(t, 2))
+
+	qt.land(t)
+
+	for _, item := range items {
+		assert.NoError(t, receiveResult(t, item).Err)
+	}
+}
+
+func TestSubmitQueueClose(t *testing.T) {
+	qt := newQueueTest(t, testRetryPolicy)
+
+	entered, release := make(chan struct{}), make(chan struct{})
+
+	qt.reject = func(n int) error {
+		if n == 1 {
+			close(entered)
+			<-release
+		}
+
+		return nil
+	}
+
+	submitted := qt.submitData(t, "a")
+	queued := qt.submitData(t, "b")
+
+	// The queue is closed while the first item is being submitted.
+	<-entered
+
+	closed := make(chan struct{})
+	go func() {
+		qt.q.Close()
+		close(closed)
+	}()
+
+	for {
+		qt.q.lock.Lock()
+		isClosed := qt.q.closed
+		qt.q.lock.Unlock()
+
+		if isClosed {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := qt.q.SubmitData(context.Background(), 1, []byte("c"))
+	assert.ErrorIs(t, err, ErrQueueClosed)
+
+	close(release)
+	<-closed
+
+	// The item that wasn't submitted fails, and gives its nonce back; the submitted one is still waited for.
+	assert.ErrorIs(t, receiveResult(t, queued).Err, ErrQueueClosed)
+	assert.Equal(t, ItemSubmitted, submitted.Status())
+
+	qt.land(t)
+
+	assert.NoError(t, receiveResult(t, submitted).Err)
+
+	nonce, err := qt.nonces.Next(qt.c, qt.funder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), nonce)
+}
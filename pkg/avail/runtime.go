@@ -0,0 +1,74 @@
+package avail
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// DefaultRuntimeVersionTTL is the time the runtime version of the Avail node is cached for by the clients created
+// without WithRuntimeVersionTTL.
+const DefaultRuntimeVersionTTL = time.Minute
+
+// badSignatureError is the message of the Avail transaction pool error returned for an extrinsic signed with
+// an outdated spec or transaction version, whose signature payload doesn't match the one of the runtime.
+const badSignatureError = "Transaction has a bad signature"
+
+// WithRuntimeVersionTTL sets the time the runtime version of the Avail node is cached for, as it only changes on
+// a runtime upgrade. Zero fetches the runtime version for every submission.
+func WithRuntimeVersionTTL(ttl time.Duration) ClientOption {
+	return func(c *client) {
+		if ttl >= 0 {
+			c.runtimeVersionTTL = ttl
+		}
+	}
+}
+
+// runtimeVersionCache is the runtime version of the Avail node, signed into the extrinsics and used to detect
+// the runtime upgrades of the metadata cache. It is safe for concurrent use.
+type runtimeVersionCache struct {
+	// lock is held while the runtime version is fetched, so concurrent callers don't fetch it again.
+	lock    sync.Mutex
+	rv      *types.RuntimeVersion
+	fetched time.Time
+}
+
+// runtimeVersion returns the latest runtime version, cached for the runtime version TTL of the client.
+func (c *client) runtimeVersion(api *gsrpc.SubstrateAPI) (*types.RuntimeVersion, error) {
+	c.runtimeVersionCache.lock.Lock()
+	defer c.runtimeVersionCache.lock.Unlock()
+
+	if c.runtimeVersionCache.rv != nil && time.Since(c.runtimeVersionCache.fetched) < c.runtimeVersionTTL {
+		return c.runtimeVersionCache.rv, nil
+	}
+
+	rv, err := api.RPC.State.GetRuntimeVersionLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	c.runtimeVersionCache.rv = rv
+	c.runtimeVersionCache.fetched = time.Now()
+
+	return rv, nil
+}
+
+// invalidateRuntimeVersion drops the cached runtime version, so it's fetched again on the next call.
+func (c *client) invalidateRuntimeVersion() {
+	c.runtimeVersionCache.lock.Lock()
+	defer c.runtimeVersionCache.lock.Unlock()
+
+	c.runtimeVersionCache.rv = nil
+}
+
+// invalidateRuntimeVersionOnBadSignature invalidates the cached runtime version if the submission error is a bad
+// signature, which the submission of an extrinsic signed with the spec version of the runtime before an upgrade
+// fails with.
+func (c *client) invalidateRuntimeVersionOnBadSignature(err error) {
+	if err != nil && strings.Contains(err.Error(), badSignatureError) {
+		c.invalidateRuntimeVersion()
+	}
+}
@@ -0,0 +1,65 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeVersionCached(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposits := func(ttl time.Duration) mockCalls {
+		m, funder := newFundedMockClient(t, 5*AVL)
+		m.client.runtimeVersionTTL = ttl
+
+		r := recordMockCalls(m)
+
+		for i := 0; i < 2; i++ {
+			_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{})
+			assert.NoError(t, err)
+		}
+
+		return r.recorded()
+	}
+
+	// Without caching, the runtime version is fetched by both the metadata lookup and the signature of each transfer.
+	calls := deposits(0)
+	assert.Equal(t, 4, calls.runtimeVersionFetches)
+
+	calls = deposits(time.Hour)
+	assert.Equal(t, 1, calls.runtimeVersionFetches)
+
+	// The genesis hash is never fetched again.
+	assert.NotContains(t, calls.blockHashes, uint64(0))
+}
+
+func TestRuntimeVersionInvalidatedOnBadSignature(t *testing.T) {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, funder := newFundedMockClient(t, 5*AVL)
+	m.client.runtimeVersionTTL = time.Hour
+
+	r := recordMockCalls(m)
+
+	badSignature := errors.New("1010: Invalid Transaction: Transaction has a bad signature")
+	m.Script(MockOutcome{Err: badSignature})
+
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{})
+	assert.ErrorIs(t, err, badSignature)
+	assert.Equal(t, 1, r.recorded().runtimeVersionFetches)
+
+	// The transfer was signed with an outdated runtime version, so it's fetched again.
+	_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.recorded().runtimeVersionFetches)
+}
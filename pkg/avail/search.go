@@ -1,8 +1,10 @@
 package avail
 
 import (
+	"errors"
 	"fmt"
 
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 )
 
@@ -22,22 +24,24 @@ type SearchFunc func(*types.SignedBlock) (int64, bool, error)
 //   - *types.SignedBlock: The found block.
 //   - error: An error if the block search fails.
 func (c *client) SearchBlock(offset int64, searchFunc SearchFunc) (*types.SignedBlock, error) {
+	api := c.instance()
+
 	// In case offset is zero, it means that we have new chain node and we need to sync it
 	// from latest head in avail towards first block.
 	if offset == 0 {
-		header, err := c.api.RPC.Chain.GetHeaderLatest()
+		header, err := api.RPC.Chain.GetHeaderLatest()
 		if err != nil {
 			return nil, err
 		}
 		offset = int64(header.Number)
 	}
 
-	blkHash, err := c.api.RPC.Chain.GetBlockHash(uint64(offset))
+	blkHash, err := api.RPC.Chain.GetBlockHash(uint64(offset))
 	if err != nil {
 		return nil, err
 	}
 
-	blk, err := c.api.RPC.Chain.GetBlock(blkHash)
+	blk, err := api.RPC.Chain.GetBlock(blkHash)
 	if err != nil {
 		return nil, err
 	}
@@ -58,12 +62,12 @@ func (c *client) SearchBlock(offset int64, searchFunc SearchFunc) (*types.Signed
 			break
 		}
 
-		blkHash, err := c.api.RPC.Chain.GetBlockHash(uint64(blk.Block.Header.Number) + uint64(offset))
+		blkHash, err := api.RPC.Chain.GetBlockHash(uint64(blk.Block.Header.Number) + uint64(offset))
 		if err != nil {
 			return nil, err
 		}
 
-		blk, err = c.api.RPC.Chain.GetBlock(blkHash)
+		blk, err = api.RPC.Chain.GetBlock(blkHash)
 		if err != nil {
 			return nil, err
 		}
@@ -80,3 +84,151 @@ func (c *client) SearchBlock(offset int64, searchFunc SearchFunc) (*types.Signed
 
 	return nil, fmt.Errorf("can't find block")
 }
+
+// ErrExtrinsicNotFound is the error matched by an *ExtrinsicNotFoundError.
+var ErrExtrinsicNotFound = errors.New("extrinsic not found")
+
+// ExtrinsicNotFoundError is the error returned by FindExtrinsic when no block of the scanned range includes the
+// extrinsic. The extrinsic may still be in the transaction pool, or included in a block out of the range.
+// It matches ErrExtrinsicNotFound.
+type ExtrinsicNotFoundError struct {
+	// ExtrinsicHash is the hash of the extrinsic looked for.
+	ExtrinsicHash types.Hash
+	// FromBlock and ToBlock are the bounds of the scanned range, in the scanning order.
+	FromBlock, ToBlock uint64
+}
+
+func (e *ExtrinsicNotFoundError) Error() string {
+	return fmt.Sprintf("%s: %s in blocks %d to %d", ErrExtrinsicNotFound, e.ExtrinsicHash.Hex(), e.FromBlock, e.ToBlock)
+}
+
+// Is matches ErrExtrinsicNotFound.
+func (e *ExtrinsicNotFoundError) Is(target error) bool {
+	return target == ErrExtrinsicNotFound
+}
+
+// FindExtrinsic looks for a submitted extrinsic in the blocks of the range, e.g. to find out on restart whether an
+// extrinsic submitted before a crash landed. The bounds are inclusive, and the blocks are scanned from fromBlock
+// to toBlock, backwards if toBlock is below fromBlock, until the extrinsic is found. The blocks above the head
+// aren't scanned.
+// It takes a client, the hash of the extrinsic, and the bounds of the range.
+// It returns the submission result of the extrinsic, with its block, its index in the block, the nonce it was
+// signed with and its events, and a *DispatchError if it failed to dispatch. It returns an *ExtrinsicNotFoundError
+// if no block of the range includes it, or an error if there is an issue.
+func FindExtrinsic(client Client, extHash types.Hash, fromBlock, toBlock uint64) (*SubmitResult, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	api := c.instance()
+
+	header, err := api.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := scanExtrinsic(api, extHash, fromBlock, toBlock, uint64(header.Number))
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, &ExtrinsicNotFoundError{ExtrinsicHash: extHash, FromBlock: fromBlock, ToBlock: toBlock}
+	}
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	finalized, err := isFinalized(api, found.blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SubmitResult{ExtrinsicHash: extHash, BlockHash: found.blockHash, Finalized: finalized}
+	if found.ext.IsSigned() {
+		result.Nonce = uint64(found.ext.Signature.Nonce.Int64())
+	}
+
+	return result, c.dispatchOutcome(api.RPC, meta, result)
+}
+
+// FindRecentExtrinsic looks for a submitted extrinsic in the last blocks, walking back from the head, see
+// FindExtrinsic.
+// It takes a client, the hash of the extrinsic, and the number of blocks to scan.
+func FindRecentExtrinsic(client Client, extHash types.Hash, blocks uint64) (*SubmitResult, error) {
+	if blocks == 0 {
+		return nil, errors.New("no blocks to scan")
+	}
+
+	header, err := client.GetLatestHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	head := uint64(header.Number)
+
+	return FindExtrinsic(client, extHash, head, oldestRecentBlock(head, blocks))
+}
+
+// foundExtrinsic is an extrinsic found in a block.
+type foundExtrinsic struct {
+	blockHash types.Hash
+	ext       types.Extrinsic
+}
+
+// scanExtrinsic scans the blocks from fromBlock to toBlock, both included and capped at the head, for the extrinsic,
+// and returns it and the hash of the block including it, or nil if it isn't found.
+func scanExtrinsic(api *gsrpc.SubstrateAPI, extrinsicHash types.Hash, fromBlock, toBlock, head uint64) (*foundExtrinsic, error) {
+	step := int64(1)
+	if toBlock < fromBlock {
+		fromBlock, toBlock, step = toBlock, fromBlock, -1
+	}
+
+	// fromBlock is now the lower bound, and toBlock the upper one.
+	if fromBlock > head {
+		return nil, nil
+	}
+
+	if toBlock > head {
+		toBlock = head
+	}
+
+	n, last := fromBlock, toBlock
+	if step < 0 {
+		n, last = toBlock, fromBlock
+	}
+
+	for ; ; n = uint64(int64(n) + step) {
+		blockHash, err := api.RPC.Chain.GetBlockHash(n)
+		if err != nil {
+			return nil, err
+		}
+
+		block, err := api.RPC.Chain.GetBlock(blockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ext := range block.Block.Extrinsics {
+			if hash, err := hashExtrinsic(ext); err == nil && hash == extrinsicHash {
+				return &foundExtrinsic{blockHash: blockHash, ext: ext}, nil
+			}
+		}
+
+		if n == last {
+			return nil, nil
+		}
+	}
+}
+
+// oldestRecentBlock returns the number of the oldest of the last blocks up to the head, the genesis block at most.
+func oldestRecentBlock(head, blocks uint64) uint64 {
+	if head < blocks {
+		return 0
+	}
+
+	return head - blocks + 1
+}
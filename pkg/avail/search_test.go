@@ -0,0 +1,100 @@
+package avail
+
+import (
+	"context"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// requestsChain captures the numbers of the requested block hashes.
+type requestsChain struct {
+	chain.Chain
+
+	requested []uint64
+}
+
+func (c *requestsChain) GetBlockHash(n uint64) (types.Hash, error) {
+	c.requested = append(c.requested, n)
+	return c.Chain.GetBlockHash(n)
+}
+
+func TestFindExtrinsic(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	// The data is included in the blocks 1 to 3.
+	var hashes []types.Hash
+
+	for _, data := range []string{"a", "b", "c"} {
+		result, err := SubmitData(context.Background(), m, funder, 1, []byte(data), SubmitOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hashes = append(hashes, result.ExtrinsicHash)
+	}
+
+	ch := &requestsChain{Chain: m.api.RPC.Chain}
+	m.api.RPC.Chain = ch
+
+	testCases := []struct {
+		name      string
+		from, to  uint64
+		requested []uint64
+	}{
+		{"forwards", 0, 3, []uint64{0, 1, 2}},
+		{"backwards", 3, 0, []uint64{3, 2}},
+		{"single block", 2, 2, []uint64{2}},
+		{"above the head", 10, 0, []uint64{3, 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch.requested = nil
+
+			result, err := FindExtrinsic(m, hashes[1], tc.from, tc.to)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, uint64(2), result.BlockNumber)
+			assert.Equal(t, uint32(0), result.ExtrinsicIndex)
+			assert.Equal(t, uint64(1), result.Nonce)
+			assert.True(t, result.Finalized)
+			assert.NotNil(t, result.Events)
+
+			// The scan stops at the block including the extrinsic, which is then checked to be finalized.
+			assert.Equal(t, append(tc.requested, 2), ch.requested)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := FindExtrinsic(m, hashes[1], 3, 3)
+		assert.ErrorIs(t, err, ErrExtrinsicNotFound)
+
+		var notFound *ExtrinsicNotFoundError
+		if assert.ErrorAs(t, err, &notFound) {
+			assert.Equal(t, ExtrinsicNotFoundError{ExtrinsicHash: hashes[1], FromBlock: 3, ToBlock: 3}, *notFound)
+		}
+
+		_, err = FindExtrinsic(m, hashes[0], 4, 10)
+		assert.ErrorIs(t, err, ErrExtrinsicNotFound)
+	})
+
+	t.Run("recent", func(t *testing.T) {
+		result, err := FindRecentExtrinsic(m, hashes[1], 2)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint64(2), result.BlockNumber)
+		}
+
+		_, err = FindRecentExtrinsic(m, hashes[1], 1)
+		assert.ErrorIs(t, err, ErrExtrinsicNotFound)
+
+		result, err = FindRecentExtrinsic(m, hashes[0], 100)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint64(1), result.BlockNumber)
+		}
+	})
+}
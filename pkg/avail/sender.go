@@ -1,6 +1,7 @@
 package avail
 
 import (
+	"context"
 	"fmt"
 
 	edgetypes "github.com/0xPolygon/polygon-edge/types"
@@ -19,8 +20,12 @@ const (
 type Sender interface {
 	// Send sends a block to Avail without waiting for any status response.
 	Send(blk *edgetypes.Block) error
-	// SendAndWaitForStatus sends a block to Avail and waits for the specified extrinsic status.
-	SendAndWaitForStatus(blk *edgetypes.Block, status types.ExtrinsicStatus) error
+	// SendAndWaitForStatus sends a block to Avail and waits for the specified extrinsic status, until the context
+	// is done. It returns the submission result locating the block data on Avail once it's included.
+	SendAndWaitForStatus(ctx context.Context, blk *edgetypes.Block, status types.ExtrinsicStatus) (*SubmitResult, error)
+	// SendAsync sends a block to Avail and returns as soon as it's accepted by the transaction pool. The specified
+	// extrinsic status is waited for in the background, and the outcome delivered by the pending submission.
+	SendAsync(ctx context.Context, blk *edgetypes.Block, status types.ExtrinsicStatus) (*PendingSubmission, error)
 }
 
 // Result represents the final result of block data submission.
@@ -34,9 +39,22 @@ func (t *blackholeSender) Send(blk *edgetypes.Block) error {
 	return nil
 }
 
-// SendAndWaitForStatus ignores the sent block and the specified status.
-func (t *blackholeSender) SendAndWaitForStatus(blk *edgetypes.Block, status types.ExtrinsicStatus) error {
-	return nil
+// SendAndWaitForStatus ignores the sent block and the specified status, and returns an empty submission result.
+func (t *blackholeSender) SendAndWaitForStatus(ctx context.Context, blk *edgetypes.Block, status types.ExtrinsicStatus) (*SubmitResult, error) {
+	return &SubmitResult{}, nil
+}
+
+// SendAsync ignores the sent block and the specified status, and returns a pending submission delivering an empty
+// submission result right away.
+func (t *blackholeSender) SendAsync(ctx context.Context, blk *edgetypes.Block, status types.ExtrinsicStatus) (*PendingSubmission, error) {
+	p := &PendingSubmission{
+		result: make(chan PendingResult, 1),
+		cancel: func() {},
+	}
+
+	p.result <- PendingResult{Result: &SubmitResult{}}
+
+	return p, nil
 }
 
 // NewBlackholeSender constructs an Avail block data sender that ignores sent
@@ -50,23 +68,33 @@ type sender struct {
 	appID          types.UCompact
 	client         Client
 	signingKeyPair signature.KeyringPair
-	nextNonce      uint64
+	nonces         *NonceManager
+	signOpts       SignOpts
 }
 
 // NewSender constructs a block data sender for Avail.
-// It takes a Client instance, appID of type types.UCompact, and a signingKeyPair of type signature.KeyringPair.
+// It takes a Client instance, appID of type types.UCompact, a signingKeyPair of type signature.KeyringPair,
+// the NonceManager handing out the signing account nonces, and the signature options.
+// A nil NonceManager is replaced with a new one.
 // It returns a Sender instance.
-func NewSender(client Client, appID types.UCompact, signingKeyPair signature.KeyringPair) Sender {
+func NewSender(client Client, appID types.UCompact, signingKeyPair signature.KeyringPair, nonces *NonceManager, signOpts SignOpts) Sender {
+	if nonces == nil {
+		nonces = NewNonceManager()
+	}
+
 	return &sender{
 		appID:          appID,
 		client:         client,
 		signingKeyPair: signingKeyPair,
+		nonces:         nonces,
+		signOpts:       signOpts,
 	}
 }
 
 // Send submits data to Avail without waiting for any status response.
 // It takes a blk parameter of type *edgetypes.Block.
-// It returns an error if there was a problem sending the data.
+// It returns an error if there was a problem sending the data, wrapping ErrFeeBudgetExceeded if the submission is
+// withheld by the fee budget of the client, see WithFeeBudget.
 func (s *sender) Send(blk *edgetypes.Block) error {
 	api, err := instance(s.client)
 	if err != nil {
@@ -78,8 +106,22 @@ func (s *sender) Send(blk *edgetypes.Block) error {
 		return err
 	}
 
+	c, err := implementation(s.client)
+	if err != nil {
+		return err
+	}
+
+	spend, err := c.chargeFee(api, c.feeBudget, ext)
+	if err != nil {
+		s.nonces.Resync(s.signingKeyPair)
+		return err
+	}
+
 	_, err = api.RPC.Author.SubmitExtrinsic(ext)
 	if err != nil {
+		// The nonce of the rejected extrinsic isn't used.
+		refundFee(c.feeBudget, spend)
+		s.nonces.Resync(s.signingKeyPair)
 		return err
 	}
 
@@ -87,69 +129,155 @@ func (s *sender) Send(blk *edgetypes.Block) error {
 }
 
 // SendAndWaitForStatus submits data to Avail and does not wait for the future blocks.
-// It takes blk parameter of type *edgetypes.Block and dstatus parameter of type types.ExtrinsicStatus.
-// It returns an error if there was a problem sending the data or if the specified status expectation is not supported.
-func (s *sender) SendAndWaitForStatus(blk *edgetypes.Block, dstatus types.ExtrinsicStatus) error {
+// It takes a context bounding the submission and the wait, blk parameter of type *edgetypes.Block and dstatus
+// parameter of type types.ExtrinsicStatus.
+// It returns the submission result, with the block hash, the block number and the extrinsic index of the data once
+// it's included in a block, and an error if there was a problem sending the data or if the specified status
+// expectation is not supported. It returns a *DispatchError if the included data failed to dispatch, and a
+// *SubmitTimeoutError if the context is done before the data reaches the status, the data staying in the
+// transaction pool.
+// The data is ready once it's accepted by the transaction pool. When the status subscription fails, e.g. on a
+// WebSocket disconnection, the data is looked for in the recent blocks, or resubmitted, following the retry policy
+// of the client, see RetryPolicy, so it's known whether it landed.
+// When waiting for finalization, it returns an error wrapping ErrExtrinsicRetracted or ErrFinalityTimeout if the
+// block including the data won't be finalized. It returns a *FeeBudgetExceededError, without submitting the data,
+// if the submission is withheld by the fee budget of the client, see WithFeeBudget. The statuses of the submission
+// are passed to the status callback of the client, if any, see WithStatusCallback.
+func (s *sender) SendAndWaitForStatus(ctx context.Context, blk *edgetypes.Block, dstatus types.ExtrinsicStatus) (*SubmitResult, error) {
 	// Only these three are supported for now.
-	// NOTE: If adding new types here, handle them correspondingly in the end of
-	//       the function as well!
+	// NOTE: If adding new types here, handle them correspondingly below as well!
 	if !dstatus.IsFinalized && !dstatus.IsReady && !dstatus.IsInBlock {
-		return fmt.Errorf("unsupported extrinsic status expectation: %#v", dstatus)
+		return nil, fmt.Errorf("unsupported extrinsic status expectation: %#v", dstatus)
 	}
 
-	api, err := instance(s.client)
+	c, err := implementation(s.client)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	ext, err := s.prepareExtrinsicForSend(api, blk)
+	api := c.instance()
+
+	meta, err := c.metadata(api)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	sub, err := api.RPC.Author.SubmitAndWatchExtrinsic(ext)
+	sign, err := s.signer(api, blk)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer sub.Unsubscribe()
-
-	for {
-		select {
-		case status := <-sub.Chan():
-			_, err := dstatus.MarshalJSON()
-			if err != nil {
-				panic(err)
-			}
-			// NOTE: See first line of this function for supported extrinsic status expectations.
-			switch {
-			case dstatus.IsFinalized && status.IsFinalized:
-				return nil
-			case dstatus.IsInBlock && status.IsInBlock:
-				return nil
-			case dstatus.IsReady && status.IsReady:
-				return nil
-			default:
-				if status.IsDropped || status.IsInvalid {
-					return fmt.Errorf("unexpected extrinsic status from Avail: %#v", status)
-				}
-			}
-		case err := <-sub.Err():
-			// TODO: Consider re-connecting subscription channel on error?
-			return err
+	// The statuses are reached in order, so the first expected one is waited for.
+	opts := SubmitOpts{Nonces: s.nonces, WaitFor: WaitInBlock}
+	if !dstatus.IsReady && !dstatus.IsInBlock {
+		opts.WaitFor = WaitFinalized
+	}
+
+	sub, ext, nonce, err := c.signAndWatch(ctx, api, meta, s.signingKeyPair, opts, sign)
+	if err != nil {
+		return nil, err
+	}
+
+	if dstatus.IsReady {
+		sub.Unsubscribe()
+
+		extrinsicHash, err := hashExtrinsic(ext)
+		if err != nil {
+			return nil, err
 		}
+
+		return &SubmitResult{ExtrinsicHash: extrinsicHash, Nonce: nonce}, nil
+	}
+
+	result, err := c.awaitInclusion(ctx, sub, ext, s.signingKeyPair, nonce, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The data is located in the block including it, and its dispatch checked.
+	return result, c.dispatchOutcome(api.RPC, meta, result)
+}
+
+// SendAsync submits data to Avail and returns as soon as it's accepted by the transaction pool, so the caller, e.g.
+// the block production, doesn't wait for the data to be included or finalized.
+// It takes a context bounding the submission and the watch, blk parameter of type *edgetypes.Block and dstatus
+// parameter of type types.ExtrinsicStatus, which must expect the data to be in a block or finalized.
+// The status is watched in the background, like SendAndWaitForStatus does, and the outcome, the submission result
+// or one of the errors SendAndWaitForStatus returns, is delivered by the pending submission. The watch counts
+// towards the maximum number of submissions watched by the client, see WithMaxPendingSubmissions, and it's
+// cancelled once the context is done or the client is closed.
+// It returns the pending submission, and an error if the data wasn't accepted, in which case nothing is watched.
+func (s *sender) SendAsync(ctx context.Context, blk *edgetypes.Block, dstatus types.ExtrinsicStatus) (*PendingSubmission, error) {
+	if !dstatus.IsFinalized && !dstatus.IsInBlock {
+		return nil, fmt.Errorf("unsupported extrinsic status expectation: %#v", dstatus)
+	}
+
+	c, err := implementation(s.client)
+	if err != nil {
+		return nil, err
+	}
+
+	api := c.instance()
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := s.signer(api, blk)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := SubmitOpts{Nonces: s.nonces, WaitFor: WaitInBlock}
+	if !dstatus.IsInBlock {
+		opts.WaitFor = WaitFinalized
 	}
+
+	return c.submitAsync(ctx, api, meta, s.signingKeyPair, opts, sign)
 }
 
-// prepareExtrinsicForSend prepares the extrinsic for sending the block data.
+// prepareExtrinsicForSend prepares the extrinsic for sending the block data, signed with the next nonce.
 // It takes api parameter of type *gsrpc.SubstrateAPI and blk parameter of type *edgetypes.Block.
 // It returns a types.Extrinsic and an error if there was a problem preparing the extrinsic.
 func (s *sender) prepareExtrinsicForSend(api *gsrpc.SubstrateAPI, blk *edgetypes.Block) (types.Extrinsic, error) {
-	meta, err := api.RPC.State.GetMetadataLatest()
+	sign, err := s.signer(api, blk)
+	if err != nil {
+		return types.Extrinsic{}, err
+	}
+
+	nonce, err := s.nonces.next(api, s.signingKeyPair)
 	if err != nil {
 		return types.Extrinsic{}, err
 	}
 
+	ext, err := sign(nonce)
+	if err != nil {
+		// The nonce isn't used by the unsigned extrinsic.
+		s.nonces.Resync(s.signingKeyPair)
+		return types.Extrinsic{}, err
+	}
+
+	return ext, nil
+}
+
+// signer returns the function signing the extrinsic submitting the block data with a nonce.
+func (s *sender) signer(api *gsrpc.SubstrateAPI, blk *edgetypes.Block) (signFunc, error) {
+	c, err := implementation(s.client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, err
+	}
+
+	constants, err := c.constantsOf(meta)
+	if err != nil {
+		return nil, err
+	}
+
 	blob := Blob{
 		Magic: BlobMagic,
 		Data:  blk.MarshalRLP(),
@@ -164,55 +292,51 @@ func (s *sender) prepareExtrinsicForSend(api *gsrpc.SubstrateAPI, blk *edgetypes
 		// requires further investigation to fix.
 		encodedBytes, err := codec.Encode(blob)
 		if err != nil {
-			return types.Extrinsic{}, err
+			return nil, err
+		}
+
+		if encodedBytes, err = c.compress(encodedBytes); err != nil {
+			return nil, err
+		}
+
+		if len(encodedBytes) > constants.MaxAppDataLength {
+			return nil, fmt.Errorf("%w: block %d encodes to %d bytes, maximum is %d", ErrDataTooLarge, blk.Number(), len(encodedBytes), constants.MaxAppDataLength)
 		}
 
 		call, err = types.NewCall(meta, CallSubmitData, encodedBytes)
 		if err != nil {
-			return types.Extrinsic{}, err
+			return nil, err
 		}
 	}
 
-	ext := types.NewExtrinsic(call)
-
-	rv, err := api.RPC.State.GetRuntimeVersionLatest()
+	rv, err := c.runtimeVersion(api)
 	if err != nil {
-		return types.Extrinsic{}, err
+		return nil, err
 	}
 
-	key, err := types.CreateStorageKey(meta, "System", "Account", s.signingKeyPair.PublicKey)
+	era, blockHash, err := signingEra(api.RPC, s.client.GenesisHash(), s.signOpts)
 	if err != nil {
-		return types.Extrinsic{}, err
-	}
-
-	var accountInfo types.AccountInfo
-	ok, err := api.RPC.State.GetStorageLatest(key, &accountInfo)
-	if err != nil || !ok {
-		return types.Extrinsic{}, fmt.Errorf("couldn't fetch latest account storage info")
+		return nil, err
 	}
 
-	nonce := uint64(accountInfo.Nonce)
-	if s.nextNonce > nonce {
-		nonce = s.nextNonce
-	}
-	s.nextNonce = nonce + 1
-	o := types.SignatureOptions{
-		// This transaction is Immortal (https://wiki.polkadot.network/docs/build-protocol-info#transaction-mortality)
-		// Hence BlockHash: Genesis Hash.
-		BlockHash:          s.client.GenesisHash(),
-		Era:                types.ExtrinsicEra{IsMortalEra: false},
-		GenesisHash:        s.client.GenesisHash(),
-		Nonce:              types.NewUCompactFromUInt(nonce),
-		SpecVersion:        rv.SpecVersion,
-		Tip:                types.NewUCompactFromUInt(100),
-		AppID:              s.appID,
-		TransactionVersion: rv.TransactionVersion,
-	}
+	return func(nonce uint64) (types.Extrinsic, error) {
+		ext := types.NewExtrinsic(call)
+
+		o := types.SignatureOptions{
+			// An immortal transaction (https://wiki.polkadot.network/docs/build-protocol-info#transaction-mortality)
+			// is checked against the genesis hash, a mortal one against the block its era starts at.
+			BlockHash:          blockHash,
+			Era:                era,
+			GenesisHash:        s.client.GenesisHash(),
+			Nonce:              types.NewUCompactFromUInt(nonce),
+			SpecVersion:        rv.SpecVersion,
+			Tip:                s.signOpts.tipOr(100),
+			AppID:              s.appID,
+			TransactionVersion: rv.TransactionVersion,
+		}
 
-	err = ext.Sign(s.signingKeyPair, o)
-	if err != nil {
-		return types.Extrinsic{}, err
-	}
+		err := ext.Sign(s.signingKeyPair, o)
 
-	return ext, nil
+		return ext, err
+	}, nil
 }
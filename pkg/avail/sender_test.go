@@ -0,0 +1,124 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	edgetypes "github.com/0xPolygon/polygon-edge/types"
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSenderGivesBackUnusedNonces(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	if err := m.SetNonce(account, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSender(m, types.NewUCompactFromUInt(1), account, NewNonceManager(), SignOpts{})
+	blk := &edgetypes.Block{Header: &edgetypes.Header{Number: 1}}
+
+	// The block data isn't accepted by the transaction pool, e.g. on a connection error.
+	m.Script(MockOutcome{Err: errors.New("connection reset by peer")})
+
+	assert.Error(t, s.Send(blk))
+	assert.NoError(t, s.Send(blk))
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 2) {
+		assert.Equal(t, types.NewUCompactFromUInt(3), submitted[0].Signature.Nonce)
+		assert.Equal(t, types.NewUCompactFromUInt(3), submitted[1].Signature.Nonce)
+	}
+}
+
+func TestSenderSendAsync(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	s := NewSender(m, types.NewUCompactFromUInt(1), account, NewNonceManager(), SignOpts{})
+	blk := &edgetypes.Block{Header: &edgetypes.Header{Number: 1}}
+
+	p, err := s.SendAsync(context.Background(), blk, types.ExtrinsicStatus{IsFinalized: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	outcome := pendingResult(t, p)
+	if assert.NoError(t, outcome.Err) {
+		assert.Equal(t, p.ExtrinsicHash, outcome.Result.ExtrinsicHash)
+		assert.True(t, outcome.Result.Finalized)
+	}
+
+	// Only the statuses reached after the data is accepted can be waited for in the background.
+	_, err = s.SendAsync(context.Background(), blk, types.ExtrinsicStatus{IsReady: true})
+	assert.Error(t, err)
+
+	// The data rejected by the transaction pool isn't watched.
+	m.Script(MockOutcome{Err: errors.New("1010: Invalid Transaction")})
+
+	_, err = s.SendAsync(context.Background(), blk, types.ExtrinsicStatus{IsInBlock: true})
+	assert.EqualError(t, err, "1010: Invalid Transaction")
+	assert.Empty(t, m.pendingSubmissions)
+
+	p, err = NewBlackholeSender().SendAsync(context.Background(), blk, types.ExtrinsicStatus{IsFinalized: true})
+	if assert.NoError(t, err) {
+		assert.NoError(t, pendingResult(t, p).Err)
+	}
+}
+
+func TestSenderSendAndWaitForStatus(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	s := NewSender(m, types.NewUCompactFromUInt(1), account, NewNonceManager(), SignOpts{})
+	blk := &edgetypes.Block{Header: &edgetypes.Header{Number: 1}}
+
+	result, err := s.SendAndWaitForStatus(context.Background(), blk, types.ExtrinsicStatus{IsFinalized: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(1), result.BlockNumber)
+		assert.True(t, result.Finalized)
+		assert.Equal(t, uint64(0), result.Nonce)
+	}
+
+	// The data is ready once it's accepted by the transaction pool.
+	result, err = s.SendAndWaitForStatus(context.Background(), blk, types.ExtrinsicStatus{IsReady: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.Hash{}, result.BlockHash)
+		assert.Equal(t, uint64(1), result.Nonce)
+	}
+
+	// The status subscription fails once the data is in a block, which is found after reconnecting.
+	m.client.submitAndWatch = func(_ *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+		if _, err := m.submit(ext); err != nil {
+			return nil, err
+		}
+
+		return newFakeSubscription(errConnectionReset), nil
+	}
+
+	result, err = s.SendAndWaitForStatus(context.Background(), blk, types.ExtrinsicStatus{IsInBlock: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(3), result.BlockNumber)
+		assert.Equal(t, uint64(2), result.Nonce)
+	}
+
+	assert.Len(t, m.Submitted(), 3)
+
+	_, err = s.SendAndWaitForStatus(context.Background(), blk, types.ExtrinsicStatus{IsFuture: true})
+	assert.Error(t, err)
+
+	// The wait is bounded by the context, the data staying in the transaction pool.
+	m.client.submitAndWatch = func(_ *gsrpc.SubstrateAPI, _ types.Extrinsic) (extrinsicSubscription, error) {
+		return newFakeSubscription(nil, types.ExtrinsicStatus{IsReady: true}), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = s.SendAndWaitForStatus(ctx, blk, types.ExtrinsicStatus{IsFinalized: true})
+
+	var timeoutErr *SubmitTimeoutError
+	assert.ErrorAs(t, err, &timeoutErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
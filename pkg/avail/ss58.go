@@ -0,0 +1,136 @@
+package avail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/decred/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+// DefaultSS58Prefix returns the SS58 network prefix of the addresses of the accounts created by the package, 42
+// being the generic substrate prefix, unless it was set to the prefix of the chain with UseChainDefaults.
+func DefaultSS58Prefix() uint16 {
+	chainDefaults.lock.RLock()
+	defer chainDefaults.lock.RUnlock()
+
+	return chainDefaults.ss58Prefix
+}
+
+// ErrInvalidSS58Address is the error returned when an SS58 address is malformed, or its checksum doesn't match.
+var ErrInvalidSS58Address = errors.New("invalid SS58 address")
+
+const (
+	// maxSS58Prefix is the largest network prefix encodable in an SS58 address.
+	maxSS58Prefix = 1<<14 - 1
+
+	ss58PublicKeyLength = 32
+	ss58ChecksumLength  = 2
+)
+
+// ss58ChecksumPrefix is prepended to the address payload when hashing its checksum.
+var ss58ChecksumPrefix = []byte("SS58PRE")
+
+// ToSS58 encodes the 32 bytes public key to an SS58 address of the network with the prefix, between 0 and 16383.
+// It returns the address, or an error if the public key or the prefix are invalid.
+func ToSS58(pubKey []byte, prefix uint16) (string, error) {
+	if len(pubKey) != ss58PublicKeyLength {
+		return "", fmt.Errorf("invalid public key length %d, expected %d", len(pubKey), ss58PublicKeyLength)
+	}
+
+	if prefix > maxSS58Prefix {
+		return "", fmt.Errorf("invalid SS58 prefix %d, maximum is %d", prefix, maxSS58Prefix)
+	}
+
+	var payload []byte
+	if prefix < 64 {
+		payload = []byte{byte(prefix)}
+	} else {
+		payload = []byte{
+			byte((prefix&0xfc)>>2) | 0x40,
+			byte(prefix>>8) | byte(prefix&0x03)<<6,
+		}
+	}
+
+	payload = append(payload, pubKey...)
+
+	return base58.Encode(append(payload, ss58Checksum(payload)...)), nil
+}
+
+// FromSS58 decodes the SS58 address, and validates its checksum.
+// It returns the public key and the network prefix of the address, or an error wrapping ErrInvalidSS58Address
+// if the address is malformed.
+func FromSS58(addr string) ([]byte, uint16, error) {
+	decoded := base58.Decode(addr)
+	if len(decoded) == 0 {
+		return nil, 0, fmt.Errorf("%w: %q isn't base58 encoded", ErrInvalidSS58Address, addr)
+	}
+
+	var (
+		prefix       uint16
+		prefixLength int
+	)
+
+	// The network prefixes below 64 take one byte, the other ones two bytes.
+	switch first := decoded[0]; {
+	case first < 64:
+		prefix, prefixLength = uint16(first), 1
+	case first < 128 && len(decoded) > 1:
+		second := decoded[1]
+		lower := (first&0x3f)<<2 | second>>6
+		prefix, prefixLength = uint16(lower)|uint16(second&0x3f)<<8, 2
+	default:
+		return nil, 0, fmt.Errorf("%w: %q has an invalid network prefix", ErrInvalidSS58Address, addr)
+	}
+
+	if len(decoded) != prefixLength+ss58PublicKeyLength+ss58ChecksumLength {
+		return nil, 0, fmt.Errorf("%w: %q has an invalid length", ErrInvalidSS58Address, addr)
+	}
+
+	payload, checksum := decoded[:len(decoded)-ss58ChecksumLength], decoded[len(decoded)-ss58ChecksumLength:]
+	if !bytes.Equal(ss58Checksum(payload), checksum) {
+		return nil, 0, fmt.Errorf("%w: %q has an invalid checksum", ErrInvalidSS58Address, addr)
+	}
+
+	return payload[prefixLength:], prefix, nil
+}
+
+// ss58Checksum returns the checksum of the SS58 address payload, i.e. the prefix and the public key.
+func ss58Checksum(payload []byte) []byte {
+	hash := blake2b.Sum512(append(append([]byte{}, ss58ChecksumPrefix...), payload...))
+	return hash[:ss58ChecksumLength]
+}
+
+// ss58Address returns the SS58 address of the public key with the default prefix, for logs and errors.
+// It falls back to the hex encoding of the public key if it can't be encoded.
+func ss58Address(pubKey []byte) string {
+	addr, err := ToSS58(pubKey, DefaultSS58Prefix())
+	if err != nil {
+		return fmt.Sprintf("%#x", pubKey)
+	}
+
+	return addr
+}
+
+// ss58PrefixOr returns the first of the optional prefixes, or DefaultSS58Prefix without one.
+func ss58PrefixOr(prefix []uint16) uint16 {
+	if len(prefix) > 0 {
+		return prefix[0]
+	}
+
+	return DefaultSS58Prefix()
+}
+
+// withSS58Prefix sets the address of the key pair to the SS58 address of its public key with the prefix.
+func withSS58Prefix(keyPair signature.KeyringPair, prefix uint16) (signature.KeyringPair, error) {
+	addr, err := ToSS58(keyPair.PublicKey, prefix)
+	if err != nil {
+		return signature.KeyringPair{}, err
+	}
+
+	keyPair.Address = addr
+
+	return keyPair, nil
+}
@@ -0,0 +1,76 @@
+package avail
+
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSS58(t *testing.T) {
+	alice := signature.TestKeyringPairAlice.PublicKey
+
+	tt := []struct {
+		prefix  uint16
+		address string
+	}{
+		{prefix: 42, address: "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+		{prefix: 0, address: "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"},
+		{prefix: 2, address: "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F"},
+		{prefix: 64},
+		{prefix: 255},
+		{prefix: 16383},
+	}
+
+	for _, tc := range tt {
+		address, err := ToSS58(alice, tc.prefix)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		if tc.address != "" {
+			assert.Equal(t, tc.address, address)
+		}
+
+		pubKey, prefix, err := FromSS58(address)
+		assert.NoError(t, err)
+		assert.Equal(t, alice, pubKey)
+		assert.Equal(t, tc.prefix, prefix)
+	}
+}
+
+func TestSS58Invalid(t *testing.T) {
+	alice := signature.TestKeyringPairAlice.PublicKey
+
+	_, err := ToSS58(alice[:31], 42)
+	assert.Error(t, err)
+
+	_, err = ToSS58(alice, 16384)
+	assert.Error(t, err)
+
+	for _, address := range []string{
+		// The last character is altered, so the checksum doesn't match.
+		"5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQZ",
+		// The public key is truncated.
+		"5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKu",
+		"",
+		"0OIl",
+	} {
+		_, _, err := FromSS58(address)
+		assert.ErrorIs(t, err, ErrInvalidSS58Address, address)
+	}
+}
+
+func TestDefaultSS58Prefix(t *testing.T) {
+	defer setChainDefaults(DefaultSS58Prefix(), DefaultTokenDecimals())
+
+	setChainDefaults(0, DefaultTokenDecimals())
+
+	account, err := DeriveAccount(devPhrase, "//Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5", account.Address)
+	assert.Equal(t, signature.TestKeyringPairAlice.PublicKey, account.PublicKey)
+}
@@ -0,0 +1,109 @@
+package avail
+
+import (
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// statusCallbackBuffer is the number of statuses of a submission buffered for its status callback. A submission
+// rarely goes through more than a handful of statuses, so only a stuck callback fills it up.
+const statusCallbackBuffer = 16
+
+// WithStatusCallback sets the callback called with every status of the extrinsics submitted by the client, e.g. to
+// log the progress of the submissions waiting for finality or to record metrics, unless one is set by the submission
+// options, see SubmitOpts.OnStatus, including the ones of the Sender and the SubmitQueue.
+// The callback is called with the statuses observed on the status subscription of the extrinsic, Ready, Broadcast,
+// InBlock, Finalized, Dropped and so on, in order, including the ones of a resubmission after a reconnection.
+// It's called on a goroutine of its own for each submission, so it's never called concurrently for the same
+// submission, but it is for different ones, and it may still be called once the submission returned.
+// It must not block: the watch of the extrinsic never waits for it, and the statuses are dropped, which is logged,
+// while statusCallbackBuffer are waiting for it.
+func WithStatusCallback(callback func(types.ExtrinsicStatus)) ClientOption {
+	return func(c *client) {
+		c.onStatus = callback
+	}
+}
+
+// onStatusOr returns the status callback of the submission options, or the one of the client if none was given.
+func (o SubmitOpts) onStatusOr(callback func(types.ExtrinsicStatus)) func(types.ExtrinsicStatus) {
+	if o.OnStatus != nil {
+		return o.OnStatus
+	}
+
+	return callback
+}
+
+// statusDispatcher calls the status callback of a submission with its statuses, in order, on a goroutine of its own,
+// so the watch of the extrinsic doesn't wait for the callback. A nil dispatcher, without a callback, is a no-op.
+type statusDispatcher struct {
+	logger   hclog.Logger
+	statuses chan types.ExtrinsicStatus
+}
+
+// dispatchStatuses starts the dispatch of the statuses of a submission to the status callback of the options, or
+// of the client, and returns the dispatcher to close once the submission is done, nil without a callback.
+func (c *client) dispatchStatuses(opts SubmitOpts) *statusDispatcher {
+	callback := opts.onStatusOr(c.onStatus)
+	if callback == nil {
+		return nil
+	}
+
+	d := &statusDispatcher{logger: c.logger, statuses: make(chan types.ExtrinsicStatus, statusCallbackBuffer)}
+
+	go func() {
+		for status := range d.statuses {
+			callback(status)
+		}
+	}()
+
+	return d
+}
+
+// observe hands the status over to the callback, or drops it if the callback fell statusCallbackBuffer statuses
+// behind.
+func (d *statusDispatcher) observe(status types.ExtrinsicStatus) {
+	if d == nil {
+		return
+	}
+
+	select {
+	case d.statuses <- status:
+	default:
+		d.logger.Warn("extrinsic status callback falling behind, dropping status", "status", statusName(status))
+	}
+}
+
+// close ends the dispatch once the buffered statuses are handed over to the callback, without waiting for it.
+func (d *statusDispatcher) close() {
+	if d != nil {
+		close(d.statuses)
+	}
+}
+
+// statusName returns the name of the kind of the status, as in the JSON-RPC API of the node.
+func statusName(status types.ExtrinsicStatus) string {
+	switch {
+	case status.IsFuture:
+		return "future"
+	case status.IsReady:
+		return "ready"
+	case status.IsBroadcast:
+		return "broadcast"
+	case status.IsInBlock:
+		return "inBlock"
+	case status.IsRetracted:
+		return "retracted"
+	case status.IsFinalityTimeout:
+		return "finalityTimeout"
+	case status.IsFinalized:
+		return "finalized"
+	case status.IsUsurped:
+		return "usurped"
+	case status.IsDropped:
+		return "dropped"
+	case status.IsInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
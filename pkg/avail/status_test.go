@@ -0,0 +1,122 @@
+package avail
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusRecorder is a status callback recording the names of the statuses.
+type statusRecorder chan string
+
+func (r statusRecorder) callback(status types.ExtrinsicStatus) { r <- statusName(status) }
+
+// next returns the names of the next n statuses, failing the test if they aren't dispatched in time.
+func (r statusRecorder) next(t *testing.T, n int) []string {
+	t.Helper()
+
+	var names []string
+
+	for len(names) < n {
+		select {
+		case name := <-r:
+			names = append(names, name)
+		case <-time.After(time.Second):
+			t.Fatalf("statuses %v dispatched, want %d", names, n)
+		}
+	}
+
+	return names
+}
+
+func TestStatusCallback(t *testing.T) {
+	ready := types.ExtrinsicStatus{IsReady: true}
+	broadcast := types.ExtrinsicStatus{IsBroadcast: true, AsBroadcast: []types.Text{"peer"}}
+
+	wt := newWatchTest(t, testRetryPolicy,
+		newFakeSubscription(nil, ready, broadcast, inBlockStatus),
+		newFakeSubscription(nil, ready, inBlockStatus, finalizedStatus),
+	)
+
+	client, opts := make(statusRecorder, 8), make(statusRecorder, 8)
+	WithStatusCallback(client.callback)(wt.c)
+
+	assert.NoError(t, wt.deposit(t))
+	assert.Equal(t, []string{"ready", "broadcast", "inBlock"}, client.next(t, 3))
+
+	// The callback of the options overrides the one of the client.
+	assert.NoError(t, wt.depositWith(t, context.Background(), SubmitOpts{WaitFor: WaitFinalized, OnStatus: opts.callback}))
+	assert.Equal(t, []string{"ready", "inBlock", "finalized"}, opts.next(t, 3))
+	assert.Empty(t, client)
+}
+
+func TestStatusCallbackOfMockClient(t *testing.T) {
+	statuses := make(statusRecorder, 8)
+
+	m, err := NewMockClient(WithStatusCallback(statuses.callback))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(account, new(big.Int).SetUint64(AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = SubmitData(context.Background(), m, account, 1, []byte("data"), SubmitOpts{WaitFor: WaitFinalized})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ready", "inBlock", "finalized"}, statuses.next(t, 3))
+}
+
+func TestStatusCallbackDoesNotBlockWatch(t *testing.T) {
+	broadcasts := make([]types.ExtrinsicStatus, 2*statusCallbackBuffer)
+	for i := range broadcasts {
+		broadcasts[i] = types.ExtrinsicStatus{IsBroadcast: true}
+	}
+
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(nil, append(broadcasts, inBlockStatus)...))
+
+	// The callback is stuck until the submission returned.
+	unblock := make(chan struct{})
+	names := make(statusRecorder, len(broadcasts)+1)
+
+	WithStatusCallback(func(status types.ExtrinsicStatus) {
+		<-unblock
+		names.callback(status)
+	})(wt.c)
+
+	done := make(chan error, 1)
+	go func() { done <- wt.deposit(t) }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the watch waited for the status callback")
+	}
+
+	close(unblock)
+
+	// The statuses over the buffer are dropped, the ones dispatched still in order.
+	dispatched := names.next(t, statusCallbackBuffer)
+
+	time.Sleep(50 * time.Millisecond)
+
+	for len(names) > 0 {
+		dispatched = append(dispatched, <-names)
+	}
+
+	assert.LessOrEqual(t, len(dispatched), statusCallbackBuffer+1)
+
+	for _, name := range dispatched {
+		assert.Equal(t, "broadcast", name)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"sync/atomic"
 
-	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/hashicorp/go-hclog"
 )
@@ -23,7 +22,7 @@ type blockStream struct {
 	closed  *atomic.Bool
 	closeCh chan struct{}
 	dataCh  chan *types.SignedBlock
-	api     *gsrpc.SubstrateAPI
+	client  *client
 	logger  hclog.Logger
 	offset  uint64
 }
@@ -32,7 +31,7 @@ type blockStream struct {
 // It takes a client of type Client, a logger of type hclog.Logger, and an offset of type uint64.
 // It returns a BlockStream instance.
 func newBlockStream(client Client, logger hclog.Logger, offset uint64) BlockStream {
-	api, err := instance(client)
+	c, err := implementation(client)
 	if err != nil {
 		panic("unsupported client in newBlockStream()")
 	}
@@ -41,7 +40,7 @@ func newBlockStream(client Client, logger hclog.Logger, offset uint64) BlockStre
 		closed:  new(atomic.Bool),
 		closeCh: make(chan struct{}),
 		dataCh:  make(chan *types.SignedBlock),
-		api:     api,
+		client:  c,
 		logger:  logger.Named("blockstream"),
 		offset:  offset,
 	}
@@ -65,7 +64,12 @@ func (bs *blockStream) Chan() <-chan *types.SignedBlock {
 
 // watch continuously watches for new blocks and sends them to the data channel.
 func (bs *blockStream) watch() {
-	hdr, err := bs.api.RPC.Chain.GetHeaderLatest()
+	if err := bs.client.requireSubscriptions("BlockStream"); err != nil {
+		bs.logger.Error("couldn't stream Avail blocks", "error", err)
+		return
+	}
+
+	hdr, err := bs.client.instance().RPC.Chain.GetHeaderLatest()
 	if err != nil {
 		bs.logger.Error("couldn't fetch latest block hash", "error", err)
 		return
@@ -84,7 +88,8 @@ func (bs *blockStream) watch() {
 
 	latestBlockNumber := hdr.Number + 1
 	for {
-		subscription, err := bs.api.RPC.Chain.SubscribeNewHeads()
+		// The api is looked up on every subscription, so it's restarted with the new endpoint after a failover.
+		subscription, err := bs.client.instance().RPC.Chain.SubscribeNewHeads()
 		if err != nil {
 			bs.logger.Error("failed to subscribe to new heads", "error", err)
 			return
@@ -118,7 +123,7 @@ func (bs *blockStream) watch() {
 					continue
 				}
 
-				blockHash, err := bs.api.RPC.Chain.GetBlockHash(uint64(hdr.Number))
+				blockHash, err := bs.client.instance().RPC.Chain.GetBlockHash(uint64(hdr.Number))
 				if err != nil {
 					bs.logger.Error("couldn't fetch block hash for block", "block_number", hdr.Number, "error", err)
 					continue
@@ -126,7 +131,7 @@ func (bs *blockStream) watch() {
 
 				bs.logger.Info("Received new avail block", "nbr", hdr.Number, "hash", blockHash.Hex())
 
-				blk, err := bs.api.RPC.Chain.GetBlock(blockHash)
+				blk, err := bs.client.instance().RPC.Chain.GetBlock(blockHash)
 				if err != nil {
 					bs.logger.Error("couldn't fetch block", "block_number", hdr.Number, "block_hash", blockHash, "error", err)
 					continue
@@ -153,13 +158,15 @@ func (bs *blockStream) watch() {
 func (bs *blockStream) catchUp(fromOffset, toOffset uint64) (err error) {
 	// Have we reached the HEAD?
 	for i := fromOffset; i <= toOffset; i++ {
-		blockHash, err := bs.api.RPC.Chain.GetBlockHash(i)
+		api := bs.client.reader()
+
+		blockHash, err := api.RPC.Chain.GetBlockHash(i)
 		if err != nil {
 			bs.logger.Error("couldn't fetch block hash for block", "block_number", i, "error", err)
 			continue
 		}
 
-		blk, err := bs.api.RPC.Chain.GetBlock(blockHash)
+		blk, err := api.RPC.Chain.GetBlock(blockHash)
 		if err != nil {
 			bs.logger.Error("couldn't fetch block", "block_number", i, "block_hash", blockHash, "error", err)
 			continue
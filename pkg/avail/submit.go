@@ -0,0 +1,235 @@
+package avail
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"golang.org/x/crypto/blake2b"
+)
+
+// DefaultMaxAppDataLength is the maximum length of the data of a single submission, in bytes, used when
+// the chain metadata doesn't expose the DataAvailability.MaxAppDataLength constant.
+const DefaultMaxAppDataLength = 512 * 1024
+
+// ErrDataTooLarge is the error returned by SubmitData when the data exceeds the maximum length accepted by the chain.
+var ErrDataTooLarge = errors.New("data exceeds the maximum Avail submission length")
+
+// SubmitOpts are the options of an extrinsic submission. The tip of the submission is set on the signature options.
+type SubmitOpts struct {
+	SignOpts
+
+	// Nonces hands out the nonce of the submission. If it's nil, the nonce is looked up on chain.
+	Nonces *NonceManager
+	// WaitFor is the status of the submission waited for, WaitInBlock by default.
+	WaitFor WaitFor
+	// DryRun dry runs the signed extrinsic before submitting it, so a call failing to dispatch, e.g. after a runtime
+	// upgrade, doesn't cost a nonce and a fee. The submission is aborted with an error wrapping ErrDryRunFailed then.
+	DryRun bool
+	// AllowDeath transfers with CallTransfer, which reaps the sender account, and loses its nonce, if its balance
+	// drops below the existential deposit. The transfers use CallTransferKeepAlive, failing instead, by default.
+	// It's only used by the transfers.
+	AllowDeath bool
+	// FeeBudget caps the fees of the submissions, the one of the client set with WithFeeBudget if nil. A submission
+	// over the budget is withheld with an error wrapping ErrFeeBudgetExceeded, see FeeBudget.
+	FeeBudget *FeeBudget
+	// OnStatus is called with every status of the submitted extrinsic, the callback of the client set with
+	// WithStatusCallback if nil, see WithStatusCallback.
+	OnStatus func(types.ExtrinsicStatus)
+}
+
+// SubmitResult is the result of an extrinsic submission, locating the extrinsic on Avail, e.g. to later prove that
+// a blob was posted.
+type SubmitResult struct {
+	// ExtrinsicHash is the hash of the submitted extrinsic.
+	ExtrinsicHash types.Hash
+	// BlockHash and BlockNumber are the hash and the number of the block the submission was included in.
+	BlockHash   types.Hash
+	BlockNumber uint64
+	// ExtrinsicIndex is the index of the extrinsic in the block.
+	ExtrinsicIndex uint32
+	// Finalized is whether the block was finalized when the submission returned, which is always the case
+	// when waiting for finalization.
+	Finalized bool
+	// Nonce is the nonce the submission was signed with.
+	Nonce uint64
+	// Events are the events of the block the submission was included in, e.g. to look up the events emitted
+	// by the dispatch of the call.
+	Events *ExtrinsicEvents
+}
+
+// SubmitData submits the data to Avail under the AppID, signed with the account, and waits for the submission
+// to be included in a block.
+// It takes a context bounding the wait for the inclusion, a client, the account key pair, the AppID, the data
+// and the submission options.
+// It returns the submission result, and an error wrapping ErrDataTooLarge if the data exceeds the maximum
+// length accepted by the chain once compressed with the codec of the client, if any, see WithCompression, in which
+// case nothing is submitted, a *SubmitTimeoutError if the context is
+// done before the inclusion, or an error if there is an issue. The result of an included submission is returned
+// even with an error, e.g. a *DispatchError if the submission failed to dispatch.
+func SubmitData(ctx context.Context, client Client, account signature.KeyringPair, appID uint32, data []byte, opts SubmitOpts) (*SubmitResult, error) {
+	api, err := instance(client)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := latestMetadata(client, api)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := c.submitDataCall(meta, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return submitExtrinsic(ctx, client, api, meta, account, CallSubmitData, call, appID, opts)
+}
+
+// submitDataCall returns the DataAvailability.submit_data call of the data, compressed with the codec of the client,
+// and records its length. It returns an error wrapping ErrDataTooLarge if the data exceeds the maximum length
+// accepted by the chain.
+func (c *client) submitDataCall(meta *types.Metadata, data []byte) (types.Call, error) {
+	constants, err := c.constantsOf(meta)
+	if err != nil {
+		return types.Call{}, err
+	}
+
+	if data, err = c.compress(data); err != nil {
+		return types.Call{}, err
+	}
+
+	if len(data) > constants.MaxAppDataLength {
+		return types.Call{}, fmt.Errorf("%w: %d bytes, maximum is %d", ErrDataTooLarge, len(data), constants.MaxAppDataLength)
+	}
+
+	call, err := types.NewCall(meta, CallSubmitData, data)
+	if err != nil {
+		return types.Call{}, err
+	}
+
+	c.submissionMetrics().DataSubmitted(len(data))
+
+	return call, nil
+}
+
+// submitExtrinsic signs the call with the account and the AppID, submits it, and waits for its inclusion,
+// or its finalization if requested by the options, until the context is done. The submission is recorded
+// by the metrics of the client under the call name.
+// Once the extrinsic is included, the result is returned with a *DispatchError if the extrinsic failed to dispatch,
+// or an error if its events couldn't be fetched. The statuses of the extrinsic are passed to the status callback of
+// the options, or of the client, if any, see WithStatusCallback.
+func submitExtrinsic(ctx context.Context, client Client, api *gsrpc.SubstrateAPI, meta *types.Metadata, account signature.KeyringPair, callName string, call types.Call, appID uint32, opts SubmitOpts) (*SubmitResult, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := c.callSigner(api, account, call, appID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	sub, signed, nonce, err := c.signAndWatch(ctx, api, meta, account, opts, sign)
+	if err != nil {
+		c.recordSubmission(callName, opts.WaitFor, start, err)
+		return nil, err
+	}
+
+	defer sub.Unsubscribe()
+
+	statuses := c.dispatchStatuses(opts)
+	defer statuses.close()
+
+	extrinsicHash, err := hashExtrinsic(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SubmitResult{
+		ExtrinsicHash: extrinsicHash,
+		Nonce:         nonce,
+	}
+
+	result.BlockHash, result.Finalized, err = waitForInclusion(ctx, sub, extrinsicHash, account, opts.Nonces, opts.WaitFor, statuses)
+	c.recordSubmission(callName, opts.WaitFor, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, c.dispatchOutcome(api.RPC, meta, result)
+}
+
+// callSigner returns the function signing the call with the account and the AppID, with the era and the tip of
+// the options, and the cached runtime version of the client.
+func (c *client) callSigner(api *gsrpc.SubstrateAPI, account signature.KeyringPair, call types.Call, appID uint32, opts SubmitOpts) (signFunc, error) {
+	ext := types.NewExtrinsic(call)
+
+	rv, err := c.runtimeVersion(api)
+	if err != nil {
+		return nil, err
+	}
+
+	era, blockHash, err := signingEra(api.RPC, c.genesisHash, opts.SignOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(nonce uint64) (types.Extrinsic, error) {
+		o := types.SignatureOptions{
+			// An immortal transaction (https://wiki.polkadot.network/docs/build-protocol-info#transaction-mortality)
+			// is checked against the genesis hash, a mortal one against the block its era starts at.
+			BlockHash:          blockHash,
+			Era:                era,
+			GenesisHash:        c.genesisHash,
+			Nonce:              types.NewUCompactFromUInt(nonce),
+			SpecVersion:        rv.SpecVersion,
+			Tip:                opts.tipOr(0),
+			AppID:              types.NewUCompactFromUInt(uint64(appID)),
+			TransactionVersion: rv.TransactionVersion,
+		}
+
+		signed := ext
+		err := signed.Sign(account, o)
+
+		return signed, err
+	}, nil
+}
+
+// hashExtrinsic returns the hash the extrinsic is identified by on chain.
+func hashExtrinsic(ext types.Extrinsic) (types.Hash, error) {
+	encoded, err := codec.Encode(ext)
+	if err != nil {
+		return types.Hash{}, err
+	}
+
+	return types.Hash(blake2b.Sum256(encoded)), nil
+}
+
+// maxAppDataLength returns the maximum length of the data of a single submission accepted by the chain.
+func maxAppDataLength(meta *types.Metadata) (int, error) {
+	value, err := meta.FindConstantValue("DataAvailability", "MaxAppDataLength")
+	if err != nil {
+		return DefaultMaxAppDataLength, nil
+	}
+
+	if len(value) != 4 {
+		return 0, fmt.Errorf("invalid DataAvailability.MaxAppDataLength constant: %x", value)
+	}
+
+	return int(binary.LittleEndian.Uint32(value)), nil
+}
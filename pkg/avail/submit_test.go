@@ -0,0 +1,106 @@
+package avail
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitData(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	if err := m.SetNonce(account, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	nonces := NewNonceManager()
+	data := []byte("op-evm block")
+
+	m.Script(MockOutcome{Err: errSubmitStopped})
+
+	_, err := SubmitData(context.Background(), m, account, 7, data, SubmitOpts{SignOpts: SignOpts{}.WithTip(100), Nonces: nonces})
+	assert.ErrorIs(t, err, errSubmitStopped)
+
+	submitted := m.Submitted()
+	if !assert.Len(t, submitted, 1) {
+		return
+	}
+
+	encodedData, err := codec.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, submitted[0].IsSigned())
+	assert.Equal(t, types.NewUCompactFromUInt(7), submitted[0].Signature.AppID)
+	assert.Equal(t, types.NewUCompactFromUInt(4), submitted[0].Signature.Nonce)
+	assert.Equal(t, types.NewUCompactFromUInt(100), submitted[0].Signature.Tip)
+	assert.Equal(t, types.CallIndex{SectionIndex: 29, MethodIndex: 1}, submitted[0].Method.CallIndex)
+	assert.Equal(t, types.Args(encodedData), submitted[0].Method.Args)
+
+	// An oversized payload is rejected before a nonce is taken and anything is submitted.
+	_, err = SubmitData(context.Background(), m, account, 7, bytes.Repeat([]byte{1}, DefaultMaxAppDataLength+1), SubmitOpts{Nonces: nonces})
+	assert.ErrorIs(t, err, ErrDataTooLarge)
+	assert.Len(t, m.Submitted(), 1)
+
+	// The nonce of the first submission, which wasn't accepted by the transaction pool, was given back.
+	_, err = SubmitData(context.Background(), m, account, 7, bytes.Repeat([]byte{1}, DefaultMaxAppDataLength), SubmitOpts{Nonces: nonces})
+	assert.NoError(t, err)
+
+	if submitted := m.Submitted(); assert.Len(t, submitted, 2) {
+		assert.Equal(t, types.NewUCompactFromUInt(4), submitted[1].Signature.Nonce)
+	}
+}
+
+func TestSubmitDataDefaultMaxLength(t *testing.T) {
+	m, account := newFundedMockClient(t, AVL)
+
+	// Without the DataAvailability pallet, the default maximum length is enforced.
+	var meta types.Metadata
+	if err := codec.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	m.UpgradeRuntime(&meta)
+	r := recordMockCalls(m)
+
+	_, err := SubmitData(context.Background(), m, account, 1, make([]byte, DefaultMaxAppDataLength+1), SubmitOpts{})
+	assert.ErrorIs(t, err, ErrDataTooLarge)
+	assert.Empty(t, m.Submitted())
+	assert.Empty(t, r.recorded().lookups)
+	assert.Empty(t, r.recorded().nonceLookups)
+}
+
+func TestSubmitDataInclusion(t *testing.T) {
+	m, funder := newFundedMockClient(t, 10*AVL)
+
+	if err := m.SetNonce(funder, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := SubmitData(context.Background(), m, funder, 1, []byte("data"), SubmitOpts{WaitFor: WaitFinalized})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	extrinsicHash, err := hashExtrinsic(m.Submitted()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockHash, err := m.api.RPC.Chain.GetBlockHash(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, extrinsicHash, result.ExtrinsicHash)
+	assert.Equal(t, blockHash, result.BlockHash)
+	assert.Equal(t, uint64(1), result.BlockNumber)
+	assert.Equal(t, uint32(0), result.ExtrinsicIndex)
+	assert.True(t, result.Finalized)
+	assert.Equal(t, uint64(5), result.Nonce)
+}
@@ -0,0 +1,154 @@
+package avail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var (
+	// ErrBlockNotFinalized is the error returned when the Avail block reading starts from isn't finalized yet, or
+	// isn't part of the finalized chain.
+	ErrBlockNotFinalized = errors.New("Avail block not finalized")
+
+	// ErrGenesisExtrinsicNotFound is the error returned when no finalized Avail block includes the submission marking
+	// the genesis of the chain.
+	ErrGenesisExtrinsicNotFound = errors.New("genesis extrinsic not found")
+)
+
+// SyncStart is the Avail block reading the submissions starts from, e.g. for a node joining an existing network.
+// It's set with FromBlockNumber, FromBlockHash or FromGenesisExtrinsic, and resolved with ResolveSyncStart.
+type SyncStart struct {
+	number *uint64
+	hash   *types.Hash
+
+	// appID and marker are the AppID and the prefix of the data of the genesis submission.
+	appID  uint32
+	marker []byte
+}
+
+// FromBlockNumber starts reading from the finalized Avail block of the number.
+func FromBlockNumber(n uint64) *SyncStart {
+	return &SyncStart{number: &n}
+}
+
+// FromBlockHash starts reading from the finalized Avail block of the hash.
+func FromBlockHash(h types.Hash) *SyncStart {
+	return &SyncStart{hash: &h}
+}
+
+// FromGenesisExtrinsic starts reading from the first finalized Avail block including a submit_data extrinsic
+// under the AppID whose data starts with the marker, e.g. the submission of the genesis block of the chain.
+// The finalized blocks are scanned forward from the first one to find it. An empty marker matches the first
+// submission under the AppID. The data compressed by the client is matched once decompressed, see WithCompression.
+func FromGenesisExtrinsic(appID uint32, marker []byte) *SyncStart {
+	return &SyncStart{appID: appID, marker: append([]byte(nil), marker...)}
+}
+
+func (s *SyncStart) String() string {
+	switch {
+	case s.number != nil:
+		return fmt.Sprintf("block %d", *s.number)
+	case s.hash != nil:
+		return fmt.Sprintf("block %s", s.hash.Hex())
+	default:
+		return fmt.Sprintf("genesis extrinsic of AppID %d", s.appID)
+	}
+}
+
+// ResolveSyncStart resolves the Avail block reading starts from, and checks that it exists and is finalized.
+// It takes a client and the start point.
+// It returns the number of the block, and an error wrapping ErrBlockNotFound if it doesn't exist,
+// ErrBlockNotFinalized if it isn't finalized, ErrGenesisExtrinsicNotFound if no finalized block includes
+// the genesis extrinsic, or an error if there is an issue.
+func ResolveSyncStart(client Client, start *SyncStart) (uint64, error) {
+	c, err := implementation(client)
+	if err != nil {
+		return 0, err
+	}
+
+	api := c.instance()
+
+	switch {
+	case start.number != nil:
+		blockHash, err := api.RPC.Chain.GetBlockHash(*start.number)
+		if err != nil || blockHash == (types.Hash{}) {
+			return 0, fmt.Errorf("%w: sync start block %d", ErrBlockNotFound, *start.number)
+		}
+
+		return finalizedBlockNumber(api, blockHash)
+	case start.hash != nil:
+		return finalizedBlockNumber(api, *start.hash)
+	default:
+		return c.findGenesisExtrinsic(api, start.appID, start.marker)
+	}
+}
+
+// finalizedBlockNumber returns the number of the block, checking that it's finalized.
+func finalizedBlockNumber(api *gsrpc.SubstrateAPI, blockHash types.Hash) (uint64, error) {
+	header, err := api.RPC.Chain.GetHeader(blockHash)
+	if err != nil {
+		return 0, fmt.Errorf("%w: sync start block %s: %s", ErrBlockNotFound, blockHash.Hex(), err)
+	}
+
+	finalized, err := isFinalized(api, blockHash)
+	if err != nil {
+		return 0, err
+	}
+
+	if !finalized {
+		return 0, fmt.Errorf("%w: sync start block %d (%s)", ErrBlockNotFinalized, header.Number, blockHash.Hex())
+	}
+
+	return uint64(header.Number), nil
+}
+
+// findGenesisExtrinsic scans the finalized blocks forward for the first one including a submit_data extrinsic under
+// the AppID whose data starts with the marker, and returns its number.
+func (c *client) findGenesisExtrinsic(api *gsrpc.SubstrateAPI, appID uint32, marker []byte) (uint64, error) {
+	meta, err := c.metadata(api)
+	if err != nil {
+		return 0, err
+	}
+
+	callIdx, err := meta.FindCallIndex(CallSubmitData)
+	if err != nil {
+		return 0, err
+	}
+
+	finalizedHash, err := api.RPC.Chain.GetFinalizedHead()
+	if err != nil {
+		return 0, err
+	}
+
+	finalizedHeader, err := api.RPC.Chain.GetHeader(finalizedHash)
+	if err != nil {
+		return 0, err
+	}
+
+	for n := uint64(1); n <= uint64(finalizedHeader.Number); n++ {
+		blk, err := finalizedBlock(c.reader(), n)
+		if err != nil {
+			return 0, err
+		}
+
+		data, err := blockExtrinsicsData(blk, callIdx, appID, nil)
+		if errors.Is(err, ErrNoExtrinsicFound) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		for _, d := range data {
+			// The data that can't be decompressed isn't the genesis extrinsic.
+			if d, err := c.decompress(d); err == nil && bytes.HasPrefix(d, marker) {
+				return n, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("%w: AppID %d in blocks 1 to %d", ErrGenesisExtrinsicNotFound, appID, finalizedHeader.Number)
+}
@@ -0,0 +1,129 @@
+package avail
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSyncStart(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, RetryPolicy{})
+
+	ft.finalize(6)
+
+	testCases := []struct {
+		name   string
+		start  *SyncStart
+		number uint64
+		err    error
+	}{
+		{"number", FromBlockNumber(4), 4, nil},
+		{"finalized head", FromBlockNumber(6), 6, nil},
+		{"hash", FromBlockHash(blockHashOf(t, ft.m, 5)), 5, nil},
+		{"number not finalized", FromBlockNumber(7), 0, ErrBlockNotFinalized},
+		{"hash not finalized", FromBlockHash(blockHashOf(t, ft.m, 8)), 0, ErrBlockNotFinalized},
+		{"unknown number", FromBlockNumber(20), 0, ErrBlockNotFound},
+		{"unknown hash", FromBlockHash(types.NewHash([]byte("unknown"))), 0, ErrBlockNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := ResolveSyncStart(ft.c, tc.start)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.number, n)
+			}
+		})
+	}
+}
+
+func TestResolveSyncStartGenesisExtrinsic(t *testing.T) {
+	m, account := newFundedMockClient(t, 10*AVL)
+
+	var genesis *SubmitResult
+
+	for _, submission := range []struct {
+		appID uint32
+		data  string
+	}{
+		{2, "genesis of another chain"},
+		{1, "not the genesis"},
+		{1, "genesis block"},
+		{1, "genesis block again"},
+	} {
+		result, err := SubmitData(context.Background(), m, account, submission.appID, []byte(submission.data), SubmitOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if genesis == nil && submission.appID == 1 && submission.data == "genesis block" {
+			genesis = result
+		}
+	}
+
+	n, err := ResolveSyncStart(m, FromGenesisExtrinsic(1, []byte("genesis")))
+	assert.NoError(t, err)
+	assert.Equal(t, genesis.BlockNumber, n)
+
+	_, err = ResolveSyncStart(m, FromGenesisExtrinsic(3, []byte("genesis")))
+	assert.ErrorIs(t, err, ErrGenesisExtrinsicNotFound)
+}
+
+func TestResolveSyncStartCompressedGenesisExtrinsic(t *testing.T) {
+	m, err := NewMockClient(WithCompression(CompressionGzip))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetBalance(account, new(big.Int).SetUint64(10*AVL)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := SubmitData(context.Background(), m, account, 1, append([]byte("genesis"), make([]byte, 512)...), SubmitOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := ResolveSyncStart(m, FromGenesisExtrinsic(1, []byte("genesis")))
+	assert.NoError(t, err)
+	assert.Equal(t, result.BlockNumber, n)
+}
+
+func TestBlockFollowerStart(t *testing.T) {
+	ft := newFinalizedHeadsTest(t, RetryPolicy{}, newFakeHeadsSubscription(errConnectionReset, 6, 7))
+	ft.finalize(7)
+
+	f, err := NewBlockFollower(context.Background(), ft.c, BlockFollowerOpts{Start: FromBlockNumber(4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []types.BlockNumber{4, 5, 6, 7}, receiveBlocks(t, f.Chan()))
+	assert.ErrorIs(t, <-f.Err(), errConnectionReset)
+
+	// The last processed block takes precedence over the start.
+	ft = newFinalizedHeadsTest(t, RetryPolicy{}, newFakeHeadsSubscription(errConnectionReset, 6))
+	ft.finalize(7)
+
+	lastProcessed := uint64(4)
+
+	f, err = NewBlockFollower(context.Background(), ft.c, BlockFollowerOpts{LastProcessed: &lastProcessed, Start: FromBlockNumber(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []types.BlockNumber{5, 6}, receiveBlocks(t, f.Chan()))
+
+	// The start is checked before following.
+	_, err = NewBlockFollower(context.Background(), ft.c, BlockFollowerOpts{Start: FromBlockNumber(8)})
+	assert.ErrorIs(t, err, ErrBlockNotFinalized)
+}
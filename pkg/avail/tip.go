@@ -0,0 +1,75 @@
+package avail
+
+import (
+	"sort"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// suggestTipBlocks is the number of recent blocks SuggestTip looks at.
+const suggestTipBlocks = 10
+
+// WithTip returns the signature options with the tip paid for the extrinsics, in Avail fractions.
+// Without a tip, the extrinsics are signed with the default tip of the submit helper, which is zero
+// for all of them except the Sender and the application key creation.
+func (o SignOpts) WithTip(amount uint64) SignOpts {
+	o.tip = &amount
+	return o
+}
+
+// tipOr returns the tip of the signature options, or the default tip if none was given.
+func (o SignOpts) tipOr(defaultTip uint64) types.UCompact {
+	if o.tip != nil {
+		return types.NewUCompactFromUInt(*o.tip)
+	}
+
+	return types.NewUCompactFromUInt(defaultTip)
+}
+
+// SuggestTip proposes a tip for the next submissions, based on the tips of the signed extrinsics included
+// in the recent blocks: it's the median of their tips, so it's zero unless most of the included extrinsics
+// pay one, which is the case when the transaction pool is congested and prioritizes them.
+// It takes a client and returns the tip in Avail fractions, or an error if there is an issue.
+func SuggestTip(client Client) (uint64, error) {
+	api, err := instance(client)
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := api.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return 0, err
+	}
+
+	head := uint64(header.Number)
+
+	var tips []uint64
+
+	for i := uint64(0); i < suggestTipBlocks && i <= head; i++ {
+		blockHash, err := api.RPC.Chain.GetBlockHash(head - i)
+		if err != nil {
+			return 0, err
+		}
+
+		block, err := api.RPC.Chain.GetBlock(blockHash)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, ext := range block.Block.Extrinsics {
+			if ext.IsSigned() {
+				tips = append(tips, uint64(ext.Signature.Tip.Int64()))
+			}
+		}
+	}
+
+	if len(tips) == 0 {
+		return 0, nil
+	}
+
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i] < tips[j]
+	})
+
+	return tips[len(tips)/2], nil
+}
@@ -0,0 +1,108 @@
+package avail
+
+import (
+	"context"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepositBalanceTip(t *testing.T) {
+	tt := []struct {
+		name     string
+		signOpts SignOpts
+		tip      uint64
+	}{
+		{name: "default", signOpts: SignOpts{}, tip: 0},
+		{name: "with tip", signOpts: SignOpts{}.WithTip(1500), tip: 1500},
+		{name: "with mortal era", signOpts: SignOpts{MortalPeriod: 64}.WithTip(10), tip: 10},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, funder := newFundedMockClient(t, 2*AVL)
+
+			recipient, err := NewAccount()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = DepositBalance(context.Background(), m, funder, recipient, AVL, SubmitOpts{SignOpts: tc.signOpts})
+			assert.NoError(t, err)
+
+			if submitted := m.Submitted(); assert.Len(t, submitted, 1) {
+				assert.Equal(t, types.NewUCompactFromUInt(tc.tip), submitted[0].Signature.Tip)
+			}
+		})
+	}
+}
+
+// tippedExtrinsics returns an unsigned extrinsic and signed extrinsics paying the tips.
+func tippedExtrinsics(tips ...uint64) []types.Extrinsic {
+	extrinsics := []types.Extrinsic{{Version: types.ExtrinsicVersion4}}
+
+	for _, tip := range tips {
+		extrinsics = append(extrinsics, types.Extrinsic{
+			Version:   types.ExtrinsicVersion4 | types.ExtrinsicBitSigned,
+			Signature: types.ExtrinsicSignatureV4{Tip: types.NewUCompactFromUInt(tip)},
+		})
+	}
+
+	return extrinsics
+}
+
+func TestSuggestTip(t *testing.T) {
+	// The tips of the extrinsics of the blocks after the genesis block, which has none.
+	tt := []struct {
+		name   string
+		blocks [][]uint64
+		tip    uint64
+	}{
+		{
+			name:   "no signed extrinsics",
+			blocks: [][]uint64{{}, {}, {}},
+			tip:    0,
+		},
+		{
+			name:   "mostly untipped",
+			blocks: [][]uint64{{0, 0}, {0, 500}},
+			tip:    0,
+		},
+		{
+			name:   "congested",
+			blocks: [][]uint64{{100, 0}, {300, 200}},
+			tip:    200,
+		},
+		{
+			// Only the last suggestTipBlocks blocks are looked at, so the first block isn't fetched.
+			name: "recent blocks only",
+			blocks: func() [][]uint64 {
+				blocks := [][]uint64{{1000, 1000, 1000}}
+				for n := uint64(1); n <= suggestTipBlocks; n++ {
+					blocks = append(blocks, []uint64{n})
+				}
+				return blocks
+			}(),
+			tip: suggestTipBlocks/2 + 1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewMockClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, tips := range tc.blocks {
+				produceBlock(t, m, tippedExtrinsics(tips...)...)
+			}
+
+			tip, err := SuggestTip(m)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.tip, tip)
+			}
+		})
+	}
+}
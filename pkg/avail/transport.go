@@ -0,0 +1,265 @@
+package avail
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// Transport is the transport the client connects to the Avail endpoints with.
+type Transport int
+
+const (
+	// TransportWebSocket connects with WebSocket, supporting the subscriptions.
+	TransportWebSocket Transport = iota
+	// TransportHTTP connects with HTTP, which doesn't support the subscriptions: the status of the submitted
+	// extrinsics is polled instead, and the helpers relying on a subscription fail with ErrSubscriptionsUnsupported.
+	TransportHTTP
+)
+
+// DefaultPollInterval is the interval the status of an extrinsic submitted over HTTP is polled at.
+const DefaultPollInterval = 2 * time.Second
+
+// ErrSubscriptionsUnsupported is the error returned when a helper relying on a subscription, e.g.
+// SubscribeFinalizedHeads, is called with a client connected over HTTP.
+var ErrSubscriptionsUnsupported = errors.New("subscriptions unsupported over HTTP")
+
+func (t Transport) String() string {
+	switch t {
+	case TransportWebSocket:
+		return "websocket"
+	case TransportHTTP:
+		return "http"
+	default:
+		return fmt.Sprintf("Transport(%d)", int(t))
+	}
+}
+
+// Capabilities are the features supported by the transport of a client.
+type Capabilities struct {
+	// Transport is the transport the client connects to the Avail endpoints with.
+	Transport Transport
+	// Subscriptions tells whether the subscriptions are supported, e.g. by SubscribeFinalizedHeads,
+	// SubscribeBalance, NewBlockFollower, BlockStream and BlockDataWatcher.
+	Subscriptions bool
+	// PolledSubmissions tells whether the status of the submitted extrinsics is polled, rather than watched with
+	// a subscription.
+	PolledSubmissions bool
+}
+
+// WithPollInterval sets the interval the status of an extrinsic submitted over HTTP is polled at.
+func WithPollInterval(interval time.Duration) ClientOption {
+	return func(c *client) {
+		if interval > 0 {
+			c.pollInterval = interval
+		}
+	}
+}
+
+// Capabilities returns the features supported by the transport of the client.
+func (c *client) Capabilities() Capabilities {
+	return Capabilities{
+		Transport:         c.transport,
+		Subscriptions:     c.transport != TransportHTTP,
+		PolledSubmissions: c.transport == TransportHTTP,
+	}
+}
+
+// endpointsTransport returns the transport of the endpoints, HTTP for the http and https URLs, WebSocket for the
+// others. It returns an error if the endpoints don't share the same transport, as failing over between them would
+// stop the subscriptions.
+func endpointsTransport(urls []string) (Transport, error) {
+	transport := urlTransport(urls[0])
+
+	for _, url := range urls[1:] {
+		if urlTransport(url) != transport {
+			return 0, fmt.Errorf("Avail endpoints %s mix the %s and %s transports", strings.Join(urls, ", "), transport, urlTransport(url))
+		}
+	}
+
+	return transport, nil
+}
+
+// urlTransport returns the transport of the endpoint URL.
+func urlTransport(url string) Transport {
+	url = strings.ToLower(url)
+
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return TransportHTTP
+	}
+
+	return TransportWebSocket
+}
+
+// requireSubscriptions returns an error wrapping ErrSubscriptionsUnsupported if the transport of the client doesn't
+// support the subscriptions the helper relies on.
+func (c *client) requireSubscriptions(helper string) error {
+	if c.transport == TransportHTTP {
+		return fmt.Errorf("%w: %s needs a WebSocket Avail endpoint", ErrSubscriptionsUnsupported, helper)
+	}
+
+	return nil
+}
+
+// pollingSubscription emulates the status subscription of an extrinsic submitted over HTTP, polling the blocks
+// for it: an InBlock status is sent once a new block includes it, then a Finalized status once the block is
+// finalized, or a Retracted status if another block is finalized at its height, and the blocks are polled again.
+// Polling fails like a subscription when a call fails, so the submission is resumed following the retry policy.
+// The Ready, Dropped and Invalid statuses aren't emulated.
+type pollingSubscription struct {
+	statuses chan types.ExtrinsicStatus
+	errs     chan error
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+func (s *pollingSubscription) Chan() <-chan types.ExtrinsicStatus {
+	return s.statuses
+}
+
+func (s *pollingSubscription) Err() <-chan error {
+	return s.errs
+}
+
+func (s *pollingSubscription) Unsubscribe() {
+	s.quitOnce.Do(func() {
+		close(s.quit)
+	})
+}
+
+// submitAndPoll submits the extrinsic with the api, and polls its status, see pollingSubscription.
+func (c *client) submitAndPoll(api *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+	extrinsicHash, err := hashExtrinsic(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	// The blocks after the current head are the ones that can include the extrinsic.
+	header, err := api.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := api.RPC.Author.SubmitExtrinsic(ext); err != nil {
+		return nil, err
+	}
+
+	sub := &pollingSubscription{
+		statuses: make(chan types.ExtrinsicStatus),
+		errs:     make(chan error, 1),
+		quit:     make(chan struct{}),
+	}
+
+	go c.poll(api, sub, extrinsicHash, uint64(header.Number)+1)
+
+	return sub, nil
+}
+
+// poll polls the blocks from the number on for the extrinsic, and then the finality of the block including it,
+// until the subscription is unsubscribed or a Finalized status is sent.
+func (c *client) poll(api *gsrpc.SubstrateAPI, sub *pollingSubscription, extrinsicHash types.Hash, next uint64) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	// included is the hash of the block including the extrinsic, and includedNumber its number, once found.
+	var (
+		included       *types.Hash
+		includedNumber uint64
+	)
+
+	send := func(status types.ExtrinsicStatus) bool {
+		select {
+		case sub.statuses <- status:
+			return true
+		case <-sub.quit:
+			return false
+		}
+	}
+
+	fail := func(err error) {
+		sub.errs <- err
+	}
+
+	for {
+		select {
+		case <-sub.quit:
+			return
+		case <-ticker.C:
+		}
+
+		if included == nil {
+			header, err := api.RPC.Chain.GetHeaderLatest()
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			head := uint64(header.Number)
+			if head < next {
+				continue
+			}
+
+			found, err := scanExtrinsic(api, extrinsicHash, next, head, head)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			if found == nil {
+				next = head + 1
+				continue
+			}
+
+			foundHeader, err := api.RPC.Chain.GetHeader(found.blockHash)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			included, includedNumber = &found.blockHash, uint64(foundHeader.Number)
+
+			if !send(types.ExtrinsicStatus{IsInBlock: true, AsInBlock: found.blockHash}) {
+				return
+			}
+		}
+
+		finalizedHash, err := api.RPC.Chain.GetFinalizedHead()
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		finalizedHeader, err := api.RPC.Chain.GetHeader(finalizedHash)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		if uint64(finalizedHeader.Number) < includedNumber {
+			continue
+		}
+
+		canonicalHash, err := api.RPC.Chain.GetBlockHash(includedNumber)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		if canonicalHash == *included {
+			send(types.ExtrinsicStatus{IsFinalized: true, AsFinalized: canonicalHash})
+			return
+		}
+
+		// The block including the extrinsic was retracted, so the canonical blocks are polled from its height.
+		if !send(types.ExtrinsicStatus{IsRetracted: true, AsRetracted: *included}) {
+			return
+		}
+
+		included, next = nil, includedNumber
+	}
+}
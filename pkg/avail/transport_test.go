@@ -0,0 +1,236 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/author"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointsTransport(t *testing.T) {
+	for url, expected := range map[string]Transport{
+		"http://127.0.0.1:9933":        TransportHTTP,
+		"HTTPS://avail.example/rpc":    TransportHTTP,
+		"ws://127.0.0.1:9944/v1":       TransportWebSocket,
+		"wss://avail.example/ws":       TransportWebSocket,
+		"/var/run/avail/avail.ipc":     TransportWebSocket,
+		"httpish://avail.example/rpc?": TransportWebSocket,
+	} {
+		transport, err := endpointsTransport([]string{url})
+		if assert.NoError(t, err, url) {
+			assert.Equal(t, expected, transport, url)
+		}
+	}
+
+	_, err := endpointsTransport([]string{"wss://a.example", "https://b.example"})
+	assert.Error(t, err)
+}
+
+// pollingChain serves the canonical blocks by number, produced one at a time, and the finalized head.
+type pollingChain struct {
+	chain.Chain
+
+	lock      sync.Mutex
+	head      uint64
+	finalized uint64
+	hashes    map[uint64]types.Hash
+	blocks    map[types.Hash]*types.SignedBlock
+}
+
+func newPollingChain() *pollingChain {
+	c := &pollingChain{hashes: make(map[uint64]types.Hash), blocks: make(map[types.Hash]*types.SignedBlock)}
+	c.set(0, types.NewHash([]byte("genesis")))
+
+	return c
+}
+
+// set sets the canonical block of the number, with the extrinsics.
+func (c *pollingChain) set(n uint64, hash types.Hash, exts ...types.Extrinsic) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.hashes[n] = hash
+	c.blocks[hash] = &types.SignedBlock{Block: types.Block{Header: types.Header{Number: types.BlockNumber(n)}, Extrinsics: exts}}
+
+	if n > c.head {
+		c.head = n
+	}
+}
+
+func (c *pollingChain) finalize(n uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.finalized = n
+}
+
+func (c *pollingChain) GetHeaderLatest() (*types.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return &types.Header{Number: types.BlockNumber(c.head)}, nil
+}
+
+func (c *pollingChain) GetBlockHash(n uint64) (types.Hash, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	hash, ok := c.hashes[n]
+	if !ok {
+		return types.Hash{}, errors.New("block not found")
+	}
+
+	return hash, nil
+}
+
+func (c *pollingChain) GetBlock(blockHash types.Hash) (*types.SignedBlock, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	blk, ok := c.blocks[blockHash]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+
+	return blk, nil
+}
+
+func (c *pollingChain) GetHeader(blockHash types.Hash) (*types.Header, error) {
+	blk, err := c.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blk.Block.Header, nil
+}
+
+func (c *pollingChain) GetFinalizedHead() (types.Hash, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.hashes[c.finalized], nil
+}
+
+// pollingAuthor captures the extrinsics submitted without watching their status.
+type pollingAuthor struct {
+	author.Author
+
+	submitted chan types.Extrinsic
+}
+
+func (a *pollingAuthor) SubmitExtrinsic(ext types.Extrinsic) (types.Hash, error) {
+	a.submitted <- ext
+
+	return hashExtrinsic(ext)
+}
+
+// newHTTPClient returns a client connected over HTTP to the chain, submitting the extrinsics to the author.
+func newHTTPClient(t *testing.T, ch *pollingChain, au *pollingAuthor) *client {
+	dial := func(c *client) {
+		c.dial = func(string) (*gsrpc.SubstrateAPI, error) {
+			return &gsrpc.SubstrateAPI{RPC: &rpc.RPC{Chain: ch, Author: au}}, nil
+		}
+	}
+
+	c, err := newClient([]string{"https://avail.example/rpc"}, hclog.NewNullLogger(), dial, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestHTTPClientCapabilities(t *testing.T) {
+	c := newHTTPClient(t, newPollingChain(), &pollingAuthor{})
+
+	assert.Equal(t, Capabilities{Transport: TransportHTTP, PolledSubmissions: true}, c.Capabilities())
+
+	_, _, err := SubscribeFinalizedHeads(context.Background(), c)
+	assert.ErrorIs(t, err, ErrSubscriptionsUnsupported)
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = SubscribeBalance(context.Background(), c, account)
+	assert.ErrorIs(t, err, ErrSubscriptionsUnsupported)
+
+	_, err = NewBlockFollower(context.Background(), c, BlockFollowerOpts{})
+	assert.ErrorIs(t, err, ErrSubscriptionsUnsupported)
+
+	m, err := NewMockClient()
+	if assert.NoError(t, err) {
+		assert.Equal(t, Capabilities{Transport: TransportWebSocket, Subscriptions: true}, m.Capabilities())
+	}
+}
+
+func TestSubmitOverHTTPPollsStatus(t *testing.T) {
+	ch := newPollingChain()
+	ch.set(1, types.NewHash([]byte("1")))
+
+	au := &pollingAuthor{submitted: make(chan types.Extrinsic, 1)}
+	c := newHTTPClient(t, ch, au)
+
+	account, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ext := types.NewExtrinsic(types.Call{CallIndex: types.CallIndex{SectionIndex: 1, MethodIndex: 2}, Args: []byte{1}})
+
+	extrinsicHash, err := hashExtrinsic(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := c.watch(c.instance(), ext)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, ext, <-au.submitted)
+
+	// The extrinsic is included in block 2, which is retracted, and then in the canonical block 3.
+	retracted := types.NewHash([]byte("2a"))
+	ch.set(2, retracted, ext)
+
+	status := <-sub.Chan()
+	assert.Equal(t, types.ExtrinsicStatus{IsInBlock: true, AsInBlock: retracted}, status)
+
+	canonical := types.NewHash([]byte("3"))
+	ch.set(2, types.NewHash([]byte("2b")))
+	ch.set(3, canonical, ext)
+	ch.finalize(2)
+
+	status = <-sub.Chan()
+	assert.Equal(t, types.ExtrinsicStatus{IsRetracted: true, AsRetracted: retracted}, status)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		result, err := c.awaitInclusion(context.Background(), sub, ext, account, 0, SubmitOpts{WaitFor: WaitFinalized})
+		if assert.NoError(t, err) {
+			assert.Equal(t, &SubmitResult{BlockHash: canonical, ExtrinsicHash: extrinsicHash, Finalized: true}, result)
+		}
+	}()
+
+	ch.finalize(3)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("extrinsic finalization not polled")
+	}
+}
@@ -0,0 +1,450 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// ErrExtrinsicStatusUnknown is the error returned when the status subscription of a submitted extrinsic failed,
+// and the extrinsic couldn't be found on chain after reconnecting while its nonce was already used, so it
+// may have been included in an older block than the ones scanned, or replaced by another extrinsic.
+var ErrExtrinsicStatusUnknown = errors.New("extrinsic status unknown")
+
+// WaitFor is the status of a submitted extrinsic the submit helpers wait for.
+type WaitFor int
+
+const (
+	// WaitInBlock waits for the extrinsic to be included in a block, which can still be retracted on a fork.
+	WaitInBlock WaitFor = iota
+	// WaitFinalized waits for the block including the extrinsic to be finalized.
+	WaitFinalized
+)
+
+var (
+	// ErrExtrinsicRetracted is the error returned when waiting for finalization, and the block including
+	// the extrinsic is retracted.
+	ErrExtrinsicRetracted = errors.New("extrinsic block retracted")
+
+	// ErrFinalityTimeout is the error returned when waiting for finalization, and the block including
+	// the extrinsic isn't finalized in time by the Avail node.
+	ErrFinalityTimeout = errors.New("extrinsic finality timeout")
+
+	// ErrExtrinsicDropped is the error returned when the submitted extrinsic is dropped from the transaction pool.
+	ErrExtrinsicDropped = errors.New("extrinsic dropped")
+
+	// ErrExtrinsicInvalid is the error returned when the submitted extrinsic is found invalid by the transaction pool.
+	ErrExtrinsicInvalid = errors.New("extrinsic invalid")
+)
+
+// included checks whether the extrinsic status is the one waited for, and returns the hash of the block including
+// the extrinsic if so, which is finalized if the status is. It returns an error wrapping ErrExtrinsicRetracted or
+// ErrFinalityTimeout if the extrinsic won't be finalized while waiting for it.
+func (w WaitFor) included(status types.ExtrinsicStatus) (types.Hash, bool, error) {
+	switch {
+	case status.IsFinalized:
+		return status.AsFinalized, true, nil
+	case status.IsInBlock && w == WaitInBlock:
+		return status.AsInBlock, true, nil
+	case status.IsRetracted && w == WaitFinalized:
+		return types.Hash{}, false, fmt.Errorf("%w: %s", ErrExtrinsicRetracted, status.AsRetracted.Hex())
+	case status.IsFinalityTimeout && w == WaitFinalized:
+		return types.Hash{}, false, fmt.Errorf("%w: %s", ErrFinalityTimeout, status.AsFinalityTimeout.Hex())
+	default:
+		return types.Hash{}, false, nil
+	}
+}
+
+// rejected returns an error wrapping ErrExtrinsicDropped or ErrExtrinsicInvalid if the extrinsic with the status
+// won't be included, or nil.
+func rejected(status types.ExtrinsicStatus) error {
+	switch {
+	case status.IsDropped:
+		return fmt.Errorf("%w: unexpected extrinsic status from Avail: %#v", ErrExtrinsicDropped, status)
+	case status.IsInvalid:
+		return fmt.Errorf("%w: unexpected extrinsic status from Avail: %#v", ErrExtrinsicInvalid, status)
+	default:
+		return nil
+	}
+}
+
+// ErrSubmitTimeout is the error matched by a *SubmitTimeoutError.
+var ErrSubmitTimeout = errors.New("extrinsic submission timed out")
+
+// SubmitTimeoutError is the error returned when the context of a submission is done before the extrinsic is
+// included. The extrinsic may still be included later on, its hash can be used to check its fate.
+type SubmitTimeoutError struct {
+	// ExtrinsicHash is the hash of the submitted extrinsic.
+	ExtrinsicHash types.Hash
+	// Err is the error of the context.
+	Err error
+}
+
+func (e *SubmitTimeoutError) Error() string {
+	return fmt.Sprintf("%s waiting for extrinsic %s: %v", ErrSubmitTimeout, e.ExtrinsicHash.Hex(), e.Err)
+}
+
+// Unwrap returns the error of the context.
+func (e *SubmitTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrSubmitTimeout.
+func (e *SubmitTimeoutError) Is(target error) bool {
+	return target == ErrSubmitTimeout
+}
+
+// RetryPolicy is the policy used when the status subscription of a submitted extrinsic fails, e.g. on a
+// WebSocket disconnection: the connection is re-established, the recent blocks are searched for the extrinsic,
+// and the extrinsic is resubmitted, with the same nonce and signature, if it didn't land and its nonce is unused.
+// It's also used when the submission is rejected for a stale nonce, see IsStaleNonceError: the nonce is fetched
+// from chain again, and the extrinsic is re-signed with it and resubmitted.
+type RetryPolicy struct {
+	// MaxReconnects is the number of times the connection is re-established for a single extrinsic.
+	// Zero returns the subscription error.
+	MaxReconnects int
+	// Backoff is the delay before a reconnection, multiplied by the number of the attempt. It's also the delay
+	// before a resubmission with a new nonce, multiplied by the number of the attempt and jittered.
+	Backoff time.Duration
+	// ScanBlocks is the number of recent blocks searched for the extrinsic after a reconnection.
+	ScanBlocks uint64
+	// MaxNonceRetries is the number of times an extrinsic rejected for a stale nonce is re-signed with a new
+	// nonce and resubmitted. Zero returns the rejection error.
+	MaxNonceRetries int
+}
+
+// DefaultRetryPolicy is the retry policy of the clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxReconnects:   3,
+	Backoff:         2 * time.Second,
+	ScanBlocks:      20,
+	MaxNonceRetries: 3,
+}
+
+// alreadyImportedError is the message of the Avail transaction pool error returned when a resubmitted
+// extrinsic is still in the pool.
+const alreadyImportedError = "Transaction Already Imported"
+
+// extrinsicSubscription is the status subscription of a submitted extrinsic, i.e. an *author.ExtrinsicStatusSubscription.
+type extrinsicSubscription interface {
+	Chan() <-chan types.ExtrinsicStatus
+	Err() <-chan error
+	Unsubscribe()
+}
+
+// watch submits the extrinsic with the api, and subscribes to its status, or polls it over HTTP.
+func (c *client) watch(api *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+	if c.submitAndWatch != nil {
+		return c.submitAndWatch(api, ext)
+	}
+
+	if c.transport == TransportHTTP {
+		return c.submitAndPoll(api, ext)
+	}
+
+	sub, err := api.RPC.Author.SubmitAndWatchExtrinsic(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// signFunc returns the extrinsic signed with the nonce.
+type signFunc func(nonce uint64) (types.Extrinsic, error)
+
+// signAndWatch signs the extrinsic with the next nonce of the account, submits it with the api, and subscribes
+// to its status. It returns the subscription, the signed extrinsic and its nonce.
+// When the submission is rejected for a stale nonce, e.g. because another component submitted an extrinsic of the
+// account at the same time, the nonce is fetched from chain again, and the extrinsic is re-signed and resubmitted
+// after a jittered backoff, following the retry policy of the client. An extrinsic already in the transaction pool
+// is never resubmitted.
+// With the DryRun submission option, the signed extrinsic is dry run before it's submitted, and an error wrapping
+// ErrDryRunFailed is returned if it would fail to dispatch. With a fee budget, the estimated fee of the signed
+// extrinsic is charged to it before it's submitted, and an error wrapping ErrFeeBudgetExceeded is returned if it
+// doesn't fit, see FeeBudget.
+func (c *client) signAndWatch(ctx context.Context, api *gsrpc.SubstrateAPI, meta *types.Metadata, account signature.KeyringPair, opts SubmitOpts, sign signFunc) (extrinsicSubscription, types.Extrinsic, uint64, error) {
+	nonces := opts.Nonces
+	budget := opts.feeBudgetOr(c.feeBudget)
+
+	var staleNonce uint64
+
+	for attempt := 0; ; attempt++ {
+		nonce, err := nonces.next(api, account)
+		if err != nil {
+			return nil, types.Extrinsic{}, 0, err
+		}
+
+		if attempt > 0 {
+			c.logger.Warn("extrinsic rejected for a stale nonce, resubmitting", "account", account.Address, "old_nonce", staleNonce, "new_nonce", nonce, "attempt", attempt)
+		}
+
+		// The nonce isn't used by a submission failing before the extrinsic is accepted by the transaction pool,
+		// so it's given back.
+		ext, err := sign(nonce)
+		if err != nil {
+			nonces.Resync(account)
+			return nil, types.Extrinsic{}, 0, err
+		}
+
+		if opts.DryRun {
+			if err := c.preflight(ext); err != nil {
+				nonces.Resync(account)
+				return nil, types.Extrinsic{}, 0, err
+			}
+		}
+
+		spend, err := c.chargeFee(api, budget, ext)
+		if err != nil {
+			nonces.Resync(account)
+			return nil, types.Extrinsic{}, 0, err
+		}
+
+		sub, err := c.watch(api, ext)
+		if err == nil {
+			return sub, ext, nonce, nil
+		}
+
+		refundFee(budget, spend)
+
+		// The nonce is resynced, so the next one is fetched from the node, and the runtime version on a bad signature.
+		nonces.Resync(account)
+		c.invalidateRuntimeVersionOnBadSignature(err)
+
+		if !IsStaleNonceError(err) || attempt >= c.retry.MaxNonceRetries {
+			return nil, types.Extrinsic{}, 0, err
+		}
+
+		staleNonce = nonce
+
+		select {
+		case <-time.After(jittered(time.Duration(attempt+1) * c.retry.Backoff)):
+		case <-ctx.Done():
+			return nil, types.Extrinsic{}, 0, err
+		}
+	}
+}
+
+// jittered returns a random delay between half and one and a half of the delay, so the resubmissions of the
+// extrinsics rejected at the same time don't collide again.
+func jittered(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return delay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)))
+}
+
+// submitAndWaitForInclusion signs the extrinsic with the next nonce of the account, submits it with the api, and
+// waits for its inclusion, or its finalization. A submission rejected for a stale nonce is re-signed with a new
+// nonce and resubmitted, see signAndWatch.
+// When the status subscription fails, it reconnects, looks for the extrinsic in the recent blocks, and either
+// resumes waiting or resubmits it, following the retry policy of the client.
+// It returns the submission result with the hash of the block the extrinsic was included in, a *SubmitTimeoutError
+// if the context is done before, or an error if there is an issue. The dispatch of the extrinsic isn't checked.
+func (c *client) submitAndWaitForInclusion(ctx context.Context, api *gsrpc.SubstrateAPI, meta *types.Metadata, account signature.KeyringPair, opts SubmitOpts, sign signFunc) (*SubmitResult, error) {
+	sub, ext, nonce, err := c.signAndWatch(ctx, api, meta, account, opts, sign)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.awaitInclusion(ctx, sub, ext, account, nonce, opts)
+}
+
+// awaitInclusion waits for the inclusion, or the finalization, of the extrinsic of the status subscription, signed
+// with the nonce and already submitted, like submitAndWaitForInclusion does. The subscription is unsubscribed.
+// The statuses of the subscriptions are passed to the status callback of the options, or of the client, if any, see
+// WithStatusCallback.
+func (c *client) awaitInclusion(ctx context.Context, sub extrinsicSubscription, ext types.Extrinsic, account signature.KeyringPair, nonce uint64, opts SubmitOpts) (*SubmitResult, error) {
+	nonces, waitFor := opts.Nonces, opts.WaitFor
+
+	statuses := c.dispatchStatuses(opts)
+	defer statuses.close()
+
+	extrinsicHash, err := hashExtrinsic(ext)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+
+	included := func(blockHash types.Hash, finalized bool) *SubmitResult {
+		return &SubmitResult{BlockHash: blockHash, ExtrinsicHash: extrinsicHash, Finalized: finalized, Nonce: nonce}
+	}
+
+	attempt := 0
+
+	for {
+		blockHash, finalized, err := waitForInclusion(ctx, sub, extrinsicHash, account, nonces, waitFor, statuses)
+		sub.Unsubscribe()
+
+		if err == nil {
+			return included(blockHash, finalized), nil
+		} else if !errors.Is(err, errSubscriptionFailed) {
+			return nil, err
+		}
+
+		// Reconnect until the extrinsic is found, or resubmitted and watched again.
+		for sub = nil; sub == nil; {
+			if attempt++; attempt > c.retry.MaxReconnects {
+				return nil, err
+			}
+
+			c.logger.Warn("extrinsic status subscription failed, reconnecting", "account", account.Address, "nonce", nonce, "attempt", attempt, "error", err)
+
+			select {
+			case <-time.After(time.Duration(attempt) * c.retry.Backoff):
+			case <-ctx.Done():
+				return nil, &SubmitTimeoutError{ExtrinsicHash: extrinsicHash, Err: ctx.Err()}
+			}
+
+			var found bool
+			if sub, blockHash, found, err = c.resume(extrinsicHash, ext, account, nonce, waitFor); errors.Is(err, ErrExtrinsicStatusUnknown) {
+				return nil, err
+			} else if found {
+				// A found extrinsic is only returned once finalized when waiting for finalization.
+				return included(blockHash, waitFor == WaitFinalized), nil
+			}
+		}
+	}
+}
+
+// resume reconnects and looks for the extrinsic in the recent blocks. It returns the hash of the block including it
+// if it's found, and finalized when waiting for finalization, or else a subscription to the status of the resubmitted
+// extrinsic. It returns an error if the extrinsic is still in the transaction pool, or its block isn't finalized yet,
+// so it's looked for again on the next attempt.
+func (c *client) resume(extrinsicHash types.Hash, ext types.Extrinsic, account signature.KeyringPair, nonce uint64, waitFor WaitFor) (extrinsicSubscription, types.Hash, bool, error) {
+	api, err := c.reconnect()
+	if err != nil {
+		return nil, types.Hash{}, false, err
+	}
+
+	blockHash, found, err := findExtrinsic(api, extrinsicHash, c.retry.ScanBlocks)
+	if err != nil {
+		return nil, types.Hash{}, false, err
+	}
+
+	if found {
+		if waitFor == WaitFinalized {
+			if finalized, err := isFinalized(api, blockHash); err != nil {
+				return nil, types.Hash{}, false, err
+			} else if !finalized {
+				return nil, types.Hash{}, false, fmt.Errorf("extrinsic %s block %s isn't finalized yet", extrinsicHash.Hex(), blockHash.Hex())
+			}
+		}
+
+		return nil, blockHash, true, nil
+	}
+
+	meta, err := c.metadata(api)
+	if err != nil {
+		return nil, types.Hash{}, false, err
+	}
+
+	chainNonce, err := chainNonce(api.RPC, meta, account)
+	if err != nil {
+		return nil, types.Hash{}, false, err
+	}
+
+	if chainNonce > nonce {
+		return nil, types.Hash{}, false, fmt.Errorf("%w: %s not found in the last %d blocks, and nonce %d is used", ErrExtrinsicStatusUnknown, extrinsicHash.Hex(), c.retry.ScanBlocks, nonce)
+	}
+
+	sub, err := c.watch(api, ext)
+	if err != nil {
+		if strings.Contains(err.Error(), alreadyImportedError) {
+			return nil, types.Hash{}, false, fmt.Errorf("extrinsic %s is still in the transaction pool", extrinsicHash.Hex())
+		}
+
+		return nil, types.Hash{}, false, err
+	}
+
+	return sub, types.Hash{}, false, nil
+}
+
+// findExtrinsic searches the last blocks for the extrinsic, and returns the hash of the block including it.
+func findExtrinsic(api *gsrpc.SubstrateAPI, extrinsicHash types.Hash, blocks uint64) (types.Hash, bool, error) {
+	if blocks == 0 {
+		return types.Hash{}, false, nil
+	}
+
+	header, err := api.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return types.Hash{}, false, err
+	}
+
+	head := uint64(header.Number)
+
+	found, err := scanExtrinsic(api, extrinsicHash, head, oldestRecentBlock(head, blocks), head)
+	if err != nil || found == nil {
+		return types.Hash{}, false, err
+	}
+
+	return found.blockHash, true, nil
+}
+
+// isFinalized checks whether the block is finalized, i.e. it's on the canonical chain, at or below the finalized head.
+func isFinalized(api *gsrpc.SubstrateAPI, blockHash types.Hash) (bool, error) {
+	header, err := api.RPC.Chain.GetHeader(blockHash)
+	if err != nil {
+		return false, err
+	}
+
+	finalizedHash, err := api.RPC.Chain.GetFinalizedHead()
+	if err != nil {
+		return false, err
+	}
+
+	finalizedHeader, err := api.RPC.Chain.GetHeader(finalizedHash)
+	if err != nil {
+		return false, err
+	}
+
+	if finalizedHeader.Number < header.Number {
+		return false, nil
+	}
+
+	canonicalHash, err := api.RPC.Chain.GetBlockHash(uint64(header.Number))
+	if err != nil {
+		return false, err
+	}
+
+	return canonicalHash == blockHash, nil
+}
+
+// errSubscriptionFailed is the error wrapping the error of a failed extrinsic status subscription.
+var errSubscriptionFailed = errors.New("extrinsic status subscription failed")
+
+// waitForInclusion waits for the extrinsic of the subscription to be included in a block, or finalized, and returns
+// the block hash, and whether the block is finalized. It returns an error wrapping errSubscriptionFailed if the subscription fails, a *SubmitTimeoutError
+// if the context is done, an error wrapping ErrExtrinsicDropped or ErrExtrinsicInvalid if the extrinsic won't be
+// included, or an error wrapping ErrExtrinsicRetracted or ErrFinalityTimeout while waiting for finalization.
+// The statuses are handed over to the status dispatcher, if any.
+func waitForInclusion(ctx context.Context, sub extrinsicSubscription, extrinsicHash types.Hash, account signature.KeyringPair, nonces *NonceManager, waitFor WaitFor, statuses *statusDispatcher) (types.Hash, bool, error) {
+	for {
+		select {
+		case status := <-sub.Chan():
+			statuses.observe(status)
+
+			if blockHash, ok, err := waitFor.included(status); ok || err != nil {
+				return blockHash, status.IsFinalized, err
+			}
+
+			if err := rejected(status); err != nil {
+				// The nonce of a dropped or invalid extrinsic isn't used.
+				nonces.Resync(account)
+				return types.Hash{}, false, err
+			}
+		case err := <-sub.Err():
+			return types.Hash{}, false, fmt.Errorf("%w: %v", errSubscriptionFailed, err)
+		case <-ctx.Done():
+			return types.Hash{}, false, &SubmitTimeoutError{ExtrinsicHash: extrinsicHash, Err: ctx.Err()}
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package avail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var errConnectionReset = errors.New("connection reset")
+
+// fakeSubscription is an extrinsic status subscription delivering statuses, and then failing with an error.
+type fakeSubscription struct {
+	statusCh chan types.ExtrinsicStatus
+	errCh    chan error
+}
+
+func newFakeSubscription(err error, statuses ...types.ExtrinsicStatus) *fakeSubscription {
+	sub := &fakeSubscription{
+		statusCh: make(chan types.ExtrinsicStatus, len(statuses)),
+		errCh:    make(chan error, 1),
+	}
+
+	for _, status := range statuses {
+		sub.statusCh <- status
+	}
+
+	if err != nil {
+		sub.errCh <- err
+	}
+
+	return sub
+}
+
+// statusBlockHash stands for the hash of the block the extrinsic lands in, in the statuses of the fake subscriptions.
+// It's replaced with the hash of the block of the mock client once the extrinsic lands, see landIn.
+var statusBlockHash = types.NewHash([]byte("landed"))
+
+var (
+	inBlockStatus   = types.ExtrinsicStatus{IsInBlock: true, AsInBlock: statusBlockHash}
+	finalizedStatus = types.ExtrinsicStatus{IsFinalized: true, AsFinalized: statusBlockHash}
+)
+
+func (s *fakeSubscription) Chan() <-chan types.ExtrinsicStatus { return s.statusCh }
+func (s *fakeSubscription) Err() <-chan error                  { return s.errCh }
+func (s *fakeSubscription) Unsubscribe()                       {}
+
+// landIn replaces statusBlockHash with the block hash in the statuses not received yet.
+func (s *fakeSubscription) landIn(blockHash types.Hash) {
+	statuses := make([]types.ExtrinsicStatus, 0, len(s.statusCh))
+	for len(s.statusCh) > 0 {
+		statuses = append(statuses, <-s.statusCh)
+	}
+
+	for _, status := range statuses {
+		for _, hash := range []*types.Hash{&status.AsInBlock, &status.AsRetracted, &status.AsFinalityTimeout, &status.AsFinalized} {
+			if *hash == statusBlockHash {
+				*hash = blockHash
+			}
+		}
+
+		s.statusCh <- status
+	}
+}
+
+// watchTest is a DepositBalance submission to a mock client whose status subscriptions are served in order.
+// The subscriptions are faked, as the mock client can't fail them or report statuses of its own choosing.
+type watchTest struct {
+	m      *MockClient
+	c      *client
+	funder signature.KeyringPair
+	dials  int
+	// submitted are the submitted extrinsics, and onSubmit is called with each of them.
+	submitted []types.Extrinsic
+	onSubmit  func(ext types.Extrinsic)
+}
+
+func newWatchTest(t *testing.T, policy RetryPolicy, subs ...extrinsicSubscription) *watchTest {
+	m, funder := newFundedMockClient(t, 15*AVL)
+
+	if err := m.SetNonce(funder, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	wt := &watchTest{m: m, c: m.client, funder: funder}
+
+	api := wt.c.instance()
+	served := 0
+
+	wt.c.retry = policy
+	wt.c.dial = func(_ string) (*gsrpc.SubstrateAPI, error) {
+		wt.dials++
+		return api, nil
+	}
+	wt.c.submitAndWatch = func(_ *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+		wt.submitted = append(wt.submitted, ext)
+
+		if wt.onSubmit != nil {
+			wt.onSubmit(ext)
+		}
+
+		if served == len(subs) {
+			return nil, errSubmitStopped
+		}
+
+		served++
+
+		// The extrinsic lands in the block of the statuses of the subscription.
+		if sub, ok := subs[served-1].(*fakeSubscription); ok && len(sub.statusCh) > 0 {
+			sub.landIn(wt.land(t, ext))
+		}
+
+		return subs[served-1], nil
+	}
+
+	return wt
+}
+
+// land includes the extrinsic in a new block of the mock client, its dispatch succeeding, and returns the hash of
+// the block.
+func (wt *watchTest) land(t *testing.T, ext types.Extrinsic) types.Hash {
+	wt.m.lock.Lock()
+	defer wt.m.lock.Unlock()
+
+	blockHash, err := wt.m.include(ext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return blockHash
+}
+
+func (wt *watchTest) deposit(t *testing.T) error {
+	return wt.depositWith(t, context.Background(), SubmitOpts{})
+}
+
+func (wt *watchTest) depositWith(t *testing.T, ctx context.Context, opts SubmitOpts) error {
+	recipient, err := NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DepositBalance(ctx, wt.c, wt.funder, recipient, AVL, opts)
+
+	return err
+}
+
+var testRetryPolicy = RetryPolicy{MaxReconnects: 2, ScanBlocks: 5}
+
+func TestDepositBalanceResumesAfterSubscriptionError(t *testing.T) {
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(errConnectionReset))
+
+	// The transfer lands in the head block while the subscription is down.
+	wt.onSubmit = func(ext types.Extrinsic) {
+		wt.land(t, ext)
+	}
+
+	assert.NoError(t, wt.deposit(t))
+	assert.Len(t, wt.submitted, 1)
+	assert.Equal(t, 1, wt.dials)
+}
+
+func TestDepositBalanceResubmitsAfterSubscriptionError(t *testing.T) {
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(errConnectionReset), newFakeSubscription(nil, inBlockStatus))
+
+	assert.NoError(t, wt.deposit(t))
+	assert.Equal(t, 1, wt.dials)
+
+	// The transfer didn't land and its nonce is unused, so it's resubmitted as is.
+	if assert.Len(t, wt.submitted, 2) {
+		assert.Equal(t, wt.submitted[0], wt.submitted[1])
+	}
+}
+
+func TestDepositBalanceStatusUnknownAfterSubscriptionError(t *testing.T) {
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(errConnectionReset))
+
+	// The nonce is used, by an extrinsic that isn't found in the recent blocks.
+	wt.onSubmit = func(ext types.Extrinsic) {
+		if err := wt.m.SetNonce(wt.funder, uint32(ext.Signature.Nonce.Int64())+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.ErrorIs(t, wt.deposit(t), ErrExtrinsicStatusUnknown)
+	assert.Len(t, wt.submitted, 1)
+}
+
+func TestDepositBalanceWithoutReconnects(t *testing.T) {
+	wt := newWatchTest(t, RetryPolicy{}, newFakeSubscription(errConnectionReset))
+
+	err := wt.deposit(t)
+	assert.ErrorIs(t, err, errSubscriptionFailed)
+	assert.ErrorContains(t, err, errConnectionReset.Error())
+	assert.Equal(t, 0, wt.dials)
+}
+
+func TestDepositBalanceReconnectFails(t *testing.T) {
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(errConnectionReset))
+
+	errRefused := errors.New("connection refused")
+	wt.c.dial = func(_ string) (*gsrpc.SubstrateAPI, error) {
+		wt.dials++
+		return nil, errRefused
+	}
+
+	assert.ErrorIs(t, wt.deposit(t), errRefused)
+	assert.Equal(t, testRetryPolicy.MaxReconnects, wt.dials)
+	assert.Len(t, wt.submitted, 1)
+}
+
+func TestDepositBalanceTimeout(t *testing.T) {
+	// The subscription never reports the inclusion.
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := wt.depositWith(t, ctx, SubmitOpts{})
+	assert.ErrorIs(t, err, ErrSubmitTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeoutErr *SubmitTimeoutError
+	if assert.ErrorAs(t, err, &timeoutErr) && assert.Len(t, wt.submitted, 1) {
+		extrinsicHash, err := hashExtrinsic(wt.submitted[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, extrinsicHash, timeoutErr.ExtrinsicHash)
+	}
+}
+
+func TestDepositBalanceTimeoutDuringBackoff(t *testing.T) {
+	wt := newWatchTest(t, RetryPolicy{MaxReconnects: 1, Backoff: time.Hour}, newFakeSubscription(errConnectionReset))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, wt.depositWith(t, ctx, SubmitOpts{}), ErrSubmitTimeout)
+	assert.Equal(t, 0, wt.dials)
+}
+
+func TestSubmitDataTimeoutWaitingForFinalization(t *testing.T) {
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(nil, inBlockStatus))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The submission is included, but never finalized.
+	_, err := SubmitData(ctx, wt.c, wt.funder, 1, []byte("data"), SubmitOpts{WaitFor: WaitFinalized})
+	assert.ErrorIs(t, err, ErrSubmitTimeout)
+	assert.Len(t, wt.submitted, 1)
+}
+
+func TestWaitForIncluded(t *testing.T) {
+	retracted := types.ExtrinsicStatus{IsRetracted: true, AsRetracted: statusBlockHash}
+	finalityTimeout := types.ExtrinsicStatus{IsFinalityTimeout: true, AsFinalityTimeout: statusBlockHash}
+
+	tt := []struct {
+		name     string
+		waitFor  WaitFor
+		status   types.ExtrinsicStatus
+		included bool
+		err      error
+	}{
+		{name: "in block", waitFor: WaitInBlock, status: inBlockStatus, included: true},
+		{name: "in block finalized", waitFor: WaitInBlock, status: finalizedStatus, included: true},
+		{name: "in block retracted", waitFor: WaitInBlock, status: retracted},
+		{name: "finalized in block", waitFor: WaitFinalized, status: inBlockStatus},
+		{name: "finalized", waitFor: WaitFinalized, status: finalizedStatus, included: true},
+		{name: "finalized retracted", waitFor: WaitFinalized, status: retracted, err: ErrExtrinsicRetracted},
+		{name: "finality timeout", waitFor: WaitFinalized, status: finalityTimeout, err: ErrFinalityTimeout},
+		{name: "ready", waitFor: WaitFinalized, status: types.ExtrinsicStatus{IsReady: true}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			blockHash, included, err := tc.waitFor.included(tc.status)
+			assert.Equal(t, tc.included, included)
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if included {
+				assert.Equal(t, statusBlockHash, blockHash)
+			}
+		})
+	}
+}
+
+func TestDepositBalanceWaitFinalized(t *testing.T) {
+	// Without the finalized status, the subscription reports nothing more and the deadline passes.
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(nil, inBlockStatus))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, wt.depositWith(t, ctx, SubmitOpts{WaitFor: WaitFinalized}), ErrSubmitTimeout)
+
+	wt = newWatchTest(t, testRetryPolicy, newFakeSubscription(nil, inBlockStatus, finalizedStatus))
+	assert.NoError(t, wt.depositWith(t, context.Background(), SubmitOpts{WaitFor: WaitFinalized}))
+}
+
+func TestDepositBalanceRetracted(t *testing.T) {
+	retracted := types.ExtrinsicStatus{IsRetracted: true, AsRetracted: statusBlockHash}
+
+	wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(nil, inBlockStatus, retracted))
+	assert.ErrorIs(t, wt.depositWith(t, context.Background(), SubmitOpts{WaitFor: WaitFinalized}), ErrExtrinsicRetracted)
+
+	// The retraction is ignored when waiting for the inclusion only, which returns on the in-block status.
+	wt = newWatchTest(t, testRetryPolicy, newFakeSubscription(nil, retracted, inBlockStatus))
+	assert.NoError(t, wt.deposit(t))
+}
+
+func TestDepositBalanceResumesWaitingForFinalization(t *testing.T) {
+	// The finalized head lags behind the head by the number of blocks.
+	tt := []struct {
+		name string
+		lag  uint64
+		err  bool
+	}{
+		{name: "finalized", lag: 0},
+		{name: "not finalized", lag: 1, err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			wt := newWatchTest(t, testRetryPolicy, newFakeSubscription(errConnectionReset))
+			lagFinality(wt.m, tc.lag)
+
+			wt.onSubmit = func(ext types.Extrinsic) {
+				wt.land(t, ext)
+			}
+
+			err := wt.depositWith(t, context.Background(), SubmitOpts{WaitFor: WaitFinalized})
+			if tc.err {
+				assert.Error(t, err)
+				assert.Equal(t, testRetryPolicy.MaxReconnects, wt.dials)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, wt.dials)
+			}
+
+			assert.Len(t, wt.submitted, 1)
+		})
+	}
+}
+
+// rejectSubmissions makes the submissions of the watch test fail with the errors, in order, before the
+// subscriptions are served. The chain nonce of the funder is bumped on each rejection, as if another
+// extrinsic of the account had landed.
+func (wt *watchTest) rejectSubmissions(errs ...error) {
+	submitAndWatch := wt.c.submitAndWatch
+	wt.c.submitAndWatch = func(api *gsrpc.SubstrateAPI, ext types.Extrinsic) (extrinsicSubscription, error) {
+		if len(errs) == 0 {
+			return submitAndWatch(api, ext)
+		}
+
+		err := errs[0]
+		errs = errs[1:]
+		wt.submitted = append(wt.submitted, ext)
+
+		if nonceErr := wt.m.SetNonce(wt.funder, uint32(ext.Signature.Nonce.Int64())+1); nonceErr != nil {
+			return nil, nonceErr
+		}
+
+		return nil, err
+	}
+}
+
+var errPriorityTooLow = errors.New("1014: Priority is too low: (140 vs 140)")
+
+func TestDepositBalanceResignsAfterStaleNonce(t *testing.T) {
+	wt := newWatchTest(t, RetryPolicy{MaxNonceRetries: 2}, newFakeSubscription(nil, inBlockStatus))
+	wt.rejectSubmissions(errPriorityTooLow, errors.New("1010: Invalid Transaction: Transaction is outdated"))
+
+	nonces := NewNonceManager()
+
+	assert.NoError(t, wt.depositWith(t, context.Background(), SubmitOpts{Nonces: nonces}))
+
+	// The nonce is fetched from chain again after each rejection, and the transfer re-signed with it.
+	if assert.Len(t, wt.submitted, 3) {
+		assert.Equal(t, types.NewUCompactFromUInt(5), wt.submitted[0].Signature.Nonce)
+		assert.Equal(t, types.NewUCompactFromUInt(6), wt.submitted[1].Signature.Nonce)
+		assert.Equal(t, types.NewUCompactFromUInt(7), wt.submitted[2].Signature.Nonce)
+	}
+
+	nonce, err := nonces.Next(wt.c, wt.funder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), nonce)
+}
+
+func TestDepositBalanceStaleNonceRetriesBounded(t *testing.T) {
+	wt := newWatchTest(t, RetryPolicy{MaxNonceRetries: 2})
+	wt.rejectSubmissions(errPriorityTooLow, errPriorityTooLow, errPriorityTooLow, errPriorityTooLow)
+
+	assert.ErrorIs(t, wt.deposit(t), errPriorityTooLow)
+	assert.Len(t, wt.submitted, 3)
+}
+
+func TestDepositBalanceNotResubmittedWhenAlreadyImported(t *testing.T) {
+	wt := newWatchTest(t, RetryPolicy{MaxNonceRetries: 2}, newFakeSubscription(nil, inBlockStatus))
+
+	errAlreadyImported := errors.New("1013: Transaction Already Imported")
+	wt.rejectSubmissions(errAlreadyImported)
+
+	assert.ErrorIs(t, wt.deposit(t), errAlreadyImported)
+	assert.Len(t, wt.submitted, 1)
+}
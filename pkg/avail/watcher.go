@@ -1,12 +1,10 @@
 package avail
 
 import (
-	"bytes"
 	"log"
 
 	gsrpc "github.com/centrifuge/go-substrate-rpc-client/v4"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/rpc/chain"
-	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types/codec"
 )
@@ -43,14 +41,21 @@ func NewBlockDataWatcher(client Client, appID types.UCompact, handler BlockDataH
 }
 
 // Start starts the BlockDataWatcher and begins processing blocks.
-// It returns an error if the watcher fails to start.
+// It returns an error wrapping ErrSubscriptionsUnsupported if the client is connected over HTTP, or an error if
+// the watcher fails to start.
 func (bw *BlockDataWatcher) Start() error {
-	api, err := instance(bw.client)
+	c, err := implementation(bw.client)
 	if err != nil {
 		return err
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	if err := c.requireSubscriptions("BlockDataWatcher"); err != nil {
+		return err
+	}
+
+	api := c.instance()
+
+	meta, err := latestMetadata(bw.client, api)
 	if err != nil {
 		return err
 	}
@@ -119,8 +124,7 @@ func (bw *BlockDataWatcher) processBlocks(api *gsrpc.SubstrateAPI, callIdx types
 						continue
 					}
 
-					decoder := scale.NewDecoder(bytes.NewBuffer(bs))
-					err = blob.Decode(*decoder)
+					blob, err = decodeBlob(bs)
 					if err != nil {
 						// Don't invoke HandleError() on this because there is no
 						// way of filtering uninteresting extrinsics / method.Args
@@ -768,15 +768,31 @@ func (b *Blockchain) verifyBlockParent(childBlock *types.Block) error {
 	return nil
 }
 
+var (
+	beginDisputeResolutionMethodOnce sync.Once
+	beginDisputeResolutionMethod     *abi.Method
+)
+
+// beginDisputeResolutionABIMethod returns the BeginDisputeResolution method of the Staking contract ABI,
+// parsing the ABI only on the first call.
+func beginDisputeResolutionABIMethod() *abi.Method {
+	beginDisputeResolutionMethodOnce.Do(func() {
+		method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["BeginDisputeResolution"]
+		if !ok {
+			panic("BeginDisputeResolution method doesn't exist in Staking contract ABI. Contract is broken.")
+		}
+
+		beginDisputeResolutionMethod = method
+	})
+
+	return beginDisputeResolutionMethod
+}
+
 // isBeginDisputeResolutionTx checks if the given transaction represents a BeginDisputeResolution
 // function call in the Staking contract.
 // It returns true if the transaction is a BeginDisputeResolution call and false otherwise.
 func isBeginDisputeResolutionTx(tx *types.Transaction) bool {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["BeginDisputeResolution"]
-	if !ok {
-		panic("BeginDisputeResolution method doesn't exist in Staking contract ABI. Contract is broken.")
-	}
-
+	method := beginDisputeResolutionABIMethod()
 	fnSelector := method.ID()
 
 	if len(fnSelector) >= len(tx.Input) {
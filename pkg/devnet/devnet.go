@@ -5,6 +5,7 @@
 package devnet
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -270,7 +271,8 @@ func startNode(logger hclog.Logger, cfg *edge_server.Config, availAddr, accountP
 		bootnode = true
 	}
 
-	availAccount, err := avail.AccountFromFile(accountPath)
+	// Devnet account files aren't encrypted.
+	availAccount, err := avail.AccountFromFile(accountPath, "")
 	if err != nil {
 		log.Fatalf("failed to read Avail account from %q: %s\n", accountPath, err)
 	}
@@ -280,12 +282,14 @@ func startNode(logger hclog.Logger, cfg *edge_server.Config, availAddr, accountP
 		log.Fatalf("failed to create Avail client: %s\n", err)
 	}
 
-	appID, err := avail.EnsureApplicationKeyExists(availClient, avail.ApplicationKey, availAccount)
+	nonces := avail.NewNonceManager()
+
+	appID, err := avail.EnsureApplicationKeyExists(context.Background(), availClient, avail.ApplicationKey, availAccount, avail.SubmitOpts{Nonces: nonces})
 	if err != nil {
 		log.Fatalf("failed to get AppID from Avail: %s\n", err)
 	}
 
-	availSender := avail.NewSender(availClient, appID, availAccount)
+	availSender := avail.NewSender(availClient, appID, availAccount, nonces, avail.SignOpts{})
 
 	consensusCfg := consensus.Config{
 		Bootnode:          bootnode,
@@ -403,22 +407,23 @@ func createAvailAccounts(logger hclog.Logger, availAddr, accountPath string, nod
 		return err
 	}
 
-	var nonceIncrement uint64
+	// The accounts are funded concurrently from the same dev funder account.
+	nonces := avail.NewNonceManager()
+
 	errCh := make(chan error)
 	for _, nt := range nodeTypes {
 		accountWg.Add(1)
 
-		go func(accountPath string, nonceIncrement uint64) {
+		go func(accountPath string) {
 			defer accountWg.Done()
 			// Initiate creation of the avail account if not present
-			err := createAvailAccount(logger, availClient, accountPath, nonceIncrement)
+			err := createAvailAccount(logger, availClient, accountPath, nonces)
 			if err != nil {
 				errCh <- fmt.Errorf("failed to create new avail account: %w", err)
 				return
 			}
-		}(nnh.nextAccountPath(nt), nonceIncrement)
+		}(nnh.nextAccountPath(nt))
 
-		nonceIncrement++
 		time.Sleep(250 * time.Millisecond)
 	}
 
@@ -438,15 +443,32 @@ func createAvailAccounts(logger hclog.Logger, availAddr, accountPath string, nod
 	return nil
 }
 
+// availAccountBalance is the minimum balance of the Avail accounts of the nodes, in AVL.
+const availAccountBalance = 15
+
 // createAvailAccount creates a new Avail account and deposits initial balance.
-func createAvailAccount(logger hclog.Logger, availClient avail.Client, accountPath string, nonceIncrement uint64) error {
+func createAvailAccount(logger hclog.Logger, availClient avail.Client, accountPath string, nonces *avail.NonceManager) error {
+	minimum := new(big.Int).Mul(big.NewInt(availAccountBalance), big.NewInt(avail.AVL))
+
 	// If file exists, make sure that we return the file and not go through account creation process.
-	// In rare cases, funds may be depleted but in that case we can erase files and run it again.
-	// TODO: Potentially add lookup for account balance check and if it's too low, process with creation
+	// Its balance is topped up in case the funds were depleted.
 	if _, err := os.Stat(accountPath); !errors.Is(err, os.ErrNotExist) {
 		// In case that account path exists but is not visible in Avail (restart)
 		// make sure to go through the process of the account creation.
-		if ok, err := avail.AccountExistsFromMnemonic(availClient, accountPath); err == nil && ok {
+		if account, err := avail.AccountFromFile(accountPath, ""); err != nil {
+			logger.Warn("couldn't read the Avail account file, creating a new account", "path", accountPath, "error", err)
+		} else if ok, err := avail.AccountExists(availClient, account); err != nil {
+			return err
+		} else if ok {
+			deposited, err := avail.EnsureBalance(context.Background(), availClient, avail.DevFunder(availClient), account, minimum, avail.SubmitOpts{Nonces: nonces})
+			if err != nil {
+				return err
+			}
+
+			if deposited.Sign() > 0 {
+				logger.Info("Successfully topped up", "avl", avail.FormatAVL(deposited), "to", account.Address)
+			}
+
 			return nil
 		}
 	}
@@ -456,7 +478,7 @@ func createAvailAccount(logger hclog.Logger, availClient avail.Client, accountPa
 		return err
 	}
 
-	err = avail.DepositBalance(availClient, availAccount, 15*avail.AVL, nonceIncrement)
+	deposited, err := avail.EnsureBalance(context.Background(), availClient, avail.DevFunder(availClient), availAccount, minimum, avail.SubmitOpts{Nonces: nonces})
 	if err != nil {
 		return err
 	}
@@ -465,19 +487,19 @@ func createAvailAccount(logger hclog.Logger, availClient avail.Client, accountPa
 		if !errors.Is(err, avail.ErrAppIDNotFound) {
 			return err
 		}
-		_, err = avail.EnsureApplicationKeyExists(availClient, avail.ApplicationKey, availAccount)
+		_, err = avail.EnsureApplicationKeyExists(context.Background(), availClient, avail.ApplicationKey, availAccount, avail.SubmitOpts{Nonces: nonces})
 		if err != nil {
 			return err
 		}
 	}
 
-	logger.Info("Successfully deposited", "avl", 15, "to", availAccount.Address)
+	logger.Info("Successfully deposited", "avl", avail.FormatAVL(deposited), "to", availAccount.Address)
 
-	if err := os.WriteFile(accountPath, []byte(availAccount.URI), 0o644); err != nil {
+	if err := avail.SaveAccount(accountPath, availAccount.URI, "", avail.WithAccountNetwork("devnet")); err != nil {
 		return err
 	}
 
-	logger.Info("Successfully written mnemonic", "into", accountPath)
+	logger.Info("Successfully written account file", "into", accountPath)
 
 	return nil
 }
@@ -0,0 +1,63 @@
+package staking
+
+import (
+	"sync"
+
+	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// stakingABIHandles holds the parsed staking contract ABI and the handles of its methods and events.
+// It is built once and never modified afterwards, so it can be read from any goroutine.
+type stakingABIHandles struct {
+	abi     *abi.ABI
+	methods map[string]*abi.Method
+	events  map[string]*abi.Event
+}
+
+var (
+	stakingABIOnce   sync.Once
+	stakingABICached *stakingABIHandles
+)
+
+// stakingHandles returns the staking contract ABI handles, parsing the ABI on the first call.
+func stakingHandles() *stakingABIHandles {
+	stakingABIOnce.Do(func() {
+		parsed := abi.MustNewABI(staking_contract.StakingABI)
+
+		handles := &stakingABIHandles{
+			abi:     parsed,
+			methods: make(map[string]*abi.Method, len(parsed.Methods)),
+			events:  make(map[string]*abi.Event, len(parsed.Events)),
+		}
+
+		for name, method := range parsed.Methods {
+			handles.methods[name] = method
+		}
+
+		for name, event := range parsed.Events {
+			handles.events[name] = event
+		}
+
+		stakingABICached = handles
+	})
+
+	return stakingABICached
+}
+
+// stakingABI returns the parsed staking contract ABI, shared by all callers. It must not be modified.
+func stakingABI() *abi.ABI {
+	return stakingHandles().abi
+}
+
+// stakingMethod returns the handle of the staking contract method with the given name and false if the ABI doesn't have it.
+func stakingMethod(name string) (*abi.Method, bool) {
+	method, ok := stakingHandles().methods[name]
+	return method, ok
+}
+
+// stakingEvent returns the handle of the staking contract event with the given name and false if the ABI doesn't have it.
+func stakingEvent(name string) (*abi.Event, bool) {
+	event, ok := stakingHandles().events[name]
+	return event, ok
+}
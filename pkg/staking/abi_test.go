@@ -0,0 +1,92 @@
+package staking
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestStakingABIHandlesShared(t *testing.T) {
+	tAssert := assert.New(t)
+
+	method, ok := stakingMethod("GetCurrentSequencers")
+	tAssert.True(ok)
+
+	other, ok := stakingMethod("GetCurrentSequencers")
+	tAssert.True(ok)
+	tAssert.True(method == other, "method handles should be built once")
+	tAssert.True(stakingABI().Methods["GetCurrentSequencers"] == method)
+
+	_, ok = stakingMethod("GetNonExistent")
+	tAssert.False(ok)
+
+	_, ok = stakingEvent("Staked")
+	tAssert.True(ok)
+}
+
+// TestStakingQueriesConcurrent runs the query paths from many goroutines at once.
+// Run it with -race to check the ABI handles are safe for concurrent use.
+func TestStakingQueriesConcurrent(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencer, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencer, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	const workers = 16
+
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, workers*4)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sequencers, err := querier.Get(Sequencer)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			if len(sequencers) != 1 || sequencers[0] != sequencer {
+				t.Errorf("unexpected sequencers: %v", sequencers)
+			}
+
+			if _, err := querier.GetBalance(sequencer); err != nil {
+				errCh <- err
+			}
+
+			if _, err := querier.GetTotalStakedAmount(); err != nil {
+				errCh <- err
+			}
+
+			if _, err := StakeTx(types.Address{}, stakeAmount, string(Sequencer), 1_000_000); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		tAssert.NoError(err)
+	}
+}
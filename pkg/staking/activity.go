@@ -0,0 +1,137 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/blockchain"
+	"github.com/umbracle/ethgo"
+)
+
+// The activity of a sequencer is the last block it produced or, for a freshly staked sequencer that hasn't
+// produced a block yet, the block it staked in, so it isn't reported as inactive before it had the time to
+// produce one. The deployed staking contract doesn't have the GetLastBlockProduced getter, in that case the
+// activity is derived from the chain instead: the miner of every block is the sequencer that produced it.
+
+// QueryLastBlockProduced queries the activity of the sequencer from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, and the address of the sequencer as parameters.
+// It returns the number of the last block produced by the sequencer, or of the block it staked in if it hasn't
+// produced one yet, and an error if the operation fails.
+// The returned error wraps ErrUnsupportedByContract if the contract doesn't track the activity of the sequencers.
+func QueryLastBlockProduced(t *state.Transition, gasLimit uint64, from types.Address, sequencer types.Address) (uint64, error) {
+	return DefaultStakingContract().queryLastBlockProduced(t, gasLimit, from, sequencer)
+}
+
+// queryLastBlockProduced queries the activity of the sequencer with the given transition.
+func (sc *StakingContract) queryLastBlockProduced(t *state.Transition, gasLimit uint64, from types.Address, sequencer types.Address) (uint64, error) {
+	last, err := sc.queryAmount(t, gasLimit, from, "GetLastBlockProduced", map[string]interface{}{"sequencerAddr": ethgo.Address(sequencer)})
+	if err != nil {
+		return 0, err
+	}
+
+	if !last.IsUint64() {
+		return 0, fmt.Errorf("last block produced by %s out of range: %s", sequencer, last)
+	}
+
+	return last.Uint64(), nil
+}
+
+// GetInactiveSequencers method returns the active sequencers whose last activity is at least threshold blocks
+// older than the chain head, in the order returned by Get.
+// The activity is read from the contract if it tracks it. Otherwise it is derived from the last threshold blocks,
+// which is capped by MaxStakingHistoryRange, with sequencers staked in the genesis block counting as active at block 0.
+// It returns an error wrapping ErrUnsupportedByContract if the contract doesn't track the activity and the querier
+// can't read the blocks, i.e. it is bound to a state root.
+func (asq *activeParticipantsQuerier) GetInactiveSequencers(threshold uint64) ([]types.Address, error) {
+	parent := asq.head()
+
+	sequencers, err := asq.Get(Sequencer)
+	if err != nil {
+		return nil, err
+	}
+
+	if threshold == 0 || len(sequencers) == 0 {
+		return nil, nil
+	}
+
+	contract := asq.registry.Contract(Sequencer)
+
+	var inactive []types.Address
+
+	err = asq.withTxn(parent, uint64(len(sequencers)), func(t *state.Transition, gasLimit uint64) error {
+		inactive = nil
+
+		for _, sequencer := range sequencers {
+			last, err := contract.queryLastBlockProduced(t, gasLimit, types.BytesToAddress(parent.Miner), sequencer)
+			if err != nil {
+				return err
+			}
+
+			if isInactive(parent.Number, last, threshold) {
+				inactive = append(inactive, sequencer)
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, ErrUnsupportedByContract) && asq.blockchain != nil {
+		inactive, err = findInactiveSequencers(asq.blockchain, parent, sequencers, threshold)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := asq.checkHead(parent, "GetInactiveSequencers"); err != nil {
+		return nil, err
+	}
+
+	return inactive, nil
+}
+
+// isInactive checks whether the activity at block last is at least threshold blocks older than the head.
+func isInactive(head, last, threshold uint64) bool {
+	return last <= head && head-last >= threshold
+}
+
+// findInactiveSequencers walks the last threshold blocks up to the head and returns the sequencers that neither
+// produced nor staked in any of them, in the given order.
+// It returns an error if the threshold exceeds MaxStakingHistoryRange or the operation fails.
+func findInactiveSequencers(blockchain *blockchain.Blockchain, head *types.Header, sequencers []types.Address, threshold uint64) ([]types.Address, error) {
+	if threshold > MaxStakingHistoryRange {
+		return nil, fmt.Errorf("%w: threshold of %d exceeds %d blocks", ErrStakingHistoryRangeTooLarge, threshold, MaxStakingHistoryRange)
+	}
+
+	// Nobody can be inactive for longer than the chain exists.
+	if head.Number < threshold {
+		return nil, nil
+	}
+
+	active := map[types.Address]struct{}{}
+
+	err := walkStakingEvents(blockchain, head.Number-threshold+1, head.Number, func(hdr *types.Header, event *StakingEvent) error {
+		switch {
+		case event == nil:
+			active[types.BytesToAddress(hdr.Miner)] = struct{}{}
+		case event.Action == ActionStaked:
+			active[event.Account] = struct{}{}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var inactive []types.Address
+
+	for _, sequencer := range sequencers {
+		if _, ok := active[sequencer]; !ok {
+			inactive = append(inactive, sequencer)
+		}
+	}
+
+	return inactive, nil
+}
@@ -0,0 +1,92 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/block"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestGetInactiveSequencers(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+
+	sequencer2, sequencer2SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer2, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer2, sequencer2SignKey, stakeAmount, 1_000_000, "test"))
+
+	// Freshly staked sequencers that haven't produced a block since their stake aren't inactive.
+	inactive, err := querier.GetInactiveSequencers(2)
+	tAssert.NoError(err)
+	tAssert.Empty(inactive)
+
+	// Blocks produced by somebody else.
+	const idleBlocks = 3
+	for i := 0; i < idleBlocks; i++ {
+		other, _ := test.NewAccount(t)
+		test.DepositBalance(t, other, balance, blockchain, executor)
+	}
+
+	inactive, err = querier.GetInactiveSequencers(idleBlocks + 1)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, inactive)
+
+	inactive, err = querier.GetInactiveSequencers(idleBlocks)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2}, inactive)
+
+	// Sequencer 1 produces a block.
+	blk, err := block.NewBlockBuilderFactory(blockchain, executor, hclog.Default()).FromBlockchainHead()
+	tAssert.NoError(err)
+
+	blk.SetCoinbaseAddress(sequencer1)
+	blk.SignWith(sequencer1SignKey)
+
+	fBlock, err := blk.Build()
+	tAssert.NoError(err)
+	tAssert.NoError(blockchain.WriteBlock(fBlock, "test"))
+
+	inactive, err = querier.GetInactiveSequencers(idleBlocks)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer2}, inactive)
+
+	// The deployed contract doesn't track the activity, it's derived from the chain by the querier.
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, types.BytesToAddress(parent.Miner))
+	tAssert.NoError(err)
+
+	_, err = QueryLastBlockProduced(transition, 1_000_000, types.BytesToAddress(parent.Miner), sequencer1)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract), "unexpected error: %v", err)
+
+	// A querier bound to a state root can't read the blocks.
+	rootQuerier := NewParticipantsQuerierAtRoot(executor, parent.StateRoot, parent, hclog.Default())
+	_, err = rootQuerier.GetInactiveSequencers(idleBlocks)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract), "unexpected error: %v", err)
+
+	// The chain is younger than the threshold.
+	inactive, err = querier.GetInactiveSequencers(blockchain.Header().Number + 1)
+	tAssert.NoError(err)
+	tAssert.Empty(inactive)
+
+	_, err = querier.GetInactiveSequencers(MaxStakingHistoryRange + 1)
+	tAssert.True(errors.Is(err, ErrStakingHistoryRangeTooLarge))
+}
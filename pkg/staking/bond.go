@@ -0,0 +1,170 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// WatchtowerBond is the bond a watchtower posted and the part of it it can lose for false accusations.
+type WatchtowerBond struct {
+	// Bond is the amount the watchtower posted.
+	Bond *big.Int
+	// AtRisk is the amount slashed if all the open disputes raised by the watchtower turn out to be false accusations.
+	AtRisk *big.Int
+	// OpenDisputes is the number of open disputes raised by the watchtower.
+	OpenDisputes int
+}
+
+// bondCall calls a staking contract method in the state the bond is read from.
+type bondCall func(methodName string, inputs map[string]interface{}) (*abi.Method, []byte, error)
+
+// QueryWatchtowerBond queries the bond of the watchtower from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, and the address of the watchtower as parameters.
+// The deployed contract doesn't keep a separate bond, in that case the stake of the watchtower is its bond.
+// It returns the bond as a big.Int value and an error if the operation fails.
+func QueryWatchtowerBond(t *state.Transition, gasLimit uint64, from types.Address, addr types.Address) (*big.Int, error) {
+	return queryBond(DefaultStakingContract().bondCall(t, gasLimit, from), addr)
+}
+
+// QueryBondAtRisk queries the part of the bond of the watchtower at risk in its open disputes from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, and the address of the watchtower as parameters.
+// The bond, the open disputes and the slash percentage are all read in the given transition, so the amount is consistent
+// with the open disputes returned by QueryDisputes or QueryDisputedWatchtowers in the same transition.
+// It returns the amount at risk as a big.Int value and an error if the operation fails.
+func QueryBondAtRisk(t *state.Transition, gasLimit uint64, from types.Address, addr types.Address) (*big.Int, error) {
+	bond, err := queryWatchtowerBond(DefaultStakingContract().bondCall(t, gasLimit, from), addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return bond.AtRisk, nil
+}
+
+// GetWatchtowerBond method returns the bond of the watchtower and the part of it at risk in its open disputes,
+// all queried in a single transition on top of the chain head.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) GetWatchtowerBond(addr types.Address) (*WatchtowerBond, error) {
+	parent := asq.head()
+	contract := asq.registry.Contract(WatchTower)
+
+	var bond *WatchtowerBond
+
+	// The bond, the open disputes and the slash percentage are queried, methods missing in the ABI aren't called.
+	err := asq.withTxn(parent, 3, func(t *state.Transition, gasLimit uint64) (err error) {
+		bond, err = queryWatchtowerBond(contract.bondCall(t, gasLimit, types.BytesToAddress(parent.Miner)), addr)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bond, nil
+}
+
+// bondCall binds the contract calls of the bond queries to the transition.
+func (sc *StakingContract) bondCall(t *state.Transition, gasLimit uint64, from types.Address) bondCall {
+	return func(methodName string, inputs map[string]interface{}) (*abi.Method, []byte, error) {
+		return sc.call(t, gasLimit, from, methodName, inputs)
+	}
+}
+
+// queryWatchtowerBond reads the bond of the watchtower, its open disputes and the slash percentage with the given call,
+// and computes the amount at risk from them.
+func queryWatchtowerBond(call bondCall, addr types.Address) (*WatchtowerBond, error) {
+	bond, err := queryBond(call, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	openDisputes, err := queryOpenDisputes(call, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	toReturn := &WatchtowerBond{
+		Bond:         bond,
+		AtRisk:       big.NewInt(0),
+		OpenDisputes: openDisputes,
+	}
+
+	if openDisputes == 0 {
+		return toReturn, nil
+	}
+
+	_, returnValue, err := call("GetSlashPercentage", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every false accusation is slashed by the slash percentage of the bond, and no more than the bond can be slashed.
+	toReturn.AtRisk.Mul(bond, new(big.Int).SetBytes(returnValue))
+	toReturn.AtRisk.Mul(toReturn.AtRisk, big.NewInt(int64(openDisputes)))
+	toReturn.AtRisk.Div(toReturn.AtRisk, big.NewInt(100))
+
+	if toReturn.AtRisk.Cmp(bond) > 0 {
+		toReturn.AtRisk.Set(bond)
+	}
+
+	return toReturn, nil
+}
+
+// queryBond reads the bond of the watchtower, falling back to its stake if the contract doesn't keep a separate bond.
+func queryBond(call bondCall, addr types.Address) (*big.Int, error) {
+	_, returnValue, err := call("GetWatchtowerBond", map[string]interface{}{"watchtowerAddr": ethgo.Address(addr)})
+	if errors.Is(err, ErrUnsupportedByContract) {
+		_, returnValue, err = call("GetCurrentAccountStakedAmount", map[string]interface{}{"addr": ethgo.Address(addr)})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(returnValue), nil
+}
+
+// queryOpenDisputes counts the open disputes raised by the watchtower. It reads the dispute records if the contract
+// keeps them, otherwise the disputed watchtowers, as the deployed contract allows a single open dispute per watchtower.
+func queryOpenDisputes(call bondCall, addr types.Address) (int, error) {
+	method, returnValue, err := call("GetDisputes", nil)
+	if err == nil {
+		disputes, err := DecodeDisputeRecords(method, returnValue)
+		if err != nil {
+			return 0, err
+		}
+
+		open := 0
+
+		for _, dispute := range disputes {
+			if dispute.Accuser == addr && dispute.Status == DisputeStatusOpen {
+				open++
+			}
+		}
+
+		return open, nil
+	}
+
+	if !errors.Is(err, ErrUnsupportedByContract) {
+		return 0, err
+	}
+
+	method, returnValue, err = call("GetCurrentDisputeWatchtowers", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	watchtowers, err := DecodeParticipants(method, returnValue)
+	if err != nil {
+		return 0, err
+	}
+
+	if containsAddress(watchtowers, addr) {
+		return 1, nil
+	}
+
+	return 0, nil
+}
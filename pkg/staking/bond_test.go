@@ -0,0 +1,65 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierGetWatchtowerBond(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	// Without open disputes nothing is at risk.
+	bond, err := querier.GetWatchtowerBond(watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(&WatchtowerBond{Bond: stakeAmount, AtRisk: big.NewInt(0)}, bond)
+
+	tAssert.NoError(NewDisputeResolution(blockchain, executor, sender, hclog.Default()).Begin(sequencerAddr, watchtowerSignKey))
+
+	// The slash percentage of the genesis contract is 1%.
+	onePercent := big.NewInt(0).Div(stakeAmount, big.NewInt(100))
+
+	bond, err = querier.GetWatchtowerBond(watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(&WatchtowerBond{Bond: stakeAmount, AtRisk: onePercent, OpenDisputes: 1}, bond)
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, watchtowerAddr)
+	tAssert.NoError(err)
+
+	amount, err := QueryWatchtowerBond(transition, 1_000_000, watchtowerAddr, watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, amount)
+
+	atRisk, err := QueryBondAtRisk(transition, 1_000_000, watchtowerAddr, watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(onePercent, atRisk)
+
+	// The disputed sequencer has no open dispute of its own.
+	atRisk, err = QueryBondAtRisk(transition, 1_000_000, watchtowerAddr, sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(big.NewInt(0), atRisk)
+}
@@ -0,0 +1,189 @@
+package staking
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+)
+
+// ChurnStats are the participant set changes observed within the tracking window.
+type ChurnStats struct {
+	// Window is the duration the changes are counted over.
+	Window time.Duration
+	// Additions is the number of participants that joined the set.
+	Additions int
+	// Removals is the number of participants that left the set.
+	Removals int
+	// Probations is the number of sequencers that were put in probation.
+	Probations int
+}
+
+// churnEvent are the changes introduced by a single SetChanged notification.
+type churnEvent struct {
+	at         time.Time
+	additions  int
+	removals   int
+	probations int
+}
+
+// ChurnTrackerOption configures the churn tracker.
+type ChurnTrackerOption func(*ChurnTracker)
+
+// WithChurnGauges reports the churn stats as metrics gauges each time they are updated.
+func WithChurnGauges() ChurnTrackerOption {
+	return func(ct *ChurnTracker) {
+		ct.gauges = true
+	}
+}
+
+// ChurnTracker keeps rolling counts of the participant set changes reported by the ParticipantWatcher,
+// so an unusually volatile sequencer set can be alerted on.
+// The counts are kept in memory only; after a restart the tracker starts from an empty window.
+type ChurnTracker struct {
+	window time.Duration
+	gauges bool
+	logger hclog.Logger
+	now    func() time.Time
+
+	lock   sync.Mutex
+	events []churnEvent
+}
+
+// NewChurnTracker creates a new instance of ChurnTracker.
+// It takes the duration of the tracking window, a logger and options as parameters.
+func NewChurnTracker(window time.Duration, logger hclog.Logger, opts ...ChurnTrackerOption) *ChurnTracker {
+	ct := &ChurnTracker{
+		window: window,
+		logger: logger.Named("churn_tracker"),
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(ct)
+	}
+
+	return ct
+}
+
+// Run feeds the tracker with the notifications received on the given channel, usually the
+// ParticipantWatcher's Changes channel, until the context is cancelled or the channel is closed.
+func (ct *ChurnTracker) Run(ctx context.Context, changes <-chan SetChanged) {
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+
+			ct.Observe(change)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Observe records the changes between the previous and the current snapshot of the notification.
+// The first notification, without a previous snapshot, is the baseline and doesn't count as churn.
+// Sequencers moving in or out of probation remain participants, so they aren't counted as additions or removals.
+func (ct *ChurnTracker) Observe(change SetChanged) {
+	if change.Previous == nil || change.Current == nil {
+		return
+	}
+
+	previous := participantSet(change.Previous)
+	current := participantSet(change.Current)
+
+	event := churnEvent{
+		additions:  countMissing(current, previous),
+		removals:   countMissing(previous, current),
+		probations: countMissing(addressSet(change.Current.SequencersInProbation), addressSet(change.Previous.SequencersInProbation)),
+	}
+
+	if event.additions == 0 && event.removals == 0 && event.probations == 0 {
+		return
+	}
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	event.at = ct.now()
+	ct.events = append(ct.events, event)
+
+	stats := ct.statsLocked()
+
+	ct.logger.Debug("participant churn", "block_number", change.Current.BlockNumber, "additions", event.additions, "removals", event.removals, "probations", event.probations, "window_additions", stats.Additions, "window_removals", stats.Removals, "window_probations", stats.Probations)
+
+	ct.reportLocked(stats)
+}
+
+// Stats returns the changes observed within the tracking window ending now.
+func (ct *ChurnTracker) Stats() ChurnStats {
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	stats := ct.statsLocked()
+	ct.reportLocked(stats)
+
+	return stats
+}
+
+// statsLocked drops the events that fell out of the window and sums up the remaining ones.
+// The lock must be held by the caller.
+func (ct *ChurnTracker) statsLocked() ChurnStats {
+	cutoff := ct.now().Add(-ct.window)
+
+	expired := 0
+	for expired < len(ct.events) && !ct.events[expired].at.After(cutoff) {
+		expired++
+	}
+
+	ct.events = ct.events[expired:]
+
+	stats := ChurnStats{Window: ct.window}
+	for _, event := range ct.events {
+		stats.Additions += event.additions
+		stats.Removals += event.removals
+		stats.Probations += event.probations
+	}
+
+	return stats
+}
+
+// reportLocked sets the metrics gauges, if enabled. The lock must be held by the caller.
+func (ct *ChurnTracker) reportLocked(stats ChurnStats) {
+	if !ct.gauges {
+		return
+	}
+
+	metrics.SetGauge([]string{"staking", "churn", "additions"}, float32(stats.Additions))
+	metrics.SetGauge([]string{"staking", "churn", "removals"}, float32(stats.Removals))
+	metrics.SetGauge([]string{"staking", "churn", "probations"}, float32(stats.Probations))
+}
+
+// participantSet returns the addresses of all participants of the snapshot, including the sequencers in probation.
+func participantSet(snapshot *ParticipantsSnapshot) map[types.Address]struct{} {
+	set := addressSet(snapshot.Sequencers)
+	for _, addrs := range [][]types.Address{snapshot.SequencersInProbation, snapshot.WatchTowers} {
+		for _, addr := range addrs {
+			set[addr] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// countMissing returns the number of addresses of a that aren't in b.
+func countMissing(a, b map[types.Address]struct{}) int {
+	count := 0
+	for addr := range a {
+		if _, ok := b[addr]; !ok {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,74 @@
+package staking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestChurnTracker(t *testing.T) {
+	tAssert := assert.New(t)
+
+	sequencer1 := types.StringToAddress("1")
+	sequencer2 := types.StringToAddress("2")
+	sequencer3 := types.StringToAddress("3")
+	watchtower := types.StringToAddress("4")
+
+	now := time.Unix(1_000_000, 0)
+
+	tracker := NewChurnTracker(time.Hour, hclog.NewNullLogger(), WithChurnGauges())
+	tracker.now = func() time.Time { return now }
+
+	snapshot1 := &ParticipantsSnapshot{BlockNumber: 1, Sequencers: []types.Address{sequencer1, sequencer2}, WatchTowers: []types.Address{watchtower}}
+	snapshot2 := &ParticipantsSnapshot{BlockNumber: 2, Sequencers: []types.Address{sequencer1, sequencer3}, SequencersInProbation: []types.Address{sequencer2}, WatchTowers: []types.Address{watchtower}}
+	snapshot3 := &ParticipantsSnapshot{BlockNumber: 3, Sequencers: []types.Address{sequencer1, sequencer3}, WatchTowers: []types.Address{watchtower}}
+
+	// The first notification is the baseline.
+	tracker.Observe(SetChanged{Current: snapshot1})
+	tAssert.Equal(ChurnStats{Window: time.Hour}, tracker.Stats())
+
+	// Sequencer 3 joins and sequencer 2 is put in probation.
+	tracker.Observe(SetChanged{Previous: snapshot1, Current: snapshot2})
+	tAssert.Equal(ChurnStats{Window: time.Hour, Additions: 1, Probations: 1}, tracker.Stats())
+
+	// Sequencer 2 is removed from probation and from the set.
+	now = now.Add(30 * time.Minute)
+	tracker.Observe(SetChanged{Previous: snapshot2, Current: snapshot3})
+	tAssert.Equal(ChurnStats{Window: time.Hour, Additions: 1, Removals: 1, Probations: 1}, tracker.Stats())
+
+	// The first change falls out of the window.
+	now = now.Add(30 * time.Minute)
+	tAssert.Equal(ChurnStats{Window: time.Hour, Removals: 1}, tracker.Stats())
+
+	now = now.Add(30 * time.Minute)
+	tAssert.Equal(ChurnStats{Window: time.Hour}, tracker.Stats())
+
+	// A restarted tracker starts from an empty window.
+	tracker = NewChurnTracker(time.Hour, hclog.NewNullLogger())
+	tAssert.Equal(ChurnStats{Window: time.Hour}, tracker.Stats())
+}
+
+func TestChurnTrackerRun(t *testing.T) {
+	tAssert := assert.New(t)
+
+	sequencer1 := types.StringToAddress("1")
+	sequencer2 := types.StringToAddress("2")
+
+	tracker := NewChurnTracker(time.Hour, hclog.NewNullLogger())
+
+	changes := make(chan SetChanged, 2)
+	changes <- SetChanged{Current: &ParticipantsSnapshot{Sequencers: []types.Address{sequencer1}}}
+	changes <- SetChanged{
+		Previous: &ParticipantsSnapshot{Sequencers: []types.Address{sequencer1}},
+		Current:  &ParticipantsSnapshot{Sequencers: []types.Address{sequencer2}},
+	}
+	close(changes)
+
+	tracker.Run(context.Background(), changes)
+
+	tAssert.Equal(ChurnStats{Window: time.Hour, Additions: 1, Removals: 1}, tracker.Stats())
+}
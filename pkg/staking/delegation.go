@@ -0,0 +1,156 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/umbracle/ethgo"
+)
+
+// ErrUnsupportedByContract is returned when the deployed staking contract does not
+// implement the method required to answer a query (e.g. delegation getters on an
+// older contract deployment).
+var ErrUnsupportedByContract = errors.New("method not supported by the staking contract")
+
+// DelegationInfo holds the stake composition of a single participant.
+type DelegationInfo struct {
+	// OwnStake is the amount staked by the participant itself.
+	OwnStake *big.Int
+	// DelegatedStake is the amount delegated to the participant by third parties.
+	DelegatedStake *big.Int
+	// Delegators is the list of addresses that delegated stake to the participant.
+	Delegators []types.Address
+}
+
+// TotalStake returns the effective stake of the participant, i.e. own stake plus delegated stake.
+func (di *DelegationInfo) TotalStake() *big.Int {
+	total := new(big.Int)
+
+	if di.OwnStake != nil {
+		total.Add(total, di.OwnStake)
+	}
+
+	if di.DelegatedStake != nil {
+		total.Add(total, di.DelegatedStake)
+	}
+
+	return total
+}
+
+// GetDelegationInfo method retrieves own stake, delegated stake and the list of delegators for the given address.
+// All values are queried on top of the same parent state root so they describe one consistent state.
+// It returns an error wrapping ErrUnsupportedByContract if the contract doesn't support delegation.
+func (asq *activeParticipantsQuerier) GetDelegationInfo(address types.Address) (*DelegationInfo, error) {
+	parent := asq.head()
+	contract := asq.registry.Contract(Sequencer)
+
+	ownStake, err := asq.queryAmount(parent, contract, "GetCurrentAccountStakedAmount", map[string]interface{}{"addr": ethgo.Address(address)})
+	if err != nil {
+		return nil, err
+	}
+
+	delegatedStake, err := asq.queryAmount(parent, contract, "GetDelegatedAmount", map[string]interface{}{"sequencerAddr": ethgo.Address(address)})
+	if err != nil {
+		return nil, err
+	}
+
+	delegators, err := asq.queryAddresses(parent, Sequencer, "GetDelegators", map[string]interface{}{"sequencerAddr": ethgo.Address(address)})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := asq.checkHead(parent, "GetDelegationInfo"); err != nil {
+		return nil, err
+	}
+
+	return &DelegationInfo{
+		OwnStake:       ownStake,
+		DelegatedStake: delegatedStake,
+		Delegators:     delegators,
+	}, nil
+}
+
+// QueryDelegatedAmount queries the amount delegated to a sequencer by third parties from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, and the address of the sequencer as parameters.
+// It returns the delegated amount as a big.Int value and an error if the operation fails.
+// The returned error wraps ErrUnsupportedByContract if the contract doesn't support delegation.
+func QueryDelegatedAmount(t *state.Transition, gasLimit uint64, from types.Address, sequencer types.Address) (*big.Int, error) {
+	method, ok := stakingMethod("GetDelegatedAmount")
+	if !ok {
+		return nil, fmt.Errorf("%w: GetDelegatedAmount", ErrUnsupportedByContract)
+	}
+
+	selector := method.ID()
+
+	encodedInput, encodeErr := method.Inputs.Encode(
+		map[string]interface{}{
+			"sequencerAddr": ethgo.Address(sequencer),
+		},
+	)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+
+	res, err := applyQuery(t, &types.Transaction{
+		From:     from,
+		To:       &AddrStakingContract,
+		Value:    big.NewInt(0),
+		Input:    append(selector, encodedInput...),
+		GasPrice: big.NewInt(0),
+		Gas:      gasLimit,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Failed() {
+		return nil, queryFailure(method.Name, res)
+	}
+
+	return new(big.Int).SetBytes(res.ReturnValue), nil
+}
+
+// QueryDelegators queries the addresses that delegated stake to a sequencer from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, and the address of the sequencer as parameters.
+// It returns a slice of delegator addresses and an error if the operation fails.
+// The returned error wraps ErrUnsupportedByContract if the contract doesn't support delegation.
+func QueryDelegators(t *state.Transition, gasLimit uint64, from types.Address, sequencer types.Address) ([]types.Address, error) {
+	method, ok := stakingMethod("GetDelegators")
+	if !ok {
+		return nil, fmt.Errorf("%w: GetDelegators", ErrUnsupportedByContract)
+	}
+
+	selector := method.ID()
+
+	encodedInput, encodeErr := method.Inputs.Encode(
+		map[string]interface{}{
+			"sequencerAddr": ethgo.Address(sequencer),
+		},
+	)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+
+	res, err := applyQuery(t, &types.Transaction{
+		From:     from,
+		To:       &AddrStakingContract,
+		Value:    big.NewInt(0),
+		Input:    append(selector, encodedInput...),
+		GasPrice: big.NewInt(0),
+		Gas:      gasLimit,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Failed() {
+		return nil, queryFailure(method.Name, res)
+	}
+
+	return DecodeParticipants(method, res.ReturnValue)
+}
@@ -0,0 +1,25 @@
+package staking
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestGetDelegationInfoUnsupportedByContract(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	sequencerAddr, _ := test.NewAccount(t)
+
+	participantsQuerier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	info, err := participantsQuerier.GetDelegationInfo(sequencerAddr)
+	tAssert.Nil(info)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract))
+}
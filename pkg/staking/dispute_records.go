@@ -0,0 +1,350 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/blockchain"
+	"github.com/hashicorp/go-hclog"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// DisputeStatus is the status of a dispute recorded by the staking contract.
+type DisputeStatus uint8
+
+const (
+	// DisputeStatusOpen means the dispute has been raised and is not resolved yet.
+	DisputeStatusOpen DisputeStatus = iota
+	// DisputeStatusResolved means the dispute has been resolved.
+	DisputeStatusResolved
+)
+
+// DisputeRecord is a dispute raised by a watchtower against a sequencer for a block.
+type DisputeRecord struct {
+	// Accuser is the address of the watchtower that raised the dispute.
+	Accuser types.Address
+	// Accused is the address of the disputed sequencer.
+	Accused types.Address
+	// BlockHash is the hash of the disputed block.
+	BlockHash types.Hash
+	// Status is the status of the dispute.
+	Status DisputeStatus
+}
+
+// DisputeQuerier defines the methods for reading the dispute records kept by the staking contract.
+// The deployed staking contract doesn't keep dispute records, both methods return an error wrapping
+// ErrUnsupportedByContract then: whether a block is disputed is unknown, which must not be taken for
+// the block not being disputed.
+type DisputeQuerier interface {
+	// Disputes retrieves all dispute records.
+	Disputes() ([]DisputeRecord, error)
+
+	// DisputeForBlock retrieves the dispute record for the given block hash.
+	// The returned boolean is false if no dispute was raised for the block, and only tells so without an error.
+	DisputeForBlock(blockHash types.Hash) (*DisputeRecord, bool, error)
+}
+
+// NewDisputeQuerier creates a new instance of DisputeQuerier with the
+// provided blockchain, executor, and logger.
+//
+// Parameters:
+//
+//	blockchain - The blockchain instance.
+//	executor - The executor instance.
+//	logger - The logger instance.
+//
+// Returns:
+//
+//	A new instance of DisputeQuerier.
+//
+// Example:
+//
+//	dq := NewDisputeQuerier(blockchain, executor, logger)
+func NewDisputeQuerier(blockchain *blockchain.Blockchain, executor *state.Executor, logger hclog.Logger) DisputeQuerier {
+	return &disputeResolution{
+		blockchain: blockchain,
+		executor:   executor,
+		logger:     logger.ResetNamed("staking_dispute_resolution"),
+	}
+}
+
+// Disputes is a method on the disputeResolution structure that retrieves
+// all the dispute records kept by the staking contract.
+//
+// Returns:
+//
+//	An array of dispute records.
+//	An error wrapping ErrUnsupportedByContract if the contract doesn't keep dispute records.
+//
+// Example:
+//
+//	disputes, err := dq.Disputes()
+//	if err != nil {
+//	  log.Fatalf("failed to retrieve disputes: %s", err)
+//	}
+func (dr *disputeResolution) Disputes() ([]DisputeRecord, error) {
+	transition, gasLimit, minerAddress, err := dr.beginQueryTxn()
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryDisputes(transition, gasLimit, minerAddress)
+}
+
+// DisputeForBlock is a method on the disputeResolution structure that retrieves
+// the dispute record for the given block hash.
+//
+// Parameters:
+//
+//	blockHash - The hash of the block.
+//
+// Returns:
+//
+//	The dispute record and true if a dispute was raised for the block.
+//	An error wrapping ErrUnsupportedByContract if the contract doesn't keep dispute records.
+//
+// Example:
+//
+//	dispute, exists, err := dq.DisputeForBlock(blk.Header.Hash)
+//	if err != nil {
+//	  log.Fatalf("failed to retrieve dispute: %s", err)
+//	}
+func (dr *disputeResolution) DisputeForBlock(blockHash types.Hash) (*DisputeRecord, bool, error) {
+	transition, gasLimit, minerAddress, err := dr.beginQueryTxn()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return QueryDisputeForBlock(transition, gasLimit, minerAddress, blockHash)
+}
+
+// beginQueryTxn begins a new transition on top of the chain head for querying the staking contract.
+func (dr *disputeResolution) beginQueryTxn() (*state.Transition, uint64, types.Address, error) {
+	parent := dr.blockchain.Header()
+	minerAddress := types.BytesToAddress(parent.Miner)
+
+	header := &types.Header{
+		ParentHash: parent.Hash,
+		Number:     parent.Number + 1,
+		Miner:      minerAddress.Bytes(),
+		Nonce:      types.Nonce{},
+		GasLimit:   parent.GasLimit, // Inherit from parent for now, will need to adjust dynamically later.
+		Timestamp:  uint64(time.Now().Unix()),
+	}
+
+	// calculate gas limit based on parent header
+	gasLimit, err := dr.blockchain.CalculateGasLimit(header.Number)
+	if err != nil {
+		return nil, 0, types.Address{}, err
+	}
+
+	transition, err := dr.executor.BeginTxn(parent.StateRoot, header, minerAddress)
+	if err != nil {
+		return nil, 0, types.Address{}, err
+	}
+
+	return transition, gasLimit, minerAddress, nil
+}
+
+// QueryDisputes calls the GetDisputes method on the Staking contract.
+//
+// It sends a transaction to query the contract and returns the result.
+//
+// Parameters:
+//
+//	t - The state transition object.
+//	gasLimit - The gas limit for the transaction.
+//	from - The address of the query initiator.
+//
+// Returns:
+//
+//	An array of dispute records.
+//	An error wrapping ErrUnsupportedByContract if the contract doesn't keep dispute records.
+//
+// Example:
+//
+//	disputes, err := QueryDisputes(transition, 50000, fromAddress)
+//	if err != nil {
+//	  log.Fatalf("failed to query disputes: %s", err)
+//	}
+func QueryDisputes(t *state.Transition, gasLimit uint64, from types.Address) ([]DisputeRecord, error) {
+	method, ok := stakingMethod("GetDisputes")
+	if !ok {
+		return nil, fmt.Errorf("%w: GetDisputes", ErrUnsupportedByContract)
+	}
+
+	res, err := applyQuery(t, &types.Transaction{
+		From:     from,
+		To:       &AddrStakingContract,
+		Value:    big.NewInt(0),
+		Input:    method.ID(),
+		GasPrice: big.NewInt(0),
+		Gas:      gasLimit,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Failed() {
+		return nil, queryFailure(method.Name, res)
+	}
+
+	return DecodeDisputeRecords(method, res.ReturnValue)
+}
+
+// QueryDisputeForBlock calls the GetDisputeForBlock method on the Staking contract.
+//
+// It sends a transaction to query the contract and returns the result.
+//
+// Parameters:
+//
+//	t - The state transition object.
+//	gasLimit - The gas limit for the transaction.
+//	from - The address of the query initiator.
+//	blockHash - The hash of the disputed block.
+//
+// Returns:
+//
+//	The dispute record and true if a dispute was raised for the block.
+//	An error wrapping ErrUnsupportedByContract if the contract doesn't keep dispute records.
+//
+// Example:
+//
+//	dispute, exists, err := QueryDisputeForBlock(transition, 50000, fromAddress, blockHash)
+//	if err != nil {
+//	  log.Fatalf("failed to query dispute: %s", err)
+//	}
+func QueryDisputeForBlock(t *state.Transition, gasLimit uint64, from types.Address, blockHash types.Hash) (*DisputeRecord, bool, error) {
+	method, ok := stakingMethod("GetDisputeForBlock")
+	if !ok {
+		return nil, false, fmt.Errorf("%w: GetDisputeForBlock", ErrUnsupportedByContract)
+	}
+
+	encodedInput, encodeErr := method.Inputs.Encode(
+		map[string]interface{}{
+			"blockHash": ethgo.Hash(blockHash),
+		},
+	)
+	if encodeErr != nil {
+		return nil, false, encodeErr
+	}
+
+	res, err := applyQuery(t, &types.Transaction{
+		From:     from,
+		To:       &AddrStakingContract,
+		Value:    big.NewInt(0),
+		Input:    append(method.ID(), encodedInput...),
+		GasPrice: big.NewInt(0),
+		Gas:      gasLimit,
+	})
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if res.Failed() {
+		return nil, false, queryFailure(method.Name, res)
+	}
+
+	record, err := DecodeDisputeRecord(method, res.ReturnValue)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The contract returns an empty record for blocks that were never disputed.
+	if record.BlockHash == types.ZeroHash {
+		return nil, false, nil
+	}
+
+	return record, true, nil
+}
+
+// DecodeDisputeRecords decodes a list of dispute structs returned by the staking contract.
+// It takes a method object and the returned value as parameters.
+// It returns a slice of dispute records and an error if the operation fails.
+func DecodeDisputeRecords(method *abi.Method, returnValue []byte) ([]DisputeRecord, error) {
+	decodedResults, err := method.Outputs.Decode(returnValue)
+	if err != nil {
+		return nil, err
+	}
+
+	results, ok := decodedResults.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("failed type assertion from decodedResults to map")
+	}
+
+	structs, ok := results["0"].([]map[string]interface{})
+	if !ok {
+		return nil, errors.New("failed type assertion from results[0] to []map[string]interface{}")
+	}
+
+	records := make([]DisputeRecord, len(structs))
+	for idx, s := range structs {
+		record, err := decodeDisputeStruct(s)
+		if err != nil {
+			return nil, err
+		}
+
+		records[idx] = *record
+	}
+
+	return records, nil
+}
+
+// DecodeDisputeRecord decodes a single dispute struct returned by the staking contract.
+// It takes a method object and the returned value as parameters.
+// It returns the dispute record and an error if the operation fails.
+func DecodeDisputeRecord(method *abi.Method, returnValue []byte) (*DisputeRecord, error) {
+	decodedResults, err := method.Outputs.Decode(returnValue)
+	if err != nil {
+		return nil, err
+	}
+
+	results, ok := decodedResults.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("failed type assertion from decodedResults to map")
+	}
+
+	s, ok := results["0"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("failed type assertion from results[0] to map")
+	}
+
+	return decodeDisputeStruct(s)
+}
+
+// decodeDisputeStruct converts a decoded dispute struct into a DisputeRecord.
+func decodeDisputeStruct(s map[string]interface{}) (*DisputeRecord, error) {
+	accuser, ok := s["accuser"].(ethgo.Address)
+	if !ok {
+		return nil, errors.New("failed type assertion from accuser to ethgo.Address")
+	}
+
+	accused, ok := s["accused"].(ethgo.Address)
+	if !ok {
+		return nil, errors.New("failed type assertion from accused to ethgo.Address")
+	}
+
+	blockHash, ok := s["blockHash"].([32]byte)
+	if !ok {
+		return nil, errors.New("failed type assertion from blockHash to [32]byte")
+	}
+
+	status, ok := s["status"].(uint8)
+	if !ok {
+		return nil, errors.New("failed type assertion from status to uint8")
+	}
+
+	return &DisputeRecord{
+		Accuser:   types.Address(accuser),
+		Accused:   types.Address(accused),
+		BlockHash: types.Hash(blockHash),
+		Status:    DisputeStatus(status),
+	}, nil
+}
@@ -0,0 +1,72 @@
+package staking
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+const disputeRecordsABI = `[
+	{"name":"GetDisputes","type":"function","inputs":[],"outputs":[{"name":"","type":"tuple[]","components":[
+		{"name":"accuser","type":"address"},{"name":"accused","type":"address"},{"name":"blockHash","type":"bytes32"},{"name":"status","type":"uint8"}]}]},
+	{"name":"GetDisputeForBlock","type":"function","inputs":[{"name":"blockHash","type":"bytes32"}],"outputs":[{"name":"","type":"tuple","components":[
+		{"name":"accuser","type":"address"},{"name":"accused","type":"address"},{"name":"blockHash","type":"bytes32"},{"name":"status","type":"uint8"}]}]}
+]`
+
+func TestDecodeDisputeRecords(t *testing.T) {
+	tAssert := assert.New(t)
+
+	disputesABI := abi.MustNewABI(disputeRecordsABI)
+
+	record := DisputeRecord{
+		Accuser:   types.StringToAddress("0x1"),
+		Accused:   types.StringToAddress("0x2"),
+		BlockHash: types.StringToHash("0x3"),
+		Status:    DisputeStatusResolved,
+	}
+
+	encodedStruct := map[string]interface{}{
+		"accuser":   ethgo.Address(record.Accuser),
+		"accused":   ethgo.Address(record.Accused),
+		"blockHash": [32]byte(record.BlockHash),
+		"status":    uint8(record.Status),
+	}
+
+	method := disputesABI.Methods["GetDisputes"]
+	encoded, err := method.Outputs.Encode([]interface{}{[]map[string]interface{}{encodedStruct}})
+	tAssert.NoError(err)
+
+	records, err := DecodeDisputeRecords(method, encoded)
+	tAssert.NoError(err)
+	tAssert.Equal([]DisputeRecord{record}, records)
+
+	method = disputesABI.Methods["GetDisputeForBlock"]
+	encoded, err = method.Outputs.Encode([]interface{}{encodedStruct})
+	tAssert.NoError(err)
+
+	decoded, err := DecodeDisputeRecord(method, encoded)
+	tAssert.NoError(err)
+	tAssert.Equal(record, *decoded)
+}
+
+func TestDisputeQuerierUnsupportedByContract(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	dq := NewDisputeQuerier(blockchain, executor, hclog.Default())
+
+	_, err = dq.Disputes()
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract))
+
+	_, exists, err := dq.DisputeForBlock(blockchain.Header().Hash)
+	tAssert.False(exists)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract))
+}
@@ -17,7 +17,6 @@ import (
 	eth_abi "github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/hashicorp/go-hclog"
 	"github.com/umbracle/ethgo"
-	"github.com/umbracle/ethgo/abi"
 )
 
 // DisputeResolution defines the methods required for interacting
@@ -422,7 +421,7 @@ func (dr *disputeResolution) End(probationAddr types.Address, signKey *ecdsa.Pri
 //	  log.Fatalf("failed to create dispute resolution transaction: %s", err)
 //	}
 func BeginDisputeResolutionTx(from types.Address, probationAddr types.Address, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["BeginDisputeResolution"]
+	method, ok := stakingMethod("BeginDisputeResolution")
 	if !ok {
 		panic("BeginDisputeResolution method doesn't exist in Staking contract ABI. Contract is broken.")
 	}
@@ -431,7 +430,7 @@ func BeginDisputeResolutionTx(from types.Address, probationAddr types.Address, g
 
 	encodedInput, encodeErr := method.Inputs.Encode(
 		map[string]interface{}{
-			"sequencerAddr": probationAddr.Bytes(),
+			"sequencerAddr": ethgo.Address(probationAddr),
 		},
 	)
 	if encodeErr != nil {
@@ -509,7 +508,7 @@ func IsBeginDisputeResolutionTx(tx *types.Transaction) (bool, error) {
 //	  log.Fatalf("failed to create dispute resolution conclusion transaction: %s", err)
 //	}
 func EndDisputeResolutionTx(from types.Address, probationAddr types.Address, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["EndDisputeResolution"]
+	method, ok := stakingMethod("EndDisputeResolution")
 	if !ok {
 		panic("EndDisputeResolution method doesn't exist in Staking contract ABI. Contract is broken.")
 	}
@@ -518,7 +517,7 @@ func EndDisputeResolutionTx(from types.Address, probationAddr types.Address, gas
 
 	encodedInput, encodeErr := method.Inputs.Encode(
 		map[string]interface{}{
-			"sequencerAddr": probationAddr.Bytes(),
+			"sequencerAddr": ethgo.Address(probationAddr),
 		},
 	)
 	if encodeErr != nil {
@@ -558,14 +557,14 @@ func EndDisputeResolutionTx(from types.Address, probationAddr types.Address, gas
 //	  log.Fatalf("failed to query disputed sequencer address: %s", err)
 //	}
 func QueryDisputedSequencerAddr(t *state.Transition, gasLimit uint64, from types.Address, watchtowerAddr types.Address) (types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetDisputedSequencerAddrs"]
+	method, ok := stakingMethod("GetDisputedSequencerAddrs")
 	if !ok {
 		return types.Address{}, errors.New("GetDisputedSequencerAddrs method doesn't exist in Staking contract ABI")
 	}
 
 	encodedInput, encodeErr := method.Inputs.Encode(
 		map[string]interface{}{
-			"watchtowerAddr": watchtowerAddr.Bytes(),
+			"watchtowerAddr": ethgo.Address(watchtowerAddr),
 		},
 	)
 	if encodeErr != nil {
@@ -573,14 +572,13 @@ func QueryDisputedSequencerAddr(t *state.Transition, gasLimit uint64, from types
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    append(selector, encodedInput...),
 		GasPrice: big.NewInt(5000),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -588,7 +586,7 @@ func QueryDisputedSequencerAddr(t *state.Transition, gasLimit uint64, from types
 	}
 
 	if res.Failed() {
-		return types.Address{}, res.Err
+		return types.Address{}, queryFailure(method.Name, res)
 	}
 
 	decodedResults, err := method.Outputs.Decode(res.ReturnValue)
@@ -628,14 +626,14 @@ func QueryDisputedSequencerAddr(t *state.Transition, gasLimit uint64, from types
 //	  log.Fatalf("failed to query disputed watchtower address: %s", err)
 //	}
 func QueryDisputedWatchtowerAddr(t *state.Transition, gasLimit uint64, from types.Address, sequencerAddr types.Address) (types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetDisputedWatchtowerAddr"]
+	method, ok := stakingMethod("GetDisputedWatchtowerAddr")
 	if !ok {
 		return types.Address{}, errors.New("GetDisputedWatchtowerAddr method doesn't exist in Staking contract ABI")
 	}
 
 	encodedInput, encodeErr := method.Inputs.Encode(
 		map[string]interface{}{
-			"sequencerAddr": sequencerAddr.Bytes(),
+			"sequencerAddr": ethgo.Address(sequencerAddr),
 		},
 	)
 	if encodeErr != nil {
@@ -643,14 +641,13 @@ func QueryDisputedWatchtowerAddr(t *state.Transition, gasLimit uint64, from type
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    append(selector, encodedInput...),
 		GasPrice: big.NewInt(5000),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -658,7 +655,7 @@ func QueryDisputedWatchtowerAddr(t *state.Transition, gasLimit uint64, from type
 	}
 
 	if res.Failed() {
-		return types.Address{}, res.Err
+		return types.Address{}, queryFailure(method.Name, res)
 	}
 
 	decodedResults, err := method.Outputs.Decode(res.ReturnValue)
@@ -697,20 +694,19 @@ func QueryDisputedWatchtowerAddr(t *state.Transition, gasLimit uint64, from type
 //	  log.Fatalf("failed to query disputed watchtower addresses: %s", err)
 //	}
 func QueryDisputedWatchtowers(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentDisputeWatchtowers"]
+	method, ok := stakingMethod("GetCurrentDisputeWatchtowers")
 	if !ok {
 		return nil, errors.New("GetCurrentDisputeWatchtowers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -718,7 +714,7 @@ func QueryDisputedWatchtowers(t *state.Transition, gasLimit uint64, from types.A
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return DecodeParticipants(method, res.ReturnValue)
@@ -0,0 +1,121 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// StakingStateDump is the staking state relevant for debugging a node, meant to be attached to support requests.
+// It only contains public chain data, never keys or any other secret of the node.
+type StakingStateDump struct {
+	// BlockNumber and BlockHash identify the head block the participants were read at.
+	BlockNumber uint64     `json:"block_number"`
+	BlockHash   types.Hash `json:"block_hash"`
+
+	// Sequencers are the active sequencers, i.e. the ones not in probation.
+	Sequencers []types.Address `json:"sequencers"`
+	// SequencersInProbation are the sequencers currently in probation.
+	SequencersInProbation []types.Address `json:"sequencers_in_probation"`
+	// WatchTowers are the active watchtowers.
+	WatchTowers []types.Address `json:"watchtowers"`
+
+	// TotalStaked is the total staked amount in wei.
+	TotalStaked *big.Int `json:"total_staked"`
+
+	// Self is the staking state of the node itself.
+	Self NodeStakingState `json:"self"`
+}
+
+// ProbationUnsupported is the ProbationRemainingStatus of a node in probation whose remaining probation can't be told.
+const ProbationUnsupported = "unsupported"
+
+// NodeStakingState is the staking state of a single node.
+type NodeStakingState struct {
+	// Address is the miner address of the node.
+	Address types.Address `json:"address"`
+	// Participant is true if the address has ever staked in the staking contract.
+	Participant bool `json:"participant"`
+	// Sequencer is true if the node is an active sequencer.
+	Sequencer bool `json:"sequencer"`
+	// InProbation is true if the node is a sequencer in probation.
+	InProbation bool `json:"in_probation"`
+	// WatchTower is true if the node is an active watchtower.
+	WatchTower bool `json:"watchtower"`
+	// ProbationRemaining is the number of blocks until the node is eligible again, nil if the node isn't in probation
+	// or the querier can't tell how long the probation lasts, see ProbationRemainingStatus.
+	ProbationRemaining *uint64 `json:"probation_remaining,omitempty"`
+	// ProbationRemainingStatus tells why ProbationRemaining isn't set for a node in probation: ProbationUnsupported
+	// if the querier or the staking contract can't tell how long the probation lasts, as with the deployed contract,
+	// which has no probation duration, or the error of the query otherwise.
+	ProbationRemainingStatus string `json:"probation_remaining_status,omitempty"`
+	// Balance is the staked amount of the node in wei, nil if the node isn't a participant.
+	Balance *big.Int `json:"balance"`
+}
+
+// DumpStakingState gathers the staking state of the network and of the node with the given address.
+// The participant sets and the membership of the node come from a single Snapshot of the active participants,
+// so they are consistent with each other.
+// It returns the dump and an error if the operation fails.
+func DumpStakingState(ap ActiveParticipants, self types.Address) (*StakingStateDump, error) {
+	snapshot, err := ap.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := ap.GetBalance(self)
+	if err != nil && !errors.Is(err, ErrNotAParticipant) {
+		return nil, err
+	}
+
+	participant := err == nil
+
+	totalStaked, err := ap.GetTotalStakedAmount()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeState := NodeStakingState{
+		Address:     self,
+		Participant: participant,
+		Sequencer:   containsAddress(snapshot.Sequencers, self),
+		InProbation: containsAddress(snapshot.SequencersInProbation, self),
+		WatchTower:  containsAddress(snapshot.WatchTowers, self),
+		Balance:     balance,
+	}
+
+	// The remaining probation is best effort, the deployed contract doesn't have a probation duration.
+	if nodeState.InProbation {
+		nodeState.ProbationRemaining, nodeState.ProbationRemainingStatus = probationRemainingState(ap, self)
+	}
+
+	return &StakingStateDump{
+		BlockNumber:           snapshot.BlockNumber,
+		BlockHash:             snapshot.BlockHash,
+		Sequencers:            snapshot.Sequencers,
+		SequencersInProbation: snapshot.SequencersInProbation,
+		WatchTowers:           snapshot.WatchTowers,
+		TotalStaked:           totalStaked,
+		Self:                  nodeState,
+	}, nil
+}
+
+// probationRemainingState returns the remaining probation of the node in probation, or the status telling why it
+// can't be told.
+func probationRemainingState(ap ActiveParticipants, self types.Address) (*uint64, string) {
+	reporter, ok := ap.(ProbationReporter)
+	if !ok {
+		return nil, ProbationUnsupported
+	}
+
+	remaining, err := reporter.ProbationRemaining(self)
+	switch {
+	case errors.Is(err, ErrUnsupportedByContract):
+		return nil, ProbationUnsupported
+	case err != nil:
+		return nil, err.Error()
+	}
+
+	return &remaining, ""
+}
@@ -0,0 +1,52 @@
+package staking
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestDumpStakingState(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	dump, err := DumpStakingState(querier, sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(blockchain.Header().Number, dump.BlockNumber)
+	tAssert.Equal(blockchain.Header().Hash, dump.BlockHash)
+	tAssert.Equal([]types.Address{sequencerAddr}, dump.Sequencers)
+	tAssert.Empty(dump.SequencersInProbation)
+	tAssert.Equal(0, stakeAmount.Cmp(dump.TotalStaked))
+	tAssert.Equal(NodeStakingState{Address: sequencerAddr, Participant: true, Sequencer: true, Balance: stakeAmount}, dump.Self)
+
+	raw, err := json.Marshal(dump)
+	tAssert.NoError(err)
+
+	var decoded map[string]interface{}
+	tAssert.NoError(json.Unmarshal(raw, &decoded))
+	tAssert.Equal(sequencerAddr.String(), decoded["self"].(map[string]interface{})["address"])
+
+	// A node that never staked.
+	unknownAddr, _ := test.NewAccount(t)
+
+	dump, err = DumpStakingState(querier, unknownAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(NodeStakingState{Address: unknownAddr}, dump.Self)
+}
@@ -0,0 +1,101 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/umbracle/ethgo"
+)
+
+// StakingAction is the action recorded by a staking contract event.
+type StakingAction string
+
+const (
+	// ActionStaked is recorded by the `Staked` event.
+	ActionStaked StakingAction = "staked"
+	// ActionUnstaked is recorded by the `Unstaked` event.
+	ActionUnstaked StakingAction = "unstaked"
+	// ActionSlashed is recorded by the `Slashed` event.
+	ActionSlashed StakingAction = "slashed"
+	// ActionDisputeResolutionBegan is recorded by the `DisputeResolutionBegan` event.
+	ActionDisputeResolutionBegan StakingAction = "dispute_resolution_began"
+	// ActionDisputeResolutionEnded is recorded by the `DisputeResolutionEnded` event.
+	ActionDisputeResolutionEnded StakingAction = "dispute_resolution_ended"
+)
+
+// stakingEventActions maps the staking contract event names to the actions they record.
+var stakingEventActions = map[string]StakingAction{
+	"Staked":                 ActionStaked,
+	"Unstaked":               ActionUnstaked,
+	"Slashed":                ActionSlashed,
+	"DisputeResolutionBegan": ActionDisputeResolutionBegan,
+	"DisputeResolutionEnded": ActionDisputeResolutionEnded,
+}
+
+// StakingEvent is a decoded staking contract event.
+type StakingEvent struct {
+	// Action is the action recorded by the event.
+	Action StakingAction
+	// Account is the participant the event refers to.
+	Account types.Address
+	// Amount is the staked, unstaked or slashed amount. It's nil for dispute resolution events.
+	Amount *big.Int
+}
+
+// IsStakingLog checks whether the given log was emitted by the staking contract.
+func IsStakingLog(log *types.Log) bool {
+	return log.Address == AddrStakingContract && len(log.Topics) > 0
+}
+
+// DecodeStakingEvent decodes a log emitted by the staking contract.
+// It returns nil values if the log isn't a known staking contract event.
+// It returns an error if the log matches a staking contract event but can't be decoded.
+func DecodeStakingEvent(log *types.Log) (*StakingEvent, error) {
+	if !IsStakingLog(log) {
+		return nil, nil
+	}
+
+	for name, action := range stakingEventActions {
+		event, ok := stakingEvent(name)
+		if !ok || types.Hash(event.ID()) != log.Topics[0] {
+			continue
+		}
+
+		topics := make([]ethgo.Hash, len(log.Topics))
+		for i, topic := range log.Topics {
+			topics[i] = ethgo.Hash(topic)
+		}
+
+		values, err := event.ParseLog(&ethgo.Log{
+			Address: ethgo.Address(log.Address),
+			Topics:  topics,
+			Data:    log.Data,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s event: %w", name, err)
+		}
+
+		account, ok := values["account"].(ethgo.Address)
+		if !ok {
+			return nil, errors.New("failed type assertion from account to ethgo.Address")
+		}
+
+		stakingEvent := &StakingEvent{
+			Action:  action,
+			Account: types.Address(account),
+		}
+
+		switch action {
+		case ActionStaked, ActionUnstaked:
+			stakingEvent.Amount, _ = values["amount"].(*big.Int)
+		case ActionSlashed:
+			stakingEvent.Amount, _ = values["slashedAmount"].(*big.Int)
+		}
+
+		return stakingEvent, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,203 @@
+package staking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// SnapshotExportVersion is the version of the schema of the staking snapshot exported by ExportSnapshotJSON.
+// It's bumped on every change of the schema that isn't backwards compatible.
+const SnapshotExportVersion = 1
+
+// SnapshotExporter is implemented by the queriers that export the staking state at a given block.
+type SnapshotExporter interface {
+	// ExportSnapshotJSON writes the staking snapshot at the given block as JSON.
+	ExportSnapshotJSON(w io.Writer, blockNumber uint64) error
+}
+
+// SnapshotExport is the staking state at a block, as exported by ExportSnapshotJSON.
+// Addresses are checksummed hex strings, amounts are decimal strings of wei, and the keys and the participants are
+// sorted, the participants by address, so two nodes exporting the same block produce byte-identical output.
+// The fields of the export types are declared in the order of their keys, which encoding/json keeps.
+type SnapshotExport struct {
+	// BlockHash, BlockNumber and StateRoot identify the block the staking state was read at.
+	BlockHash   string `json:"block_hash"`
+	BlockNumber uint64 `json:"block_number"`
+	// Governance are the governance parameters, a parameter the contract doesn't have is null.
+	Governance ExportedGovernance `json:"governance"`
+	// Sequencers are all the registered sequencers, including the ones in probation.
+	Sequencers []ExportedParticipant `json:"sequencers"`
+	StateRoot  string                `json:"state_root"`
+	// Totals are the staked amounts.
+	Totals ExportedTotals `json:"totals"`
+	// Version is the version of the schema, see SnapshotExportVersion.
+	Version int `json:"version"`
+	// WatchTowers are all the registered watchtowers.
+	WatchTowers []ExportedParticipant `json:"watchtowers"`
+}
+
+// ExportedParticipant is a staking participant in the SnapshotExport.
+type ExportedParticipant struct {
+	// Address is the checksummed address of the participant.
+	Address string `json:"address"`
+	// Balance is the staked amount of the participant.
+	Balance string `json:"balance"`
+	// InProbation is true if the participant is a sequencer in probation.
+	InProbation bool `json:"in_probation"`
+}
+
+// ExportedTotals are the staked amounts in the SnapshotExport.
+type ExportedTotals struct {
+	// Sequencers is the sum of the balances of the sequencers.
+	Sequencers string `json:"sequencers"`
+	// Staked is the total staked amount reported by the staking contract.
+	Staked string `json:"staked"`
+	// WatchTowers is the sum of the balances of the watchtowers.
+	WatchTowers string `json:"watchtowers"`
+}
+
+// ExportedGovernance are the governance parameters in the SnapshotExport.
+type ExportedGovernance struct {
+	EpochLength       *string `json:"epoch_length"`
+	ProbationDuration *string `json:"probation_duration"`
+	SlashPercentage   *string `json:"slash_percentage"`
+	WithdrawalDelay   *string `json:"withdrawal_delay"`
+}
+
+// ExportSnapshotJSON method writes the staking snapshot at the given block as indented JSON, see SnapshotExport for the
+// schema. The whole snapshot is queried on top of the state root of the block, so it's consistent.
+// It returns an error if the block or its state isn't available, or if the operation fails.
+func (asq *activeParticipantsQuerier) ExportSnapshotJSON(w io.Writer, blockNumber uint64) error {
+	if asq.blockchain == nil {
+		return errors.New("snapshot export requires the blockchain, the querier is bound to a state root")
+	}
+
+	header, ok := asq.blockchain.GetHeaderByNumber(blockNumber)
+	if !ok {
+		return fmt.Errorf("header for block %d not found", blockNumber)
+	}
+
+	atBlock := NewParticipantsQuerierAtRoot(asq.executor, header.StateRoot, header, asq.logger, WithContractRegistry(asq.registry))
+
+	export, err := buildSnapshotExport(atBlock, header)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(raw, '\n'))
+
+	return err
+}
+
+// buildSnapshotExport reads the staking state with the querier bound to the state root of the header.
+func buildSnapshotExport(ap ActiveParticipants, header *types.Header) (*SnapshotExport, error) {
+	sequencers, err := ap.GetRegistered(Sequencer)
+	if err != nil {
+		return nil, err
+	}
+
+	watchtowers, err := ap.GetRegistered(WatchTower)
+	if err != nil {
+		return nil, err
+	}
+
+	probation, err := ap.InProbationBatch(sequencers)
+	if err != nil {
+		return nil, err
+	}
+
+	exportedSequencers, sequencersTotal, err := exportParticipants(ap, sequencers, probation)
+	if err != nil {
+		return nil, err
+	}
+
+	exportedWatchtowers, watchtowersTotal, err := exportParticipants(ap, watchtowers, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	totalStaked, err := ap.GetTotalStakedAmount()
+	if err != nil {
+		return nil, err
+	}
+
+	export := &SnapshotExport{
+		Version:     SnapshotExportVersion,
+		BlockNumber: header.Number,
+		BlockHash:   header.Hash.String(),
+		StateRoot:   header.StateRoot.String(),
+		Sequencers:  exportedSequencers,
+		WatchTowers: exportedWatchtowers,
+		Totals: ExportedTotals{
+			Staked:      totalStaked.String(),
+			Sequencers:  sequencersTotal.String(),
+			WatchTowers: watchtowersTotal.String(),
+		},
+	}
+
+	if reader, ok := ap.(GovernanceReader); ok {
+		params, err := reader.GetGovernanceParams()
+		if err != nil {
+			return nil, err
+		}
+
+		export.Governance = ExportedGovernance{
+			EpochLength:       exportedAmount(params.EpochLength),
+			ProbationDuration: exportedAmount(params.ProbationDuration),
+			SlashPercentage:   exportedAmount(params.SlashPercentage),
+			WithdrawalDelay:   exportedAmount(params.WithdrawalDelay),
+		}
+	}
+
+	return export, nil
+}
+
+// exportParticipants returns the participants sorted by address with their balances, and the sum of the balances.
+func exportParticipants(ap ActiveParticipants, addrs []types.Address, probation map[types.Address]bool) ([]ExportedParticipant, *big.Int, error) {
+	sorted := make([]types.Address, len(addrs))
+	copy(sorted, addrs)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0 })
+
+	toReturn := make([]ExportedParticipant, 0, len(sorted))
+	total := big.NewInt(0)
+
+	for _, addr := range sorted {
+		balance, err := ap.GetBalance(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		total.Add(total, balance)
+
+		toReturn = append(toReturn, ExportedParticipant{
+			Address:     addr.String(),
+			Balance:     balance.String(),
+			InProbation: probation[addr],
+		})
+	}
+
+	return toReturn, total, nil
+}
+
+// exportedAmount returns the decimal string of the amount, nil if the amount is nil.
+func exportedAmount(amount *big.Int) *string {
+	if amount == nil {
+		return nil
+	}
+
+	s := amount.String()
+
+	return &s
+}
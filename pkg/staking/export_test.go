@@ -0,0 +1,121 @@
+package staking
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestExportSnapshotJSON(t *testing.T) {
+	tAssert := assert.New(t)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	bigStakeAmount := big.NewInt(0).Mul(big.NewInt(25), commontoken.ETH)
+
+	// The sequencers are registered out of order, the export sorts them.
+	sequencer1 := types.StringToAddress("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+	sequencer2 := types.StringToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	watchtower := types.StringToAddress("0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb")
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	alloc, err := GenesisStakingAlloc(GenesisStakingParams{
+		Code:               chain.Genesis.Alloc[AddrStakingContract].Code,
+		Sequencers:         []GenesisStake{{sequencer1, stakeAmount}, {sequencer2, bigStakeAmount}},
+		WatchTowers:        []GenesisStake{{watchtower, stakeAmount}},
+		MinNumParticipants: 1,
+		MaxNumParticipants: 10,
+	})
+	tAssert.NoError(err)
+
+	for addr, account := range alloc {
+		chain.Genesis.Alloc[addr] = account
+	}
+
+	// The faucet account is generated on every run, it's dropped to keep the genesis state root stable.
+	delete(chain.Genesis.Alloc, test.FaucetAccount)
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(SnapshotExporter)
+
+	var out bytes.Buffer
+	tAssert.NoError(querier.ExportSnapshotJSON(&out, 0))
+
+	golden := filepath.Join("testdata", "snapshot_export.golden.json")
+	if *updateGolden {
+		tAssert.NoError(os.WriteFile(golden, out.Bytes(), 0o600))
+	}
+
+	expected, err := os.ReadFile(golden)
+	tAssert.NoError(err)
+	tAssert.Equal(string(expected), out.String())
+	tAssert.NoError(checkSortedKeys(json.NewDecoder(bytes.NewReader(out.Bytes()))))
+
+	// Exporting the same block again is byte-identical.
+	var again bytes.Buffer
+	tAssert.NoError(querier.ExportSnapshotJSON(&again, 0))
+	tAssert.Equal(out.Bytes(), again.Bytes())
+
+	tAssert.Error(querier.ExportSnapshotJSON(&again, blockchain.Header().Number+1))
+}
+
+// checkSortedKeys reads the next JSON value from the decoder, and returns an error if the keys of one of its objects
+// aren't sorted.
+func checkSortedKeys(dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		previous := ""
+
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			if key.(string) < previous {
+				return fmt.Errorf("key %q after %q", key, previous)
+			}
+
+			previous = key.(string)
+
+			if err := checkSortedKeys(dec); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for dec.More() {
+			if err := checkSortedKeys(dec); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// The closing delimiter of the object or the array.
+	_, err = dec.Token()
+
+	return err
+}
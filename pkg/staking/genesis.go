@@ -0,0 +1,166 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/chain"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// GenesisStake is a participant that is already staked in the genesis state.
+type GenesisStake struct {
+	// Address is the address of the participant.
+	Address types.Address
+	// Amount is the staked amount in wei.
+	Amount *big.Int
+}
+
+// GenesisStakingParams are the parameters of the staking contract account in the genesis state.
+type GenesisStakingParams struct {
+	// Address is the address the staking contract is deployed at. Defaults to AddrStakingContract.
+	Address types.Address
+	// Code is the deployed bytecode of the staking contract.
+	Code []byte
+	// Balance is the balance of the contract account on top of the staked amounts.
+	Balance *big.Int
+
+	// Sequencers are the bootstrap sequencers.
+	Sequencers []GenesisStake
+	// WatchTowers are the bootstrap watchtowers.
+	WatchTowers []GenesisStake
+
+	// MinStakingThreshold is the minimum stake of a participant. The contract default is used when nil.
+	MinStakingThreshold *big.Int
+	// SlashPercentage is the percentage of the stake slashed for a fraud. The contract default is used when nil.
+	SlashPercentage *big.Int
+
+	// MinNumParticipants and MaxNumParticipants bound the number of participants.
+	MinNumParticipants uint64
+	MaxNumParticipants uint64
+}
+
+var (
+	// ErrMissingStakingCode is returned when the genesis staking parameters have no contract bytecode.
+	ErrMissingStakingCode = errors.New("missing staking contract bytecode")
+
+	// ErrInvalidGenesisStake is returned when a genesis stake is invalid.
+	ErrInvalidGenesisStake = errors.New("invalid genesis stake")
+)
+
+// GenesisStakingAlloc builds the staking contract account for the genesis alloc of a new network.
+// The storage of the account is pre-computed so the given sequencers and watchtowers are already
+// staked, with their stake held by the contract, and the thresholds are set.
+// It returns the alloc entries to merge into the chain's genesis alloc and an error if the parameters are invalid.
+func GenesisStakingAlloc(params GenesisStakingParams) (map[types.Address]*chain.GenesisAccount, error) {
+	if len(params.Code) == 0 {
+		return nil, ErrMissingStakingCode
+	}
+
+	contractAddr := params.Address
+	if contractAddr == types.ZeroAddress {
+		contractAddr = AddrStakingContract
+	}
+
+	storage := map[types.Hash]types.Hash{}
+
+	// The node types are copied from the genesis of the existing networks.
+	nodeTypes := []NodeType{Sequencer, WatchTower}
+
+	storage[slotKey(availableNodeTypesSlot)] = types.BytesToHash(big.NewInt(int64(len(nodeTypes))).Bytes())
+	for i, nodeType := range nodeTypes {
+		storage[arrayElementKey(availableNodeTypesSlot, i)] = encodeShortString(string(nodeType))
+	}
+
+	setUint := func(key types.Hash, value *big.Int) {
+		if value != nil && value.Sign() != 0 {
+			storage[key] = types.BytesToHash(value.Bytes())
+		}
+	}
+
+	threshold := params.MinStakingThreshold
+	setUint(slotKey(minStakingThresholdSlot), threshold)
+	setUint(slotKey(slashPercentageSlot), params.SlashPercentage)
+	setUint(slotKey(minNumParticipantsSlot), new(big.Int).SetUint64(params.MinNumParticipants))
+	setUint(slotKey(maxNumParticipantsSlot), new(big.Int).SetUint64(params.MaxNumParticipants))
+
+	groups := []struct {
+		nodeType  NodeType
+		stakes    []GenesisStake
+		arraySlot int64
+		isSlot    int64
+		indexSlot int64
+	}{
+		{Sequencer, params.Sequencers, sequencersSlot, addressToIsSequencerSlot, addressToSequencerIndexSlot},
+		{WatchTower, params.WatchTowers, watchtowersSlot, addressToIsWatchtowerSlot, addressToWatchtowerIndexSlot},
+	}
+
+	seen := map[types.Address]struct{}{}
+	totalStaked := new(big.Int)
+	participants := 0
+
+	for _, group := range groups {
+		for i, stake := range group.stakes {
+			if stake.Address == types.ZeroAddress {
+				return nil, fmt.Errorf("%w: %s %d has the zero address", ErrInvalidGenesisStake, group.nodeType, i)
+			}
+
+			if _, ok := seen[stake.Address]; ok {
+				return nil, fmt.Errorf("%w: %s is staked more than once", ErrInvalidGenesisStake, stake.Address)
+			}
+
+			if stake.Amount == nil || stake.Amount.Sign() <= 0 {
+				return nil, fmt.Errorf("%w: %s has no stake", ErrInvalidGenesisStake, stake.Address)
+			}
+
+			if threshold != nil && stake.Amount.Cmp(threshold) < 0 {
+				return nil, fmt.Errorf("%w: stake of %s is below the threshold %s", ErrInvalidGenesisStake, stake.Address, threshold)
+			}
+
+			seen[stake.Address] = struct{}{}
+
+			storage[arrayElementKey(participantsSlot, participants)] = types.BytesToHash(stake.Address.Bytes())
+			storage[mappingKey(addressToIsParticipantSlot, stake.Address)] = types.BytesToHash([]byte{1})
+			storage[mappingKey(addressToStakedAmountSlot, stake.Address)] = types.BytesToHash(stake.Amount.Bytes())
+			setUint(mappingKey(addressToParticipantIndexSlot, stake.Address), big.NewInt(int64(participants)))
+
+			storage[arrayElementKey(group.arraySlot, i)] = types.BytesToHash(stake.Address.Bytes())
+			storage[mappingKey(group.isSlot, stake.Address)] = types.BytesToHash([]byte{1})
+			setUint(mappingKey(group.indexSlot, stake.Address), big.NewInt(int64(i)))
+
+			totalStaked.Add(totalStaked, stake.Amount)
+			participants++
+		}
+
+		setUint(slotKey(group.arraySlot), big.NewInt(int64(len(group.stakes))))
+	}
+
+	setUint(slotKey(participantsSlot), big.NewInt(int64(participants)))
+	setUint(slotKey(stakedAmountSlot), totalStaked)
+
+	// The contract holds the staked funds.
+	balance := new(big.Int).Set(totalStaked)
+	if params.Balance != nil {
+		balance.Add(balance, params.Balance)
+	}
+
+	return map[types.Address]*chain.GenesisAccount{
+		contractAddr: {
+			Code:    params.Code,
+			Storage: storage,
+			Balance: balance,
+		},
+	}, nil
+}
+
+// encodeShortString encodes a string of less than 32 bytes the way solidity stores it in a single slot:
+// the data left aligned and twice the length in the lowest order byte.
+func encodeShortString(s string) types.Hash {
+	var h types.Hash
+
+	copy(h[:], s)
+	h[len(h)-1] = byte(len(s) * 2)
+
+	return h
+}
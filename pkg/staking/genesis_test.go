@@ -0,0 +1,106 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestGenesisStakingAlloc(t *testing.T) {
+	tAssert := assert.New(t)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+
+	sequencer1 := types.StringToAddress("0x1001")
+	sequencer2 := types.StringToAddress("0x1002")
+	watchtower := types.StringToAddress("0x2001")
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	alloc, err := GenesisStakingAlloc(GenesisStakingParams{
+		Code:               chain.Genesis.Alloc[AddrStakingContract].Code,
+		Sequencers:         []GenesisStake{{sequencer1, stakeAmount}, {sequencer2, stakeAmount}},
+		WatchTowers:        []GenesisStake{{watchtower, stakeAmount}},
+		MinNumParticipants: 1,
+		MaxNumParticipants: 10,
+	})
+	tAssert.NoError(err)
+
+	for addr, account := range alloc {
+		chain.Genesis.Alloc[addr] = account
+	}
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, sequencer1)
+	tAssert.NoError(err)
+
+	sequencers, err := QuerySequencers(transition, 1_000_000, sequencer1)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2}, sequencers)
+
+	watchtowers, err := QueryWatchtower(transition, 1_000_000, sequencer1)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{watchtower}, watchtowers)
+
+	participants, err := QueryParticipants(transition, 1_000_000, sequencer1)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2, watchtower}, participants)
+
+	tAssert.Equal(new(big.Int).Mul(stakeAmount, big.NewInt(3)), transition.GetBalance(AddrStakingContract))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	balance, err := querier.GetBalance(sequencer2)
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, balance)
+
+	total, err := querier.GetTotalStakedAmount()
+	tAssert.NoError(err)
+	tAssert.Equal(new(big.Int).Mul(stakeAmount, big.NewInt(3)), total)
+
+	// A sequencer staked after genesis is appended to the pre-computed arrays.
+	sequencer3, sequencer3SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer3, big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH), blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencer3, sequencer3SignKey, stakeAmount, 1_000_000, "test"))
+
+	activeSequencers, err := querier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2, sequencer3}, activeSequencers)
+
+	isSequencer, err := querier.Contains(sequencer2, Sequencer)
+	tAssert.NoError(err)
+	tAssert.True(isSequencer)
+}
+
+func TestGenesisStakingAllocValidation(t *testing.T) {
+	tAssert := assert.New(t)
+
+	code := []byte{0x60, 0x00}
+	stake := GenesisStake{types.StringToAddress("0x1001"), big.NewInt(10)}
+
+	_, err := GenesisStakingAlloc(GenesisStakingParams{Sequencers: []GenesisStake{stake}})
+	tAssert.True(errors.Is(err, ErrMissingStakingCode))
+
+	testCases := []GenesisStakingParams{
+		{Code: code, Sequencers: []GenesisStake{{types.ZeroAddress, big.NewInt(10)}}},
+		{Code: code, Sequencers: []GenesisStake{{stake.Address, nil}}},
+		{Code: code, Sequencers: []GenesisStake{stake}, WatchTowers: []GenesisStake{stake}},
+		{Code: code, Sequencers: []GenesisStake{stake}, MinStakingThreshold: big.NewInt(11)},
+	}
+
+	for _, params := range testCases {
+		_, err := GenesisStakingAlloc(params)
+		tAssert.True(errors.Is(err, ErrInvalidGenesisStake), "unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// GovernanceParams are the staking parameters set by governance in the staking contract.
+// Parameters the deployed contract doesn't have are nil.
+type GovernanceParams struct {
+	// EpochLength is the number of blocks of a staking epoch.
+	EpochLength *big.Int
+	// ProbationDuration is the number of blocks a sequencer stays in probation.
+	ProbationDuration *big.Int
+	// SlashPercentage is the percentage of the stake slashed for a fraud.
+	SlashPercentage *big.Int
+	// WithdrawalDelay is the number of blocks an unstaked amount is locked for before it can be withdrawn.
+	WithdrawalDelay *big.Int
+}
+
+// Copy returns a deep copy of the governance parameters.
+func (gp *GovernanceParams) Copy() *GovernanceParams {
+	copyAmount := func(amount *big.Int) *big.Int {
+		if amount == nil {
+			return nil
+		}
+
+		return new(big.Int).Set(amount)
+	}
+
+	return &GovernanceParams{
+		EpochLength:       copyAmount(gp.EpochLength),
+		ProbationDuration: copyAmount(gp.ProbationDuration),
+		SlashPercentage:   copyAmount(gp.SlashPercentage),
+		WithdrawalDelay:   copyAmount(gp.WithdrawalDelay),
+	}
+}
+
+// GovernanceReader is implemented by the queriers that read the governance parameters of the staking contract.
+type GovernanceReader interface {
+	// GetGovernanceParams returns the governance parameters at the chain head.
+	GetGovernanceParams() (*GovernanceParams, error)
+}
+
+// governanceGetters are the staking contract getters of the governance parameters.
+var governanceGetters = []struct {
+	methodName string
+	field      func(gp *GovernanceParams) **big.Int
+}{
+	{"GetEpochLength", func(gp *GovernanceParams) **big.Int { return &gp.EpochLength }},
+	{"GetProbationDuration", func(gp *GovernanceParams) **big.Int { return &gp.ProbationDuration }},
+	{"GetSlashPercentage", func(gp *GovernanceParams) **big.Int { return &gp.SlashPercentage }},
+	{"GetWithdrawalDelay", func(gp *GovernanceParams) **big.Int { return &gp.WithdrawalDelay }},
+}
+
+// governanceCache holds the governance parameters read on top of the last seen head.
+type governanceCache struct {
+	lock   sync.Mutex
+	hash   types.Hash
+	params *GovernanceParams
+}
+
+// QueryGovernanceParams queries the governance parameters from the staking contract.
+// It takes a transaction transition, gas limit, and the address of the sender as parameters.
+// Parameters without a getter in the contract are left nil.
+// It returns the governance parameters and an error if the operation fails.
+func QueryGovernanceParams(t *state.Transition, gasLimit uint64, from types.Address) (*GovernanceParams, error) {
+	return DefaultStakingContract().queryGovernanceParams(t, gasLimit, from)
+}
+
+// queryGovernanceParams calls the governance getters of the contract in the transition.
+func (sc *StakingContract) queryGovernanceParams(t *state.Transition, gasLimit uint64, from types.Address) (*GovernanceParams, error) {
+	params := &GovernanceParams{}
+
+	for _, getter := range governanceGetters {
+		value, err := sc.queryAmount(t, gasLimit, from, getter.methodName, nil)
+		if errors.Is(err, ErrUnsupportedByContract) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		*getter.field(params) = value
+	}
+
+	return params, nil
+}
+
+// GetGovernanceParams method returns the governance parameters of the staking contract at the chain head.
+// The parameters only change with the state, so they are queried again only once the head moved.
+// When the querier is fed the block events, they are carried over blocks without staking activity.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) GetGovernanceParams() (*GovernanceParams, error) {
+	parent := asq.head()
+
+	asq.governance.lock.Lock()
+	defer asq.governance.lock.Unlock()
+
+	if asq.governance.params != nil && asq.governance.hash == parent.Hash {
+		return asq.governance.params.Copy(), nil
+	}
+
+	contract := asq.registry.Contract(Sequencer)
+
+	var params *GovernanceParams
+
+	err := asq.withTxn(parent, uint64(len(governanceGetters)), func(t *state.Transition, gasLimit uint64) (err error) {
+		params, err = contract.queryGovernanceParams(t, gasLimit, types.BytesToAddress(parent.Miner))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	asq.governance.hash = parent.Hash
+	asq.governance.params = params
+
+	return params.Copy(), nil
+}
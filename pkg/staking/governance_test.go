@@ -0,0 +1,77 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQueryGovernanceParams(t *testing.T) {
+	tAssert := assert.New(t)
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakingAlloc := *chain.Genesis.Alloc[AddrStakingContract]
+	stakingAlloc.Storage = map[types.Hash]types.Hash{}
+
+	for k, v := range chain.Genesis.Alloc[AddrStakingContract].Storage {
+		stakingAlloc.Storage[k] = v
+	}
+
+	stakingAlloc.Storage[slotKey(slashPercentageSlot)] = types.BytesToHash(big.NewInt(5).Bytes())
+	chain.Genesis.Alloc[AddrStakingContract] = &stakingAlloc
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, types.ZeroAddress)
+	tAssert.NoError(err)
+
+	// The deployed contract only has the slash percentage getter.
+	params, err := QueryGovernanceParams(transition, 1_000_000, types.ZeroAddress)
+	tAssert.NoError(err)
+	tAssert.Equal(&GovernanceParams{SlashPercentage: big.NewInt(5)}, params)
+}
+
+func TestGetGovernanceParamsCache(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	queries := 0
+	querier.beforeQuery = func() { queries++ }
+
+	params, err := querier.GetGovernanceParams()
+	tAssert.NoError(err)
+	tAssert.Equal(big.NewInt(1), params.SlashPercentage)
+	tAssert.Equal(1, queries)
+
+	// Modifying the returned parameters doesn't modify the cached ones.
+	params.SlashPercentage.SetInt64(50)
+
+	params, err = querier.GetGovernanceParams()
+	tAssert.NoError(err)
+	tAssert.Equal(big.NewInt(1), params.SlashPercentage)
+	tAssert.Equal(1, queries)
+
+	// A new head invalidates the cache.
+	sequencer, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer, big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH), blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencer, sequencerSignKey, big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH), 1_000_000, "test"))
+
+	params, err = querier.GetGovernanceParams()
+	tAssert.NoError(err)
+	tAssert.Equal(big.NewInt(1), params.SlashPercentage)
+	tAssert.Equal(2, queries)
+}
@@ -0,0 +1,113 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/blockchain"
+)
+
+// MaxStakingHistoryRange is the maximum number of blocks BuildStakingHistory walks in one call.
+const MaxStakingHistoryRange = uint64(10_000)
+
+// ErrStakingHistoryRangeTooLarge is returned when the requested block range exceeds MaxStakingHistoryRange.
+var ErrStakingHistoryRangeTooLarge = errors.New("staking history block range too large")
+
+// StakingHistoryEntry is a single staking action of a participant.
+type StakingHistoryEntry struct {
+	// Block is the number of the block the action was included in.
+	Block uint64
+	// Action is the recorded staking action.
+	Action StakingAction
+	// Amount is the amount the action refers to, nil for dispute resolution actions.
+	Amount *big.Int
+}
+
+// BuildStakingHistory reconstructs the staking history of the given address in the inclusive block range.
+// It returns the ordered list of staking history entries and an error if the operation fails.
+// The range is capped by MaxStakingHistoryRange; use WalkStakingHistory to process longer ranges.
+func BuildStakingHistory(blockchain *blockchain.Blockchain, addr types.Address, fromBlock, toBlock uint64) ([]StakingHistoryEntry, error) {
+	if toBlock >= fromBlock && toBlock-fromBlock >= MaxStakingHistoryRange {
+		return nil, fmt.Errorf("%w: %d-%d exceeds %d blocks", ErrStakingHistoryRangeTooLarge, fromBlock, toBlock, MaxStakingHistoryRange)
+	}
+
+	entries := []StakingHistoryEntry{}
+
+	err := WalkStakingHistory(blockchain, addr, fromBlock, toBlock, func(entry StakingHistoryEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// WalkStakingHistory walks the block receipts in the inclusive block range and calls fn for every
+// staking contract event of the given address, in order. Receipts are loaded one block at a time.
+// Returning an error from fn stops the walk and the error is returned to the caller.
+func WalkStakingHistory(blockchain *blockchain.Blockchain, addr types.Address, fromBlock, toBlock uint64, fn func(StakingHistoryEntry) error) error {
+	return walkStakingEvents(blockchain, fromBlock, toBlock, func(hdr *types.Header, event *StakingEvent) error {
+		if event == nil || event.Account != addr {
+			return nil
+		}
+
+		return fn(StakingHistoryEntry{
+			Block:  hdr.Number,
+			Action: event.Action,
+			Amount: event.Amount,
+		})
+	})
+}
+
+// walkStakingEvents walks the blocks in the inclusive block range, capped by the chain head, and calls fn
+// for every staking contract event, in order. fn is also called once with a nil event for every block,
+// before its events, so callers can inspect the headers without walking the range twice.
+func walkStakingEvents(blockchain *blockchain.Blockchain, fromBlock, toBlock uint64, fn func(*types.Header, *StakingEvent) error) error {
+	if head := blockchain.Header().Number; toBlock > head {
+		toBlock = head
+	}
+
+	for n := fromBlock; n <= toBlock; n++ {
+		hdr, ok := blockchain.GetHeaderByNumber(n)
+		if !ok {
+			return fmt.Errorf("header for block %d not found", n)
+		}
+
+		if err := fn(hdr, nil); err != nil {
+			return err
+		}
+
+		// Blocks without transactions don't have receipts stored.
+		if hdr.TxRoot == types.EmptyRootHash {
+			continue
+		}
+
+		receipts, err := blockchain.GetReceiptsByHash(hdr.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to get receipts for block %d: %w", n, err)
+		}
+
+		for _, receipt := range receipts {
+			for _, log := range receipt.Logs {
+				event, err := DecodeStakingEvent(log)
+				if err != nil {
+					return err
+				}
+
+				if event == nil {
+					continue
+				}
+
+				if err := fn(hdr, event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestBuildStakingHistory(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	stakerAddr, stakerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, stakerAddr, balance, blockchain, executor)
+
+	otherAddr, otherSignKey := test.NewAccount(t)
+	test.DepositBalance(t, otherAddr, balance, blockchain, executor)
+
+	sender := NewTestAvailSender()
+
+	stakeErr := Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), otherAddr, otherSignKey, stakeAmount, 1_000_000, "test")
+	tAssert.NoError(stakeErr)
+
+	stakeErr = Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), stakerAddr, stakerSignKey, stakeAmount, 1_000_000, "test")
+	tAssert.NoError(stakeErr)
+
+	stakedAt := blockchain.Header().Number
+
+	unStakeErr := UnStake(blockchain, executor, sender, hclog.Default(), stakerAddr, stakerSignKey, 1_000_000, "test")
+	tAssert.NoError(unStakeErr)
+
+	history, err := BuildStakingHistory(blockchain, stakerAddr, 0, blockchain.Header().Number)
+	tAssert.NoError(err)
+	tAssert.Len(history, 2)
+	tAssert.Equal(StakingHistoryEntry{Block: stakedAt, Action: ActionStaked, Amount: stakeAmount}, history[0])
+	tAssert.Equal(ActionUnstaked, history[1].Action)
+	tAssert.Equal(stakedAt+1, history[1].Block)
+
+	// Range before staking is empty.
+	history, err = BuildStakingHistory(blockchain, stakerAddr, 0, stakedAt-1)
+	tAssert.NoError(err)
+	tAssert.Empty(history)
+
+	_, err = BuildStakingHistory(blockchain, stakerAddr, 0, MaxStakingHistoryRange)
+	tAssert.True(errors.Is(err, ErrStakingHistoryRangeTooLarge))
+}
@@ -8,13 +8,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/state/runtime"
 	"github.com/0xPolygon/polygon-edge/types"
-	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
 	"github.com/availproject/op-evm/pkg/blockchain"
 	"github.com/hashicorp/go-hclog"
 	"github.com/umbracle/ethgo"
@@ -29,6 +31,18 @@ type DumbActiveParticipants struct{}
 // It satisfies the ActiveParticipants interface.
 func (dasq *DumbActiveParticipants) Get(nodeType NodeType) ([]types.Address, error) { return nil, nil }
 
+// GetRegistered method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetRegistered(nodeType NodeType) ([]types.Address, error) {
+	return nil, nil
+}
+
+// GetAll method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetAll() (map[NodeType][]types.Address, error) {
+	return nil, nil
+}
+
 // Contains method of DumbActiveParticipants struct always returns true.
 // It satisfies the ActiveParticipants interface.
 func (dasq *DumbActiveParticipants) Contains(_ types.Address, nodeType NodeType) (bool, error) {
@@ -47,47 +61,233 @@ func (dasq *DumbActiveParticipants) GetTotalStakedAmount() (*big.Int, error) {
 	return nil, nil
 }
 
+// GetTotalStakedAmountByType method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetTotalStakedAmountByType(_ NodeType) (*big.Int, error) {
+	return nil, nil
+}
+
 // InProbation method of DumbActiveParticipants struct always returns true.
 // It satisfies the ActiveParticipants interface.
 func (dasq *DumbActiveParticipants) InProbation(_ types.Address) (bool, error) {
 	return true, nil
 }
 
+// InProbationBatch method of DumbActiveParticipants struct always reports every address in probation.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) InProbationBatch(addrs []types.Address) (map[types.Address]bool, error) {
+	return probationMembership(addrs, addrs), nil
+}
+
+// IsSlashed method of DumbActiveParticipants struct always returns false.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) IsSlashed(_ types.Address) (bool, error) {
+	return false, nil
+}
+
+// GetInactiveSequencers method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetInactiveSequencers(_ uint64) ([]types.Address, error) {
+	return nil, nil
+}
+
+// GetStakeRequirement method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetStakeRequirement(_ NodeType) (*big.Int, error) {
+	return nil, nil
+}
+
+// GetDelegationInfo method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetDelegationInfo(_ types.Address) (*DelegationInfo, error) {
+	return nil, nil
+}
+
+// GetWatchtowerBond method of DumbActiveParticipants struct always returns nil values.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetWatchtowerBond(_ types.Address) (*WatchtowerBond, error) {
+	return nil, nil
+}
+
+// Snapshot method of DumbActiveParticipants struct always returns an empty snapshot.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) Snapshot() (*ParticipantsSnapshot, error) {
+	return &ParticipantsSnapshot{}, nil
+}
+
+// HasQuorum method of DumbActiveParticipants struct always returns true.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) HasQuorum(_ int) (bool, error) {
+	return true, nil
+}
+
+// Count method of DumbActiveParticipants struct always returns zero.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) Count(_ NodeType) (int, error) {
+	return 0, nil
+}
+
+// GetSequencerIndex method of DumbActiveParticipants struct always returns the only position of a single sequencer set.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) GetSequencerIndex(_ types.Address) (int, int, error) {
+	return 0, 1, nil
+}
+
+// IsMyTurn method of DumbActiveParticipants struct always returns true.
+// It satisfies the ActiveParticipants interface.
+func (dasq *DumbActiveParticipants) IsMyTurn(_ types.Address, _ uint64) (bool, error) {
+	return true, nil
+}
+
 // ActiveParticipants is an interface for obtaining details about active participants in the network.
-// It includes methods for getting participant addresses, checking participant existence,
-// checking probation status, and getting balances.
+// It includes methods for getting active or all registered participant addresses, checking participant existence,
+// checking probation status, alone or in batches, checking whether an address has been slashed, finding the inactive sequencers, getting balances (including delegated stake) and stake requirements, counting participants,
+// checking sequencer quorum and the round-robin sequencer schedule.
 type ActiveParticipants interface {
 	Get(nodeType NodeType) ([]types.Address, error)
+	GetRegistered(nodeType NodeType) ([]types.Address, error)
+	GetAll() (map[NodeType][]types.Address, error)
 	Contains(addr types.Address, nodeType NodeType) (bool, error)
 	InProbation(address types.Address) (bool, error)
+	InProbationBatch(addrs []types.Address) (map[types.Address]bool, error)
+	IsSlashed(addr types.Address) (bool, error)
+	GetInactiveSequencers(threshold uint64) ([]types.Address, error)
 	GetBalance(addr types.Address) (*big.Int, error)
 	GetTotalStakedAmount() (*big.Int, error)
+	GetTotalStakedAmountByType(nodeType NodeType) (*big.Int, error)
+	GetStakeRequirement(nodeType NodeType) (*big.Int, error)
+	GetDelegationInfo(addr types.Address) (*DelegationInfo, error)
+	GetWatchtowerBond(addr types.Address) (*WatchtowerBond, error)
+	Snapshot() (*ParticipantsSnapshot, error)
+	HasQuorum(minimum int) (bool, error)
+	Count(nodeType NodeType) (int, error)
+	GetSequencerIndex(addr types.Address) (int, int, error)
+	IsMyTurn(addr types.Address, height uint64) (bool, error)
 }
 
 // activeParticipantsQuerier is a concrete implementation of the ActiveParticipants interface.
 // It uses the blockchain, executor, and logger to query participant details from the blockchain.
 type activeParticipantsQuerier struct {
+	executor *state.Executor
+	logger   hclog.Logger
+
+	// blockchain is used to read the imported blocks in HandleBlockEvent, nil for a querier bound to a state root.
 	blockchain *blockchain.Blockchain
-	executor   *state.Executor
-	logger     hclog.Logger
+
+	// head returns the header the queries run on top of, the chain head unless the querier is bound to a state root.
+	head func() *types.Header
+
+	// boundToRoot is set when the querier runs its queries against a fixed state root,
+	// which must be checked to exist in the state DB before any query.
+	boundToRoot bool
+
+	// registry selects the staking contract to call for each node type.
+	registry *ContractRegistry
+
+	// gasLimitFn returns the gas limit used for the queries on top of the given parent header.
+	gasLimitFn func(parent *types.Header) (uint64, error)
+
+	// allowlist and denylist filter the participants returned by the querier.
+	allowlist map[types.Address]struct{}
+	denylist  map[types.Address]struct{}
+
+	// pool holds the transitions reused by the queries on top of the chain head.
+	// A nil pool makes every query begin a fresh transition.
+	pool *transitionPool
+
+	// capabilities caches the capabilities detected per staking contract.
+	capabilities capabilitiesCache
+
+	// governance caches the governance parameters read on top of the last seen head.
+	governance governanceCache
+
+	// slashed caches the slashed participants derived from the chain.
+	slashed slashedCache
+
+	// strictHead makes the queries fail if the chain head moved while they were running.
+	strictHead bool
+
+	// pageSize is the number of addresses fetched per page when a flat address list query runs out of gas.
+	pageSize int
+
+	// beforeQuery is called before every query, tests use it to move the chain head mid-query.
+	beforeQuery func()
+
+	snapshotLock sync.Mutex
+	snapshot     *ParticipantsSnapshot
+}
+
+var (
+	// ErrQueryOutOfGas is returned when a staking contract query runs out of the gas limit configured for the querier.
+	ErrQueryOutOfGas = errors.New("staking contract query ran out of gas")
+
+	// ErrNotAParticipant is returned when the queried address has never staked in the staking contract.
+	ErrNotAParticipant = errors.New("address is not a staking participant")
+)
+
+// QuerierOption configures the active participants querier.
+type QuerierOption func(*activeParticipantsQuerier)
+
+// WithFixedGasLimit sets an explicit gas cap for every query instead of recomputing it per query.
+// It's useful for read-only simulations that need more gas than the block gas limit allows.
+func WithFixedGasLimit(gasLimit uint64) QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		asq.gasLimitFn = func(_ *types.Header) (uint64, error) {
+			return gasLimit, nil
+		}
+	}
+}
+
+// WithGasLimitFromParent uses the gas limit of the parent header for every query
+// instead of calculating the gas limit of the next block.
+func WithGasLimitFromParent() QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		asq.gasLimitFn = func(parent *types.Header) (uint64, error) {
+			return parent.GasLimit, nil
+		}
+	}
+}
+
+// WithContractRegistry sets the registry used to select the staking contract to call for each node type.
+// By default a single-contract registry with the contract deployed at AddrStakingContract is used.
+func WithContractRegistry(registry *ContractRegistry) QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		asq.registry = registry
+	}
 }
 
 // NewActiveParticipantsQuerier creates a new instance of activeParticipantsQuerier.
-// It takes a blockchain, executor, and logger as parameters.
+// It takes a blockchain, executor, logger and optional configuration options as parameters.
+// By default the gas limit of every query is calculated from the parent header and all
+// node types are handled by the staking contract deployed at AddrStakingContract.
 // It returns the ActiveParticipants interface.
-func NewActiveParticipantsQuerier(blockchain *blockchain.Blockchain, executor *state.Executor, logger hclog.Logger) ActiveParticipants {
-	return &activeParticipantsQuerier{
-		blockchain: blockchain,
+func NewActiveParticipantsQuerier(blockchain *blockchain.Blockchain, executor *state.Executor, logger hclog.Logger, opts ...QuerierOption) ActiveParticipants {
+	asq := &activeParticipantsQuerier{
 		executor:   executor,
 		logger:     logger.Named("active_staking_participants_querier"),
+		blockchain: blockchain,
+		head:       blockchain.Header,
+		registry:   NewSingleContractRegistry(),
+		gasLimitFn: func(parent *types.Header) (uint64, error) {
+			// calculate gas limit based on parent header
+			return blockchain.CalculateGasLimit(parent.Number + 1)
+		},
+		pool:     new(transitionPool),
+		pageSize: defaultPageSize,
+	}
+
+	for _, opt := range opts {
+		opt(asq)
 	}
+
+	return asq
 }
 
-// Get method returns the addresses of active participants based on the given node type.
-// It takes the nodeType parameter, which represents the type of node (Sequencer or WatchTower).
-// It returns a slice of addresses and an error if the operation fails.
-func (asq *activeParticipantsQuerier) Get(nodeType NodeType) ([]types.Address, error) {
-	parent := asq.blockchain.Header()
+// beginTxn begins a new transition on top of the parent header for querying the staking contract.
+// The gas pool of the transition fits the given number of queries.
+// It returns the transition, the gas limit to use for every query, the number of queries
+// the transition can actually run and an error if the operation fails.
+func (asq *activeParticipantsQuerier) beginTxn(parent *types.Header, queries uint64) (*state.Transition, uint64, uint64, error) {
 	minerAddress := types.BytesToAddress(parent.Miner)
 
 	header := &types.Header{
@@ -99,37 +299,134 @@ func (asq *activeParticipantsQuerier) Get(nodeType NodeType) ([]types.Address, e
 		Timestamp:  uint64(time.Now().Unix()),
 	}
 
-	// calculate gas limit based on parent header
-	gasLimit, err := asq.blockchain.CalculateGasLimit(header.Number)
+	gasLimit, err := asq.gasLimitFn(parent)
 	if err != nil {
-		return nil, err
+		return nil, 0, 0, err
+	}
+
+	// The gas pool of the transition is bounded by the header gas limit,
+	// raise it so larger query caps and all the queries run on the transition are honored.
+	if gasLimit > 0 && queries > math.MaxUint64/gasLimit {
+		queries = math.MaxUint64 / gasLimit
+	}
+
+	if gasLimit*queries > header.GasLimit {
+		header.GasLimit = gasLimit * queries
+	}
+
+	if asq.boundToRoot {
+		if _, err := asq.executor.State().NewSnapshotAt(parent.StateRoot); err != nil {
+			return nil, 0, 0, fmt.Errorf("%w: %s: %s", ErrStateRootNotFound, parent.StateRoot, err)
+		}
 	}
 
 	transition, err := asq.executor.BeginTxn(parent.StateRoot, header, minerAddress)
 	if err != nil {
-		return nil, err
+		return nil, 0, 0, err
+	}
+
+	return transition, gasLimit, queries, nil
+}
+
+// checkQueryGas wraps the query error into ErrQueryOutOfGas if the query didn't have enough gas.
+func checkQueryGas(err error, gasLimit uint64) error {
+	var appErr *state.TransitionApplicationError
+
+	if errors.Is(err, runtime.ErrOutOfGas) ||
+		(errors.As(err, &appErr) && appErr.Err == state.ErrNotEnoughIntrinsicGas) {
+		return fmt.Errorf("%w (gas limit %d): %s", ErrQueryOutOfGas, gasLimit, err)
+	}
+
+	return err
+}
+
+// applyQuery runs the read-only query transaction as a plain contract call on top of the transition state
+// and reverts every state change it made afterwards. The transition may be shared with a block being built,
+// so a query must never leave a trace the real transactions would trip over: the call neither takes gas
+// from the block gas pool, nor bumps the nonce of the sender, nor fires the post hook of the transition.
+// The given transaction is left untouched.
+func applyQuery(t *state.Transition, tx *types.Transaction) (*runtime.ExecutionResult, error) {
+	intrinsicGas, err := state.TransactionGasCost(tx, true, true)
+	if err != nil {
+		return nil, state.NewTransitionApplicationError(err, false)
+	}
+
+	if tx.Gas < intrinsicGas {
+		return nil, state.NewTransitionApplicationError(state.ErrNotEnoughIntrinsicGas, false)
 	}
 
+	txn := t.Txn()
+	snapshot := txn.Snapshot()
+
+	defer txn.RevertToSnapshot(snapshot)
+
+	res := t.Call2(tx.From, *tx.To, tx.Input, tx.Value, tx.Gas-intrinsicGas)
+	res.UpdateGasUsed(tx.Gas, t.GetRefund())
+
+	return res, nil
+}
+
+// Get method returns the addresses of active participants based on the given node type.
+// It takes the nodeType parameter, which represents the type of node (Sequencer or WatchTower).
+// Only active participants are returned: sequencers in probation are excluded, see GetRegistered for the full set.
+// It returns a slice of addresses and an error if the operation fails.
+func (asq *activeParticipantsQuerier) Get(nodeType NodeType) ([]types.Address, error) {
+	parent := asq.head()
+
 	switch nodeType {
 	case Sequencer:
-		addrs, err := QueryActiveSequencers(asq.blockchain, asq.executor, transition, gasLimit, minerAddress)
+		addrs, err := asq.queryAddresses(parent, Sequencer, "GetCurrentSequencers", nil)
+		if err != nil {
+			asq.logger.Error("failed to query sequencers", "error", err)
+			return nil, err
+		}
+
+		probationAddrs, err := asq.queryProbation(parent)
 		if err != nil {
 			asq.logger.Error("failed to query sequencers", "error", err)
 			return nil, err
 		}
-		return addrs, nil
+
+		if err := asq.checkHead(parent, "Get"); err != nil {
+			return nil, err
+		}
+
+		return asq.filterParticipants(Sequencer, excludeAddresses(addrs, probationAddrs)), nil
 	case WatchTower:
-		addrs, err := QueryWatchtower(transition, gasLimit, minerAddress)
+		addrs, err := asq.queryAddresses(parent, WatchTower, "GetCurrentWatchtowers", nil)
 		if err != nil {
 			asq.logger.Error("failed to query watchtowers", "error", err)
 			return nil, err
 		}
-		return addrs, nil
+		return asq.filterParticipants(WatchTower, addrs), nil
 	default:
-		return nil, fmt.Errorf("failure to query participants due to node type missmatch. '%s' is not node type", nodeType)
+		methodName, ok := participantsGetters[nodeType]
+		if !ok {
+			return nil, fmt.Errorf("failure to query participants due to node type missmatch. '%s' is not node type", nodeType)
+		}
+
+		addrs, err := asq.queryAddresses(parent, nodeType, methodName, nil)
+		if err != nil {
+			asq.logger.Error("failed to query participants", "node_type", nodeType, "error", err)
+			return nil, err
+		}
+		return asq.filterParticipants(nodeType, addrs), nil
 	}
 }
 
+// GetRegistered method returns the addresses of all registered participants of the given node type,
+// including the sequencers in probation, which are still accountable for the blocks they signed.
+// The allowlist and the denylist of the querier aren't applied either.
+// It returns a slice of addresses and an error if the operation fails.
+func (asq *activeParticipantsQuerier) GetRegistered(nodeType NodeType) ([]types.Address, error) {
+	methodName, ok := participantsGetters[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("failure to query registered participants due to node type missmatch. '%s' is not node type", nodeType)
+	}
+
+	return asq.queryAddresses(asq.head(), nodeType, methodName, nil)
+}
+
 // Contains method checks if the given address is contained in the active participants list.
 // It takes the addr parameter, which represents the address to check, and the nodeType parameter, which represents the type of node (Sequencer or WatchTower).
 // It returns a boolean value indicating whether the address is found and an error if the operation fails.
@@ -157,30 +454,7 @@ func (asq *activeParticipantsQuerier) Contains(addr types.Address, nodeType Node
 // It takes the address parameter, which represents the address to check.
 // It returns a boolean value indicating whether the address is in probation and an error if the operation fails.
 func (asq *activeParticipantsQuerier) InProbation(address types.Address) (bool, error) {
-	parent := asq.blockchain.Header()
-	minerAddress := types.BytesToAddress(parent.Miner)
-
-	header := &types.Header{
-		ParentHash: parent.Hash,
-		Number:     parent.Number + 1,
-		Miner:      minerAddress.Bytes(),
-		Nonce:      types.Nonce{},
-		GasLimit:   parent.GasLimit,
-		Timestamp:  uint64(time.Now().Unix()),
-	}
-
-	// calculate gas limit based on parent header
-	gasLimit, err := asq.blockchain.CalculateGasLimit(header.Number)
-	if err != nil {
-		return false, err
-	}
-
-	transition, err := asq.executor.BeginTxn(parent.StateRoot, header, minerAddress)
-	if err != nil {
-		return false, err
-	}
-
-	probationAddrs, err := QuerySequencersInProbation(transition, gasLimit, minerAddress)
+	probationAddrs, err := asq.queryProbation(asq.head())
 	if err != nil {
 		return false, err
 	}
@@ -194,93 +468,178 @@ func (asq *activeParticipantsQuerier) InProbation(address types.Address) (bool,
 	return false, nil
 }
 
+// InProbationBatch method checks the probation status of all the given addresses at once.
+// The probation list is queried once for all of them, and not at all when no address is given.
+// Duplicate addresses are answered once.
+// It returns a map of every given address to whether it is in probation and an error if the operation fails.
+func (asq *activeParticipantsQuerier) InProbationBatch(addrs []types.Address) (map[types.Address]bool, error) {
+	if len(addrs) == 0 {
+		return map[types.Address]bool{}, nil
+	}
+
+	probationAddrs, err := asq.queryProbation(asq.head())
+	if err != nil {
+		return nil, err
+	}
+
+	return probationMembership(addrs, probationAddrs), nil
+}
+
+// probationMembership maps every given address to whether it is one of the probation addresses.
+func probationMembership(addrs []types.Address, probationAddrs []types.Address) map[types.Address]bool {
+	probation := addressSet(probationAddrs)
+	membership := make(map[types.Address]bool, len(addrs))
+
+	for _, addr := range addrs {
+		_, ok := probation[addr]
+		membership[addr] = ok
+	}
+
+	return membership
+}
+
 // GetBalance method retrieves the balance of the given address.
 // It takes the address parameter, which represents the address to query.
+// With separate staking contracts per node type, the balance is the sum of the stakes in all of them.
+// The membership and the stake are queried in the same transition, so a registered participant
+// with no stake gets an explicit zero balance while an unknown address gets ErrNotAParticipant.
 // It returns the balance as a big.Int value and an error if the operation fails.
 func (asq *activeParticipantsQuerier) GetBalance(address types.Address) (*big.Int, error) {
-	parent := asq.blockchain.Header()
+	parent := asq.head()
 	minerAddress := types.BytesToAddress(parent.Miner)
+	contracts := asq.registry.Contracts()
 
-	header := &types.Header{
-		ParentHash: parent.Hash,
-		Number:     parent.Number + 1,
-		Miner:      minerAddress.Bytes(),
-		Nonce:      types.Nonce{},
-		GasLimit:   parent.GasLimit, // Inherit from parent for now, will need to adjust dynamically later.
-		Timestamp:  uint64(time.Now().Unix()),
+	capabilities := make([]ContractCapabilities, len(contracts))
+	for i, contract := range contracts {
+		var err error
+		if capabilities[i], err = asq.contractCapabilities(parent, contract); err != nil {
+			return nil, err
+		}
 	}
 
-	// calculate gas limit based on parent header
-	gasLimit, err := asq.blockchain.CalculateGasLimit(header.Number)
-	if err != nil {
-		return nil, err
-	}
+	balance := new(big.Int)
+	isParticipant := false
 
-	transition, err := asq.executor.BeginTxn(parent.StateRoot, header, minerAddress)
+	err := asq.withTxn(parent, uint64(2*len(contracts)), func(t *state.Transition, gasLimit uint64) error {
+		for i, contract := range contracts {
+			registered, err := asq.queryIsParticipant(t, gasLimit, minerAddress, contract, capabilities[i], address)
+			if err != nil {
+				return err
+			}
+
+			if !registered {
+				continue
+			}
+
+			amount, err := contract.queryAmount(t, gasLimit, minerAddress, "GetCurrentAccountStakedAmount", map[string]interface{}{"addr": ethgo.Address(address)})
+			if err != nil {
+				return err
+			}
+
+			isParticipant = true
+
+			balance.Add(balance, amount)
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	balance, err := QueryParticipantBalance(transition, gasLimit, minerAddress, address)
-	if err != nil {
-		return nil, err
+	if !isParticipant {
+		return nil, ErrNotAParticipant
 	}
 
 	return balance, nil
 }
 
 // GetTotalStakedAmount method retrieves the total staked amount in the system.
+// With separate staking contracts per node type, the amount is the sum of all of them.
 // It returns the total staked amount as a big.Int value and an error if the operation fails.
 func (asq *activeParticipantsQuerier) GetTotalStakedAmount() (*big.Int, error) {
-	parent := asq.blockchain.Header()
-	minerAddress := types.BytesToAddress(parent.Miner)
+	parent := asq.head()
 
-	header := &types.Header{
-		ParentHash: parent.Hash,
-		Number:     parent.Number + 1,
-		Miner:      minerAddress.Bytes(),
-		Nonce:      types.Nonce{},
-		GasLimit:   parent.GasLimit, // Inherit from parent for now, will need to adjust dynamically later.
-		Timestamp:  uint64(time.Now().Unix()),
-	}
-
-	// calculate gas limit based on parent header
-	gasLimit, err := asq.blockchain.CalculateGasLimit(header.Number)
+	total, err := asq.queryTotalStake(parent)
 	if err != nil {
 		return nil, err
 	}
 
-	transition, err := asq.executor.BeginTxn(parent.StateRoot, header, minerAddress)
-	if err != nil {
+	if err := asq.checkHead(parent, "GetTotalStakedAmount"); err != nil {
 		return nil, err
 	}
 
-	balance, err := QueryParticipantTotalStakedAmount(transition, gasLimit, minerAddress)
-	if err != nil {
-		return nil, err
+	return total, nil
+}
+
+// queryTotalStake queries the total staked amount, summed over the staking contracts, on top of the parent header.
+func (asq *activeParticipantsQuerier) queryTotalStake(parent *types.Header) (*big.Int, error) {
+	total := new(big.Int)
+
+	for _, contract := range asq.registry.Contracts() {
+		amount, err := asq.queryAmount(parent, contract, "GetCurrentStakedAmount", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		total.Add(total, amount)
 	}
 
-	return balance, nil
+	return total, nil
+}
+
+// GetTotalStakedAmountByType method retrieves the total staked amount in the contract handling the given node type.
+// When a single contract handles all node types, it returns the same amount as GetTotalStakedAmount.
+// It returns the total staked amount as a big.Int value and an error if the operation fails.
+func (asq *activeParticipantsQuerier) GetTotalStakedAmountByType(nodeType NodeType) (*big.Int, error) {
+	return asq.queryAmount(asq.head(), asq.registry.Contract(nodeType), "GetCurrentStakedAmount", nil)
+}
+
+// queryAddresses queries a list of addresses from the staking contract handling the given node type.
+// If the query runs out of gas and the list has a paged variant, the list is queried page by page.
+func (asq *activeParticipantsQuerier) queryAddresses(parent *types.Header, nodeType NodeType, methodName string, inputs map[string]interface{}) ([]types.Address, error) {
+	contract := asq.registry.Contract(nodeType)
+	minerAddress := types.BytesToAddress(parent.Miner)
+
+	addrs, err := asq.queryAt(parent, func(t *state.Transition, gasLimit uint64) ([]types.Address, error) {
+		return contract.queryAddresses(t, gasLimit, minerAddress, methodName, inputs)
+	})
+	if isPaginatable(err, methodName, inputs) {
+		// Large lists can't be decoded within the gas limit of a single call.
+		asq.logger.Debug("address list query ran out of gas, querying it page by page", "method", methodName, "page_size", asq.pageSize)
+		return asq.queryAddressesPaged(parent, nodeType, methodName)
+	}
+
+	return addrs, err
+}
+
+// queryAmount queries an amount from the given staking contract in a fresh transition on top of the parent header.
+func (asq *activeParticipantsQuerier) queryAmount(parent *types.Header, contract *StakingContract, methodName string, inputs map[string]interface{}) (amount *big.Int, err error) {
+	err = asq.withTxn(parent, 1, func(t *state.Transition, gasLimit uint64) error {
+		amount, err = contract.queryAmount(t, gasLimit, types.BytesToAddress(parent.Miner), methodName, inputs)
+		return err
+	})
+
+	return amount, err
 }
 
 // QueryParticipants queries the current participants from the staking contract.
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns a slice of addresses representing the current participants and an error if the operation fails.
 func QueryParticipants(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentParticipants"]
+	method, ok := stakingMethod("GetCurrentParticipants")
 	if !ok {
 		return nil, errors.New("GetCurrentParticipants method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -288,82 +647,48 @@ func QueryParticipants(t *state.Transition, gasLimit uint64, from types.Address)
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return DecodeParticipants(method, res.ReturnValue)
 }
 
 // QueryActiveSequencers queries the current active sequencers from the staking contract.
-// It takes a blockchain, an executor, a transaction transition, gas limit, and the address of the sender as parameters.
+// It takes a transaction transition, gas limit, and the address of the sender as parameters.
+// Both the sequencers and the sequencers in probation are read from the given transition,
+// so the result always belongs to the state the caller's transition runs on top of.
 // It returns a slice of addresses representing the current active sequencers and an error if the operation fails.
-func QueryActiveSequencers(blockchain *blockchain.Blockchain, executor *state.Executor, t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
-	toReturn := []types.Address{}
-
+func QueryActiveSequencers(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
 	addrs, err := QuerySequencers(t, gasLimit, from)
 	if err != nil {
 		return nil, err
 	}
 
-	parent := blockchain.Header()
-
-	header := &types.Header{
-		ParentHash: parent.Hash,
-		Number:     parent.Number + 1,
-		Miner:      from.Bytes(),
-		Nonce:      types.Nonce{},
-		GasLimit:   parent.GasLimit, // Inherit from parent for now, will need to adjust dynamically later.
-		Timestamp:  uint64(time.Now().Unix()),
-	}
-
-	// calculate gas limit based on parent header
-	probationGasLimit, err := blockchain.CalculateGasLimit(header.Number)
-	if err != nil {
-		return nil, err
-	}
-
-	transition, err := executor.BeginTxn(parent.StateRoot, header, from)
-	if err != nil {
-		return nil, err
-	}
-
-	probationAddrs, err := QuerySequencersInProbation(transition, probationGasLimit, from)
+	probationAddrs, err := QuerySequencersInProbation(t, gasLimit, from)
 	if err != nil {
 		return nil, err
 	}
 
-mainLoop:
-	for _, addr := range addrs {
-		for _, probationAddr := range probationAddrs {
-			if addr.String() == probationAddr.String() {
-				continue mainLoop
-			}
-		}
-
-		toReturn = append(toReturn, addr)
-	}
-
-	return toReturn, nil
+	return excludeAddresses(addrs, probationAddrs), nil
 }
 
 // QuerySequencers queries the current sequencers from the staking contract.
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns a slice of addresses representing the current sequencers and an error if the operation fails.
 func QuerySequencers(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentSequencers"]
+	method, ok := stakingMethod("GetCurrentSequencers")
 	if !ok {
 		return nil, errors.New("GetCurrentSequencers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -371,7 +696,7 @@ func QuerySequencers(t *state.Transition, gasLimit uint64, from types.Address) (
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return DecodeParticipants(method, res.ReturnValue)
@@ -381,20 +706,19 @@ func QuerySequencers(t *state.Transition, gasLimit uint64, from types.Address) (
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns a slice of addresses representing the sequencers in probation and an error if the operation fails.
 func QuerySequencersInProbation(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentSequencersInProbation"]
+	method, ok := stakingMethod("GetCurrentSequencersInProbation")
 	if !ok {
 		return nil, errors.New("GetCurrentSequencersInProbation method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -402,7 +726,7 @@ func QuerySequencersInProbation(t *state.Transition, gasLimit uint64, from types
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return DecodeParticipants(method, res.ReturnValue)
@@ -412,20 +736,19 @@ func QuerySequencersInProbation(t *state.Transition, gasLimit uint64, from types
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns a slice of addresses representing the current watchtowers and an error if the operation fails.
 func QueryWatchtower(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentWatchtowers"]
+	method, ok := stakingMethod("GetCurrentWatchtowers")
 	if !ok {
 		return nil, errors.New("GetCurrentWatchtowers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -433,7 +756,7 @@ func QueryWatchtower(t *state.Transition, gasLimit uint64, from types.Address) (
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return DecodeParticipants(method, res.ReturnValue)
@@ -471,7 +794,7 @@ func DecodeParticipants(method *abi.Method, returnValue []byte) ([]types.Address
 // It takes a transaction transition, gas limit, the address of the sender, and the address of the participant as parameters.
 // It returns the staked amount as a big.Int value and an error if the operation fails.
 func QueryParticipantBalance(t *state.Transition, gasLimit uint64, from types.Address, addr types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentAccountStakedAmount"]
+	method, ok := stakingMethod("GetCurrentAccountStakedAmount")
 	if !ok {
 		return nil, errors.New("GetCurrentAccountStakedAmount method doesn't exist in Staking contract ABI")
 	}
@@ -480,21 +803,20 @@ func QueryParticipantBalance(t *state.Transition, gasLimit uint64, from types.Ad
 
 	encodedInput, encodeErr := method.Inputs.Encode(
 		map[string]interface{}{
-			"addr": addr.Bytes(),
+			"addr": ethgo.Address(addr),
 		},
 	)
 	if encodeErr != nil {
 		return nil, encodeErr
 	}
 
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    append(selector, encodedInput...),
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -502,7 +824,7 @@ func QueryParticipantBalance(t *state.Transition, gasLimit uint64, from types.Ad
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return new(big.Int).SetBytes(res.ReturnValue), nil
@@ -512,20 +834,19 @@ func QueryParticipantBalance(t *state.Transition, gasLimit uint64, from types.Ad
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns the total staked amount as a big.Int value and an error if the operation fails.
 func QueryParticipantTotalStakedAmount(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentStakedAmount"]
+	method, ok := stakingMethod("GetCurrentStakedAmount")
 	if !ok {
 		return nil, errors.New("GetCurrentStakedAmount method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -533,7 +854,7 @@ func QueryParticipantTotalStakedAmount(t *state.Transition, gasLimit uint64, fro
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	return new(big.Int).SetBytes(res.ReturnValue), nil
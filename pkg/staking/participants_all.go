@@ -0,0 +1,103 @@
+package staking
+
+import (
+	"errors"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// participantsGetters maps every node type to the staking contract getter of its registered participants.
+// Node types added here are picked up by GetRegistered and GetAll.
+var participantsGetters = map[NodeType]string{
+	Sequencer:  "GetCurrentSequencers",
+	WatchTower: "GetCurrentWatchtowers",
+}
+
+// GetAll method returns the active participants of every node type, queried in a single transition on top of the chain head.
+// The sets are the same as the ones returned by Get. Node types the staking contract doesn't have a getter for are omitted.
+// When an address list can't be decoded within the gas limit of a single call, it falls back to Get per node type.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) GetAll() (map[NodeType][]types.Address, error) {
+	parent := asq.head()
+	minerAddress := types.BytesToAddress(parent.Miner)
+
+	capabilities, err := asq.contractCapabilities(parent, asq.registry.Contract(Sequencer))
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[NodeType][]types.Address, len(participantsGetters))
+
+	var probationAddrs []types.Address
+
+	err = asq.withTxn(parent, uint64(len(participantsGetters)+1), func(t *state.Transition, gasLimit uint64) error {
+		for nodeType, methodName := range participantsGetters {
+			addrs, err := asq.registry.Contract(nodeType).queryAddresses(t, gasLimit, minerAddress, methodName, nil)
+			if errors.Is(err, ErrUnsupportedByContract) {
+				asq.logger.Debug("staking contract doesn't support node type, omitting it", "node_type", nodeType, "method", methodName)
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			registered[nodeType] = addrs
+		}
+
+		if _, ok := registered[Sequencer]; !ok || !capabilities.Probation {
+			return nil
+		}
+
+		probationAddrs, err = asq.registry.Contract(Sequencer).queryAddresses(t, gasLimit, minerAddress, "GetCurrentSequencersInProbation", nil)
+
+		return err
+	})
+	if errors.Is(err, ErrQueryOutOfGas) {
+		asq.logger.Debug("participants query ran out of gas, querying every node type on its own")
+		return asq.getAllByNodeType()
+	}
+
+	if err != nil {
+		asq.logger.Error("failed to query participants", "error", err)
+		return nil, err
+	}
+
+	if err := asq.checkHead(parent, "GetAll"); err != nil {
+		return nil, err
+	}
+
+	toReturn := make(map[NodeType][]types.Address, len(registered))
+
+	for nodeType, addrs := range registered {
+		if nodeType == Sequencer {
+			addrs = excludeAddresses(addrs, probationAddrs)
+		}
+
+		toReturn[nodeType] = asq.filterParticipants(nodeType, addrs)
+	}
+
+	return toReturn, nil
+}
+
+// getAllByNodeType returns the active participants of every node type with a Get call per node type.
+func (asq *activeParticipantsQuerier) getAllByNodeType() (map[NodeType][]types.Address, error) {
+	toReturn := make(map[NodeType][]types.Address, len(participantsGetters))
+
+	for nodeType := range participantsGetters {
+		addrs, err := asq.Get(nodeType)
+		if errors.Is(err, ErrUnsupportedByContract) {
+			asq.logger.Debug("staking contract doesn't support node type, omitting it", "node_type", nodeType)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		toReturn[nodeType] = addrs
+	}
+
+	return toReturn, nil
+}
@@ -0,0 +1,94 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+	"github.com/umbracle/ethgo/abi"
+)
+
+func TestQuerierGetAll(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	maliciousAddr, maliciousSignKey := test.NewAccount(t)
+	test.DepositBalance(t, maliciousAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), maliciousAddr, maliciousSignKey, stakeAmount, 1_000_000, "test"))
+
+	tAssert.NoError(NewDisputeResolution(blockchain, executor, sender, hclog.Default()).Begin(maliciousAddr, watchtowerSignKey))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	// Detect the capabilities upfront, so only the participant queries are counted.
+	_, err = querier.Capabilities()
+	tAssert.NoError(err)
+
+	queries := 0
+	querier.beforeQuery = func() { queries++ }
+
+	all, err := querier.GetAll()
+	tAssert.NoError(err)
+	tAssert.Equal(map[NodeType][]types.Address{
+		Sequencer:  {sequencerAddr},
+		WatchTower: {watchtowerAddr},
+	}, all)
+	tAssert.Equal(1, queries)
+
+	for nodeType, addrs := range all {
+		expected, err := querier.Get(nodeType)
+		tAssert.NoError(err)
+		tAssert.Equal(expected, addrs)
+	}
+}
+
+func TestQuerierGetAllUnsupportedNodeType(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	// The watchtowers are handled by a contract without a watchtower getter.
+	partialABI := &abi.ABI{Methods: map[string]*abi.Method{}, Events: stakingABI().Events}
+	for name, method := range stakingABI().Methods {
+		if name != "GetCurrentWatchtowers" {
+			partialABI.Methods[name] = method
+		}
+	}
+
+	registry := NewSingleContractRegistry()
+	registry.Register(WatchTower, &StakingContract{Address: AddrStakingContract, ABI: partialABI})
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), WithContractRegistry(registry))
+
+	all, err := querier.GetAll()
+	tAssert.NoError(err)
+	tAssert.Equal(map[NodeType][]types.Address{Sequencer: {sequencerAddr}}, all)
+}
@@ -0,0 +1,43 @@
+package staking
+
+import (
+	"errors"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// ErrStateRootNotFound is returned by a querier bound to a state root that is missing from the local state DB.
+var ErrStateRootNotFound = errors.New("state root not found in the state DB")
+
+// NewParticipantsQuerierAtRoot creates a new instance of activeParticipantsQuerier bound to the given state root.
+// Watchtowers use it to evaluate the participant set as of the parent of a disputed block instead of the chain head.
+// The header is the header of the block the state root belongs to, it provides the block context of the queries
+// and the gas limit, which is taken from the header unless a gas limit option is given.
+// Every method returns an error wrapping ErrStateRootNotFound if the state root is missing from the state DB,
+// the querier never falls back to the chain head.
+// It returns the ActiveParticipants interface.
+func NewParticipantsQuerierAtRoot(executor *state.Executor, stateRoot types.Hash, header *types.Header, logger hclog.Logger, opts ...QuerierOption) ActiveParticipants {
+	parent := header.Copy()
+	parent.StateRoot = stateRoot
+
+	asq := &activeParticipantsQuerier{
+		executor:    executor,
+		logger:      logger.Named("staking_participants_querier_at_root"),
+		head:        func() *types.Header { return parent },
+		boundToRoot: true,
+		registry:    NewSingleContractRegistry(),
+		gasLimitFn: func(parent *types.Header) (uint64, error) {
+			return parent.GasLimit, nil
+		},
+		pool:     new(transitionPool),
+		pageSize: defaultPageSize,
+	}
+
+	for _, opt := range opts {
+		opt(asq)
+	}
+
+	return asq
+}
@@ -0,0 +1,107 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestParticipantsQuerierAtRoot(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+
+	pastHeader := blockchain.Header()
+
+	sequencer2, sequencer2SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer2, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer2, sequencer2SignKey, stakeAmount, 1_000_000, "test"))
+
+	headQuerier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	sequencers, err := headQuerier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal(sortedAddresses([]types.Address{sequencer1, sequencer2}), sortedAddresses(sequencers))
+
+	// The querier bound to the past state root doesn't see the second sequencer.
+	pastQuerier := NewParticipantsQuerierAtRoot(executor, pastHeader.StateRoot, pastHeader, hclog.Default())
+
+	sequencers, err = pastQuerier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, sequencers)
+
+	isSequencer, err := pastQuerier.Contains(sequencer2, Sequencer)
+	tAssert.NoError(err)
+	tAssert.False(isSequencer)
+
+	_, err = pastQuerier.GetBalance(sequencer2)
+	tAssert.True(errors.Is(err, ErrNotAParticipant), "unexpected error: %v", err)
+
+	total, err := pastQuerier.GetTotalStakedAmount()
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, total)
+
+	snapshot, err := pastQuerier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal(pastHeader.Number, snapshot.BlockNumber)
+	tAssert.Equal([]types.Address{sequencer1}, snapshot.Sequencers)
+}
+
+func TestParticipantsQuerierAtMissingRoot(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	addr := types.StringToAddress("0x1")
+	querier := NewParticipantsQuerierAtRoot(executor, types.StringToHash("0xdead"), blockchain.Header(), hclog.Default())
+
+	_, err = querier.Get(Sequencer)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.Contains(addr, Sequencer)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.InProbation(addr)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.GetBalance(addr)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.GetTotalStakedAmount()
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.GetTotalStakedAmountByType(WatchTower)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.GetDelegationInfo(addr)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.Snapshot()
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.HasQuorum(1)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.Count(Sequencer)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+
+	_, err = querier.IsMyTurn(addr, 1)
+	tAssert.True(errors.Is(err, ErrStateRootNotFound), "unexpected error: %v", err)
+}
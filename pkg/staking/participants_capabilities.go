@@ -0,0 +1,154 @@
+package staking
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/umbracle/ethgo"
+)
+
+// pushSelectorOpcode is the PUSH4 opcode the solidity function dispatcher pushes the method selectors with.
+const pushSelectorOpcode = 0x63
+
+// ContractCapabilities are the optional features detected in the bytecode of a deployed staking contract.
+// Testnets still run older deployments of the staking contract that lack some of the getters.
+type ContractCapabilities struct {
+	// Address is the address of the staking contract.
+	Address types.Address
+	// Probation is true if the contract has the GetCurrentSequencersInProbation getter.
+	Probation bool
+	// MembershipGetters is true if the contract has the per-address membership getters.
+	MembershipGetters bool
+}
+
+// CapabilitiesReporter is implemented by the queriers that detect the capabilities of the staking contracts.
+type CapabilitiesReporter interface {
+	// Capabilities returns the detected capabilities of every staking contract the querier calls.
+	Capabilities() ([]ContractCapabilities, error)
+}
+
+// probationMethods and membershipMethods are the methods that must all be dispatched by a contract
+// to have the corresponding capability.
+var (
+	probationMethods  = []string{"GetCurrentSequencersInProbation"}
+	membershipMethods = []string{"_addressToIsParticipant", "IsSequencer", "IsWatchtower"}
+)
+
+// capabilitiesCache holds the capabilities detected per contract address.
+// Deployed bytecode doesn't change, so the capabilities are detected only once.
+type capabilitiesCache struct {
+	lock         sync.Mutex
+	capabilities map[types.Address]ContractCapabilities
+}
+
+// Capabilities method returns the detected capabilities of every staking contract in the registry.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) Capabilities() ([]ContractCapabilities, error) {
+	contracts := asq.registry.Contracts()
+	toReturn := make([]ContractCapabilities, 0, len(contracts))
+
+	for _, contract := range contracts {
+		capabilities, err := asq.contractCapabilities(asq.head(), contract)
+		if err != nil {
+			return nil, err
+		}
+
+		toReturn = append(toReturn, capabilities)
+	}
+
+	return toReturn, nil
+}
+
+// contractCapabilities returns the capabilities of the contract, detecting them in the state on top of the parent header
+// the first time the contract is seen. A contract without code isn't cached, as it may be deployed later.
+func (asq *activeParticipantsQuerier) contractCapabilities(parent *types.Header, contract *StakingContract) (ContractCapabilities, error) {
+	asq.capabilities.lock.Lock()
+	defer asq.capabilities.lock.Unlock()
+
+	if capabilities, ok := asq.capabilities.capabilities[contract.Address]; ok {
+		return capabilities, nil
+	}
+
+	// Reading the code isn't a contract call, the transition doesn't need any gas.
+	transition, _, _, err := asq.beginTxn(parent, 0)
+	if err != nil {
+		return ContractCapabilities{}, err
+	}
+
+	code := transition.GetCode(contract.Address)
+
+	capabilities := ContractCapabilities{
+		Address:           contract.Address,
+		Probation:         dispatchesMethods(code, contract, probationMethods),
+		MembershipGetters: dispatchesMethods(code, contract, membershipMethods),
+	}
+
+	if len(code) == 0 {
+		return capabilities, nil
+	}
+
+	if !capabilities.Probation {
+		asq.logger.Warn("staking contract doesn't support probation, no sequencer is considered in probation", "contract", contract.Address)
+	}
+
+	if !capabilities.MembershipGetters {
+		asq.logger.Warn("staking contract doesn't have membership getters, falling back to participant list scans", "contract", contract.Address)
+	}
+
+	if asq.capabilities.capabilities == nil {
+		asq.capabilities.capabilities = make(map[types.Address]ContractCapabilities)
+	}
+
+	asq.capabilities.capabilities[contract.Address] = capabilities
+
+	return capabilities, nil
+}
+
+// dispatchesMethods checks whether the bytecode dispatches all the given methods of the contract ABI,
+// i.e. it pushes their selectors to compare them with the selector of the call.
+func dispatchesMethods(code []byte, contract *StakingContract, methodNames []string) bool {
+	for _, methodName := range methodNames {
+		method, ok := contract.ABI.Methods[methodName]
+		if !ok {
+			return false
+		}
+
+		if !bytes.Contains(code, append([]byte{pushSelectorOpcode}, method.ID()...)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// queryProbation queries the sequencers in probation on top of the parent header.
+// It returns no sequencers if the contract doesn't support probation.
+func (asq *activeParticipantsQuerier) queryProbation(parent *types.Header) ([]types.Address, error) {
+	capabilities, err := asq.contractCapabilities(parent, asq.registry.Contract(Sequencer))
+	if err != nil {
+		return nil, err
+	}
+
+	if !capabilities.Probation {
+		return nil, nil
+	}
+
+	return asq.queryAddresses(parent, Sequencer, "GetCurrentSequencersInProbation", nil)
+}
+
+// queryIsParticipant checks in the transition whether the address has ever staked in the contract.
+// It scans the participant list if the contract doesn't have the membership getters.
+func (asq *activeParticipantsQuerier) queryIsParticipant(t *state.Transition, gasLimit uint64, from types.Address, contract *StakingContract, capabilities ContractCapabilities, addr types.Address) (bool, error) {
+	if capabilities.MembershipGetters {
+		return contract.queryBool(t, gasLimit, from, "_addressToIsParticipant", map[string]interface{}{"0": ethgo.Address(addr)})
+	}
+
+	participants, err := contract.queryAddresses(t, gasLimit, from, "GetCurrentParticipants", nil)
+	if err != nil {
+		return false, err
+	}
+
+	return containsAddress(participants, addr), nil
+}
@@ -0,0 +1,97 @@
+package staking
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierCapabilities(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(CapabilitiesReporter)
+
+	capabilities, err := querier.Capabilities()
+	tAssert.NoError(err)
+	tAssert.Equal([]ContractCapabilities{{Address: AddrStakingContract, Probation: true, MembershipGetters: true}}, capabilities)
+}
+
+func TestQuerierOlderContract(t *testing.T) {
+	tAssert := assert.New(t)
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	// Emulate an older deployment by removing the probation and membership getters from the dispatcher.
+	stakingAlloc := *chain.Genesis.Alloc[AddrStakingContract]
+	stakingAlloc.Code = append([]byte(nil), stakingAlloc.Code...)
+
+	contract := DefaultStakingContract()
+	for _, methodName := range []string{"GetCurrentSequencersInProbation", "_addressToIsParticipant"} {
+		selector := append([]byte{pushSelectorOpcode}, contract.ABI.Methods[methodName].ID()...)
+		tAssert.True(bytes.Contains(stakingAlloc.Code, selector))
+
+		stakingAlloc.Code = bytes.ReplaceAll(stakingAlloc.Code, selector, []byte{pushSelectorOpcode, 0xde, 0xad, 0xbe, 0xef})
+	}
+
+	chain.Genesis.Alloc[AddrStakingContract] = &stakingAlloc
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	// The removed getters revert.
+	_, err = querier.(*activeParticipantsQuerier).queryAddresses(blockchain.Header(), Sequencer, "GetCurrentSequencersInProbation", nil)
+	tAssert.True(errors.Is(err, ErrQueryReverted), "unexpected error: %v", err)
+
+	capabilities, err := querier.(CapabilitiesReporter).Capabilities()
+	tAssert.NoError(err)
+	tAssert.Equal([]ContractCapabilities{{Address: AddrStakingContract}}, capabilities)
+
+	sequencers, err := querier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencerAddr}, sequencers)
+
+	count, err := querier.Count(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal(1, count)
+
+	inProbation, err := querier.InProbation(sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.False(inProbation)
+
+	probation, err := querier.InProbationBatch([]types.Address{sequencerAddr})
+	tAssert.NoError(err)
+	tAssert.Equal(map[types.Address]bool{sequencerAddr: false}, probation)
+
+	snapshot, err := querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencerAddr}, snapshot.Sequencers)
+	tAssert.Empty(snapshot.SequencersInProbation)
+
+	stake, err := querier.GetBalance(sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(0, stakeAmount.Cmp(stake))
+
+	_, err = querier.GetBalance(types.StringToAddress("0x5678"))
+	tAssert.True(errors.Is(err, ErrNotAParticipant))
+}
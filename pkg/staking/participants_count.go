@@ -0,0 +1,98 @@
+package staking
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// QuerySequencerCount queries the number of current sequencers, including the ones in probation, from the staking contract.
+// The staking contract doesn't have count getters, so the length is read from the storage slot of the sequencers array
+// instead of decoding the whole array. The gas limit and the address of the sender aren't used, they are kept
+// so the function takes the same parameters as QuerySequencers.
+// It returns the number of sequencers and an error if the operation fails.
+func QuerySequencerCount(t *state.Transition, _ uint64, _ types.Address) (int, error) {
+	return queryArrayLength(t, AddrStakingContract, sequencersSlot)
+}
+
+// QueryWatchtowerCount queries the number of current watchtowers from the staking contract.
+// Like QuerySequencerCount, it reads the length from the storage slot of the watchtowers array.
+// It returns the number of watchtowers and an error if the operation fails.
+func QueryWatchtowerCount(t *state.Transition, _ uint64, _ types.Address) (int, error) {
+	return queryArrayLength(t, AddrStakingContract, watchtowersSlot)
+}
+
+// queryArrayLength reads the length of the dynamic array stored at the given slot of the contract.
+func queryArrayLength(t *state.Transition, contract types.Address, slot int64) (int, error) {
+	length := new(big.Int).SetBytes(t.GetStorage(contract, slotKey(slot)).Bytes())
+	if !length.IsInt64() {
+		return 0, fmt.Errorf("invalid array length %s at slot %d of contract %s", length, slot, contract)
+	}
+
+	return int(length.Int64()), nil
+}
+
+// Count method returns the number of active participants of the given node type.
+// It's equivalent to len(Get(nodeType)) without decoding the addresses. When an allowlist or
+// a denylist is configured the addresses are needed to filter them, so it falls back to Get.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) Count(nodeType NodeType) (int, error) {
+	if len(asq.allowlist) > 0 || len(asq.denylist) > 0 {
+		addrs, err := asq.Get(nodeType)
+		if err != nil {
+			return 0, err
+		}
+
+		return len(addrs), nil
+	}
+
+	parent := asq.head()
+
+	var slots []int64
+
+	switch nodeType {
+	case Sequencer:
+		capabilities, err := asq.contractCapabilities(parent, asq.registry.Contract(Sequencer))
+		if err != nil {
+			return 0, err
+		}
+
+		slots = []int64{sequencersSlot}
+		if capabilities.Probation {
+			slots = append(slots, sequencersInProbationSlot)
+		}
+	case WatchTower:
+		slots = []int64{watchtowersSlot}
+	default:
+		return 0, fmt.Errorf("failure to count participants due to node type missmatch. '%s' is not node type", nodeType)
+	}
+
+	contract := asq.registry.Contract(nodeType)
+	lengths := make([]int, len(slots))
+
+	err := asq.withTxn(parent, 0, func(t *state.Transition, _ uint64) error {
+		for i, slot := range slots {
+			length, err := queryArrayLength(t, contract.Address, slot)
+			if err != nil {
+				return err
+			}
+
+			lengths[i] = length
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// The sequencers in probation are still part of the sequencers array, but Get excludes them.
+	count := lengths[0]
+	for _, length := range lengths[1:] {
+		count -= length
+	}
+
+	return count, nil
+}
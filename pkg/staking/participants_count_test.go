@@ -0,0 +1,88 @@
+package staking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierCountMatchesGet(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+
+	var sequencers []types.Address
+
+	for i := 0; i < 3; i++ {
+		sequencerAddr, sequencerSignKey := test.NewAccount(t)
+		test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+		tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+		sequencers = append(sequencers, sequencerAddr)
+	}
+
+	// Put a sequencer in probation, it's still in the sequencers array of the contract.
+	tAssert.NoError(NewDisputeResolution(blockchain, executor, sender, hclog.Default()).Begin(sequencers[0], watchtowerSignKey))
+
+	testCases := []struct {
+		name string
+		opts []QuerierOption
+	}{
+		{"no filtering", nil},
+		{"denylist", []QuerierOption{WithDenylist([]types.Address{sequencers[1]})}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			tAssert := assert.New(t)
+
+			querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), tc.opts...)
+
+			for _, nodeType := range []NodeType{Sequencer, WatchTower} {
+				addrs, err := querier.Get(nodeType)
+				tAssert.NoError(err)
+
+				count, err := querier.Count(nodeType)
+				tAssert.NoError(err)
+				tAssert.Equal(len(addrs), count, "count mismatch for %s", nodeType)
+			}
+		})
+	}
+
+	// The raw contract counts include the sequencer in probation.
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, watchtowerAddr)
+	tAssert.NoError(err)
+
+	sequencerCount, err := QuerySequencerCount(transition, 1_000_000, watchtowerAddr)
+	tAssert.NoError(err)
+
+	allSequencers, err := QuerySequencers(transition, 1_000_000, watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(len(allSequencers), sequencerCount)
+	tAssert.Equal(3, sequencerCount)
+
+	watchtowerCount, err := QueryWatchtowerCount(transition, 1_000_000, watchtowerAddr)
+	tAssert.NoError(err)
+
+	watchtowers, err := QueryWatchtower(transition, 1_000_000, watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(len(watchtowers), watchtowerCount)
+	tAssert.Equal(1, watchtowerCount)
+}
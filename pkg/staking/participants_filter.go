@@ -0,0 +1,71 @@
+package staking
+
+import (
+	"strings"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// WithAllowlist restricts the participants returned by the querier to the given addresses.
+// Participants not on the allowlist are ignored even if they appear in the on-chain set.
+// An empty allowlist doesn't restrict anything.
+func WithAllowlist(addrs []types.Address) QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		asq.allowlist = addressSet(addrs)
+	}
+}
+
+// WithDenylist makes the querier ignore the given addresses even if they appear in the on-chain set.
+// An empty denylist doesn't remove anything.
+func WithDenylist(addrs []types.Address) QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		asq.denylist = addressSet(addrs)
+	}
+}
+
+// filterParticipants applies the allowlist (intersect) and the denylist (subtract) to the given addresses.
+// Every address that gets removed is logged, so a divergence from the on-chain set is visible.
+func (asq *activeParticipantsQuerier) filterParticipants(nodeType NodeType, addrs []types.Address) []types.Address {
+	if len(asq.allowlist) == 0 && len(asq.denylist) == 0 {
+		return addrs
+	}
+
+	toReturn := make([]types.Address, 0, len(addrs))
+
+	for _, addr := range addrs {
+		if _, ok := asq.allowlist[addr]; len(asq.allowlist) > 0 && !ok {
+			asq.logger.Info("ignoring participant not on the allowlist", strings.ToLower(string(nodeType)), addr)
+			continue
+		}
+
+		if _, ok := asq.denylist[addr]; ok {
+			asq.logger.Info("ignoring participant on the denylist", strings.ToLower(string(nodeType)), addr)
+			continue
+		}
+
+		toReturn = append(toReturn, addr)
+	}
+
+	return toReturn
+}
+
+// addressSet converts the given addresses into a set.
+func addressSet(addrs []types.Address) map[types.Address]struct{} {
+	set := make(map[types.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+
+	return set
+}
+
+// containsAddress checks whether the address is one of the given addresses.
+func containsAddress(addrs []types.Address, addr types.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package staking
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierAddressFiltering(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+
+	sequencer2, sequencer2SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer2, balance, blockchain, executor)
+
+	sender := NewTestAvailSender()
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer2, sequencer2SignKey, stakeAmount, 1_000_000, "test"))
+
+	testCases := []struct {
+		name               string
+		opts               []QuerierOption
+		expectedSequencers []types.Address
+	}{
+		{"no filtering", nil, []types.Address{sequencer1, sequencer2}},
+		{"empty lists", []QuerierOption{WithAllowlist(nil), WithDenylist([]types.Address{})}, []types.Address{sequencer1, sequencer2}},
+		{"allowlist", []QuerierOption{WithAllowlist([]types.Address{sequencer1})}, []types.Address{sequencer1}},
+		{"denylist", []QuerierOption{WithDenylist([]types.Address{sequencer1})}, []types.Address{sequencer2}},
+		{"allowlist and denylist", []QuerierOption{WithAllowlist([]types.Address{sequencer1, sequencer2}), WithDenylist([]types.Address{sequencer2})}, []types.Address{sequencer1}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			tAssert := assert.New(t)
+
+			querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), tc.opts...)
+
+			sequencers, err := querier.Get(Sequencer)
+			tAssert.NoError(err)
+			tAssert.Equal(sortedAddresses(tc.expectedSequencers), sortedAddresses(sequencers))
+
+			for _, addr := range []types.Address{sequencer1, sequencer2} {
+				contains, err := querier.Contains(addr, Sequencer)
+				tAssert.NoError(err)
+				tAssert.Equal(containsAddress(tc.expectedSequencers, addr), contains)
+			}
+
+			snapshot, err := querier.Snapshot()
+			tAssert.NoError(err)
+			tAssert.Equal(sortedAddresses(tc.expectedSequencers), sortedAddresses(snapshot.Sequencers))
+		})
+	}
+}
+
+func sortedAddresses(addrs []types.Address) []types.Address {
+	sorted := make(addresses, len(addrs))
+	copy(sorted, addrs)
+	sort.Stable(sorted)
+
+	return sorted
+}
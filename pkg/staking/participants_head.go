@@ -0,0 +1,38 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// ErrHeadMoved is returned by a querier created with WithStrictHead when the chain head
+// moved while a query made of several contract calls was running.
+var ErrHeadMoved = errors.New("chain head moved during staking query")
+
+// WithStrictHead makes the queries made of several contract calls fail with ErrHeadMoved
+// if the chain head moved while they were running. All the calls of a query always run on top
+// of the same parent header, so the result is consistent either way, but it may be stale.
+// By default the head movement is only logged.
+func WithStrictHead() QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		asq.strictHead = true
+	}
+}
+
+// checkHead checks whether the head is still the parent header the query of the given method ran on top of.
+func (asq *activeParticipantsQuerier) checkHead(parent *types.Header, method string) error {
+	head := asq.head()
+	if head.Hash == parent.Hash {
+		return nil
+	}
+
+	asq.logger.Debug("chain head moved during query", "method", method, "parent_number", parent.Number, "parent_hash", parent.Hash, "head_number", head.Number, "head_hash", head.Hash)
+
+	if asq.strictHead {
+		return fmt.Errorf("%w: %s queried on top of block %d (%s), head is block %d (%s)", ErrHeadMoved, method, parent.Number, parent.Hash, head.Number, head.Hash)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierHeadMovedMidQuery(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+
+	// stakeMidQuery returns a hook that stakes a new sequencer, and so moves the head, once the first query ran.
+	stakeMidQuery := func() func() {
+		queries := 0
+
+		return func() {
+			queries++
+			if queries != 2 {
+				return
+			}
+
+			addr, signKey := test.NewAccount(t)
+			test.DepositBalance(t, addr, balance, blockchain, executor)
+			tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), addr, signKey, stakeAmount, 1_000_000, "test"))
+		}
+	}
+
+	// Both calls of the query run on top of the head captured at the start.
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+	querier.beforeQuery = stakeMidQuery()
+
+	head := blockchain.Header()
+
+	sequencers, err := querier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, sequencers)
+	tAssert.NotEqual(head.Hash, blockchain.Header().Hash)
+
+	strictQuerier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), WithStrictHead()).(*activeParticipantsQuerier)
+	strictQuerier.beforeQuery = stakeMidQuery()
+
+	_, err = strictQuerier.Get(Sequencer)
+	tAssert.True(errors.Is(err, ErrHeadMoved), "unexpected error: %v", err)
+
+	// Without head movement the strict querier succeeds.
+	strictQuerier.beforeQuery = nil
+
+	sequencers, err = strictQuerier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Len(sequencers, 3)
+}
+
+func TestQueryActiveSequencersIgnoresHeadMovement(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, types.BytesToAddress(parent.Miner))
+	tAssert.NoError(err)
+
+	// A sequencer staked after the transition began moves the head, but not the state of the transition.
+	sequencer2, sequencer2SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer2, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer2, sequencer2SignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NotEqual(parent.Hash, blockchain.Header().Hash)
+
+	sequencers, err := QueryActiveSequencers(transition, 1_000_000, types.BytesToAddress(parent.Miner))
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, sequencers)
+}
@@ -0,0 +1,149 @@
+package staking
+
+import (
+	"context"
+	"fmt"
+
+	edge_blockchain "github.com/0xPolygon/polygon-edge/blockchain"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/blockchain"
+)
+
+// BlockEventHandler is implemented by the queriers that keep their cached results in sync with the imported blocks.
+type BlockEventHandler interface {
+	// HandleBlockEvent processes a blockchain event, invalidating the cached results if the
+	// imported blocks have staking activity and carrying them over to the new head otherwise.
+	HandleBlockEvent(ev *edge_blockchain.Event)
+}
+
+// ListenBlockEvents feeds the events of the blockchain subscription to the handler until the context is done.
+func ListenBlockEvents(ctx context.Context, sub blockchain.Subscription, handler BlockEventHandler) {
+	for {
+		select {
+		case ev := <-sub.GetEventCh():
+			if ev != nil {
+				handler.HandleBlockEvent(ev)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// HandleBlockEvent method processes an event of the blockchain subscription.
+// The cached snapshot and governance parameters are dropped when a block with staking activity is imported
+// or the chain reorganizes. Otherwise the staking state didn't change, so they are carried over to the new head
+// and served without querying the contract again.
+func (asq *activeParticipantsQuerier) HandleBlockEvent(ev *edge_blockchain.Event) {
+	if ev.Type == edge_blockchain.EventFork {
+		// The head didn't change.
+		return
+	}
+
+	if len(ev.OldChain) > 0 {
+		asq.logger.Debug("chain reorganized, invalidating cached staking state")
+		asq.invalidateCaches()
+
+		return
+	}
+
+	for _, hdr := range ev.NewChain {
+		active, err := asq.hasStakingActivity(hdr)
+		if err != nil {
+			asq.logger.Error("failed to check block for staking activity, invalidating cached staking state", "block_number", hdr.Number, "error", err)
+			asq.invalidateCaches()
+
+			continue
+		}
+
+		if active {
+			asq.logger.Debug("staking activity in block, invalidating cached staking state", "block_number", hdr.Number)
+			asq.invalidateCaches()
+
+			continue
+		}
+
+		asq.carryCaches(hdr)
+	}
+}
+
+// hasStakingActivity checks whether the block with the given header changed the state of any staking contract,
+// i.e. it has a log emitted by a staking contract or a successful transaction to one. The governance setters
+// of the contract don't emit events, so the transactions are checked as well.
+func (asq *activeParticipantsQuerier) hasStakingActivity(hdr *types.Header) (bool, error) {
+	if asq.blockchain == nil {
+		// Without access to the blocks, every block is assumed to have staking activity.
+		return true, nil
+	}
+
+	// Blocks without transactions can't change the staking state.
+	if hdr.TxRoot == types.EmptyRootHash {
+		return false, nil
+	}
+
+	contracts := map[types.Address]struct{}{}
+	for _, contract := range asq.registry.Contracts() {
+		contracts[contract.Address] = struct{}{}
+	}
+
+	body, ok := asq.blockchain.GetBodyByHash(hdr.Hash)
+	if !ok {
+		return false, fmt.Errorf("body for block %d not found", hdr.Number)
+	}
+
+	receipts, err := asq.blockchain.GetReceiptsByHash(hdr.Hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to get receipts for block %d: %w", hdr.Number, err)
+	}
+
+	if len(receipts) != len(body.Transactions) {
+		return false, fmt.Errorf("block %d has %d transactions but %d receipts", hdr.Number, len(body.Transactions), len(receipts))
+	}
+
+	for i, tx := range body.Transactions {
+		for _, log := range receipts[i].Logs {
+			if _, ok := contracts[log.Address]; ok {
+				return true, nil
+			}
+		}
+
+		if tx.To == nil {
+			continue
+		}
+
+		if _, ok := contracts[*tx.To]; ok && receipts[i].Status != nil && *receipts[i].Status == types.ReceiptSuccess {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// carryCaches moves the cached results on top of the parent of the given header over to the header.
+func (asq *activeParticipantsQuerier) carryCaches(hdr *types.Header) {
+	asq.snapshotLock.Lock()
+	if asq.snapshot != nil && asq.snapshot.BlockHash == hdr.ParentHash {
+		carried := *asq.snapshot
+		carried.BlockNumber = hdr.Number
+		carried.BlockHash = hdr.Hash
+		asq.snapshot = &carried
+	}
+	asq.snapshotLock.Unlock()
+
+	asq.governance.lock.Lock()
+	if asq.governance.params != nil && asq.governance.hash == hdr.ParentHash {
+		asq.governance.hash = hdr.Hash
+	}
+	asq.governance.lock.Unlock()
+}
+
+// invalidateCaches drops the cached results.
+func (asq *activeParticipantsQuerier) invalidateCaches() {
+	asq.snapshotLock.Lock()
+	asq.snapshot = nil
+	asq.snapshotLock.Unlock()
+
+	asq.governance.lock.Lock()
+	asq.governance.params = nil
+	asq.governance.lock.Unlock()
+}
@@ -0,0 +1,108 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	edge_blockchain "github.com/0xPolygon/polygon-edge/blockchain"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/block"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierHandleBlockEvent(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencer, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencer, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	queries := 0
+	querier.beforeQuery = func() { queries++ }
+
+	handleHead := func() {
+		querier.HandleBlockEvent(&edge_blockchain.Event{Type: edge_blockchain.EventHead, NewChain: []*types.Header{blockchain.Header()}})
+	}
+
+	snapshot, err := querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer}, snapshot.Sequencers)
+
+	_, err = querier.GetGovernanceParams()
+	tAssert.NoError(err)
+
+	// The head advances with an empty block and a block with a plain transfer, neither has staking activity.
+	blk, err := block.NewBlockBuilderFactory(blockchain, executor, hclog.Default()).FromBlockchainHead()
+	tAssert.NoError(err)
+
+	blk.SetCoinbaseAddress(sequencer)
+	blk.SignWith(sequencerSignKey)
+
+	fBlock, err := blk.Build()
+	tAssert.NoError(err)
+	tAssert.NoError(blockchain.WriteBlock(fBlock, "test"))
+	handleHead()
+
+	other, otherSignKey := test.NewAccount(t)
+	test.DepositBalance(t, other, balance, blockchain, executor)
+	handleHead()
+
+	queries = 0
+
+	carried, err := querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal(blockchain.Header().Hash, carried.BlockHash)
+	tAssert.Equal(blockchain.Header().Number, carried.BlockNumber)
+	tAssert.Equal(snapshot.Sequencers, carried.Sequencers)
+
+	_, err = querier.GetGovernanceParams()
+	tAssert.NoError(err)
+	tAssert.Equal(0, queries)
+
+	// A staking event invalidates the cached results.
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), other, otherSignKey, stakeAmount, 1_000_000, "test"))
+	handleHead()
+
+	queries = 0
+
+	snapshot, err = querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal(blockchain.Header().Hash, snapshot.BlockHash)
+	tAssert.Len(snapshot.Sequencers, 2)
+	tAssert.NotZero(queries)
+
+	queries = 0
+
+	_, err = querier.GetGovernanceParams()
+	tAssert.NoError(err)
+	tAssert.NotZero(queries)
+}
+
+func TestQuerierHandleBlockEventReorg(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	_, err = querier.Snapshot()
+	tAssert.NoError(err)
+
+	querier.HandleBlockEvent(&edge_blockchain.Event{Type: edge_blockchain.EventFork, NewChain: []*types.Header{blockchain.Header()}})
+	tAssert.NotNil(querier.snapshot)
+
+	querier.HandleBlockEvent(&edge_blockchain.Event{Type: edge_blockchain.EventReorg, OldChain: []*types.Header{blockchain.Header()}, NewChain: []*types.Header{blockchain.Header()}})
+	tAssert.Nil(querier.snapshot)
+}
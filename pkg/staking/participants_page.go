@@ -0,0 +1,131 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// defaultPageSize is the number of addresses fetched per page when a flat address list query runs out of gas.
+const defaultPageSize = 100
+
+// pagedGetter is the paged variant of a flat address list getter of the staking contract.
+type pagedGetter struct {
+	// methodName is the name of the paged getter, taking the offset and the limit of the page.
+	methodName string
+	// arraySlot is the storage slot of the array returned by the getter, read when the contract has no paged getter.
+	arraySlot int64
+}
+
+// pagedGetters maps the flat address list getters of the staking contract to their paged variant.
+var pagedGetters = map[string]pagedGetter{
+	"GetCurrentParticipants":          {"GetCurrentParticipantsPage", participantsSlot},
+	"GetCurrentSequencers":            {"GetCurrentSequencersPage", sequencersSlot},
+	"GetCurrentSequencersInProbation": {"GetCurrentSequencersInProbationPage", sequencersInProbationSlot},
+	"GetCurrentWatchtowers":           {"GetCurrentWatchtowersPage", watchtowersSlot},
+}
+
+// WithPageSize sets the number of addresses fetched per page when a flat address list query
+// runs out of gas and the querier falls back to paginated queries.
+func WithPageSize(size int) QuerierOption {
+	return func(asq *activeParticipantsQuerier) {
+		if size > 0 {
+			asq.pageSize = size
+		}
+	}
+}
+
+// QuerySequencersPage queries a page of the current sequencers, including the ones in probation, from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, the index of the first sequencer and
+// the maximum number of sequencers of the page as parameters.
+// The deployed contract has no paged getters, in that case the page is read from the storage of the sequencers array.
+// It returns the sequencers of the page, empty past the last sequencer, and an error if the operation fails.
+func QuerySequencersPage(t *state.Transition, gasLimit uint64, from types.Address, offset, limit int) ([]types.Address, error) {
+	return DefaultStakingContract().queryAddressesPage(t, gasLimit, from, "GetCurrentSequencers", offset, limit)
+}
+
+// QueryWatchtowersPage queries a page of the current watchtowers from the staking contract.
+// Like QuerySequencersPage, it reads the page from the storage of the watchtowers array if the contract has no paged getters.
+// It returns the watchtowers of the page, empty past the last watchtower, and an error if the operation fails.
+func QueryWatchtowersPage(t *state.Transition, gasLimit uint64, from types.Address, offset, limit int) ([]types.Address, error) {
+	return DefaultStakingContract().queryAddressesPage(t, gasLimit, from, "GetCurrentWatchtowers", offset, limit)
+}
+
+// queryAddressesPage queries a page of the address list returned by the given flat getter.
+// It calls the paged getter if the contract ABI has it, otherwise it reads the page from the storage of the array,
+// which costs no gas.
+func (sc *StakingContract) queryAddressesPage(t *state.Transition, gasLimit uint64, from types.Address, methodName string, offset, limit int) ([]types.Address, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("invalid page with offset %d and limit %d", offset, limit)
+	}
+
+	getter, ok := pagedGetters[methodName]
+	if !ok {
+		return nil, fmt.Errorf("%w: paged %s", ErrUnsupportedByContract, methodName)
+	}
+
+	if _, ok := sc.ABI.Methods[getter.methodName]; ok {
+		return sc.queryAddresses(t, gasLimit, from, getter.methodName, map[string]interface{}{
+			"offset": big.NewInt(int64(offset)),
+			"limit":  big.NewInt(int64(limit)),
+		})
+	}
+
+	length, err := queryArrayLength(t, sc.Address, getter.arraySlot)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset >= length {
+		return []types.Address{}, nil
+	}
+
+	if limit > length-offset {
+		limit = length - offset
+	}
+
+	page := make([]types.Address, 0, limit)
+	for i := offset; i < offset+limit; i++ {
+		page = append(page, types.BytesToAddress(t.GetStorage(sc.Address, arrayElementKey(getter.arraySlot, i)).Bytes()))
+	}
+
+	return page, nil
+}
+
+// queryAddressesPaged queries the address list returned by the given flat getter page by page on top of the parent header,
+// until a page isn't full, and stitches the pages together.
+func (asq *activeParticipantsQuerier) queryAddressesPaged(parent *types.Header, nodeType NodeType, methodName string) ([]types.Address, error) {
+	contract := asq.registry.Contract(nodeType)
+	minerAddress := types.BytesToAddress(parent.Miner)
+
+	toReturn := []types.Address{}
+
+	for offset := 0; ; offset += asq.pageSize {
+		page, err := asq.queryAt(parent, func(t *state.Transition, gasLimit uint64) ([]types.Address, error) {
+			return contract.queryAddressesPage(t, gasLimit, minerAddress, methodName, offset, asq.pageSize)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		toReturn = append(toReturn, page...)
+
+		if len(page) < asq.pageSize {
+			return toReturn, nil
+		}
+	}
+}
+
+// isPaginatable checks whether a failed flat address list query can be retried page by page.
+func isPaginatable(err error, methodName string, inputs map[string]interface{}) bool {
+	if !errors.Is(err, ErrQueryOutOfGas) || inputs != nil {
+		return false
+	}
+
+	_, ok := pagedGetters[methodName]
+
+	return ok
+}
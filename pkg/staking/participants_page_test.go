@@ -0,0 +1,108 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+// pageTestGasLimit covers the intrinsic gas of a query, but not decoding a list of several addresses.
+const pageTestGasLimit = 22_000
+
+func TestQueryParticipantsPaged(t *testing.T) {
+	tAssert := assert.New(t)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+
+	var sequencers, watchtowers []types.Address
+
+	var sequencerStakes, watchtowerStakes []GenesisStake
+
+	for i := 0; i < 4; i++ {
+		addr := types.StringToAddress(fmt.Sprintf("0x10%02d", i))
+		sequencers = append(sequencers, addr)
+		sequencerStakes = append(sequencerStakes, GenesisStake{addr, stakeAmount})
+	}
+
+	for i := 0; i < 6; i++ {
+		addr := types.StringToAddress(fmt.Sprintf("0x20%02d", i))
+		watchtowers = append(watchtowers, addr)
+		watchtowerStakes = append(watchtowerStakes, GenesisStake{addr, stakeAmount})
+	}
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	alloc, err := GenesisStakingAlloc(GenesisStakingParams{
+		Code:               chain.Genesis.Alloc[AddrStakingContract].Code,
+		Sequencers:         sequencerStakes,
+		WatchTowers:        watchtowerStakes,
+		MinNumParticipants: 1,
+		MaxNumParticipants: 20,
+	})
+	tAssert.NoError(err)
+
+	for addr, account := range alloc {
+		chain.Genesis.Alloc[addr] = account
+	}
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, types.ZeroAddress)
+	tAssert.NoError(err)
+
+	// The flat getter can't decode the whole list within the gas limit.
+	_, err = QueryWatchtower(transition, pageTestGasLimit, types.ZeroAddress)
+	tAssert.Error(err)
+	tAssert.True(errors.Is(checkQueryGas(err, pageTestGasLimit), ErrQueryOutOfGas), "unexpected error: %v", err)
+
+	pageCases := []struct {
+		offset   int
+		limit    int
+		expected []types.Address
+	}{
+		{0, 3, watchtowers[0:3]},
+		{3, 3, watchtowers[3:6]},
+		// The page past an exact multiple of the page size is empty.
+		{6, 3, []types.Address{}},
+		{4, 4, watchtowers[4:6]},
+		{10, 4, []types.Address{}},
+	}
+
+	for _, c := range pageCases {
+		page, err := QueryWatchtowersPage(transition, pageTestGasLimit, types.ZeroAddress, c.offset, c.limit)
+		tAssert.NoError(err)
+		tAssert.Equal(c.expected, page, "offset %d, limit %d", c.offset, c.limit)
+	}
+
+	page, err := QuerySequencersPage(transition, pageTestGasLimit, types.ZeroAddress, 1, 2)
+	tAssert.NoError(err)
+	tAssert.Equal(sequencers[1:3], page)
+
+	_, err = QuerySequencersPage(transition, pageTestGasLimit, types.ZeroAddress, 0, 0)
+	tAssert.Error(err)
+
+	// Get stitches the pages together once the flat query runs out of gas, whether the size of the list
+	// is an exact multiple of the page size or not.
+	for _, pageSize := range []int{1, 2, 3, 4, 6, 10} {
+		querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), WithFixedGasLimit(pageTestGasLimit), WithPageSize(pageSize))
+
+		activeWatchtowers, err := querier.Get(WatchTower)
+		tAssert.NoError(err, "page size %d", pageSize)
+		tAssert.Equal(watchtowers, activeWatchtowers, "page size %d", pageSize)
+
+		activeSequencers, err := querier.Get(Sequencer)
+		tAssert.NoError(err, "page size %d", pageSize)
+		tAssert.Equal(sequencers, activeSequencers, "page size %d", pageSize)
+	}
+}
@@ -0,0 +1,142 @@
+package staking
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+const (
+	// maxPooledTransitions is the maximum number of idle transitions kept for the chain head.
+	maxPooledTransitions = 128
+
+	// maxQueriesPerTransition is the number of contract calls a pooled transition runs before it's retired.
+	// The gas pool of the transition is sized for this many calls.
+	maxQueriesPerTransition = 64
+)
+
+// pooledTransition is a transition on top of a parent header that is reused by several queries.
+type pooledTransition struct {
+	transition *state.Transition
+	gasLimit   uint64
+
+	// remaining is the number of contract calls the transition can still run before its gas pool is exhausted.
+	remaining uint64
+}
+
+// transitionPool keeps the idle transitions on top of a single parent header.
+// Every transition is handed out to one query at a time, so concurrent queries
+// never share the same transition. The pool is flushed as soon as a query
+// for a different parent header is made.
+type transitionPool struct {
+	lock sync.Mutex
+	head types.Hash
+	idle []*pooledTransition
+}
+
+// get returns an idle transition on top of the given parent hash that can still run the
+// given number of contract calls, or nil if there's none.
+func (tp *transitionPool) get(parentHash types.Hash, calls uint64) *pooledTransition {
+	tp.lock.Lock()
+	defer tp.lock.Unlock()
+
+	if tp.head != parentHash {
+		tp.head = parentHash
+		tp.idle = nil
+
+		return nil
+	}
+
+	for i := len(tp.idle) - 1; i >= 0; i-- {
+		if pt := tp.idle[i]; pt.remaining >= calls {
+			tp.idle = append(tp.idle[:i], tp.idle[i+1:]...)
+			pt.remaining -= calls
+
+			return pt
+		}
+	}
+
+	return nil
+}
+
+// put returns the transition to the pool, unless it's exhausted, the pool is full
+// or the pool has moved on to another parent hash in the meantime.
+func (tp *transitionPool) put(parentHash types.Hash, pt *pooledTransition) {
+	if pt.remaining == 0 {
+		return
+	}
+
+	tp.lock.Lock()
+	defer tp.lock.Unlock()
+
+	if tp.head != parentHash || len(tp.idle) >= maxPooledTransitions {
+		return
+	}
+
+	tp.idle = append(tp.idle, pt)
+}
+
+// withTxn runs the given query, made of the given number of contract calls, in a transition on top of the parent header.
+// The transition is taken from the pool when possible and any state change made by the
+// query is reverted afterwards, so the transition can be reused by the next query.
+// A transition whose query failed is dropped instead of being returned to the pool.
+func (asq *activeParticipantsQuerier) withTxn(parent *types.Header, calls uint64, query func(t *state.Transition, gasLimit uint64) error) error {
+	if asq.beforeQuery != nil {
+		asq.beforeQuery()
+	}
+
+	pt, err := asq.acquireTxn(parent, calls)
+	if err != nil {
+		return err
+	}
+
+	txn := pt.transition.Txn()
+	snapshot := txn.Snapshot()
+
+	err = query(pt.transition, pt.gasLimit)
+
+	txn.RevertToSnapshot(snapshot)
+
+	if err != nil {
+		return checkQueryGas(err, pt.gasLimit)
+	}
+
+	if asq.pool != nil {
+		asq.pool.put(parent.Hash, pt)
+	}
+
+	return nil
+}
+
+// acquireTxn takes an idle transition on top of the parent header from the pool or begins a new one
+// that can run the given number of contract calls.
+func (asq *activeParticipantsQuerier) acquireTxn(parent *types.Header, calls uint64) (*pooledTransition, error) {
+	queries := calls
+
+	if asq.pool != nil {
+		if pt := asq.pool.get(parent.Hash, calls); pt != nil {
+			return pt, nil
+		}
+
+		if queries < maxQueriesPerTransition {
+			queries = maxQueriesPerTransition
+		}
+	}
+
+	transition, gasLimit, queries, err := asq.beginTxn(parent, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	if queries < calls {
+		return nil, fmt.Errorf("%w (gas limit %d): gas pool too small for %d calls", ErrQueryOutOfGas, gasLimit, calls)
+	}
+
+	return &pooledTransition{
+		transition: transition,
+		gasLimit:   gasLimit,
+		remaining:  queries - calls,
+	}, nil
+}
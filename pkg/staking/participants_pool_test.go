@@ -0,0 +1,129 @@
+package staking
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierConcurrentQueries(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+	unknownAddr := types.StringToAddress("0x1234")
+
+	// The in-memory test storage doesn't support writes concurrent with reads,
+	// so the head is moved in between two rounds of concurrent queries to flush the pool.
+	for round := 0; round < 2; round++ {
+		var wg sync.WaitGroup
+
+		errs := make(chan error, 2*100*maxQueriesPerTransition)
+
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				// Run enough queries to exhaust and retire the pooled transitions.
+				for j := 0; j < maxQueriesPerTransition; j++ {
+					contains, err := querier.Contains(sequencerAddr, Sequencer)
+					if err == nil && !contains {
+						t.Error("sequencer not found")
+					}
+
+					errs <- err
+
+					contains, err = querier.Contains(unknownAddr, Sequencer)
+					if err == nil && contains {
+						t.Error("unknown address found")
+					}
+
+					errs <- err
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			tAssert.NoError(err)
+		}
+
+		otherAddr, _ := test.NewAccount(t)
+		test.DepositBalance(t, otherAddr, balance, blockchain, executor)
+	}
+
+	// The queries must not leave any state change behind in the pooled transitions.
+	stakedBalance, err := querier.GetBalance(sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, stakedBalance)
+
+	sequencers, err := querier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencerAddr}, sequencers)
+}
+
+func BenchmarkConcurrentContains(b *testing.B) {
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	addr := types.StringToAddress("0x1234")
+
+	benchmarks := []struct {
+		name   string
+		pooled bool
+	}{
+		{"fresh transitions", false},
+		{"pooled transitions", true},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.NewNullLogger()).(*activeParticipantsQuerier)
+			if !bm.pooled {
+				querier.pool = nil
+			}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				var wg sync.WaitGroup
+
+				for j := 0; j < 100; j++ {
+					wg.Add(1)
+
+					go func() {
+						defer wg.Done()
+
+						if _, err := querier.Contains(addr, Sequencer); err != nil {
+							b.Error(err)
+						}
+					}()
+				}
+
+				wg.Wait()
+			}
+		})
+	}
+}
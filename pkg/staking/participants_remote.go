@@ -0,0 +1,542 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/0xPolygon/polygon-edge/helper/hex"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/jsonrpc"
+)
+
+// RemoteQuerierOption configures the remote participants querier.
+type RemoteQuerierOption func(*remoteParticipantsQuerier)
+
+// WithBlockTag sets the block the remote participants querier runs its calls against.
+// By default the latest block is used.
+func WithBlockTag(block ethgo.BlockNumber) RemoteQuerierOption {
+	return func(rpq *remoteParticipantsQuerier) {
+		rpq.block = block
+	}
+}
+
+// remoteParticipantsQuerier is an implementation of the ActiveParticipants interface
+// that queries the staking contract through `eth_call` requests against a node's JSON-RPC endpoint.
+type remoteParticipantsQuerier struct {
+	client *jsonrpc.Client
+	block  ethgo.BlockNumber
+	logger hclog.Logger
+
+	snapshotLock sync.Mutex
+	snapshot     *ParticipantsSnapshot
+}
+
+// NewRemoteParticipantsQuerier creates a new instance of remoteParticipantsQuerier.
+// It takes the JSON-RPC URL of a node, a logger and optional configuration options as parameters.
+// It returns the ActiveParticipants interface and an error if the JSON-RPC client can't be created.
+func NewRemoteParticipantsQuerier(rpcURL string, logger hclog.Logger, opts ...RemoteQuerierOption) (ActiveParticipants, error) {
+	client, err := jsonrpc.NewClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rpq := &remoteParticipantsQuerier{
+		client: client,
+		block:  ethgo.Latest,
+		logger: logger.Named("remote_staking_participants_querier"),
+	}
+
+	for _, opt := range opts {
+		opt(rpq)
+	}
+
+	return rpq, nil
+}
+
+// Get method returns the addresses of active participants based on the given node type.
+// It takes the nodeType parameter, which represents the type of node (Sequencer or WatchTower).
+// Only active participants are returned: sequencers in probation are excluded, see GetRegistered for the full set.
+// The sequencers and the sequencers in probation are queried at the same block.
+// It returns a slice of addresses and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) Get(nodeType NodeType) ([]types.Address, error) {
+	block, err := rpq.resolveBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return rpq.get(nodeType, block)
+}
+
+// get returns the addresses of the active participants of the node type at the block.
+func (rpq *remoteParticipantsQuerier) get(nodeType NodeType, block ethgo.BlockNumber) ([]types.Address, error) {
+	switch nodeType {
+	case Sequencer:
+		sequencers, err := rpq.queryAddresses("GetCurrentSequencers", block)
+		if err != nil {
+			rpq.logger.Error("failed to query sequencers", "error", err)
+			return nil, err
+		}
+
+		probationAddrs, err := rpq.queryAddresses("GetCurrentSequencersInProbation", block)
+		if err != nil {
+			rpq.logger.Error("failed to query sequencers in probation", "error", err)
+			return nil, err
+		}
+
+		return excludeAddresses(sequencers, probationAddrs), nil
+	case WatchTower:
+		addrs, err := rpq.queryAddresses("GetCurrentWatchtowers", block)
+		if err != nil {
+			rpq.logger.Error("failed to query watchtowers", "error", err)
+			return nil, err
+		}
+		return addrs, nil
+	default:
+		return nil, fmt.Errorf("failure to query participants due to node type missmatch. '%s' is not node type", nodeType)
+	}
+}
+
+// GetRegistered method returns the addresses of all registered participants of the given node type,
+// including the sequencers in probation.
+// It returns a slice of addresses and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) GetRegistered(nodeType NodeType) ([]types.Address, error) {
+	methodName, ok := participantsGetters[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("failure to query registered participants due to node type missmatch. '%s' is not node type", nodeType)
+	}
+
+	return rpq.queryAddresses(methodName, rpq.block)
+}
+
+// GetAll method returns the active participants of every node type, all queried at the block of the querier.
+// Node types the staking contract ABI doesn't have a getter for are omitted.
+// It returns an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) GetAll() (map[NodeType][]types.Address, error) {
+	block, err := rpq.resolveBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	toReturn := make(map[NodeType][]types.Address, len(participantsGetters))
+
+	for nodeType, methodName := range participantsGetters {
+		var (
+			addrs []types.Address
+			err   error
+		)
+
+		if nodeType == Sequencer {
+			addrs, err = rpq.get(Sequencer, block)
+		} else {
+			addrs, err = rpq.queryAddresses(methodName, block)
+		}
+
+		if errors.Is(err, ErrUnsupportedByContract) {
+			rpq.logger.Debug("staking contract doesn't support node type, omitting it", "node_type", nodeType, "method", methodName)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		toReturn[nodeType] = addrs
+	}
+
+	return toReturn, nil
+}
+
+// Contains method checks if the given address is contained in the active participants list.
+// It takes the addr parameter, which represents the address to check, and the nodeType parameter, which represents the type of node (Sequencer or WatchTower).
+// It returns a boolean value indicating whether the address is found and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) Contains(addr types.Address, nodeType NodeType) (bool, error) {
+	addrs, err := rpq.Get(nodeType)
+	if err != nil {
+		return false, err
+	}
+
+	for _, a := range addrs {
+		if a == addr {
+			return true, nil
+		}
+	}
+
+	rpq.logger.Debug(fmt.Sprintf("Stake not discovered for '%s' as %s.", addr, strings.ToLower(string(nodeType))))
+
+	return false, nil
+}
+
+// InProbation method checks if the given address is in probation.
+// It takes the address parameter, which represents the address to check.
+// It returns a boolean value indicating whether the address is in probation and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) InProbation(address types.Address) (bool, error) {
+	probationAddrs, err := rpq.queryAddresses("GetCurrentSequencersInProbation", rpq.block)
+	if err != nil {
+		return false, err
+	}
+
+	for _, probationAddr := range probationAddrs {
+		if probationAddr == address {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// InProbationBatch method checks the probation status of all the given addresses with a single call.
+// No call is made when no address is given.
+// It returns a map of every given address to whether it is in probation and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) InProbationBatch(addrs []types.Address) (map[types.Address]bool, error) {
+	if len(addrs) == 0 {
+		return map[types.Address]bool{}, nil
+	}
+
+	probationAddrs, err := rpq.queryAddresses("GetCurrentSequencersInProbation", rpq.block)
+	if err != nil {
+		return nil, err
+	}
+
+	return probationMembership(addrs, probationAddrs), nil
+}
+
+// IsSlashed method checks if the given address has been slashed.
+// The remote querier can't scan the blocks of the node, so it needs a contract that keeps the slashed participants.
+// It returns an error wrapping ErrUnsupportedByContract if the contract doesn't keep them.
+func (rpq *remoteParticipantsQuerier) IsSlashed(addr types.Address) (bool, error) {
+	slashed, err := rpq.queryAddresses("GetSlashedParticipants", rpq.block)
+	if err != nil {
+		return false, err
+	}
+
+	return containsAddress(slashed, addr), nil
+}
+
+// GetInactiveSequencers method returns the active sequencers whose last activity is at least threshold blocks
+// older than the block the querier is bound to, in the order returned by Get.
+// The remote querier can't scan the blocks of the node, so it needs a contract that tracks the activity.
+// It returns an error wrapping ErrUnsupportedByContract if the contract doesn't track it.
+func (rpq *remoteParticipantsQuerier) GetInactiveSequencers(threshold uint64) ([]types.Address, error) {
+	if _, ok := stakingMethod("GetLastBlockProduced"); !ok {
+		return nil, fmt.Errorf("%w: GetLastBlockProduced", ErrUnsupportedByContract)
+	}
+
+	blk, err := rpq.headBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sequencers, err := rpq.get(Sequencer, ethgo.BlockNumber(blk.Number))
+	if err != nil {
+		return nil, err
+	}
+
+	if threshold == 0 || len(sequencers) == 0 {
+		return nil, nil
+	}
+
+	var inactive []types.Address
+
+	for _, sequencer := range sequencers {
+		last, err := rpq.queryAmount("GetLastBlockProduced", ethgo.BlockNumber(blk.Number), map[string]interface{}{"sequencerAddr": ethgo.Address(sequencer)})
+		if err != nil {
+			return nil, err
+		}
+
+		if !last.IsUint64() {
+			return nil, fmt.Errorf("last block produced by %s out of range: %s", sequencer, last)
+		}
+
+		if isInactive(blk.Number, last.Uint64(), threshold) {
+			inactive = append(inactive, sequencer)
+		}
+	}
+
+	return inactive, nil
+}
+
+// GetBalance method retrieves the staked amount of the given address.
+// It takes the address parameter, which represents the address to query.
+// It returns the balance as a big.Int value, or ErrNotAParticipant if the address has never staked,
+// and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) GetBalance(address types.Address) (*big.Int, error) {
+	block, err := rpq.resolveBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return rpq.balance(address, block)
+}
+
+// balance returns the staked amount of the address at the block, or ErrNotAParticipant if it has never staked.
+func (rpq *remoteParticipantsQuerier) balance(address types.Address, block ethgo.BlockNumber) (*big.Int, error) {
+	registered, err := rpq.queryAmount("_addressToIsParticipant", block, map[string]interface{}{"0": ethgo.Address(address)})
+	if err != nil {
+		return nil, err
+	}
+
+	if registered.Sign() == 0 {
+		return nil, ErrNotAParticipant
+	}
+
+	return rpq.queryAmount("GetCurrentAccountStakedAmount", block, map[string]interface{}{"addr": ethgo.Address(address)})
+}
+
+// GetTotalStakedAmount method retrieves the total staked amount in the system.
+// It returns the total staked amount as a big.Int value and an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) GetTotalStakedAmount() (*big.Int, error) {
+	return rpq.queryAmount("GetCurrentStakedAmount", rpq.block, nil)
+}
+
+// GetTotalStakedAmountByType method retrieves the total staked amount for the given node type.
+// The remote querier talks to a single staking contract, so it returns the same amount as GetTotalStakedAmount.
+func (rpq *remoteParticipantsQuerier) GetTotalStakedAmountByType(_ NodeType) (*big.Int, error) {
+	return rpq.GetTotalStakedAmount()
+}
+
+// GetStakeRequirement method retrieves the minimum stake required to stake as the given node type.
+// If the contract only has a single global threshold, it returns the global threshold together with
+// an error wrapping ErrSingleStakeThreshold.
+func (rpq *remoteParticipantsQuerier) GetStakeRequirement(nodeType NodeType) (*big.Int, error) {
+	methodName, ok := stakeRequirementMethods[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type: %q", nodeType)
+	}
+
+	requirement, err := rpq.queryAmount(methodName, rpq.block, nil)
+	if !errors.Is(err, ErrUnsupportedByContract) {
+		return requirement, err
+	}
+
+	threshold, err := rpq.queryAmount("GetCurrentStakingThreshold", rpq.block, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return threshold, ErrSingleStakeThreshold
+}
+
+// GetDelegationInfo method retrieves own stake, delegated stake and the list of delegators for the given address.
+// All values are queried at the same block.
+// It returns an error wrapping ErrUnsupportedByContract if the contract doesn't support delegation.
+func (rpq *remoteParticipantsQuerier) GetDelegationInfo(address types.Address) (*DelegationInfo, error) {
+	block, err := rpq.resolveBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ownStake, err := rpq.balance(address, block)
+	if err != nil {
+		return nil, err
+	}
+
+	delegatedStake, err := rpq.queryAmount("GetDelegatedAmount", block, map[string]interface{}{"sequencerAddr": ethgo.Address(address)})
+	if err != nil {
+		return nil, err
+	}
+
+	delegators, err := rpq.queryAddresses("GetDelegators", block, map[string]interface{}{"sequencerAddr": ethgo.Address(address)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DelegationInfo{
+		OwnStake:       ownStake,
+		DelegatedStake: delegatedStake,
+		Delegators:     delegators,
+	}, nil
+}
+
+// GetWatchtowerBond method returns the bond of the watchtower and the part of it at risk in its open disputes.
+// All calls are made at the same block, the one the querier is bound to.
+// It returns an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) GetWatchtowerBond(addr types.Address) (*WatchtowerBond, error) {
+	block, err := rpq.resolveBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return queryWatchtowerBond(func(methodName string, inputs map[string]interface{}) (*abi.Method, []byte, error) {
+		return rpq.call(methodName, block, inputs)
+	}, addr)
+}
+
+// Snapshot method returns a consistent view of the staking participants at the configured block.
+// All calls are pinned to the number of the resolved block, and the snapshot is cached per block hash.
+// It returns an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) Snapshot() (*ParticipantsSnapshot, error) {
+	blk, err := rpq.headBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	rpq.snapshotLock.Lock()
+	defer rpq.snapshotLock.Unlock()
+
+	if rpq.snapshot != nil && rpq.snapshot.BlockHash == types.Hash(blk.Hash) {
+		return rpq.snapshot, nil
+	}
+
+	block := ethgo.BlockNumber(blk.Number)
+
+	sequencers, err := rpq.queryAddresses("GetCurrentSequencers", block)
+	if err != nil {
+		return nil, err
+	}
+
+	probationAddrs, err := rpq.queryAddresses("GetCurrentSequencersInProbation", block)
+	if err != nil {
+		return nil, err
+	}
+
+	watchtowers, err := rpq.queryAddresses("GetCurrentWatchtowers", block)
+	if err != nil {
+		return nil, err
+	}
+
+	slashed, err := rpq.queryAddresses("GetSlashedParticipants", block)
+	switch {
+	case errors.Is(err, ErrUnsupportedByContract):
+		rpq.logger.Warn("staking contract doesn't keep the slashed participants, leaving them out of the snapshot", "error", err)
+	case err != nil:
+		return nil, err
+	}
+
+	totalStake, err := rpq.queryAmount("GetCurrentStakedAmount", block, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rpq.snapshot = &ParticipantsSnapshot{
+		BlockNumber:           blk.Number,
+		BlockHash:             types.Hash(blk.Hash),
+		Sequencers:            excludeAddresses(sequencers, probationAddrs),
+		RegisteredSequencers:  sequencers,
+		SequencersInProbation: probationAddrs,
+		WatchTowers:           watchtowers,
+		Slashed:               slashed,
+		TotalStake:            totalStake,
+	}
+
+	return rpq.snapshot, nil
+}
+
+// HasQuorum method checks whether the number of active sequencers (excluding the ones in probation)
+// is at least the given minimum, based on the consistent participants snapshot.
+// It returns an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) HasQuorum(minimum int) (bool, error) {
+	snapshot, err := rpq.Snapshot()
+	if err != nil {
+		return false, err
+	}
+
+	return len(snapshot.Sequencers) >= minimum, nil
+}
+
+// Count method returns the number of active participants of the given node type.
+// Storage reads aren't part of the calls the remote querier makes, so it counts the addresses returned by Get.
+// It returns an error if the operation fails.
+func (rpq *remoteParticipantsQuerier) Count(nodeType NodeType) (int, error) {
+	addrs, err := rpq.Get(nodeType)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(addrs), nil
+}
+
+// headBlock returns the block the querier is bound to.
+func (rpq *remoteParticipantsQuerier) headBlock() (*ethgo.Block, error) {
+	blk, err := rpq.client.Eth().GetBlockByNumber(rpq.block, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if blk == nil {
+		return nil, fmt.Errorf("block '%s' not found", rpq.block)
+	}
+
+	return blk, nil
+}
+
+// resolveBlock returns the number of the block the querier is bound to, so the calls of a query all run against
+// the same block, even when a new block is produced in between. A pinned block number is returned as is.
+func (rpq *remoteParticipantsQuerier) resolveBlock() (ethgo.BlockNumber, error) {
+	if rpq.block >= 0 {
+		return rpq.block, nil
+	}
+
+	blk, err := rpq.headBlock()
+	if err != nil {
+		return 0, err
+	}
+
+	return ethgo.BlockNumber(blk.Number), nil
+}
+
+// queryAddresses calls an address list returning staking contract method and decodes the result
+// with DecodeParticipants.
+func (rpq *remoteParticipantsQuerier) queryAddresses(methodName string, block ethgo.BlockNumber, inputs ...map[string]interface{}) ([]types.Address, error) {
+	method, returnValue, err := rpq.call(methodName, block, inputs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeParticipants(method, returnValue)
+}
+
+// queryAmount calls an amount returning staking contract method and decodes the result as a big.Int value.
+func (rpq *remoteParticipantsQuerier) queryAmount(methodName string, block ethgo.BlockNumber, inputs map[string]interface{}) (*big.Int, error) {
+	_, returnValue, err := rpq.call(methodName, block, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(returnValue), nil
+}
+
+// call encodes the given staking contract method call and executes it with `eth_call` at the given block.
+func (rpq *remoteParticipantsQuerier) call(methodName string, block ethgo.BlockNumber, inputs ...map[string]interface{}) (*abi.Method, []byte, error) {
+	method, ok := stakingMethod(methodName)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedByContract, methodName)
+	}
+
+	input := method.ID()
+
+	if len(inputs) > 0 && inputs[0] != nil {
+		encodedInput, err := method.Inputs.Encode(inputs[0])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		input = append(input, encodedInput...)
+	}
+
+	to := ethgo.Address(AddrStakingContract)
+
+	res, err := rpq.client.Eth().Call(&ethgo.CallMsg{
+		To:   &to,
+		Data: input,
+	}, block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	returnValue, err := hex.DecodeHex(res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(returnValue) == 0 {
+		return nil, nil, errors.New("empty response from the staking contract")
+	}
+
+	return method, returnValue, nil
+}
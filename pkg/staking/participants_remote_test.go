@@ -0,0 +1,212 @@
+package staking
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/helper/hex"
+	"github.com/0xPolygon/polygon-edge/types"
+	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// newCannedStakingServer returns a JSON-RPC test server that answers `eth_call` requests
+// with the canned return values keyed by the staking contract method name.
+func newCannedStakingServer(t *testing.T, responses map[string]interface{}, blockTags *[]string) *httptest.Server {
+	t.Helper()
+
+	stakingABI := abi.MustNewABI(staking_contract.StakingABI)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{}       `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %s", err)
+			return
+		}
+
+		var result interface{}
+
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			blk, err := json.Marshal(&ethgo.Block{
+				Number:     7,
+				Hash:       ethgo.HexToHash("0x07"),
+				Difficulty: big.NewInt(0),
+			})
+			if err != nil {
+				t.Errorf("failed to encode block: %s", err)
+				return
+			}
+
+			result = json.RawMessage(blk)
+		case "eth_call":
+			var msg struct {
+				Data string `json:"data"`
+			}
+
+			var tag string
+
+			if err := json.Unmarshal(req.Params[0], &msg); err != nil {
+				t.Errorf("failed to decode call message: %s", err)
+				return
+			}
+
+			if err := json.Unmarshal(req.Params[1], &tag); err != nil {
+				t.Errorf("failed to decode block tag: %s", err)
+				return
+			}
+
+			if blockTags != nil {
+				*blockTags = append(*blockTags, tag)
+			}
+
+			data, _ := hex.DecodeHex(msg.Data)
+			var method *abi.Method
+
+			for _, m := range stakingABI.Methods {
+				if string(m.ID()) == string(data[:4]) {
+					method = m
+				}
+			}
+
+			if method == nil {
+				t.Errorf("unknown selector %x", data[:4])
+				return
+			}
+
+			var encoded []byte
+
+			switch v := responses[method.Name].(type) {
+			case *big.Int:
+				encoded = ethgo.Hash(types.BytesToHash(v.Bytes())).Bytes()
+			case []ethgo.Address:
+				var err error
+
+				encoded, err = method.Outputs.Encode([]interface{}{v})
+				if err != nil {
+					t.Errorf("failed to encode response: %s", err)
+					return
+				}
+			default:
+				t.Errorf("no canned response for %s", method.Name)
+				return
+			}
+
+			result = hex.EncodeToHex(encoded)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		})
+	}))
+}
+
+func TestRemoteParticipantsQuerier(t *testing.T) {
+	sequencer1 := types.StringToAddress("0x1")
+	sequencer2 := types.StringToAddress("0x2")
+	watchtower := types.StringToAddress("0x3")
+
+	responses := map[string]interface{}{
+		"GetCurrentSequencers":            []ethgo.Address{ethgo.Address(sequencer1), ethgo.Address(sequencer2)},
+		"GetCurrentSequencersInProbation": []ethgo.Address{ethgo.Address(sequencer2)},
+		"GetCurrentWatchtowers":           []ethgo.Address{ethgo.Address(watchtower)},
+		"GetCurrentAccountStakedAmount":   big.NewInt(10),
+		"GetCurrentStakedAmount":          big.NewInt(30),
+		"GetCurrentStakingThreshold":      big.NewInt(5),
+		"_addressToIsParticipant":         big.NewInt(1),
+	}
+
+	// The queries made of several calls resolve the latest block once, and make all their calls at its number.
+	testCases := []struct {
+		name        string
+		opts        []RemoteQuerierOption
+		expectedTag string
+		resolvedTag string
+	}{
+		{"latest block", nil, "latest", "0x7"},
+		{"pinned block", []RemoteQuerierOption{WithBlockTag(ethgo.BlockNumber(5))}, "0x5", "0x5"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			tAssert := assert.New(t)
+
+			tags := []string{}
+			srv := newCannedStakingServer(t, responses, &tags)
+			defer srv.Close()
+
+			querier, err := NewRemoteParticipantsQuerier(srv.URL, hclog.NewNullLogger(), tc.opts...)
+			tAssert.NoError(err)
+
+			sequencers, err := querier.Get(Sequencer)
+			tAssert.NoError(err)
+			tAssert.Equal([]types.Address{sequencer1}, sequencers)
+			tAssert.Equal([]string{tc.resolvedTag, tc.resolvedTag}, tags)
+
+			registered, err := querier.GetRegistered(Sequencer)
+			tAssert.NoError(err)
+			tAssert.Equal([]types.Address{sequencer1, sequencer2}, registered)
+
+			watchtowers, err := querier.Get(WatchTower)
+			tAssert.NoError(err)
+			tAssert.Equal([]types.Address{watchtower}, watchtowers)
+
+			all, err := querier.GetAll()
+			tAssert.NoError(err)
+			tAssert.Equal(map[NodeType][]types.Address{Sequencer: {sequencer1}, WatchTower: {watchtower}}, all)
+
+			contains, err := querier.Contains(sequencer2, Sequencer)
+			tAssert.NoError(err)
+			tAssert.False(contains)
+
+			inProbation, err := querier.InProbation(sequencer2)
+			tAssert.NoError(err)
+			tAssert.True(inProbation)
+
+			probation, err := querier.InProbationBatch([]types.Address{sequencer1, sequencer2})
+			tAssert.NoError(err)
+			tAssert.Equal(map[types.Address]bool{sequencer1: false, sequencer2: true}, probation)
+
+			balance, err := querier.GetBalance(sequencer1)
+			tAssert.NoError(err)
+			tAssert.Equal(big.NewInt(10), balance)
+
+			total, err := querier.GetTotalStakedAmount()
+			tAssert.NoError(err)
+			tAssert.Equal(big.NewInt(30), total)
+
+			requirement, err := querier.GetStakeRequirement(WatchTower)
+			tAssert.True(errors.Is(err, ErrSingleStakeThreshold), "unexpected error: %v", err)
+			tAssert.Equal(big.NewInt(5), requirement)
+
+			for _, tag := range tags {
+				tAssert.Contains([]string{tc.expectedTag, tc.resolvedTag}, tag)
+			}
+
+			hasQuorum, err := querier.HasQuorum(2)
+			tAssert.NoError(err)
+			tAssert.False(hasQuorum)
+
+			snapshot, err := querier.Snapshot()
+			tAssert.NoError(err)
+			tAssert.Equal(uint64(7), snapshot.BlockNumber)
+			tAssert.Equal([]types.Address{sequencer2}, snapshot.SequencersInProbation)
+			tAssert.Equal([]types.Address{sequencer1, sequencer2}, snapshot.RegisteredSequencers)
+		})
+	}
+}
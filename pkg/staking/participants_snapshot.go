@@ -0,0 +1,135 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// ParticipantsSnapshot is a consistent view of the staking participants,
+// queried from a single transition on top of one chain head.
+type ParticipantsSnapshot struct {
+	// BlockNumber is the number of the head block the snapshot was taken at.
+	BlockNumber uint64
+	// BlockHash is the hash of the head block the snapshot was taken at.
+	BlockHash types.Hash
+	// Sequencers are the active sequencers, i.e. the ones not in probation.
+	Sequencers []types.Address
+	// RegisteredSequencers are all the registered sequencers, including the ones in probation.
+	// They come from the same contract call as Sequencers.
+	RegisteredSequencers []types.Address
+	// SequencersInProbation are the sequencers currently in probation.
+	SequencersInProbation []types.Address
+	// WatchTowers are the active watchtowers.
+	WatchTowers []types.Address
+	// Slashed are the participants that have been slashed, nil if the slashed participants can't be read.
+	Slashed []types.Address
+	// TotalStake is the total staked amount, as returned by GetTotalStakedAmount at the block of the snapshot.
+	TotalStake *big.Int
+}
+
+// Snapshot method returns a consistent view of the staking participants at the current chain head.
+// The snapshot is cached per head hash, so all callers observe the same result until a new block is written.
+// When the querier is fed the block events, the snapshot is carried over blocks without staking activity.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) Snapshot() (*ParticipantsSnapshot, error) {
+	parent := asq.head()
+
+	asq.snapshotLock.Lock()
+	defer asq.snapshotLock.Unlock()
+
+	if asq.snapshot != nil && asq.snapshot.BlockHash == parent.Hash {
+		return asq.snapshot, nil
+	}
+
+	// Every query consumes the gas pool of its transition, so each one gets its own
+	// transition; all of them are started from the same parent state root.
+	sequencers, err := asq.queryAddresses(parent, Sequencer, "GetCurrentSequencers", nil)
+	if err != nil {
+		asq.logger.Error("failed to query sequencers", "error", err)
+		return nil, err
+	}
+
+	probationAddrs, err := asq.queryProbation(parent)
+	if err != nil {
+		asq.logger.Error("failed to query sequencers in probation", "error", err)
+		return nil, err
+	}
+
+	watchtowers, err := asq.queryAddresses(parent, WatchTower, "GetCurrentWatchtowers", nil)
+	if err != nil {
+		asq.logger.Error("failed to query watchtowers", "error", err)
+		return nil, err
+	}
+
+	slashed, err := asq.querySlashed(parent)
+	switch {
+	case errors.Is(err, ErrUnsupportedByContract):
+		asq.logger.Warn("slashed participants can't be read, leaving them out of the snapshot", "error", err)
+	case err != nil:
+		asq.logger.Error("failed to query slashed participants", "error", err)
+		return nil, err
+	}
+
+	totalStake, err := asq.queryTotalStake(parent)
+	if err != nil {
+		asq.logger.Error("failed to query total stake", "error", err)
+		return nil, err
+	}
+
+	asq.snapshot = &ParticipantsSnapshot{
+		BlockNumber:           parent.Number,
+		BlockHash:             parent.Hash,
+		Sequencers:            asq.filterParticipants(Sequencer, excludeAddresses(sequencers, probationAddrs)),
+		RegisteredSequencers:  sequencers,
+		SequencersInProbation: probationAddrs,
+		WatchTowers:           asq.filterParticipants(WatchTower, watchtowers),
+		Slashed:               slashed,
+		TotalStake:            totalStake,
+	}
+
+	return asq.snapshot, nil
+}
+
+// HasQuorum method checks whether the number of active sequencers (excluding the ones in probation)
+// is at least the given minimum. The check is based on the consistent participants snapshot, so the
+// result doesn't change within one chain head.
+// It returns an error if the operation fails.
+func (asq *activeParticipantsQuerier) HasQuorum(minimum int) (bool, error) {
+	snapshot, err := asq.Snapshot()
+	if err != nil {
+		return false, err
+	}
+
+	return len(snapshot.Sequencers) >= minimum, nil
+}
+
+// queryAt runs the given address query in a transition on top of the parent header.
+func (asq *activeParticipantsQuerier) queryAt(parent *types.Header, query func(t *state.Transition, gasLimit uint64) ([]types.Address, error)) (addrs []types.Address, err error) {
+	err = asq.withTxn(parent, 1, func(t *state.Transition, gasLimit uint64) error {
+		addrs, err = query(t, gasLimit)
+		return err
+	})
+
+	return addrs, err
+}
+
+// excludeAddresses returns the addresses from addrs that are not present in excluded, preserving order.
+func excludeAddresses(addrs []types.Address, excluded []types.Address) []types.Address {
+	toReturn := []types.Address{}
+
+mainLoop:
+	for _, addr := range addrs {
+		for _, e := range excluded {
+			if addr == e {
+				continue mainLoop
+			}
+		}
+
+		toReturn = append(toReturn, addr)
+	}
+
+	return toReturn
+}
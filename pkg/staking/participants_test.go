@@ -0,0 +1,417 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+func TestQuerierGasLimitOptions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        []QuerierOption
+		expectedErr error
+	}{
+		{"default gas limit", nil, nil},
+		{"gas limit from parent", []QuerierOption{WithGasLimitFromParent()}, nil},
+		{"fixed gas limit above block gas limit", []QuerierOption{WithFixedGasLimit(100_000_000)}, nil},
+		{"fixed gas limit too low for the call", []QuerierOption{WithFixedGasLimit(21_100)}, ErrQueryOutOfGas},
+		{"fixed gas limit too low for intrinsic gas", []QuerierOption{WithFixedGasLimit(1_000)}, ErrQueryOutOfGas},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			tAssert := assert.New(t)
+
+			executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+			tAssert.NoError(err)
+
+			addr, _ := test.NewAccount(t)
+			querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), tc.opts...)
+
+			// Address lists that run out of gas are queried page by page from the storage of the arrays,
+			// which costs no gas.
+			_, err = querier.Get(Sequencer)
+			tAssert.NoError(err)
+
+			_, err = querier.InProbation(addr)
+			tAssert.NoError(err)
+
+			// The address has never staked, so a query with enough gas reports it as unknown.
+			expectedBalanceErr := tc.expectedErr
+			if expectedBalanceErr == nil {
+				expectedBalanceErr = ErrNotAParticipant
+			}
+
+			_, err = querier.GetBalance(addr)
+			tAssert.True(errors.Is(err, expectedBalanceErr), "unexpected error: %v", err)
+
+			_, err = querier.GetTotalStakedAmount()
+			tAssert.True(errors.Is(err, tc.expectedErr), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestQuerierGetBalance(t *testing.T) {
+	tAssert := assert.New(t)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	zeroStakeAddr := types.StringToAddress("0x1234")
+	unknownAddr := types.StringToAddress("0x5678")
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	// Register a participant without any stake by setting its `_addressToIsParticipant`
+	// mapping entry directly in the genesis storage of the staking contract.
+
+	stakingAlloc := *chain.Genesis.Alloc[AddrStakingContract]
+	stakingAlloc.Storage = map[types.Hash]types.Hash{}
+
+	for k, v := range chain.Genesis.Alloc[AddrStakingContract].Storage {
+		stakingAlloc.Storage[k] = v
+	}
+
+	stakingAlloc.Storage[mappingKey(addressToIsParticipantSlot, zeroStakeAddr)] = types.BytesToHash([]byte{1})
+	chain.Genesis.Alloc[AddrStakingContract] = &stakingAlloc
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	testCases := []struct {
+		name            string
+		addr            types.Address
+		expectedBalance *big.Int
+		expectedErr     error
+	}{
+		{"staked", sequencerAddr, stakeAmount, nil},
+		{"registered with zero stake", zeroStakeAddr, big.NewInt(0), nil},
+		{"unknown", unknownAddr, nil, ErrNotAParticipant},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			tAssert := assert.New(t)
+
+			balance, err := querier.GetBalance(tc.addr)
+			tAssert.True(errors.Is(err, tc.expectedErr), "unexpected error: %v", err)
+
+			if tc.expectedBalance == nil {
+				tAssert.Nil(balance)
+			} else {
+				tAssert.Equal(0, tc.expectedBalance.Cmp(balance), "unexpected balance: %v", balance)
+			}
+		})
+	}
+}
+
+func TestQueryParticipantBalanceAddressEncoding(t *testing.T) {
+	tAssert := assert.New(t)
+
+	addrs := []types.Address{
+		types.StringToAddress("0x8ba1f109551bD432803012645Ac136ddd64DBA72"),
+		// Leading and trailing zero bytes must survive the encoding.
+		types.StringToAddress("0x00000000000000000000000000000000000000ab"),
+		types.StringToAddress("0xab00000000000000000000000000000000000000"),
+	}
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	// Seed a known staked amount for every address in the `_addressToStakedAmount` mapping.
+	stakingAlloc := *chain.Genesis.Alloc[AddrStakingContract]
+	stakingAlloc.Storage = map[types.Hash]types.Hash{}
+
+	for k, v := range chain.Genesis.Alloc[AddrStakingContract].Storage {
+		stakingAlloc.Storage[k] = v
+	}
+
+	amounts := map[types.Address]*big.Int{}
+	for i, addr := range addrs {
+		amounts[addr] = big.NewInt(int64(i+1) * 1_000)
+		stakingAlloc.Storage[mappingKey(addressToStakedAmountSlot, addr)] = types.BytesToHash(amounts[addr].Bytes())
+	}
+
+	chain.Genesis.Alloc[AddrStakingContract] = &stakingAlloc
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	method := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentAccountStakedAmount"]
+
+	for _, addr := range addrs {
+		encoded, err := method.Inputs.Encode(map[string]interface{}{"addr": ethgo.Address(addr)})
+		tAssert.NoError(err)
+		tAssert.Len(encoded, 32)
+		tAssert.Equal(addr.Bytes(), encoded[12:])
+
+		decoded, err := method.Inputs.Decode(encoded)
+		tAssert.NoError(err)
+		tAssert.Equal(ethgo.Address(addr), decoded.(map[string]interface{})["addr"])
+
+		head := blockchain.Header()
+		transition, err := executor.BeginTxn(head.StateRoot, head, types.ZeroAddress)
+		tAssert.NoError(err)
+
+		tAssert.Equal(types.BytesToHash(amounts[addr].Bytes()), transition.GetStorage(AddrStakingContract, mappingKey(addressToStakedAmountSlot, addr)))
+
+		balance, err := QueryParticipantBalance(transition, 1_000_000, types.ZeroAddress, addr)
+		tAssert.NoError(err)
+		tAssert.Equal(0, amounts[addr].Cmp(balance), "unexpected balance of %s: %v", addr, balance)
+	}
+}
+
+func TestQuerierInProbationBatch(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	maliciousAddr, maliciousSignKey := test.NewAccount(t)
+	test.DepositBalance(t, maliciousAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), maliciousAddr, maliciousSignKey, stakeAmount, 1_000_000, "test"))
+
+	tAssert.NoError(NewDisputeResolution(blockchain, executor, sender, hclog.Default()).Begin(maliciousAddr, watchtowerSignKey))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	queries := 0
+	querier.beforeQuery = func() { queries++ }
+
+	probation, err := querier.InProbationBatch([]types.Address{sequencerAddr, maliciousAddr, watchtowerAddr, maliciousAddr})
+	tAssert.NoError(err)
+	tAssert.Equal(map[types.Address]bool{sequencerAddr: false, maliciousAddr: true, watchtowerAddr: false}, probation)
+	tAssert.Equal(1, queries)
+
+	for addr, inProbation := range probation {
+		expected, err := querier.InProbation(addr)
+		tAssert.NoError(err)
+		tAssert.Equal(expected, inProbation)
+	}
+
+	queries = 0
+
+	probation, err = querier.InProbationBatch(nil)
+	tAssert.NoError(err)
+	tAssert.Empty(probation)
+	tAssert.NotNil(probation)
+	tAssert.Equal(0, queries)
+}
+
+func TestQuerierGetRegistered(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+
+	sequencer2, sequencer2SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer2, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencer2, sequencer2SignKey, stakeAmount, 1_000_000, "test"))
+
+	watchtower, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtower, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtower, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+
+	// The disputed sequencer is put in probation.
+	tAssert.NoError(NewDisputeResolution(blockchain, executor, sender, hclog.Default()).Begin(sequencer2, watchtowerSignKey))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	active, err := querier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, active)
+
+	registered, err := querier.GetRegistered(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2}, registered)
+
+	watchtowers, err := querier.GetRegistered(WatchTower)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{watchtower}, watchtowers)
+
+	snapshot, err := querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, snapshot.Sequencers)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2}, snapshot.RegisteredSequencers)
+	tAssert.Equal([]types.Address{sequencer2}, snapshot.SequencersInProbation)
+
+	totalStake, err := querier.GetTotalStakedAmount()
+	tAssert.NoError(err)
+	tAssert.Equal(totalStake, snapshot.TotalStake)
+
+	_, err = querier.GetRegistered(NodeType("unknown"))
+	tAssert.Error(err)
+}
+
+func TestQueryInterleavedWithMinerTransactions(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	miner, _ := test.NewAccount(t)
+	test.DepositBalance(t, miner, balance, blockchain, executor)
+
+	receiver, _ := test.NewAccount(t)
+
+	// The queries run on the transition of the block being built, with the miner as the sender.
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, miner)
+	tAssert.NoError(err)
+
+	transfer := func() error {
+		return transition.Write(&types.Transaction{
+			From:     miner,
+			To:       &receiver,
+			Value:    big.NewInt(1),
+			GasPrice: big.NewInt(0),
+			Gas:      21_000,
+			Nonce:    transition.GetNonce(miner),
+		})
+	}
+
+	nonce := transition.GetNonce(miner)
+
+	tAssert.NoError(transfer())
+
+	_, err = QuerySequencers(transition, 1_000_000, miner)
+	tAssert.NoError(err)
+
+	_, err = GetThresholdTx(transition, 1_000_000, miner)
+	tAssert.NoError(err)
+
+	tAssert.Equal(nonce+1, transition.GetNonce(miner))
+
+	// The nonce of the next miner transaction, built before the queries ran, is still valid.
+	next := &types.Transaction{
+		From:     miner,
+		To:       &receiver,
+		Value:    big.NewInt(1),
+		GasPrice: big.NewInt(0),
+		Gas:      21_000,
+		Nonce:    nonce + 1,
+	}
+
+	_, err = QueryParticipants(transition, 1_000_000, miner)
+	tAssert.NoError(err)
+
+	tAssert.NoError(transition.Write(next))
+	tAssert.Equal(nonce+2, transition.GetNonce(miner))
+	tAssert.Len(transition.Receipts(), 2)
+	tAssert.Equal(big.NewInt(2), transition.GetBalance(receiver))
+}
+
+func TestQueryLeavesNoTraceInBlockBeingBuilt(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	miner, _ := test.NewAccount(t)
+	test.DepositBalance(t, miner, big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH), blockchain, executor)
+
+	receiver, _ := test.NewAccount(t)
+
+	// The block being built only fits two transfers, far less than the gas of a single query.
+	parent := blockchain.Header()
+	header := &types.Header{
+		ParentHash: parent.Hash,
+		Number:     parent.Number + 1,
+		Miner:      miner.Bytes(),
+		GasLimit:   2 * 21_000,
+		Timestamp:  parent.Timestamp + 1,
+	}
+
+	transition, err := executor.BeginTxn(parent.StateRoot, header, miner)
+	tAssert.NoError(err)
+
+	hooks := 0
+	transition.PostHook = func(_ *state.Transition) { hooks++ }
+
+	transfer := func(nonce uint64) error {
+		return transition.Write(&types.Transaction{
+			From:     miner,
+			To:       &receiver,
+			Value:    big.NewInt(1),
+			GasPrice: big.NewInt(0),
+			Gas:      21_000,
+			Nonce:    nonce,
+		})
+	}
+
+	tAssert.NoError(transfer(0))
+
+	method, ok := stakingMethod("GetCurrentSequencers")
+	tAssert.True(ok)
+
+	query := &types.Transaction{
+		From:     miner,
+		To:       &AddrStakingContract,
+		Value:    big.NewInt(0),
+		Input:    method.ID(),
+		GasPrice: big.NewInt(0),
+		Gas:      1_000_000,
+		Nonce:    42,
+	}
+
+	res, err := applyQuery(transition, query)
+	tAssert.NoError(err)
+	tAssert.False(res.Failed())
+	tAssert.NotZero(res.GasUsed)
+	tAssert.Equal(uint64(42), query.Nonce)
+
+	_, err = QueryParticipants(transition, 1_000_000, miner)
+	tAssert.NoError(err)
+
+	// The queries took no gas from the block and didn't fire the post hook.
+	tAssert.NoError(transfer(1))
+	tAssert.Equal(2, hooks)
+	tAssert.Equal(uint64(2*21_000), transition.TotalGas())
+}
@@ -0,0 +1,189 @@
+package staking
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// SetChanged is the notification emitted by the ParticipantWatcher whenever the
+// participant membership or the probation status of a sequencer changes.
+type SetChanged struct {
+	// Previous is the last known snapshot before the change, nil on the first notification.
+	Previous *ParticipantsSnapshot
+	// Current is the snapshot that introduced the change.
+	Current *ParticipantsSnapshot
+}
+
+// ParticipantWatcher maintains the current set of staking participants in the background
+// and notifies subscribers when the set changes.
+type ParticipantWatcher struct {
+	activeParticipants ActiveParticipants
+	interval           time.Duration
+	logger             hclog.Logger
+
+	current  atomic.Pointer[ParticipantsSnapshot]
+	changeCh chan SetChanged
+	pollCh   chan struct{}
+
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	doneCh chan struct{}
+}
+
+// NewParticipantWatcher creates a new instance of ParticipantWatcher.
+// It takes the active participants querier, the poll interval and a logger as parameters.
+// Besides polling in the given interval, the participant set is refreshed each time Notify is called,
+// which allows hooking the watcher to a block subscription.
+func NewParticipantWatcher(activeParticipants ActiveParticipants, interval time.Duration, logger hclog.Logger) *ParticipantWatcher {
+	return &ParticipantWatcher{
+		activeParticipants: activeParticipants,
+		interval:           interval,
+		logger:             logger.Named("participant_watcher"),
+		changeCh:           make(chan SetChanged, 1),
+		pollCh:             make(chan struct{}, 1),
+	}
+}
+
+// Start starts watching the participant set in the background until the context is
+// cancelled or Stop is called. Calling Start on a running watcher is a no-op.
+func (pw *ParticipantWatcher) Start(ctx context.Context) {
+	pw.lock.Lock()
+	defer pw.lock.Unlock()
+
+	if pw.cancel != nil {
+		return
+	}
+
+	ctx, pw.cancel = context.WithCancel(ctx)
+	pw.doneCh = make(chan struct{})
+
+	go pw.run(ctx, pw.doneCh)
+}
+
+// Stop stops the watcher and waits for the background loop to exit.
+func (pw *ParticipantWatcher) Stop() {
+	pw.lock.Lock()
+	cancel, doneCh := pw.cancel, pw.doneCh
+	pw.cancel, pw.doneCh = nil, nil
+	pw.lock.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-doneCh
+}
+
+// Changes returns the channel on which the SetChanged notifications are received.
+// A notification not received before the next change is replaced by one spanning both changes, so the watcher
+// keeps polling when the channel isn't read, e.g. by the users of Current only.
+func (pw *ParticipantWatcher) Changes() <-chan SetChanged {
+	return pw.changeCh
+}
+
+// Current returns the last known participants snapshot without blocking.
+// It returns nil if the participant set hasn't been fetched yet.
+func (pw *ParticipantWatcher) Current() *ParticipantsSnapshot {
+	return pw.current.Load()
+}
+
+// Notify schedules an immediate refresh of the participant set, e.g. when a new block is written.
+func (pw *ParticipantWatcher) Notify() {
+	select {
+	case pw.pollCh <- struct{}{}:
+	default:
+		// Refresh is already scheduled.
+	}
+}
+
+// run polls the participant set until the context is cancelled.
+func (pw *ParticipantWatcher) run(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	t := time.NewTicker(pw.interval)
+	defer t.Stop()
+
+	for {
+		pw.poll()
+
+		select {
+		case <-t.C:
+		case <-pw.pollCh:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poll fetches the participant set and emits a notification if it differs from the last known one.
+func (pw *ParticipantWatcher) poll() {
+	snapshot, err := pw.activeParticipants.Snapshot()
+	if err != nil {
+		pw.logger.Error("failed to query participants", "error", err)
+		return
+	}
+
+	previous := pw.current.Load()
+	pw.current.Store(snapshot)
+
+	if previous != nil && sameParticipants(previous, snapshot) {
+		return
+	}
+
+	pw.logger.Debug("participant set changed", "block_number", snapshot.BlockNumber, "sequencers", snapshot.Sequencers, "probation", snapshot.SequencersInProbation, "watchtowers", snapshot.WatchTowers)
+
+	change := SetChanged{Previous: previous, Current: snapshot}
+
+	for {
+		select {
+		case pw.changeCh <- change:
+			return
+		default:
+		}
+
+		// The pending notification wasn't received yet, it's replaced by one from its previous snapshot.
+		select {
+		case pending := <-pw.changeCh:
+			change.Previous = pending.Previous
+		default:
+		}
+
+		if change.Previous != nil && sameParticipants(change.Previous, snapshot) {
+			// The participant set changed back to the one of the pending notification.
+			return
+		}
+	}
+}
+
+// sameParticipants checks whether both snapshots contain the same participants, regardless of order.
+func sameParticipants(a, b *ParticipantsSnapshot) bool {
+	return sameAddresses(a.Sequencers, b.Sequencers) &&
+		sameAddresses(a.SequencersInProbation, b.SequencersInProbation) &&
+		sameAddresses(a.WatchTowers, b.WatchTowers)
+}
+
+// sameAddresses checks whether both slices contain the same set of addresses.
+func sameAddresses(a, b []types.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[types.Address]struct{}, len(a))
+	for _, addr := range a {
+		set[addr] = struct{}{}
+	}
+
+	for _, addr := range b {
+		if _, ok := set[addr]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,122 @@
+package staking
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+type mutableActiveParticipants struct {
+	DumbActiveParticipants
+
+	lock     sync.Mutex
+	snapshot ParticipantsSnapshot
+}
+
+func (mas *mutableActiveParticipants) Snapshot() (*ParticipantsSnapshot, error) {
+	mas.lock.Lock()
+	defer mas.lock.Unlock()
+
+	snapshot := mas.snapshot
+	return &snapshot, nil
+}
+
+func (mas *mutableActiveParticipants) set(snapshot ParticipantsSnapshot) {
+	mas.lock.Lock()
+	defer mas.lock.Unlock()
+
+	mas.snapshot = snapshot
+}
+
+func TestParticipantWatcher(t *testing.T) {
+	tAssert := assert.New(t)
+
+	sequencer1 := types.StringToAddress("1")
+	sequencer2 := types.StringToAddress("2")
+
+	ap := &mutableActiveParticipants{}
+	ap.set(ParticipantsSnapshot{BlockNumber: 1, Sequencers: []types.Address{sequencer1, sequencer2}})
+
+	watcher := NewParticipantWatcher(ap, time.Hour, hclog.NewNullLogger())
+	tAssert.Nil(watcher.Current())
+
+	watcher.Start(context.Background())
+	defer watcher.Stop()
+
+	change := <-watcher.Changes()
+	tAssert.Nil(change.Previous)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2}, change.Current.Sequencers)
+
+	// New head with the same participants in a different order must not notify.
+	ap.set(ParticipantsSnapshot{BlockNumber: 2, Sequencers: []types.Address{sequencer2, sequencer1}})
+	watcher.Notify()
+
+	// Probation status change must notify.
+	ap.set(ParticipantsSnapshot{BlockNumber: 3, Sequencers: []types.Address{sequencer1}, SequencersInProbation: []types.Address{sequencer2}})
+	watcher.Notify()
+
+	select {
+	case change = <-watcher.Changes():
+		tAssert.Equal(uint64(3), change.Current.BlockNumber)
+		tAssert.Equal([]types.Address{sequencer2}, change.Current.SequencersInProbation)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notification received")
+	}
+
+	tAssert.Equal(uint64(3), watcher.Current().BlockNumber)
+
+	watcher.Stop()
+
+	select {
+	case change = <-watcher.Changes():
+		t.Fatalf("unexpected change notification: %v", change)
+	default:
+	}
+}
+
+func TestParticipantWatcherNotBlockedByUnreadChanges(t *testing.T) {
+	tAssert := assert.New(t)
+
+	sequencer1 := types.StringToAddress("1")
+	sequencer2 := types.StringToAddress("2")
+
+	ap := &mutableActiveParticipants{}
+	ap.set(ParticipantsSnapshot{BlockNumber: 1, Sequencers: []types.Address{sequencer1}})
+
+	watcher := NewParticipantWatcher(ap, time.Hour, hclog.NewNullLogger())
+	watcher.Start(context.Background())
+	defer watcher.Stop()
+
+	// The changes aren't received, the watcher still follows the participant set.
+	for n, sequencers := range [][]types.Address{{sequencer1, sequencer2}, {sequencer2}, {sequencer1, sequencer2}} {
+		number := uint64(n + 2)
+
+		ap.set(ParticipantsSnapshot{BlockNumber: number, Sequencers: sequencers})
+		watcher.Notify()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for current := watcher.Current(); current == nil || current.BlockNumber != number; current = watcher.Current() {
+			if time.Now().After(deadline) {
+				t.Fatalf("block %d not polled", number)
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The pending notification spans the unread changes.
+	change := <-watcher.Changes()
+	tAssert.Nil(change.Previous)
+	tAssert.Equal(uint64(4), change.Current.BlockNumber)
+
+	select {
+	case change = <-watcher.Changes():
+		t.Fatalf("unexpected change notification: %v", change)
+	default:
+	}
+}
@@ -0,0 +1,145 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// ErrNotInProbation is returned when the probation of an address that isn't in probation is requested.
+var ErrNotInProbation = errors.New("not in probation")
+
+// errProbationBoundToRoot is the error of the probation queries searching the staking history with a querier bound
+// to a state root, which has no blockchain.
+var errProbationBoundToRoot = errors.New("probation info requires the blockchain, the querier is bound to a state root")
+
+// ProbationInfo describes the probation of a sequencer.
+type ProbationInfo struct {
+	// Since is the number of the block the sequencer was put in probation in.
+	Since uint64
+}
+
+// ProbationReporter is implemented by the queriers that report how long the sequencers stay in probation.
+type ProbationReporter interface {
+	// ProbationInfo returns the probation of the sequencer.
+	ProbationInfo(addr types.Address) (*ProbationInfo, error)
+	// ProbationRemaining returns the number of blocks until the sequencer is eligible again.
+	// The deployed staking contract has no probation duration, a probation lasting until the dispute resolution
+	// concludes, so the remaining probation can't be told there and an error wrapping ErrUnsupportedByContract
+	// is returned instead.
+	ProbationRemaining(addr types.Address) (uint64, error)
+}
+
+// ProbationInfo method returns the probation of the sequencer. The start of the probation is the block of the last
+// dispute resolution begun against the sequencer, searched in the last MaxStakingHistoryRange blocks.
+// It returns an error wrapping ErrNotInProbation if the sequencer isn't in probation.
+func (asq *activeParticipantsQuerier) ProbationInfo(addr types.Address) (*ProbationInfo, error) {
+	if asq.blockchain == nil {
+		return nil, errProbationBoundToRoot
+	}
+
+	if err := asq.checkInProbation(addr); err != nil {
+		return nil, err
+	}
+
+	return asq.probationInfo(addr)
+}
+
+// checkInProbation returns an error wrapping ErrNotInProbation if the sequencer isn't in probation.
+func (asq *activeParticipantsQuerier) checkInProbation(addr types.Address) error {
+	inProbation, err := asq.InProbation(addr)
+	if err != nil {
+		return err
+	}
+
+	if !inProbation {
+		return fmt.Errorf("%w: %s", ErrNotInProbation, addr)
+	}
+
+	return nil
+}
+
+// probationInfo searches the staking history of the sequencer in probation for the start of its probation.
+func (asq *activeParticipantsQuerier) probationInfo(addr types.Address) (*ProbationInfo, error) {
+	head := asq.head().Number
+
+	fromBlock := uint64(0)
+	if head >= MaxStakingHistoryRange {
+		fromBlock = head - MaxStakingHistoryRange + 1
+	}
+
+	var (
+		since uint64
+		found bool
+	)
+
+	err := WalkStakingHistory(asq.blockchain, addr, fromBlock, head, func(entry StakingHistoryEntry) error {
+		if entry.Action == ActionDisputeResolutionBegan {
+			since, found = entry.Block, true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("start of the probation of %s not found in blocks %d-%d", addr, fromBlock, head)
+	}
+
+	return &ProbationInfo{Since: since}, nil
+}
+
+// ProbationRemaining method returns the number of blocks until the sequencer is eligible again, based on the start
+// of its probation and the probation duration governance parameter. It returns zero if the probation duration
+// has already passed, even if the sequencer is still listed in probation.
+// It returns an error wrapping ErrNotInProbation if the sequencer isn't in probation, and an error wrapping
+// ErrUnsupportedByContract if the contract doesn't have a probation duration, in which case the staking history
+// isn't searched.
+func (asq *activeParticipantsQuerier) ProbationRemaining(addr types.Address) (uint64, error) {
+	if err := asq.checkInProbation(addr); err != nil {
+		return 0, err
+	}
+
+	params, err := asq.GetGovernanceParams()
+	if err != nil {
+		return 0, err
+	}
+
+	if params.ProbationDuration == nil {
+		return 0, fmt.Errorf("%w: probation duration", ErrUnsupportedByContract)
+	}
+
+	if !params.ProbationDuration.IsUint64() {
+		return 0, fmt.Errorf("invalid probation duration %s", params.ProbationDuration)
+	}
+
+	if asq.blockchain == nil {
+		return 0, errProbationBoundToRoot
+	}
+
+	info, err := asq.probationInfo(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	return probationRemaining(info.Since, params.ProbationDuration.Uint64(), asq.head().Number), nil
+}
+
+// probationRemaining returns the number of blocks after the head until a probation of the given duration,
+// started in the since block, is over.
+func probationRemaining(since, duration, head uint64) uint64 {
+	end := since + duration
+	if end < since {
+		// The duration overflows, the probation never ends.
+		end = ^uint64(0)
+	}
+
+	if head >= end {
+		return 0
+	}
+
+	return end - head
+}
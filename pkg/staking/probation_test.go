@@ -0,0 +1,87 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestQuerierProbationRemaining(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	_, err = querier.ProbationRemaining(sequencerAddr)
+	tAssert.True(errors.Is(err, ErrNotInProbation))
+
+	tAssert.NoError(NewDisputeResolution(blockchain, executor, sender, hclog.Default()).Begin(sequencerAddr, watchtowerSignKey))
+	since := blockchain.Header().Number
+
+	// Blocks written after the start of the probation don't move it.
+	other, _ := test.NewAccount(t)
+	test.DepositBalance(t, other, balance, blockchain, executor)
+
+	info, err := querier.ProbationInfo(sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(&ProbationInfo{Since: since}, info)
+
+	// The deployed contract doesn't have a probation duration.
+	_, err = querier.ProbationRemaining(sequencerAddr)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract))
+
+	// The staking history isn't searched then, so a querier bound to a state root tells the same.
+	header := blockchain.Header()
+	atRoot := NewParticipantsQuerierAtRoot(executor, header.StateRoot, header, hclog.Default()).(*activeParticipantsQuerier)
+
+	_, err = atRoot.ProbationRemaining(sequencerAddr)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract))
+
+	_, err = querier.ProbationRemaining(watchtowerAddr)
+	tAssert.True(errors.Is(err, ErrNotInProbation))
+
+	dump, err := DumpStakingState(querier, sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.True(dump.Self.InProbation)
+	tAssert.Nil(dump.Self.ProbationRemaining)
+	tAssert.Equal(ProbationUnsupported, dump.Self.ProbationRemainingStatus)
+}
+
+func TestProbationRemaining(t *testing.T) {
+	testCases := []struct {
+		since, duration, head uint64
+		remaining             uint64
+	}{
+		{since: 10, duration: 5, head: 10, remaining: 5},
+		{since: 10, duration: 5, head: 12, remaining: 3},
+		{since: 10, duration: 5, head: 15, remaining: 0},
+		{since: 10, duration: 5, head: 100, remaining: 0},
+		{since: 10, duration: 0, head: 10, remaining: 0},
+		{since: 10, duration: ^uint64(0), head: 20, remaining: ^uint64(0) - 20},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.remaining, probationRemaining(tc.since, tc.duration, tc.head), "since %d, duration %d, head %d", tc.since, tc.duration, tc.head)
+	}
+}
@@ -0,0 +1,69 @@
+package staking
+
+import (
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+)
+
+// QuorumGuard tracks whether the network has enough active sequencers to keep producing blocks.
+// It is meant to be polled by the block production loop; transitions between having and losing
+// quorum are logged and reported as metrics.
+type QuorumGuard struct {
+	activeParticipants ActiveParticipants
+	minimum            int
+	logger             hclog.Logger
+
+	lock      sync.Mutex
+	checked   bool
+	hasQuorum bool
+}
+
+// NewQuorumGuard creates a new instance of QuorumGuard.
+// It takes the active participants querier, the minimum number of active sequencers and a logger as parameters.
+func NewQuorumGuard(activeParticipants ActiveParticipants, minimum int, logger hclog.Logger) *QuorumGuard {
+	return &QuorumGuard{
+		activeParticipants: activeParticipants,
+		minimum:            minimum,
+		logger:             logger.Named("quorum_guard"),
+	}
+}
+
+// Check method returns true if the network currently has a quorum of active sequencers.
+// A failing query is treated as a lost quorum, so that block production is paused rather than
+// continued on an unknown participant set.
+func (qg *QuorumGuard) Check() bool {
+	hasQuorum, err := qg.activeParticipants.HasQuorum(qg.minimum)
+	if err != nil {
+		qg.logger.Error("failed to check sequencer quorum", "error", err)
+		hasQuorum = false
+	}
+
+	qg.lock.Lock()
+	defer qg.lock.Unlock()
+
+	if hasQuorum {
+		metrics.SetGauge([]string{"staking", "sequencer_quorum"}, 1)
+	} else {
+		metrics.SetGauge([]string{"staking", "sequencer_quorum"}, 0)
+	}
+
+	if qg.checked && qg.hasQuorum == hasQuorum {
+		return hasQuorum
+	}
+
+	switch {
+	case hasQuorum && qg.checked:
+		qg.logger.Info("sequencer quorum regained", "minimum", qg.minimum)
+		metrics.IncrCounter([]string{"staking", "sequencer_quorum_regained"}, 1)
+	case !hasQuorum:
+		qg.logger.Warn("sequencer quorum lost", "minimum", qg.minimum)
+		metrics.IncrCounter([]string{"staking", "sequencer_quorum_lost"}, 1)
+	}
+
+	qg.checked = true
+	qg.hasQuorum = hasQuorum
+
+	return hasQuorum
+}
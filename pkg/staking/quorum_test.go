@@ -0,0 +1,70 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestHasQuorum(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	stakerAddr, stakerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, stakerAddr, balance, blockchain, executor)
+
+	participantsQuerier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	hasQuorum, err := participantsQuerier.HasQuorum(1)
+	tAssert.NoError(err)
+	tAssert.False(hasQuorum)
+
+	// Snapshot must be reused for the same head.
+	first, err := participantsQuerier.Snapshot()
+	tAssert.NoError(err)
+	second, err := participantsQuerier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.True(first == second)
+
+	stakeErr := Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), stakerAddr, stakerSignKey, stakeAmount, 1_000_000, "test")
+	tAssert.NoError(stakeErr)
+
+	snapshot, err := participantsQuerier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal(blockchain.Header().Hash, snapshot.BlockHash)
+	tAssert.Equal([]types.Address{stakerAddr}, snapshot.Sequencers)
+
+	hasQuorum, err = participantsQuerier.HasQuorum(1)
+	tAssert.NoError(err)
+	tAssert.True(hasQuorum)
+
+	hasQuorum, err = participantsQuerier.HasQuorum(2)
+	tAssert.NoError(err)
+	tAssert.False(hasQuorum)
+}
+
+func TestQuorumGuard(t *testing.T) {
+	tAssert := assert.New(t)
+
+	ap := &staticActiveSequencers{}
+	guard := NewQuorumGuard(ap, 2, hclog.NewNullLogger())
+
+	tAssert.False(guard.Check())
+
+	ap.sequencers = []types.Address{types.StringToAddress("1"), types.StringToAddress("2")}
+	tAssert.True(guard.Check())
+	tAssert.True(guard.Check())
+
+	ap.sequencers = ap.sequencers[:1]
+	tAssert.False(guard.Check())
+}
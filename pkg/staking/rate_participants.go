@@ -9,11 +9,9 @@ import (
 	edge_crypto "github.com/0xPolygon/polygon-edge/crypto"
 	"github.com/0xPolygon/polygon-edge/state"
 	"github.com/0xPolygon/polygon-edge/types"
-	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
 	"github.com/availproject/op-evm/pkg/block"
 	"github.com/availproject/op-evm/pkg/blockchain"
 	"github.com/hashicorp/go-hclog"
-	"github.com/umbracle/ethgo/abi"
 )
 
 // ParticipantRate is an interface for managing the minimum and maximum number of participants.
@@ -185,7 +183,7 @@ func (st *participantRate) CurrentMaximum() (*big.Int, error) {
 // It takes the sender address, the new minimum value, and the gas limit as parameters.
 // It returns the transaction and an error if the operation fails.
 func SetMinimumParticipantsTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetMinNumParticipants"]
+	method, ok := stakingMethod("SetMinNumParticipants")
 	if !ok {
 		return nil, errors.New("SetMinNumParticipants method doesn't exist in Staking contract ABI")
 	}
@@ -215,20 +213,19 @@ func SetMinimumParticipantsTx(from types.Address, amount *big.Int, gasLimit uint
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns the current minimum value as a big.Int and an error if the operation fails.
 func GetMinimumParticipantsTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetMinNumParticipants"]
+	method, ok := stakingMethod("GetMinNumParticipants")
 	if !ok {
 		return nil, errors.New("GetMinNumParticipants method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -236,7 +233,7 @@ func GetMinimumParticipantsTx(t *state.Transition, gasLimit uint64, from types.A
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
@@ -248,7 +245,7 @@ func GetMinimumParticipantsTx(t *state.Transition, gasLimit uint64, from types.A
 // It takes the sender address, the new maximum value, and the gas limit as parameters.
 // It returns the transaction and an error if the operation fails.
 func SetMaximumParticipantsTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetMaxNumParticipants"]
+	method, ok := stakingMethod("SetMaxNumParticipants")
 	if !ok {
 		return nil, errors.New("SetMaxNumParticipants method doesn't exist in Staking contract ABI")
 	}
@@ -278,20 +275,19 @@ func SetMaximumParticipantsTx(from types.Address, amount *big.Int, gasLimit uint
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns the current maximum value as a big.Int and an error if the operation fails.
 func GetMaximumParticipantsTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetMaxNumParticipants"]
+	method, ok := stakingMethod("GetMaxNumParticipants")
 	if !ok {
 		return nil, errors.New("GetMaxNumParticipants method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -299,7 +295,7 @@ func GetMaximumParticipantsTx(t *state.Transition, gasLimit uint64, from types.A
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
@@ -9,11 +9,9 @@ import (
 	edge_crypto "github.com/0xPolygon/polygon-edge/crypto"
 	"github.com/0xPolygon/polygon-edge/state"
 	"github.com/0xPolygon/polygon-edge/types"
-	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
 	"github.com/availproject/op-evm/pkg/block"
 	"github.com/availproject/op-evm/pkg/blockchain"
 	"github.com/hashicorp/go-hclog"
-	"github.com/umbracle/ethgo/abi"
 )
 
 // SequencerRate is an interface for managing the minimum and maximum number of sequencers.
@@ -185,7 +183,7 @@ func (st *sequencerRate) CurrentMaximum() (*big.Int, error) {
 // It takes the sender address, the new minimum value, and the gas limit as parameters.
 // It returns the transaction and an error if the operation fails.
 func SetMinimumSequencersTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetMinNumSequencers"]
+	method, ok := stakingMethod("SetMinNumSequencers")
 	if !ok {
 		return nil, errors.New("SetMinNumSequencers method doesn't exist in Staking contract ABI")
 	}
@@ -215,20 +213,19 @@ func SetMinimumSequencersTx(from types.Address, amount *big.Int, gasLimit uint64
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns the current minimum value as a big.Int and an error if the operation fails.
 func GetMinimumSequencersTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetMinNumSequencers"]
+	method, ok := stakingMethod("GetMinNumSequencers")
 	if !ok {
 		return nil, errors.New("GetMinNumSequencers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -236,7 +233,7 @@ func GetMinimumSequencersTx(t *state.Transition, gasLimit uint64, from types.Add
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
@@ -248,7 +245,7 @@ func GetMinimumSequencersTx(t *state.Transition, gasLimit uint64, from types.Add
 // It takes the sender address, the new maximum value, and the gas limit as parameters.
 // It returns the transaction and an error if the operation fails.
 func SetMaximumSequencersTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetMaxNumSequencers"]
+	method, ok := stakingMethod("SetMaxNumSequencers")
 	if !ok {
 		return nil, errors.New("SetMaxNumSequencers method doesn't exist in Staking contract ABI")
 	}
@@ -278,20 +275,19 @@ func SetMaximumSequencersTx(from types.Address, amount *big.Int, gasLimit uint64
 // It takes a transaction transition, gas limit, and the address of the sender as parameters.
 // It returns the current maximum value as a big.Int and an error if the operation fails.
 func GetMaximumSequencersTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetMaxNumSequencers"]
+	method, ok := stakingMethod("GetMaxNumSequencers")
 	if !ok {
 		return nil, errors.New("GetMaxNumSequencers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -299,7 +295,7 @@ func GetMaximumSequencersTx(t *state.Transition, gasLimit uint64, from types.Add
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
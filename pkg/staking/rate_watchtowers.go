@@ -9,11 +9,9 @@ import (
 	edge_crypto "github.com/0xPolygon/polygon-edge/crypto"
 	"github.com/0xPolygon/polygon-edge/state"
 	"github.com/0xPolygon/polygon-edge/types"
-	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
 	"github.com/availproject/op-evm/pkg/block"
 	"github.com/availproject/op-evm/pkg/blockchain"
 	"github.com/hashicorp/go-hclog"
-	"github.com/umbracle/ethgo/abi"
 )
 
 // WatchtowerRate is an interface for managing the minimum and maximum number of watchtowers.
@@ -182,7 +180,7 @@ func (st *watchtowerRate) CurrentMaximum() (*big.Int, error) {
 // It takes the sender address, the new minimum value, and the gas limit as parameters.
 // The transaction is returned or an error if it fails.
 func SetMinimumWatchtowersTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetMinNumWatchtowers"]
+	method, ok := stakingMethod("SetMinNumWatchtowers")
 	if !ok {
 		return nil, errors.New("SetMinNumWatchtowers method doesn't exist in Staking contract ABI")
 	}
@@ -212,20 +210,19 @@ func SetMinimumWatchtowersTx(from types.Address, amount *big.Int, gasLimit uint6
 // It takes a state transition, gas limit, and sender address as parameters.
 // The minimum number of watchtowers is returned as a *big.Int or an error if the operation fails.
 func GetMinimumWatchtowersTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetMinNumWatchtowers"]
+	method, ok := stakingMethod("GetMinNumWatchtowers")
 	if !ok {
 		return nil, errors.New("GetMinNumWatchtowers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -233,7 +230,7 @@ func GetMinimumWatchtowersTx(t *state.Transition, gasLimit uint64, from types.Ad
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
@@ -245,7 +242,7 @@ func GetMinimumWatchtowersTx(t *state.Transition, gasLimit uint64, from types.Ad
 // It takes the sender address, the new maximum value, and the gas limit as parameters.
 // The transaction is returned or an error if it fails.
 func SetMaximumWatchtowersTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetMaxNumWatchtowers"]
+	method, ok := stakingMethod("SetMaxNumWatchtowers")
 	if !ok {
 		return nil, errors.New("SetMaxNumWatchtowers method doesn't exist in Staking contract ABI")
 	}
@@ -275,20 +272,19 @@ func SetMaximumWatchtowersTx(from types.Address, amount *big.Int, gasLimit uint6
 // It takes a state transition, gas limit, and sender address as parameters.
 // The maximum number of watchtowers is returned as a *big.Int or an error if the operation fails.
 func GetMaximumWatchtowersTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetMaxNumWatchtowers"]
+	method, ok := stakingMethod("GetMaxNumWatchtowers")
 	if !ok {
 		return nil, errors.New("GetMaxNumWatchtowers method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -296,7 +292,7 @@ func GetMaximumWatchtowersTx(t *state.Transition, gasLimit uint64, from types.Ad
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
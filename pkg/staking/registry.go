@@ -0,0 +1,157 @@
+package staking
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// StakingContract describes a deployed staking contract.
+type StakingContract struct {
+	// Address is the address the contract is deployed at.
+	Address types.Address
+	// ABI is the ABI of the deployed contract.
+	ABI *abi.ABI
+}
+
+// DefaultStakingContract returns the staking contract deployed at AddrStakingContract.
+func DefaultStakingContract() *StakingContract {
+	return &StakingContract{
+		Address: AddrStakingContract,
+		ABI:     stakingABI(),
+	}
+}
+
+// ContractRegistry maps node types to the staking contracts handling them.
+// Node types without a registered contract are handled by the default contract.
+type ContractRegistry struct {
+	lock            sync.RWMutex
+	defaultContract *StakingContract
+	contracts       map[NodeType]*StakingContract
+}
+
+// NewContractRegistry creates a new instance of ContractRegistry.
+// It takes the contract handling all node types without a registered contract as a parameter.
+func NewContractRegistry(defaultContract *StakingContract) *ContractRegistry {
+	return &ContractRegistry{
+		defaultContract: defaultContract,
+		contracts:       make(map[NodeType]*StakingContract),
+	}
+}
+
+// NewSingleContractRegistry creates a ContractRegistry where all node types are handled by the
+// staking contract deployed at AddrStakingContract. This is the registry used by default.
+func NewSingleContractRegistry() *ContractRegistry {
+	return NewContractRegistry(DefaultStakingContract())
+}
+
+// Register sets the staking contract handling the given node type.
+func (cr *ContractRegistry) Register(nodeType NodeType, contract *StakingContract) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	cr.contracts[nodeType] = contract
+}
+
+// Contract returns the staking contract handling the given node type.
+func (cr *ContractRegistry) Contract(nodeType NodeType) *StakingContract {
+	cr.lock.RLock()
+	defer cr.lock.RUnlock()
+
+	if contract, ok := cr.contracts[nodeType]; ok {
+		return contract
+	}
+
+	return cr.defaultContract
+}
+
+// Contracts returns the distinct staking contracts in the registry, ordered by node type.
+func (cr *ContractRegistry) Contracts() []*StakingContract {
+	toReturn := []*StakingContract{}
+	seen := make(map[types.Address]struct{})
+
+	for _, nodeType := range []NodeType{Sequencer, WatchTower} {
+		contract := cr.Contract(nodeType)
+		if _, ok := seen[contract.Address]; ok {
+			continue
+		}
+
+		seen[contract.Address] = struct{}{}
+		toReturn = append(toReturn, contract)
+	}
+
+	return toReturn
+}
+
+// call executes the given contract method in the transition.
+// It returns the method and the return value, or an error wrapping ErrUnsupportedByContract if the
+// contract ABI doesn't have the method.
+func (sc *StakingContract) call(t *state.Transition, gasLimit uint64, from types.Address, methodName string, inputs map[string]interface{}) (*abi.Method, []byte, error) {
+	method, ok := sc.ABI.Methods[methodName]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedByContract, methodName)
+	}
+
+	input := method.ID()
+
+	if inputs != nil {
+		encodedInput, err := method.Inputs.Encode(inputs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		input = append(input, encodedInput...)
+	}
+
+	res, err := applyQuery(t, &types.Transaction{
+		From:     from,
+		To:       &sc.Address,
+		Value:    big.NewInt(0),
+		Input:    input,
+		GasPrice: big.NewInt(0),
+		Gas:      gasLimit,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.Failed() {
+		return nil, nil, queryFailure(method.Name, res)
+	}
+
+	return method, res.ReturnValue, nil
+}
+
+// queryAddresses calls an address list returning contract method and decodes the result with DecodeParticipants.
+func (sc *StakingContract) queryAddresses(t *state.Transition, gasLimit uint64, from types.Address, methodName string, inputs map[string]interface{}) ([]types.Address, error) {
+	method, returnValue, err := sc.call(t, gasLimit, from, methodName, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeParticipants(method, returnValue)
+}
+
+// queryAmount calls an amount returning contract method and decodes the result as a big.Int value.
+func (sc *StakingContract) queryAmount(t *state.Transition, gasLimit uint64, from types.Address, methodName string, inputs map[string]interface{}) (*big.Int, error) {
+	_, returnValue, err := sc.call(t, gasLimit, from, methodName, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(returnValue), nil
+}
+
+// queryBool calls a boolean returning contract method and decodes the result.
+func (sc *StakingContract) queryBool(t *state.Transition, gasLimit uint64, from types.Address, methodName string, inputs map[string]interface{}) (bool, error) {
+	_, returnValue, err := sc.call(t, gasLimit, from, methodName, inputs)
+	if err != nil {
+		return false, err
+	}
+
+	return new(big.Int).SetBytes(returnValue).Sign() != 0, nil
+}
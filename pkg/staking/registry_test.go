@@ -0,0 +1,102 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/block"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestTwoContractRegistry(t *testing.T) {
+	tAssert := assert.New(t)
+
+	// Deploy a second copy of the staking contract that handles watchtowers only.
+	addrWatchtowerContract := types.StringToAddress("0x0110000000000000000000000000000000000002")
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakingAlloc := *chain.Genesis.Alloc[AddrStakingContract]
+	chain.Genesis.Alloc[addrWatchtowerContract] = &stakingAlloc
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	stakeErr := Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test")
+	tAssert.NoError(stakeErr)
+
+	// Stake the watchtower towards the watchtower contract.
+	blk, err := block.NewBlockBuilderFactory(blockchain, executor, hclog.Default()).FromBlockchainHead()
+	tAssert.NoError(err)
+
+	blk.SetCoinbaseAddress(watchtowerAddr)
+	blk.SignWith(watchtowerSignKey)
+
+	tx, err := StakeTx(watchtowerAddr, stakeAmount, string(WatchTower), 1_000_000)
+	tAssert.NoError(err)
+
+	tx.To = &addrWatchtowerContract
+	blk.AddTransactions(tx)
+
+	fBlock, err := blk.Build()
+	tAssert.NoError(err)
+	tAssert.NoError(blockchain.WriteBlock(fBlock, "test"))
+
+	registry := NewSingleContractRegistry()
+	registry.Register(WatchTower, &StakingContract{
+		Address: addrWatchtowerContract,
+		ABI:     DefaultStakingContract().ABI,
+	})
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default(), WithContractRegistry(registry))
+
+	sequencers, err := querier.Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencerAddr}, sequencers)
+
+	watchtowers, err := querier.Get(WatchTower)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{watchtowerAddr}, watchtowers)
+
+	isWatchtower, err := querier.Contains(watchtowerAddr, WatchTower)
+	tAssert.NoError(err)
+	tAssert.True(isWatchtower)
+
+	watchtowerBalance, err := querier.GetBalance(watchtowerAddr)
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, watchtowerBalance)
+
+	sequencerTotal, err := querier.GetTotalStakedAmountByType(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, sequencerTotal)
+
+	watchtowerTotal, err := querier.GetTotalStakedAmountByType(WatchTower)
+	tAssert.NoError(err)
+	tAssert.Equal(stakeAmount, watchtowerTotal)
+
+	total, err := querier.GetTotalStakedAmount()
+	tAssert.NoError(err)
+	tAssert.Equal(new(big.Int).Mul(stakeAmount, big.NewInt(2)), total)
+
+	// The default single contract registry doesn't see the watchtower.
+	defaultQuerier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	watchtowers, err = defaultQuerier.Get(WatchTower)
+	tAssert.NoError(err)
+	tAssert.Empty(watchtowers)
+}
@@ -0,0 +1,87 @@
+package staking
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state/runtime"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// ErrQueryReverted is returned when the staking contract reverts a query, e.g. because the queried
+// address isn't a staking contract or the contract is paused. The reason is included in the error message
+// when the contract reverts with one.
+var ErrQueryReverted = errors.New("staking contract query reverted")
+
+// panicSelector is the selector of the `Panic(uint256)` payload solidity reverts with on failed assertions
+// and runtime errors.
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// panicReasons describes the solidity panic codes.
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// queryFailure returns the error of a failed staking contract query of the given method.
+// Reverted executions return an error wrapping ErrQueryReverted with the decoded reason, other failures,
+// e.g. running out of gas, return the execution error as is.
+func queryFailure(methodName string, res *runtime.ExecutionResult) error {
+	if !res.Reverted() {
+		return res.Err
+	}
+
+	if reason := decodeRevertReason(res); reason != "" {
+		return fmt.Errorf("%w: %s: %s", ErrQueryReverted, methodName, reason)
+	}
+
+	return fmt.Errorf("%w: %s", ErrQueryReverted, methodName)
+}
+
+// decodeRevertReason decodes the `Error(string)` reason or the `Panic(uint256)` code from the return data
+// of a reverted execution.
+// It returns an empty string if the execution didn't revert or reverted without a reason.
+func decodeRevertReason(res *runtime.ExecutionResult) string {
+	if !res.Reverted() || len(res.ReturnValue) == 0 {
+		return ""
+	}
+
+	if reason, ok := decodePanic(res.ReturnValue); ok {
+		return reason
+	}
+
+	reason, err := abi.UnpackRevertError(res.ReturnValue)
+	if err != nil {
+		return ""
+	}
+
+	return reason
+}
+
+// decodePanic decodes the `Panic(uint256)` revert payload.
+// It returns false if the payload isn't a panic.
+func decodePanic(returnValue []byte) (string, bool) {
+	if len(returnValue) != len(panicSelector)+32 || !bytes.HasPrefix(returnValue, panicSelector) {
+		return "", false
+	}
+
+	code := new(big.Int).SetBytes(returnValue[len(panicSelector):])
+
+	if code.IsUint64() {
+		if reason, ok := panicReasons[code.Uint64()]; ok {
+			return fmt.Sprintf("panic: %s (0x%x)", reason, code.Uint64()), true
+		}
+	}
+
+	return fmt.Sprintf("panic: unknown code 0x%x", code), true
+}
@@ -0,0 +1,108 @@
+package staking
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/state/runtime"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+// revertReasonPayload is the `Error(string)` revert payload with the "paused" reason.
+const revertReasonPayload = "08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000006" +
+	"7061757365640000000000000000000000000000000000000000000000000000"
+
+func TestQueryFailure(t *testing.T) {
+	tAssert := assert.New(t)
+
+	reasonPayload, err := hex.DecodeString(revertReasonPayload)
+	tAssert.NoError(err)
+
+	panicPayload := append(append([]byte(nil), panicSelector...), make([]byte, 32)...)
+	panicPayload[len(panicPayload)-1] = 0x11
+
+	cases := []struct {
+		name    string
+		res     *runtime.ExecutionResult
+		message string
+	}{
+		{
+			name:    "reason",
+			res:     &runtime.ExecutionResult{Err: runtime.ErrExecutionReverted, ReturnValue: reasonPayload},
+			message: "staking contract query reverted: GetCurrentSequencers: paused",
+		},
+		{
+			name:    "panic",
+			res:     &runtime.ExecutionResult{Err: runtime.ErrExecutionReverted, ReturnValue: panicPayload},
+			message: "staking contract query reverted: GetCurrentSequencers: panic: arithmetic overflow or underflow (0x11)",
+		},
+		{
+			name:    "no reason",
+			res:     &runtime.ExecutionResult{Err: runtime.ErrExecutionReverted},
+			message: "staking contract query reverted: GetCurrentSequencers",
+		},
+	}
+
+	for _, c := range cases {
+		err := queryFailure("GetCurrentSequencers", c.res)
+		tAssert.True(errors.Is(err, ErrQueryReverted), c.name)
+		tAssert.EqualError(err, c.message, c.name)
+	}
+
+	// Failures other than reverts are returned as is.
+	err = queryFailure("GetCurrentSequencers", &runtime.ExecutionResult{Err: runtime.ErrOutOfGas})
+	tAssert.Equal(runtime.ErrOutOfGas, err)
+}
+
+func TestQueryRevertReason(t *testing.T) {
+	tAssert := assert.New(t)
+
+	chain, err := test.NewChain(getGenesisBasePath())
+	tAssert.NoError(err)
+
+	payload, err := hex.DecodeString(revertReasonPayload)
+	tAssert.NoError(err)
+
+	// Replace the staking contract with one that copies the payload to memory and reverts with it,
+	// like a paused contract would.
+	prelude := []byte{
+		0x60, byte(len(payload)), // PUSH1 payload length
+		0x60, 0x0d, // PUSH1 payload offset, the length of this prelude
+		0x60, 0x00, // PUSH1 0
+		0x39,                     // CODECOPY
+		0x60, byte(len(payload)), // PUSH1 payload length
+		0x60, 0x00, // PUSH1 0
+		0xfd, // REVERT
+		0x00, // STOP
+	}
+
+	stakingAlloc := *chain.Genesis.Alloc[AddrStakingContract]
+	stakingAlloc.Code = append(prelude, payload...)
+	chain.Genesis.Alloc[AddrStakingContract] = &stakingAlloc
+
+	executor, blockchain, txpool, err := test.NewBlockchainWithTxPool(chain, NewVerifier(new(DumbActiveParticipants), hclog.Default()))
+	tAssert.NoError(err)
+	defer txpool.Close()
+
+	sequencer, _ := test.NewAccount(t)
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, sequencer)
+	tAssert.NoError(err)
+
+	_, err = QuerySequencers(transition, 1_000_000, sequencer)
+	tAssert.True(errors.Is(err, ErrQueryReverted), "unexpected error: %v", err)
+	tAssert.EqualError(err, "staking contract query reverted: GetCurrentSequencers: paused")
+
+	_, err = GetThresholdTx(transition, 1_000_000, sequencer)
+	tAssert.True(errors.Is(err, ErrQueryReverted), "unexpected error: %v", err)
+
+	_, err = NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).GetTotalStakedAmount()
+	tAssert.True(errors.Is(err, ErrQueryReverted), "unexpected error: %v", err)
+	tAssert.Contains(err.Error(), "GetCurrentStakedAmount: paused")
+}
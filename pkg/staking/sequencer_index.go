@@ -0,0 +1,77 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// ErrNotInSequencerSet is returned when the address is not in the active sequencer set.
+var ErrNotInSequencerSet = errors.New("address is not in the active sequencer set")
+
+// SequencerIndex returns the position of the given address among the active sequencers of the snapshot
+// and the number of active sequencers. The sequencers are sorted in ascending order, so every node
+// computes the same index for the same snapshot.
+// It returns an error wrapping ErrNotInSequencerSet if the address is not an active sequencer.
+func (ps *ParticipantsSnapshot) SequencerIndex(addr types.Address) (int, int, error) {
+	sorted := make(addresses, len(ps.Sequencers))
+	copy(sorted, ps.Sequencers)
+	sort.Stable(sorted)
+
+	for i, s := range sorted {
+		if s == addr {
+			return i, len(sorted), nil
+		}
+	}
+
+	return 0, len(sorted), fmt.Errorf("%w: %s", ErrNotInSequencerSet, addr)
+}
+
+// isMyTurn implements the round-robin schedule on top of the sequencer index:
+// the sequencer at position `height % count` produces the block at the given height.
+func isMyTurn(ap ActiveParticipants, addr types.Address, height uint64) (bool, error) {
+	index, count, err := ap.GetSequencerIndex(addr)
+	if err != nil {
+		return false, err
+	}
+
+	return uint64(index) == height%uint64(count), nil
+}
+
+// GetSequencerIndex method returns the position of the given address in the active sequencer set and
+// the size of the set, both taken from the same participants snapshot.
+// It returns an error wrapping ErrNotInSequencerSet if the address is not an active sequencer.
+func (asq *activeParticipantsQuerier) GetSequencerIndex(addr types.Address) (int, int, error) {
+	snapshot, err := asq.Snapshot()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return snapshot.SequencerIndex(addr)
+}
+
+// IsMyTurn method checks whether the given sequencer is scheduled to produce the block at the given height.
+// It returns an error wrapping ErrNotInSequencerSet if the address is not an active sequencer.
+func (asq *activeParticipantsQuerier) IsMyTurn(addr types.Address, height uint64) (bool, error) {
+	return isMyTurn(asq, addr, height)
+}
+
+// GetSequencerIndex method returns the position of the given address in the active sequencer set and
+// the size of the set, both taken from the same participants snapshot.
+// It returns an error wrapping ErrNotInSequencerSet if the address is not an active sequencer.
+func (rpq *remoteParticipantsQuerier) GetSequencerIndex(addr types.Address) (int, int, error) {
+	snapshot, err := rpq.Snapshot()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return snapshot.SequencerIndex(addr)
+}
+
+// IsMyTurn method checks whether the given sequencer is scheduled to produce the block at the given height.
+// It returns an error wrapping ErrNotInSequencerSet if the address is not an active sequencer.
+func (rpq *remoteParticipantsQuerier) IsMyTurn(addr types.Address, height uint64) (bool, error) {
+	return isMyTurn(rpq, addr, height)
+}
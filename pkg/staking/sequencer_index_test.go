@@ -0,0 +1,55 @@
+package staking
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/test-go/testify/assert"
+)
+
+func TestSequencerIndex(t *testing.T) {
+	tAssert := assert.New(t)
+
+	sequencers := []types.Address{
+		types.StringToAddress("0x3"),
+		types.StringToAddress("0x1"),
+		types.StringToAddress("0x2"),
+	}
+
+	ap := &staticActiveSequencers{sequencers: sequencers}
+
+	for expectedIndex, addr := range sortedAddresses(sequencers) {
+		index, count, err := ap.GetSequencerIndex(addr)
+		tAssert.NoError(err)
+		tAssert.Equal(expectedIndex, index)
+		tAssert.Equal(len(sequencers), count)
+	}
+
+	_, count, err := ap.GetSequencerIndex(types.StringToAddress("0x4"))
+	tAssert.True(errors.Is(err, ErrNotInSequencerSet), "unexpected error: %v", err)
+	tAssert.Equal(len(sequencers), count)
+
+	// Exactly one sequencer is scheduled for every height, in the order of the index.
+	for height := uint64(0); height < 10; height++ {
+		turns := 0
+
+		for _, addr := range sequencers {
+			myTurn, err := ap.IsMyTurn(addr, height)
+			tAssert.NoError(err)
+
+			if myTurn {
+				turns++
+
+				index, _, err := ap.GetSequencerIndex(addr)
+				tAssert.NoError(err)
+				tAssert.Equal(height%uint64(len(sequencers)), uint64(index))
+			}
+		}
+
+		tAssert.Equal(1, turns)
+	}
+
+	_, err = ap.IsMyTurn(types.StringToAddress("0x4"), 1)
+	tAssert.True(errors.Is(err, ErrNotInSequencerSet), "unexpected error: %v", err)
+}
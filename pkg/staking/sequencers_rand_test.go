@@ -19,6 +19,15 @@ func (sas *staticActiveSequencers) Get(_ NodeType) ([]types.Address, error) {
 	return lst, nil
 }
 
+func (sas *staticActiveSequencers) GetRegistered(_ NodeType) ([]types.Address, error) {
+	return sas.sequencers, nil
+}
+
+func (sas *staticActiveSequencers) GetAll() (map[NodeType][]types.Address, error) {
+	sequencers, _ := sas.Get(Sequencer)
+	return map[NodeType][]types.Address{Sequencer: sequencers}, nil
+}
+
 func (sas *staticActiveSequencers) Contains(addr types.Address, _ NodeType) (bool, error) {
 	for _, a := range sas.sequencers {
 		if bytes.Equal(a.Bytes(), addr.Bytes()) {
@@ -37,10 +46,59 @@ func (dasq *staticActiveSequencers) GetTotalStakedAmount() (*big.Int, error) {
 	return nil, nil
 }
 
+func (dasq *staticActiveSequencers) GetTotalStakedAmountByType(_ NodeType) (*big.Int, error) {
+	return nil, nil
+}
+
 func (dasq *staticActiveSequencers) InProbation(_ types.Address) (bool, error) {
 	return false, nil
 }
 
+func (dasq *staticActiveSequencers) InProbationBatch(addrs []types.Address) (map[types.Address]bool, error) {
+	return probationMembership(addrs, nil), nil
+}
+
+func (dasq *staticActiveSequencers) IsSlashed(_ types.Address) (bool, error) {
+	return false, nil
+}
+
+func (dasq *staticActiveSequencers) GetInactiveSequencers(_ uint64) ([]types.Address, error) {
+	return nil, nil
+}
+
+func (dasq *staticActiveSequencers) GetStakeRequirement(_ NodeType) (*big.Int, error) {
+	return nil, nil
+}
+
+func (dasq *staticActiveSequencers) GetDelegationInfo(_ types.Address) (*DelegationInfo, error) {
+	return nil, nil
+}
+
+func (dasq *staticActiveSequencers) GetWatchtowerBond(_ types.Address) (*WatchtowerBond, error) {
+	return nil, nil
+}
+
+func (sas *staticActiveSequencers) Snapshot() (*ParticipantsSnapshot, error) {
+	sequencers, _ := sas.Get(Sequencer)
+	return &ParticipantsSnapshot{Sequencers: sequencers}, nil
+}
+
+func (sas *staticActiveSequencers) HasQuorum(minimum int) (bool, error) {
+	return len(sas.sequencers) >= minimum, nil
+}
+
+func (sas *staticActiveSequencers) Count(_ NodeType) (int, error) {
+	return len(sas.sequencers), nil
+}
+
+func (sas *staticActiveSequencers) GetSequencerIndex(addr types.Address) (int, int, error) {
+	return (&ParticipantsSnapshot{Sequencers: sas.sequencers}).SequencerIndex(addr)
+}
+
+func (sas *staticActiveSequencers) IsMyTurn(addr types.Address, height uint64) (bool, error) {
+	return isMyTurn(sas, addr, height)
+}
+
 func Test_RandomizedSequencers(t *testing.T) {
 
 	testCases := []struct {
@@ -0,0 +1,127 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// StakeWeight selects which stake amount is used to weight sequencer selection.
+type StakeWeight int
+
+const (
+	// OwnStake weights sequencers by the amount they staked themselves.
+	OwnStake StakeWeight = iota
+	// EffectiveStake weights sequencers by own stake plus the stake delegated to them.
+	// When the staking contract doesn't support delegation, own stake is used instead.
+	EffectiveStake
+)
+
+type weightedActiveSequencersQuerier struct {
+	rngSeedFn RandomSeedFn
+	querier   ActiveParticipants
+	weight    StakeWeight
+}
+
+// NewWeightedActiveSequencersQuerier creates a new instance of weightedActiveSequencersQuerier.
+// It returns an implementation of the ActiveSequencers interface that deterministically orders the
+// list of currently active sequencers, where the probability of a sequencer being placed earlier
+// in the list is proportional to its stake. The return value of the Get method will be the same for
+// the same seed, list of addresses and stakes from ActiveParticipants.
+func NewWeightedActiveSequencersQuerier(rngSeedFn RandomSeedFn, activeParticipants ActiveParticipants, weight StakeWeight) ActiveSequencers {
+	return &weightedActiveSequencersQuerier{
+		rngSeedFn: rngSeedFn,
+		querier:   activeParticipants,
+		weight:    weight,
+	}
+}
+
+// Get returns the list of currently active sequencers.
+// It sorts the addresses in ascending order and then performs a deterministic weighted shuffle
+// using the provided seed. Sequencers without any stake are placed at the end of the list.
+// An error is returned if the operation fails.
+func (wasq *weightedActiveSequencersQuerier) Get() ([]types.Address, error) {
+	as, err := wasq.querier.Get(Sequencer)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := addresses(as)
+	sort.Stable(addrs)
+
+	weights := make([]*big.Int, len(addrs))
+	for i, addr := range addrs {
+		weights[i], err = wasq.stakeOf(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rng := rand.New(rand.NewSource(wasq.rngSeedFn()))
+	toReturn := make([]types.Address, 0, len(addrs))
+
+	for {
+		total := new(big.Int)
+		for _, w := range weights {
+			total.Add(total, w)
+		}
+
+		if total.Sign() == 0 {
+			break
+		}
+
+		// Pick the next sequencer with probability proportional to its weight.
+		pick := new(big.Int).Rand(rng, total)
+		for i, w := range weights {
+			if pick.Cmp(w) < 0 {
+				toReturn = append(toReturn, addrs[i])
+				addrs = append(addrs[:i], addrs[i+1:]...)
+				weights = append(weights[:i], weights[i+1:]...)
+				break
+			}
+
+			pick.Sub(pick, w)
+		}
+	}
+
+	// Remaining sequencers have zero weight; keep them in sorted order.
+	return append(toReturn, addrs...), nil
+}
+
+// Contains checks if the given address is in the list of currently active sequencers.
+// It delegates the check to the underlying querier and returns the result.
+// An error is returned if the operation fails.
+func (wasq *weightedActiveSequencersQuerier) Contains(addr types.Address) (bool, error) {
+	return wasq.querier.Contains(addr, Sequencer)
+}
+
+// stakeOf returns the selection weight of the given sequencer based on the configured StakeWeight.
+func (wasq *weightedActiveSequencersQuerier) stakeOf(addr types.Address) (*big.Int, error) {
+	if wasq.weight == EffectiveStake {
+		info, err := wasq.querier.GetDelegationInfo(addr)
+		switch {
+		case err == nil && info != nil:
+			return info.TotalStake(), nil
+		case err != nil && !errors.Is(err, ErrUnsupportedByContract):
+			return nil, err
+		}
+	}
+
+	balance, err := wasq.querier.GetBalance(addr)
+	if errors.Is(err, ErrNotAParticipant) {
+		return new(big.Int), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if balance == nil {
+		return new(big.Int), nil
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,104 @@
+package staking
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+type stakedActiveSequencers struct {
+	staticActiveSequencers
+	ownStake       map[types.Address]*big.Int
+	delegatedStake map[types.Address]*big.Int
+}
+
+func (sas *stakedActiveSequencers) GetBalance(addr types.Address) (*big.Int, error) {
+	return sas.ownStake[addr], nil
+}
+
+func (sas *stakedActiveSequencers) GetDelegationInfo(addr types.Address) (*DelegationInfo, error) {
+	if sas.delegatedStake == nil {
+		return nil, fmt.Errorf("%w: GetDelegatedAmount", ErrUnsupportedByContract)
+	}
+
+	return &DelegationInfo{
+		OwnStake:       sas.ownStake[addr],
+		DelegatedStake: sas.delegatedStake[addr],
+	}, nil
+}
+
+func Test_WeightedSequencers(t *testing.T) {
+	small := types.StringToAddress("0x11444390fE555f166E44CD8Da9A60f295B4aEB42")
+	large := types.StringToAddress("0xAFF12c2B1df7D56144B3CbeDfb64B48d4F018D89")
+	unstaked := types.StringToAddress("0x20e6E89dAf166D929ccD459fefB51893a9dE4E00")
+
+	huge, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+
+	testCases := []struct {
+		name               string
+		weight             StakeWeight
+		ownStake           map[types.Address]*big.Int
+		delegatedStake     map[types.Address]*big.Int
+		expectedSequencers []types.Address
+	}{
+		{
+			name:   "own stake",
+			weight: OwnStake,
+			ownStake: map[types.Address]*big.Int{
+				small: big.NewInt(1),
+				large: huge,
+			},
+			delegatedStake:     map[types.Address]*big.Int{small: new(big.Int).Mul(huge, huge)},
+			expectedSequencers: []types.Address{large, small, unstaked},
+		},
+		{
+			name:   "effective stake",
+			weight: EffectiveStake,
+			ownStake: map[types.Address]*big.Int{
+				small: big.NewInt(1),
+				large: huge,
+			},
+			delegatedStake:     map[types.Address]*big.Int{small: new(big.Int).Mul(huge, huge)},
+			expectedSequencers: []types.Address{small, large, unstaked},
+		},
+		{
+			name:   "effective stake without delegation support falls back to own stake",
+			weight: EffectiveStake,
+			ownStake: map[types.Address]*big.Int{
+				small: big.NewInt(1),
+				large: huge,
+			},
+			delegatedStake:     nil,
+			expectedSequencers: []types.Address{large, small, unstaked},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			ap := &stakedActiveSequencers{
+				staticActiveSequencers: staticActiveSequencers{[]types.Address{unstaked, small, large}},
+				ownStake:               tc.ownStake,
+				delegatedStake:         tc.delegatedStake,
+			}
+
+			sqs := NewWeightedActiveSequencersQuerier(func() int64 { return 42 }, ap, tc.weight)
+
+			sequencers, err := sqs.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(sequencers) != len(tc.expectedSequencers) {
+				t.Fatalf("expected %d sequencers, got %d", len(tc.expectedSequencers), len(sequencers))
+			}
+
+			for i, s := range sequencers {
+				if s != tc.expectedSequencers[i] {
+					t.Fatalf("got address %q at index %d, expected %q", s.String(), i, tc.expectedSequencers[i].String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package staking
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/blockchain"
+	"github.com/hashicorp/go-hclog"
+)
+
+// simulationGasLimit is the gas limit of the simulated staking transactions, the same the stake helpers use.
+const simulationGasLimit = 1_000_000
+
+// SimulationResult is the outcome of a simulated staking transaction.
+type SimulationResult struct {
+	// Success is true if the transaction would succeed.
+	Success bool
+	// Err is the reason the transaction would fail, nil on success.
+	Err error
+	// RevertReason is the reason decoded from the return data when the contract reverts with one.
+	RevertReason string
+	// GasUsed is the gas used by the transaction.
+	GasUsed uint64
+	// Sequencers are the active sequencers after the transaction, i.e. the ones not in probation.
+	Sequencers []types.Address
+}
+
+// StakeSimulator dry-runs staking transactions on top of the chain head without committing them.
+type StakeSimulator interface {
+	// SimulateStake simulates staking the given amount as the given node type.
+	SimulateStake(from types.Address, amount *big.Int, nodeType NodeType) (*SimulationResult, error)
+
+	// SimulateUnstake simulates unstaking the stake of the given address.
+	SimulateUnstake(from types.Address) (*SimulationResult, error)
+}
+
+type stakeSimulator struct {
+	blockchain *blockchain.Blockchain
+	executor   *state.Executor
+	logger     hclog.Logger
+}
+
+// NewStakeSimulator creates a new instance of StakeSimulator.
+// It takes a blockchain, executor and logger as parameters.
+// Every simulation runs in a throwaway transition on top of the chain head, nothing is ever written.
+func NewStakeSimulator(blockchain *blockchain.Blockchain, executor *state.Executor, logger hclog.Logger) StakeSimulator {
+	return &stakeSimulator{
+		blockchain: blockchain,
+		executor:   executor,
+		logger:     logger.Named("staking_simulator"),
+	}
+}
+
+// SimulateStake builds the stake transaction with StakeTx and applies it in a throwaway transition.
+// It returns the simulation result, or an error if the transaction can't be built or the simulation can't run.
+func (ss *stakeSimulator) SimulateStake(from types.Address, amount *big.Int, nodeType NodeType) (*SimulationResult, error) {
+	tx, err := StakeTx(from, amount, string(nodeType), simulationGasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.simulate(tx)
+}
+
+// SimulateUnstake builds the unstake transaction with UnStakeTx and applies it in a throwaway transition.
+// It returns the simulation result, or an error if the transaction can't be built or the simulation can't run.
+func (ss *stakeSimulator) SimulateUnstake(from types.Address) (*SimulationResult, error) {
+	tx, err := UnStakeTx(from, simulationGasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.simulate(tx)
+}
+
+// simulate applies the transaction in a new transition on top of the chain head and queries the
+// resulting active sequencers from the same transition. The transition is discarded afterwards.
+func (ss *stakeSimulator) simulate(tx *types.Transaction) (*SimulationResult, error) {
+	parent := ss.blockchain.Header()
+	minerAddress := types.BytesToAddress(parent.Miner)
+
+	queryGasLimit, err := ss.blockchain.CalculateGasLimit(parent.Number + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &types.Header{
+		ParentHash: parent.Hash,
+		Number:     parent.Number + 1,
+		Miner:      minerAddress.Bytes(),
+		Nonce:      types.Nonce{},
+		// Fit the transaction and the two sequencer queries.
+		GasLimit:  tx.Gas + 2*queryGasLimit,
+		Timestamp: uint64(time.Now().Unix()),
+	}
+
+	transition, err := ss.executor.BeginTxn(parent.StateRoot, header, minerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Nonce = transition.GetNonce(tx.From)
+
+	result := &SimulationResult{}
+
+	res, err := transition.Apply(tx)
+
+	switch {
+	case err != nil:
+		result.Err = err
+	case res.Failed():
+		result.Err = res.Err
+		result.GasUsed = res.GasUsed
+		result.RevertReason = decodeRevertReason(res)
+	default:
+		result.Success = true
+		result.GasUsed = res.GasUsed
+	}
+
+	if !result.Success {
+		ss.logger.Debug("simulated staking transaction fails", "from", tx.From, "error", result.Err, "reason", result.RevertReason)
+	}
+
+	contract := DefaultStakingContract()
+
+	sequencers, err := contract.queryAddresses(transition, queryGasLimit, minerAddress, "GetCurrentSequencers", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	probationAddrs, err := contract.queryAddresses(transition, queryGasLimit, minerAddress, "GetCurrentSequencersInProbation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Sequencers = excludeAddresses(sequencers, probationAddrs)
+
+	return result, nil
+}
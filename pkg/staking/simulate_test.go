@@ -0,0 +1,72 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestStakeSimulator(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sequencer1, sequencer1SignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencer1, balance, blockchain, executor)
+	tAssert.NoError(Stake(blockchain, executor, NewTestAvailSender(), hclog.Default(), string(Sequencer), sequencer1, sequencer1SignKey, stakeAmount, 1_000_000, "test"))
+
+	sequencer2, _ := test.NewAccount(t)
+	test.DepositBalance(t, sequencer2, balance, blockchain, executor)
+
+	head := blockchain.Header()
+	simulator := NewStakeSimulator(blockchain, executor, hclog.Default())
+
+	res, err := simulator.SimulateStake(sequencer2, stakeAmount, Sequencer)
+	tAssert.NoError(err)
+	tAssert.True(res.Success)
+	tAssert.NoError(res.Err)
+	tAssert.NotZero(res.GasUsed)
+	tAssert.Equal([]types.Address{sequencer1, sequencer2}, res.Sequencers)
+
+	// Nothing is committed.
+	tAssert.Equal(head.Hash, blockchain.Header().Hash)
+
+	sequencers, err := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, sequencers)
+
+	// A stake below the threshold is reverted by the contract with a reason.
+	res, err = simulator.SimulateStake(sequencer2, big.NewInt(1), Sequencer)
+	tAssert.NoError(err)
+	tAssert.False(res.Success)
+	tAssert.Error(res.Err)
+	tAssert.NotEmpty(res.RevertReason)
+	tAssert.Equal([]types.Address{sequencer1}, res.Sequencers)
+
+	// An account without funds can't pay for the stake.
+	unfunded, _ := test.NewAccount(t)
+
+	res, err = simulator.SimulateStake(unfunded, stakeAmount, Sequencer)
+	tAssert.NoError(err)
+	tAssert.False(res.Success)
+	tAssert.Error(res.Err)
+	tAssert.Zero(res.GasUsed)
+
+	res, err = simulator.SimulateUnstake(sequencer1)
+	tAssert.NoError(err)
+	tAssert.True(res.Success)
+	tAssert.Empty(res.Sequencers)
+
+	sequencers, err = NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).Get(Sequencer)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{sequencer1}, sequencers)
+}
@@ -0,0 +1,262 @@
+package staking
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/availproject/op-evm/pkg/blockchain"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// ErrSlashedParticipant is returned when building a stake transaction for an address that has been slashed.
+var ErrSlashedParticipant = errors.New("address has been slashed")
+
+// The deployed staking contract doesn't keep the set of slashed addresses and its `Slashed` event records the
+// slasher instead of the slashed address. Unless the contract has the GetSlashedParticipants getter, the slashed
+// set is derived from the successful `slash` transactions included in the chain.
+
+// slashedCache holds the slashed addresses derived from the chain, extended with the blocks imported
+// since the last scan.
+type slashedCache struct {
+	lock sync.Mutex
+
+	// scanned is set once the blocks up to number, whose hash is hash, have been scanned.
+	scanned bool
+	number  uint64
+	hash    types.Hash
+
+	addrs []types.Address
+	seen  map[types.Address]struct{}
+}
+
+// QuerySlashedParticipants queries the slashed participants from the staking contract.
+// It takes a transaction transition, gas limit, and the address of the sender as parameters.
+// It returns a slice of addresses representing the slashed participants and an error if the operation fails.
+// The returned error wraps ErrUnsupportedByContract if the contract doesn't keep the slashed participants.
+func QuerySlashedParticipants(t *state.Transition, gasLimit uint64, from types.Address) ([]types.Address, error) {
+	method, ok := stakingMethod("GetSlashedParticipants")
+	if !ok {
+		return nil, fmt.Errorf("%w: GetSlashedParticipants", ErrUnsupportedByContract)
+	}
+
+	res, err := applyQuery(t, &types.Transaction{
+		From:     from,
+		To:       &AddrStakingContract,
+		Value:    big.NewInt(0),
+		Input:    method.ID(),
+		GasPrice: big.NewInt(0),
+		Gas:      gasLimit,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Failed() {
+		return nil, queryFailure(method.Name, res)
+	}
+
+	return DecodeParticipants(method, res.ReturnValue)
+}
+
+// IsSlashed method checks if the given address has been slashed.
+// The slashed participants are read from the contract if it keeps them. Otherwise they are derived from the
+// chain, scanning all the blocks once and only the newly imported ones afterwards.
+// It returns an error wrapping ErrUnsupportedByContract if the contract doesn't keep the slashed participants
+// and the querier can't read the blocks, i.e. it is bound to a state root.
+func (asq *activeParticipantsQuerier) IsSlashed(addr types.Address) (bool, error) {
+	slashed, err := asq.querySlashed(asq.head())
+	if err != nil {
+		return false, err
+	}
+
+	return containsAddress(slashed, addr), nil
+}
+
+// querySlashed returns the slashed participants on top of the parent header.
+func (asq *activeParticipantsQuerier) querySlashed(parent *types.Header) ([]types.Address, error) {
+	contract := asq.registry.Contract(Sequencer)
+
+	slashed, err := asq.queryAt(parent, func(t *state.Transition, gasLimit uint64) ([]types.Address, error) {
+		return contract.queryAddresses(t, gasLimit, types.BytesToAddress(parent.Miner), "GetSlashedParticipants", nil)
+	})
+	if !errors.Is(err, ErrUnsupportedByContract) || asq.blockchain == nil {
+		return slashed, err
+	}
+
+	return asq.slashed.update(asq.blockchain, parent)
+}
+
+// update scans the blocks imported since the last scan up to the parent header and returns all the slashed
+// addresses, in the order they were first slashed. The cache starts over if the last scanned block is no
+// longer part of the chain the parent header is on.
+func (sc *slashedCache) update(blockchain *blockchain.Blockchain, parent *types.Header) ([]types.Address, error) {
+	method, ok := stakingMethod("slash")
+	if !ok {
+		return nil, errors.New("slash method doesn't exist in Staking contract ABI")
+	}
+
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	if sc.scanned && !sc.isAncestor(blockchain, parent) {
+		sc.scanned = false
+		sc.addrs = nil
+		sc.seen = nil
+	}
+
+	fromBlock := uint64(0)
+	if sc.scanned {
+		fromBlock = sc.number + 1
+	}
+
+	if sc.seen == nil {
+		sc.seen = map[types.Address]struct{}{}
+	}
+
+	for n := fromBlock; n <= parent.Number; n++ {
+		hdr := parent
+		if n != parent.Number {
+			if hdr, ok = blockchain.GetHeaderByNumber(n); !ok {
+				return nil, fmt.Errorf("header for block %d not found", n)
+			}
+		}
+
+		addrs, err := slashedInBlock(blockchain, method, hdr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range addrs {
+			if _, ok := sc.seen[addr]; !ok {
+				sc.seen[addr] = struct{}{}
+				sc.addrs = append(sc.addrs, addr)
+			}
+		}
+
+		sc.scanned, sc.number, sc.hash = true, n, hdr.Hash
+	}
+
+	return append([]types.Address{}, sc.addrs...), nil
+}
+
+// isAncestor checks whether the last scanned block is the parent header or one of its canonical ancestors.
+func (sc *slashedCache) isAncestor(blockchain *blockchain.Blockchain, parent *types.Header) bool {
+	if sc.number > parent.Number {
+		return false
+	}
+
+	if sc.number == parent.Number {
+		return sc.hash == parent.Hash
+	}
+
+	hdr, ok := blockchain.GetHeaderByNumber(sc.number)
+
+	return ok && hdr.Hash == sc.hash
+}
+
+// FindSlashedParticipants walks the blocks in the inclusive block range and returns the addresses
+// slashed by a successful `slash` transaction, in the order they were first slashed.
+// The range is capped by MaxStakingHistoryRange.
+// It returns an error if the operation fails.
+func FindSlashedParticipants(blockchain *blockchain.Blockchain, fromBlock, toBlock uint64) ([]types.Address, error) {
+	if toBlock >= fromBlock && toBlock-fromBlock >= MaxStakingHistoryRange {
+		return nil, fmt.Errorf("%w: %d-%d exceeds %d blocks", ErrStakingHistoryRangeTooLarge, fromBlock, toBlock, MaxStakingHistoryRange)
+	}
+
+	method, ok := stakingMethod("slash")
+	if !ok {
+		return nil, errors.New("slash method doesn't exist in Staking contract ABI")
+	}
+
+	if head := blockchain.Header().Number; toBlock > head {
+		toBlock = head
+	}
+
+	slashed := []types.Address{}
+	seen := map[types.Address]struct{}{}
+
+	for n := fromBlock; n <= toBlock; n++ {
+		hdr, ok := blockchain.GetHeaderByNumber(n)
+		if !ok {
+			return nil, fmt.Errorf("header for block %d not found", n)
+		}
+
+		addrs, err := slashedInBlock(blockchain, method, hdr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range addrs {
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+
+			seen[addr] = struct{}{}
+			slashed = append(slashed, addr)
+		}
+	}
+
+	return slashed, nil
+}
+
+// slashedInBlock returns the addresses slashed by the successful `slash` transactions of the block with the given header.
+func slashedInBlock(blockchain *blockchain.Blockchain, method *abi.Method, hdr *types.Header) ([]types.Address, error) {
+	// Blocks without transactions can't slash anybody.
+	if hdr.TxRoot == types.EmptyRootHash {
+		return nil, nil
+	}
+
+	body, ok := blockchain.GetBodyByHash(hdr.Hash)
+	if !ok {
+		return nil, fmt.Errorf("body for block %d not found", hdr.Number)
+	}
+
+	receipts, err := blockchain.GetReceiptsByHash(hdr.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipts for block %d: %w", hdr.Number, err)
+	}
+
+	if len(receipts) != len(body.Transactions) {
+		return nil, fmt.Errorf("block %d has %d transactions but %d receipts", hdr.Number, len(body.Transactions), len(receipts))
+	}
+
+	selector := method.ID()
+
+	var slashed []types.Address
+
+	for i, tx := range body.Transactions {
+		if tx.To == nil || *tx.To != AddrStakingContract || !bytes.HasPrefix(tx.Input, selector) {
+			continue
+		}
+
+		if receipts[i].Status == nil || *receipts[i].Status != types.ReceiptSuccess {
+			continue
+		}
+
+		values, err := method.Inputs.Decode(tx.Input[len(selector):])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode slash transaction %s: %w", tx.Hash, err)
+		}
+
+		args, ok := values.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("failed type assertion from slash arguments to map")
+		}
+
+		addr, ok := args["slashAddr"].(ethgo.Address)
+		if !ok {
+			return nil, errors.New("failed type assertion from slashAddr to ethgo.Address")
+		}
+
+		slashed = append(slashed, types.Address(addr))
+	}
+
+	return slashed, nil
+}
@@ -0,0 +1,147 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestFindSlashedParticipants(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	maliciousAddr, maliciousSignKey := test.NewAccount(t)
+	test.DepositBalance(t, maliciousAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), maliciousAddr, maliciousSignKey, stakeAmount, 1_000_000, "test"))
+
+	slashed, err := FindSlashedParticipants(blockchain, 0, blockchain.Header().Number)
+	tAssert.NoError(err)
+	tAssert.Empty(slashed)
+
+	tAssert.NoError(Slash(blockchain, executor, hclog.Default(), sequencerAddr, sequencerSignKey, maliciousAddr, 1_000_000, "test"))
+
+	slashedAt := blockchain.Header().Number
+
+	slashed, err = FindSlashedParticipants(blockchain, 0, blockchain.Header().Number)
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{maliciousAddr}, slashed)
+
+	// Range before the slash.
+	slashed, err = FindSlashedParticipants(blockchain, 0, slashedAt-1)
+	tAssert.NoError(err)
+	tAssert.Empty(slashed)
+
+	_, err = FindSlashedParticipants(blockchain, 0, MaxStakingHistoryRange)
+	tAssert.True(errors.Is(err, ErrStakingHistoryRangeTooLarge))
+
+	slashed, err = FindSlashedParticipants(blockchain, 0, blockchain.Header().Number)
+	tAssert.NoError(err)
+
+	_, err = StakeTx(maliciousAddr, stakeAmount, string(Sequencer), 1_000_000, WithSlashedParticipants(slashed))
+	tAssert.True(errors.Is(err, ErrSlashedParticipant))
+
+	tx, err := StakeTx(maliciousAddr, stakeAmount, string(Sequencer), 1_000_000, WithSlashedParticipants(slashed), WithSlashedOverride())
+	tAssert.NoError(err)
+	tAssert.Equal(maliciousAddr, tx.From)
+
+	_, err = StakeTx(sequencerAddr, stakeAmount, string(Sequencer), 1_000_000, WithSlashedParticipants(slashed))
+	tAssert.NoError(err)
+}
+
+func TestQuerySlashedParticipantsUnsupported(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, types.BytesToAddress(parent.Miner))
+	tAssert.NoError(err)
+
+	_, err = QuerySlashedParticipants(transition, 1_000_000, types.BytesToAddress(parent.Miner))
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract), "unexpected error: %v", err)
+}
+
+func TestQuerierIsSlashed(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	maliciousAddr, maliciousSignKey := test.NewAccount(t)
+	test.DepositBalance(t, maliciousAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), maliciousAddr, maliciousSignKey, stakeAmount, 1_000_000, "test"))
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default()).(*activeParticipantsQuerier)
+
+	slashed, err := querier.IsSlashed(maliciousAddr)
+	tAssert.NoError(err)
+	tAssert.False(slashed)
+
+	scanned := querier.slashed.number
+	tAssert.Equal(blockchain.Header().Number, scanned)
+
+	snapshot, err := querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Empty(snapshot.Slashed)
+
+	tAssert.NoError(Slash(blockchain, executor, hclog.Default(), sequencerAddr, sequencerSignKey, maliciousAddr, 1_000_000, "test"))
+
+	// Only the blocks imported since the last scan are scanned.
+	slashed, err = querier.IsSlashed(maliciousAddr)
+	tAssert.NoError(err)
+	tAssert.True(slashed)
+	tAssert.Equal(blockchain.Header().Number, querier.slashed.number)
+	tAssert.Greater(querier.slashed.number, scanned)
+
+	slashed, err = querier.IsSlashed(sequencerAddr)
+	tAssert.NoError(err)
+	tAssert.False(slashed)
+
+	snapshot, err = querier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Equal([]types.Address{maliciousAddr}, snapshot.Slashed)
+
+	// A querier bound to a state root can't read the blocks.
+	head := blockchain.Header()
+	rootQuerier := NewParticipantsQuerierAtRoot(executor, head.StateRoot, head, hclog.Default())
+
+	_, err = rootQuerier.IsSlashed(maliciousAddr)
+	tAssert.True(errors.Is(err, ErrUnsupportedByContract), "unexpected error: %v", err)
+
+	snapshot, err = rootQuerier.Snapshot()
+	tAssert.NoError(err)
+	tAssert.Nil(snapshot.Slashed)
+}
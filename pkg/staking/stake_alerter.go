@@ -0,0 +1,239 @@
+package staking
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	edge_blockchain "github.com/0xPolygon/polygon-edge/blockchain"
+	"github.com/hashicorp/go-hclog"
+)
+
+// StakeAlertKind is the threshold a StakeAlert was raised for.
+type StakeAlertKind string
+
+const (
+	// StakeDropAlert is raised when the total stake drops by more than the drop percentage within the drop window.
+	StakeDropAlert StakeAlertKind = "stake_drop"
+	// StakeFloorAlert is raised when the total stake falls below the stake floor.
+	StakeFloorAlert StakeAlertKind = "stake_floor"
+	// SequencerFloorAlert is raised when the number of active sequencers falls below the sequencer floor.
+	SequencerFloorAlert StakeAlertKind = "sequencer_floor"
+)
+
+// StakeAlertThresholds are the thresholds the StakeAlerter evaluates. A zero threshold is disabled.
+type StakeAlertThresholds struct {
+	// DropPercentage is the drop of the total stake, in percent of the highest total stake within the drop window,
+	// that raises a StakeDropAlert.
+	DropPercentage uint64
+	// DropWindow is the number of blocks the drop of the total stake is measured over.
+	DropWindow uint64
+	// MinTotalStake is the total stake below which a StakeFloorAlert is raised.
+	MinTotalStake *big.Int
+	// MinSequencers is the number of active sequencers below which a SequencerFloorAlert is raised.
+	MinSequencers int
+}
+
+// StakeSample is the staking state of a block the StakeAlerter evaluates the thresholds on.
+type StakeSample struct {
+	// BlockNumber is the number of the block the state was read at.
+	BlockNumber uint64
+	// TotalStake is the total staked amount.
+	TotalStake *big.Int
+	// Sequencers is the number of active sequencers, i.e. the ones not in probation.
+	Sequencers int
+}
+
+// StakeAlert is the payload passed to the StakeAlerter callbacks when a threshold is crossed.
+type StakeAlert struct {
+	// Kind is the threshold that was crossed.
+	Kind StakeAlertKind
+	// Sample is the staking state that crossed the threshold.
+	Sample StakeSample
+	// Reference is the highest total stake within the drop window, only set for StakeDropAlert.
+	Reference *big.Int
+	// ReferenceBlock is the number of the block of the reference total stake, only set for StakeDropAlert.
+	ReferenceBlock uint64
+	// Thresholds are the thresholds the sample was evaluated on.
+	Thresholds StakeAlertThresholds
+}
+
+// StakeAlertFunc is a callback invoked with every alert raised by the StakeAlerter.
+type StakeAlertFunc func(alert StakeAlert)
+
+// StakeAlerter raises alerts when the total stake or the number of active sequencers cross the configured thresholds,
+// e.g. on a mass exit or an exploit draining the stake.
+// An alert is raised once when its threshold is crossed and isn't raised again until the state recovers above it.
+// The samples are kept in memory only; after a restart the alerter starts from an empty drop window.
+type StakeAlerter struct {
+	activeParticipants ActiveParticipants
+	thresholds         StakeAlertThresholds
+	logger             hclog.Logger
+
+	lock      sync.Mutex
+	callbacks []StakeAlertFunc
+	samples   []StakeSample
+	firing    map[StakeAlertKind]bool
+}
+
+// NewStakeAlerter creates a new instance of StakeAlerter.
+// It takes the active participants querier the snapshots are read with, the thresholds and a logger as parameters.
+func NewStakeAlerter(ap ActiveParticipants, thresholds StakeAlertThresholds, logger hclog.Logger) *StakeAlerter {
+	return &StakeAlerter{
+		activeParticipants: ap,
+		thresholds:         thresholds,
+		logger:             logger.Named("stake_alerter"),
+		firing:             make(map[StakeAlertKind]bool),
+	}
+}
+
+// OnAlert registers a callback invoked with every raised alert.
+// The callbacks are invoked synchronously, in the order they were registered.
+func (sa *StakeAlerter) OnAlert(fn StakeAlertFunc) {
+	sa.lock.Lock()
+	defer sa.lock.Unlock()
+
+	sa.callbacks = append(sa.callbacks, fn)
+}
+
+// Run evaluates the thresholds on the notifications received on the given channel, usually the
+// ParticipantWatcher's Changes channel, until the context is cancelled or the channel is closed.
+func (sa *StakeAlerter) Run(ctx context.Context, changes <-chan SetChanged) {
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+
+			if change.Current != nil {
+				sa.observeSnapshot(change.Current)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// HandleBlockEvent method evaluates the thresholds on the new chain head, so a stake drop without
+// a change of the participant set, e.g. a slash, is caught as well.
+func (sa *StakeAlerter) HandleBlockEvent(ev *edge_blockchain.Event) {
+	if ev.Type == edge_blockchain.EventFork || len(ev.NewChain) == 0 {
+		return
+	}
+
+	snapshot, err := sa.activeParticipants.Snapshot()
+	if err != nil {
+		sa.logger.Error("failed to query participants", "error", err)
+		return
+	}
+
+	sa.observeSnapshot(snapshot)
+}
+
+// observeSnapshot evaluates the thresholds on the participants snapshot and the total stake at its block.
+// The total stake is read along with the snapshot, so it always belongs to the block of the snapshot.
+func (sa *StakeAlerter) observeSnapshot(snapshot *ParticipantsSnapshot) {
+	if snapshot.TotalStake == nil {
+		sa.logger.Error("participants snapshot without total stake", "block_number", snapshot.BlockNumber)
+		return
+	}
+
+	sa.Observe(StakeSample{
+		BlockNumber: snapshot.BlockNumber,
+		TotalStake:  snapshot.TotalStake,
+		Sequencers:  len(snapshot.Sequencers),
+	})
+}
+
+// Observe evaluates the thresholds on the sample and invokes the callbacks with the alerts it raises.
+// A sample for a block lower than or equal to the last observed one, e.g. after a reorg, replaces
+// the samples from that block on.
+func (sa *StakeAlerter) Observe(sample StakeSample) {
+	if sample.TotalStake == nil {
+		return
+	}
+
+	sa.lock.Lock()
+	alerts := sa.observeLocked(sample)
+	callbacks := sa.callbacks
+	sa.lock.Unlock()
+
+	for _, alert := range alerts {
+		sa.logger.Warn("stake alert", "kind", alert.Kind, "block_number", alert.Sample.BlockNumber, "total_stake", alert.Sample.TotalStake, "sequencers", alert.Sample.Sequencers)
+
+		for _, fn := range callbacks {
+			fn(alert)
+		}
+	}
+}
+
+// observeLocked records the sample and returns the alerts it raises. The lock must be held by the caller.
+func (sa *StakeAlerter) observeLocked(sample StakeSample) []StakeAlert {
+	kept := 0
+	for kept < len(sa.samples) && sa.samples[kept].BlockNumber < sample.BlockNumber {
+		kept++
+	}
+
+	sa.samples = append(sa.samples[:kept], sample)
+
+	if sample.BlockNumber >= sa.thresholds.DropWindow {
+		cutoff := sample.BlockNumber - sa.thresholds.DropWindow
+
+		expired := 0
+		for expired < len(sa.samples) && sa.samples[expired].BlockNumber < cutoff {
+			expired++
+		}
+
+		sa.samples = sa.samples[expired:]
+	}
+
+	var alerts []StakeAlert
+
+	// raise returns an alert of the kind when its threshold is crossed for the first time since the last recovery.
+	raise := func(kind StakeAlertKind, crossed bool) bool {
+		wasFiring := sa.firing[kind]
+		sa.firing[kind] = crossed
+
+		return crossed && !wasFiring
+	}
+
+	if sa.thresholds.DropPercentage > 0 {
+		reference := sa.samples[0]
+		for _, s := range sa.samples[1:] {
+			if s.TotalStake.Cmp(reference.TotalStake) > 0 {
+				reference = s
+			}
+		}
+
+		// The stake dropped by more than the percentage if drop * 100 > percentage * reference.
+		drop := new(big.Int).Sub(reference.TotalStake, sample.TotalStake)
+		drop.Mul(drop, big.NewInt(100))
+
+		limit := new(big.Int).Mul(reference.TotalStake, new(big.Int).SetUint64(sa.thresholds.DropPercentage))
+
+		if raise(StakeDropAlert, drop.Cmp(limit) > 0) {
+			alerts = append(alerts, StakeAlert{
+				Kind:           StakeDropAlert,
+				Sample:         sample,
+				Reference:      new(big.Int).Set(reference.TotalStake),
+				ReferenceBlock: reference.BlockNumber,
+				Thresholds:     sa.thresholds,
+			})
+		}
+	}
+
+	if sa.thresholds.MinTotalStake != nil && sa.thresholds.MinTotalStake.Sign() > 0 {
+		if raise(StakeFloorAlert, sample.TotalStake.Cmp(sa.thresholds.MinTotalStake) < 0) {
+			alerts = append(alerts, StakeAlert{Kind: StakeFloorAlert, Sample: sample, Thresholds: sa.thresholds})
+		}
+	}
+
+	if sa.thresholds.MinSequencers > 0 {
+		if raise(SequencerFloorAlert, sample.Sequencers < sa.thresholds.MinSequencers) {
+			alerts = append(alerts, StakeAlert{Kind: SequencerFloorAlert, Sample: sample, Thresholds: sa.thresholds})
+		}
+	}
+
+	return alerts
+}
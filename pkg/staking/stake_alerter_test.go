@@ -0,0 +1,132 @@
+package staking
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	edge_blockchain "github.com/0xPolygon/polygon-edge/blockchain"
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+type stakeCurvePoint struct {
+	stake      int64
+	sequencers int
+}
+
+func driveStakeCurve(alerter *StakeAlerter, fromBlock uint64, curve []stakeCurvePoint) {
+	for i, point := range curve {
+		alerter.Observe(StakeSample{
+			BlockNumber: fromBlock + uint64(i),
+			TotalStake:  big.NewInt(point.stake),
+			Sequencers:  point.sequencers,
+		})
+	}
+}
+
+func TestStakeAlerterDrop(t *testing.T) {
+	tAssert := assert.New(t)
+
+	alerter := NewStakeAlerter(new(DumbActiveParticipants), StakeAlertThresholds{DropPercentage: 20, DropWindow: 3}, hclog.NewNullLogger())
+
+	var alerts []StakeAlert
+	alerter.OnAlert(func(alert StakeAlert) { alerts = append(alerts, alert) })
+
+	// A slow decline stays within the percentage in every window.
+	driveStakeCurve(alerter, 1, []stakeCurvePoint{{1000, 3}, {950, 3}, {900, 3}, {850, 3}, {800, 3}, {750, 3}})
+	tAssert.Empty(alerts)
+
+	// A steep drop fires once, even while the stake keeps falling.
+	driveStakeCurve(alerter, 7, []stakeCurvePoint{{500, 3}, {450, 3}, {400, 3}})
+	tAssert.Len(alerts, 1)
+	tAssert.Equal(StakeDropAlert, alerts[0].Kind)
+	tAssert.Equal(uint64(7), alerts[0].Sample.BlockNumber)
+	tAssert.Equal(big.NewInt(850), alerts[0].Reference)
+	tAssert.Equal(uint64(4), alerts[0].ReferenceBlock)
+
+	// The drop falls out of the window, which re-arms the alert, and a new drop fires again.
+	driveStakeCurve(alerter, 10, []stakeCurvePoint{{400, 3}, {400, 3}, {400, 3}, {300, 3}})
+	tAssert.Len(alerts, 2)
+	tAssert.Equal(uint64(13), alerts[1].Sample.BlockNumber)
+	tAssert.Equal(big.NewInt(400), alerts[1].Reference)
+}
+
+func TestStakeAlerterFloors(t *testing.T) {
+	tAssert := assert.New(t)
+
+	alerter := NewStakeAlerter(new(DumbActiveParticipants), StakeAlertThresholds{MinTotalStake: big.NewInt(500), MinSequencers: 2}, hclog.NewNullLogger())
+
+	var kinds []StakeAlertKind
+	alerter.OnAlert(func(alert StakeAlert) { kinds = append(kinds, alert.Kind) })
+
+	// The drop threshold is disabled, only the floors are evaluated.
+	driveStakeCurve(alerter, 1, []stakeCurvePoint{{1000, 3}, {100, 3}, {90, 3}, {80, 1}, {70, 1}})
+	tAssert.Equal([]StakeAlertKind{StakeFloorAlert, SequencerFloorAlert}, kinds)
+
+	// Recovering above the floors re-arms them.
+	driveStakeCurve(alerter, 6, []stakeCurvePoint{{500, 2}, {600, 2}, {499, 1}})
+	tAssert.Equal([]StakeAlertKind{StakeFloorAlert, SequencerFloorAlert, StakeFloorAlert, SequencerFloorAlert}, kinds)
+}
+
+func TestStakeAlerterReorg(t *testing.T) {
+	tAssert := assert.New(t)
+
+	alerter := NewStakeAlerter(new(DumbActiveParticipants), StakeAlertThresholds{DropPercentage: 50, DropWindow: 10}, hclog.NewNullLogger())
+
+	var alerts []StakeAlert
+	alerter.OnAlert(func(alert StakeAlert) { alerts = append(alerts, alert) })
+
+	driveStakeCurve(alerter, 1, []stakeCurvePoint{{100, 1}, {1000, 1}})
+
+	// Block 2 is reorged out, the stake on the new chain never was 1000.
+	driveStakeCurve(alerter, 2, []stakeCurvePoint{{100, 1}, {400, 1}})
+	tAssert.Empty(alerts)
+}
+
+// headStakeParticipants returns the snapshots it's set to, and the total stake of a later head.
+type headStakeParticipants struct {
+	mutableActiveParticipants
+
+	queries int
+}
+
+func (hsp *headStakeParticipants) GetTotalStakedAmount() (*big.Int, error) {
+	hsp.queries++
+
+	return big.NewInt(1), nil
+}
+
+func TestStakeAlerterRun(t *testing.T) {
+	tAssert := assert.New(t)
+
+	sequencer1 := types.StringToAddress("1")
+	sequencer2 := types.StringToAddress("2")
+
+	ap := &headStakeParticipants{}
+
+	alerter := NewStakeAlerter(ap, StakeAlertThresholds{DropPercentage: 10, DropWindow: 5, MinSequencers: 2}, hclog.NewNullLogger())
+
+	var kinds []StakeAlertKind
+	alerter.OnAlert(func(alert StakeAlert) { kinds = append(kinds, alert.Kind) })
+
+	snapshot1 := &ParticipantsSnapshot{BlockNumber: 1, BlockHash: types.StringToHash("1"), Sequencers: []types.Address{sequencer1, sequencer2}, TotalStake: big.NewInt(1000)}
+	snapshot2 := &ParticipantsSnapshot{BlockNumber: 2, BlockHash: types.StringToHash("2"), Sequencers: []types.Address{sequencer1}, SequencersInProbation: []types.Address{sequencer2}, TotalStake: big.NewInt(100)}
+
+	changes := make(chan SetChanged, 2)
+	changes <- SetChanged{Current: snapshot1}
+	changes <- SetChanged{Previous: snapshot1, Current: snapshot2}
+	close(changes)
+
+	alerter.Run(context.Background(), changes)
+	tAssert.Equal([]StakeAlertKind{StakeDropAlert, SequencerFloorAlert}, kinds)
+
+	// The block event for the same head evaluates the same snapshot and doesn't fire again.
+	ap.set(*snapshot2)
+	alerter.HandleBlockEvent(&edge_blockchain.Event{Type: edge_blockchain.EventHead, NewChain: []*types.Header{{Number: 2}}})
+	tAssert.Len(kinds, 2)
+
+	// The total stakes are the ones of the blocks of the snapshots, not of the current head.
+	tAssert.Equal(0, ap.queries)
+}
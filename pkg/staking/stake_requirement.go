@@ -0,0 +1,69 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/state"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// ErrSingleStakeThreshold is returned together with the global staking threshold when the staking contract
+// doesn't have a stake requirement per node type. It wraps ErrUnsupportedByContract.
+var ErrSingleStakeThreshold = fmt.Errorf("%w: per node type stake requirement, using the global staking threshold", ErrUnsupportedByContract)
+
+// stakeRequirementMethods are the staking contract getters of the minimum stake of every node type.
+var stakeRequirementMethods = map[NodeType]string{
+	Sequencer:  "GetCurrentSequencerStakingThreshold",
+	WatchTower: "GetCurrentWatchtowerStakingThreshold",
+}
+
+// GetStakeRequirement method retrieves the minimum stake required to stake as the given node type.
+// If the contract only has a single global threshold, it returns the global threshold together with
+// an error wrapping ErrSingleStakeThreshold, so callers can fall back to it.
+func (asq *activeParticipantsQuerier) GetStakeRequirement(nodeType NodeType) (*big.Int, error) {
+	methodName, ok := stakeRequirementMethods[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type: %q", nodeType)
+	}
+
+	parent := asq.head()
+	contract := asq.registry.Contract(nodeType)
+
+	requirement, err := asq.queryAmount(parent, contract, methodName, nil)
+	if !errors.Is(err, ErrUnsupportedByContract) {
+		return requirement, err
+	}
+
+	threshold, err := asq.queryAmount(parent, contract, "GetCurrentStakingThreshold", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return threshold, ErrSingleStakeThreshold
+}
+
+// QueryStakeRequirement queries the minimum stake required to stake as the given node type from the staking contract.
+// It takes a transaction transition, gas limit, the address of the sender, and the node type as parameters.
+// It returns the stake requirement as a big.Int value and an error if the operation fails.
+// If the contract only has a single global threshold, it returns the global threshold together with
+// an error wrapping ErrSingleStakeThreshold.
+func QueryStakeRequirement(t *state.Transition, gasLimit uint64, from types.Address, nodeType NodeType) (*big.Int, error) {
+	methodName, ok := stakeRequirementMethods[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type: %q", nodeType)
+	}
+
+	requirement, err := DefaultStakingContract().queryAmount(t, gasLimit, from, methodName, nil)
+	if !errors.Is(err, ErrUnsupportedByContract) {
+		return requirement, err
+	}
+
+	threshold, err := GetThresholdTx(t, gasLimit, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return threshold, ErrSingleStakeThreshold
+}
@@ -0,0 +1,61 @@
+package staking
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+func TestGetStakeRequirementSingleThreshold(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	defaultStakingThresholdAmount := big.NewInt(0).Mul(big.NewInt(1), commontoken.ETH)
+
+	querier := NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())
+
+	// The deployed contract only has the global threshold, which both node types fall back to.
+	for _, nodeType := range []NodeType{Sequencer, WatchTower} {
+		requirement, err := querier.GetStakeRequirement(nodeType)
+		tAssert.True(errors.Is(err, ErrSingleStakeThreshold), "unexpected error: %v", err)
+		tAssert.True(errors.Is(err, ErrUnsupportedByContract))
+		tAssert.Equal(defaultStakingThresholdAmount, requirement)
+	}
+
+	_, err = querier.GetStakeRequirement(NodeType("unknown"))
+	tAssert.Error(err)
+	tAssert.False(errors.Is(err, ErrUnsupportedByContract))
+
+	parent := blockchain.Header()
+	transition, err := executor.BeginTxn(parent.StateRoot, parent, types.ZeroAddress)
+	tAssert.NoError(err)
+
+	requirement, err := QueryStakeRequirement(transition, 1_000_000, types.ZeroAddress, WatchTower)
+	tAssert.True(errors.Is(err, ErrSingleStakeThreshold), "unexpected error: %v", err)
+	tAssert.Equal(defaultStakingThresholdAmount, requirement)
+}
+
+func TestStakeTxWithStakeRequirement(t *testing.T) {
+	tAssert := assert.New(t)
+
+	requirement := ToStakeUnits(10)
+
+	_, err := StakeTx(types.ZeroAddress, ToStakeUnits(5), string(WatchTower), 1_000_000, WithStakeRequirement(requirement))
+	tAssert.True(errors.Is(err, ErrInvalidStakeAmount), "unexpected error: %v", err)
+
+	tx, err := StakeTx(types.ZeroAddress, requirement, string(WatchTower), 1_000_000, WithStakeRequirement(requirement))
+	tAssert.NoError(err)
+	tAssert.Equal(requirement, tx.Value)
+
+	// A nil requirement doesn't restrict the amount.
+	_, err = StakeTx(types.ZeroAddress, ToStakeUnits(5), string(WatchTower), 1_000_000, WithStakeRequirement(nil))
+	tAssert.NoError(err)
+}
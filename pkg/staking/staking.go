@@ -3,16 +3,15 @@ package staking
 import (
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/0xPolygon/polygon-edge/helper/common"
 	"github.com/0xPolygon/polygon-edge/state"
-	"github.com/availproject/op-evm-contracts/staking/pkg/staking"
 	"github.com/availproject/op-evm/pkg/block"
 	"github.com/availproject/op-evm/pkg/blockchain"
-	commontoken "github.com/availproject/op-evm/pkg/common"
 	"github.com/hashicorp/go-hclog"
-	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo"
 
 	"github.com/0xPolygon/polygon-edge/types"
 )
@@ -126,9 +125,63 @@ func Slash(bh *blockchain.Blockchain, exec *state.Executor, logger hclog.Logger,
 	return nil
 }
 
+// stakeTxConfig is the configuration of the stake transaction builder.
+type stakeTxConfig struct {
+	slashed      map[types.Address]struct{}
+	allowSlashed bool
+	requirement  *big.Int
+}
+
+// StakeTxOption configures the stake transaction builder.
+type StakeTxOption func(*stakeTxConfig)
+
+// WithSlashedParticipants makes StakeTx refuse to build a stake transaction for any of the given slashed
+// addresses, see ActiveParticipants.IsSlashed and QuerySlashedParticipants.
+func WithSlashedParticipants(addrs []types.Address) StakeTxOption {
+	return func(cfg *stakeTxConfig) {
+		cfg.slashed = addressSet(addrs)
+	}
+}
+
+// WithSlashedOverride builds the stake transaction even if the address has been slashed.
+func WithSlashedOverride() StakeTxOption {
+	return func(cfg *stakeTxConfig) {
+		cfg.allowSlashed = true
+	}
+}
+
+// WithStakeRequirement makes StakeTx refuse to build a stake transaction for less than the stake requirement
+// of the node type, see GetStakeRequirement.
+func WithStakeRequirement(requirement *big.Int) StakeTxOption {
+	return func(cfg *stakeTxConfig) {
+		cfg.requirement = requirement
+	}
+}
+
 // StakeTx returns a stake transaction with the specified parameters.
-func StakeTx(from types.Address, amount *big.Int, nodeType string, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking.StakingABI).Methods["stake"]
+// The amount is in wei, use ToStakeUnits or ParseStakeAmount to convert an amount of tokens.
+// It returns an error wrapping ErrSlashedParticipant if the address is one of the slashed participants
+// given with WithSlashedParticipants, unless WithSlashedOverride is given as well, and an error wrapping
+// ErrInvalidStakeAmount if the amount is below the requirement given with WithStakeRequirement.
+func StakeTx(from types.Address, amount *big.Int, nodeType string, gasLimit uint64, opts ...StakeTxOption) (*types.Transaction, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: stake must be positive, got %s", ErrInvalidStakeAmount, amount)
+	}
+
+	cfg := &stakeTxConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if _, ok := cfg.slashed[from]; ok && !cfg.allowSlashed {
+		return nil, fmt.Errorf("%w: refusing to stake %s", ErrSlashedParticipant, from)
+	}
+
+	if cfg.requirement != nil && amount.Cmp(cfg.requirement) < 0 {
+		return nil, fmt.Errorf("%w: stake of %s is below the %s stake requirement of %s", ErrInvalidStakeAmount, amount, nodeType, cfg.requirement)
+	}
+
+	method, ok := stakingMethod("stake")
 	if !ok {
 		return nil, errors.New("stake method doesn't exist in Staking contract ABI")
 	}
@@ -147,7 +200,7 @@ func StakeTx(from types.Address, amount *big.Int, nodeType string, gasLimit uint
 	tx := &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
-		Value:    big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH), // 10 ETH
+		Value:    new(big.Int).Set(amount),
 		Input:    append(selector, encodedInput...),
 		GasPrice: big.NewInt(5000),
 		Gas:      gasLimit,
@@ -156,9 +209,20 @@ func StakeTx(from types.Address, amount *big.Int, nodeType string, gasLimit uint
 	return tx, nil
 }
 
+// StakeTxWithTokens returns a stake transaction for a decimal amount of tokens, like "1.5".
+// The amount is converted to wei with ParseStakeAmount.
+func StakeTxWithTokens(from types.Address, tokens string, nodeType string, gasLimit uint64, opts ...StakeTxOption) (*types.Transaction, error) {
+	amount, err := ParseStakeAmount(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return StakeTx(from, amount, nodeType, gasLimit, opts...)
+}
+
 // UnStakeTx returns an unstake transaction for the specified address.
 func UnStakeTx(from types.Address, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking.StakingABI).Methods["unstake"]
+	method, ok := stakingMethod("unstake")
 	if !ok {
 		return nil, errors.New("unstake method doesn't exist in Staking contract ABI")
 	}
@@ -179,7 +243,7 @@ func UnStakeTx(from types.Address, gasLimit uint64) (*types.Transaction, error)
 
 // SlashStakerTx returns a slash transaction to slash the malicious staker address.
 func SlashStakerTx(activeSequencerAddr types.Address, maliciousStakerAddr types.Address, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking.StakingABI).Methods["slash"]
+	method, ok := stakingMethod("slash")
 	if !ok {
 		return nil, errors.New("Slash method doesn't exist in Staking contract ABI")
 	}
@@ -188,7 +252,7 @@ func SlashStakerTx(activeSequencerAddr types.Address, maliciousStakerAddr types.
 
 	encodedInput, encodeErr := method.Inputs.Encode(
 		map[string]interface{}{
-			"slashAddr": maliciousStakerAddr.Bytes(),
+			"slashAddr": ethgo.Address(maliciousStakerAddr),
 		},
 	)
 	if encodeErr != nil {
@@ -0,0 +1,52 @@
+package staking
+
+import (
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/crypto"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// Storage slots of the staking contract state variables, in declaration order.
+// Dynamic arrays hold their length in the slot and their elements starting at keccak256(slot),
+// mappings hold their values at keccak256(key . slot).
+const (
+	availableNodeTypesSlot        = 0
+	minStakingThresholdSlot       = 1
+	slashPercentageSlot           = 2
+	participantsSlot              = 3
+	addressToIsParticipantSlot    = 4
+	addressToStakedAmountSlot     = 5
+	addressToParticipantIndexSlot = 6
+	stakedAmountSlot              = 8
+	minNumParticipantsSlot        = 9
+	maxNumParticipantsSlot        = 10
+	minNumSequencersSlot          = 11
+	minNumWatchtowersSlot         = 12
+	maxNumSequencersSlot          = 13
+	maxNumWatchtowersSlot         = 14
+	sequencersSlot                = 15
+	addressToIsSequencerSlot      = 16
+	addressToSequencerIndexSlot   = 17
+	sequencersInProbationSlot     = 18
+	watchtowersSlot               = 21
+	addressToIsWatchtowerSlot     = 22
+	addressToWatchtowerIndexSlot  = 23
+)
+
+// slotKey returns the storage key of the given slot.
+func slotKey(slot int64) types.Hash {
+	return types.BytesToHash(big.NewInt(slot).Bytes())
+}
+
+// arrayElementKey returns the storage key of the element at the given index of the dynamic array stored at the slot.
+func arrayElementKey(slot int64, index int) types.Hash {
+	base := new(big.Int).SetBytes(crypto.Keccak256(slotKey(slot).Bytes()))
+
+	return types.BytesToHash(base.Add(base, big.NewInt(int64(index))).Bytes())
+}
+
+// mappingKey returns the storage key of the value for the given address in the mapping stored at the slot.
+func mappingKey(slot int64, addr types.Address) types.Hash {
+	return types.BytesToHash(crypto.Keccak256(types.BytesToHash(addr.Bytes()).Bytes(), slotKey(slot).Bytes()))
+}
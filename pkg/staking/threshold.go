@@ -9,11 +9,9 @@ import (
 	edge_crypto "github.com/0xPolygon/polygon-edge/crypto"
 	"github.com/0xPolygon/polygon-edge/state"
 	"github.com/0xPolygon/polygon-edge/types"
-	staking_contract "github.com/availproject/op-evm-contracts/staking/pkg/staking"
 	"github.com/availproject/op-evm/pkg/block"
 	"github.com/availproject/op-evm/pkg/blockchain"
 	"github.com/hashicorp/go-hclog"
-	"github.com/umbracle/ethgo/abi"
 )
 
 // Threshold represents an interface for managing the staking threshold.
@@ -104,7 +102,7 @@ func (st *threshold) Current() (*big.Int, error) {
 
 // SetThresholdTx returns a transaction to set the staking threshold.
 func SetThresholdTx(from types.Address, amount *big.Int, gasLimit uint64) (*types.Transaction, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["SetStakingMinThreshold"]
+	method, ok := stakingMethod("SetStakingMinThreshold")
 	if !ok {
 		return nil, errors.New("SetStakingMinThreshold method doesn't exist in Staking contract ABI")
 	}
@@ -132,20 +130,19 @@ func SetThresholdTx(from types.Address, amount *big.Int, gasLimit uint64) (*type
 
 // GetThresholdTx returns the current staking threshold from the transition state.
 func GetThresholdTx(t *state.Transition, gasLimit uint64, from types.Address) (*big.Int, error) {
-	method, ok := abi.MustNewABI(staking_contract.StakingABI).Methods["GetCurrentStakingThreshold"]
+	method, ok := stakingMethod("GetCurrentStakingThreshold")
 	if !ok {
 		return nil, errors.New("GetCurrentStakingThreshold method doesn't exist in Staking contract ABI")
 	}
 
 	selector := method.ID()
-	res, err := t.Apply(&types.Transaction{
+	res, err := applyQuery(t, &types.Transaction{
 		From:     from,
 		To:       &AddrStakingContract,
 		Value:    big.NewInt(0),
 		Input:    selector,
 		GasPrice: big.NewInt(0),
 		Gas:      gasLimit,
-		Nonce:    t.GetNonce(from),
 	})
 
 	if err != nil {
@@ -153,7 +150,7 @@ func GetThresholdTx(t *state.Transition, gasLimit uint64, from types.Address) (*
 	}
 
 	if res.Failed() {
-		return nil, res.Err
+		return nil, queryFailure(method.Name, res)
 	}
 
 	toReturn := new(big.Int)
@@ -0,0 +1,174 @@
+package staking
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	edge_blockchain "github.com/0xPolygon/polygon-edge/blockchain"
+	"github.com/0xPolygon/polygon-edge/types"
+)
+
+// defaultTxGateMaxAge is the longest the StakingTxGate serves the participant sets without refreshing them,
+// when it isn't fed the block events.
+const defaultTxGateMaxAge = 2 * time.Second
+
+// txSenderRole is the role the sender of a staking contract transaction must have.
+type txSenderRole int
+
+const (
+	// anySender is for the methods anybody may call, e.g. stake to become a participant.
+	anySender txSenderRole = iota
+	// registeredSender is for the methods only a registered participant may call, including a sequencer in probation.
+	registeredSender
+	// activeSequencerSender is for the methods only an active sequencer may call.
+	activeSequencerSender
+	// activeWatchtowerSender is for the methods only an active watchtower may call.
+	activeWatchtowerSender
+)
+
+// txSenderRoles maps the restricted staking contract methods to the role their sender must have.
+// Methods missing here, e.g. the governance setters, are left to the access control of the contract.
+var txSenderRoles = map[string]txSenderRole{
+	"stake":                  anySender,
+	"unstake":                registeredSender,
+	"slash":                  activeSequencerSender,
+	"EndDisputeResolution":   activeSequencerSender,
+	"BeginDisputeResolution": activeWatchtowerSender,
+}
+
+// txGateMembership holds the participant sets the StakingTxGate checks the senders against.
+type txGateMembership struct {
+	fetchedAt        time.Time
+	registered       map[types.Address]struct{}
+	activeSequencers map[types.Address]struct{}
+	watchtowers      map[types.Address]struct{}
+}
+
+// StakingTxGate decides whether a transaction may enter the txpool based on the staking status of its sender.
+// Transactions calling a restricted method of the staking contract are only allowed from senders with the role the
+// method requires, e.g. a fraud proof only from an active watchtower. Every other transaction is always allowed.
+// It's meant to be plugged into the validation of the txpool.
+type StakingTxGate struct {
+	activeParticipants ActiveParticipants
+	maxAge             time.Duration
+
+	lock       sync.Mutex
+	membership *txGateMembership
+}
+
+// StakingTxGateOption is a function type used to configure the StakingTxGate.
+type StakingTxGateOption func(gate *StakingTxGate)
+
+// WithTxGateMaxAge sets the longest the gate serves the participant sets without refreshing them.
+// The sets are refreshed anyway on every block event the gate is fed.
+func WithTxGateMaxAge(maxAge time.Duration) StakingTxGateOption {
+	return func(gate *StakingTxGate) {
+		if maxAge > 0 {
+			gate.maxAge = maxAge
+		}
+	}
+}
+
+// NewStakingTxGate creates a new instance of StakingTxGate.
+// It takes the active participants querier the staking status of the senders is checked with as a parameter.
+func NewStakingTxGate(ap ActiveParticipants, opts ...StakingTxGateOption) *StakingTxGate {
+	gate := &StakingTxGate{
+		activeParticipants: ap,
+		maxAge:             defaultTxGateMaxAge,
+	}
+
+	for _, opt := range opts {
+		opt(gate)
+	}
+
+	return gate
+}
+
+// Allowed checks whether the transaction may enter the txpool.
+// Transactions that don't call a restricted method of the staking contract are allowed without any query.
+// The participant sets are cached, so checking many transactions doesn't query the contract for each one.
+// It returns an error if the participant sets can't be queried.
+func (g *StakingTxGate) Allowed(tx *types.Transaction) (bool, error) {
+	if tx.To == nil || *tx.To != AddrStakingContract || len(tx.Input) < 4 {
+		return true, nil
+	}
+
+	role, ok := restrictedTxSenderRole(tx.Input[:4])
+	if !ok || role == anySender {
+		return true, nil
+	}
+
+	membership, err := g.currentMembership()
+	if err != nil {
+		return false, err
+	}
+
+	var set map[types.Address]struct{}
+
+	switch role {
+	case registeredSender:
+		set = membership.registered
+	case activeSequencerSender:
+		set = membership.activeSequencers
+	case activeWatchtowerSender:
+		set = membership.watchtowers
+	}
+
+	_, allowed := set[tx.From]
+
+	return allowed, nil
+}
+
+// restrictedTxSenderRole returns the role the sender of a call with the given selector must have,
+// if the selector is the one of a restricted staking contract method.
+func restrictedTxSenderRole(selector []byte) (txSenderRole, bool) {
+	for methodName, role := range txSenderRoles {
+		if method, ok := stakingMethod(methodName); ok && bytes.Equal(method.ID(), selector) {
+			return role, true
+		}
+	}
+
+	return anySender, false
+}
+
+// HandleBlockEvent method drops the cached participant sets, the staking status of the senders
+// may have changed with the new blocks.
+func (g *StakingTxGate) HandleBlockEvent(ev *edge_blockchain.Event) {
+	if ev.Type == edge_blockchain.EventFork {
+		return
+	}
+
+	g.lock.Lock()
+	g.membership = nil
+	g.lock.Unlock()
+}
+
+// currentMembership returns the cached participant sets, refreshing them from a participants snapshot if they're too old.
+func (g *StakingTxGate) currentMembership() (*txGateMembership, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.membership != nil && time.Since(g.membership.fetchedAt) < g.maxAge {
+		return g.membership, nil
+	}
+
+	snapshot, err := g.activeParticipants.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	registered := addressSet(snapshot.RegisteredSequencers)
+	for _, addr := range snapshot.WatchTowers {
+		registered[addr] = struct{}{}
+	}
+
+	g.membership = &txGateMembership{
+		fetchedAt:        time.Now(),
+		registered:       registered,
+		activeSequencers: addressSet(snapshot.Sequencers),
+		watchtowers:      addressSet(snapshot.WatchTowers),
+	}
+
+	return g.membership, nil
+}
@@ -0,0 +1,108 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	edge_blockchain "github.com/0xPolygon/polygon-edge/blockchain"
+	"github.com/0xPolygon/polygon-edge/types"
+	commontoken "github.com/availproject/op-evm/pkg/common"
+	"github.com/availproject/op-evm/pkg/test"
+	"github.com/hashicorp/go-hclog"
+	"github.com/test-go/testify/assert"
+)
+
+// snapshotCounter counts the snapshots taken through the wrapped active participants.
+type snapshotCounter struct {
+	ActiveParticipants
+	snapshots int
+}
+
+func (sc *snapshotCounter) Snapshot() (*ParticipantsSnapshot, error) {
+	sc.snapshots++
+	return sc.ActiveParticipants.Snapshot()
+}
+
+func TestStakingTxGate(t *testing.T) {
+	tAssert := assert.New(t)
+
+	executor, blockchain, err := test.NewBlockchain(NewVerifier(new(DumbActiveParticipants), hclog.Default()), getGenesisBasePath())
+	tAssert.NoError(err)
+
+	stakeAmount := big.NewInt(0).Mul(big.NewInt(10), commontoken.ETH)
+	balance := big.NewInt(0).Mul(big.NewInt(1000), commontoken.ETH)
+
+	sender := NewTestAvailSender()
+
+	watchtowerAddr, watchtowerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, watchtowerAddr, balance, blockchain, executor)
+
+	sequencerAddr, sequencerSignKey := test.NewAccount(t)
+	test.DepositBalance(t, sequencerAddr, balance, blockchain, executor)
+
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(WatchTower), watchtowerAddr, watchtowerSignKey, stakeAmount, 1_000_000, "test"))
+	tAssert.NoError(Stake(blockchain, executor, sender, hclog.Default(), string(Sequencer), sequencerAddr, sequencerSignKey, stakeAmount, 1_000_000, "test"))
+
+	stranger, _ := test.NewAccount(t)
+
+	counter := &snapshotCounter{ActiveParticipants: NewActiveParticipantsQuerier(blockchain, executor, hclog.Default())}
+	gate := NewStakingTxGate(counter, WithTxGateMaxAge(time.Hour))
+
+	mustTx := func(tx *types.Transaction, err error) *types.Transaction {
+		tAssert.NoError(err)
+		return tx
+	}
+
+	// Transactions that don't call a restricted staking method pass through without any query.
+	receiver, _ := test.NewAccount(t)
+	passThrough := []*types.Transaction{
+		{From: stranger, To: &receiver, Value: big.NewInt(1)},
+		{From: stranger, Input: []byte{0x60, 0x80}},
+		{From: stranger, To: &AddrStakingContract, Input: []byte{0x01}},
+		mustTx(StakeTx(stranger, stakeAmount, string(Sequencer), 1_000_000)),
+	}
+
+	for _, tx := range passThrough {
+		allowed, err := gate.Allowed(tx)
+		tAssert.NoError(err)
+		tAssert.True(allowed)
+	}
+
+	tAssert.Equal(0, counter.snapshots)
+
+	testCases := []struct {
+		name    string
+		tx      *types.Transaction
+		allowed bool
+	}{
+		{"fraud proof from watchtower", mustTx(BeginDisputeResolutionTx(watchtowerAddr, sequencerAddr, 1_000_000)), true},
+		{"fraud proof from sequencer", mustTx(BeginDisputeResolutionTx(sequencerAddr, watchtowerAddr, 1_000_000)), false},
+		{"fraud proof from stranger", mustTx(BeginDisputeResolutionTx(stranger, sequencerAddr, 1_000_000)), false},
+		{"slash from sequencer", mustTx(SlashStakerTx(sequencerAddr, watchtowerAddr, 1_000_000)), true},
+		{"slash from stranger", mustTx(SlashStakerTx(stranger, sequencerAddr, 1_000_000)), false},
+		{"end dispute from watchtower", mustTx(EndDisputeResolutionTx(watchtowerAddr, sequencerAddr, 1_000_000)), false},
+		{"unstake from watchtower", mustTx(UnStakeTx(watchtowerAddr, 1_000_000)), true},
+		{"unstake from stranger", mustTx(UnStakeTx(stranger, 1_000_000)), false},
+	}
+
+	for _, tc := range testCases {
+		allowed, err := gate.Allowed(tc.tx)
+		tAssert.NoError(err, tc.name)
+		tAssert.Equal(tc.allowed, allowed, tc.name)
+	}
+
+	// All the checks were served from a single snapshot.
+	tAssert.Equal(1, counter.snapshots)
+
+	// A fork doesn't change the head, a new head refreshes the participant sets.
+	gate.HandleBlockEvent(&edge_blockchain.Event{Type: edge_blockchain.EventFork})
+	_, err = gate.Allowed(testCases[0].tx)
+	tAssert.NoError(err)
+	tAssert.Equal(1, counter.snapshots)
+
+	gate.HandleBlockEvent(&edge_blockchain.Event{Type: edge_blockchain.EventHead, NewChain: []*types.Header{blockchain.Header()}})
+	_, err = gate.Allowed(testCases[0].tx)
+	tAssert.NoError(err)
+	tAssert.Equal(2, counter.snapshots)
+}
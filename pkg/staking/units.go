@@ -0,0 +1,105 @@
+package staking
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	commontoken "github.com/availproject/op-evm/pkg/common"
+)
+
+// StakeDecimals is the number of decimal places of the staked token, the smallest unit is wei.
+const StakeDecimals = 18
+
+// ErrInvalidStakeAmount is returned when a stake amount can't be converted to wei.
+var ErrInvalidStakeAmount = errors.New("invalid stake amount")
+
+// ToStakeUnits converts an amount of tokens into wei.
+// The float is converted through its shortest decimal representation, so 1.1 becomes exactly 1.1 * 10^18 wei.
+// It returns nil if the amount is negative, not finite or has more than StakeDecimals decimal places.
+func ToStakeUnits(tokens float64) *big.Int {
+	if math.IsNaN(tokens) || math.IsInf(tokens, 0) {
+		return nil
+	}
+
+	wei, err := ParseStakeAmount(strconv.FormatFloat(tokens, 'f', -1, 64))
+	if err != nil {
+		return nil
+	}
+
+	return wei
+}
+
+// FromStakeUnits converts an amount of wei into tokens.
+// The result has enough precision to hold the wei amount exactly.
+// It returns nil if the amount is nil.
+func FromStakeUnits(wei *big.Int) *big.Float {
+	if wei == nil {
+		return nil
+	}
+
+	tokens := new(big.Float).SetPrec(uint(wei.BitLen()) + 64).SetInt(wei)
+
+	return tokens.Quo(tokens, new(big.Float).SetInt(commontoken.ETH))
+}
+
+// ParseStakeAmount parses a decimal amount of tokens, like "1.5", into wei.
+// It returns an error wrapping ErrInvalidStakeAmount if the amount is negative, is not a plain
+// decimal number or has more than StakeDecimals decimal places.
+func ParseStakeAmount(tokens string) (*big.Int, error) {
+	s := strings.TrimSpace(tokens)
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty amount", ErrInvalidStakeAmount)
+	}
+
+	if strings.HasPrefix(s, "-") {
+		return nil, fmt.Errorf("%w: negative amount %q", ErrInvalidStakeAmount, tokens)
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidStakeAmount, tokens)
+	}
+
+	if len(fraction) > StakeDecimals {
+		return nil, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidStakeAmount, tokens, StakeDecimals)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", StakeDecimals-len(fraction))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidStakeAmount, tokens)
+		}
+	}
+
+	wei, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidStakeAmount, tokens)
+	}
+
+	return wei, nil
+}
+
+// FormatStakeAmount formats an amount of wei as a decimal amount of tokens, without trailing zeros.
+// It's the inverse of ParseStakeAmount.
+func FormatStakeAmount(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+
+	whole, fraction := new(big.Int).QuoRem(new(big.Int).Abs(wei), commontoken.ETH, new(big.Int))
+
+	s := whole.String()
+	if fraction.Sign() != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%0*s", StakeDecimals, fraction.String()), "0")
+	}
+
+	if wei.Sign() < 0 {
+		s = "-" + s
+	}
+
+	return s
+}
@@ -0,0 +1,100 @@
+package staking
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/types"
+	"github.com/test-go/testify/assert"
+)
+
+func TestParseStakeAmount(t *testing.T) {
+	testCases := []struct {
+		tokens      string
+		expectedWei string
+		expectedErr error
+	}{
+		{"1", "1000000000000000000", nil},
+		{"1.5", "1500000000000000000", nil},
+		{" 10 ", "10000000000000000000", nil},
+		{".5", "500000000000000000", nil},
+		{"2.", "2000000000000000000", nil},
+		{"0.000000000000000001", "1", nil},
+		{"123456789.123456789123456789", "123456789123456789123456789", nil},
+		{"0.0000000000000000001", "", ErrInvalidStakeAmount},
+		{"-1", "", ErrInvalidStakeAmount},
+		{"", "", ErrInvalidStakeAmount},
+		{".", "", ErrInvalidStakeAmount},
+		{"1e18", "", ErrInvalidStakeAmount},
+		{"+1", "", ErrInvalidStakeAmount},
+		{"1.2.3", "", ErrInvalidStakeAmount},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tokens, func(t *testing.T) {
+			tAssert := assert.New(t)
+
+			wei, err := ParseStakeAmount(tc.tokens)
+			if tc.expectedErr != nil {
+				tAssert.True(errors.Is(err, tc.expectedErr), "unexpected error: %v", err)
+				return
+			}
+
+			tAssert.NoError(err)
+			tAssert.Equal(tc.expectedWei, wei.String())
+			tAssert.Equal(wei, mustParseStakeAmount(t, FormatStakeAmount(wei)))
+		})
+	}
+}
+
+func TestStakeUnitsConversion(t *testing.T) {
+	tAssert := assert.New(t)
+
+	tAssert.Equal("1100000000000000000", ToStakeUnits(1.1).String())
+	tAssert.Equal("10000000000000000000", ToStakeUnits(10).String())
+	tAssert.Equal("0", ToStakeUnits(0).String())
+	tAssert.Nil(ToStakeUnits(-1))
+	tAssert.Nil(ToStakeUnits(math.NaN()))
+	tAssert.Nil(ToStakeUnits(math.Inf(1)))
+	tAssert.Nil(ToStakeUnits(1e-19))
+
+	tokens := FromStakeUnits(big.NewInt(1_500_000_000_000_000_000))
+	tAssert.Equal("1.5", tokens.Text('f', -1))
+
+	// The whole wei amount is kept, even beyond the precision of a float64.
+	wei, _ := new(big.Int).SetString("123456789123456789123456789", 10)
+	tAssert.Equal("123456789.123456789123456789", FromStakeUnits(wei).Text('f', StakeDecimals))
+	tAssert.Nil(FromStakeUnits(nil))
+
+	tAssert.Equal("0.000000000000000001", FormatStakeAmount(big.NewInt(1)))
+	tAssert.Equal("-2.5", FormatStakeAmount(big.NewInt(-2_500_000_000_000_000_000)))
+}
+
+func TestStakeTxAmount(t *testing.T) {
+	tAssert := assert.New(t)
+
+	from := types.StringToAddress("0x1")
+
+	tx, err := StakeTxWithTokens(from, "2.5", string(Sequencer), 1_000_000)
+	tAssert.NoError(err)
+	tAssert.Equal(ToStakeUnits(2.5), tx.Value)
+
+	_, err = StakeTxWithTokens(from, "-2.5", string(Sequencer), 1_000_000)
+	tAssert.True(errors.Is(err, ErrInvalidStakeAmount))
+
+	_, err = StakeTx(from, big.NewInt(0), string(Sequencer), 1_000_000)
+	tAssert.True(errors.Is(err, ErrInvalidStakeAmount))
+}
+
+func mustParseStakeAmount(t *testing.T, tokens string) *big.Int {
+	t.Helper()
+
+	wei, err := ParseStakeAmount(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return wei
+}